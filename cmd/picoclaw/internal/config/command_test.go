@@ -0,0 +1,36 @@
+package config
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfigCommand(t *testing.T) {
+	cmd := NewConfigCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "config", cmd.Use)
+	assert.Equal(t, "Inspect the effective configuration", cmd.Short)
+
+	assert.True(t, cmd.HasSubCommands())
+
+	allowedCommands := []string{
+		"show",
+		"validate",
+	}
+
+	subcommands := cmd.Commands()
+	assert.Len(t, subcommands, len(allowedCommands))
+
+	for _, subcmd := range subcommands {
+		found := slices.Contains(allowedCommands, subcmd.Name())
+		assert.True(t, found, "unexpected subcommand %q", subcmd.Name())
+
+		assert.Nil(t, subcmd.Run)
+		assert.NotNil(t, subcmd.RunE)
+	}
+}
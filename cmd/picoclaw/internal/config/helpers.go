@@ -0,0 +1,19 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// renderEffectiveConfig marshals cfg as indented JSON. SecureString fields are
+// never marshaled to JSON; as a second line of defense, any secret value that
+// shows up in a plain field (e.g. pasted into a header or URL) is filtered.
+func renderEffectiveConfig(cfg *config.Config) (string, error) {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return cfg.SensitiveDataReplacer().Replace(string(data)), nil
+}
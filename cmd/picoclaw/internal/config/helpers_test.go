@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func writeTestConfig(t *testing.T, mutate func(*config.Config)) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	cfg := config.DefaultConfig()
+	if mutate != nil {
+		mutate(cfg)
+	}
+	require.NoError(t, config.SaveConfig(path, cfg))
+	t.Setenv(config.EnvConfig, path)
+	t.Setenv("PICOCLAW_HOME", dir)
+	return path
+}
+
+func TestShowCommand_RedactsSecretsAndAppliesEnv(t *testing.T) {
+	const secret = "sk-test-super-secret-key"
+	writeTestConfig(t, func(cfg *config.Config) {
+		cfg.ModelList = append(cfg.ModelList, &config.ModelConfig{
+			ModelName:     "leaky",
+			Model:         "openai/gpt-4o",
+			APIKeys:       config.SimpleSecureStrings(secret),
+			CustomHeaders: map[string]string{"X-Key": secret},
+		})
+	})
+	t.Setenv("PICOCLAW_AGENTS_DEFAULTS_MODEL_NAME", "leaky")
+
+	var out bytes.Buffer
+	cmd := newShowCommand()
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Execute())
+
+	assert.NotContains(t, out.String(), secret)
+	assert.Contains(t, out.String(), "[FILTERED]")
+	assert.Contains(t, out.String(), `"model_name": "leaky"`)
+}
+
+func TestValidateCommand_FailsOnInvalidConfig(t *testing.T) {
+	writeTestConfig(t, func(cfg *config.Config) {
+		cfg.ModelList = append(cfg.ModelList, &config.ModelConfig{ModelName: "broken"})
+	})
+
+	cmd := newValidateCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model is required")
+}
+
+func TestValidateCommand_OK(t *testing.T) {
+	path := writeTestConfig(t, nil)
+
+	var out bytes.Buffer
+	cmd := newValidateCommand()
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), path)
+}
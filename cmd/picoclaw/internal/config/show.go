@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func newShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective config with secrets redacted",
+		Long: `Load the config the same way the gateway does (config file, security
+file and PICOCLAW_* environment overrides), validate it, and print the
+result as JSON. Secret fields are omitted and any secret value that appears
+elsewhere is replaced with [FILTERED].`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			out, err := renderEffectiveConfig(cfg)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func newValidateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check that the effective config loads and is valid",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := internal.LoadConfig(); err != nil {
+				return fmt.Errorf("invalid config: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Config OK: %s\n", internal.GetConfigPath())
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -30,6 +30,7 @@ func LoadConfig() (*config.Config, error) {
 		return nil, err
 	}
 	logger.SetLevelFromString(cfg.Gateway.LogLevel)
+	logger.SetFormat(cfg.Gateway.LogFormat)
 	return cfg, nil
 }
 
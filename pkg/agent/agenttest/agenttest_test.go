@@ -0,0 +1,101 @@
+package agenttest
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+type weatherTool struct{}
+
+func (weatherTool) Name() string        { return "weather" }
+func (weatherTool) Description() string { return "Current weather for a city" }
+func (weatherTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city": map[string]any{"type": "string"},
+		},
+		"required": []string{"city"},
+	}
+}
+
+func (weatherTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	return tools.SilentResult("sunny in " + args["city"].(string))
+}
+
+func TestReplayFixture(t *testing.T) {
+	conv, err := LoadConversation(filepath.Join("testdata", "weather.json"))
+	if err != nil {
+		t.Fatalf("LoadConversation() error = %v", err)
+	}
+	h := New(t, NewMockProvider(), WithTools(weatherTool{}))
+	h.Replay(context.Background(), conv)
+
+	// The tool result reached the model, and the second turn saw the first.
+	reqs := h.Provider.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("provider calls = %d, want 3", len(reqs))
+	}
+	if !containsContent(reqs[1], "sunny in Paris") {
+		t.Error("second model call did not include the weather tool result")
+	}
+	if !containsContent(reqs[2], "It is sunny in Paris.") {
+		t.Error("second turn did not include the first turn's history")
+	}
+}
+
+func TestReplayTable(t *testing.T) {
+	tests := []Conversation{
+		{
+			Name: "message tool publishes to the channel",
+			Turns: []Turn{{
+				User: "tell the chat hello",
+				Responses: []Response{
+					{ToolCalls: []ToolCall{{Name: "message", Arguments: map[string]any{"content": "hello"}}}},
+					{Content: "Sent."},
+				},
+				WantTools:    []ToolCall{{Name: "message"}},
+				WantOutbound: []string{"hello"},
+			}},
+		},
+		{
+			Name: "invalid tool arguments are reported back to the model",
+			Turns: []Turn{{
+				User: "weather?",
+				Responses: []Response{
+					{ToolCalls: []ToolCall{{Name: "weather", Arguments: map[string]any{}}}},
+					{Content: "Which city?"},
+				},
+				WantReply: "Which city?",
+			}},
+		},
+		{
+			Name: "provider errors surface from the turn",
+			Turns: []Turn{{
+				User:      "hi",
+				Responses: []Response{{Error: "upstream exploded"}},
+				WantError: "upstream exploded",
+			}},
+		},
+	}
+
+	for _, conv := range tests {
+		t.Run(conv.Name, func(t *testing.T) {
+			h := New(t, NewMockProvider(), WithTools(weatherTool{}))
+			h.Replay(context.Background(), &conv)
+		})
+	}
+}
+
+func containsContent(req Request, s string) bool {
+	for _, msg := range req.Messages {
+		if strings.Contains(msg.Content, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package agenttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Conversation is a replayable script of user turns. Each turn lists the
+// model responses the loop will receive while handling it and what the test
+// expects to come out. In JSON:
+//
+//	{
+//	  "name": "looks up the weather",
+//	  "turns": [{
+//	    "user": "weather in Paris?",
+//	    "responses": [
+//	      {"tool_calls": [{"name": "weather", "arguments": {"city": "Paris"}}]},
+//	      {"content": "Sunny, 21°C."}
+//	    ],
+//	    "want_reply": "Sunny, 21°C.",
+//	    "want_tools": [{"name": "weather", "arguments": {"city": "Paris"}}]
+//	  }]
+//	}
+type Conversation struct {
+	Name  string `json:"name,omitempty"`
+	Turns []Turn `json:"turns"`
+}
+
+// Turn is one user message and its expectations. Empty expectations are not
+// checked, except that every scripted response must be used.
+type Turn struct {
+	User      string     `json:"user"`
+	Responses []Response `json:"responses"`
+
+	// WantReply is the exact reply; WantReplyContains only a substring.
+	WantReply         string `json:"want_reply,omitempty"`
+	WantReplyContains string `json:"want_reply_contains,omitempty"`
+	// WantError is a substring of the turn's error; without it the turn
+	// must succeed.
+	WantError string `json:"want_error,omitempty"`
+	// WantTools are the tool calls executed during the turn, in order.
+	// Arguments are only compared when given.
+	WantTools []ToolCall `json:"want_tools,omitempty"`
+	// WantOutbound are the contents of messages published to the bus
+	// during the turn, in order.
+	WantOutbound []string `json:"want_outbound,omitempty"`
+}
+
+// LoadConversation reads a Conversation from a JSON file.
+func LoadConversation(path string) (*Conversation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var conv Conversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(conv.Turns) == 0 {
+		return nil, fmt.Errorf("%s: conversation has no turns", path)
+	}
+	return &conv, nil
+}
+
+// Replay feeds each turn of conv through the harness and reports every
+// unmet expectation as a test error.
+func (h *Harness) Replay(ctx context.Context, conv *Conversation) {
+	h.t.Helper()
+	for i, turn := range conv.Turns {
+		h.replayTurn(ctx, i+1, turn)
+	}
+}
+
+func (h *Harness) replayTurn(ctx context.Context, n int, turn Turn) {
+	t := h.t
+	t.Helper()
+
+	toolsBefore := len(h.tools)
+	outboundBefore := len(h.Channel.Messages())
+	h.Provider.Push(turn.Responses...)
+
+	reply, err := h.Send(ctx, turn.User)
+
+	switch {
+	case turn.WantError != "":
+		if err == nil || !strings.Contains(err.Error(), turn.WantError) {
+			t.Errorf("turn %d: error = %v, want it to contain %q", n, err, turn.WantError)
+		}
+	case err != nil:
+		t.Errorf("turn %d: unexpected error: %v", n, err)
+	}
+	if turn.WantReply != "" && reply != turn.WantReply {
+		t.Errorf("turn %d: reply = %q, want %q", n, reply, turn.WantReply)
+	}
+	if turn.WantReplyContains != "" && !strings.Contains(reply, turn.WantReplyContains) {
+		t.Errorf("turn %d: reply = %q, want it to contain %q", n, reply, turn.WantReplyContains)
+	}
+
+	if left := h.Provider.Remaining(); left > 0 {
+		t.Errorf("turn %d: %d scripted responses were not used", n, left)
+	}
+
+	if turn.WantTools != nil {
+		checkTools(t, n, h.tools[toolsBefore:], turn.WantTools)
+	}
+	if turn.WantOutbound != nil {
+		var got []string
+		for _, msg := range h.Channel.Messages()[outboundBefore:] {
+			got = append(got, msg.Content)
+		}
+		if !reflect.DeepEqual(got, turn.WantOutbound) {
+			t.Errorf("turn %d: outbound = %q, want %q", n, got, turn.WantOutbound)
+		}
+	}
+}
+
+func checkTools(t testing.TB, n int, got []ToolInvocation, want []ToolCall) {
+	t.Helper()
+	if len(got) != len(want) {
+		names := make([]string, len(got))
+		for i, call := range got {
+			names[i] = call.Name
+		}
+		t.Errorf("turn %d: tools called = %q, want %d calls", n, names, len(want))
+		return
+	}
+	for i, w := range want {
+		if got[i].Name != w.Name {
+			t.Errorf("turn %d: tool call %d = %q, want %q", n, i+1, got[i].Name, w.Name)
+			continue
+		}
+		if w.Arguments != nil && !sameJSON(got[i].Arguments, w.Arguments) {
+			t.Errorf("turn %d: tool call %d (%s) arguments = %v, want %v",
+				n, i+1, w.Name, got[i].Arguments, w.Arguments)
+		}
+	}
+}
+
+// sameJSON compares argument maps by their JSON encoding, so 1 and 1.0 from
+// a fixture match.
+func sameJSON(a, b map[string]any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
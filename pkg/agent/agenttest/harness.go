@@ -0,0 +1,176 @@
+package agenttest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+const (
+	// DefaultChannel and DefaultChatID address the turns a Harness sends.
+	DefaultChannel = "test"
+	DefaultChatID  = "chat"
+	// DefaultSessionKey is the session the turns belong to.
+	DefaultSessionKey = "agenttest"
+
+	eventBuffer = 256
+)
+
+// ToolInvocation is a tool call the agent loop executed.
+type ToolInvocation struct {
+	Name      string
+	Arguments map[string]any
+}
+
+// Channel records what the agent publishes to the bus, standing in for the
+// channel manager that would normally deliver it.
+type Channel struct {
+	mu       sync.Mutex
+	messages []bus.OutboundMessage
+	media    []bus.OutboundMediaMessage
+}
+
+// Messages returns the text messages published so far.
+func (c *Channel) Messages() []bus.OutboundMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]bus.OutboundMessage(nil), c.messages...)
+}
+
+// Media returns the media messages published so far.
+func (c *Channel) Media() []bus.OutboundMediaMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]bus.OutboundMediaMessage(nil), c.media...)
+}
+
+// drain moves everything waiting on the bus into the channel. Publishing
+// blocks until a message is buffered, so after a turn returns its messages
+// are all waiting.
+func (c *Channel) drain(msgBus *bus.MessageBus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		select {
+		case msg := <-msgBus.OutboundChan():
+			c.messages = append(c.messages, msg)
+		case msg := <-msgBus.OutboundMediaChan():
+			c.media = append(c.media, msg)
+		default:
+			return
+		}
+	}
+}
+
+// Harness runs turns through a real AgentLoop backed by a MockProvider.
+type Harness struct {
+	Loop     *agent.AgentLoop
+	Bus      *bus.MessageBus
+	Provider *MockProvider
+	Channel  *Channel
+	Config   *config.Config
+
+	t      testing.TB
+	events agent.EventSubscription
+	tools  []ToolInvocation
+}
+
+// Option configures a Harness.
+type Option func(*harnessOptions)
+
+type harnessOptions struct {
+	configure []func(*config.Config)
+	tools     []tools.Tool
+}
+
+// WithConfig lets the test adjust the config before the loop is built.
+func WithConfig(fn func(*config.Config)) Option {
+	return func(o *harnessOptions) { o.configure = append(o.configure, fn) }
+}
+
+// WithTools registers extra tools on the loop.
+func WithTools(ts ...tools.Tool) Option {
+	return func(o *harnessOptions) { o.tools = append(o.tools, ts...) }
+}
+
+// New builds a Harness whose agent works in a temporary workspace and gets
+// its model responses from provider. The loop is closed when the test ends.
+func New(t testing.TB, provider *MockProvider, opts ...Option) *Harness {
+	t.Helper()
+
+	var o harnessOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	cfg.Agents.Defaults.ModelName = provider.GetDefaultModel()
+	cfg.Agents.Defaults.MaxTokens = 4096
+	cfg.Agents.Defaults.MaxToolIterations = 10
+	for _, fn := range o.configure {
+		fn(cfg)
+	}
+
+	msgBus := bus.NewMessageBus()
+	al := agent.NewAgentLoop(cfg, msgBus, provider)
+	for _, tool := range o.tools {
+		al.RegisterTool(tool)
+	}
+
+	h := &Harness{
+		Loop:     al,
+		Bus:      msgBus,
+		Provider: provider,
+		Channel:  &Channel{},
+		Config:   cfg,
+		t:        t,
+		events:   al.SubscribeEvents(eventBuffer),
+	}
+	t.Cleanup(func() {
+		al.UnsubscribeEvents(h.events.ID)
+		al.Close()
+		msgBus.Close()
+	})
+	return h
+}
+
+// Send runs one user turn through ProcessDirectWithChannel and returns the
+// agent's reply. Outbound messages and tool calls made during the turn are
+// collected into Channel and ToolCalls.
+func (h *Harness) Send(ctx context.Context, content string) (string, error) {
+	h.t.Helper()
+	reply, err := h.Loop.ProcessDirectWithChannel(ctx, content, DefaultSessionKey, DefaultChannel, DefaultChatID)
+	h.Channel.drain(h.Bus)
+	h.collectEvents()
+	return reply, err
+}
+
+// ToolCalls returns the tool calls executed so far, in order.
+func (h *Harness) ToolCalls() []ToolInvocation {
+	return append([]ToolInvocation(nil), h.tools...)
+}
+
+func (h *Harness) collectEvents() {
+	for {
+		select {
+		case evt, ok := <-h.events.C:
+			if !ok {
+				return
+			}
+			if evt.Kind != agent.EventKindToolExecStart {
+				continue
+			}
+			if p, ok := evt.Payload.(agent.ToolExecStartPayload); ok {
+				h.tools = append(h.tools, ToolInvocation{Name: p.Tool, Arguments: p.Arguments})
+			}
+		default:
+			return
+		}
+	}
+}
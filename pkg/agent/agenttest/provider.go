@@ -0,0 +1,126 @@
+// Package agenttest drives an AgentLoop through scripted conversations for
+// tests: a MockProvider returns canned model responses, a Channel captures
+// what the agent sends out, and a Harness ties them to a real AgentLoop.
+// Conversations can be written in Go or loaded from JSON fixtures (see
+// Conversation).
+package agenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// ErrScriptExhausted is returned by MockProvider.Chat when the loop asks for
+// more model responses than were scripted.
+var ErrScriptExhausted = errors.New("agenttest: no scripted response left")
+
+// Response is one scripted model response. Error, when set, makes the call
+// fail with that message instead.
+type Response struct {
+	Content   string     `json:"content,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// ToolCall is a tool call in a scripted response. An empty ID becomes
+// "call-<response>-<index>", counting both from 1.
+type ToolCall struct {
+	ID        string         `json:"id,omitempty"`
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// Request is one Chat call the MockProvider received.
+type Request struct {
+	Messages []providers.Message
+	Tools    []providers.ToolDefinition
+	Model    string
+}
+
+// MockProvider is a providers.LLMProvider that answers each Chat call with
+// the next scripted Response, in order. It is safe for concurrent use.
+type MockProvider struct {
+	mu       sync.Mutex
+	script   []Response
+	requests []Request
+	calls    int
+}
+
+// NewMockProvider returns a provider scripted with responses.
+func NewMockProvider(responses ...Response) *MockProvider {
+	return &MockProvider{script: responses}
+}
+
+// Push appends responses to the script.
+func (p *MockProvider) Push(responses ...Response) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.script = append(p.script, responses...)
+}
+
+// Remaining reports how many scripted responses have not been used yet.
+func (p *MockProvider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.script)
+}
+
+// Requests returns the Chat calls received so far.
+func (p *MockProvider) Requests() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Request(nil), p.requests...)
+}
+
+// Chat records the request and returns the next scripted response.
+func (p *MockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, Request{
+		Messages: append([]providers.Message(nil), messages...),
+		Tools:    tools,
+		Model:    model,
+	})
+	if len(p.script) == 0 {
+		return nil, ErrScriptExhausted
+	}
+	next := p.script[0]
+	p.script = p.script[1:]
+	p.calls++
+
+	if next.Error != "" {
+		return nil, errors.New(next.Error)
+	}
+	resp := &providers.LLMResponse{Content: next.Content, FinishReason: "stop"}
+	for i, tc := range next.ToolCalls {
+		id := tc.ID
+		if id == "" {
+			id = fmt.Sprintf("call-%d-%d", p.calls, i+1)
+		}
+		args := tc.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		resp.ToolCalls = append(resp.ToolCalls, providers.ToolCall{ID: id, Name: tc.Name, Arguments: args})
+	}
+	if len(resp.ToolCalls) > 0 {
+		resp.FinishReason = "tool_calls"
+	}
+	return resp, nil
+}
+
+// GetDefaultModel returns "mock-model".
+func (p *MockProvider) GetDefaultModel() string {
+	return "mock-model"
+}
@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// sessionSpend is the usage charged against one session's budget: the
+// session's own LLM calls and those of the SubTurns its turns spawn. Like
+// the usage totals it lives in memory only and resets when the gateway
+// restarts, the session history is cleared, or the session is evicted as
+// idle.
+type sessionSpend struct {
+	mu               sync.Mutex
+	tokens           int64
+	promptTokens     int64
+	completionTokens int64
+	updated          time.Time
+}
+
+// budgetSessionKey is the session whose budget a turn spends: its own, or
+// for a SubTurn, that of the root turn that spawned it.
+func (ts *turnState) budgetSessionKey() string {
+	root := ts
+	for root.parentTurnState != nil {
+		root = root.parentTurnState
+	}
+	return root.sessionKey
+}
+
+// chargeSessionBudget adds usage to the spend of sessionKey and returns which
+// budget ("token" or "cost") the session has exhausted, or "" if neither.
+func (al *AgentLoop) chargeSessionBudget(
+	sessionKey string,
+	usage *providers.UsageInfo,
+	budget config.BudgetConfig,
+) string {
+	sessionKey = strings.TrimSpace(sessionKey)
+	if sessionKey == "" || usage == nil || (budget.MaxTokens <= 0 && budget.MaxCostUSD <= 0) {
+		return ""
+	}
+	total := usage.TotalTokens
+	if total == 0 {
+		total = usage.PromptTokens + usage.CompletionTokens
+	}
+
+	v, _ := al.sessionSpend.LoadOrStore(sessionKey, &sessionSpend{})
+	spend := v.(*sessionSpend)
+	spend.mu.Lock()
+	spend.tokens += int64(total)
+	spend.promptTokens += int64(usage.PromptTokens)
+	spend.completionTokens += int64(usage.CompletionTokens)
+	spend.updated = time.Now()
+	spend.mu.Unlock()
+
+	return spend.exceeded(budget)
+}
+
+// sessionBudgetExceeded returns which budget ("token" or "cost") sessionKey
+// has already exhausted, or "" if neither, without charging anything. Turns
+// check it before each LLM call so an exhausted session makes no more calls.
+func (al *AgentLoop) sessionBudgetExceeded(sessionKey string, budget config.BudgetConfig) string {
+	if budget.MaxTokens <= 0 && budget.MaxCostUSD <= 0 {
+		return ""
+	}
+	v, ok := al.sessionSpend.Load(strings.TrimSpace(sessionKey))
+	if !ok {
+		return ""
+	}
+	return v.(*sessionSpend).exceeded(budget)
+}
+
+func (s *sessionSpend) exceeded(budget config.BudgetConfig) string {
+	s.mu.Lock()
+	tokens := s.tokens
+	cost := float64(s.promptTokens)*budget.InputCostPerMTok/1e6 +
+		float64(s.completionTokens)*budget.OutputCostPerMTok/1e6
+	s.mu.Unlock()
+
+	if budget.MaxTokens > 0 && tokens >= int64(budget.MaxTokens) {
+		return "token"
+	}
+	if budget.MaxCostUSD > 0 && cost >= budget.MaxCostUSD {
+		return "cost"
+	}
+	return ""
+}
+
+func (al *AgentLoop) resetSessionBudget(sessionKey string) {
+	al.sessionSpend.Delete(strings.TrimSpace(sessionKey))
+}
+
+// budgetExceededContent is the reply for a turn stopped because its session
+// exhausted the budget kind ("token" or "cost"), in locale.
+func budgetExceededContent(locale, partial, kind string) string {
+	id := i18n.ReplyTokenBudget
+	if kind == "cost" {
+		id = i18n.ReplyCostBudget
+	}
+	notice := i18n.Text(locale, id)
+	if partial == "" {
+		return notice
+	}
+	return partial + "\n\n" + notice
+}
+
+// toolLimitContent is the reply for a turn that used all max_tool_iterations
+// steps: the last text the model wrote alongside its tool calls, if any, and
+// a note in locale saying where it stopped.
+func toolLimitContent(locale, partial string, steps int) string {
+	notice := i18n.Text(locale, i18n.ReplyToolLimitOne)
+	if steps != 1 {
+		notice = i18n.Sprintf(locale, i18n.ReplyToolLimit, steps)
+	}
+	if strings.TrimSpace(partial) == "" {
+		return notice
+	}
+	return partial + "\n\n" + notice
+}
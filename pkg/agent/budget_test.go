@@ -0,0 +1,215 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// loopingToolProvider keeps requesting the same tool, reporting fixed usage
+// for every call, so only a budget (or MaxIterations) can end the turn.
+type loopingToolProvider struct {
+	mu    sync.Mutex
+	calls int
+	usage providers.UsageInfo
+}
+
+func (p *loopingToolProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	usage := p.usage
+	return &providers.LLMResponse{
+		Content: "still working",
+		ToolCalls: []providers.ToolCall{{
+			ID:        "call",
+			Name:      "echo_text",
+			Arguments: map[string]any{"text": "again"},
+		}},
+		Usage: &usage,
+	}, nil
+}
+
+func (p *loopingToolProvider) GetDefaultModel() string {
+	return "looping-tool-provider"
+}
+
+func runBudgetTurn(t *testing.T, provider *loopingToolProvider, budget config.BudgetConfig) string {
+	t.Helper()
+	al, agent, cleanup := newHookTestLoop(t, provider)
+	t.Cleanup(cleanup)
+
+	agent.MaxIterations = 10
+	agent.Budget = budget
+	al.RegisterTool(&echoTextTool{})
+
+	resp, err := al.runAgentLoop(context.Background(), agent, processOptions{
+		SessionKey:      "budget-session",
+		Channel:         "cli",
+		ChatID:          "direct",
+		UserMessage:     "loop forever",
+		DefaultResponse: defaultResponse,
+	})
+	if err != nil {
+		t.Fatalf("runAgentLoop failed: %v", err)
+	}
+	return resp
+}
+
+func TestSessionBudget_StopsOnTokenLimit(t *testing.T) {
+	provider := &loopingToolProvider{usage: providers.UsageInfo{PromptTokens: 50, CompletionTokens: 10, TotalTokens: 60}}
+
+	resp := runBudgetTurn(t, provider, config.BudgetConfig{MaxTokens: 100})
+
+	if provider.calls != 2 {
+		t.Fatalf("expected the turn to stop after 2 LLM calls, got %d", provider.calls)
+	}
+	if !strings.HasPrefix(resp, "still working") || !strings.Contains(resp, "token budget") {
+		t.Fatalf("expected partial content plus token budget notice, got %q", resp)
+	}
+}
+
+func TestSessionBudget_StopsOnCostLimit(t *testing.T) {
+	provider := &loopingToolProvider{usage: providers.UsageInfo{PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000}}
+
+	// Each call costs 1000*$1/M + 1000*$4/M = $0.005.
+	resp := runBudgetTurn(t, provider, config.BudgetConfig{
+		MaxCostUSD:        0.012,
+		InputCostPerMTok:  1,
+		OutputCostPerMTok: 4,
+	})
+
+	if provider.calls != 3 {
+		t.Fatalf("expected the turn to stop after 3 LLM calls, got %d", provider.calls)
+	}
+	if !strings.Contains(resp, "cost budget") {
+		t.Fatalf("expected cost budget notice, got %q", resp)
+	}
+}
+
+func TestSessionBudget_DisabledByDefault(t *testing.T) {
+	provider := &loopingToolProvider{usage: providers.UsageInfo{TotalTokens: 1_000_000}}
+
+	resp := runBudgetTurn(t, provider, config.BudgetConfig{})
+
+	if provider.calls != 10 {
+		t.Fatalf("expected MaxIterations to bound the turn, got %d calls", provider.calls)
+	}
+	if strings.Contains(resp, "budget") {
+		t.Fatalf("unexpected budget notice without a budget: %q", resp)
+	}
+}
+
+func TestSessionBudget_SpansTurnsUntilCleared(t *testing.T) {
+	provider := &loopingToolProvider{usage: providers.UsageInfo{PromptTokens: 50, CompletionTokens: 10, TotalTokens: 60}}
+	al, agent, cleanup := newHookTestLoop(t, provider)
+	t.Cleanup(cleanup)
+
+	agent.MaxIterations = 2
+	agent.Budget = config.BudgetConfig{MaxTokens: 150}
+	al.RegisterTool(&echoTextTool{})
+
+	run := func() string {
+		t.Helper()
+		resp, err := al.runAgentLoop(context.Background(), agent, processOptions{
+			SessionKey:      "budget-session",
+			Channel:         "cli",
+			ChatID:          "direct",
+			UserMessage:     "loop forever",
+			DefaultResponse: defaultResponse,
+		})
+		if err != nil {
+			t.Fatalf("runAgentLoop failed: %v", err)
+		}
+		return resp
+	}
+
+	// The first turn spends 120 tokens and ends at MaxIterations.
+	if resp := run(); strings.Contains(resp, "budget") {
+		t.Fatalf("first turn should stay within budget, got %q", resp)
+	}
+	// The second turn pushes the session past 150 on its first call.
+	provider.calls = 0
+	if resp := run(); provider.calls != 1 || !strings.Contains(resp, "token budget") {
+		t.Fatalf("expected the second turn to stop after 1 call with a notice, got %d calls, %q", provider.calls, resp)
+	}
+	// The session is now exhausted: the next turn makes no call at all.
+	provider.calls = 0
+	if resp := run(); provider.calls != 0 || !strings.Contains(resp, "token budget") {
+		t.Fatalf("expected an exhausted session to get 0 calls and a notice, got %d calls, %q", provider.calls, resp)
+	}
+
+	if err := al.clearSession(context.Background(), agent, "budget-session"); err != nil {
+		t.Fatalf("clearSession failed: %v", err)
+	}
+	provider.calls = 0
+	if resp := run(); provider.calls != 2 || strings.Contains(resp, "budget") {
+		t.Fatalf("expected a cleared session to start a fresh budget, got %d calls, %q", provider.calls, resp)
+	}
+}
+
+func TestSessionBudget_SubTurnChargesRootSession(t *testing.T) {
+	root := &turnState{sessionKey: "chat"}
+	child := &turnState{sessionKey: "subturn-1", parentTurnState: root}
+	grandchild := &turnState{sessionKey: "subturn-2", parentTurnState: child}
+
+	if got := grandchild.budgetSessionKey(); got != "chat" {
+		t.Fatalf("budgetSessionKey() = %q, want the root session", got)
+	}
+
+	al := &AgentLoop{}
+	budget := config.BudgetConfig{MaxTokens: 100}
+	if got := al.chargeSessionBudget(child.budgetSessionKey(), &providers.UsageInfo{TotalTokens: 70}, budget); got != "" {
+		t.Fatalf("chargeSessionBudget = %q, want within budget", got)
+	}
+	if got := al.chargeSessionBudget(root.budgetSessionKey(), &providers.UsageInfo{TotalTokens: 40}, budget); got != "token" {
+		t.Fatalf("chargeSessionBudget = %q, want token budget exhausted", got)
+	}
+}
+
+func TestSessionBudget_NoticeUsesReplyLocale(t *testing.T) {
+	provider := &loopingToolProvider{usage: providers.UsageInfo{TotalTokens: 60}}
+	al, agent, cleanup := newHookTestLoop(t, provider)
+	defer cleanup()
+	al.GetConfig().Agents.Defaults.Locale = "zh"
+	agent.MaxIterations = 10
+	agent.Budget = config.BudgetConfig{MaxTokens: 100}
+	al.RegisterTool(&echoTextTool{})
+
+	resp, err := al.runAgentLoop(context.Background(), agent, processOptions{
+		SessionKey:      "budget-session",
+		Channel:         "cli",
+		ChatID:          "direct",
+		UserMessage:     "loop forever",
+		DefaultResponse: defaultResponse,
+	})
+	if err != nil {
+		t.Fatalf("runAgentLoop failed: %v", err)
+	}
+	if want := "still working\n\n" + i18n.Text("zh", i18n.ReplyTokenBudget); resp != want {
+		t.Fatalf("response = %q, want %q", resp, want)
+	}
+}
+
+func TestToolLimitContent_Localized(t *testing.T) {
+	if got, want := toolLimitContent("zh", "", 3), i18n.Sprintf("zh", i18n.ReplyToolLimit, 3); got != want {
+		t.Fatalf("toolLimitContent(zh, 3) = %q, want %q", got, want)
+	}
+	if got := toolLimitContent("", "", 1); !strings.Contains(got, "1 tool step without") {
+		t.Fatalf("toolLimitContent(1) = %q, want the singular", got)
+	}
+	if got := budgetExceededContent("", "", "cost"); got != i18n.Text("", i18n.ReplyCostBudget) {
+		t.Fatalf("budgetExceededContent(cost) = %q", got)
+	}
+}
@@ -47,6 +47,9 @@ const (
 	EventKindSubTurnOrphan
 	// EventKindError is emitted when a turn encounters an execution error.
 	EventKindError
+	// EventKindToolLimitReached is emitted when a turn stops because it used
+	// all of its tool iterations without a final answer.
+	EventKindToolLimitReached
 
 	eventKindCount
 )
@@ -71,6 +74,7 @@ var eventKindNames = [...]string{
 	"subturn_result_delivered",
 	"subturn_orphan",
 	"error",
+	"tool_limit_reached",
 }
 
 // String returns the stable string form of an EventKind.
@@ -264,6 +268,13 @@ type SubTurnOrphanPayload struct {
 	Reason       string
 }
 
+// ToolLimitReachedPayload describes a turn cut off by max_tool_iterations.
+type ToolLimitReachedPayload struct {
+	Iterations        int
+	MaxIterations     int
+	PartialContentLen int
+}
+
 // ErrorPayload describes an execution error inside the agent loop.
 type ErrorPayload struct {
 	Stage   string
@@ -35,6 +35,12 @@ func (r *hookRuntime) setMounted(names []string) {
 	r.mu.Unlock()
 }
 
+func (r *hookRuntime) addMounted(name string) {
+	r.mu.Lock()
+	r.mounted = append(r.mounted, name)
+	r.mu.Unlock()
+}
+
 func (r *hookRuntime) reset(al *AgentLoop) {
 	r.mu.Lock()
 	names := append([]string(nil), r.mounted...)
@@ -116,7 +122,26 @@ func (al *AgentLoop) ensureHooksInitialized(ctx context.Context) error {
 	}
 
 	al.hookRuntime.initOnce.Do(func() {
-		al.hookRuntime.setInitErr(al.loadConfiguredHooks(ctx))
+		if err := al.loadConfiguredHooks(ctx); err != nil {
+			al.hookRuntime.setInitErr(err)
+			return
+		}
+		name, err := al.mountToolTraceHook()
+		if err != nil {
+			al.hookRuntime.setInitErr(fmt.Errorf("mount tool trace hook: %w", err))
+			return
+		}
+		if name != "" {
+			al.hookRuntime.addMounted(name)
+		}
+		name, err = al.mountToolConfirmHook()
+		if err != nil {
+			al.hookRuntime.setInitErr(fmt.Errorf("mount tool confirm hook: %w", err))
+			return
+		}
+		if name != "" {
+			al.hookRuntime.addMounted(name)
+		}
 	})
 
 	return al.hookRuntime.getInitErr()
@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	toolConfirmHookName = "tool_confirm"
+
+	toolConfirmPollInterval = 100 * time.Millisecond
+)
+
+// toolConfirmHook asks for an explicit yes/no before a ConfirmableTool runs.
+// On a human channel the preview is sent to the chat and the next message in
+// the session is taken as the answer; on internal channels the configured
+// autonomous policy decides. Timeouts follow hooks.defaults.approval_timeout_ms
+// and deny the call.
+type toolConfirmHook struct {
+	al          *AgentLoop
+	autoApprove bool
+}
+
+func (h *toolConfirmHook) ApproveTool(ctx context.Context, req *ToolApprovalRequest) (ApprovalDecision, error) {
+	approved := ApprovalDecision{Approved: true}
+	if req == nil {
+		return approved, nil
+	}
+	ts := h.al.getActiveTurnState(req.Meta.SessionKey)
+	if ts == nil || ts.agent == nil || ts.agent.Tools == nil {
+		return approved, nil
+	}
+	preview := ts.agent.Tools.ConfirmationPreview(req.Tool, req.Arguments)
+	if preview == "" {
+		return approved, nil
+	}
+
+	if ts.channel == "" || constants.IsInternalChannel(ts.channel) {
+		if h.autoApprove {
+			return ApprovalDecision{Approved: true, Reason: "auto-approved: no human in the loop"}, nil
+		}
+		return ApprovalDecision{
+			Reason: fmt.Sprintf("%s requires confirmation and no human is in the loop", req.Tool),
+		}, nil
+	}
+
+	prompt := fmt.Sprintf("Confirm %s?\n\n%s\n\nReply \"yes\" to proceed or \"no\" to cancel.", req.Tool, preview)
+	if err := h.al.bus.PublishOutbound(ctx, outboundMessageForTurn(ts, prompt)); err != nil {
+		return ApprovalDecision{}, fmt.Errorf("send confirmation prompt: %w", err)
+	}
+
+	answer, err := h.waitForAnswer(ctx, ts.sessionKey, ts.agentID)
+	if err != nil {
+		return ApprovalDecision{}, err
+	}
+	switch parseConfirmAnswer(answer) {
+	case confirmYes:
+		return ApprovalDecision{Approved: true, Reason: "confirmed by user"}, nil
+	case confirmNo:
+		return ApprovalDecision{Reason: "user declined " + req.Tool}, nil
+	default:
+		// Not an answer: keep the message for the agent and treat the call as
+		// declined so the user's new instruction takes over.
+		_ = h.al.enqueueSteeringMessage(ts.sessionKey, ts.agentID, providers.Message{
+			Role:    "user",
+			Content: answer,
+		})
+		return ApprovalDecision{Reason: "user replied without confirming " + req.Tool}, nil
+	}
+}
+
+// waitForAnswer takes the next user message queued for the session while the
+// turn is blocked on the confirmation. Any further queued messages are put
+// back for the agent.
+func (h *toolConfirmHook) waitForAnswer(ctx context.Context, sessionKey, agentID string) (string, error) {
+	ticker := time.NewTicker(toolConfirmPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if msgs := h.al.dequeueSteeringMessagesForScope(sessionKey); len(msgs) > 0 {
+			for _, rest := range msgs[1:] {
+				_ = h.al.enqueueSteeringMessage(sessionKey, agentID, rest)
+			}
+			return msgs[0].Content, nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+type confirmAnswer int
+
+const (
+	confirmOther confirmAnswer = iota
+	confirmYes
+	confirmNo
+)
+
+func parseConfirmAnswer(s string) confirmAnswer {
+	switch strings.ToLower(strings.Trim(strings.TrimSpace(s), ".!")) {
+	case "y", "yes", "ok", "okay", "confirm", "approve", "proceed":
+		return confirmYes
+	case "n", "no", "cancel", "deny", "stop", "abort":
+		return confirmNo
+	default:
+		return confirmOther
+	}
+}
+
+// mountToolConfirmHook mounts the confirmation hook when tools.confirm is
+// enabled and reports the mounted hook name.
+func (al *AgentLoop) mountToolConfirmHook() (string, error) {
+	if al == nil || al.cfg == nil || !al.cfg.Tools.Confirm.Enabled {
+		return "", nil
+	}
+
+	policy := strings.ToLower(strings.TrimSpace(al.cfg.Tools.Confirm.Autonomous))
+	switch policy {
+	case "", "deny", "approve":
+	default:
+		logger.WarnCF("agent", "Unknown tools.confirm.autonomous policy, denying",
+			map[string]any{"policy": al.cfg.Tools.Confirm.Autonomous})
+	}
+
+	// Run after the other in-process approvers so a call they deny never
+	// prompts the user.
+	if err := al.MountHook(HookRegistration{
+		Name:     toolConfirmHookName,
+		Priority: math.MaxInt32,
+		Source:   HookSourceInProcess,
+		Hook:     &toolConfirmHook{al: al, autoApprove: policy == "approve"},
+	}); err != nil {
+		return "", err
+	}
+	return toolConfirmHookName, nil
+}
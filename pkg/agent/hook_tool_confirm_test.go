@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// confirmEchoTool is echo_text with a confirmation preview.
+type confirmEchoTool struct {
+	echoTextTool
+	ran bool
+}
+
+func (t *confirmEchoTool) ConfirmationPreview(args map[string]any) string {
+	text, _ := args["text"].(string)
+	return "echo " + text
+}
+
+func (t *confirmEchoTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	t.ran = true
+	return t.echoTextTool.Execute(ctx, args)
+}
+
+func runConfirmTurn(t *testing.T, channel, autonomous string, answer string) (string, *confirmEchoTool) {
+	t.Helper()
+	al, agent, cleanup := newHookTestLoop(t, &toolHookProvider{})
+	t.Cleanup(cleanup)
+
+	al.cfg.Tools.Confirm.Enabled = true
+	al.cfg.Tools.Confirm.Autonomous = autonomous
+	tool := &confirmEchoTool{}
+	al.RegisterTool(tool)
+	if err := al.ensureHooksInitialized(context.Background()); err != nil {
+		t.Fatalf("ensureHooksInitialized failed: %v", err)
+	}
+
+	if answer != "" {
+		go func() {
+			select {
+			case out := <-al.bus.OutboundChan():
+				if !strings.Contains(out.Content, "echo original") {
+					t.Errorf("confirmation prompt = %q, want preview", out.Content)
+				}
+			case <-time.After(5 * time.Second):
+				t.Error("no confirmation prompt was sent")
+				return
+			}
+			_ = al.enqueueSteeringMessage("confirm-session", agent.ID, providers.Message{
+				Role:    "user",
+				Content: answer,
+			})
+		}()
+	}
+
+	resp, err := al.runAgentLoop(context.Background(), agent, processOptions{
+		SessionKey:      "confirm-session",
+		Channel:         channel,
+		ChatID:          "chat-1",
+		UserMessage:     "run tool",
+		DefaultResponse: defaultResponse,
+	})
+	if err != nil {
+		t.Fatalf("runAgentLoop failed: %v", err)
+	}
+	return resp, tool
+}
+
+func TestToolConfirm_AutonomousDeniesByDefault(t *testing.T) {
+	resp, tool := runConfirmTurn(t, "system", "", "")
+	if tool.ran {
+		t.Fatal("confirmable tool ran without a human in the loop")
+	}
+	if !strings.Contains(resp, "no human is in the loop") {
+		t.Fatalf("response = %q, want denial reason", resp)
+	}
+}
+
+func TestToolConfirm_AutonomousApprovePolicy(t *testing.T) {
+	_, tool := runConfirmTurn(t, "system", "approve", "")
+	if !tool.ran {
+		t.Fatal("tool should run under the approve policy")
+	}
+}
+
+func TestToolConfirm_HumanReplies(t *testing.T) {
+	_, tool := runConfirmTurn(t, "telegram", "", "yes")
+	if !tool.ran {
+		t.Fatal("tool should run after the user confirmed")
+	}
+
+	resp, tool := runConfirmTurn(t, "telegram", "approve", "no")
+	if tool.ran {
+		t.Fatal("tool ran although the user declined")
+	}
+	if !strings.Contains(resp, "user declined echo_text") {
+		t.Fatalf("response = %q, want decline reason", resp)
+	}
+}
+
+func TestParseConfirmAnswer(t *testing.T) {
+	cases := map[string]confirmAnswer{
+		"yes":          confirmYes,
+		" Y ":          confirmYes,
+		"OK!":          confirmYes,
+		"no.":          confirmNo,
+		"cancel":       confirmNo,
+		"do X instead": confirmOther,
+	}
+	for in, want := range cases {
+		if got := parseConfirmAnswer(in); got != want {
+			t.Errorf("parseConfirmAnswer(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/tooltrace"
+)
+
+const toolTraceHookName = "tool_trace"
+
+// toolTraceHook records every tool invocation through a tooltrace.Recorder.
+// It runs ahead of other in-process interceptors so the trace holds the raw
+// tool output, and never alters the call or result.
+type toolTraceHook struct {
+	recorder *tooltrace.Recorder
+}
+
+func (h *toolTraceHook) BeforeTool(
+	_ context.Context,
+	call *ToolCallHookRequest,
+) (*ToolCallHookRequest, HookDecision, error) {
+	return call, HookDecision{Action: HookActionContinue}, nil
+}
+
+func (h *toolTraceHook) AfterTool(
+	_ context.Context,
+	resp *ToolResultHookResponse,
+) (*ToolResultHookResponse, HookDecision, error) {
+	if resp != nil {
+		rec := tooltrace.Record{
+			Time:       time.Now().Add(-resp.Duration),
+			SessionKey: resp.Meta.SessionKey,
+			AgentID:    resp.Meta.AgentID,
+			TurnID:     resp.Meta.TurnID,
+			Iteration:  resp.Meta.Iteration,
+			Tool:       resp.Tool,
+			Arguments:  resp.Arguments,
+			DurationMS: resp.Duration.Milliseconds(),
+		}
+		if r := resp.Result; r != nil {
+			rec.Result = r.ForLLM
+			rec.IsError = r.IsError
+			if r.Err != nil {
+				rec.Error = r.Err.Error()
+			}
+		}
+		h.recorder.Record(rec)
+	}
+	return resp, HookDecision{Action: HookActionContinue}, nil
+}
+
+func (h *toolTraceHook) Close() error {
+	return h.recorder.Close()
+}
+
+// mountToolTraceHook mounts the tool trace recorder when tools.trace is
+// enabled and reports the mounted hook name.
+func (al *AgentLoop) mountToolTraceHook() (string, error) {
+	if al == nil || al.cfg == nil || !al.cfg.Tools.Trace.Enabled {
+		return "", nil
+	}
+
+	dir := al.cfg.Tools.Trace.Dir
+	if dir == "" {
+		dir = filepath.Join(al.cfg.WorkspacePath(), "traces")
+	}
+
+	recorder := tooltrace.NewRecorder(dir)
+	if err := al.MountHook(HookRegistration{
+		Name:     toolTraceHookName,
+		Priority: math.MinInt32,
+		Source:   HookSourceInProcess,
+		Hook:     &toolTraceHook{recorder: recorder},
+	}); err != nil {
+		_ = recorder.Close()
+		return "", err
+	}
+	return toolTraceHookName, nil
+}
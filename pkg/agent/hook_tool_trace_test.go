@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/tooltrace"
+)
+
+func TestAgentLoop_ToolTraceRecordsToolCalls(t *testing.T) {
+	provider := &toolHookProvider{}
+	al, agent, cleanup := newHookTestLoop(t, provider)
+	defer cleanup()
+
+	traceDir := t.TempDir()
+	al.cfg.Tools.Trace.Enabled = true
+	al.cfg.Tools.Trace.Dir = traceDir
+
+	al.RegisterTool(&echoTextTool{})
+	if err := al.ensureHooksInitialized(context.Background()); err != nil {
+		t.Fatalf("ensureHooksInitialized failed: %v", err)
+	}
+
+	if _, err := al.runAgentLoop(context.Background(), agent, processOptions{
+		SessionKey:      "session-1",
+		Channel:         "cli",
+		ChatID:          "direct",
+		UserMessage:     "run tool",
+		DefaultResponse: defaultResponse,
+	}); err != nil {
+		t.Fatalf("runAgentLoop failed: %v", err)
+	}
+
+	// Unmounting closes the recorder, flushing queued records.
+	al.hookRuntime.reset(al)
+
+	records, err := tooltrace.LoadSession(traceDir, "session-1")
+	if err != nil {
+		t.Fatalf("LoadSession failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 trace record, got %d", len(records))
+	}
+	rec := records[0]
+	if rec.Tool != "echo_text" || rec.Result != "original" || rec.Arguments["text"] != "original" {
+		t.Fatalf("unexpected trace record: %+v", rec)
+	}
+	if rec.AgentID != agent.ID || rec.TurnID == "" {
+		t.Fatalf("trace record missing correlation fields: %+v", rec)
+	}
+}
+
+func TestAgentLoop_ToolTraceDisabledByDefault(t *testing.T) {
+	al, _, cleanup := newHookTestLoop(t, &toolHookProvider{})
+	defer cleanup()
+
+	name, err := al.mountToolTraceHook()
+	if err != nil || name != "" {
+		t.Fatalf("mountToolTraceHook() = %q, %v; want no hook when disabled", name, err)
+	}
+}
@@ -31,9 +31,11 @@ type AgentInstance struct {
 	MaxTokens                 int
 	Temperature               float64
 	ThinkingLevel             ThinkingLevel
+	ThinkingBudget            int
 	ContextWindow             int
 	SummarizeMessageThreshold int
 	SummarizeTokenPercent     int
+	Budget                    config.BudgetConfig
 	Provider                  providers.LLMProvider
 	Sessions                  session.SessionStore
 	ContextBuilder            *ContextBuilder
@@ -95,6 +97,17 @@ func NewAgentInstance(
 			toolsRegistry.Register(tools.NewReadFileBytesTool(workspace, readRestrict, maxReadFileSize, allowReadPaths))
 		}
 	}
+	if cfg.Tools.IsToolEnabled("tail_file") {
+		toolsRegistry.Register(tools.NewTailFileTool(
+			workspace, readRestrict, cfg.Tools.ReadFile.MaxReadFileSize, allowReadPaths))
+	}
+	if cfg.Tools.IsToolEnabled("grep") {
+		toolsRegistry.Register(tools.NewGrepTool(workspace, cfg.Tools.ReadFile.MaxReadFileSize))
+	}
+	if cfg.Tools.IsToolEnabled("diff") {
+		toolsRegistry.Register(tools.NewDiffTool(
+			workspace, readRestrict, cfg.Tools.ReadFile.MaxReadFileSize, allowReadPaths))
+	}
 	if cfg.Tools.IsToolEnabled("write_file") {
 		toolsRegistry.Register(tools.NewWriteFileTool(workspace, restrict, allowWritePaths))
 	}
@@ -117,6 +130,15 @@ func NewAgentInstance(
 	if cfg.Tools.IsToolEnabled("append_file") {
 		toolsRegistry.Register(tools.NewAppendFileTool(workspace, restrict, allowWritePaths))
 	}
+	if cfg.Tools.IsToolEnabled("memory") {
+		toolsRegistry.Register(tools.NewMemoryTool(workspace))
+	}
+	if cfg.Tools.IsToolEnabled("project") {
+		toolsRegistry.Register(tools.NewProjectTool(workspace))
+	}
+	if cfg.Tools.IsToolEnabled("encode") {
+		toolsRegistry.Register(tools.NewEncodeTool())
+	}
 
 	sessionsDir := filepath.Join(workspace, "sessions")
 	sessions := initSessionStore(sessionsDir)
@@ -168,8 +190,12 @@ func NewAgentInstance(
 	}
 
 	var thinkingLevelStr string
+	thinkingBudget := defaults.ThinkingBudget
 	if mc, err := cfg.GetModelConfig(model); err == nil {
 		thinkingLevelStr = mc.ThinkingLevel
+		if mc.ThinkingBudget > 0 {
+			thinkingBudget = mc.ThinkingBudget
+		}
 	}
 	thinkingLevel := parseThinkingLevel(thinkingLevelStr)
 
@@ -188,6 +214,7 @@ func NewAgentInstance(
 
 	candidateProviders := make(map[string]providers.LLMProvider)
 	populateCandidateProvidersFromNames(cfg, workspace, fallbacks, candidateProviders)
+	candidates = dropUnreachableFallbacks(candidates, candidateProviders)
 
 	// Model routing setup: pre-resolve light model candidates at creation time
 	// to avoid repeated model_list lookups on every incoming message.
@@ -232,9 +259,11 @@ func NewAgentInstance(
 		MaxTokens:                 maxTokens,
 		Temperature:               temperature,
 		ThinkingLevel:             thinkingLevel,
+		ThinkingBudget:            thinkingBudget,
 		ContextWindow:             contextWindow,
 		SummarizeMessageThreshold: summarizeMessageThreshold,
 		SummarizeTokenPercent:     summarizeTokenPercent,
+		Budget:                    defaults.Budget,
 		Provider:                  provider,
 		Sessions:                  sessions,
 		ContextBuilder:            contextBuilder,
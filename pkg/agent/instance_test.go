@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -494,6 +495,60 @@ func TestNewAgentInstance_CandidateProvidersPopulatedForCrossProviderFallbacks(t
 	}
 }
 
+// TestNewAgentInstance_FallbackChainFromConfig verifies that fallbacks are
+// resolved in order, deduplicated, and given a provider when they are
+// model_list entries on another provider.
+func TestNewAgentInstance_FallbackChainFromConfig(t *testing.T) {
+	workspace := t.TempDir()
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace: workspace,
+				ModelName: "mistral-small",
+				ModelFallbacks: []string{
+					"gemma",                   // model_list alias on another provider
+					"anthropic/claude-x",      // other provider, not configured
+					"openrouter/backup-model", // primary's provider, inherits its client
+					"gemma",                   // duplicate
+				},
+			},
+		},
+		ModelList: []*config.ModelConfig{
+			{
+				ModelName: "mistral-small",
+				Model:     "openrouter/mistralai/mistral-small",
+				APIKeys:   config.SimpleSecureStrings("sk-or-test"),
+				Workspace: workspace,
+			},
+			{
+				ModelName: "gemma",
+				Model:     "gemini/gemma-3-27b-it",
+				APIKeys:   config.SimpleSecureStrings("AIzaSy-test"),
+				Workspace: workspace,
+			},
+		},
+	}
+
+	agent := NewAgentInstance(nil, &cfg.Agents.Defaults, cfg, &mockProvider{})
+
+	var got []string
+	for _, c := range agent.Candidates {
+		got = append(got, providers.ModelKey(c.Provider, c.Model))
+	}
+	want := []string{
+		providers.ModelKey("openrouter", "mistralai/mistral-small"),
+		providers.ModelKey("gemini", "gemma-3-27b-it"),
+		providers.ModelKey("openrouter", "backup-model"),
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Candidates = %v, want %v", got, want)
+	}
+	if _, ok := agent.CandidateProviders[want[1]]; !ok {
+		t.Errorf("CandidateProviders missing %q", want[1])
+	}
+}
+
 func TestNewAgentInstance_ReadFileModeSelectsSchema(t *testing.T) {
 	workspace := t.TempDir()
 
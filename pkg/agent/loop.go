@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -23,6 +24,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/outputfilter"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/session"
@@ -42,18 +44,23 @@ type AgentLoop struct {
 	hooks    *HookManager
 
 	// Runtime state
-	running        atomic.Bool
-	contextManager ContextManager
-	fallback       *providers.FallbackChain
-	channelManager *channels.Manager
-	mediaStore     media.MediaStore
-	transcriber    asr.Transcriber
-	cmdRegistry    *commands.Registry
-	mcp            mcpRuntime
-	hookRuntime    hookRuntime
-	steering       *steeringQueue
-	pendingSkills  sync.Map
-	mu             sync.RWMutex
+	running         atomic.Bool
+	contextManager  ContextManager
+	fallback        *providers.FallbackChain
+	channelManager  *channels.Manager
+	mediaStore      media.MediaStore
+	transcriber     asr.Transcriber
+	cmdRegistry     *commands.Registry
+	mcp             mcpRuntime
+	hookRuntime     hookRuntime
+	steering        *steeringQueue
+	pendingSkills   sync.Map
+	sessionUsage    sync.Map
+	sessionSpend    sync.Map // session key -> *sessionSpend charged against the budget
+	sessionModels   sync.Map // session key -> *sessionModel
+	sessionThinking sync.Map // session key -> int thinking budget set with /think
+	outputFilters   atomic.Pointer[outputfilter.Chain]
+	mu              sync.RWMutex
 
 	// workerSem limits concurrent turn processing workers.
 	workerSem chan struct{}
@@ -64,8 +71,25 @@ type AgentLoop struct {
 
 	turnSeq        atomic.Uint64
 	activeRequests sync.WaitGroup
+	// inFlightTurns counts inbound messages accepted by Run that are still
+	// being processed; Drain waits for it to reach zero.
+	inFlightTurns atomic.Int64
+	// sessionsEvicted counts idle sessions released by evictIdleSessions.
+	sessionsEvicted atomic.Uint64
+	// abandonedToolCalls counts timed-out tool calls whose goroutine has not
+	// returned yet.
+	abandonedToolCalls atomic.Int64
+	// toolSlots enforces tools.max_concurrent and backs ToolConcurrency.
+	toolSlots toolConcurrency
+	// usage backs ChannelMessageCounts and ProviderStats.
+	usage usageStats
+	// retryQueue holds messages that failed during a provider outage; nil
+	// unless agents.defaults.retry_queue is enabled. It is rebuilt on reload.
+	retryQueue atomic.Pointer[retryQueue]
 
 	reloadFunc func() error
+	// configPath is the config file, when known; mcp_admin reloads from it.
+	configPath string
 
 	providerFactory func(*config.ModelConfig) (providers.LLMProvider, string, error)
 }
@@ -90,8 +114,10 @@ type processOptions struct {
 	EnableSummary           bool                   // Whether to trigger summarization
 	SendResponse            bool                   // Whether to send response via bus
 	AllowInterimPicoPublish bool                   // Whether pico tool-call interim text can be published when SendResponse is false
+	StreamResponse          bool                   // Whether the direct answer may be streamed to channels that support it
 	SuppressToolFeedback    bool                   // Whether to suppress inline tool feedback messages
 	NoHistory               bool                   // If true, don't load session history (for heartbeat)
+	NoTools                 bool                   // If true, send no tool definitions to the model (/chat)
 	SkipInitialSteeringPoll bool                   // If true, skip the steering poll at loop start (used by Continue)
 	InboundContext          *bus.InboundContext    // Normalized inbound facts for events/hooks
 	RouteResult             *routing.ResolvedRoute // Route decision snapshot for events/hooks
@@ -106,7 +132,6 @@ type continuationTarget struct {
 
 const (
 	defaultResponse            = "The model returned an empty response. This may indicate a provider error or token limit."
-	toolLimitResponse          = "I've reached `max_tool_iterations` without a final response. Increase `max_tool_iterations` in config.json if this task needs more tool steps."
 	handledToolResponseSummary = "Requested output delivered via tool attachment."
 	sessionKeyAgentPrefix      = "agent:"
 	pendingTurnPrefix          = "pending-"
@@ -132,6 +157,9 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 		return err
 	}
 
+	go al.runSessionEviction(ctx)
+	go al.runRetryQueue(ctx)
+
 	idleTicker := time.NewTicker(100 * time.Millisecond)
 	defer idleTicker.Stop()
 
@@ -154,7 +182,9 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 				// Non-routable message (e.g., system) — process immediately.
 				// Note: system messages are processed in the main goroutine,
 				// so they block the receive loop but guarantee session serialization.
+				al.inFlightTurns.Add(1)
 				al.processMessageSync(ctx, msg)
+				al.inFlightTurns.Add(-1)
 				continue
 			}
 
@@ -175,7 +205,7 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 					Content: msg.Content,
 					Media:   append([]string(nil), msg.Media...),
 				}); err != nil {
-					logger.WarnCF("agent", "Failed to enqueue steering message",
+					logger.WarnCtx(ctx, "agent", "Failed to enqueue steering message",
 						map[string]any{
 							"error":       err.Error(),
 							"channel":     msg.Channel,
@@ -189,14 +219,14 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 			// Session claimed — spawn a worker goroutine that acquires a semaphore
 			// slot. The goroutine is spawned immediately so the main loop keeps
 			// draining the inbound channel. The goroutine blocks on the semaphore.
+			al.inFlightTurns.Add(1)
 			go func(m bus.InboundMessage) {
-				// Acquire semaphore slot (blocks if at capacity)
-				select {
-				case al.workerSem <- struct{}{}:
-					// Got slot, start worker
-				case <-ctx.Done():
-					// Context canceled while waiting for a slot — clean up the
-					// placeholder to prevent session-level deadlock.
+				defer al.inFlightTurns.Add(-1)
+
+				// Acquire semaphore slot (waits up to turn_queue_timeout if at capacity)
+				if !al.acquireTurnSlot(ctx, m) {
+					// Canceled or rejected as busy — clean up the placeholder to
+					// prevent session-level deadlock.
 					al.activeTurnStates.Delete(sessionKey)
 					return
 				}
@@ -217,7 +247,7 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 				defer func() {
 					if r := recover(); r != nil {
 						logger.RecoverPanicNoExit(r)
-						logger.ErrorCF("agent", "Worker goroutine panicked",
+						logger.ErrorCtx(ctx, "agent", "Worker goroutine panicked",
 							map[string]any{
 								"session_key": sessionKey,
 								"channel":     m.Channel,
@@ -265,6 +295,38 @@ func (al *AgentLoop) Stop() {
 	al.running.Store(false)
 }
 
+// Drain stops accepting inbound messages and waits for turns already in
+// flight to finish. If ctx expires first, the sessions still running are
+// logged and ctx.Err() is returned.
+func (al *AgentLoop) Drain(ctx context.Context) error {
+	al.Stop()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for al.inFlightTurns.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			var sessions []string
+			al.activeTurnStates.Range(func(key, _ any) bool {
+				if sessionKey, ok := key.(string); ok {
+					sessions = append(sessions, sessionKey)
+				}
+				return true
+			})
+			sort.Strings(sessions)
+			logger.WarnCtx(ctx, "agent", "Drain timed out with turns still in flight",
+				map[string]any{
+					"in_flight": al.inFlightTurns.Load(),
+					"sessions":  sessions,
+				})
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
 // Close releases resources held by agent session stores. Call after Stop.
 func (al *AgentLoop) Close() {
 	mcpManager := al.mcp.takeManager()
@@ -279,6 +341,14 @@ func (al *AgentLoop) Close() {
 	}
 
 	al.GetRegistry().Close()
+	if al.state != nil {
+		if err := al.state.Flush(); err != nil {
+			logger.ErrorCF("agent", "Failed to save state",
+				map[string]any{
+					"error": err.Error(),
+				})
+		}
+	}
 	if al.hooks != nil {
 		al.hooks.Close()
 	}
@@ -331,7 +401,7 @@ func (al *AgentLoop) ReloadProviderAndConfig(
 			if r := recover(); r != nil {
 				logger.RecoverPanicNoExit(r)
 				panicErr = fmt.Errorf("panic during registry creation: %v", r)
-				logger.ErrorCF("agent", "Panic during registry creation",
+				logger.ErrorCtx(ctx, "agent", "Panic during registry creation",
 					map[string]any{"panic": r})
 			}
 			close(done)
@@ -379,24 +449,29 @@ func (al *AgentLoop) ReloadProviderAndConfig(
 		}
 	}
 	al.fallback = providers.NewFallbackChain(providers.NewCooldownTracker(), newRL)
+	al.fallback.SetHedgeDelay(cfg.Agents.Defaults.FallbackRace.HedgeDelay())
 
 	al.mu.Unlock()
 
+	al.configureOutputFilters(cfg)
+	al.configureRetryQueue(cfg)
+	al.resetSessionModels()
+
 	oldMCPManager := al.mcp.reset()
 	al.hookRuntime.reset(al)
 	configureHookManagerFromConfig(al.hooks, cfg)
 	if err := al.ensureHooksInitialized(ctx); err != nil {
-		logger.WarnCF("agent", "Configured hooks failed to reinitialize after reload",
+		logger.WarnCtx(ctx, "agent", "Configured hooks failed to reinitialize after reload",
 			map[string]any{"error": err.Error()})
 	}
 	if oldMCPManager != nil {
 		if err := oldMCPManager.Close(); err != nil {
-			logger.WarnCF("agent", "Failed to close previous MCP manager during reload",
+			logger.WarnCtx(ctx, "agent", "Failed to close previous MCP manager during reload",
 				map[string]any{"error": err.Error()})
 		}
 	}
 	if err := al.ensureMCPInitialized(ctx); err != nil {
-		logger.WarnCF("agent", "MCP failed to reinitialize after reload",
+		logger.WarnCtx(ctx, "agent", "MCP failed to reinitialize after reload",
 			map[string]any{"error": err.Error()})
 	}
 
@@ -411,14 +486,14 @@ func (al *AgentLoop) ReloadProviderAndConfig(
 				stateful.Close()
 			case <-ctx.Done():
 				// Context canceled, close immediately but log warning
-				logger.WarnCF("agent", "Context canceled during provider cleanup, forcing close",
+				logger.WarnCtx(ctx, "agent", "Context canceled during provider cleanup, forcing close",
 					map[string]any{"error": ctx.Err()})
 				stateful.Close()
 			}
 		}
 	}
 
-	logger.InfoCF("agent", "Provider and config reloaded successfully",
+	logger.InfoCtx(ctx, "agent", "Provider and config reloaded successfully",
 		map[string]any{
 			"model": cfg.Agents.Defaults.GetModelName(),
 		})
@@ -466,6 +541,7 @@ func (al *AgentLoop) runAgentLoop(
 	agent *AgentInstance,
 	opts processOptions,
 ) (string, error) {
+	ctx = logger.EnsureTraceID(ctx)
 	opts = normalizeProcessOptions(opts)
 
 	// Record last channel for heartbeat notifications (skip internal channels and cli)
@@ -474,7 +550,8 @@ func (al *AgentLoop) runAgentLoop(
 		!constants.IsInternalChannel(opts.Dispatch.Channel()) {
 		channelKey := fmt.Sprintf("%s:%s", opts.Dispatch.Channel(), opts.Dispatch.ChatID())
 		if err := al.RecordLastChannel(channelKey); err != nil {
-			logger.WarnCF(
+			logger.WarnCtx(
+				ctx,
 				"agent",
 				"Failed to record last channel",
 				map[string]any{"error": err.Error()},
@@ -505,7 +582,7 @@ func (al *AgentLoop) runAgentLoop(
 
 	for _, followUp := range result.followUps {
 		if pubErr := al.bus.PublishInbound(ctx, followUp); pubErr != nil {
-			logger.WarnCF("agent", "Failed to publish follow-up after turn",
+			logger.WarnCtx(ctx, "agent", "Failed to publish follow-up after turn",
 				map[string]any{
 					"turn_id": ts.turnID,
 					"error":   pubErr.Error(),
@@ -535,7 +612,7 @@ func (al *AgentLoop) runAgentLoop(
 
 	if result.finalContent != "" {
 		responsePreview := utils.Truncate(result.finalContent, 120)
-		logger.InfoCF("agent", fmt.Sprintf("Response: %s", responsePreview),
+		logger.InfoCtx(ctx, "agent", fmt.Sprintf("Response: %s", responsePreview),
 			map[string]any{
 				"agent_id":     agent.ID,
 				"session_key":  opts.Dispatch.SessionKey,
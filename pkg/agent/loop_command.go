@@ -9,6 +9,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
@@ -24,7 +25,11 @@ func (al *AgentLoop) handleCommand(
 		return "", false
 	}
 
-	if matched, handled, reply := al.applyExplicitSkillCommand(msg.Content, agent, opts); matched {
+	locale := al.localeFor(msg.Channel, msg.Sender.Language)
+	if matched, handled, reply := al.applyExplicitSkillCommand(msg.Content, locale, agent, opts); matched {
+		return reply, handled
+	}
+	if matched, handled, reply := applyChatCommand(msg.Content, opts); matched {
 		return reply, handled
 	}
 
@@ -33,6 +38,7 @@ func (al *AgentLoop) handleCommand(
 	}
 
 	rt := al.buildCommandsRuntime(ctx, agent, opts)
+	rt.WelcomeMessage = func() string { return al.welcomeMessage(agent, msg) }
 	executor := commands.NewExecutor(al.cmdRegistry, rt)
 
 	var commandReply string
@@ -41,6 +47,7 @@ func (al *AgentLoop) handleCommand(
 		ChatID:   msg.ChatID,
 		SenderID: msg.SenderID,
 		Text:     msg.Content,
+		Locale:   locale,
 		Reply: func(text string) error {
 			commandReply = text
 			return nil
@@ -50,7 +57,7 @@ func (al *AgentLoop) handleCommand(
 	switch result.Outcome {
 	case commands.OutcomeHandled:
 		if result.Err != nil {
-			return mapCommandError(result), true
+			return mapCommandError(locale, result), true
 		}
 		if commandReply != "" {
 			return commandReply, true
@@ -61,8 +68,17 @@ func (al *AgentLoop) handleCommand(
 	}
 }
 
+// RegisterCommand adds a slash command that is handled before the message
+// reaches the model. Built-in commands cannot be replaced.
+func (al *AgentLoop) RegisterCommand(def commands.Definition) error {
+	if al.cmdRegistry == nil {
+		return fmt.Errorf("command registry not initialized")
+	}
+	return al.cmdRegistry.Register(def)
+}
+
 func (al *AgentLoop) applyExplicitSkillCommand(
-	raw string,
+	raw, locale string,
 	agent *AgentInstance,
 	opts *processOptions,
 ) (matched bool, handled bool, reply string) {
@@ -74,12 +90,12 @@ func (al *AgentLoop) applyExplicitSkillCommand(
 	}
 
 	if agent == nil || agent.ContextBuilder == nil {
-		return true, true, commandsUnavailableSkillMessage()
+		return true, true, i18n.Text(locale, i18n.UseUnavailable)
 	}
 
 	parts := strings.Fields(strings.TrimSpace(raw))
 	if len(parts) < 2 {
-		return true, true, buildUseCommandHelp(agent)
+		return true, true, buildUseCommandHelp(locale, agent)
 	}
 
 	arg := strings.TrimSpace(parts[1])
@@ -87,28 +103,25 @@ func (al *AgentLoop) applyExplicitSkillCommand(
 		if opts != nil {
 			al.clearPendingSkills(opts.Dispatch.SessionKey)
 		}
-		return true, true, "Cleared pending skill override."
+		return true, true, i18n.Text(locale, i18n.UseCleared)
 	}
 
 	skillName, ok := agent.ContextBuilder.ResolveSkillName(arg)
 	if !ok {
-		return true, true, fmt.Sprintf("Unknown skill: %s\nUse /list skills to see installed skills.", arg)
+		return true, true, i18n.Sprintf(locale, i18n.UseUnknownSkill, arg)
 	}
 
 	if len(parts) < 3 {
 		if opts == nil || strings.TrimSpace(opts.Dispatch.SessionKey) == "" {
-			return true, true, commandsUnavailableSkillMessage()
+			return true, true, i18n.Text(locale, i18n.UseUnavailable)
 		}
 		al.setPendingSkills(opts.Dispatch.SessionKey, []string{skillName})
-		return true, true, fmt.Sprintf(
-			"Skill %q is armed for your next message. Send your next prompt normally, or use /use clear to cancel.",
-			skillName,
-		)
+		return true, true, i18n.Sprintf(locale, i18n.UseArmed, skillName)
 	}
 
 	message := strings.TrimSpace(strings.Join(parts[2:], " "))
 	if message == "" {
-		return true, true, buildUseCommandHelp(agent)
+		return true, true, buildUseCommandHelp(locale, agent)
 	}
 
 	if opts != nil {
@@ -120,6 +133,28 @@ func (al *AgentLoop) applyExplicitSkillCommand(
 	return true, false, ""
 }
 
+// applyChatCommand turns "/chat <message>" into a normal turn for message that
+// sends no tools to the model.
+func applyChatCommand(raw string, opts *processOptions) (matched bool, handled bool, reply string) {
+	cmdName, ok := commands.CommandName(raw)
+	if !ok || cmdName != "chat" {
+		return false, false, ""
+	}
+
+	raw = strings.TrimSpace(raw)
+	message := strings.TrimSpace(raw[len(strings.Fields(raw)[0]):])
+	if message == "" {
+		return true, true, "Usage: /chat <message>"
+	}
+
+	if opts != nil {
+		opts.NoTools = true
+		opts.Dispatch.UserMessage = message
+		opts.UserMessage = message
+	}
+	return true, false, ""
+}
+
 func (al *AgentLoop) buildCommandsRuntime(
 	ctx context.Context,
 	agent *AgentInstance,
@@ -195,6 +230,10 @@ func (al *AgentLoop) buildCommandsRuntime(
 			agent.Provider = nextProvider
 			agent.Candidates = nextCandidates
 			agent.ThinkingLevel = parseThinkingLevel(modelCfg.ThinkingLevel)
+			agent.ThinkingBudget = cfg.Agents.Defaults.ThinkingBudget
+			if modelCfg.ThinkingBudget > 0 {
+				agent.ThinkingBudget = modelCfg.ThinkingBudget
+			}
 
 			if oldProvider != nil && oldProvider != nextProvider {
 				if stateful, ok := oldProvider.(providers.StatefulProvider); ok {
@@ -204,11 +243,40 @@ func (al *AgentLoop) buildCommandsRuntime(
 			return oldModel, nil
 		}
 
+		if agent.Tools != nil {
+			rt.ListToolNames = agent.Tools.List
+		}
 		rt.ClearHistory = func() error {
 			if opts == nil {
 				return fmt.Errorf("process options not available")
 			}
-			return al.contextManager.Clear(ctx, opts.SessionKey)
+			return al.clearSession(ctx, agent, opts.SessionKey)
+		}
+		rt.GetThinking = func() (string, int) {
+			var sessionKey string
+			if opts != nil {
+				sessionKey = opts.Dispatch.SessionKey
+			}
+			level, budget := al.thinkingFor(agent, sessionKey)
+			return string(level), budget
+		}
+		rt.SetThinkingBudget = func(budget int) {
+			if opts != nil && opts.Dispatch.SessionKey != "" {
+				al.setSessionThinkingBudget(opts.Dispatch.SessionKey, budget)
+			}
+		}
+		rt.ThinkingSupported = func() bool {
+			var sessionKey string
+			if opts != nil {
+				sessionKey = opts.Dispatch.SessionKey
+			}
+			return providerSupportsThinking(al.sessionProvider(agent, sessionKey))
+		}
+		rt.GetSessionUsage = func() commands.TokenUsage {
+			if opts == nil {
+				return commands.TokenUsage{}
+			}
+			return al.SessionUsage(opts.Dispatch.SessionKey)
 		}
 
 		rt.AskSideQuestion = func(ctx context.Context, question string) (string, error) {
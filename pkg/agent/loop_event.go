@@ -155,6 +155,10 @@ func (al *AgentLoop) logEvent(evt Event) {
 		fields["target_channel"] = payload.TargetChannel
 		fields["target_chat_id"] = payload.TargetChatID
 		fields["content_len"] = payload.ContentLen
+	case ToolLimitReachedPayload:
+		fields["iterations_total"] = payload.Iterations
+		fields["max_iterations"] = payload.MaxIterations
+		fields["partial_len"] = payload.PartialContentLen
 	case ErrorPayload:
 		fields["stage"] = payload.Stage
 		fields["error"] = payload.Message
@@ -13,6 +13,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/commands"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/outputfilter"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/state"
@@ -38,6 +39,7 @@ func NewAgentLoop(
 		}
 	}
 	fallbackChain := providers.NewFallbackChain(cooldown, rl)
+	fallbackChain.SetHedgeDelay(cfg.Agents.Defaults.FallbackRace.HedgeDelay())
 
 	// Create state manager using default agent's workspace for channel recording
 	defaultAgent := registry.GetDefaultAgent()
@@ -66,9 +68,11 @@ func NewAgentLoop(
 		workerSem:   make(chan struct{}, workerPoolSize),
 	}
 	al.providerFactory = providers.CreateProviderFromConfig
+	al.configureRetryQueue(cfg)
 	al.hooks = NewHookManager(eventBus)
 	configureHookManagerFromConfig(al.hooks, cfg)
 	al.contextManager = al.resolveContextManager()
+	al.configureOutputFilters(cfg)
 
 	// Register shared tools to all agents (now that al is created)
 	registerSharedTools(al, cfg, msgBus, registry, provider)
@@ -76,6 +80,29 @@ func NewAgentLoop(
 	return al
 }
 
+// configureOutputFilters builds the output filter chain from cfg and installs
+// it on the bus, where it covers every outbound message: final replies,
+// interim text and messages sent by tools. It runs again on config reload.
+func (al *AgentLoop) configureOutputFilters(cfg *config.Config) {
+	filters, err := outputfilter.FromConfig(cfg.Agents.Defaults.OutputFilters)
+	if err != nil {
+		logger.ErrorCF("agent", "Invalid output filters; replies will not be filtered",
+			map[string]any{"error": err.Error()})
+		filters = nil
+	} else if filters.Len() == 0 {
+		filters = nil
+	}
+	al.outputFilters.Store(filters)
+	if al.bus == nil {
+		return
+	}
+	if filters == nil {
+		al.bus.SetOutboundFilter(nil)
+	} else {
+		al.bus.SetOutboundFilter(filters.Apply)
+	}
+}
+
 func registerSharedTools(
 	al *AgentLoop,
 	cfg *config.Config,
@@ -91,6 +118,15 @@ func registerSharedTools(
 			logger.WarnCF("voice-tts", "send_tts enabled but no TTS provider configured", nil)
 		}
 	}
+	var imageGenOpts *tools.ImageGenToolOptions
+	if cfg.Tools.IsToolEnabled("image_gen") {
+		opts, err := imageGenToolOptions(cfg)
+		if err != nil {
+			logger.WarnCF("agent", "image_gen enabled but not usable", map[string]any{"error": err.Error()})
+		} else {
+			imageGenOpts = &opts
+		}
+	}
 
 	for _, agentID := range registry.ListAgentIDs() {
 		agent, ok := registry.GetAgent(agentID)
@@ -101,6 +137,7 @@ func registerSharedTools(
 		if cfg.Tools.IsToolEnabled("web") {
 			searchTool, err := tools.NewWebSearchTool(tools.WebSearchToolOptions{
 				BraveAPIKeys:          cfg.Tools.Web.Brave.APIKeys.Values(),
+				BraveBaseURL:          cfg.Tools.Web.Brave.BaseURL,
 				BraveMaxResults:       cfg.Tools.Web.Brave.MaxResults,
 				BraveEnabled:          cfg.Tools.Web.Brave.Enabled,
 				TavilyAPIKeys:         cfg.Tools.Web.Tavily.APIKeys.Values(),
@@ -134,7 +171,7 @@ func registerSharedTools(
 		}
 		if cfg.Tools.IsToolEnabled("web_fetch") {
 			fetchTool, err := tools.NewWebFetchToolWithProxy(
-				50000,
+				cfg.Tools.Web.FetchMaxChars,
 				cfg.Tools.Web.Proxy,
 				cfg.Tools.Web.Format,
 				cfg.Tools.Web.FetchLimitBytes,
@@ -145,6 +182,18 @@ func registerSharedTools(
 				agent.Tools.Register(fetchTool)
 			}
 		}
+		if cfg.Tools.IsToolEnabled("http_request") {
+			httpTool, err := tools.NewHTTPRequestTool(tools.HTTPRequestToolOptions{
+				Proxy:                cfg.Tools.Web.Proxy,
+				PrivateHostWhitelist: cfg.Tools.Web.PrivateHostWhitelist,
+				MaxChars:             cfg.Tools.Web.FetchMaxChars,
+			})
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create http_request tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(httpTool)
+			}
+		}
 
 		// Hardware tools (I2C, SPI) - Linux only, returns error on other platforms
 		if cfg.Tools.IsToolEnabled("i2c") {
@@ -200,6 +249,14 @@ func registerSharedTools(
 			agent.Tools.Register(reactionTool)
 		}
 
+		if cfg.Tools.IsToolEnabled("mcp_admin") {
+			agent.Tools.Register(tools.NewMCPAdminTool(mcpAdmin{al: al}))
+		}
+
+		if cfg.Tools.IsToolEnabled("model") {
+			agent.Tools.Register(tools.NewModelTool(sessionModelSwitcher{al: al, agent: agent}))
+		}
+
 		// Send file tool (outbound media via MediaStore — store injected later by SetMediaStore)
 		if cfg.Tools.IsToolEnabled("send_file") {
 			sendFileTool := tools.NewSendFileTool(
@@ -216,6 +273,17 @@ func registerSharedTools(
 			agent.Tools.Register(tools.NewSendTTSTool(ttsProvider, nil))
 		}
 
+		if imageGenOpts != nil {
+			opts := *imageGenOpts
+			opts.Workspace = agent.Workspace
+			imageTool, err := tools.NewImageGenTool(opts, nil)
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create image_gen tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(imageTool)
+			}
+		}
+
 		if cfg.Tools.IsToolEnabled("load_image") {
 			loadImageTool := tools.NewLoadImageTool(
 				agent.Workspace,
@@ -351,3 +419,27 @@ func registerSharedTools(
 		}
 	}
 }
+
+// imageGenToolOptions resolves tools.image_gen.model_name against model_list.
+// The tool registration fills in the per-agent workspace.
+func imageGenToolOptions(cfg *config.Config) (tools.ImageGenToolOptions, error) {
+	name := cfg.Tools.ImageGen.ModelName
+	if name == "" {
+		return tools.ImageGenToolOptions{}, fmt.Errorf("tools.image_gen.model_name is not set")
+	}
+	mc, err := cfg.GetModelConfig(name)
+	if err != nil {
+		return tools.ImageGenToolOptions{}, err
+	}
+	mc = mc.WithEnvExpanded()
+	protocol, modelID := providers.ExtractProtocol(mc.Model)
+	return tools.ImageGenToolOptions{
+		Protocol:          protocol,
+		APIBase:           providers.ResolveAPIBase(mc),
+		APIKey:            mc.APIKey(),
+		Model:             modelID,
+		Proxy:             mc.Proxy,
+		Size:              cfg.Tools.ImageGen.Size,
+		MaxPerUserPerHour: cfg.Tools.ImageGen.MaxPerUserPerHour,
+	}, nil
+}
@@ -21,6 +21,9 @@ func (al *AgentLoop) RegisterTool(tool tools.Tool) {
 
 func (al *AgentLoop) SetChannelManager(cm *channels.Manager) {
 	al.channelManager = cm
+	if cm != nil {
+		cm.SetTurnCanceler(al.CancelChatTurns)
+	}
 }
 
 func (al *AgentLoop) GetRegistry() *AgentRegistry {
@@ -60,6 +63,11 @@ func (al *AgentLoop) SetReloadFunc(fn func() error) {
 	al.reloadFunc = fn
 }
 
+// SetConfigPath tells the loop which file its config was loaded from.
+func (al *AgentLoop) SetConfigPath(path string) {
+	al.configPath = path
+}
+
 func (al *AgentLoop) RecordLastChannel(channel string) error {
 	if al.state == nil {
 		return nil
@@ -122,40 +122,7 @@ func (al *AgentLoop) ensureMCPInitialized(ctx context.Context) error {
 
 		for serverName, conn := range servers {
 			uniqueTools += len(conn.Tools)
-
-			// Determine whether this server's tools should be deferred (hidden).
-			// Per-server "deferred" field takes precedence over the global Discovery.Enabled.
-			serverCfg := al.cfg.Tools.MCP.Servers[serverName]
-			registerAsHidden := serverIsDeferred(al.cfg.Tools.MCP.Discovery.Enabled, serverCfg)
-
-			for _, tool := range conn.Tools {
-				for _, agentID := range agentIDs {
-					agent, ok := al.registry.GetAgent(agentID)
-					if !ok {
-						continue
-					}
-
-					mcpTool := tools.NewMCPTool(mcpManager, serverName, tool)
-					mcpTool.SetWorkspace(agent.Workspace)
-					mcpTool.SetMaxInlineTextRunes(al.cfg.Tools.MCP.GetMaxInlineTextChars())
-
-					if registerAsHidden {
-						agent.Tools.RegisterHidden(mcpTool)
-					} else {
-						agent.Tools.Register(mcpTool)
-					}
-
-					totalRegistrations++
-					logger.DebugCF("agent", "Registered MCP tool",
-						map[string]any{
-							"agent_id": agentID,
-							"server":   serverName,
-							"tool":     tool.Name,
-							"name":     mcpTool.Name(),
-							"deferred": registerAsHidden,
-						})
-				}
-			}
+			totalRegistrations += al.registerMCPServerTools(mcpManager, al.cfg.Tools.MCP, serverName, conn)
 		}
 		logger.InfoCF("agent", "MCP tools registered successfully",
 			map[string]any{
@@ -213,12 +180,81 @@ func (al *AgentLoop) ensureMCPInitialized(ctx context.Context) error {
 			}
 		}
 
+		// Servers may add or drop tools at runtime; mirror that in every agent.
+		mcpManager.SetToolsChangedHandler(func(serverName string) {
+			al.syncMCPServerTools(mcpManager, al.GetConfig().Tools.MCP, serverName)
+		})
+
 		al.mcp.setManager(mcpManager)
 	})
 
 	return al.mcp.getInitErr()
 }
 
+// registerMCPServerTools registers conn's tools with every agent and returns
+// the number of registrations.
+func (al *AgentLoop) registerMCPServerTools(
+	manager *mcp.Manager,
+	mcpCfg config.MCPConfig,
+	serverName string,
+	conn *mcp.ServerConnection,
+) int {
+	// Determine whether this server's tools should be deferred (hidden).
+	// Per-server "deferred" field takes precedence over the global Discovery.Enabled.
+	registerAsHidden := serverIsDeferred(mcpCfg.Discovery.Enabled, mcpCfg.Servers[serverName])
+	registry := al.GetRegistry()
+
+	registrations := 0
+	for _, tool := range conn.Tools {
+		for _, agentID := range registry.ListAgentIDs() {
+			agent, ok := registry.GetAgent(agentID)
+			if !ok {
+				continue
+			}
+
+			mcpTool := tools.NewMCPTool(manager, serverName, tool)
+			mcpTool.SetWorkspace(agent.Workspace)
+			mcpTool.SetMaxInlineTextRunes(mcpCfg.GetMaxInlineTextChars())
+
+			if registerAsHidden {
+				agent.Tools.RegisterHidden(mcpTool)
+			} else {
+				agent.Tools.Register(mcpTool)
+			}
+
+			registrations++
+			logger.DebugCF("agent", "Registered MCP tool",
+				map[string]any{
+					"agent_id": agentID,
+					"server":   serverName,
+					"tool":     tool.Name,
+					"name":     mcpTool.Name(),
+					"deferred": registerAsHidden,
+				})
+		}
+	}
+	return registrations
+}
+
+// syncMCPServerTools replaces every agent's tools for serverName with the
+// tools the server currently offers (none if it is not connected).
+func (al *AgentLoop) syncMCPServerTools(manager *mcp.Manager, mcpCfg config.MCPConfig, serverName string) {
+	registry := al.GetRegistry()
+	for _, agentID := range registry.ListAgentIDs() {
+		agent, ok := registry.GetAgent(agentID)
+		if !ok {
+			continue
+		}
+		agent.Tools.UnregisterFunc(func(tool tools.Tool) bool {
+			mt, ok := tool.(*tools.MCPTool)
+			return ok && mt.ServerName() == serverName
+		})
+	}
+	if conn, ok := manager.GetServer(serverName); ok {
+		al.registerMCPServerTools(manager, mcpCfg, serverName, conn)
+	}
+}
+
 // serverIsDeferred reports whether an MCP server's tools should be registered
 // as hidden (deferred/discovery mode).
 //
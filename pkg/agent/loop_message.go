@@ -104,6 +104,7 @@ func (al *AgentLoop) ProcessHeartbeat(
 
 func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage) (string, error) {
 	msg = bus.NormalizeInboundMessage(msg)
+	ctx = logger.EnsureTraceID(logger.WithTraceID(ctx, msg.TraceID))
 
 	// Add message preview to log (show full content for error messages)
 	var logContent string
@@ -112,7 +113,8 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 	} else {
 		logContent = utils.Truncate(msg.Content, 80)
 	}
-	logger.InfoCF(
+	logger.InfoCtx(
+		ctx,
 		"agent",
 		fmt.Sprintf("Processing message from %s:%s: %s", msg.Channel, msg.SenderID, logContent),
 		map[string]any{
@@ -123,6 +125,8 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		},
 	)
 
+	al.usage.recordReceived(msg.Channel)
+
 	var hadAudio bool
 	msg, hadAudio = al.transcribeAudioInMessage(ctx, msg)
 
@@ -156,7 +160,7 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		}
 	}
 
-	logger.InfoCF("agent", "Routed message",
+	logger.InfoCtx(ctx, "agent", "Routed message",
 		map[string]any{
 			"agent_id":           agent.ID,
 			"scope_key":          scopeKey,
@@ -183,8 +187,11 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		EnableSummary:           true,
 		SendResponse:            false,
 		AllowInterimPicoPublish: true,
+		StreamResponse:          al.outputFilters.Load() == nil, // streamed text would bypass output filters
 	}
 
+	al.greetNewSender(ctx, agent, msg)
+
 	// context-dependent commands check their own Runtime fields and report
 	// "unavailable" when the required capability is nil.
 	if response, handled := al.handleCommand(ctx, msg, agent, &opts); handled {
@@ -193,7 +200,7 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 
 	if pending := al.takePendingSkills(opts.Dispatch.SessionKey); len(pending) > 0 {
 		opts.ForcedSkills = append(opts.ForcedSkills, pending...)
-		logger.InfoCF("agent", "Applying pending skill override",
+		logger.InfoCtx(ctx, "agent", "Applying pending skill override",
 			map[string]any{
 				"session_key": opts.Dispatch.SessionKey,
 				"skills":      strings.Join(pending, ","),
@@ -238,7 +245,7 @@ func (al *AgentLoop) processSystemMessage(
 		)
 	}
 
-	logger.InfoCF("agent", "Processing system message",
+	logger.InfoCtx(ctx, "agent", "Processing system message",
 		map[string]any{
 			"sender_id": msg.SenderID,
 			"chat_id":   msg.ChatID,
@@ -263,7 +270,7 @@ func (al *AgentLoop) processSystemMessage(
 
 	// Skip internal channels - only log, don't send to user
 	if constants.IsInternalChannel(originChannel) {
-		logger.InfoCF("agent", "Subagent completed (internal channel)",
+		logger.InfoCtx(ctx, "agent", "Subagent completed (internal channel)",
 			map[string]any{
 				"sender_id":   msg.SenderID,
 				"content_len": len(content),
@@ -5,7 +5,6 @@ package agent
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
@@ -17,7 +16,7 @@ func (al *AgentLoop) maybePublishError(ctx context.Context, channel, chatID, ses
 	if errors.Is(err, context.Canceled) {
 		return false
 	}
-	al.PublishResponseIfNeeded(ctx, channel, chatID, sessionKey, fmt.Sprintf("Error processing message: %v", err))
+	al.PublishResponseIfNeeded(ctx, channel, chatID, sessionKey, al.errorReply(ctx, channel, err))
 	return true
 }
 
@@ -41,18 +40,9 @@ func (al *AgentLoop) PublishResponseIfNeeded(ctx context.Context, channel, chatI
 		return
 	}
 
-	alreadySentToSameChat := false
-	defaultAgent := al.GetRegistry().GetDefaultAgent()
-	if defaultAgent != nil {
-		if tool, ok := defaultAgent.Tools.Get("message"); ok {
-			if mt, ok := tool.(*tools.MessageTool); ok {
-				alreadySentToSameChat = mt.HasSentTo(sessionKey, channel, chatID)
-			}
-		}
-	}
-
-	if alreadySentToSameChat {
-		logger.DebugCF(
+	if al.messageToolSentTo(sessionKey, channel, chatID) {
+		logger.DebugCtx(
+			ctx,
 			"agent",
 			"Skipped outbound (message tool already sent to same chat)",
 			map[string]any{"channel": channel, "chat_id": chatID},
@@ -64,7 +54,7 @@ func (al *AgentLoop) PublishResponseIfNeeded(ctx context.Context, channel, chatI
 		Context: bus.NewOutboundContext(channel, chatID, ""),
 		Content: response,
 	})
-	logger.InfoCF("agent", "Published outbound response",
+	logger.InfoCtx(ctx, "agent", "Published outbound response",
 		map[string]any{
 			"channel":     channel,
 			"chat_id":     chatID,
@@ -72,6 +62,21 @@ func (al *AgentLoop) PublishResponseIfNeeded(ctx context.Context, channel, chatI
 		})
 }
 
+// messageToolSentTo reports whether the message tool already delivered this
+// turn's reply to the chat, in which case the final response is not sent.
+func (al *AgentLoop) messageToolSentTo(sessionKey, channel, chatID string) bool {
+	defaultAgent := al.GetRegistry().GetDefaultAgent()
+	if defaultAgent == nil {
+		return false
+	}
+	if tool, ok := defaultAgent.Tools.Get("message"); ok {
+		if mt, ok := tool.(*tools.MessageTool); ok {
+			return mt.HasSentTo(sessionKey, channel, chatID)
+		}
+	}
+	return false
+}
+
 func (al *AgentLoop) targetReasoningChannelID(channelName string) (chatID string) {
 	if al.channelManager == nil {
 		return ""
@@ -106,12 +111,12 @@ func (al *AgentLoop) publishPicoReasoning(ctx context.Context, reasoningContent,
 	}); err != nil {
 		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) ||
 			errors.Is(err, bus.ErrBusClosed) {
-			logger.DebugCF("agent", "Pico reasoning publish skipped (timeout/cancel)", map[string]any{
+			logger.DebugCtx(ctx, "agent", "Pico reasoning publish skipped (timeout/cancel)", map[string]any{
 				"channel": "pico",
 				"error":   err.Error(),
 			})
 		} else {
-			logger.WarnCF("agent", "Failed to publish pico reasoning (best-effort)", map[string]any{
+			logger.WarnCtx(ctx, "agent", "Failed to publish pico reasoning (best-effort)", map[string]any{
 				"channel": "pico",
 				"error":   err.Error(),
 			})
@@ -151,12 +156,12 @@ func (al *AgentLoop) handleReasoning(
 		// shutdown when the bus is closed before all goroutines finish.
 		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) ||
 			errors.Is(err, bus.ErrBusClosed) {
-			logger.DebugCF("agent", "Reasoning publish skipped (timeout/cancel)", map[string]any{
+			logger.DebugCtx(ctx, "agent", "Reasoning publish skipped (timeout/cancel)", map[string]any{
 				"channel": channelName,
 				"error":   err.Error(),
 			})
 		} else {
-			logger.WarnCF("agent", "Failed to publish reasoning (best-effort)", map[string]any{
+			logger.WarnCtx(ctx, "agent", "Failed to publish reasoning (best-effort)", map[string]any{
 				"channel": channelName,
 				"error":   err.Error(),
 			})
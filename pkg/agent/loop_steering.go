@@ -6,21 +6,33 @@ import (
 	"context"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
 func (al *AgentLoop) processMessageSync(ctx context.Context, msg bus.InboundMessage) {
+	ctx = logger.WithTraceID(ctx, msg.TraceID)
+	ctx = i18n.WithLocale(ctx, al.localeFor(msg.Channel, msg.Sender.Language))
 	if al.channelManager != nil {
 		defer al.channelManager.InvokeTypingStop(msg.Channel, msg.ChatID)
 	}
 
 	response, err := al.processMessage(ctx, msg)
+	if al.settleRetry(ctx, msg, err) {
+		return
+	}
 	al.publishResponseOrError(ctx, msg.Channel, msg.ChatID, msg.SessionKey, response, err)
 }
 
 func (al *AgentLoop) runTurnWithSteering(ctx context.Context, initialMsg bus.InboundMessage) {
+	// Steering continuations share the initial message's trace ID.
+	ctx = logger.WithTraceID(ctx, initialMsg.TraceID)
+	ctx = i18n.WithLocale(ctx, al.localeFor(initialMsg.Channel, initialMsg.Sender.Language))
 	// Process the initial message
 	response, err := al.processMessage(ctx, initialMsg)
+	if al.settleRetry(ctx, initialMsg, err) {
+		err = nil
+	}
 	if err != nil {
 		if !al.maybePublishError(ctx, initialMsg.Channel, initialMsg.ChatID, initialMsg.SessionKey, err) {
 			return // context canceled
@@ -32,7 +44,7 @@ func (al *AgentLoop) runTurnWithSteering(ctx context.Context, initialMsg bus.Inb
 	// Build continuation target
 	target, targetErr := al.buildContinuationTarget(initialMsg)
 	if targetErr != nil {
-		logger.WarnCF("agent", "Failed to build steering continuation target",
+		logger.WarnCtx(ctx, "agent", "Failed to build steering continuation target",
 			map[string]any{
 				"channel": initialMsg.Channel,
 				"error":   targetErr.Error(),
@@ -51,7 +63,7 @@ func (al *AgentLoop) runTurnWithSteering(ctx context.Context, initialMsg bus.Inb
 			return
 		}
 
-		logger.InfoCF("agent", "Continuing queued steering after turn end",
+		logger.InfoCtx(ctx, "agent", "Continuing queued steering after turn end",
 			map[string]any{
 				"channel":     target.Channel,
 				"chat_id":     target.ChatID,
@@ -61,7 +73,7 @@ func (al *AgentLoop) runTurnWithSteering(ctx context.Context, initialMsg bus.Inb
 
 		continued, continueErr := al.Continue(ctx, target.SessionKey, target.Channel, target.ChatID)
 		if continueErr != nil {
-			logger.WarnCF("agent", "Failed to continue queued steering",
+			logger.WarnCtx(ctx, "agent", "Failed to continue queued steering",
 				map[string]any{
 					"channel": target.Channel,
 					"chat_id": target.ChatID,
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// chatWithStream calls the provider and, when both the provider and the
+// turn's channel support it, streams the answer to the chat as it is
+// generated. A streamed direct answer stays pending on the turn until
+// finishStream knows whether it is the final reply; responses with tool
+// calls cancel the stream right away.
+func (al *AgentLoop) chatWithStream(
+	ctx context.Context,
+	ts *turnState,
+	provider providers.LLMProvider,
+	messages []providers.Message,
+	toolDefs []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	// Any earlier streamed answer was superseded by this call.
+	ts.cancelPendingStream(ctx)
+
+	sp, ok := provider.(providers.StreamingProvider)
+	if !ok || !ts.opts.StreamResponse || al.bus == nil ||
+		ts.chatID == "" || constants.IsInternalChannel(ts.channel) {
+		return provider.Chat(ctx, messages, toolDefs, model, opts)
+	}
+	streamer, ok := al.bus.GetStreamer(ctx, ts.channel, ts.chatID)
+	if !ok {
+		return provider.Chat(ctx, messages, toolDefs, model, opts)
+	}
+
+	resp, err := sp.ChatStream(ctx, messages, toolDefs, model, opts, func(accumulated string) {
+		_ = streamer.Update(ctx, accumulated)
+	})
+	if err != nil || resp == nil || len(resp.ToolCalls) > 0 || strings.TrimSpace(resp.Content) == "" {
+		streamer.Cancel(ctx)
+		return resp, err
+	}
+	ts.setPendingStream(streamer, resp.Content)
+	return resp, nil
+}
+
+// finishStream finalizes the pending stream when it carries the turn's final
+// reply, so the channel does not deliver the same reply twice. Otherwise the
+// stream is cancelled and the reply is sent normally.
+func (al *AgentLoop) finishStream(ctx context.Context, ts *turnState, finalContent string) {
+	streamer, content := ts.takePendingStream()
+	if streamer == nil {
+		return
+	}
+	if content != finalContent || al.messageToolSentTo(ts.sessionKey, ts.channel, ts.chatID) {
+		streamer.Cancel(ctx)
+		return
+	}
+	if err := streamer.Finalize(ctx, finalContent); err != nil {
+		streamer.Cancel(ctx)
+	}
+}
+
+func (ts *turnState) setPendingStream(streamer bus.Streamer, content string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.pendingStream = streamer
+	ts.pendingStreamContent = content
+}
+
+func (ts *turnState) takePendingStream() (bus.Streamer, string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	streamer, content := ts.pendingStream, ts.pendingStreamContent
+	ts.pendingStream, ts.pendingStreamContent = nil, ""
+	return streamer, content
+}
+
+func (ts *turnState) cancelPendingStream(ctx context.Context) {
+	if streamer, _ := ts.takePendingStream(); streamer != nil {
+		streamer.Cancel(ctx)
+	}
+}
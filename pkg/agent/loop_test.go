@@ -19,6 +19,8 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
@@ -82,6 +84,7 @@ func newStartedTestChannelManager(
 
 type recordingProvider struct {
 	lastMessages []providers.Message
+	lastTools    []providers.ToolDefinition
 	lastModel    string
 }
 
@@ -93,6 +96,7 @@ func (r *recordingProvider) Chat(
 	opts map[string]any,
 ) (*providers.LLMResponse, error) {
 	r.lastMessages = append([]providers.Message(nil), messages...)
+	r.lastTools = tools
 	r.lastModel = model
 	return &providers.LLMResponse{
 		Content:   "Mock response",
@@ -271,6 +275,94 @@ func TestProcessMessage_UseCommandLoadsRequestedSkill(t *testing.T) {
 	}
 }
 
+func TestProcessMessage_ChatCommandSendsNoTools(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	send := func(content string) {
+		t.Helper()
+		if _, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+			Channel:  "telegram",
+			SenderID: "telegram:123",
+			ChatID:   "chat-1",
+			Content:  content,
+		})); err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+	}
+
+	send("what is a monad?")
+	if len(provider.lastTools) == 0 {
+		t.Fatal("expected tools for a normal message")
+	}
+
+	send("/chat what is a\nfunctor?")
+	if len(provider.lastTools) != 0 {
+		t.Fatalf("expected no tools for /chat, got %d", len(provider.lastTools))
+	}
+	n := len(provider.lastMessages)
+	if n < 2 || provider.lastMessages[n-2].Content != "what is a\nfunctor?" {
+		t.Fatalf("provider messages = %+v, want the rewritten user message", provider.lastMessages)
+	}
+	if provider.lastMessages[n-1].Content != noToolsHintMessage().Content {
+		t.Fatalf("last provider message = %+v, want the no-tools hint", provider.lastMessages[n-1])
+	}
+
+	response, handled := al.handleCommand(context.Background(), bus.InboundMessage{Content: "/chat"}, nil, &processOptions{})
+	if !handled || !strings.Contains(response, "Usage") {
+		t.Fatalf("handleCommand(/chat) = %q, %v; want usage", response, handled)
+	}
+}
+
+func TestProcessMessage_DisableToolsDropsToolCalls(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 3,
+				DisableTools:      true,
+			},
+		},
+	}
+	// The provider asks for a tool on every call, tools or not.
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &toolLimitOnlyProvider{content: "A monad is a monoid."})
+	al.RegisterTool(&toolLimitTestTool{})
+
+	response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:123",
+		ChatID:   "chat-1",
+		Content:  "what is a monad?",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != "A monad is a monoid." {
+		t.Fatalf("response = %q, want the text sent with the dropped tool call", response)
+	}
+
+	agent := al.GetRegistry().GetDefaultAgent()
+	for _, key := range agent.Sessions.ListSessions() {
+		for _, msg := range agent.Sessions.GetHistory(key) {
+			if msg.Role == "tool" || len(msg.ToolCalls) > 0 {
+				t.Fatalf("history of %s kept a tool call or result: %+v", key, msg)
+			}
+		}
+	}
+}
+
 func TestProcessMessage_BtwCommandRunsWithoutPersistingHistory(t *testing.T) {
 	tmpDir := t.TempDir()
 	cfg := &config.Config{
@@ -715,7 +807,7 @@ func TestApplyExplicitSkillCommand_ArmsSkillForNextMessage(t *testing.T) {
 	}
 
 	opts := &processOptions{SessionKey: "agent:main:test"}
-	matched, handled, reply := al.applyExplicitSkillCommand("/use finance-news", agent, opts)
+	matched, handled, reply := al.applyExplicitSkillCommand("/use finance-news", "", agent, opts)
 	if !matched {
 		t.Fatal("expected /use command to match")
 	}
@@ -730,6 +822,11 @@ func TestApplyExplicitSkillCommand_ArmsSkillForNextMessage(t *testing.T) {
 	if len(pending) != 1 || pending[0] != "finance-news" {
 		t.Fatalf("pending skills = %#v, want [finance-news]", pending)
 	}
+
+	_, _, reply = al.applyExplicitSkillCommand("/use finance-news", "zh", agent, opts)
+	if want := i18n.Sprintf("zh", i18n.UseArmed, "finance-news"); reply != want {
+		t.Fatalf("reply in zh = %q, want %q", reply, want)
+	}
 }
 
 func TestApplyExplicitSkillCommand_InlineMessageMutatesOptions(t *testing.T) {
@@ -756,7 +853,7 @@ func TestApplyExplicitSkillCommand_InlineMessageMutatesOptions(t *testing.T) {
 		SessionKey:  "agent:main:test",
 		UserMessage: "/use finance-news dammi le ultime news",
 	}
-	matched, handled, reply := al.applyExplicitSkillCommand(opts.UserMessage, agent, opts)
+	matched, handled, reply := al.applyExplicitSkillCommand(opts.UserMessage, "", agent, opts)
 	if !matched {
 		t.Fatal("expected /use command to match")
 	}
@@ -1792,7 +1889,9 @@ func (m *picoInterleavedContentProvider) GetDefaultModel() string {
 	return "pico-interleaved-content-model"
 }
 
-type toolLimitOnlyProvider struct{}
+type toolLimitOnlyProvider struct {
+	content string
+}
 
 func (m *toolLimitOnlyProvider) Chat(
 	ctx context.Context,
@@ -1802,6 +1901,7 @@ func (m *toolLimitOnlyProvider) Chat(
 	opts map[string]any,
 ) (*providers.LLMResponse, error) {
 	return &providers.LLMResponse{
+		Content: m.content,
 		ToolCalls: []providers.ToolCall{{
 			ID:        "call_tool_limit_test",
 			Type:      "function",
@@ -3081,8 +3181,121 @@ func TestAgentLoop_EmptyModelResponseUsesAccurateFallback(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
 	}
-	if response != defaultResponse {
-		t.Fatalf("response = %q, want %q", response, defaultResponse)
+	if want := i18n.Text("", i18n.ReplyEmpty); response != want {
+		t.Fatalf("response = %q, want %q", response, want)
+	}
+}
+
+// modelReplyProvider answers with a fixed response per model and records
+// the models it was called with.
+type modelReplyProvider struct {
+	replies map[string]*providers.LLMResponse
+	models  []string
+}
+
+func (p *modelReplyProvider) Chat(
+	_ context.Context, _ []providers.Message, _ []providers.ToolDefinition, model string, _ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.models = append(p.models, model)
+	if resp, ok := p.replies[model]; ok {
+		return resp, nil
+	}
+	return &providers.LLMResponse{}, nil
+}
+
+func (p *modelReplyProvider) GetDefaultModel() string { return "primary-model" }
+
+func TestAgentLoop_EmptyResponseRefusalAndFallbackRetry(t *testing.T) {
+	newLoop := func(retry bool, provider *modelReplyProvider) *AgentLoop {
+		cfg := &config.Config{
+			Agents: config.AgentsConfig{
+				Defaults: config.AgentDefaults{
+					Workspace:          t.TempDir(),
+					ModelName:          "primary-model",
+					ModelFallbacks:     []string{"fallback-model"},
+					MaxTokens:          4096,
+					MaxToolIterations:  3,
+					RetryEmptyResponse: retry,
+				},
+			},
+		}
+		return NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	}
+	refusal := &providers.LLMResponse{FinishReason: "content_filter"}
+
+	provider := &modelReplyProvider{replies: map[string]*providers.LLMResponse{"primary-model": refusal}}
+	response, err := newLoop(false, provider).ProcessDirectWithChannel(context.Background(), "hello", "s1", "test", "chat1")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+	}
+	if want := i18n.Text("", i18n.ReplyRefused); response != want {
+		t.Fatalf("refused response = %q, want %q", response, want)
+	}
+	if len(provider.models) != 1 {
+		t.Fatalf("models called without retry = %v, want one call", provider.models)
+	}
+
+	provider = &modelReplyProvider{replies: map[string]*providers.LLMResponse{
+		"primary-model":  refusal,
+		"fallback-model": {Content: "answer from the fallback", FinishReason: "stop"},
+	}}
+	response, err = newLoop(true, provider).ProcessDirectWithChannel(context.Background(), "hello", "s2", "test", "chat1")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+	}
+	if response != "answer from the fallback" {
+		t.Fatalf("retried response = %q", response)
+	}
+	if !slices.Equal(provider.models, []string{"primary-model", "fallback-model"}) {
+		t.Fatalf("models called = %v", provider.models)
+	}
+
+	// The retry happens once; a second empty reply gets the notice.
+	provider = &modelReplyProvider{}
+	response, err = newLoop(true, provider).ProcessDirectWithChannel(context.Background(), "hello", "s3", "test", "chat1")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+	}
+	if want := i18n.Text("", i18n.ReplyEmpty); response != want || len(provider.models) != 2 {
+		t.Fatalf("response = %q after %v, want %q after two calls", response, provider.models, want)
+	}
+
+	// Gemini fills a withheld response with its own English notice; it is
+	// still a refusal, and is retried on the fallback like an empty one.
+	geminiBlock := &providers.LLMResponse{
+		Content:      "Gemini withheld the response (SAFETY).",
+		FinishReason: "content_filter",
+	}
+	provider = &modelReplyProvider{replies: map[string]*providers.LLMResponse{"primary-model": geminiBlock}}
+	response, err = newLoop(false, provider).ProcessDirectWithChannel(context.Background(), "hello", "s4", "test", "chat1")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+	}
+	if want := i18n.Text("", i18n.ReplyRefused); response != want {
+		t.Fatalf("Gemini block response = %q, want %q", response, want)
+	}
+	provider = &modelReplyProvider{replies: map[string]*providers.LLMResponse{
+		"primary-model":  geminiBlock,
+		"fallback-model": {Content: "answer from the fallback", FinishReason: "stop"},
+	}}
+	response, err = newLoop(true, provider).ProcessDirectWithChannel(context.Background(), "hello", "s5", "test", "chat1")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+	}
+	if response != "answer from the fallback" || len(provider.models) != 2 {
+		t.Fatalf("retried Gemini block response = %q after %v", response, provider.models)
+	}
+
+	// Whitespace is no answer either.
+	provider = &modelReplyProvider{replies: map[string]*providers.LLMResponse{
+		"primary-model": {Content: " \n\t", FinishReason: "stop"},
+	}}
+	response, err = newLoop(false, provider).ProcessDirectWithChannel(context.Background(), "hello", "s6", "test", "chat1")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+	}
+	if want := i18n.Text("", i18n.ReplyEmpty); response != want {
+		t.Fatalf("whitespace response = %q, want %q", response, want)
 	}
 }
 
@@ -3113,8 +3326,11 @@ func TestAgentLoop_ToolLimitUsesDedicatedFallback(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
 	}
-	if response != toolLimitResponse {
-		t.Fatalf("response = %q, want %q", response, toolLimitResponse)
+	if want := toolLimitContent("", "", 1); response != want {
+		t.Fatalf("response = %q, want %q", response, want)
+	}
+	if !strings.Contains(response, "Stopped after 1 tool step without") {
+		t.Fatalf("response = %q, want a note saying where the turn stopped", response)
 	}
 
 	defaultAgent := al.registry.GetDefaultAgent()
@@ -3135,8 +3351,41 @@ func TestAgentLoop_ToolLimitUsesDedicatedFallback(t *testing.T) {
 		t.Fatalf("history len = %d, want 4", len(history))
 	}
 	assertRoles(t, history, "user", "assistant", "tool", "assistant")
-	if history[3].Content != toolLimitResponse {
-		t.Fatalf("final assistant content = %q, want %q", history[3].Content, toolLimitResponse)
+	if history[3].Content != response {
+		t.Fatalf("final assistant content = %q, want %q", history[3].Content, response)
+	}
+}
+
+func TestAgentLoop_ToolLimitReturnsPartialContent(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 2,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &toolLimitOnlyProvider{content: "Found 3 of 5 files so far."})
+	al.RegisterTool(&toolLimitTestTool{})
+	sub := al.SubscribeEvents(64)
+	defer al.UnsubscribeEvents(sub.ID)
+
+	response, err := al.ProcessDirectWithChannel(context.Background(), "hello", "tool-limit-partial", "test", "chat1")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+	}
+	if want := "Found 3 of 5 files so far.\n\n" + i18n.Sprintf("", i18n.ReplyToolLimit, 2); response != want {
+		t.Fatalf("response = %q, want %q", response, want)
+	}
+
+	evt := waitForEvent(t, sub.C, 2*time.Second, func(evt Event) bool {
+		return evt.Kind == EventKindToolLimitReached
+	})
+	payload, ok := evt.Payload.(ToolLimitReachedPayload)
+	if !ok || payload.Iterations != 2 || payload.MaxIterations != 2 || payload.PartialContentLen == 0 {
+		t.Fatalf("payload = %+v", evt.Payload)
 	}
 }
 
@@ -4573,3 +4822,236 @@ func (p *concurrentMockProvider) Chat(
 func (p *concurrentMockProvider) GetDefaultModel() string {
 	return "test-model"
 }
+
+func TestDrain_WaitsForInFlightTurns(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var startOnce sync.Once
+	provider := &concurrentMockProvider{
+		responseFunc: func(int) string {
+			startOnce.Do(func() { close(started) })
+			<-release
+			return "done"
+		},
+	}
+
+	al := NewAgentLoop(cfg, msgBus, provider)
+	defer al.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	if err := msgBus.PublishInbound(context.Background(), bus.InboundMessage{
+		Context:  bus.InboundContext{Channel: "telegram", ChatID: "chat1", ChatType: "direct", SenderID: "user1"},
+		Channel:  "telegram",
+		ChatID:   "chat1",
+		SenderID: "user1",
+		Content:  "hello",
+	}); err != nil {
+		t.Fatalf("PublishInbound failed: %v", err)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("turn did not start")
+	}
+
+	// The turn is blocked in the provider, so a short drain must time out.
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer shortCancel()
+	if err := al.Drain(shortCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain() with blocked turn = %v, want DeadlineExceeded", err)
+	}
+
+	close(release)
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer drainCancel()
+	if err := al.Drain(drainCtx); err != nil {
+		t.Fatalf("Drain() after release = %v, want nil", err)
+	}
+}
+
+type traceRecordingProvider struct {
+	mockProvider
+	mu       sync.Mutex
+	traceIDs []string
+}
+
+func (p *traceRecordingProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	p.traceIDs = append(p.traceIDs, logger.TraceIDFromContext(ctx))
+	p.mu.Unlock()
+	return p.mockProvider.Chat(ctx, messages, tools, model, opts)
+}
+
+func TestProcessMessage_PropagatesTraceID(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	provider := &traceRecordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	msg := bus.InboundMessage{
+		Context: bus.InboundContext{Channel: "telegram", ChatID: "42", ChatType: "direct", SenderID: "u1"},
+		Content: "hello",
+		TraceID: "trace-from-channel",
+	}
+	if _, err := al.processMessage(context.Background(), msg); err != nil {
+		t.Fatalf("processMessage: %v", err)
+	}
+	if _, err := al.ProcessDirect(context.Background(), "hi", "cli:trace"); err != nil {
+		t.Fatalf("ProcessDirect: %v", err)
+	}
+
+	if len(provider.traceIDs) != 2 {
+		t.Fatalf("provider calls = %d, want 2", len(provider.traceIDs))
+	}
+	if provider.traceIDs[0] != "trace-from-channel" {
+		t.Errorf("channel turn trace = %q, want the inbound message's ID", provider.traceIDs[0])
+	}
+	if id := provider.traceIDs[1]; id == "" || id == "trace-from-channel" {
+		t.Errorf("direct turn trace = %q, want a fresh ID", id)
+	}
+}
+
+func TestProcessMessage_AppliesOutputFilters(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				OutputFilters: []config.OutputFilter{
+					{Type: config.OutputFilterRegex, Pattern: `sk-[A-Za-z0-9]+`, Replacement: "[REDACTED]"},
+					{Type: config.OutputFilterPII, Kinds: []string{"email"}},
+					// Not idempotent: a second pass would add a second prefix.
+					{Type: config.OutputFilterRegex, Pattern: `\A`, Replacement: "[bot] "},
+				},
+			},
+		},
+	}
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	al := NewAgentLoop(cfg, msgBus, &simpleMockProvider{
+		response: "Use key sk-abc123 and mail ops@example.com",
+	})
+
+	resp, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:123",
+		ChatID:   "chat-1",
+		Content:  "what is the key?",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	al.PublishResponseIfNeeded(context.Background(), "telegram", "chat-1", "", resp)
+	want := "[bot] Use key [REDACTED] and mail [REDACTED email]"
+	if got := (<-msgBus.OutboundChan()).Content; got != want {
+		t.Fatalf("delivered reply = %q, want %q", got, want)
+	}
+
+	agent := al.GetRegistry().GetDefaultAgent()
+	for _, key := range agent.Sessions.ListSessions() {
+		history := agent.Sessions.GetHistory(key)
+		if len(history) == 0 {
+			continue
+		}
+		if last := history[len(history)-1]; last.Content != want {
+			t.Fatalf("stored reply = %q, want filtered %q", last.Content, want)
+		}
+	}
+}
+
+func TestOutputFilters_CoverBusAndFollowReload(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	provider := &simpleMockProvider{response: "ok"}
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	// publish stands in for the message tool and other direct publishers.
+	publish := func(content string) string {
+		t.Helper()
+		if err := msgBus.PublishOutbound(context.Background(), bus.OutboundMessage{
+			Channel: "telegram", ChatID: "chat-1", Content: content,
+		}); err != nil {
+			t.Fatalf("PublishOutbound() error = %v", err)
+		}
+		return (<-msgBus.OutboundChan()).Content
+	}
+	if got := publish("key sk-abc123"); got != "key sk-abc123" {
+		t.Fatalf("unfiltered content = %q", got)
+	}
+
+	reloaded := *cfg
+	reloaded.Agents.Defaults.OutputFilters = []config.OutputFilter{
+		{Type: config.OutputFilterRegex, Pattern: `sk-[A-Za-z0-9]+`, Replacement: "[REDACTED]"},
+	}
+	if err := al.ReloadProviderAndConfig(context.Background(), provider, &reloaded); err != nil {
+		t.Fatalf("ReloadProviderAndConfig() error = %v", err)
+	}
+	if got := publish("key sk-abc123"); got != "key [REDACTED]" {
+		t.Fatalf("content after reload = %q, want it filtered", got)
+	}
+
+	if err := al.ReloadProviderAndConfig(context.Background(), provider, cfg); err != nil {
+		t.Fatalf("ReloadProviderAndConfig() error = %v", err)
+	}
+	if got := publish("key sk-abc123"); got != "key sk-abc123" {
+		t.Fatalf("content after removing filters = %q", got)
+	}
+}
+
+func TestUserErrorMessage_ExplainsProviderErrors(t *testing.T) {
+	pe := &providers.ProviderError{Provider: "openai", Status: 429, Err: errors.New("API request failed: status 429 ...")}
+	err := fmt.Errorf("LLM call failed after retries: %w", &providers.FallbackExhaustedError{
+		Attempts: []providers.FallbackAttempt{{Provider: "openai", Model: "gpt-4o", Error: pe}},
+	})
+	if got := userErrorMessage("", "Error processing message: {message}", err); got != "Error processing message: "+pe.UserMessage() {
+		t.Fatalf("userErrorMessage() = %q", got)
+	}
+	if got := userErrorMessage("", "Error processing message: {message}", errors.New("boom")); got != "Error processing message: boom" {
+		t.Fatalf("userErrorMessage() = %q, want the error text for other errors", got)
+	}
+}
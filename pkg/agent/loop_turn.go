@@ -30,6 +30,8 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState) (turnResult, er
 
 	al.registerActiveTurn(ts)
 	defer al.clearActiveTurn(ts)
+	// A stream still pending here was superseded by an abort or error.
+	defer ts.cancelPendingStream(context.WithoutCancel(ctx))
 
 	turnStatus := TurnEndStatusCompleted
 	defer func() {
@@ -88,14 +90,14 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState) (turnResult, er
 	if !ts.opts.NoHistory {
 		toolDefs := ts.agent.Tools.ToProviderDefs()
 		if isOverContextBudget(ts.agent.ContextWindow, messages, toolDefs, ts.agent.MaxTokens) {
-			logger.WarnCF("agent", "Proactive compression: context budget exceeded before LLM call",
+			logger.WarnCtx(ctx, "agent", "Proactive compression: context budget exceeded before LLM call",
 				map[string]any{"session_key": ts.sessionKey})
 			if err := al.contextManager.Compact(turnCtx, &CompactRequest{
 				SessionKey: ts.sessionKey,
 				Reason:     ContextCompressReasonProactive,
 				Budget:     ts.agent.ContextWindow,
 			}); err != nil {
-				logger.WarnCF("agent", "Proactive compact failed", map[string]any{
+				logger.WarnCtx(ctx, "agent", "Proactive compact failed", map[string]any{
 					"session_key": ts.sessionKey,
 					"error":       err.Error(),
 				})
@@ -141,8 +143,18 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState) (turnResult, er
 	if usedLight && ts.agent.LightProvider != nil {
 		activeProvider = ts.agent.LightProvider
 	}
+	if sm := al.sessionModelFor(ts.agent, ts.sessionKey); sm != nil {
+		activeCandidates = sm.candidates
+		activeModel = resolvedCandidateModel(sm.candidates, sm.name)
+		activeProvider = sm.provider
+	}
 	pendingMessages := append([]providers.Message(nil), ts.opts.InitialSteeringMessages...)
-	var finalContent string
+	// partialContent is the latest text the model wrote alongside tool
+	// calls, returned if the turn runs out of tool iterations.
+	var finalContent, partialContent string
+	// emptyRetried is set once an empty or refused reply was retried on the
+	// next fallback model; refused means the last reply was content-filtered.
+	var emptyRetried, refused bool
 
 turnLoop:
 	for ts.currentIteration() < ts.agent.MaxIterations || len(pendingMessages) > 0 || func() bool {
@@ -158,6 +170,18 @@ turnLoop:
 		ts.setIteration(iteration)
 		ts.setPhase(TurnPhaseRunning)
 
+		if exceeded := al.sessionBudgetExceeded(ts.budgetSessionKey(), ts.agent.Budget); exceeded != "" {
+			logger.WarnCtx(ctx, "agent", "Session budget exhausted; ending turn without an LLM call",
+				map[string]any{
+					"agent_id":    ts.agent.ID,
+					"iteration":   iteration,
+					"budget":      exceeded,
+					"session_key": ts.sessionKey,
+				})
+			finalContent = budgetExceededContent(al.replyLocale(turnCtx, ts.channel), partialContent, exceeded)
+			break
+		}
+
 		if iteration > 1 {
 			if steerMsgs := al.dequeueSteeringMessagesForScope(ts.sessionKey); len(steerMsgs) > 0 {
 				pendingMessages = append(pendingMessages, steerMsgs...)
@@ -171,14 +195,14 @@ turnLoop:
 		// Check if parent turn has ended (SubTurn support from HEAD)
 		if ts.parentTurnState != nil && ts.IsParentEnded() {
 			if !ts.critical {
-				logger.InfoCF("agent", "Parent turn ended, non-critical SubTurn exiting gracefully", map[string]any{
+				logger.InfoCtx(ctx, "agent", "Parent turn ended, non-critical SubTurn exiting gracefully", map[string]any{
 					"agent_id":  ts.agentID,
 					"iteration": iteration,
 					"turn_id":   ts.turnID,
 				})
 				break
 			}
-			logger.InfoCF("agent", "Parent turn ended, critical SubTurn continues running", map[string]any{
+			logger.InfoCtx(ctx, "agent", "Parent turn ended, critical SubTurn continues running", map[string]any{
 				"agent_id":  ts.agentID,
 				"iteration": iteration,
 				"turn_id":   ts.turnID,
@@ -211,7 +235,7 @@ turnLoop:
 					ts.recordPersistedMessage(pm)
 					ts.ingestMessage(turnCtx, al, pm)
 				}
-				logger.InfoCF("agent", "Injected steering message into context",
+				logger.InfoCtx(ctx, "agent", "Injected steering message into context",
 					map[string]any{
 						"agent_id":    ts.agent.ID,
 						"iteration":   iteration,
@@ -230,7 +254,7 @@ turnLoop:
 			pendingMessages = nil
 		}
 
-		logger.DebugCF("agent", "LLM iteration",
+		logger.DebugCtx(ctx, "agent", "LLM iteration",
 			map[string]any{
 				"agent_id":  ts.agent.ID,
 				"iteration": iteration,
@@ -238,11 +262,15 @@ turnLoop:
 			})
 
 		gracefulTerminal, _ := ts.gracefulInterruptRequested()
-		providerToolDefs := ts.agent.Tools.ToProviderDefs()
+		noTools := ts.opts.NoTools || cfg.Agents.Defaults.DisableTools
+		var providerToolDefs []providers.ToolDefinition
+		if !noTools {
+			providerToolDefs = ts.agent.Tools.ToProviderDefs()
+		}
 
 		// Native web search support (from HEAD)
 		_, hasWebSearch := ts.agent.Tools.Get("web_search")
-		useNativeSearch := al.cfg.Tools.Web.PreferNative &&
+		useNativeSearch := !noTools && al.cfg.Tools.Web.PreferNative &&
 			hasWebSearch &&
 			func() bool {
 				// Check if provider supports native search
@@ -272,8 +300,11 @@ turnLoop:
 		}
 
 		callMessages := messages
+		if noTools {
+			callMessages = append(append([]providers.Message(nil), messages...), noToolsHintMessage())
+		}
 		if gracefulTerminal {
-			callMessages = append(append([]providers.Message(nil), messages...), ts.interruptHintMessage())
+			callMessages = append(append([]providers.Message(nil), callMessages...), ts.interruptHintMessage())
 			providerToolDefs = nil
 			ts.markGracefulTerminalUsed()
 		}
@@ -286,12 +317,19 @@ turnLoop:
 		if useNativeSearch {
 			llmOpts["native_search"] = true
 		}
-		if ts.agent.ThinkingLevel != ThinkingOff {
-			if tc, ok := ts.agent.Provider.(providers.ThinkingCapable); ok && tc.SupportsThinking() {
-				llmOpts["thinking_level"] = string(ts.agent.ThinkingLevel)
-			} else {
-				logger.WarnCF("agent", "thinking_level is set but current provider does not support it, ignoring",
-					map[string]any{"agent_id": ts.agent.ID, "thinking_level": string(ts.agent.ThinkingLevel)})
+		if level, budget := al.thinkingFor(ts.agent, ts.sessionKey); level != ThinkingOff || budget > 0 {
+			if providerSupportsThinking(activeProvider) {
+				if level != ThinkingOff {
+					llmOpts["thinking_level"] = string(level)
+				}
+				if budget > 0 {
+					llmOpts["thinking_budget"] = budget
+				}
+			} else if level != ThinkingOff {
+				// A budget alone is dropped silently: it is often set in
+				// agents.defaults for every model, thinking-capable or not.
+				logger.WarnCtx(ctx, "agent", "thinking_level is set but current provider does not support it, ignoring",
+					map[string]any{"agent_id": ts.agent.ID, "thinking_level": string(level)})
 			}
 		}
 
@@ -324,6 +362,14 @@ turnLoop:
 			}
 		}
 
+		var primaryCandidate providers.FallbackCandidate
+		if len(activeCandidates) > 0 {
+			primaryCandidate = activeCandidates[0]
+		}
+		primaryOpts := candidateLLMOptions(cfg, llmOpts, primaryCandidate, llmModel)
+		requestMaxTokens, _ := primaryOpts["max_tokens"].(int)
+		requestTemperature, _ := primaryOpts["temperature"].(float64)
+
 		al.emitEvent(
 			EventKindLLMRequest,
 			ts.eventMeta("runTurn", "turn.llm.request"),
@@ -331,29 +377,31 @@ turnLoop:
 				Model:         llmModel,
 				MessagesCount: len(callMessages),
 				ToolsCount:    len(providerToolDefs),
-				MaxTokens:     ts.agent.MaxTokens,
-				Temperature:   ts.agent.Temperature,
+				MaxTokens:     requestMaxTokens,
+				Temperature:   requestTemperature,
 			},
 		)
 
-		logger.DebugCF("agent", "LLM request",
+		logger.DebugCtx(ctx, "agent", "LLM request",
 			map[string]any{
 				"agent_id":          ts.agent.ID,
 				"iteration":         iteration,
 				"model":             llmModel,
 				"messages_count":    len(callMessages),
 				"tools_count":       len(providerToolDefs),
-				"max_tokens":        ts.agent.MaxTokens,
-				"temperature":       ts.agent.Temperature,
+				"max_tokens":        requestMaxTokens,
+				"temperature":       requestTemperature,
 				"system_prompt_len": len(callMessages[0].Content),
 			})
-		logger.DebugCF("agent", "Full LLM request",
+		logger.DebugCtx(ctx, "agent", "Full LLM request",
 			map[string]any{
 				"iteration":     iteration,
 				"messages_json": formatMessagesForLog(callMessages),
 				"tools_json":    formatToolsForLog(providerToolDefs),
 			})
 
+		// answeredBy is the candidate whose response callLLM returned.
+		answeredBy := primaryCandidate
 		callLLM := func(messagesForCall []providers.Message, toolDefsForCall []providers.ToolDefinition) (*providers.LLMResponse, error) {
 			providerCtx, providerCancel := context.WithCancel(turnCtx)
 			ts.setProviderCancel(providerCancel)
@@ -374,14 +422,22 @@ turnLoop:
 						if cp, ok := ts.agent.CandidateProviders[providers.ModelKey(provider, model)]; ok {
 							candidateProvider = cp
 						}
-						return candidateProvider.Chat(ctx, messagesForCall, toolDefsForCall, model, llmOpts)
+						candidateOpts := candidateLLMOptions(cfg, llmOpts, findCandidate(activeCandidates, provider, model), model)
+						start := time.Now()
+						resp, err := candidateProvider.Chat(ctx, messagesForCall, toolDefsForCall, model, candidateOpts)
+						al.usage.recordProviderCall(provider, time.Since(start), err)
+						return resp, err
 					},
 				)
 				if fbErr != nil {
 					return nil, fbErr
 				}
+				if fbResult.Provider != "" {
+					answeredBy = findCandidate(activeCandidates, fbResult.Provider, fbResult.Model)
+				}
 				if fbResult.Provider != "" && len(fbResult.Attempts) > 0 {
-					logger.InfoCF(
+					logger.InfoCtx(
+						ctx,
 						"agent",
 						fmt.Sprintf("Fallback: succeeded with %s/%s after %d attempts",
 							fbResult.Provider, fbResult.Model, len(fbResult.Attempts)+1),
@@ -390,7 +446,10 @@ turnLoop:
 				}
 				return fbResult.Response, nil
 			}
-			return activeProvider.Chat(providerCtx, messagesForCall, toolDefsForCall, llmModel, llmOpts)
+			start := time.Now()
+			resp, err := al.chatWithStream(providerCtx, ts, activeProvider, messagesForCall, toolDefsForCall, llmModel, primaryOpts)
+			al.usage.recordProviderCall(resolvedCandidateProvider(activeCandidates, cfg.Agents.Defaults.Provider), time.Since(start), err)
+			return resp, err
 		}
 
 		var response *providers.LLMResponse
@@ -419,7 +478,7 @@ turnLoop:
 						Backoff:    0,
 					},
 				)
-				logger.WarnCF("agent", "Vision unsupported, retrying without media", map[string]any{
+				logger.WarnCtx(ctx, "agent", "Vision unsupported, retrying without media", map[string]any{
 					"error": err.Error(),
 					"retry": retry,
 				})
@@ -467,7 +526,7 @@ turnLoop:
 						Backoff:    backoff,
 					},
 				)
-				logger.WarnCF("agent", "Timeout error, retrying after backoff", map[string]any{
+				logger.WarnCtx(ctx, "agent", "Timeout error, retrying after backoff", map[string]any{
 					"error":   err.Error(),
 					"retry":   retry,
 					"backoff": backoff.String(),
@@ -494,7 +553,8 @@ turnLoop:
 						Error:      err.Error(),
 					},
 				)
-				logger.WarnCF(
+				logger.WarnCtx(
+					ctx,
 					"agent",
 					"Context window error detected, attempting compression",
 					map[string]any{
@@ -515,7 +575,7 @@ turnLoop:
 					Reason:     ContextCompressReasonRetry,
 					Budget:     ts.agent.ContextWindow,
 				}); compactErr != nil {
-					logger.WarnCF("agent", "Context overflow compact failed", map[string]any{
+					logger.WarnCtx(ctx, "agent", "Context overflow compact failed", map[string]any{
 						"session_key": ts.sessionKey,
 						"error":       compactErr.Error(),
 					})
@@ -536,8 +596,11 @@ turnLoop:
 					activeSkillNames(ts.agent, ts.opts)...,
 				)
 				callMessages = messages
+				if noTools {
+					callMessages = append(append([]providers.Message(nil), messages...), noToolsHintMessage())
+				}
 				if gracefulTerminal {
-					callMessages = append(append([]providers.Message(nil), messages...), ts.interruptHintMessage())
+					callMessages = append(append([]providers.Message(nil), callMessages...), ts.interruptHintMessage())
 				}
 				continue
 			}
@@ -554,14 +617,18 @@ turnLoop:
 					Message: err.Error(),
 				},
 			)
-			logger.ErrorCF("agent", "LLM call failed",
+			logger.ErrorCtx(ctx, "agent", "LLM call failed",
 				map[string]any{
 					"agent_id":  ts.agent.ID,
 					"iteration": iteration,
 					"model":     llmModel,
 					"error":     err.Error(),
 				})
-			return turnResult{}, fmt.Errorf("LLM call failed after retries: %w", err)
+			err = fmt.Errorf("LLM call failed after retries: %w", err)
+			if al.retryQueue.Load() != nil && iteration == 1 && ts.depth == 0 && !ts.opts.NoHistory && isProviderOutage(err) {
+				return turnResult{}, &outageError{err: err, rollback: func() error { return ts.restoreSession(ts.agent) }}
+			}
+			return turnResult{}, err
 		}
 
 		if al.hooks != nil {
@@ -632,10 +699,30 @@ turnLoop:
 			llmResponseFields["prompt_tokens"] = response.Usage.PromptTokens
 			llmResponseFields["completion_tokens"] = response.Usage.CompletionTokens
 			llmResponseFields["total_tokens"] = response.Usage.TotalTokens
+			if response.Usage.CacheReadTokens > 0 || response.Usage.CacheWriteTokens > 0 {
+				llmResponseFields["cache_read_tokens"] = response.Usage.CacheReadTokens
+				llmResponseFields["cache_write_tokens"] = response.Usage.CacheWriteTokens
+			}
+		}
+		logger.DebugCtx(ctx, "agent", "LLM response", llmResponseFields)
+
+		// Some models call tools from memory of earlier turns even when none
+		// were offered. Those calls are dropped, not run: the turn answers
+		// with whatever text came with them.
+		if noTools && len(response.ToolCalls) > 0 {
+			logger.WarnCtx(ctx, "agent", "Dropping tool calls from a turn without tools",
+				map[string]any{
+					"agent_id":   ts.agent.ID,
+					"iteration":  iteration,
+					"tool_calls": len(response.ToolCalls),
+				})
+			dropped := *response
+			dropped.ToolCalls = nil
+			response = &dropped
 		}
-		logger.DebugCF("agent", "LLM response", llmResponseFields)
 
-		if al.bus != nil && ts.channel == "pico" && len(response.ToolCalls) > 0 && ts.opts.AllowInterimPicoPublish {
+		interimPublished := al.bus != nil && ts.channel == "pico" && ts.opts.AllowInterimPicoPublish
+		if interimPublished && len(response.ToolCalls) > 0 {
 			if strings.TrimSpace(response.Content) != "" {
 				outCtx, outCancel := context.WithTimeout(turnCtx, 3*time.Second)
 				err := al.bus.PublishOutbound(outCtx, bus.OutboundMessage{
@@ -645,7 +732,7 @@ turnLoop:
 				})
 				outCancel()
 				if err != nil {
-					logger.WarnCF("agent", "Failed to publish pico interim tool-call content", map[string]any{
+					logger.WarnCtx(ctx, "agent", "Failed to publish pico interim tool-call content", map[string]any{
 						"error":     err.Error(),
 						"channel":   ts.channel,
 						"chat_id":   ts.chatID,
@@ -655,13 +742,27 @@ turnLoop:
 			}
 		}
 
+		al.recordSessionUsage(ts.sessionKey, response.Usage)
+		if exceeded := al.chargeSessionBudget(ts.budgetSessionKey(), response.Usage, ts.agent.Budget); exceeded != "" &&
+			len(response.ToolCalls) > 0 && !gracefulTerminal {
+			logger.WarnCtx(ctx, "agent", "Session budget exceeded; stopping before tool execution",
+				map[string]any{
+					"agent_id":    ts.agent.ID,
+					"iteration":   iteration,
+					"budget":      exceeded,
+					"session_key": ts.sessionKey,
+				})
+			finalContent = budgetExceededContent(al.replyLocale(turnCtx, ts.channel), response.Content, exceeded)
+			break
+		}
+
 		if len(response.ToolCalls) == 0 || gracefulTerminal {
 			responseContent := response.Content
 			if responseContent == "" && response.ReasoningContent != "" && ts.channel != "pico" {
 				responseContent = response.ReasoningContent
 			}
 			if steerMsgs := al.dequeueSteeringMessagesForScope(ts.sessionKey); len(steerMsgs) > 0 {
-				logger.InfoCF("agent", "Steering arrived after direct LLM response; continuing turn",
+				logger.InfoCtx(ctx, "agent", "Steering arrived after direct LLM response; continuing turn",
 					map[string]any{
 						"agent_id":       ts.agent.ID,
 						"iteration":      iteration,
@@ -670,8 +771,40 @@ turnLoop:
 				pendingMessages = append(pendingMessages, steerMsgs...)
 				continue
 			}
+			// A filtered reply counts as refused even when it has text: some
+			// providers (Gemini) fill it with their own notice instead.
+			refused = response.FinishReason == "content_filter"
+			if refused || strings.TrimSpace(responseContent) == "" {
+				if refused && responseContent != "" {
+					logger.InfoCtx(ctx, "agent", "Dropping text of a content-filtered LLM response",
+						map[string]any{
+							"agent_id": ts.agent.ID,
+							"model":    answeredBy.Model,
+							"content":  utils.Truncate(responseContent, 200),
+						})
+				}
+				responseContent = ""
+				next := candidatesAfter(activeCandidates, answeredBy)
+				if cfg.Agents.Defaults.RetryEmptyResponse && !emptyRetried && len(next) > 0 {
+					logger.WarnCtx(ctx, "agent", "Empty LLM response; retrying once with the next fallback model",
+						map[string]any{
+							"agent_id":      ts.agent.ID,
+							"iteration":     iteration,
+							"model":         answeredBy.Model,
+							"next_model":    next[0].Model,
+							"finish_reason": response.FinishReason,
+						})
+					emptyRetried = true
+					activeCandidates = next
+					activeModel = resolvedCandidateModel(next, activeModel)
+					if cp, ok := ts.agent.CandidateProviders[providers.ModelKey(next[0].Provider, next[0].Model)]; ok {
+						activeProvider = cp
+					}
+					continue
+				}
+			}
 			finalContent = responseContent
-			logger.InfoCF("agent", "LLM response without tool calls (direct answer)",
+			logger.InfoCtx(ctx, "agent", "LLM response without tool calls (direct answer)",
 				map[string]any{
 					"agent_id":      ts.agent.ID,
 					"iteration":     iteration,
@@ -680,6 +813,11 @@ turnLoop:
 			break
 		}
 
+		// Text already published as an interim message is not repeated.
+		if !interimPublished && strings.TrimSpace(response.Content) != "" {
+			partialContent = response.Content
+		}
+
 		normalizedToolCalls := make([]providers.ToolCall, 0, len(response.ToolCalls))
 		for _, tc := range response.ToolCalls {
 			normalizedToolCalls = append(normalizedToolCalls, providers.NormalizeToolCall(tc))
@@ -689,7 +827,7 @@ turnLoop:
 		for _, tc := range normalizedToolCalls {
 			toolNames = append(toolNames, tc.Name)
 		}
-		logger.InfoCF("agent", "LLM requested tool calls",
+		logger.InfoCtx(ctx, "agent", "LLM requested tool calls",
 			map[string]any{
 				"agent_id":  ts.agent.ID,
 				"tools":     toolNames,
@@ -731,6 +869,7 @@ turnLoop:
 		}
 
 		ts.setPhase(TurnPhaseTools)
+		prefetched := al.prefetchToolCalls(turnCtx, ts, normalizedToolCalls, iteration)
 		for i, tc := range normalizedToolCalls {
 			if ts.hardAbortRequested() {
 				turnStatus = TurnEndStatusAborted
@@ -740,13 +879,21 @@ turnLoop:
 			toolName := tc.Name
 			toolArgs := cloneStringAnyMap(tc.Arguments)
 
+			pf := prefetchAt(prefetched, i)
+
 			if al.hooks != nil {
-				toolReq, decision := al.hooks.BeforeTool(turnCtx, &ToolCallHookRequest{
-					Meta:      ts.eventMeta("runTurn", "turn.tool.before"),
-					Context:   cloneTurnContext(ts.turnCtx),
-					Tool:      toolName,
-					Arguments: toolArgs,
-				})
+				var toolReq *ToolCallHookRequest
+				var decision HookDecision
+				if pf != nil && pf.hooked {
+					toolReq, decision = pf.hookReq, pf.decision
+				} else {
+					toolReq, decision = al.hooks.BeforeTool(turnCtx, &ToolCallHookRequest{
+						Meta:      ts.eventMeta("runTurn", "turn.tool.before"),
+						Context:   cloneTurnContext(ts.turnCtx),
+						Tool:      toolName,
+						Arguments: toolArgs,
+					})
+				}
 				switch decision.normalizedAction() {
 				case HookActionContinue, HookActionModify:
 					if toolReq != nil {
@@ -765,7 +912,7 @@ turnLoop:
 
 						argsJSON, _ := json.Marshal(toolArgs)
 						argsPreview := utils.Truncate(string(argsJSON), 200)
-						logger.InfoCF("agent", fmt.Sprintf("Tool call (hook respond): %s(%s)", toolName, argsPreview),
+						logger.InfoCtx(ctx, "agent", fmt.Sprintf("Tool call (hook respond): %s(%s)", toolName, argsPreview),
 							map[string]any{
 								"agent_id":  ts.agent.ID,
 								"tool":      toolName,
@@ -842,7 +989,7 @@ turnLoop:
 							}
 							if al.channelManager != nil && ts.channel != "" && !constants.IsInternalChannel(ts.channel) {
 								if err := al.channelManager.SendMedia(ctx, outboundMedia); err != nil {
-									logger.WarnCF("agent", "Failed to deliver hook media",
+									logger.WarnCtx(ctx, "agent", "Failed to deliver hook media",
 										map[string]any{
 											"agent_id": ts.agent.ID,
 											"tool":     toolName,
@@ -889,6 +1036,8 @@ turnLoop:
 							toolResultMsg.Content = contentForLLM
 							toolResultMsg.Media = append(toolResultMsg.Media, hookResult.Media...)
 						}
+						contentForLLM = al.capToolResult(turnCtx, ts, toolName, tc.ID, toolArgs, contentForLLM)
+						toolResultMsg.Content = contentForLLM
 
 						// Emit ToolExecEnd event (after filtering, same as normal tool execution)
 						al.emitEvent(
@@ -929,7 +1078,7 @@ turnLoop:
 						if skipReason != "" {
 							remaining := len(normalizedToolCalls) - i - 1
 							if remaining > 0 {
-								logger.InfoCF("agent", "Turn checkpoint: skipping remaining tools after hook respond",
+								logger.InfoCtx(ctx, "agent", "Turn checkpoint: skipping remaining tools after hook respond",
 									map[string]any{
 										"agent_id":  ts.agent.ID,
 										"completed": i + 1,
@@ -938,17 +1087,20 @@ turnLoop:
 									})
 								for j := i + 1; j < len(normalizedToolCalls); j++ {
 									skippedTC := normalizedToolCalls[j]
-									al.emitEvent(
-										EventKindToolExecSkipped,
-										ts.eventMeta("runTurn", "turn.tool.skipped"),
-										ToolExecSkippedPayload{
-											Tool:   skippedTC.Name,
-											Reason: skipReason,
-										},
-									)
+									content, ran := al.skippedToolContent(turnCtx, ts, skippedTC, prefetchAt(prefetched, j), skipMessage)
+									if !ran {
+										al.emitEvent(
+											EventKindToolExecSkipped,
+											ts.eventMeta("runTurn", "turn.tool.skipped"),
+											ToolExecSkippedPayload{
+												Tool:   skippedTC.Name,
+												Reason: skipReason,
+											},
+										)
+									}
 									skippedMsg := providers.Message{
 										Role:       "tool",
-										Content:    skipMessage,
+										Content:    content,
 										ToolCallID: skippedTC.ID,
 									}
 									messages = append(messages, skippedMsg)
@@ -979,7 +1131,7 @@ turnLoop:
 						continue
 					}
 					// If no HookResult, fall back to continue with warning
-					logger.WarnCF("agent", "Hook returned respond action but no HookResult provided",
+					logger.WarnCtx(ctx, "agent", "Hook returned respond action but no HookResult provided",
 						map[string]any{
 							"agent_id": ts.agent.ID,
 							"tool":     toolName,
@@ -1018,12 +1170,17 @@ turnLoop:
 			}
 
 			if al.hooks != nil {
-				approval := al.hooks.ApproveTool(turnCtx, &ToolApprovalRequest{
-					Meta:      ts.eventMeta("runTurn", "turn.tool.approve"),
-					Context:   cloneTurnContext(ts.turnCtx),
-					Tool:      toolName,
-					Arguments: toolArgs,
-				})
+				var approval ApprovalDecision
+				if pf != nil && pf.approval != nil {
+					approval = *pf.approval
+				} else {
+					approval = al.hooks.ApproveTool(turnCtx, &ToolApprovalRequest{
+						Meta:      ts.eventMeta("runTurn", "turn.tool.approve"),
+						Context:   cloneTurnContext(ts.turnCtx),
+						Tool:      toolName,
+						Arguments: toolArgs,
+					})
+				}
 				if !approval.Approved {
 					allResponsesHandled = false
 					denyContent := hookDeniedToolContent("Tool execution denied by approval hook", approval.Reason)
@@ -1051,7 +1208,7 @@ turnLoop:
 
 			argsJSON, _ := json.Marshal(toolArgs)
 			argsPreview := utils.Truncate(string(argsJSON), 200)
-			logger.InfoCF("agent", fmt.Sprintf("Tool call: %s(%s)", toolName, argsPreview),
+			logger.InfoCtx(ctx, "agent", fmt.Sprintf("Tool call: %s(%s)", toolName, argsPreview),
 				map[string]any{
 					"agent_id":  ts.agent.ID,
 					"tool":      toolName,
@@ -1081,78 +1238,22 @@ turnLoop:
 			}
 
 			toolCallID := tc.ID
-			toolIteration := iteration
-			asyncToolName := toolName
-			asyncCallback := func(_ context.Context, result *tools.ToolResult) {
-				// Send ForUser content directly to the user (immediate feedback),
-				// mirroring the synchronous tool execution path.
-				if !result.Silent && result.ForUser != "" {
-					outCtx, outCancel := context.WithTimeout(context.Background(), 5*time.Second)
-					defer outCancel()
-					_ = al.bus.PublishOutbound(outCtx, outboundMessageForTurn(ts, result.ForUser))
-				}
-
-				// Determine content for the agent loop (ForLLM or error).
-				content := result.ContentForLLM()
-				if content == "" {
-					return
-				}
-
-				// Filter sensitive data before publishing
-				content = al.cfg.FilterSensitiveData(content)
-
-				logger.InfoCF("agent", "Async tool completed, publishing result",
-					map[string]any{
-						"tool":        asyncToolName,
-						"content_len": len(content),
-						"channel":     ts.channel,
-					})
-				al.emitEvent(
-					EventKindFollowUpQueued,
-					ts.scope.meta(toolIteration, "runTurn", "turn.follow_up.queued"),
-					FollowUpQueuedPayload{
-						SourceTool: asyncToolName,
-						ContentLen: len(content),
-					},
+			var toolResult *tools.ToolResult
+			var toolDuration time.Duration
+			if pf != nil && pf.started {
+				toolResult, toolDuration = pf.wait()
+			} else {
+				toolStart := time.Now()
+				toolResult = al.executeToolCall(
+					turnCtx,
+					ts,
+					toolName,
+					toolArgs,
+					al.asyncToolCallback(ts, toolName, iteration),
 				)
-
-				pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
-				defer pubCancel()
-				_ = al.bus.PublishInbound(pubCtx, bus.InboundMessage{
-					Context: bus.InboundContext{
-						Channel:  "system",
-						ChatID:   fmt.Sprintf("%s:%s", ts.channel, ts.chatID),
-						ChatType: "direct",
-						SenderID: fmt.Sprintf("async:%s", asyncToolName),
-					},
-					Content: content,
-				})
+				toolDuration = time.Since(toolStart)
 			}
 
-			toolStart := time.Now()
-			execCtx := tools.WithToolInboundContext(
-				turnCtx,
-				ts.channel,
-				ts.chatID,
-				ts.opts.Dispatch.MessageID(),
-				ts.opts.Dispatch.ReplyToMessageID(),
-			)
-			execCtx = tools.WithToolSessionContext(
-				execCtx,
-				ts.agent.ID,
-				ts.sessionKey,
-				ts.opts.Dispatch.SessionScope,
-			)
-			toolResult := ts.agent.Tools.ExecuteWithContext(
-				execCtx,
-				toolName,
-				toolArgs,
-				ts.channel,
-				ts.chatID,
-				asyncCallback,
-			)
-			toolDuration := time.Since(toolStart)
-
 			if ts.hardAbortRequested() {
 				turnStatus = TurnEndStatusAborted
 				return al.abortTurn(ts)
@@ -1220,7 +1321,7 @@ turnLoop:
 				}
 				if al.channelManager != nil && ts.channel != "" && !constants.IsInternalChannel(ts.channel) {
 					if err := al.channelManager.SendMedia(ctx, outboundMedia); err != nil {
-						logger.WarnCF("agent", "Failed to deliver handled tool media",
+						logger.WarnCtx(ctx, "agent", "Failed to deliver handled tool media",
 							map[string]any{
 								"agent_id": ts.agent.ID,
 								"tool":     toolName,
@@ -1257,7 +1358,7 @@ turnLoop:
 				(ts.opts.SendResponse || toolResult.ResponseHandled)
 			if shouldSendForUser {
 				al.bus.PublishOutbound(ctx, outboundMessageForTurn(ts, toolResult.ForUser))
-				logger.DebugCF("agent", "Sent tool result to user",
+				logger.DebugCtx(ctx, "agent", "Sent tool result to user",
 					map[string]any{
 						"tool":        toolName,
 						"content_len": len(toolResult.ForUser),
@@ -1269,6 +1370,7 @@ turnLoop:
 			if al.cfg.Tools.IsFilterSensitiveDataEnabled() {
 				contentForLLM = al.cfg.FilterSensitiveData(contentForLLM)
 			}
+			contentForLLM = al.capToolResult(turnCtx, ts, toolName, toolCallID, toolArgs, contentForLLM)
 
 			toolResultMsg := providers.Message{
 				Role:       "tool",
@@ -1314,7 +1416,7 @@ turnLoop:
 			if skipReason != "" {
 				remaining := len(normalizedToolCalls) - i - 1
 				if remaining > 0 {
-					logger.InfoCF("agent", "Turn checkpoint: skipping remaining tools",
+					logger.InfoCtx(ctx, "agent", "Turn checkpoint: skipping remaining tools",
 						map[string]any{
 							"agent_id":  ts.agent.ID,
 							"completed": i + 1,
@@ -1323,17 +1425,20 @@ turnLoop:
 						})
 					for j := i + 1; j < len(normalizedToolCalls); j++ {
 						skippedTC := normalizedToolCalls[j]
-						al.emitEvent(
-							EventKindToolExecSkipped,
-							ts.eventMeta("runTurn", "turn.tool.skipped"),
-							ToolExecSkippedPayload{
-								Tool:   skippedTC.Name,
-								Reason: skipReason,
-							},
-						)
+						content, ran := al.skippedToolContent(turnCtx, ts, skippedTC, prefetchAt(prefetched, j), skipMessage)
+						if !ran {
+							al.emitEvent(
+								EventKindToolExecSkipped,
+								ts.eventMeta("runTurn", "turn.tool.skipped"),
+								ToolExecSkippedPayload{
+									Tool:   skippedTC.Name,
+									Reason: skipReason,
+								},
+							)
+						}
 						skippedMsg := providers.Message{
 							Role:       "tool",
-							Content:    skipMessage,
+							Content:    content,
 							ToolCallID: skippedTC.ID,
 						}
 						messages = append(messages, skippedMsg)
@@ -1364,7 +1469,7 @@ turnLoop:
 
 		if allResponsesHandled {
 			if len(pendingMessages) > 0 {
-				logger.InfoCF("agent", "Pending steering exists after handled tool delivery; continuing turn before finalizing",
+				logger.InfoCtx(ctx, "agent", "Pending steering exists after handled tool delivery; continuing turn before finalizing",
 					map[string]any{
 						"agent_id":       ts.agent.ID,
 						"steering_count": len(pendingMessages),
@@ -1375,7 +1480,7 @@ turnLoop:
 			}
 
 			if steerMsgs := al.dequeueSteeringMessagesForScope(ts.sessionKey); len(steerMsgs) > 0 {
-				logger.InfoCF("agent", "Steering arrived after handled tool delivery; continuing turn before finalizing",
+				logger.InfoCtx(ctx, "agent", "Steering arrived after handled tool delivery; continuing turn before finalizing",
 					map[string]any{
 						"agent_id":       ts.agent.ID,
 						"steering_count": len(steerMsgs),
@@ -1414,7 +1519,7 @@ turnLoop:
 
 			ts.setPhase(TurnPhaseCompleted)
 			ts.setFinalContent("")
-			logger.InfoCF("agent", "Tool output satisfied delivery; ending turn without follow-up LLM",
+			logger.InfoCtx(ctx, "agent", "Tool output satisfied delivery; ending turn without follow-up LLM",
 				map[string]any{
 					"agent_id":   ts.agent.ID,
 					"iteration":  iteration,
@@ -1428,13 +1533,13 @@ turnLoop:
 		}
 
 		ts.agent.Tools.TickTTL()
-		logger.DebugCF("agent", "TTL tick after tool execution", map[string]any{
+		logger.DebugCtx(ctx, "agent", "TTL tick after tool execution", map[string]any{
 			"agent_id": ts.agent.ID, "iteration": iteration,
 		})
 	}
 
 	if steerMsgs := al.dequeueSteeringMessagesForScope(ts.sessionKey); len(steerMsgs) > 0 {
-		logger.InfoCF("agent", "Steering arrived after turn completion; continuing turn before finalizing",
+		logger.InfoCtx(ctx, "agent", "Steering arrived after turn completion; continuing turn before finalizing",
 			map[string]any{
 				"agent_id":       ts.agent.ID,
 				"steering_count": len(steerMsgs),
@@ -1451,17 +1556,45 @@ turnLoop:
 	}
 
 	if finalContent == "" {
-		if ts.currentIteration() >= ts.agent.MaxIterations && ts.agent.MaxIterations > 0 {
-			finalContent = toolLimitResponse
+		if steps := ts.currentIteration(); steps >= ts.agent.MaxIterations && ts.agent.MaxIterations > 0 {
+			logger.WarnCtx(ctx, "agent", "Tool iteration limit reached; returning partial response",
+				map[string]any{
+					"agent_id":      ts.agent.ID,
+					"session_key":   ts.sessionKey,
+					"iterations":    steps,
+					"max":           ts.agent.MaxIterations,
+					"partial_chars": len(partialContent),
+				})
+			al.emitEvent(
+				EventKindToolLimitReached,
+				ts.eventMeta("runTurn", "turn.tool_limit.reached"),
+				ToolLimitReachedPayload{
+					Iterations:        steps,
+					MaxIterations:     ts.agent.MaxIterations,
+					PartialContentLen: len(partialContent),
+				},
+			)
+			finalContent = toolLimitContent(al.replyLocale(turnCtx, ts.channel), partialContent, steps)
+		} else if ts.opts.DefaultResponse == defaultResponse {
+			// The built-in notice is localized and can be configured.
+			finalContent = al.emptyReply(turnCtx, ts.channel, refused)
 		} else {
 			finalContent = ts.opts.DefaultResponse
 		}
 	}
 
+	// The reply is filtered once, by the bus, when it is published. The
+	// history keeps the reply as it was delivered.
+	historyContent := finalContent
+	if ts.depth == 0 {
+		historyContent = al.outputFilters.Load().Apply(finalContent)
+	}
+
 	ts.setPhase(TurnPhaseFinalizing)
 	ts.setFinalContent(finalContent)
+	al.finishStream(turnCtx, ts, finalContent)
 	if !ts.opts.NoHistory {
-		finalMsg := providers.Message{Role: "assistant", Content: finalContent}
+		finalMsg := providers.Message{Role: "assistant", Content: historyContent}
 		ts.agent.Sessions.AddMessage(ts.sessionKey, finalMsg.Role, finalMsg.Content)
 		ts.recordPersistedMessage(finalMsg)
 		ts.ingestMessage(turnCtx, al, finalMsg)
@@ -1648,14 +1781,17 @@ func (al *AgentLoop) askSideQuestion(
 			return nil, err
 		}
 		defer cleanup()
+		callOpts := candidateLLMOptions(al.GetConfig(), llmOpts, candidate, model)
 		if !forceModel || strings.TrimSpace(model) == "" {
 			model = providerModel
 		}
-		callOpts := llmOpts
 		if _, exists := callOpts["thinking_level"]; !exists && agent.ThinkingLevel != ThinkingOff {
 			if tc, ok := provider.(providers.ThinkingCapable); ok && tc.SupportsThinking() {
-				callOpts = shallowCloneLLMOptions(llmOpts)
+				callOpts = shallowCloneLLMOptions(callOpts)
 				callOpts["thinking_level"] = string(agent.ThinkingLevel)
+				if agent.ThinkingBudget > 0 {
+					callOpts["thinking_budget"] = agent.ThinkingBudget
+				}
 			}
 		}
 		return provider.Chat(ctx, callMessages, nil, model, callOpts)
@@ -1717,13 +1853,7 @@ func (al *AgentLoop) askSideQuestion(
 				ctx,
 				activeCandidates,
 				func(ctx context.Context, providerName, model string) (*providers.LLMResponse, error) {
-					candidate := providers.FallbackCandidate{Provider: providerName, Model: model}
-					for _, activeCandidate := range activeCandidates {
-						if activeCandidate.Provider == providerName && activeCandidate.Model == model {
-							candidate = activeCandidate
-							break
-						}
-					}
+					candidate := findCandidate(activeCandidates, providerName, model)
 					return callProvider(ctx, candidate, model, false, callMessages)
 				},
 			)
@@ -11,6 +11,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/session"
 	"github.com/sipeed/picoclaw/pkg/utils"
@@ -424,31 +425,24 @@ func makePendingTurnID(sessionKey string, seq uint64) string {
 	return pendingTurnPrefix + sessionKey + "-" + fmt.Sprintf("%d", seq)
 }
 
-func commandsUnavailableSkillMessage() string {
-	return "Skill selection is unavailable in the current context."
-}
-
-func buildUseCommandHelp(agent *AgentInstance) string {
+func buildUseCommandHelp(locale string, agent *AgentInstance) string {
 	if agent == nil || agent.ContextBuilder == nil {
-		return "Usage: /use <skill> [message]"
+		return i18n.Text(locale, i18n.UseUsage)
 	}
 
 	names := agent.ContextBuilder.ListSkillNames()
 	if len(names) == 0 {
-		return "Usage: /use <skill> [message]\nNo installed skills found."
+		return i18n.Text(locale, i18n.UseNoSkills)
 	}
 
-	return fmt.Sprintf(
-		"Usage: /use <skill> [message]\n\nInstalled Skills:\n- %s\n\nUse /use <skill> to apply a skill to your next message, or /use <skill> <message> to force it immediately.",
-		strings.Join(names, "\n- "),
-	)
+	return i18n.Sprintf(locale, i18n.UseSkills, strings.Join(names, "\n- "))
 }
 
-func mapCommandError(result commands.ExecuteResult) string {
+func mapCommandError(locale string, result commands.ExecuteResult) string {
 	if result.Command == "" {
-		return fmt.Sprintf("Failed to execute command: %v", result.Err)
+		return i18n.Sprintf(locale, i18n.CommandFailedUnnamed, result.Err)
 	}
-	return fmt.Sprintf("Failed to execute /%s: %v", result.Command, result.Err)
+	return i18n.Sprintf(locale, i18n.CommandFailed, result.Command, result.Err)
 }
 
 func isNativeSearchProvider(p providers.LLMProvider) bool {
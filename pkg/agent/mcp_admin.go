@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/mcp"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// mcpAdmin gives the mcp_admin tool control over the loop's MCP manager and
+// keeps every agent's MCP tools in step with the servers it changes.
+type mcpAdmin struct {
+	al *AgentLoop
+}
+
+var _ tools.MCPAdmin = mcpAdmin{}
+
+func (a mcpAdmin) manager() (*mcp.Manager, error) {
+	a.al.mcp.mu.Lock()
+	defer a.al.mcp.mu.Unlock()
+	if a.al.mcp.manager == nil {
+		return nil, fmt.Errorf("MCP is not running; fix the MCP config and reload the gateway")
+	}
+	return a.al.mcp.manager, nil
+}
+
+func (a mcpAdmin) ServerStatus() ([]mcp.ServerStatus, error) {
+	manager, err := a.manager()
+	if err != nil {
+		return nil, err
+	}
+	return manager.ServerStatus(), nil
+}
+
+func (a mcpAdmin) RestartServer(ctx context.Context, name string) error {
+	manager, err := a.manager()
+	if err != nil {
+		return err
+	}
+	err = manager.Reconnect(ctx, name)
+	// The old tools are gone either way; register whatever the server has now.
+	a.al.syncMCPServerTools(manager, a.al.GetConfig().Tools.MCP, name)
+	return err
+}
+
+// ReloadServers re-reads the MCP section of the config file. The rest of the
+// config is not applied; that is what a gateway reload is for.
+func (a mcpAdmin) ReloadServers(ctx context.Context) (mcp.ReloadResult, error) {
+	manager, err := a.manager()
+	if err != nil {
+		return mcp.ReloadResult{}, err
+	}
+	if a.al.configPath == "" {
+		return mcp.ReloadResult{}, fmt.Errorf("config file path is unknown; MCP reload is only available in the gateway")
+	}
+	cfg, err := config.LoadConfig(a.al.configPath)
+	if err != nil {
+		return mcp.ReloadResult{}, fmt.Errorf("loading config: %w", err)
+	}
+
+	workspacePath := cfg.WorkspacePath()
+	if defaultAgent := a.al.GetRegistry().GetDefaultAgent(); defaultAgent != nil && defaultAgent.Workspace != "" {
+		workspacePath = defaultAgent.Workspace
+	}
+	result, err := manager.Reload(ctx, cfg.Tools.MCP, workspacePath)
+	if err != nil {
+		return result, err
+	}
+	for _, names := range [][]string{result.Added, result.Removed, result.Restarted} {
+		for _, name := range names {
+			a.al.syncMCPServerTools(manager, cfg.Tools.MCP, name)
+		}
+	}
+	logger.InfoCF("agent", "MCP servers reloaded", map[string]any{
+		"added":     result.Added,
+		"removed":   result.Removed,
+		"restarted": result.Restarted,
+		"failed":    result.Failed,
+	})
+	return result, nil
+}
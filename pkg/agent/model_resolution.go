@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
@@ -137,6 +138,35 @@ func resolveModelCandidates(
 	return candidates
 }
 
+// dropUnreachableFallbacks removes fallbacks the loop could only call through
+// the primary provider's client although they name another provider, such as
+// "anthropic/claude-x" with no model_list entry while the primary is OpenAI.
+// Such a call hits the wrong API on every failover, so the fallback is
+// skipped with a warning. Fallbacks on the primary's own provider keep
+// inheriting its client.
+func dropUnreachableFallbacks(
+	candidates []providers.FallbackCandidate,
+	candidateProviders map[string]providers.LLMProvider,
+) []providers.FallbackCandidate {
+	if len(candidates) < 2 {
+		return candidates
+	}
+	primary := candidates[0]
+	kept := candidates[:1:1]
+	for _, c := range candidates[1:] {
+		if _, ok := candidateProviders[providers.ModelKey(c.Provider, c.Model)]; ok || c.Provider == primary.Provider {
+			kept = append(kept, c)
+			continue
+		}
+		logger.WarnCF("agent", "Skipping fallback model: no usable model_list entry for its provider",
+			map[string]any{
+				"fallback":         providers.ModelKey(c.Provider, c.Model),
+				"primary_provider": primary.Provider,
+			})
+	}
+	return kept
+}
+
 func resolvedCandidateModel(candidates []providers.FallbackCandidate, fallback string) string {
 	if len(candidates) > 0 && strings.TrimSpace(candidates[0].Model) != "" {
 		return candidates[0].Model
@@ -168,3 +198,90 @@ func resolvedModelConfig(cfg *config.Config, modelName, workspace string) (*conf
 
 	return &clone, nil
 }
+
+// modelConfigForCandidate returns the model_list entry candidate was resolved
+// from. Unlike GetModelConfig it does not advance the round-robin counter.
+func modelConfigForCandidate(cfg *config.Config, candidate providers.FallbackCandidate) *config.ModelConfig {
+	name, ok := strings.CutPrefix(candidate.IdentityKey, "model_name:")
+	if !ok || cfg == nil {
+		return nil
+	}
+	for _, mc := range cfg.ModelList {
+		if mc != nil && mc.ModelName == name {
+			return mc
+		}
+	}
+	return nil
+}
+
+// findCandidate returns the candidate for provider/model, or a bare candidate
+// if it is not in the list.
+func findCandidate(candidates []providers.FallbackCandidate, provider, model string) providers.FallbackCandidate {
+	for _, candidate := range candidates {
+		if candidate.Provider == provider && candidate.Model == model {
+			return candidate
+		}
+	}
+	return providers.FallbackCandidate{Provider: provider, Model: model}
+}
+
+// candidatesAfter returns the candidates that follow answered in the
+// fallback chain, or nil when it is the last one or not in the chain.
+func candidatesAfter(
+	candidates []providers.FallbackCandidate,
+	answered providers.FallbackCandidate,
+) []providers.FallbackCandidate {
+	for i, candidate := range candidates {
+		if candidate.Provider == answered.Provider && candidate.Model == answered.Model {
+			return candidates[i+1:]
+		}
+	}
+	return nil
+}
+
+// candidateLLMOptions adapts opts to the model about to be called. The
+// model_list entry's max_tokens and temperature replace the agent defaults,
+// and the temperature is capped at the entry's max_temperature or the model's
+// known limit, or dropped for models that reject it. opts is not modified.
+func candidateLLMOptions(
+	cfg *config.Config,
+	opts map[string]any,
+	candidate providers.FallbackCandidate,
+	model string,
+) map[string]any {
+	if model == "" {
+		model = candidate.Model
+	}
+	var mc *config.ModelConfig
+	if candidate.Model == "" || candidate.Model == model {
+		// A hook may have swapped the model; the candidate's entry would not apply.
+		mc = modelConfigForCandidate(cfg, candidate)
+	}
+	limits := providers.KnownModelLimits(model)
+	if mc == nil && !limits.NoTemperature && limits.MaxTemperature == 0 {
+		return opts
+	}
+
+	out := shallowCloneLLMOptions(opts)
+	if mc != nil && mc.MaxTokens > 0 {
+		out["max_tokens"] = mc.MaxTokens
+	}
+	if mc != nil && mc.PromptCaching != nil {
+		out["prompt_caching"] = *mc.PromptCaching
+	}
+	if mc != nil && mc.Temperature != nil {
+		out["temperature"] = *mc.Temperature
+	} else if limits.NoTemperature {
+		delete(out, "temperature")
+		return out
+	}
+
+	maxTemperature, capped := limits.MaxTemperature, limits.MaxTemperature > 0
+	if mc != nil && mc.MaxTemperature != nil {
+		maxTemperature, capped = *mc.MaxTemperature, true
+	}
+	if temperature, ok := out["temperature"].(float64); ok && capped && temperature > maxTemperature {
+		out["temperature"] = maxTemperature
+	}
+	return out
+}
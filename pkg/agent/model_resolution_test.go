@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestCandidateLLMOptions(t *testing.T) {
+	temp := func(v float64) *float64 { return &v }
+	cfg := &config.Config{ModelList: []*config.ModelConfig{
+		{ModelName: "small", Model: "openai/qwen-7b", MaxTokens: 2048, MaxTemperature: temp(0.5)},
+		{ModelName: "creative", Model: "anthropic/claude-sonnet-4.6", Temperature: temp(1.5)},
+		{ModelName: "reasoner", Model: "openai/o3-mini"},
+		{ModelName: "pinned", Model: "openai/o3-mini", Temperature: temp(1)},
+	}}
+	base := map[string]any{"max_tokens": 8192, "temperature": 0.7, "prompt_cache_key": "main"}
+	candidate := func(name, model string) providers.FallbackCandidate {
+		return providers.FallbackCandidate{Model: model, IdentityKey: "model_name:" + name}
+	}
+
+	tests := []struct {
+		name      string
+		candidate providers.FallbackCandidate
+		model     string
+		maxTokens int
+		temp      any
+	}{
+		{"entry overrides and caps", candidate("small", "qwen-7b"), "qwen-7b", 2048, 0.5},
+		{"known cap applies to entry temperature", candidate("creative", "claude-sonnet-4.6"), "", 8192, 1.0},
+		{"reasoning model drops temperature", candidate("reasoner", "o3-mini"), "o3-mini", 8192, nil},
+		{"explicit temperature is kept", candidate("pinned", "o3-mini"), "o3-mini", 8192, 1.0},
+		{"unknown model is untouched", providers.FallbackCandidate{Model: "gpt-4o"}, "gpt-4o", 8192, 0.7},
+		{"hook-swapped model ignores entry", candidate("small", "qwen-7b"), "gpt-4o", 8192, 0.7},
+	}
+	for _, tt := range tests {
+		got := candidateLLMOptions(cfg, base, tt.candidate, tt.model)
+		if got["max_tokens"] != tt.maxTokens || got["temperature"] != tt.temp || got["prompt_cache_key"] != "main" {
+			t.Errorf("%s: options = %v, want max_tokens %d, temperature %v", tt.name, got, tt.maxTokens, tt.temp)
+		}
+	}
+	if base["max_tokens"] != 8192 || base["temperature"] != 0.7 {
+		t.Fatalf("base options were modified: %v", base)
+	}
+}
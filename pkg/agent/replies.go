@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// localeFor picks the locale for built-in replies: the channel's configured
+// locale, else the sender's language, else agents.defaults.locale, taking
+// the first that has a catalog. "" means English.
+func (al *AgentLoop) localeFor(channel, senderLanguage string) string {
+	cfg := al.GetConfig()
+	if cfg != nil {
+		if ch := cfg.Channels[channel]; ch != nil {
+			if locale := i18n.Normalize(ch.Locale); locale != "" {
+				return locale
+			}
+		}
+	}
+	if locale := i18n.Normalize(senderLanguage); locale != "" {
+		return locale
+	}
+	if cfg != nil {
+		return i18n.Normalize(cfg.Agents.Defaults.Locale)
+	}
+	return ""
+}
+
+// replyLocale returns the locale stored on ctx for the current turn, or the
+// channel's when the turn did not set one.
+func (al *AgentLoop) replyLocale(ctx context.Context, channel string) string {
+	if locale := i18n.FromContext(ctx); locale != "" {
+		return locale
+	}
+	return al.localeFor(channel, "")
+}
+
+// errorReply is the chat reply for a turn on channel that failed with err.
+func (al *AgentLoop) errorReply(ctx context.Context, channel string, err error) string {
+	locale := al.replyLocale(ctx, channel)
+	tmpl := al.replyText(channel, func(m config.ReplyTextConfig) string { return m.Error })
+	if tmpl == "" {
+		tmpl = i18n.Text(locale, i18n.ReplyError)
+	}
+	return userErrorMessage(locale, tmpl, err)
+}
+
+// busyReply is the chat reply when no turn worker frees up in time.
+func (al *AgentLoop) busyReply(channel, locale string) string {
+	if text := al.replyText(channel, func(m config.ReplyTextConfig) string { return m.Busy }); text != "" {
+		return text
+	}
+	return i18n.Text(locale, i18n.ReplyBusy)
+}
+
+// emptyReply is the chat reply when the model returned nothing usable:
+// refused means the provider's content filter withheld the reply.
+func (al *AgentLoop) emptyReply(ctx context.Context, channel string, refused bool) string {
+	id, field := i18n.ReplyEmpty, func(m config.ReplyTextConfig) string { return m.Empty }
+	if refused {
+		id, field = i18n.ReplyRefused, func(m config.ReplyTextConfig) string { return m.Refused }
+	}
+	if text := al.replyText(channel, field); text != "" {
+		return text
+	}
+	return i18n.Text(al.replyLocale(ctx, channel), id)
+}
+
+// replyText returns the channel's configured text, else the agent
+// default's, else "".
+func (al *AgentLoop) replyText(channel string, field func(config.ReplyTextConfig) string) string {
+	cfg := al.GetConfig()
+	if cfg == nil {
+		return ""
+	}
+	if ch := cfg.Channels[channel]; ch != nil {
+		if text := field(ch.Messages); strings.TrimSpace(text) != "" {
+			return text
+		}
+	}
+	if text := field(cfg.Agents.Defaults.Messages); strings.TrimSpace(text) != "" {
+		return text
+	}
+	return ""
+}
+
+// userErrorMessage renders an error reply template. {message} is a plain
+// explanation: provider API errors get one in locale without provider
+// internals, other errors their text. The full error is in the logs either
+// way.
+func userErrorMessage(locale, tmpl string, err error) string {
+	category, message := "internal", err.Error()
+	if pe, ok := providers.AsProviderError(err); ok {
+		category = pe.Category()
+		message = i18n.Text(locale, i18n.ProviderError(category))
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		category = "timeout"
+	}
+	return strings.NewReplacer("{category}", category, "{message}", message).Replace(tmpl)
+}
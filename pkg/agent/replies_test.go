@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestErrorAndBusyReplies_ChannelOverridesDefaults(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Agents.Defaults.Messages = config.ReplyTextConfig{Error: "Oops ({category})"}
+	cfg.Channels = config.ChannelsConfig{
+		"feishu": {
+			Enabled: true,
+			Messages: config.ReplyTextConfig{
+				Error: "处理消息时出错（{category}）：{message}",
+				Busy:  "我正忙，请稍后再试。",
+			},
+		},
+	}
+
+	ctx := context.Background()
+	rateLimited := fmt.Errorf("llm: %w", &providers.ProviderError{Provider: "openai", Status: 429})
+	tests := []struct {
+		channel string
+		err     error
+		want    string
+	}{
+		{"feishu", errors.New("boom"), "处理消息时出错（internal）：boom"},
+		{"feishu", context.DeadlineExceeded, "处理消息时出错（timeout）：context deadline exceeded"},
+		{"telegram", rateLimited, "Oops (rate_limit)"},
+	}
+	for _, tt := range tests {
+		if got := al.errorReply(ctx, tt.channel, tt.err); got != tt.want {
+			t.Errorf("errorReply(%q, %v) = %q, want %q", tt.channel, tt.err, got, tt.want)
+		}
+	}
+
+	if got := al.busyReply("feishu", ""); got != "我正忙，请稍后再试。" {
+		t.Errorf("busyReply(feishu) = %q", got)
+	}
+	if got, want := al.busyReply("telegram", ""), i18n.Text("", i18n.ReplyBusy); got != want {
+		t.Errorf("busyReply(telegram) = %q, want the built-in reply %q", got, want)
+	}
+
+	cfg.Agents.Defaults.Messages = config.ReplyTextConfig{}
+	if got := al.errorReply(ctx, "telegram", errors.New("boom")); got != "Error processing message: boom" {
+		t.Errorf("errorReply without config = %q", got)
+	}
+}
+
+func TestLocaleFor_ChannelThenSenderThenDefault(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Channels = config.ChannelsConfig{
+		"feishu":   {Enabled: true, Locale: "zh-CN"},
+		"telegram": {Enabled: true},
+		"slack":    {Enabled: true, Locale: "fr"},
+	}
+
+	tests := []struct {
+		channel, language, defaultLocale, want string
+	}{
+		{"feishu", "en-US", "", "zh"},
+		{"telegram", "zh-hans", "", "zh"},
+		{"telegram", "de", "", ""},
+		{"telegram", "de", "zh", "zh"},
+		{"telegram", "", "en", "en"},
+		{"slack", "zh-CN", "", "zh"},
+		{"slack", "", "zh", "zh"},
+	}
+	for _, tt := range tests {
+		cfg.Agents.Defaults.Locale = tt.defaultLocale
+		if got := al.localeFor(tt.channel, tt.language); got != tt.want {
+			t.Errorf("localeFor(%q, %q) with default %q = %q, want %q",
+				tt.channel, tt.language, tt.defaultLocale, got, tt.want)
+		}
+	}
+}
+
+func TestBuiltInRepliesFollowSenderLanguage(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	msg := bus.InboundMessage{
+		Channel: "telegram",
+		ChatID:  "1",
+		Content: "/help",
+		Sender:  bus.SenderInfo{Language: "zh-hans"},
+	}
+	ctx := i18n.WithLocale(context.Background(), al.localeFor(msg.Channel, msg.Sender.Language))
+
+	rateLimited := &providers.ProviderError{Provider: "openai", Status: 429}
+	if got, want := al.errorReply(ctx, "telegram", rateLimited),
+		"处理消息时出错：AI 服务请求过于频繁，请稍后再试。"; got != want {
+		t.Errorf("errorReply = %q, want %q", got, want)
+	}
+
+	reply, handled := al.handleCommand(context.Background(), msg, al.GetRegistry().GetDefaultAgent(), &processOptions{})
+	if !handled {
+		t.Fatal("/help was not handled")
+	}
+	if want := "/help - 显示此帮助信息"; !slices.Contains(strings.Split(reply, "\n"), want) {
+		t.Errorf("/help reply = %q, want a line %q", reply, want)
+	}
+}
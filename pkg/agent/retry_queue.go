@@ -0,0 +1,406 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	defaultRetryQueueSize    = 50
+	defaultRetryQueueTTL     = 6 * time.Hour
+	defaultRetryInitialDelay = 30 * time.Second
+	defaultRetryMaxDelay     = 10 * time.Minute
+	retryQueuePollInterval   = 5 * time.Second
+)
+
+// retryQueue holds messages whose turn failed because no provider was
+// available, so they can be answered once one recovers. Entries are keyed by
+// the message's trace ID, which a redelivered message keeps, and are saved
+// under the workspace state directory so they survive a restart. There is
+// one queue per gateway, kept in the default agent's workspace whichever
+// agent a message was for.
+type retryQueue struct {
+	mu           sync.Mutex
+	path         string
+	maxSize      int
+	ttl          time.Duration
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	entries      []*retryEntry
+}
+
+type retryEntry struct {
+	Message     bus.InboundMessage `json:"message"`
+	Attempts    int                `json:"attempts"` // failed turns so far
+	QueuedAt    time.Time          `json:"queued_at"`
+	NextAttempt time.Time          `json:"next_attempt"`
+	LastError   string             `json:"last_error,omitempty"`
+}
+
+func newRetryQueue(path string, cfg config.RetryQueueConfig) *retryQueue {
+	q := &retryQueue{path: path}
+	q.configure(cfg)
+	q.load()
+	return q
+}
+
+// configure applies the limits of cfg, falling back to the defaults for
+// unset fields.
+func (q *retryQueue) configure(cfg config.RetryQueueConfig) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.maxSize = defaultRetryQueueSize
+	q.ttl = defaultRetryQueueTTL
+	q.initialDelay = defaultRetryInitialDelay
+	q.maxDelay = defaultRetryMaxDelay
+	if cfg.MaxSize > 0 {
+		q.maxSize = cfg.MaxSize
+	}
+	if cfg.TTLMinutes > 0 {
+		q.ttl = time.Duration(cfg.TTLMinutes) * time.Minute
+	}
+	if cfg.InitialDelaySec > 0 {
+		q.initialDelay = time.Duration(cfg.InitialDelaySec) * time.Second
+	}
+	if cfg.MaxDelaySec > 0 {
+		q.maxDelay = time.Duration(cfg.MaxDelaySec) * time.Second
+	}
+	q.maxDelay = max(q.maxDelay, q.initialDelay)
+}
+
+// moveTo hands every queued message over to next, which is a queue stored
+// elsewhere, and empties q. Messages next already holds are not duplicated.
+func (q *retryQueue) moveTo(next *retryQueue) {
+	q.mu.Lock()
+	entries := q.entries
+	q.entries = nil
+	q.save()
+	q.mu.Unlock()
+
+	next.mu.Lock()
+	defer next.mu.Unlock()
+	for _, e := range entries {
+		if next.find(e.Message.TraceID) < 0 {
+			next.entries = append(next.entries, e)
+		}
+	}
+	next.save()
+}
+
+func (q *retryQueue) load() {
+	data, err := os.ReadFile(q.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.WarnCF("agent", "Failed to read retry queue", map[string]any{"path": q.path, "error": err.Error()})
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &q.entries); err != nil {
+		logger.WarnCF("agent", "Ignoring unreadable retry queue", map[string]any{"path": q.path, "error": err.Error()})
+		q.entries = nil
+	}
+}
+
+// save writes the queue to disk. Callers hold q.mu.
+func (q *retryQueue) save() {
+	data, err := json.MarshalIndent(q.entries, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(q.path), 0o700)
+	}
+	if err == nil {
+		err = fileutil.WriteFileAtomic(q.path, data, 0o600)
+	}
+	if err != nil {
+		logger.WarnCF("agent", "Failed to save retry queue", map[string]any{"path": q.path, "error": err.Error()})
+	}
+}
+
+// delay is the wait before the next retry of a message that has failed
+// attempts times: the initial delay, doubled per failure up to the maximum.
+func (q *retryQueue) delay(attempts int) time.Duration {
+	d := q.initialDelay
+	for i := 1; i < attempts && d < q.maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, q.maxDelay)
+}
+
+func (q *retryQueue) find(traceID string) int {
+	if traceID == "" {
+		return -1
+	}
+	return slices.IndexFunc(q.entries, func(e *retryEntry) bool { return e.Message.TraceID == traceID })
+}
+
+// fail records that msg's turn failed because no provider was available. A
+// new message is queued unless the queue is full; a queued one is rescheduled
+// unless it has outlived the TTL, in which case it is dropped. It reports
+// whether msg is queued afterwards and whether it was queued before.
+func (q *retryQueue) fail(msg bus.InboundMessage, cause error, now time.Time) (queued, retried bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if i := q.find(msg.TraceID); i >= 0 {
+		e := q.entries[i]
+		if now.Sub(e.QueuedAt) >= q.ttl {
+			q.entries = slices.Delete(q.entries, i, i+1)
+			q.save()
+			return false, true
+		}
+		e.Attempts++
+		e.LastError = cause.Error()
+		e.NextAttempt = now.Add(q.delay(e.Attempts))
+		q.save()
+		return true, true
+	}
+
+	if len(q.entries) >= q.maxSize {
+		return false, false
+	}
+	q.entries = append(q.entries, &retryEntry{
+		Message:     msg,
+		Attempts:    1,
+		QueuedAt:    now,
+		NextAttempt: now.Add(q.delay(1)),
+		LastError:   cause.Error(),
+	})
+	q.save()
+	return true, false
+}
+
+// done removes the message with traceID and reports whether it was queued.
+func (q *retryQueue) done(traceID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.find(traceID)
+	if i < 0 {
+		return false
+	}
+	q.entries = slices.Delete(q.entries, i, i+1)
+	q.save()
+	return true
+}
+
+// due returns the queued messages whose next attempt has come and for which
+// ready is true, and removes and returns those past the TTL. A due message is
+// pushed back by its current backoff so it is not handed out again while its
+// retry is still running.
+func (q *retryQueue) due(now time.Time, ready func(bus.InboundMessage) bool) (due, expired []bus.InboundMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	changed := false
+	q.entries = slices.DeleteFunc(q.entries, func(e *retryEntry) bool {
+		if now.Sub(e.QueuedAt) >= q.ttl {
+			expired = append(expired, e.Message)
+			changed = true
+			return true
+		}
+		if now.Before(e.NextAttempt) || !ready(e.Message) {
+			return false
+		}
+		e.NextAttempt = now.Add(q.delay(e.Attempts))
+		due = append(due, e.Message)
+		changed = true
+		return false
+	})
+	if changed {
+		q.save()
+	}
+	return due, expired
+}
+
+func (q *retryQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// outageError marks a turn that failed on its first LLM call because no
+// provider was available. rollback removes the turn's user message from the
+// session, so that a retry of the message does not store it twice.
+type outageError struct {
+	err      error
+	rollback func() error
+}
+
+func (e *outageError) Error() string { return e.err.Error() }
+func (e *outageError) Unwrap() error { return e.err }
+
+// isProviderOutage reports whether err means no provider could answer for a
+// reason that passes on its own: rate limits, overload, timeouts and network
+// errors. Auth, billing and request errors need someone to act first.
+func isProviderOutage(err error) bool {
+	var exhausted *providers.FallbackExhaustedError
+	if errors.As(err, &exhausted) {
+		for _, attempt := range exhausted.Attempts {
+			if !attempt.Skipped && !transientFailover(attempt.Reason) {
+				return false
+			}
+		}
+		return len(exhausted.Attempts) > 0
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	fe := providers.ClassifyError(err, "", "")
+	return fe != nil && transientFailover(fe.Reason)
+}
+
+func transientFailover(reason providers.FailoverReason) bool {
+	switch reason {
+	case providers.FailoverRateLimit, providers.FailoverOverloaded,
+		providers.FailoverTimeout, providers.FailoverNetwork:
+		return true
+	}
+	return false
+}
+
+// settleRetry updates the retry queue with the outcome of processing msg. It
+// reports whether msg was queued, in which case the caller sends no error
+// reply: the user is told once that the message will be answered later.
+func (al *AgentLoop) settleRetry(ctx context.Context, msg bus.InboundMessage, err error) bool {
+	queue := al.retryQueue.Load()
+	if queue == nil {
+		return false
+	}
+	var outage *outageError
+	if !errors.As(err, &outage) || len(msg.Media) > 0 || msg.Channel == "system" {
+		if queue.done(msg.TraceID) {
+			logger.InfoCtx(ctx, "agent", "Queued message retried", map[string]any{
+				"channel":   msg.Channel,
+				"chat_id":   msg.ChatID,
+				"succeeded": err == nil,
+			})
+		}
+		return false
+	}
+
+	if msg.TraceID == "" {
+		msg.TraceID = logger.NewTraceID()
+	}
+	queued, retried := queue.fail(msg, err, time.Now())
+	if !queued {
+		reason := "queue full"
+		if retried {
+			reason = "ttl expired"
+		}
+		logger.WarnCtx(ctx, "agent", "Message not queued for retry", map[string]any{
+			"channel": msg.Channel,
+			"chat_id": msg.ChatID,
+			"reason":  reason,
+		})
+		return false
+	}
+	if rbErr := outage.rollback(); rbErr != nil {
+		logger.WarnCtx(ctx, "agent", "Failed to roll back session for queued message", map[string]any{
+			"session_key": msg.SessionKey,
+			"error":       rbErr.Error(),
+		})
+	}
+	logger.InfoCtx(ctx, "agent", "Queued message for retry", map[string]any{
+		"channel":     msg.Channel,
+		"chat_id":     msg.ChatID,
+		"queue_depth": queue.len(),
+	})
+	if !retried {
+		al.PublishResponseIfNeeded(ctx, msg.Channel, msg.ChatID, msg.SessionKey,
+			i18n.Text(al.replyLocale(ctx, msg.Channel), i18n.ReplyQueued))
+	}
+	return true
+}
+
+// runRetryQueue redelivers queued messages as they come due until ctx is
+// done. It keeps polling while the queue is disabled, since a config reload
+// can enable it.
+func (al *AgentLoop) runRetryQueue(ctx context.Context) {
+	ticker := time.NewTicker(retryQueuePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			al.retryDueMessages(ctx, time.Now())
+		}
+	}
+}
+
+// retryDueMessages redelivers due messages through the bus, so they are
+// answered in their original chat, and tells the senders of expired ones that
+// their message was dropped.
+func (al *AgentLoop) retryDueMessages(ctx context.Context, now time.Time) {
+	queue := al.retryQueue.Load()
+	if queue == nil {
+		return
+	}
+	due, expired := queue.due(now, al.retryReady)
+	for _, msg := range expired {
+		msgCtx := logger.WithTraceID(ctx, msg.TraceID)
+		logger.WarnCtx(msgCtx, "agent", "Gave up on queued message", map[string]any{
+			"channel": msg.Channel,
+			"chat_id": msg.ChatID,
+		})
+		al.PublishResponseIfNeeded(msgCtx, msg.Channel, msg.ChatID, msg.SessionKey,
+			i18n.Text(al.localeFor(msg.Channel, msg.Sender.Language), i18n.ReplyRetryExpired))
+	}
+	for _, msg := range due {
+		if err := al.bus.RedeliverInbound(ctx, msg); err != nil {
+			logger.WarnCtx(logger.WithTraceID(ctx, msg.TraceID), "agent", "Failed to redeliver queued message",
+				map[string]any{"error": err.Error()})
+		}
+	}
+}
+
+// configureRetryQueue builds the retry queue for cfg, or drops it when the
+// queue is disabled. It runs at startup and again on config reload. When the
+// default agent's workspace changes, queued messages move to the new one; a
+// disabled queue keeps its file and resumes when it is enabled again.
+func (al *AgentLoop) configureRetryQueue(cfg *config.Config) {
+	rq := cfg.Agents.Defaults.RetryQueue
+	var defaultAgent *AgentInstance
+	if registry := al.GetRegistry(); registry != nil {
+		defaultAgent = registry.GetDefaultAgent()
+	}
+	if !rq.Enabled || defaultAgent == nil {
+		al.retryQueue.Store(nil)
+		return
+	}
+
+	path := filepath.Join(defaultAgent.Workspace, "state", "retry_queue.json")
+	current := al.retryQueue.Load()
+	if current != nil && current.path == path {
+		current.configure(rq)
+		return
+	}
+	next := newRetryQueue(path, rq)
+	if current != nil {
+		current.moveTo(next)
+	}
+	al.retryQueue.Store(next)
+}
+
+// retryReady holds a queued message back while its session has a turn
+// running, where it would be taken as steering and its outcome lost.
+func (al *AgentLoop) retryReady(msg bus.InboundMessage) bool {
+	sessionKey, _, ok := al.resolveSteeringTarget(msg)
+	if !ok {
+		return true
+	}
+	_, busy := al.activeTurnStates.Load(sessionKey)
+	return !busy
+}
@@ -0,0 +1,227 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestRetryQueue_BoundsBackoffAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "retry_queue.json")
+	q := newRetryQueue(path, config.RetryQueueConfig{
+		Enabled: true, MaxSize: 2, TTLMinutes: 60, InitialDelaySec: 10, MaxDelaySec: 25,
+	})
+	now := time.Now()
+	outage := errors.New("status 503")
+	msg := func(id string) bus.InboundMessage {
+		return bus.InboundMessage{Channel: "telegram", ChatID: "c1", Content: id, TraceID: id}
+	}
+
+	if queued, retried := q.fail(msg("a"), outage, now); !queued || retried {
+		t.Fatalf("fail(a) = %v, %v; want newly queued", queued, retried)
+	}
+	q.fail(msg("b"), outage, now)
+	if queued, _ := q.fail(msg("c"), outage, now); queued {
+		t.Fatal("fail(c) queued a message past max_size")
+	}
+
+	for attempts, want := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 25 * time.Second} {
+		if got := q.delay(attempts); got != want {
+			t.Errorf("delay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+
+	ready := func(bus.InboundMessage) bool { return true }
+	if due, _ := q.due(now.Add(5*time.Second), ready); len(due) != 0 {
+		t.Fatalf("due before the first delay = %v", due)
+	}
+	due, _ := q.due(now.Add(10*time.Second), ready)
+	if len(due) != 2 {
+		t.Fatalf("due after the first delay = %d messages, want 2", len(due))
+	}
+	if again, _ := q.due(now.Add(11*time.Second), ready); len(again) != 0 {
+		t.Fatal("a message in flight was handed out again")
+	}
+
+	if !q.done("a") || q.done("a") {
+		t.Fatal("done(a) should remove the message once")
+	}
+
+	reloaded := newRetryQueue(path, config.RetryQueueConfig{Enabled: true, TTLMinutes: 60})
+	if reloaded.len() != 1 {
+		t.Fatalf("reloaded queue has %d messages, want 1", reloaded.len())
+	}
+	due, expired := reloaded.due(now.Add(time.Hour), ready)
+	if len(due) != 0 || len(expired) != 1 || expired[0].TraceID != "b" {
+		t.Fatalf("due past the TTL = %v, expired = %v; want b expired", due, expired)
+	}
+	if reloaded.len() != 0 {
+		t.Fatal("expired message was kept")
+	}
+}
+
+func TestIsProviderOutage(t *testing.T) {
+	overloaded := &providers.ProviderError{Provider: "openai", Status: 503, Err: errors.New("status 503")}
+	auth := &providers.ProviderError{Provider: "openai", Status: 401, Err: errors.New("status 401")}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"single provider overloaded", overloaded, true},
+		{"single provider auth", auth, false},
+		{"canceled", context.Canceled, false},
+		{"all transient or cooling down", &providers.FallbackExhaustedError{Attempts: []providers.FallbackAttempt{
+			{Reason: providers.FailoverRateLimit}, {Skipped: true}, {Reason: providers.FailoverTimeout},
+		}}, true},
+		{"one needs a fix", &providers.FallbackExhaustedError{Attempts: []providers.FallbackAttempt{
+			{Reason: providers.FailoverOverloaded}, {Reason: providers.FailoverBilling},
+		}}, false},
+	}
+	for _, tt := range tests {
+		if got := isProviderOutage(tt.err); got != tt.want {
+			t.Errorf("%s: isProviderOutage() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// outageProvider fails with a 503 while down is set.
+type outageProvider struct {
+	down atomic.Bool
+}
+
+func (p *outageProvider) Chat(
+	context.Context, []providers.Message, []providers.ToolDefinition, string, map[string]any,
+) (*providers.LLMResponse, error) {
+	if p.down.Load() {
+		return nil, &providers.ProviderError{Provider: "openai", Status: 503, Err: errors.New("status 503")}
+	}
+	return &providers.LLMResponse{Content: "answered after the outage"}, nil
+}
+
+func (p *outageProvider) GetDefaultModel() string { return "test-model" }
+
+func TestAgentLoop_RetriesQueuedMessageAfterOutage(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 5,
+				RetryQueue:        config.RetryQueueConfig{Enabled: true},
+			},
+		},
+	}
+	provider := &outageProvider{}
+	provider.down.Store(true)
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, provider)
+	ctx := context.Background()
+
+	nextOutbound := func() string {
+		t.Helper()
+		select {
+		case out := <-msgBus.OutboundChan():
+			return out.Content
+		case <-time.After(5 * time.Second):
+			t.Fatal("no outbound message")
+			return ""
+		}
+	}
+
+	msg := bus.InboundMessage{
+		Channel: "telegram", ChatID: "c1", SenderID: "u1", Content: "what's the weather?", TraceID: "trace-1",
+	}
+	al.processMessageSync(ctx, msg)
+	if got, want := nextOutbound(), i18n.Text("", i18n.ReplyQueued); got != want {
+		t.Fatalf("reply during outage = %q, want %q", got, want)
+	}
+	if al.retryQueue.Load().len() != 1 {
+		t.Fatalf("queue length = %d, want 1", al.retryQueue.Load().len())
+	}
+	agent := al.GetRegistry().GetDefaultAgent()
+	for _, key := range agent.Sessions.ListSessions() {
+		if history := agent.Sessions.GetHistory(key); len(history) != 0 {
+			t.Fatalf("session %s kept %d messages from the failed turn", key, len(history))
+		}
+	}
+
+	provider.down.Store(false)
+	al.retryDueMessages(ctx, time.Now().Add(time.Minute))
+	var redelivered bus.InboundMessage
+	select {
+	case redelivered = <-msgBus.InboundChan():
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued message was not redelivered")
+	}
+	if redelivered.TraceID != "trace-1" || redelivered.ChatID != "c1" {
+		t.Fatalf("redelivered = %+v", redelivered)
+	}
+
+	al.processMessageSync(ctx, redelivered)
+	if got := nextOutbound(); got != "answered after the outage" {
+		t.Fatalf("reply after recovery = %q", got)
+	}
+	if al.retryQueue.Load().len() != 0 {
+		t.Fatalf("queue length after recovery = %d, want 0", al.retryQueue.Load().len())
+	}
+}
+
+func TestAgentLoop_ReloadConfiguresRetryQueue(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 5,
+			},
+		},
+	}
+	provider := &outageProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	ctx := context.Background()
+	if al.retryQueue.Load() != nil {
+		t.Fatal("retry queue exists while disabled")
+	}
+
+	enabled := *cfg
+	enabled.Agents.Defaults.RetryQueue = config.RetryQueueConfig{Enabled: true, MaxSize: 1}
+	if err := al.ReloadProviderAndConfig(ctx, provider, &enabled); err != nil {
+		t.Fatalf("ReloadProviderAndConfig() error = %v", err)
+	}
+	queue := al.retryQueue.Load()
+	if queue == nil {
+		t.Fatal("reload did not create the retry queue")
+	}
+	outage := errors.New("status 503")
+	queue.fail(bus.InboundMessage{Channel: "telegram", ChatID: "c1", TraceID: "a"}, outage, time.Now())
+
+	resized := enabled
+	resized.Agents.Defaults.RetryQueue.MaxSize = 2
+	if err := al.ReloadProviderAndConfig(ctx, provider, &resized); err != nil {
+		t.Fatalf("ReloadProviderAndConfig() error = %v", err)
+	}
+	if al.retryQueue.Load() != queue || queue.len() != 1 {
+		t.Fatal("reload with the same workspace replaced the queue or lost its messages")
+	}
+	if queued, _ := queue.fail(bus.InboundMessage{Channel: "telegram", ChatID: "c1", TraceID: "b"}, outage, time.Now()); !queued {
+		t.Fatal("reload did not apply the new max_size")
+	}
+
+	if err := al.ReloadProviderAndConfig(ctx, provider, cfg); err != nil {
+		t.Fatalf("ReloadProviderAndConfig() error = %v", err)
+	}
+	if al.retryQueue.Load() != nil {
+		t.Fatal("reload did not disable the retry queue")
+	}
+}
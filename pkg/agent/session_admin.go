@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+var (
+	// ErrSessionBusy is returned by ResetSession while a turn for the session
+	// is in progress.
+	ErrSessionBusy = errors.New("session has a turn in progress")
+	// ErrSessionNotFound is returned by ResetSession for an unknown session key.
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// SessionInfo summarizes one stored conversation.
+type SessionInfo struct {
+	Key          string    `json:"key"`
+	AgentID      string    `json:"agent_id"`
+	Channel      string    `json:"channel,omitempty"`
+	MessageCount int       `json:"message_count"`
+	LastActivity time.Time `json:"last_activity,omitzero"`
+	Active       bool      `json:"active"`
+}
+
+// ListSessions returns the sessions known to every agent's session store,
+// most recently active first.
+func (al *AgentLoop) ListSessions() []SessionInfo {
+	var infos []SessionInfo
+	for _, agent := range al.sessionAgents() {
+		for _, key := range agent.Sessions.ListSessions() {
+			info := SessionInfo{
+				Key:     key,
+				AgentID: agent.ID,
+				Active:  al.getActiveTurnState(key) != nil,
+			}
+			// Stats come from metadata so listing does not reload evicted
+			// sessions into memory.
+			if store, ok := agent.Sessions.(session.StatsAwareSessionStore); ok {
+				info.MessageCount, info.LastActivity = store.SessionStats(key)
+			} else {
+				info.MessageCount = len(agent.Sessions.GetHistory(key))
+				if store, ok := agent.Sessions.(session.ActivityAwareSessionStore); ok {
+					info.LastActivity = store.LastActivity(key)
+				}
+			}
+			if store, ok := agent.Sessions.(session.MetadataAwareSessionStore); ok {
+				if scope := store.GetSessionScope(key); scope != nil {
+					info.Channel = scope.Channel
+					if scope.AgentID != "" {
+						info.AgentID = scope.AgentID
+					}
+				}
+			}
+			infos = append(infos, info)
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].LastActivity.After(infos[j].LastActivity)
+	})
+	return infos
+}
+
+// ResetSession clears the stored history, summary, pending skill override and
+// usage totals of a session. It returns ErrSessionBusy instead of waiting when
+// a turn for the session is running, and holds the session for the duration
+// of the reset so no new turn can start halfway through.
+func (al *AgentLoop) ResetSession(ctx context.Context, sessionKey string) error {
+	sessionKey = strings.TrimSpace(sessionKey)
+	if sessionKey == "" {
+		return ErrSessionNotFound
+	}
+
+	placeholder := &turnState{
+		turnID: fmt.Sprintf("pending-reset-%s-%d", sessionKey, al.turnSeq.Add(1)),
+		phase:  TurnPhaseSetup,
+	}
+	if _, loaded := al.activeTurnStates.LoadOrStore(sessionKey, placeholder); loaded {
+		return ErrSessionBusy
+	}
+	defer al.activeTurnStates.Delete(sessionKey)
+
+	known := false
+	for _, agent := range al.sessionAgents() {
+		if len(agent.Sessions.GetHistory(sessionKey)) > 0 || agent.Sessions.GetSummary(sessionKey) != "" {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return ErrSessionNotFound
+	}
+
+	return al.clearSession(ctx, al.agentForSession(sessionKey), sessionKey)
+}
+
+// clearSession wipes a session through the context manager and, when the
+// session is routed to a non-default agent, through that agent's own store.
+func (al *AgentLoop) clearSession(ctx context.Context, agent *AgentInstance, sessionKey string) error {
+	if err := al.contextManager.Clear(ctx, sessionKey); err != nil {
+		return err
+	}
+	// The context manager persists through the default agent's session
+	// store; a session routed to another agent keeps its own history.
+	if agent != nil && agent.Sessions != nil {
+		if def := al.registry.GetDefaultAgent(); def == nil || def.Sessions != agent.Sessions {
+			agent.Sessions.SetHistory(sessionKey, []providers.Message{})
+			agent.Sessions.SetSummary(sessionKey, "")
+			if err := agent.Sessions.Save(sessionKey); err != nil {
+				return err
+			}
+		}
+	}
+	al.clearPendingSkills(sessionKey)
+	al.resetSessionUsage(sessionKey)
+	al.resetSessionBudget(sessionKey)
+	return nil
+}
+
+// sessionAgents returns one agent per distinct session store.
+func (al *AgentLoop) sessionAgents() []*AgentInstance {
+	registry := al.GetRegistry()
+	if registry == nil {
+		return nil
+	}
+	ids := registry.ListAgentIDs()
+	sort.Strings(ids)
+	seen := make(map[session.SessionStore]bool, len(ids))
+	agents := make([]*AgentInstance, 0, len(ids))
+	for _, id := range ids {
+		agent, ok := registry.GetAgent(id)
+		if !ok || agent == nil || agent.Sessions == nil || seen[agent.Sessions] {
+			continue
+		}
+		seen[agent.Sessions] = true
+		agents = append(agents, agent)
+	}
+	return agents
+}
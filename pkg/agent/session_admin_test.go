@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestAgentLoop_ListAndResetSession(t *testing.T) {
+	al := newUsageTestLoop(t)
+	ctx := context.Background()
+
+	if _, err := al.processMessage(ctx, bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:123",
+		ChatID:   "chat-1",
+		Content:  "hello",
+	}); err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+
+	sessions := al.ListSessions()
+	if len(sessions) != 1 {
+		t.Fatalf("ListSessions() = %+v, want 1 session", sessions)
+	}
+	info := sessions[0]
+	if info.MessageCount != 2 {
+		t.Errorf("MessageCount = %d, want 2", info.MessageCount)
+	}
+	if info.Channel != "telegram" {
+		t.Errorf("Channel = %q, want telegram", info.Channel)
+	}
+	if info.LastActivity.IsZero() {
+		t.Error("LastActivity is zero")
+	}
+	if info.Active {
+		t.Error("session reported active with no turn running")
+	}
+
+	// A turn holding the session makes the reset back off.
+	al.activeTurnStates.Store(info.Key, &turnState{turnID: "running"})
+	if err := al.ResetSession(ctx, info.Key); !errors.Is(err, ErrSessionBusy) {
+		t.Fatalf("ResetSession() during turn error = %v, want ErrSessionBusy", err)
+	}
+	al.activeTurnStates.Delete(info.Key)
+
+	if err := al.ResetSession(ctx, info.Key); err != nil {
+		t.Fatalf("ResetSession() error = %v", err)
+	}
+	if history := al.GetRegistry().GetDefaultAgent().Sessions.GetHistory(info.Key); len(history) != 0 {
+		t.Fatalf("history after reset = %d messages, want 0", len(history))
+	}
+	if al.getActiveTurnState(info.Key) != nil {
+		t.Fatal("reset left its claim on the session")
+	}
+	if err := al.ResetSession(ctx, info.Key); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("ResetSession() on empty session error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestAgentLoop_ExportSession(t *testing.T) {
+	al := newUsageTestLoop(t)
+	store := al.GetRegistry().GetDefaultAgent().Sessions
+	const key = "agent:main:export-test"
+	store.AddMessage(key, "user", "list the files")
+	store.AddFullMessage(key, providers.Message{
+		Role:             "assistant",
+		ReasoningContent: "I should call ls",
+		ToolCalls: []providers.ToolCall{{
+			ID:       "call-1",
+			Type:     "function",
+			Function: &providers.FunctionCall{Name: "exec", Arguments: `{"command":"ls"}`},
+		}},
+	})
+	store.AddFullMessage(key, providers.Message{Role: "tool", ToolCallID: "call-1", Content: "a.txt\n```\nb.txt"})
+	store.AddMessage(key, "assistant", "There are two files.")
+
+	md, err := al.ExportSession(key, "md", true)
+	if err != nil {
+		t.Fatalf("ExportSession(markdown) error = %v", err)
+	}
+	for _, want := range []string{
+		"# Session " + key, "## User\n\nlist the files", "### Tool call `exec` (`call-1`)",
+		"```json\n{\"command\":\"ls\"}\n```", "````\na.txt\n```\nb.txt\n````", "## Assistant\n\nThere are two files.",
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("markdown export missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(string(md), "I should call ls") {
+		t.Error("markdown export contains reasoning content")
+	}
+
+	raw, err := al.ExportSession(key, ExportFormatJSON, false)
+	if err != nil {
+		t.Fatalf("ExportSession(json) error = %v", err)
+	}
+	var export SessionExport
+	if err := json.Unmarshal(raw, &export); err != nil {
+		t.Fatalf("unmarshal export: %v", err)
+	}
+	if export.Key != key || len(export.Messages) != 2 {
+		t.Fatalf("json export = %+v, want the user and final assistant message only", export)
+	}
+
+	if _, err := al.ExportSession(key, "pdf", false); !errors.Is(err, ErrExportFormat) {
+		t.Errorf("ExportSession(pdf) error = %v, want ErrExportFormat", err)
+	}
+	if _, err := al.ExportSession("agent:main:missing", "json", false); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("ExportSession(missing) error = %v, want ErrSessionNotFound", err)
+	}
+}
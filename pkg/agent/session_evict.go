@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// SessionStats reports session state held in memory by the agent loop.
+type SessionStats struct {
+	InMemory int    `json:"in_memory"` // sessions currently held by in-memory stores
+	Evicted  uint64 `json:"evicted"`   // idle sessions released since start
+}
+
+// SessionStats returns the current in-memory session count and eviction total.
+func (al *AgentLoop) SessionStats() SessionStats {
+	stats := SessionStats{Evicted: al.sessionsEvicted.Load()}
+	for _, agent := range al.sessionAgents() {
+		if store, ok := agent.Sessions.(session.EvictableSessionStore); ok {
+			stats.InMemory += store.LoadedCount()
+		}
+	}
+	return stats
+}
+
+func (al *AgentLoop) sessionIdleTTL() time.Duration {
+	cfg := al.GetConfig()
+	if cfg == nil || cfg.Agents.Defaults.SessionIdleMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.Agents.Defaults.SessionIdleMinutes) * time.Minute
+}
+
+// sessionSweepInterval checks a quarter as often as the TTL, bounded so short
+// TTLs don't spin and long ones still release memory within ten minutes.
+func sessionSweepInterval(idle time.Duration) time.Duration {
+	return min(max(idle/4, 30*time.Second), 10*time.Minute)
+}
+
+// runSessionEviction periodically releases idle sessions until ctx is done.
+// It does nothing when session_idle_minutes is unset.
+func (al *AgentLoop) runSessionEviction(ctx context.Context) {
+	idle := al.sessionIdleTTL()
+	if idle <= 0 {
+		return
+	}
+	ticker := time.NewTicker(sessionSweepInterval(idle))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := al.evictIdleSessions(idle); n > 0 {
+				logger.DebugCF("agent", "Evicted idle sessions", map[string]any{"count": n})
+			}
+		}
+	}
+}
+
+// evictIdleSessions releases sessions not touched within idle. Sessions with
+// a turn running or reserved are skipped; a session evicted from a disk-backed
+// store is reloaded transparently on its next message.
+func (al *AgentLoop) evictIdleSessions(idle time.Duration) int {
+	busy := func(key string) bool {
+		_, ok := al.activeTurnStates.Load(key)
+		return ok
+	}
+
+	evicted := 0
+	for _, agent := range al.sessionAgents() {
+		if store, ok := agent.Sessions.(session.EvictableSessionStore); ok {
+			evicted += store.EvictIdle(idle, busy)
+		}
+	}
+
+	cutoff := time.Now().Add(-idle)
+	al.sessionUsage.Range(func(key, value any) bool {
+		su := value.(*sessionUsage)
+		su.mu.Lock()
+		stale := su.updated.Before(cutoff)
+		su.mu.Unlock()
+		if stale && !busy(key.(string)) {
+			al.sessionUsage.Delete(key)
+		}
+		return true
+	})
+
+	al.sessionSpend.Range(func(key, value any) bool {
+		spend := value.(*sessionSpend)
+		spend.mu.Lock()
+		stale := spend.updated.Before(cutoff)
+		spend.mu.Unlock()
+		if stale && !busy(key.(string)) {
+			al.sessionSpend.Delete(key)
+		}
+		return true
+	})
+
+	al.sessionsEvicted.Add(uint64(evicted))
+	return evicted
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+func TestEvictIdleSessions_SkipsActiveTurnsAndReloads(t *testing.T) {
+	al := newUsageTestLoop(t)
+	agent := al.GetRegistry().GetDefaultAgent()
+	store := session.NewSessionManager(t.TempDir())
+	agent.Sessions = store
+
+	store.AddMessage("idle", "user", "hello")
+	store.AddMessage("running", "user", "working on it")
+	al.recordSessionUsage("idle", &providers.UsageInfo{PromptTokens: 5, CompletionTokens: 5})
+	al.activeTurnStates.Store("running", &turnState{turnID: "running"})
+	defer al.activeTurnStates.Delete("running")
+
+	time.Sleep(5 * time.Millisecond)
+	if n := al.evictIdleSessions(time.Millisecond); n != 1 {
+		t.Fatalf("evictIdleSessions() = %d, want 1", n)
+	}
+
+	stats := al.SessionStats()
+	if stats.InMemory != 1 || stats.Evicted != 1 {
+		t.Fatalf("SessionStats() = %+v, want 1 in memory and 1 evicted", stats)
+	}
+	if usage := al.SessionUsage("idle"); usage.Requests != 0 {
+		t.Fatalf("usage for evicted session = %+v, want reset", usage)
+	}
+	if history := agent.Sessions.GetHistory("idle"); len(history) != 1 {
+		t.Fatalf("history after eviction = %+v, want it reloaded from disk", history)
+	}
+}
+
+func TestSessionSweepInterval(t *testing.T) {
+	tests := []struct {
+		idle, want time.Duration
+	}{
+		{time.Minute, 30 * time.Second},
+		{20 * time.Minute, 5 * time.Minute},
+		{24 * time.Hour, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := sessionSweepInterval(tt.idle); got != tt.want {
+			t.Errorf("sessionSweepInterval(%v) = %v, want %v", tt.idle, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// Session export formats accepted by ExportSession.
+const (
+	ExportFormatMarkdown = "markdown"
+	ExportFormatJSON     = "json"
+)
+
+// ErrExportFormat is returned by ExportSession for an unknown format.
+var ErrExportFormat = errors.New("unsupported export format")
+
+// SessionExport is the JSON form of an exported session.
+type SessionExport struct {
+	Key        string              `json:"key"`
+	Summary    string              `json:"summary,omitempty"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Messages   []providers.Message `json:"messages"`
+}
+
+// ExportSession renders the stored history of a session as Markdown or JSON
+// for archiving. Tool calls and tool results are kept only when includeTools
+// is set; reasoning content is always left out. It returns ErrSessionNotFound
+// for a session with no history and no summary.
+func (al *AgentLoop) ExportSession(sessionKey, format string, includeTools bool) ([]byte, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "md" {
+		format = ExportFormatMarkdown
+	}
+	if format != ExportFormatMarkdown && format != ExportFormatJSON {
+		return nil, fmt.Errorf("%w: %q", ErrExportFormat, format)
+	}
+
+	sessionKey = strings.TrimSpace(sessionKey)
+	var history []providers.Message
+	var summary string
+	found := false
+	for _, agent := range al.sessionAgents() {
+		history = agent.Sessions.GetHistory(sessionKey)
+		summary = agent.Sessions.GetSummary(sessionKey)
+		if len(history) > 0 || summary != "" {
+			found = true
+			break
+		}
+	}
+	if sessionKey == "" || !found {
+		return nil, ErrSessionNotFound
+	}
+
+	export := SessionExport{
+		Key:        sessionKey,
+		Summary:    summary,
+		ExportedAt: time.Now().UTC(),
+		Messages:   exportMessages(history, includeTools),
+	}
+	if format == ExportFormatJSON {
+		return json.MarshalIndent(export, "", "  ")
+	}
+	return []byte(renderSessionMarkdown(export)), nil
+}
+
+// exportMessages copies the user-facing part of a history: system messages
+// and reasoning are dropped, and so are tool calls and results unless
+// includeTools is set.
+func exportMessages(history []providers.Message, includeTools bool) []providers.Message {
+	out := make([]providers.Message, 0, len(history))
+	for _, msg := range history {
+		switch msg.Role {
+		case "user", "assistant":
+		case "tool":
+			if !includeTools {
+				continue
+			}
+		default:
+			continue
+		}
+		msg.ReasoningContent = ""
+		msg.SystemParts = nil
+		if !includeTools {
+			msg.ToolCalls = nil
+			msg.ToolCallID = ""
+		}
+		if strings.TrimSpace(msg.Content) == "" && len(msg.Media) == 0 && len(msg.ToolCalls) == 0 {
+			continue
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+func renderSessionMarkdown(export SessionExport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Session %s\n\nExported %s\n", export.Key, export.ExportedAt.Format(time.RFC3339))
+	if export.Summary != "" {
+		fmt.Fprintf(&b, "\n## Summary\n\n%s\n", strings.TrimSpace(export.Summary))
+	}
+
+	for _, msg := range export.Messages {
+		switch msg.Role {
+		case "user":
+			b.WriteString("\n## User\n")
+		case "assistant":
+			b.WriteString("\n## Assistant\n")
+		case "tool":
+			fmt.Fprintf(&b, "\n### Tool result `%s`\n\n", msg.ToolCallID)
+			writeCodeBlock(&b, "", msg.Content)
+			continue
+		}
+		if content := strings.TrimSpace(msg.Content); content != "" {
+			fmt.Fprintf(&b, "\n%s\n", content)
+		}
+		for _, ref := range msg.Media {
+			fmt.Fprintf(&b, "\n- Attachment: `%s`\n", ref)
+		}
+		for _, tc := range msg.ToolCalls {
+			name, args := tc.Name, ""
+			if tc.Function != nil {
+				if name == "" {
+					name = tc.Function.Name
+				}
+				args = tc.Function.Arguments
+			}
+			if args == "" && len(tc.Arguments) > 0 {
+				if encoded, err := json.Marshal(tc.Arguments); err == nil {
+					args = string(encoded)
+				}
+			}
+			fmt.Fprintf(&b, "\n### Tool call `%s` (`%s`)\n\n", name, tc.ID)
+			writeCodeBlock(&b, "json", args)
+		}
+	}
+	return b.String()
+}
+
+// writeCodeBlock fences text with a run of backticks longer than any run
+// inside it, so tool output containing fences cannot break out.
+func writeCodeBlock(b *strings.Builder, lang, text string) {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(b, "%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(text, "\n"), fence)
+}
@@ -0,0 +1,254 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// savedModelResolveTimeout bounds resolving a saved session model, which
+// may list the provider's models in the middle of a turn.
+const savedModelResolveTimeout = 10 * time.Second
+
+// sessionModel is a model chosen for one session with the model tool. It
+// replaces the agent's candidates for that session's turns.
+type sessionModel struct {
+	agentID    string
+	name       string // as given to the tool: a model_list name or a provider model ID
+	provider   providers.LLMProvider
+	candidates []providers.FallbackCandidate
+	// ownsProvider is false when provider is the agent's own and must not
+	// be closed with the choice.
+	ownsProvider bool
+	// unavailable marks a saved choice that failed to resolve; the session
+	// uses the agent's model until the next config reload retries it.
+	unavailable bool
+}
+
+// sessionModelFor returns the model chosen for sessionKey, or nil when the
+// session uses the agent's model. Choices saved by an earlier run are
+// resolved again on first use, and after a config reload.
+func (al *AgentLoop) sessionModelFor(agent *AgentInstance, sessionKey string) *sessionModel {
+	if agent == nil || sessionKey == "" {
+		return nil
+	}
+	if v, ok := al.sessionModels.Load(sessionKey); ok {
+		if sm := v.(*sessionModel); sm.agentID == agent.ID && !sm.unavailable {
+			return sm
+		}
+		return nil
+	}
+	if al.state == nil {
+		return nil
+	}
+	name := al.state.GetSessionModel(sessionKey)
+	if name == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), savedModelResolveTimeout)
+	defer cancel()
+	sm, err := al.resolveSessionModel(ctx, agent, name)
+	if err != nil {
+		logger.WarnCF("agent", "Saved session model is no longer available, using the agent's model",
+			map[string]any{
+				"agent_id":    agent.ID,
+				"session_key": sessionKey,
+				"model":       name,
+				"error":       err.Error(),
+			})
+		// Remember the failure so later turns do not list models again.
+		sm = &sessionModel{agentID: agent.ID, name: name, unavailable: true}
+	}
+	if v, loaded := al.sessionModels.LoadOrStore(sessionKey, sm); loaded {
+		if sm.ownsProvider {
+			closeProviderIfStateful(sm.provider)
+		}
+		sm = v.(*sessionModel)
+	}
+	if sm.agentID != agent.ID || sm.unavailable {
+		return nil
+	}
+	return sm
+}
+
+// resetSessionModels forgets every resolved session model, closing the
+// providers they own. A reload calls it so choices are resolved again
+// against the new config and the agents' new providers.
+func (al *AgentLoop) resetSessionModels() {
+	al.sessionModels.Range(func(key, _ any) bool {
+		if v, loaded := al.sessionModels.LoadAndDelete(key); loaded {
+			if sm := v.(*sessionModel); sm.ownsProvider {
+				closeProviderIfStateful(sm.provider)
+			}
+		}
+		return true
+	})
+}
+
+// resolveSessionModel turns name into a provider and candidates. A
+// model_list name gets its own provider, like /switch model; any other name
+// must be a model ID the agent's provider reports, and is sent to it.
+func (al *AgentLoop) resolveSessionModel(
+	ctx context.Context,
+	agent *AgentInstance,
+	name string,
+) (*sessionModel, error) {
+	cfg := al.GetConfig()
+	if _, err := cfg.GetModelConfig(name); err == nil {
+		modelCfg, err := resolvedModelConfig(cfg, name, agent.Workspace)
+		if err != nil {
+			return nil, err
+		}
+		candidates := resolveModelCandidates(cfg, cfg.Agents.Defaults.Provider, name, agent.Fallbacks)
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("model %q did not resolve to any provider candidates", name)
+		}
+		factory := al.providerFactory
+		if factory == nil {
+			factory = providers.CreateProviderFromConfig
+		}
+		provider, _, err := factory(modelCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize model %q: %w", name, err)
+		}
+		return &sessionModel{
+			agentID:      agent.ID,
+			name:         name,
+			provider:     provider,
+			candidates:   candidates,
+			ownsProvider: true,
+		}, nil
+	}
+
+	available, err := providerModelIDs(ctx, agent.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("model %q is not in model_list and the provider's models could not be listed: %w",
+			name, err)
+	}
+	if !slices.Contains(available, name) {
+		return nil, fmt.Errorf("model %q is not in model_list and not offered by the active provider", name)
+	}
+	if len(agent.Candidates) == 0 {
+		return nil, fmt.Errorf("agent %q has no provider to send model %q to", agent.ID, name)
+	}
+	primary := agent.Candidates[0]
+	primary.Model = name
+	return &sessionModel{
+		agentID:    agent.ID,
+		name:       name,
+		provider:   agent.Provider,
+		candidates: []providers.FallbackCandidate{primary},
+	}, nil
+}
+
+// setSessionModel switches sessionKey to name, or back to the agent's model
+// when name is empty, and returns the model it used before.
+func (al *AgentLoop) setSessionModel(
+	ctx context.Context,
+	agent *AgentInstance,
+	sessionKey, name string,
+) (string, error) {
+	if sessionKey == "" {
+		return "", fmt.Errorf("no session to switch the model for")
+	}
+	previous := agent.Model
+	if current := al.sessionModelFor(agent, sessionKey); current != nil {
+		previous = current.name
+	}
+
+	var next *sessionModel
+	if name != "" {
+		sm, err := al.resolveSessionModel(ctx, agent, name)
+		if err != nil {
+			return "", err
+		}
+		next = sm
+	}
+	if al.state != nil {
+		if err := al.state.SetSessionModel(sessionKey, name); err != nil {
+			if next != nil && next.ownsProvider {
+				closeProviderIfStateful(next.provider)
+			}
+			return "", err
+		}
+	}
+
+	var old any
+	if next != nil {
+		old, _ = al.sessionModels.Swap(sessionKey, next)
+	} else {
+		old, _ = al.sessionModels.LoadAndDelete(sessionKey)
+	}
+	if sm, ok := old.(*sessionModel); ok && sm.ownsProvider {
+		closeProviderIfStateful(sm.provider)
+	}
+
+	logger.InfoCF("agent", "Session model switched", map[string]any{
+		"agent_id":    agent.ID,
+		"session_key": sessionKey,
+		"from":        previous,
+		"to":          name,
+	})
+	return previous, nil
+}
+
+// providerModelIDs lists the model IDs provider accepts, if it can tell.
+func providerModelIDs(ctx context.Context, provider providers.LLMProvider) ([]string, error) {
+	lister, ok := provider.(providers.ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support listing models")
+	}
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(models))
+	for _, m := range models {
+		if id := strings.TrimSpace(m.ID); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// sessionModelSwitcher gives the model tool control over the model of the
+// session it is called from.
+type sessionModelSwitcher struct {
+	al    *AgentLoop
+	agent *AgentInstance
+}
+
+var _ tools.ModelSwitcher = sessionModelSwitcher{}
+
+func (s sessionModelSwitcher) ListModels(ctx context.Context) (tools.ModelChoices, error) {
+	choices := tools.ModelChoices{
+		Current: s.agent.Model,
+		Default: s.agent.Model,
+	}
+	if sm := s.al.sessionModelFor(s.agent, tools.ToolSessionKey(ctx)); sm != nil {
+		choices.Current = sm.name
+	}
+	for _, mc := range s.al.GetConfig().ModelList {
+		if mc != nil && mc.ModelName != "" && !slices.Contains(choices.Configured, mc.ModelName) {
+			choices.Configured = append(choices.Configured, mc.ModelName)
+		}
+	}
+	if _, ok := s.agent.Provider.(providers.ModelLister); ok {
+		available, err := providerModelIDs(ctx, s.agent.Provider)
+		if err != nil {
+			choices.ListError = err.Error()
+		}
+		choices.Available = available
+	}
+	return choices, nil
+}
+
+func (s sessionModelSwitcher) SetModel(ctx context.Context, model string) (string, error) {
+	return s.al.setSessionModel(ctx, s.agent, tools.ToolSessionKey(ctx), model)
+}
@@ -0,0 +1,202 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// modelSwitchProvider lists two models and records the model of each call.
+// A user message "switch to X" makes it call the model tool with X.
+type modelSwitchProvider struct {
+	mu     sync.Mutex
+	models []string
+}
+
+func (p *modelSwitchProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	toolDefs []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.models = append(p.models, model)
+
+	last := messages[len(messages)-1]
+	if last.Role == "tool" {
+		return &providers.LLMResponse{Content: last.Content}, nil
+	}
+	if target, ok := strings.CutPrefix(last.Content, "switch to "); ok {
+		return &providers.LLMResponse{ToolCalls: []providers.ToolCall{{
+			ID:        "call-1",
+			Name:      "model",
+			Arguments: map[string]any{"action": "set", "model": target},
+		}}}, nil
+	}
+	return &providers.LLMResponse{Content: "ok"}, nil
+}
+
+func (p *modelSwitchProvider) GetDefaultModel() string {
+	return "base-model"
+}
+
+func (p *modelSwitchProvider) ListModels(context.Context) ([]providers.ModelInfo, error) {
+	return []providers.ModelInfo{{ID: "base-model"}, {ID: "big-model"}}, nil
+}
+
+func (p *modelSwitchProvider) lastModel() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.models[len(p.models)-1]
+}
+
+func newModelSwitchLoop(t *testing.T, workspace string, provider *modelSwitchProvider) *AgentLoop {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				ModelName:         "base-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Session: config.SessionConfig{Dimensions: []string{"sender"}},
+		Tools:   config.ToolsConfig{Model: config.ToolConfig{Enabled: true}},
+	}
+	return NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+}
+
+func sendAs(t *testing.T, al *AgentLoop, senderID, content string) string {
+	t.Helper()
+	resp, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "cli",
+		SenderID: senderID,
+		ChatID:   "direct",
+		Content:  content,
+	}))
+	if err != nil {
+		t.Fatalf("processMessage(%q) error = %v", content, err)
+	}
+	return resp
+}
+
+func TestModelTool_SwitchesModelForSessionOnly(t *testing.T) {
+	workspace := t.TempDir()
+	provider := &modelSwitchProvider{}
+	al := newModelSwitchLoop(t, workspace, provider)
+
+	resp := sendAs(t, al, "one", "switch to big-model")
+	if !strings.Contains(resp, "from base-model to big-model") {
+		t.Fatalf("set result = %q, want previous and new model", resp)
+	}
+
+	sendAs(t, al, "one", "hello")
+	if got := provider.lastModel(); got != "big-model" {
+		t.Fatalf("switched session used %q, want big-model", got)
+	}
+	sendAs(t, al, "two", "hello")
+	if got := provider.lastModel(); got != "base-model" {
+		t.Fatalf("other session used %q, want base-model", got)
+	}
+
+	// The choice is saved with the workspace state.
+	restarted := newModelSwitchLoop(t, workspace, provider)
+	sendAs(t, restarted, "one", "hello again")
+	if got := provider.lastModel(); got != "big-model" {
+		t.Fatalf("after restart the session used %q, want big-model", got)
+	}
+}
+
+func TestModelTool_RejectsUnknownModel(t *testing.T) {
+	provider := &modelSwitchProvider{}
+	al := newModelSwitchLoop(t, t.TempDir(), provider)
+
+	resp := sendAs(t, al, "one", "switch to no-such-model")
+	if !strings.Contains(resp, "not offered by the active provider") {
+		t.Fatalf("set result = %q, want a rejection", resp)
+	}
+	sendAs(t, al, "one", "hello")
+	if got := provider.lastModel(); got != "base-model" {
+		t.Fatalf("session used %q after a rejected switch, want base-model", got)
+	}
+}
+
+type thinkingSwitchProvider struct{ modelSwitchProvider }
+
+func (p *thinkingSwitchProvider) SupportsThinking() bool { return true }
+
+func TestSessionProvider_UsesSessionModelForThinkingSupport(t *testing.T) {
+	al := newModelSwitchLoop(t, t.TempDir(), &modelSwitchProvider{})
+	agent := al.GetRegistry().GetDefaultAgent()
+	if providerSupportsThinking(al.sessionProvider(agent, "s1")) {
+		t.Fatal("agent provider without thinking reported as thinking-capable")
+	}
+
+	al.sessionModels.Store("s1", &sessionModel{
+		agentID:  agent.ID,
+		name:     "thinker",
+		provider: &thinkingSwitchProvider{},
+	})
+	if !providerSupportsThinking(al.sessionProvider(agent, "s1")) {
+		t.Fatal("session model's thinking support was ignored")
+	}
+	if providerSupportsThinking(al.sessionProvider(agent, "s2")) {
+		t.Fatal("another session picked up the session model")
+	}
+}
+
+// listingProvider offers the given models and counts how often it is asked.
+type listingProvider struct {
+	modelSwitchProvider
+	offered []string
+	lists   int
+}
+
+func (p *listingProvider) ListModels(context.Context) ([]providers.ModelInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lists++
+	models := make([]providers.ModelInfo, 0, len(p.offered))
+	for _, id := range p.offered {
+		models = append(models, providers.ModelInfo{ID: id})
+	}
+	return models, nil
+}
+
+func TestSessionModelFor_CachesFailureUntilReload(t *testing.T) {
+	old := &listingProvider{offered: []string{"base-model"}}
+	al := newModelSwitchLoop(t, t.TempDir(), &old.modelSwitchProvider)
+	reloaded := *al.GetConfig()
+	if err := al.ReloadProviderAndConfig(context.Background(), old, &reloaded); err != nil {
+		t.Fatalf("ReloadProviderAndConfig() error = %v", err)
+	}
+	if err := al.state.SetSessionModel("s1", "gone-model"); err != nil {
+		t.Fatalf("SetSessionModel() error = %v", err)
+	}
+
+	for range 3 {
+		if sm := al.sessionModelFor(al.GetRegistry().GetDefaultAgent(), "s1"); sm != nil {
+			t.Fatalf("unresolvable saved model resolved to %+v", sm)
+		}
+	}
+	if old.lists != 1 {
+		t.Fatalf("provider listed models %d times, want once", old.lists)
+	}
+
+	next := &listingProvider{offered: []string{"base-model", "gone-model"}}
+	if err := al.ReloadProviderAndConfig(context.Background(), next, &reloaded); err != nil {
+		t.Fatalf("ReloadProviderAndConfig() error = %v", err)
+	}
+	sm := al.sessionModelFor(al.GetRegistry().GetDefaultAgent(), "s1")
+	if sm == nil || sm.provider != providers.LLMProvider(next) {
+		t.Fatalf("after reload the saved model resolved to %+v, want it on the new provider", sm)
+	}
+}
@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// sessionUsage accumulates provider-reported token usage for one session.
+// Totals live in memory only and reset when the gateway restarts, the
+// session history is cleared, or the session is evicted as idle.
+type sessionUsage struct {
+	mu      sync.Mutex
+	total   commands.TokenUsage
+	updated time.Time
+}
+
+func (al *AgentLoop) recordSessionUsage(sessionKey string, usage *providers.UsageInfo) {
+	sessionKey = strings.TrimSpace(sessionKey)
+	if sessionKey == "" || usage == nil {
+		return
+	}
+	total := usage.TotalTokens
+	if total == 0 {
+		total = usage.PromptTokens + usage.CompletionTokens
+	}
+
+	v, _ := al.sessionUsage.LoadOrStore(sessionKey, &sessionUsage{})
+	su := v.(*sessionUsage)
+	su.mu.Lock()
+	su.total.Requests++
+	su.total.PromptTokens += int64(usage.PromptTokens)
+	su.total.CompletionTokens += int64(usage.CompletionTokens)
+	su.total.TotalTokens += int64(total)
+	su.total.CacheReadTokens += int64(usage.CacheReadTokens)
+	su.total.CacheWriteTokens += int64(usage.CacheWriteTokens)
+	su.updated = time.Now()
+	su.mu.Unlock()
+}
+
+// SessionUsage returns the token usage accumulated for a session.
+func (al *AgentLoop) SessionUsage(sessionKey string) commands.TokenUsage {
+	v, ok := al.sessionUsage.Load(strings.TrimSpace(sessionKey))
+	if !ok {
+		return commands.TokenUsage{}
+	}
+	su := v.(*sessionUsage)
+	su.mu.Lock()
+	defer su.mu.Unlock()
+	return su.total
+}
+
+func (al *AgentLoop) resetSessionUsage(sessionKey string) {
+	al.sessionUsage.Delete(strings.TrimSpace(sessionKey))
+}
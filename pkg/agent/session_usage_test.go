@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type usageReportingProvider struct{}
+
+func (p *usageReportingProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	return &providers.LLMResponse{
+		Content: "Mock response",
+		Usage: &providers.UsageInfo{
+			PromptTokens: 30, CompletionTokens: 12, TotalTokens: 42, CacheReadTokens: 20,
+		},
+	}, nil
+}
+
+func (p *usageReportingProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func newUsageTestLoop(t *testing.T) *AgentLoop {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	return NewAgentLoop(cfg, bus.NewMessageBus(), &usageReportingProvider{})
+}
+
+func TestProcessMessage_UsageAndResetCommands(t *testing.T) {
+	al := newUsageTestLoop(t)
+	ctx := context.Background()
+	send := func(content string) string {
+		t.Helper()
+		resp, err := al.processMessage(ctx, bus.InboundMessage{
+			Channel:  "telegram",
+			SenderID: "telegram:123",
+			ChatID:   "chat-1",
+			Content:  content,
+		})
+		if err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+		return resp
+	}
+
+	if resp := send("/usage"); !strings.Contains(resp, "No model calls") {
+		t.Fatalf("/usage before any turn = %q", resp)
+	}
+
+	send("hello")
+	send("again")
+
+	resp := send("/usage")
+	for _, want := range []string{
+		"Model calls: 2", "Prompt tokens: 60", "Completion tokens: 24", "Total tokens: 84",
+		"Cached prompt tokens: 40 read, 0 written",
+	} {
+		if !strings.Contains(resp, want) {
+			t.Fatalf("/usage = %q, want it to contain %q", resp, want)
+		}
+	}
+
+	agent := al.GetRegistry().GetDefaultAgent()
+	keys := agent.Sessions.ListSessions()
+	if len(keys) == 0 {
+		t.Fatal("expected a persisted session before reset")
+	}
+
+	if resp := send("/reset"); resp != "Chat history cleared!" {
+		t.Fatalf("/reset = %q", resp)
+	}
+	if resp := send("/usage"); !strings.Contains(resp, "No model calls") {
+		t.Fatalf("/usage after reset = %q", resp)
+	}
+
+	for _, key := range keys {
+		if history := agent.Sessions.GetHistory(key); len(history) != 0 {
+			t.Fatalf("session %q history after reset = %d messages, want 0", key, len(history))
+		}
+	}
+}
+
+func TestAgentLoop_RegisterCommand(t *testing.T) {
+	al := newUsageTestLoop(t)
+
+	err := al.RegisterCommand(commands.Definition{
+		Name:        "ping",
+		Description: "Reply with pong",
+		Handler: func(_ context.Context, req commands.Request, _ *commands.Runtime) error {
+			return req.Reply("pong")
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterCommand() error = %v", err)
+	}
+	if err := al.RegisterCommand(commands.Definition{Name: "help", Handler: func(
+		context.Context, commands.Request, *commands.Runtime,
+	) error {
+		return nil
+	}}); err == nil {
+		t.Fatal("expected registering over a built-in command to fail")
+	}
+
+	resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:123",
+		ChatID:   "chat-1",
+		Content:  "/ping",
+	})
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if resp != "pong" {
+		t.Fatalf("/ping response = %q, want pong", resp)
+	}
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/mcp"
+)
+
+// ProviderStats reports LLM calls made to one provider since start.
+type ProviderStats struct {
+	Success      uint64 `json:"success"`
+	Failure      uint64 `json:"failure"`
+	AvgLatencyMs int64  `json:"avg_latency_ms"` // mean over successful and failed calls
+}
+
+// usageStats counts inbound messages per channel and LLM calls per provider.
+// The zero value is ready to use.
+type usageStats struct {
+	mu        sync.Mutex
+	received  map[string]uint64
+	providers map[string]*providerCounter
+}
+
+type providerCounter struct {
+	success, failure uint64
+	latency          time.Duration
+}
+
+func (s *usageStats) recordReceived(channel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.received == nil {
+		s.received = make(map[string]uint64)
+	}
+	s.received[channel]++
+}
+
+// recordProviderCall counts one call. Calls cancelled by the caller (user
+// abort, steering, shutdown) are not the provider's fault and are skipped.
+func (s *usageStats) recordProviderCall(provider string, elapsed time.Duration, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if provider == "" {
+		provider = "unknown"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.providers == nil {
+		s.providers = make(map[string]*providerCounter)
+	}
+	c := s.providers[provider]
+	if c == nil {
+		c = &providerCounter{}
+		s.providers[provider] = c
+	}
+	if err != nil {
+		c.failure++
+	} else {
+		c.success++
+	}
+	c.latency += elapsed
+}
+
+// ChannelMessageCounts returns the number of inbound messages processed per
+// channel since start.
+func (al *AgentLoop) ChannelMessageCounts() map[string]uint64 {
+	al.usage.mu.Lock()
+	defer al.usage.mu.Unlock()
+	counts := make(map[string]uint64, len(al.usage.received))
+	for channel, n := range al.usage.received {
+		counts[channel] = n
+	}
+	return counts
+}
+
+// ProviderStats returns LLM call counts and average latency per provider.
+func (al *AgentLoop) ProviderStats() map[string]ProviderStats {
+	al.usage.mu.Lock()
+	defer al.usage.mu.Unlock()
+	stats := make(map[string]ProviderStats, len(al.usage.providers))
+	for name, c := range al.usage.providers {
+		ps := ProviderStats{Success: c.success, Failure: c.failure}
+		if calls := c.success + c.failure; calls > 0 {
+			ps.AvgLatencyMs = (c.latency / time.Duration(calls)).Milliseconds()
+		}
+		stats[name] = ps
+	}
+	return stats
+}
+
+// MCPServerStatus returns the health of each configured MCP server, without
+// stderr output, or nil when MCP is not running.
+func (al *AgentLoop) MCPServerStatus() []mcp.ServerStatus {
+	manager, err := mcpAdmin{al: al}.manager()
+	if err != nil {
+		return nil
+	}
+	statuses := manager.ServerStatus()
+	for i := range statuses {
+		statuses[i].Stderr = ""
+	}
+	return statuses
+}
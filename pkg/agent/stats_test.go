@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestUsageStats_ProviderCounts(t *testing.T) {
+	al := &AgentLoop{}
+	al.usage.recordProviderCall("openai", 100*time.Millisecond, nil)
+	al.usage.recordProviderCall("openai", 300*time.Millisecond, errors.New("502 bad gateway"))
+	al.usage.recordProviderCall("openai", time.Second, fmt.Errorf("aborted: %w", context.Canceled))
+	al.usage.recordProviderCall("", 50*time.Millisecond, nil)
+
+	stats := al.ProviderStats()
+	if got, want := stats["openai"], (ProviderStats{Success: 1, Failure: 1, AvgLatencyMs: 200}); got != want {
+		t.Errorf("openai stats = %+v, want %+v", got, want)
+	}
+	if got := stats["unknown"]; got.Success != 1 {
+		t.Errorf("unnamed provider stats = %+v, want one success under \"unknown\"", got)
+	}
+}
+
+func TestUsageStats_ChannelCounts(t *testing.T) {
+	al := &AgentLoop{}
+	if got := al.ChannelMessageCounts(); len(got) != 0 {
+		t.Fatalf("ChannelMessageCounts() = %v, want empty", got)
+	}
+	al.usage.recordReceived("telegram")
+	al.usage.recordReceived("telegram")
+	al.usage.recordReceived("discord")
+
+	got := al.ChannelMessageCounts()
+	if got["telegram"] != 2 || got["discord"] != 1 {
+		t.Fatalf("ChannelMessageCounts() = %v, want telegram=2 discord=1", got)
+	}
+}
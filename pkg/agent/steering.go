@@ -447,6 +447,40 @@ func (al *AgentLoop) InterruptHard() error {
 	return nil
 }
 
+// CancelChatTurns hard-aborts every active root turn replying to
+// channel/chatID, such as when the user closed the chat and nobody is left to
+// read the answer. Aborting cancels the in-flight provider call and any
+// running tool. It returns how many turns were cancelled.
+func (al *AgentLoop) CancelChatTurns(channel, chatID string) int {
+	cancelled := 0
+	al.activeTurnStates.Range(func(_, value any) bool {
+		ts, ok := value.(*turnState)
+		if !ok || ts.depth != 0 || ts.channel != channel || ts.chatID != chatID {
+			return true
+		}
+		if !ts.requestHardAbort() {
+			return true
+		}
+		cancelled++
+		al.emitEvent(
+			EventKindInterruptReceived,
+			ts.eventMeta("CancelChatTurns", "turn.interrupt.received"),
+			InterruptReceivedPayload{
+				Kind: InterruptKindHard,
+			},
+		)
+		return true
+	})
+	if cancelled > 0 {
+		logger.InfoCF("agent", "Cancelled turns for closed chat", map[string]any{
+			"channel": channel,
+			"chat_id": chatID,
+			"turns":   cancelled,
+		})
+	}
+	return cancelled
+}
+
 // ====================== SubTurn Result Polling ======================
 
 // dequeuePendingSubTurnResults polls the SubTurn result channel for the given
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -1553,3 +1554,78 @@ func init() {
 	// with the proper argument serialization.
 	_ = json.Marshal
 }
+
+type ctxWaitProvider struct {
+	started chan struct{}
+	errs    chan error
+}
+
+func (p *ctxWaitProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	close(p.started)
+	<-ctx.Done()
+	p.errs <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func (p *ctxWaitProvider) GetDefaultModel() string {
+	return "ctx-wait-mock"
+}
+
+func TestAgentLoop_CancelChatTurns_CancelsProviderCall(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	provider := &ctxWaitProvider{started: make(chan struct{}), errs: make(chan error, 1)}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	sessionKey := session.BuildMainSessionKey(routing.DefaultAgentID)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = al.ProcessDirectWithChannel(context.Background(), "write an essay", sessionKey, "pico", "pico:tab")
+	}()
+
+	select {
+	case <-provider.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("provider call did not start")
+	}
+
+	if n := al.CancelChatTurns("pico", "pico:other"); n != 0 {
+		t.Fatalf("CancelChatTurns(other chat) = %d, want 0", n)
+	}
+	start := time.Now()
+	if n := al.CancelChatTurns("pico", "pico:tab"); n != 1 {
+		t.Fatalf("CancelChatTurns() = %d, want 1", n)
+	}
+
+	select {
+	case err := <-provider.errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("provider ctx error = %v, want context.Canceled", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("provider call took %s to see the cancellation", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("provider call was not cancelled")
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("turn did not end after cancellation")
+	}
+}
@@ -1,6 +1,10 @@
 package agent
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
 
 // ThinkingLevel controls how the provider sends thinking parameters.
 //
@@ -37,3 +41,42 @@ func parseThinkingLevel(level string) ThinkingLevel {
 		return ThinkingOff
 	}
 }
+
+// thinkingFor returns the thinking level and budget for a turn of agent in
+// sessionKey. A budget set with /think replaces the agent's settings: a
+// positive budget enables thinking with that budget, and 0 turns it off.
+func (al *AgentLoop) thinkingFor(agent *AgentInstance, sessionKey string) (ThinkingLevel, int) {
+	if sessionKey != "" {
+		if v, ok := al.sessionThinking.Load(sessionKey); ok {
+			if budget := v.(int); budget > 0 {
+				return agent.ThinkingLevel, budget
+			}
+			return ThinkingOff, 0
+		}
+	}
+	return agent.ThinkingLevel, agent.ThinkingBudget
+}
+
+// sessionProvider returns the provider that serves sessionKey's turns: the
+// model chosen for the session, or the agent's own.
+func (al *AgentLoop) sessionProvider(agent *AgentInstance, sessionKey string) providers.LLMProvider {
+	if sm := al.sessionModelFor(agent, sessionKey); sm != nil {
+		return sm.provider
+	}
+	return agent.Provider
+}
+
+func providerSupportsThinking(provider providers.LLMProvider) bool {
+	tc, ok := provider.(providers.ThinkingCapable)
+	return ok && tc.SupportsThinking()
+}
+
+// setSessionThinkingBudget overrides the thinking budget of sessionKey; 0
+// turns thinking off and a negative budget restores the agent's settings.
+func (al *AgentLoop) setSessionThinkingBudget(sessionKey string, budget int) {
+	if budget < 0 {
+		al.sessionThinking.Delete(sessionKey)
+		return
+	}
+	al.sessionThinking.Store(sessionKey, budget)
+}
@@ -33,3 +33,30 @@ func TestParseThinkingLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestThinkingFor_SessionBudgetOverridesAgent(t *testing.T) {
+	al := &AgentLoop{}
+	agent := &AgentInstance{ThinkingLevel: ThinkingMedium, ThinkingBudget: 2048}
+
+	if level, budget := al.thinkingFor(agent, "s1"); level != ThinkingMedium || budget != 2048 {
+		t.Fatalf("default = (%s, %d), want (medium, 2048)", level, budget)
+	}
+
+	al.setSessionThinkingBudget("s1", 9000)
+	if level, budget := al.thinkingFor(agent, "s1"); level != ThinkingMedium || budget != 9000 {
+		t.Fatalf("session budget = (%s, %d), want (medium, 9000)", level, budget)
+	}
+	if _, budget := al.thinkingFor(agent, "s2"); budget != 2048 {
+		t.Fatalf("other session budget = %d, want 2048", budget)
+	}
+
+	al.setSessionThinkingBudget("s1", 0)
+	if level, budget := al.thinkingFor(agent, "s1"); level != ThinkingOff || budget != 0 {
+		t.Fatalf("session off = (%s, %d), want (off, 0)", level, budget)
+	}
+
+	al.setSessionThinkingBudget("s1", -1)
+	if level, budget := al.thinkingFor(agent, "s1"); level != ThinkingMedium || budget != 2048 {
+		t.Fatalf("after reset = (%s, %d), want (medium, 2048)", level, budget)
+	}
+}
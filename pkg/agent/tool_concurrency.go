@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errToolBusy is returned by toolConcurrency.acquire when no slot frees up
+// within the wait.
+var errToolBusy = errors.New("tool busy")
+
+// ToolConcurrencyStats reports the calls of one tool running and waiting for
+// a slot. Limit is the tools.max_concurrent cap, 0 when unlimited.
+type ToolConcurrencyStats struct {
+	InFlight int `json:"in_flight"`
+	Waiting  int `json:"waiting,omitempty"`
+	Limit    int `json:"limit,omitempty"`
+}
+
+// toolConcurrency enforces tools.max_concurrent across every session and
+// counts tool calls in flight. The zero value is ready to use.
+type toolConcurrency struct {
+	mu       sync.Mutex
+	slots    map[string]chan struct{}
+	inFlight map[string]int
+	waiting  map[string]int
+}
+
+// acquire takes a slot for one call to the named tool and returns the
+// function that gives it back. With limit > 0 it waits up to wait for a free
+// slot and returns errToolBusy when none frees up, or the context error when
+// the turn is cancelled first. The slot channel is replaced when the limit
+// changes on reload; calls holding the old one release into it unharmed.
+func (c *toolConcurrency) acquire(
+	ctx context.Context,
+	name string,
+	limit int,
+	wait time.Duration,
+) (func(), error) {
+	c.mu.Lock()
+	if c.inFlight == nil {
+		c.slots = make(map[string]chan struct{})
+		c.inFlight = make(map[string]int)
+		c.waiting = make(map[string]int)
+	}
+	var slots chan struct{}
+	if limit > 0 {
+		slots = c.slots[name]
+		if cap(slots) != limit {
+			slots = make(chan struct{}, limit)
+			c.slots[name] = slots
+		}
+	} else {
+		delete(c.slots, name)
+	}
+	c.mu.Unlock()
+
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+		default:
+			if err := c.waitForSlot(ctx, name, slots, wait); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.inFlight[name]++
+	c.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			c.inFlight[name]--
+			c.mu.Unlock()
+			if slots != nil {
+				<-slots
+			}
+		})
+	}, nil
+}
+
+func (c *toolConcurrency) waitForSlot(
+	ctx context.Context,
+	name string,
+	slots chan struct{},
+	wait time.Duration,
+) error {
+	c.mu.Lock()
+	c.waiting[name]++
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.waiting[name]--
+		c.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return errToolBusy
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ToolConcurrency returns the calls running and waiting per tool name, for
+// every tool called since start, along with its configured limit.
+func (al *AgentLoop) ToolConcurrency() map[string]ToolConcurrencyStats {
+	cfg := al.GetConfig()
+	al.toolSlots.mu.Lock()
+	defer al.toolSlots.mu.Unlock()
+	stats := make(map[string]ToolConcurrencyStats, len(al.toolSlots.inFlight))
+	for name, n := range al.toolSlots.inFlight {
+		stats[name] = ToolConcurrencyStats{
+			InFlight: n,
+			Waiting:  al.toolSlots.waiting[name],
+			Limit:    cfg.Tools.ConcurrencyLimit(name),
+		}
+	}
+	for name, n := range al.toolSlots.waiting {
+		if _, ok := stats[name]; !ok && n > 0 {
+			stats[name] = ToolConcurrencyStats{Waiting: n, Limit: cfg.Tools.ConcurrencyLimit(name)}
+		}
+	}
+	return stats
+}
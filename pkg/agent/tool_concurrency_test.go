@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestToolConcurrency_WaitsThenReportsBusy(t *testing.T) {
+	var c toolConcurrency
+	ctx := context.Background()
+
+	release, err := c.acquire(ctx, "build", 1, time.Second)
+	if err != nil {
+		t.Fatalf("first acquire error = %v", err)
+	}
+	if _, err := c.acquire(ctx, "build", 1, 20*time.Millisecond); !errors.Is(err, errToolBusy) {
+		t.Fatalf("acquire over the cap error = %v, want errToolBusy", err)
+	}
+
+	// A waiting call gets the slot once the running one releases it.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		release()
+	}()
+	second, err := c.acquire(ctx, "build", 1, time.Second)
+	if err != nil {
+		t.Fatalf("acquire after release error = %v", err)
+	}
+	second()
+	second() // releasing twice must not free a second slot
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	hold, _ := c.acquire(ctx, "build", 1, time.Second)
+	if _, err := c.acquire(cancelled, "build", 1, time.Second); !errors.Is(err, context.Canceled) {
+		t.Fatalf("acquire on cancelled turn error = %v, want context.Canceled", err)
+	}
+	hold()
+
+	// Tools without a cap are only counted.
+	releases := make([]func(), 3)
+	for i := range releases {
+		if releases[i], err = c.acquire(ctx, "read_file", 0, 0); err != nil {
+			t.Fatalf("unlimited acquire error = %v", err)
+		}
+	}
+	if n := c.inFlight["read_file"]; n != 3 {
+		t.Fatalf("read_file in flight = %d, want 3", n)
+	}
+	for _, r := range releases {
+		r()
+	}
+}
+
+func TestExecuteToolCall_BusyWhenAtConcurrencyLimit(t *testing.T) {
+	tool := &slowTool{name: "build", duration: 10 * time.Millisecond}
+	al := newToolTimeoutTestLoop(t, config.ToolsConfig{
+		MaxConcurrent:          map[string]int{"build": 1},
+		ConcurrencyWaitSeconds: 1,
+	}, tool)
+
+	// Another session's call holds the only slot.
+	release, err := al.toolSlots.acquire(context.Background(), "build", 1, time.Second)
+	if err != nil {
+		t.Fatalf("acquire error = %v", err)
+	}
+	resp, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "cli",
+		SenderID: "user",
+		ChatID:   "direct",
+		Content:  "run the build",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if !strings.Contains(resp, `Tool "build" is busy`) {
+		t.Fatalf("model saw %q, want a busy result", resp)
+	}
+	stats := al.ToolConcurrency()["build"]
+	if stats.InFlight != 1 || stats.Limit != 1 {
+		t.Fatalf("ToolConcurrency()[build] = %+v, want 1 in flight with limit 1", stats)
+	}
+	release()
+}
@@ -0,0 +1,226 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// toolPrefetch carries the pre-execution state of a tool call that was
+// dispatched ahead of the sequential tool loop. The loop reuses the cached
+// hook decisions instead of asking the hooks again, and waits on done instead
+// of executing the tool itself.
+type toolPrefetch struct {
+	hookReq  *ToolCallHookRequest
+	decision HookDecision
+	hooked   bool
+	approval *ApprovalDecision
+
+	started  bool
+	done     chan struct{}
+	result   *tools.ToolResult
+	duration time.Duration
+}
+
+func (p *toolPrefetch) wait() (*tools.ToolResult, time.Duration) {
+	<-p.done
+	return p.result, p.duration
+}
+
+// prefetchAt returns the prefetch entry for call i, or nil.
+func prefetchAt(prefetched []*toolPrefetch, i int) *toolPrefetch {
+	if i < len(prefetched) {
+		return prefetched[i]
+	}
+	return nil
+}
+
+// prefetchToolCalls starts the leading run of tool calls concurrently when
+// agents.defaults.max_parallel_tool_calls allows it. Calls are walked in
+// order: before_tool and approval hooks run exactly as the sequential loop
+// would run them, and prefetching stops at the first call that a hook does
+// not simply continue, that is denied, or whose tool is a SequentialTool.
+// Everything from that call on is left to the sequential loop.
+func (al *AgentLoop) prefetchToolCalls(
+	turnCtx context.Context,
+	ts *turnState,
+	calls []providers.ToolCall,
+	iteration int,
+) []*toolPrefetch {
+	limit := al.cfg.Agents.Defaults.MaxParallelToolCalls
+	if limit <= 1 || len(calls) < 2 {
+		return nil
+	}
+
+	prefetched := make([]*toolPrefetch, len(calls))
+	sem := make(chan struct{}, limit)
+	started := 0
+
+	for i, tc := range calls {
+		if ts.hardAbortRequested() || ts.agent.Tools.IsSequential(tc.Name) {
+			break
+		}
+
+		pf := &toolPrefetch{}
+		prefetched[i] = pf
+		toolName := tc.Name
+		toolArgs := cloneStringAnyMap(tc.Arguments)
+
+		if al.hooks != nil {
+			pf.hookReq, pf.decision = al.hooks.BeforeTool(turnCtx, &ToolCallHookRequest{
+				Meta:      ts.eventMeta("runTurn", "turn.tool.before"),
+				Context:   cloneTurnContext(ts.turnCtx),
+				Tool:      toolName,
+				Arguments: toolArgs,
+			})
+			pf.hooked = true
+			switch pf.decision.normalizedAction() {
+			case HookActionContinue, HookActionModify:
+				if pf.hookReq != nil {
+					toolName = pf.hookReq.Tool
+					toolArgs = pf.hookReq.Arguments
+				}
+			default:
+				return prefetched
+			}
+			if ts.agent.Tools.IsSequential(toolName) {
+				return prefetched
+			}
+
+			approval := al.hooks.ApproveTool(turnCtx, &ToolApprovalRequest{
+				Meta:      ts.eventMeta("runTurn", "turn.tool.approve"),
+				Context:   cloneTurnContext(ts.turnCtx),
+				Tool:      toolName,
+				Arguments: toolArgs,
+			})
+			pf.approval = &approval
+			if !approval.Approved {
+				return prefetched
+			}
+		}
+
+		pf.started = true
+		pf.done = make(chan struct{})
+		started++
+		asyncCallback := al.asyncToolCallback(ts, toolName, iteration)
+		go func() {
+			defer close(pf.done)
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			toolStart := time.Now()
+			pf.result = al.executeToolCall(turnCtx, ts, toolName, toolArgs, asyncCallback)
+			pf.duration = time.Since(toolStart)
+		}()
+	}
+
+	if started > 1 {
+		logger.DebugCF("agent", "Dispatched tool calls concurrently",
+			map[string]any{
+				"agent_id":  ts.agent.ID,
+				"started":   started,
+				"limit":     limit,
+				"iteration": iteration,
+			})
+	}
+	return prefetched
+}
+
+// toolExecContext attaches the inbound and session context tools expect.
+func toolExecContext(turnCtx context.Context, ts *turnState) context.Context {
+	execCtx := tools.WithToolInboundContext(
+		turnCtx,
+		ts.channel,
+		ts.chatID,
+		ts.opts.Dispatch.MessageID(),
+		ts.opts.Dispatch.ReplyToMessageID(),
+	)
+	execCtx = tools.WithToolSenderContext(execCtx, ts.opts.Dispatch.SenderID())
+	return tools.WithToolSessionContext(
+		execCtx,
+		ts.agent.ID,
+		ts.sessionKey,
+		ts.opts.Dispatch.SessionScope,
+	)
+}
+
+// asyncToolCallback builds the callback async tools use to report their
+// result back into the agent loop as a system inbound message.
+func (al *AgentLoop) asyncToolCallback(ts *turnState, toolName string, iteration int) tools.AsyncCallback {
+	return func(_ context.Context, result *tools.ToolResult) {
+		// Send ForUser content directly to the user (immediate feedback),
+		// mirroring the synchronous tool execution path.
+		if !result.Silent && result.ForUser != "" {
+			outCtx, outCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer outCancel()
+			_ = al.bus.PublishOutbound(outCtx, outboundMessageForTurn(ts, result.ForUser))
+		}
+
+		// Determine content for the agent loop (ForLLM or error).
+		content := result.ContentForLLM()
+		if content == "" {
+			return
+		}
+
+		// Filter sensitive data before publishing
+		content = al.cfg.FilterSensitiveData(content)
+		content = al.capToolResult(context.Background(), ts, toolName, "", nil, content)
+
+		logger.InfoCF("agent", "Async tool completed, publishing result",
+			map[string]any{
+				"tool":        toolName,
+				"content_len": len(content),
+				"channel":     ts.channel,
+			})
+		al.emitEvent(
+			EventKindFollowUpQueued,
+			ts.scope.meta(iteration, "runTurn", "turn.follow_up.queued"),
+			FollowUpQueuedPayload{
+				SourceTool: toolName,
+				ContentLen: len(content),
+			},
+		)
+
+		pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer pubCancel()
+		_ = al.bus.PublishInbound(pubCtx, bus.InboundMessage{
+			Context: bus.InboundContext{
+				Channel:  "system",
+				ChatID:   fmt.Sprintf("%s:%s", ts.channel, ts.chatID),
+				ChatType: "direct",
+				SenderID: fmt.Sprintf("async:%s", toolName),
+			},
+			Content: content,
+		})
+	}
+}
+
+// skippedToolContent returns the tool message content for a call that the
+// loop skips after a steering message or graceful interrupt. A call that
+// already ran concurrently reports its real result, capped like any other,
+// instead of pretending it was skipped.
+func (al *AgentLoop) skippedToolContent(
+	ctx context.Context,
+	ts *turnState,
+	tc providers.ToolCall,
+	pf *toolPrefetch,
+	skipMessage string,
+) (string, bool) {
+	if pf == nil || !pf.started {
+		return skipMessage, false
+	}
+	result, _ := pf.wait()
+	if result == nil {
+		return skipMessage, false
+	}
+	content := result.ContentForLLM()
+	if al.cfg.Tools.IsFilterSensitiveDataEnabled() {
+		content = al.cfg.FilterSensitiveData(content)
+	}
+	return al.capToolResult(ctx, ts, tc.Name, tc.ID, tc.Arguments, content), true
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// rendezvousTool blocks until `want` calls are in flight at once, so it only
+// succeeds quickly when the loop runs calls concurrently.
+type rendezvousTool struct {
+	want     int32
+	inFlight atomic.Int32
+	arrived  chan struct{}
+	once     sync.Once
+}
+
+func newRendezvousTool(want int32) *rendezvousTool {
+	return &rendezvousTool{want: want, arrived: make(chan struct{})}
+}
+
+func (t *rendezvousTool) Name() string        { return "rendezvous" }
+func (t *rendezvousTool) Description() string { return "waits for sibling calls" }
+func (t *rendezvousTool) Parameters() map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"id": map[string]any{"type": "string"}},
+	}
+}
+
+func (t *rendezvousTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	if t.inFlight.Add(1) >= t.want {
+		t.once.Do(func() { close(t.arrived) })
+	}
+	defer t.inFlight.Add(-1)
+
+	id, _ := args["id"].(string)
+	select {
+	case <-t.arrived:
+		return tools.SilentResult("met:" + id)
+	case <-time.After(time.Second):
+		return tools.SilentResult("alone:" + id)
+	}
+}
+
+// sequentialRecorder records how many rendezvous calls were still running
+// when it executed.
+type sequentialRecorder struct {
+	peer     *rendezvousTool
+	observed atomic.Int32
+}
+
+func (t *sequentialRecorder) Name() string        { return "seq_probe" }
+func (t *sequentialRecorder) Description() string { return "sequential probe" }
+func (t *sequentialRecorder) Parameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{}}
+}
+func (t *sequentialRecorder) Sequential() bool { return true }
+
+func (t *sequentialRecorder) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	t.observed.Store(t.peer.inFlight.Load())
+	return tools.SilentResult("seq")
+}
+
+type parallelToolProvider struct {
+	mu       sync.Mutex
+	calls    int
+	toolMsgs []providers.Message
+}
+
+func (p *parallelToolProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	defs []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.calls == 1 {
+		return &providers.LLMResponse{
+			ToolCalls: []providers.ToolCall{
+				{ID: "call-a", Name: "rendezvous", Arguments: map[string]any{"id": "a"}},
+				{ID: "call-b", Name: "rendezvous", Arguments: map[string]any{"id": "b"}},
+				{ID: "call-c", Name: "seq_probe", Arguments: map[string]any{}},
+			},
+		}, nil
+	}
+	for _, m := range messages {
+		if m.Role == "tool" {
+			p.toolMsgs = append(p.toolMsgs, m)
+		}
+	}
+	return &providers.LLMResponse{Content: "done"}, nil
+}
+
+func (p *parallelToolProvider) GetDefaultModel() string {
+	return "parallel-tool-provider"
+}
+
+func runMultiToolTurn(t *testing.T, maxParallel int) (*parallelToolProvider, *sequentialRecorder) {
+	t.Helper()
+	provider := &parallelToolProvider{}
+	al, _, cleanup := newHookTestLoop(t, provider)
+	t.Cleanup(cleanup)
+
+	al.cfg.Agents.Defaults.MaxParallelToolCalls = maxParallel
+	rendezvous := newRendezvousTool(2)
+	probe := &sequentialRecorder{peer: rendezvous}
+	al.RegisterTool(rendezvous)
+	al.RegisterTool(probe)
+
+	agent := al.registry.GetDefaultAgent()
+	if _, err := al.runAgentLoop(context.Background(), agent, processOptions{
+		SessionKey:      "parallel-tools",
+		Channel:         "cli",
+		ChatID:          "direct",
+		UserMessage:     "run tools",
+		DefaultResponse: defaultResponse,
+	}); err != nil {
+		t.Fatalf("runAgentLoop failed: %v", err)
+	}
+	return provider, probe
+}
+
+func TestToolCalls_RunConcurrentlyWhenEnabled(t *testing.T) {
+	provider, probe := runMultiToolTurn(t, 4)
+
+	want := []struct{ id, content string }{
+		{"call-a", "met:a"},
+		{"call-b", "met:b"},
+		{"call-c", "seq"},
+	}
+	if len(provider.toolMsgs) != len(want) {
+		t.Fatalf("tool messages = %+v, want %d", provider.toolMsgs, len(want))
+	}
+	for i, w := range want {
+		got := provider.toolMsgs[i]
+		if got.ToolCallID != w.id || got.Content != w.content {
+			t.Fatalf("tool message %d = (%s, %q), want (%s, %q)", i, got.ToolCallID, got.Content, w.id, w.content)
+		}
+	}
+	if n := probe.observed.Load(); n != 0 {
+		t.Fatalf("sequential tool ran while %d concurrent calls were in flight", n)
+	}
+}
+
+func TestToolCalls_SequentialByDefault(t *testing.T) {
+	provider, _ := runMultiToolTurn(t, 0)
+
+	if len(provider.toolMsgs) < 2 {
+		t.Fatalf("tool messages = %+v", provider.toolMsgs)
+	}
+	if provider.toolMsgs[0].Content != "alone:a" || provider.toolMsgs[1].Content != "alone:b" {
+		t.Fatalf("expected sequential execution, got %q and %q",
+			provider.toolMsgs[0].Content, provider.toolMsgs[1].Content)
+	}
+}
@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// toolResultsDir is where oversized tool results are saved, relative to the
+// agent's workspace.
+const toolResultsDir = "tool_results"
+
+// Saved tool results are pruned when a new one is written: files older than
+// toolResultsMaxAge go first, then the oldest beyond toolResultsMaxFiles.
+const (
+	toolResultsMaxAge   = 24 * time.Hour
+	toolResultsMaxFiles = 50
+)
+
+// capToolResult keeps a tool result within tools.max_result_chars. A longer
+// result is saved to the workspace and replaced by its head and tail plus
+// the saved path, so the model can read the rest with read_file instead of
+// overflowing its context. Reads of a saved result are passed through as
+// is, since read_file already pages them.
+func (al *AgentLoop) capToolResult(
+	ctx context.Context,
+	ts *turnState,
+	toolName, toolCallID string,
+	args map[string]any,
+	content string,
+) string {
+	cfg := al.GetConfig()
+	limit := cfg.Tools.MaxResultChars
+	if limit <= 0 || len(content) <= limit {
+		return content
+	}
+	if readsSavedToolResult(ts.agent.Workspace, toolName, args) {
+		return content
+	}
+	runes := []rune(content)
+	if len(runes) <= limit {
+		return content
+	}
+
+	saved := ""
+	if ts.agent.Workspace != "" {
+		rel, err := saveToolResult(ts.agent.Workspace, toolName, toolCallID, content)
+		if err != nil {
+			logger.WarnCtx(ctx, "agent", "Failed to save oversized tool result", map[string]any{
+				"tool":  toolName,
+				"error": err.Error(),
+			})
+		} else {
+			saved = rel
+		}
+	}
+
+	// The note's length depends on where the head ends, so size the excerpt
+	// with a worst-case note first.
+	note := spillNote(cfg, saved, len(runes), len(content), strings.Count(content, "\n"))
+	marker := fmt.Sprintf("\n\n[... %d characters omitted ...]\n\n", len(runes))
+
+	// Most of what is left goes to the head, where commands and documents
+	// usually put what matters; the tail keeps final errors and summaries.
+	budget := max(limit-utf8.RuneCountInString(note)-utf8.RuneCountInString(marker), 0)
+	headLen := budget * 3 / 4
+	tailLen := budget - headLen
+	head := string(runes[:headLen])
+	note = spillNote(cfg, saved, len(runes), len(head), strings.Count(head, "\n"))
+	marker = fmt.Sprintf("\n\n[... %d characters omitted ...]\n\n", len(runes)-headLen-tailLen)
+
+	var b strings.Builder
+	b.WriteString(note)
+	b.WriteString(head)
+	b.WriteString(marker)
+	b.WriteString(string(runes[len(runes)-tailLen:]))
+
+	logger.InfoCF("agent", "Truncated oversized tool result", map[string]any{
+		"agent_id": ts.agent.ID,
+		"tool":     toolName,
+		"chars":    len(runes),
+		"saved_to": saved,
+	})
+	return b.String()
+}
+
+// spillNote tells the model how much output was cut and, when it was saved,
+// the read_file arguments that continue right after the head excerpt.
+// headBytes and headLines give the excerpt's size in bytes and newlines.
+func spillNote(cfg *config.Config, saved string, chars, headBytes, headLines int) string {
+	if saved == "" {
+		return fmt.Sprintf("[Output truncated: %d characters. The full output could not be saved.]\n\n", chars)
+	}
+	if cfg.Tools.ReadFile.EffectiveMode() == config.ReadFileModeLines {
+		return fmt.Sprintf(
+			"[Output truncated: %d characters. The full output is saved at %s; "+
+				"call read_file with path=%q and start_line=%d to see the omitted part.]\n\n",
+			chars, saved, saved, headLines+1)
+	}
+	length := cfg.Tools.ReadFile.MaxReadFileSize
+	if length <= 0 {
+		length = 64 * 1024 // read_file's own default
+	}
+	return fmt.Sprintf(
+		"[Output truncated: %d characters. The full output is saved at %s; "+
+			"call read_file with path=%q, offset=%d and length=%d to see the omitted part.]\n\n",
+		chars, saved, saved, headBytes, length)
+}
+
+// readsSavedToolResult reports whether a read_file call targets a file under
+// the workspace's tool_results directory.
+func readsSavedToolResult(workspace, toolName string, args map[string]any) bool {
+	if toolName != "read_file" || workspace == "" {
+		return false
+	}
+	path, _ := args["path"].(string)
+	if path == "" {
+		return false
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workspace, path)
+	}
+	rel, err := filepath.Rel(filepath.Join(workspace, toolResultsDir), filepath.Clean(path))
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// saveToolResult writes content under the workspace's tool_results directory
+// and returns its workspace-relative path. Old saved results are pruned.
+func saveToolResult(workspace, toolName, toolCallID, content string) (string, error) {
+	dir := filepath.Join(workspace, toolResultsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	name := time.Now().Format("20060102-150405") + "-" + toolResultFileSlug(toolName)
+	if id := toolResultFileSlug(toolCallID); id != "" {
+		name += "-" + id
+	}
+	name += ".txt"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		return "", err
+	}
+	pruneToolResults(dir, name, time.Now())
+	return filepath.ToSlash(filepath.Join(toolResultsDir, name)), nil
+}
+
+// pruneToolResults removes saved results older than toolResultsMaxAge and
+// then the oldest ones beyond toolResultsMaxFiles, never touching keep.
+// Errors are ignored; a leftover file only costs disk space.
+func pruneToolResults(dir, keep string, now time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type savedFile struct {
+		name    string
+		modTime time.Time
+	}
+	var files []savedFile
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == keep {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if now.Sub(info.ModTime()) > toolResultsMaxAge {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+			continue
+		}
+		files = append(files, savedFile{name: e.Name(), modTime: info.ModTime()})
+	}
+	// keep itself takes one of the slots.
+	if extra := len(files) - (toolResultsMaxFiles - 1); extra > 0 {
+		sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+		for _, f := range files[:extra] {
+			_ = os.Remove(filepath.Join(dir, f.name))
+		}
+	}
+}
+
+// toolResultFileSlug keeps the characters of s that are safe in a file name.
+func toolResultFileSlug(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return -1
+	}, s)
+	if len(s) > 40 {
+		s = s[:40]
+	}
+	return s
+}
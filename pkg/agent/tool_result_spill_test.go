@@ -0,0 +1,213 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// verboseTool returns a fixed, usually large, output.
+type verboseTool struct {
+	output string
+}
+
+func (t *verboseTool) Name() string        { return "verbose" }
+func (t *verboseTool) Description() string { return "returns a lot of output" }
+func (t *verboseTool) Parameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{}}
+}
+
+func (t *verboseTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	return tools.SilentResult(t.output)
+}
+
+func runVerboseTool(t *testing.T, maxResultChars int, output string) (string, string) {
+	t.Helper()
+	workspace := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Tools: config.ToolsConfig{MaxResultChars: maxResultChars},
+	}
+	provider := &toolResultCapturingProvider{tool: "verbose"}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	al.RegisterTool(&verboseTool{output: output})
+
+	resp, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "cli",
+		SenderID: "user",
+		ChatID:   "direct",
+		Content:  "run the verbose tool",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	return resp, workspace
+}
+
+func TestCapToolResult_SavesOversizedResult(t *testing.T) {
+	output := "HEAD" + strings.Repeat("log line\n", 500) + "TAIL"
+	resp, workspace := runVerboseTool(t, 1000, output)
+
+	if len([]rune(resp)) > 1000 {
+		t.Fatalf("model saw %d characters, want at most 1000", len([]rune(resp)))
+	}
+	if !strings.HasPrefix(resp, "[Output truncated: 4508 characters.") ||
+		!strings.Contains(resp, "\nHEAD") || !strings.HasSuffix(resp, "TAIL") {
+		t.Fatalf("model saw %q, want a note plus head and tail", resp)
+	}
+
+	path := regexp.MustCompile(`tool_results/\S+\.txt`).FindString(resp)
+	if path == "" || !strings.Contains(path, "-verbose-call-1.txt") {
+		t.Fatalf("model saw no saved path: %q", resp)
+	}
+	saved, err := os.ReadFile(filepath.Join(workspace, filepath.FromSlash(path)))
+	if err != nil {
+		t.Fatalf("saved result: %v", err)
+	}
+	if string(saved) != output {
+		t.Fatal("saved result does not match the full tool output")
+	}
+}
+
+func TestCapToolResult_LeavesSmallResultsAlone(t *testing.T) {
+	resp, workspace := runVerboseTool(t, 1000, "short output")
+	if resp != "short output" {
+		t.Fatalf("model saw %q, want the result unchanged", resp)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, toolResultsDir)); !os.IsNotExist(err) {
+		t.Fatalf("tool_results created for a small result: %v", err)
+	}
+
+	// 0 disables the limit.
+	big := strings.Repeat("log line\n", 500)
+	if resp, _ := runVerboseTool(t, 0, big); resp != big {
+		t.Fatalf("model saw %d characters, want the full result", len(resp))
+	}
+}
+
+func TestCapToolResult_AppliesToSkippedAndAsyncResults(t *testing.T) {
+	al, cfg, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Tools.MaxResultChars = 1000
+	ts := &turnState{agent: al.GetRegistry().GetDefaultAgent(), channel: "cli", chatID: "direct"}
+	output := strings.Repeat("log line\n", 500)
+
+	pf := &toolPrefetch{started: true, done: make(chan struct{}), result: tools.SilentResult(output)}
+	close(pf.done)
+	tc := providers.ToolCall{ID: "call-1", Name: "verbose"}
+	content, ran := al.skippedToolContent(context.Background(), ts, tc, pf, "skipped")
+	if !ran || len([]rune(content)) > 1000 || !strings.HasPrefix(content, "[Output truncated:") {
+		t.Fatalf("skipped call result = %d characters (ran %v), want it capped", len([]rune(content)), ran)
+	}
+
+	al.asyncToolCallback(ts, "verbose", 1)(context.Background(), tools.SilentResult(output))
+	msg := <-msgBus.InboundChan()
+	if len([]rune(msg.Content)) > 1000 || !strings.HasPrefix(msg.Content, "[Output truncated:") {
+		t.Fatalf("async result = %d characters, want it capped", len([]rune(msg.Content)))
+	}
+}
+
+func TestCapToolResult_HintContinuesAfterHead(t *testing.T) {
+	output := strings.Repeat("log line\n", 500)
+	resp, _ := runVerboseTool(t, 1000, output)
+
+	m := regexp.MustCompile(`path="(tool_results/\S+\.txt)", offset=(\d+) and length=(\d+)`).FindStringSubmatch(resp)
+	if m == nil {
+		t.Fatalf("model saw no read_file arguments: %q", resp)
+	}
+	head := resp[strings.Index(resp, "]\n\n")+3 : strings.Index(resp, "\n\n[... ")]
+	if want := strconv.Itoa(len(head)); m[2] != want {
+		t.Fatalf("hint offset = %s, want %s (end of the head excerpt)", m[2], want)
+	}
+	if m[3] != "65536" {
+		t.Fatalf("hint length = %s, want read_file's default chunk", m[3])
+	}
+}
+
+func TestCapToolResult_DoesNotRespillSavedResults(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Tools.MaxResultChars = 1000
+	ts := &turnState{agent: al.GetRegistry().GetDefaultAgent(), channel: "cli", chatID: "direct"}
+	workspace := ts.agent.Workspace
+	output := strings.Repeat("log line\n", 500)
+
+	for _, path := range []string{
+		"tool_results/20260101-000000-exec.txt",
+		filepath.Join(workspace, toolResultsDir, "20260101-000000-exec.txt"),
+	} {
+		args := map[string]any{"path": path, "offset": 0}
+		if got := al.capToolResult(context.Background(), ts, "read_file", "call-1", args, output); got != output {
+			t.Fatalf("read of %s was capped to %d characters, want it passed through", path, len(got))
+		}
+	}
+
+	args := map[string]any{"path": "notes.txt"}
+	if got := al.capToolResult(context.Background(), ts, "read_file", "call-2", args, output); got == output {
+		t.Fatal("read of an ordinary file was not capped")
+	}
+}
+
+func TestSaveToolResult_PrunesOldFiles(t *testing.T) {
+	workspace := t.TempDir()
+	dir := filepath.Join(workspace, toolResultsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	stale := filepath.Join(dir, "stale.txt")
+	if err := os.WriteFile(stale, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(stale, now.Add(-48*time.Hour), now.Add(-48*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	for i := range toolResultsMaxFiles + 5 {
+		p := filepath.Join(dir, fmt.Sprintf("recent-%02d.txt", i))
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mt := now.Add(time.Duration(i-100) * time.Minute)
+		if err := os.Chtimes(p, mt, mt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rel, err := saveToolResult(workspace, "exec", "call-1", "output")
+	if err != nil {
+		t.Fatalf("saveToolResult() error = %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != toolResultsMaxFiles {
+		t.Fatalf("tool_results has %d files, want %d", len(entries), toolResultsMaxFiles)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("stale file was kept: %v", err)
+	}
+	for i := range 6 {
+		if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("recent-%02d.txt", i))); !os.IsNotExist(err) {
+			t.Fatalf("recent-%02d.txt was kept, want the oldest files pruned", i)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(workspace, filepath.FromSlash(rel))); err != nil {
+		t.Fatalf("new result was pruned: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// abandonedToolGrace is how long an abandoned tool call may keep running
+// after its timeout before it is reported as ignoring cancellation.
+var abandonedToolGrace = 30 * time.Second
+
+// Lifecycle of a tool call run under a timeout.
+const (
+	toolCallRunning int32 = iota
+	toolCallFinished
+	toolCallAbandoned
+	toolCallFinishedLate
+)
+
+// executeToolCall runs one tool call through the agent's registry, bounded by
+// tools.max_concurrent and tools.call_timeout_seconds (or the tool's
+// call_timeouts override). A call over its tool's concurrency cap waits for a
+// slot and gives the model a busy result if none frees up. When the time
+// limit passes, the call's context is cancelled and the loop stops waiting:
+// the model gets a timeout result and the turn moves on. A tool that ignores
+// cancellation keeps its goroutine until it returns; such calls are counted
+// and logged so they do not go unnoticed.
+func (al *AgentLoop) executeToolCall(
+	turnCtx context.Context,
+	ts *turnState,
+	toolName string,
+	toolArgs map[string]any,
+	asyncCallback tools.AsyncCallback,
+) *tools.ToolResult {
+	cfg := al.GetConfig()
+	limit := cfg.Tools.ConcurrencyLimit(toolName)
+	release, err := al.toolSlots.acquire(turnCtx, toolName, limit, cfg.Tools.ConcurrencyWait())
+	if errors.Is(err, errToolBusy) {
+		logger.WarnCF("agent", "Tool is at its concurrency limit; rejecting call",
+			map[string]any{
+				"agent_id": ts.agent.ID,
+				"tool":     toolName,
+				"limit":    limit,
+			})
+		return tools.ErrorResult(fmt.Sprintf(
+			"Tool %q is busy: %d calls are already running. Try again later, or continue without it.",
+			toolName, limit,
+		)).WithError(err)
+	}
+	if err != nil {
+		return tools.ErrorResult(fmt.Sprintf("tool %q was cancelled", toolName)).WithError(err)
+	}
+
+	execCtx := toolExecContext(turnCtx, ts)
+	timeout := cfg.Tools.CallTimeout(toolName)
+	if timeout <= 0 {
+		defer release()
+		return ts.agent.Tools.ExecuteWithContext(execCtx, toolName, toolArgs, ts.channel, ts.chatID, asyncCallback)
+	}
+
+	callCtx, cancel := context.WithTimeout(execCtx, timeout)
+	defer cancel()
+
+	var state atomic.Int32
+	done := make(chan *tools.ToolResult, 1)
+	start := time.Now()
+	go func() {
+		done <- ts.agent.Tools.ExecuteWithContext(callCtx, toolName, toolArgs, ts.channel, ts.chatID, asyncCallback)
+		// An abandoned call keeps its slot until it actually returns.
+		release()
+		if !state.CompareAndSwap(toolCallRunning, toolCallFinished) {
+			state.Store(toolCallFinishedLate)
+			al.abandonedToolCalls.Add(-1)
+			logger.InfoCF("agent", "Abandoned tool call finished",
+				map[string]any{
+					"agent_id":    ts.agent.ID,
+					"tool":        toolName,
+					"duration_ms": time.Since(start).Milliseconds(),
+				})
+		}
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-callCtx.Done():
+	}
+	if !state.CompareAndSwap(toolCallRunning, toolCallAbandoned) {
+		// Finished right as the deadline passed; keep its result.
+		return <-done
+	}
+
+	abandoned := al.abandonedToolCalls.Add(1)
+	if !errors.Is(callCtx.Err(), context.DeadlineExceeded) || turnCtx.Err() != nil {
+		return tools.ErrorResult(fmt.Sprintf("tool %q was cancelled", toolName)).WithError(callCtx.Err())
+	}
+
+	logger.WarnCF("agent", "Tool call timed out; abandoning it",
+		map[string]any{
+			"agent_id":  ts.agent.ID,
+			"tool":      toolName,
+			"timeout":   timeout.String(),
+			"abandoned": abandoned,
+		})
+	time.AfterFunc(abandonedToolGrace, func() {
+		if state.Load() == toolCallAbandoned {
+			logger.ErrorCF("agent", "Abandoned tool call is ignoring cancellation; its goroutine is still running",
+				map[string]any{
+					"agent_id":  ts.agent.ID,
+					"tool":      toolName,
+					"running":   time.Since(start).Round(time.Second).String(),
+					"abandoned": al.abandonedToolCalls.Load(),
+				})
+		}
+	})
+	return tools.ErrorResult(fmt.Sprintf(
+		"Tool %q did not finish within %s and was stopped. Try a smaller or different request, or continue without it.",
+		toolName, timeout,
+	)).WithError(callCtx.Err())
+}
@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// toolResultCapturingProvider calls one tool, then answers with the content
+// of the tool result it was given.
+type toolResultCapturingProvider struct {
+	mu    sync.Mutex
+	calls int
+	tool  string
+}
+
+func (p *toolResultCapturingProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	toolDefs []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.calls == 1 {
+		return &providers.LLMResponse{
+			ToolCalls: []providers.ToolCall{{ID: "call-1", Name: p.tool, Arguments: map[string]any{}}},
+		}, nil
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "tool" {
+			return &providers.LLMResponse{Content: messages[i].Content}, nil
+		}
+	}
+	return &providers.LLMResponse{Content: "no tool result"}, nil
+}
+
+func (p *toolResultCapturingProvider) GetDefaultModel() string {
+	return "tool-result-model"
+}
+
+func newToolTimeoutTestLoop(t *testing.T, tools config.ToolsConfig, tool *slowTool) *AgentLoop {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Tools: tools,
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &toolResultCapturingProvider{tool: tool.name})
+	al.RegisterTool(tool)
+	return al
+}
+
+func TestExecuteToolCall_AbandonsToolThatIgnoresTimeout(t *testing.T) {
+	oldGrace := abandonedToolGrace
+	abandonedToolGrace = 10 * time.Millisecond
+	t.Cleanup(func() { abandonedToolGrace = oldGrace })
+
+	tool := &slowTool{name: "hang", duration: 1500 * time.Millisecond}
+	al := newToolTimeoutTestLoop(t, config.ToolsConfig{
+		CallTimeoutSeconds: 60,
+		CallTimeouts:       map[string]int{"hang": 1},
+	}, tool)
+
+	start := time.Now()
+	resp, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "cli",
+		SenderID: "user",
+		ChatID:   "direct",
+		Content:  "run the slow tool",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 1400*time.Millisecond {
+		t.Fatalf("turn took %s; the loop should not wait for the abandoned tool", elapsed)
+	}
+	if !strings.Contains(resp, `Tool "hang" did not finish within 1s`) {
+		t.Fatalf("model saw %q, want a timeout result", resp)
+	}
+	if got := al.abandonedToolCalls.Load(); got != 1 {
+		t.Fatalf("abandonedToolCalls = %d, want 1 while the tool is still running", got)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for al.abandonedToolCalls.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("abandoned tool call was never accounted as finished")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestExecuteToolCall_NoLimitWhenDisabled(t *testing.T) {
+	tool := &slowTool{name: "quick", duration: 10 * time.Millisecond}
+	al := newToolTimeoutTestLoop(t, config.ToolsConfig{CallTimeoutSeconds: 1, CallTimeouts: map[string]int{"quick": 0}}, tool)
+
+	resp, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "cli",
+		SenderID: "user",
+		ChatID:   "direct",
+		Content:  "run the quick tool",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if resp != "executed quick" {
+		t.Fatalf("model saw %q, want the tool's own result", resp)
+	}
+}
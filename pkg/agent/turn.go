@@ -105,6 +105,10 @@ type turnState struct {
 	lastFinishReason string               // Last LLM finish_reason
 	lastUsage        *providers.UsageInfo // Last LLM usage info
 
+	// Streamed direct answer awaiting the turn's final content
+	pendingStream        bus.Streamer
+	pendingStreamContent string
+
 	// Back-reference to the owning AgentLoop (set for SubTurns only, used for hard abort cascade)
 	al *AgentLoop
 }
@@ -402,6 +406,15 @@ func (ts *turnState) interruptHintMessage() providers.Message {
 	}
 }
 
+// noToolsHintMessage tells the model that, despite the system prompt, it has
+// no tools for this turn.
+func noToolsHintMessage() providers.Message {
+	return providers.Message{
+		Role:    "user",
+		Content: "Tools are disabled for this message. Answer directly without calling any tools.",
+	}
+}
+
 // SubTurn-related methods
 
 // Finish marks the turn as finished and closes the pendingResults channel
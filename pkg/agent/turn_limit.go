@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// TurnStats is a snapshot of the turn worker pool.
+type TurnStats struct {
+	Running int `json:"running"` // turns holding a worker slot
+	Waiting int `json:"waiting"` // accepted messages still waiting for a slot
+	Limit   int `json:"limit"`   // agents.defaults.max_parallel_turns
+}
+
+// TurnStats reports how many turns are running and queued. It is safe to call
+// from any goroutine and is exposed on the gateway's /health endpoint.
+func (al *AgentLoop) TurnStats() TurnStats {
+	running := len(al.workerSem)
+	return TurnStats{
+		Running: running,
+		Waiting: max(int(al.inFlightTurns.Load())-running, 0),
+		Limit:   cap(al.workerSem),
+	}
+}
+
+// turnQueueTimeout returns how long a message may wait for a worker slot.
+// Zero means wait until one frees up; negative means do not wait at all.
+func (al *AgentLoop) turnQueueTimeout() time.Duration {
+	cfg := al.GetConfig()
+	if cfg == nil {
+		return 0
+	}
+	return time.Duration(cfg.Agents.Defaults.TurnQueueTimeout) * time.Second
+}
+
+// acquireTurnSlot takes a worker slot for msg, honouring
+// agents.defaults.turn_queue_timeout. When the pool stays full it replies
+// with the busy reply and returns false; the caller must not release a slot.
+func (al *AgentLoop) acquireTurnSlot(ctx context.Context, msg bus.InboundMessage) bool {
+	timeout := al.turnQueueTimeout()
+
+	var expired <-chan time.Time
+	switch {
+	case timeout < 0:
+		select {
+		case al.workerSem <- struct{}{}:
+			return true
+		default:
+		}
+	case timeout > 0:
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+		fallthrough
+	default:
+		select {
+		case al.workerSem <- struct{}{}:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-expired:
+		}
+	}
+
+	logger.WarnCtx(ctx, "agent", "Turn pool full, rejecting message",
+		map[string]any{
+			"channel": msg.Channel,
+			"chat_id": msg.ChatID,
+			"limit":   cap(al.workerSem),
+			"waited":  max(timeout, 0).String(),
+		})
+	if al.channelManager != nil {
+		al.channelManager.InvokeTypingStop(msg.Channel, msg.ChatID)
+	}
+	if msg.ChatID != "" && !constants.IsInternalChannel(msg.Channel) {
+		al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+			Context: outboundContextFromInbound(&msg.Context, msg.Channel, msg.ChatID, msg.MessageID),
+			Content: al.busyReply(msg.Channel, al.localeFor(msg.Channel, msg.Sender.Language)),
+		})
+	}
+	return false
+}
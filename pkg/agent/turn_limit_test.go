@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func TestAcquireTurnSlot_RejectsWhenBusy(t *testing.T) {
+	al, cfg, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Agents.Defaults.TurnQueueTimeout = -1
+
+	al.workerSem <- struct{}{} // pool of one, already taken
+	al.inFlightTurns.Add(2)
+
+	if got := al.TurnStats(); got != (TurnStats{Running: 1, Waiting: 1, Limit: 1}) {
+		t.Fatalf("TurnStats() = %+v", got)
+	}
+
+	msg := bus.InboundMessage{Channel: "telegram", ChatID: "42", Content: "hi"}
+	if al.acquireTurnSlot(context.Background(), msg) {
+		t.Fatal("acquireTurnSlot() = true with a full pool and no wait")
+	}
+
+	select {
+	case out := <-msgBus.OutboundChan():
+		if out.ChatID != "42" || out.Content != "I'm busy with other conversations right now. Please try again in a moment." {
+			t.Fatalf("outbound = %+v, want busy reply to chat 42", out)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a busy reply")
+	}
+}
+
+func TestAcquireTurnSlot_WaitsForFreedSlot(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Agents.Defaults.TurnQueueTimeout = 5
+
+	al.workerSem <- struct{}{}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		<-al.workerSem
+	}()
+
+	if !al.acquireTurnSlot(context.Background(), bus.InboundMessage{Channel: "telegram", ChatID: "42"}) {
+		t.Fatal("acquireTurnSlot() = false, want slot once it is released")
+	}
+	if got := al.TurnStats().Running; got != 1 {
+		t.Fatalf("Running = %d, want 1", got)
+	}
+}
+
+func TestAcquireTurnSlot_CanceledWithoutBusyReply(t *testing.T) {
+	al, _, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	al.workerSem <- struct{}{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if al.acquireTurnSlot(ctx, bus.InboundMessage{Channel: "telegram", ChatID: "42"}) {
+		t.Fatal("acquireTurnSlot() = true after cancel")
+	}
+	if n := len(msgBus.OutboundChan()); n != 0 {
+		t.Fatalf("outbound queue = %d, want no busy reply on cancel", n)
+	}
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// welcomeMessage renders the channel's configured welcome message for msg's
+// sender, or returns "" when the channel has none.
+func (al *AgentLoop) welcomeMessage(agent *AgentInstance, msg bus.InboundMessage) string {
+	cfg := al.GetConfig()
+	if cfg == nil {
+		return ""
+	}
+	ch := cfg.Channels[msg.Channel]
+	if ch == nil || strings.TrimSpace(ch.Welcome.Message) == "" {
+		return ""
+	}
+
+	botName := "PicoClaw"
+	if agent != nil && agent.Name != "" {
+		botName = agent.Name
+	}
+	user := msg.Sender.DisplayName
+	if user == "" {
+		user = msg.Sender.Username
+	}
+	if user == "" {
+		user = "there"
+	}
+	var cmdList []string
+	if al.cmdRegistry != nil {
+		for _, def := range al.cmdRegistry.Definitions() {
+			cmdList = append(cmdList, "/"+def.Name+" - "+def.Description)
+		}
+	}
+
+	return strings.NewReplacer(
+		"{bot_name}", botName,
+		"{user}", user,
+		"{commands}", strings.Join(cmdList, "\n"),
+	).Replace(ch.Welcome.Message)
+}
+
+// greetNewSender sends the channel's welcome message the first time a
+// sender writes to it. Greeted senders are kept in the workspace state so a
+// restart does not greet them again; the state keeps the most recent
+// senders only. A first message of /start is left to the start command,
+// which replies with the same welcome.
+func (al *AgentLoop) greetNewSender(ctx context.Context, agent *AgentInstance, msg bus.InboundMessage) {
+	if al.state == nil || al.bus == nil || msg.SenderID == "" {
+		return
+	}
+	welcome := al.welcomeMessage(agent, msg)
+	if welcome == "" {
+		return
+	}
+	if !al.state.MarkGreeted(msg.Channel + ":" + msg.SenderID) {
+		return
+	}
+	if name, ok := commands.CommandName(msg.Content); ok && name == "start" {
+		return
+	}
+
+	pubCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := al.bus.PublishOutbound(pubCtx, bus.OutboundMessage{
+		Context: bus.NewOutboundContext(msg.Channel, msg.ChatID, ""),
+		Content: welcome,
+	}); err != nil {
+		logger.WarnCtx(ctx, "agent", "Failed to send welcome message", map[string]any{
+			"channel": msg.Channel,
+			"chat_id": msg.ChatID,
+			"error":   err.Error(),
+		})
+	}
+}
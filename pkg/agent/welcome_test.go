@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newWelcomeTestLoop(t *testing.T, workspace string) (*AgentLoop, *bus.MessageBus) {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Channels: config.ChannelsConfig{
+			"telegram": {
+				Enabled: true,
+				Type:    config.ChannelTelegram,
+				Welcome: config.WelcomeConfig{Message: "Hi {user}, I'm {bot_name}.\n{commands}"},
+			},
+		},
+	}
+	msgBus := bus.NewMessageBus()
+	t.Cleanup(msgBus.Close)
+	return NewAgentLoop(cfg, msgBus, &mockProvider{}), msgBus
+}
+
+func drainOutbound(msgBus *bus.MessageBus) []string {
+	var out []string
+	for {
+		select {
+		case msg := <-msgBus.OutboundChan():
+			out = append(out, msg.Content)
+		default:
+			return out
+		}
+	}
+}
+
+func TestGreetNewSender_OncePerSenderAcrossRestarts(t *testing.T) {
+	workspace := t.TempDir()
+	al, msgBus := newWelcomeTestLoop(t, workspace)
+	msg := testInboundMessage(bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:1",
+		Sender:   bus.SenderInfo{DisplayName: "Alice"},
+		ChatID:   "1",
+		Content:  "hello",
+	})
+
+	if _, err := al.processMessage(context.Background(), msg); err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || !strings.HasPrefix(out[0], "Hi Alice, I'm ") || !strings.Contains(out[0], "/start - ") {
+		t.Fatalf("outbound = %q, want one rendered welcome", out)
+	}
+
+	if _, err := al.processMessage(context.Background(), msg); err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if out := drainOutbound(msgBus); len(out) != 0 {
+		t.Fatalf("second message greeted again: %q", out)
+	}
+
+	restarted, restartedBus := newWelcomeTestLoop(t, workspace)
+	if _, err := restarted.processMessage(context.Background(), msg); err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if out := drainOutbound(restartedBus); len(out) != 0 {
+		t.Fatalf("sender greeted again after restart: %q", out)
+	}
+}
+
+func TestGreetNewSender_StartCommandRepliesWithWelcome(t *testing.T) {
+	al, msgBus := newWelcomeTestLoop(t, t.TempDir())
+	response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:2",
+		Sender:   bus.SenderInfo{Username: "bob"},
+		ChatID:   "2",
+		Content:  "/start",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if !strings.HasPrefix(response, "Hi bob, I'm ") {
+		t.Fatalf("/start response = %q, want the welcome message", response)
+	}
+	if out := drainOutbound(msgBus); len(out) != 0 {
+		t.Fatalf("/start also sent an automatic welcome: %q", out)
+	}
+}
+
+func TestGreetNewSender_NoWelcomeConfigured(t *testing.T) {
+	al, msgBus := newWelcomeTestLoop(t, t.TempDir())
+	response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "discord",
+		SenderID: "discord:3",
+		ChatID:   "3",
+		Content:  "/start",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != "Hello! I am PicoClaw 🦞" {
+		t.Fatalf("/start response = %q, want the default greeting", response)
+	}
+	if out := drainOutbound(msgBus); len(out) != 0 {
+		t.Fatalf("outbound = %q, want none", out)
+	}
+}
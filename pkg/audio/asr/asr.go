@@ -49,7 +49,7 @@ func supportsWhisperTranscription(model string) bool {
 		"ollama", "moonshot", "shengsuanyun", "deepseek", "cerebras",
 		"vivgrid", "volcengine", "vllm", "qwen", "qwen-intl", "qwen-international", "dashscope-intl",
 		"qwen-us", "dashscope-us", "mistral", "avian", "minimax", "longcat", "modelscope", "novita",
-		"coding-plan", "alibaba-coding", "qwen-coding", "mimo":
+		"coding-plan", "alibaba-coding", "qwen-coding", "mimo", "custom":
 		return true
 	default:
 		return false
@@ -76,6 +76,7 @@ func transcriberFromModelConfig(modelCfg *config.ModelConfig) Transcriber {
 	if modelCfg == nil {
 		return nil
 	}
+	modelCfg = modelCfg.WithEnvExpanded()
 
 	protocol, _ := providers.ExtractProtocol(modelCfg.Model)
 	if protocol == "elevenlabs" && modelCfg.APIKey() != "" {
@@ -94,6 +95,7 @@ func fallbackTranscriberFromModelConfig(modelCfg *config.ModelConfig) Transcribe
 	if modelCfg == nil {
 		return nil
 	}
+	modelCfg = modelCfg.WithEnvExpanded()
 
 	protocol, _ := providers.ExtractProtocol(modelCfg.Model)
 	if protocol == "elevenlabs" && modelCfg.APIKey() != "" {
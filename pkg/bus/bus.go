@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
@@ -43,11 +44,19 @@ type MessageBus struct {
 	audioChunks   chan AudioChunk
 	voiceControls chan VoiceControl
 
+	// Observers and inbound deduplication, see SubscribeInbound and
+	// SetInboundDedupWindow.
+	inboundSubs  *subscriberSet[InboundMessage]
+	outboundSubs *subscriberSet[OutboundMessage]
+	dedup        *dedupWindow
+	duplicates   atomic.Uint64
+
 	closeOnce      sync.Once
 	done           chan struct{}
 	closed         atomic.Bool
 	wg             sync.WaitGroup
 	streamDelegate atomic.Value // stores StreamDelegate
+	outboundFilter atomic.Pointer[func(string) string]
 }
 
 func NewMessageBus() *MessageBus {
@@ -57,11 +66,17 @@ func NewMessageBus() *MessageBus {
 		outboundMedia: make(chan OutboundMediaMessage, defaultBusBufferSize),
 		audioChunks:   make(chan AudioChunk, defaultBusBufferSize*4), // Audio chunks need more buffer.
 		voiceControls: make(chan VoiceControl, defaultBusBufferSize),
+		inboundSubs:   &subscriberSet[InboundMessage]{stream: "inbound"},
+		outboundSubs:  &subscriberSet[OutboundMessage]{stream: "outbound"},
+		dedup:         newDedupWindow(DefaultInboundDedupWindow),
 		done:          make(chan struct{}),
 	}
 }
 
-func publish[T any](ctx context.Context, mb *MessageBus, ch chan T, msg T) error {
+// publish sends msg to the primary consumer and then, if subs is non-nil, to
+// its subscriptions. Both happen inside the wait group so Close never closes
+// a channel under a sender.
+func publish[T any](ctx context.Context, mb *MessageBus, ch chan T, msg T, subs *subscriberSet[T]) error {
 	// check bus closed before acquiring wg, to avoid unnecessary wg.Add and potential deadlock
 	if mb.closed.Load() {
 		return ErrBusClosed
@@ -81,12 +96,15 @@ func publish[T any](ctx context.Context, mb *MessageBus, ch chan T, msg T) error
 
 	select {
 	case ch <- msg:
-		return nil
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-mb.done:
 		return ErrBusClosed
 	}
+	if subs != nil {
+		subs.deliver(ctx, mb.done, msg)
+	}
+	return nil
 }
 
 func (mb *MessageBus) PublishInbound(ctx context.Context, msg InboundMessage) error {
@@ -94,7 +112,32 @@ func (mb *MessageBus) PublishInbound(ctx context.Context, msg InboundMessage) er
 	if msg.Context.isZero() {
 		return ErrMissingInboundContext
 	}
-	return publish(ctx, mb, mb.inbound, msg)
+	if msg.TraceID == "" {
+		msg.TraceID = logger.TraceIDFromContext(ctx)
+	}
+	if msg.TraceID == "" {
+		msg.TraceID = logger.NewTraceID()
+	}
+	if key := inboundDedupKey(msg); key != "" && mb.dedup.seenRecently(key, time.Now()) {
+		mb.duplicates.Add(1)
+		logger.DebugCF("bus", "Dropped duplicate inbound message", map[string]any{
+			"channel":    msg.Context.Channel,
+			"chat_id":    msg.Context.ChatID,
+			"message_id": msg.Context.MessageID,
+		})
+		return nil
+	}
+	return publish(ctx, mb, mb.inbound, msg, mb.inboundSubs)
+}
+
+// RedeliverInbound publishes msg again without the duplicate check, for a
+// message that was already accepted once and is being retried.
+func (mb *MessageBus) RedeliverInbound(ctx context.Context, msg InboundMessage) error {
+	msg = NormalizeInboundMessage(msg)
+	if msg.Context.isZero() {
+		return ErrMissingInboundContext
+	}
+	return publish(ctx, mb, mb.inbound, msg, mb.inboundSubs)
 }
 
 func (mb *MessageBus) InboundChan() <-chan InboundMessage {
@@ -106,7 +149,13 @@ func (mb *MessageBus) PublishOutbound(ctx context.Context, msg OutboundMessage)
 	if msg.Context.isZero() {
 		return ErrMissingOutboundContext
 	}
-	return publish(ctx, mb, mb.outbound, msg)
+	if msg.TraceID == "" {
+		msg.TraceID = logger.TraceIDFromContext(ctx)
+	}
+	if filter := mb.outboundFilter.Load(); filter != nil {
+		msg.Content = (*filter)(msg.Content)
+	}
+	return publish(ctx, mb, mb.outbound, msg, mb.outboundSubs)
 }
 
 func (mb *MessageBus) OutboundChan() <-chan OutboundMessage {
@@ -118,7 +167,10 @@ func (mb *MessageBus) PublishOutboundMedia(ctx context.Context, msg OutboundMedi
 	if msg.Context.isZero() {
 		return ErrMissingOutboundMediaContext
 	}
-	return publish(ctx, mb, mb.outboundMedia, msg)
+	if msg.TraceID == "" {
+		msg.TraceID = logger.TraceIDFromContext(ctx)
+	}
+	return publish(ctx, mb, mb.outboundMedia, msg, nil)
 }
 
 func (mb *MessageBus) OutboundMediaChan() <-chan OutboundMediaMessage {
@@ -126,7 +178,7 @@ func (mb *MessageBus) OutboundMediaChan() <-chan OutboundMediaMessage {
 }
 
 func (mb *MessageBus) PublishAudioChunk(ctx context.Context, chunk AudioChunk) error {
-	return publish(ctx, mb, mb.audioChunks, chunk)
+	return publish(ctx, mb, mb.audioChunks, chunk, nil)
 }
 
 func (mb *MessageBus) AudioChunksChan() <-chan AudioChunk {
@@ -134,7 +186,7 @@ func (mb *MessageBus) AudioChunksChan() <-chan AudioChunk {
 }
 
 func (mb *MessageBus) PublishVoiceControl(ctx context.Context, ctrl VoiceControl) error {
-	return publish(ctx, mb, mb.voiceControls, ctrl)
+	return publish(ctx, mb, mb.voiceControls, ctrl, nil)
 }
 
 func (mb *MessageBus) VoiceControlsChan() <-chan VoiceControl {
@@ -146,6 +198,17 @@ func (mb *MessageBus) SetStreamDelegate(d StreamDelegate) {
 	mb.streamDelegate.Store(d)
 }
 
+// SetOutboundFilter installs filter to rewrite the content of every outbound
+// message before it is delivered, so no publisher can bypass it. A nil filter
+// removes the current one.
+func (mb *MessageBus) SetOutboundFilter(filter func(content string) string) {
+	if filter == nil {
+		mb.outboundFilter.Store(nil)
+		return
+	}
+	mb.outboundFilter.Store(&filter)
+}
+
 // GetStreamer returns a Streamer for the given channel+chatID via the delegate.
 func (mb *MessageBus) GetStreamer(ctx context.Context, channel, chatID string) (Streamer, bool) {
 	if d, ok := mb.streamDelegate.Load().(StreamDelegate); ok && d != nil {
@@ -172,6 +235,8 @@ func (mb *MessageBus) Close() {
 		close(mb.outboundMedia)
 		close(mb.audioChunks)
 		close(mb.voiceControls)
+		mb.inboundSubs.close()
+		mb.outboundSubs.close()
 
 		// clean up any remaining messages in channels
 		drained := 0
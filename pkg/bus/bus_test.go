@@ -5,6 +5,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
 func TestPublishConsume(t *testing.T) {
@@ -171,6 +173,57 @@ func TestPublishInbound_BackfillsContextFromLegacyFields(t *testing.T) {
 	}
 }
 
+func TestPublishInbound_AssignsTraceID(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	msg := InboundMessage{Channel: "pico", ChatID: "session-1", SenderID: "user-1", Content: "hello"}
+	if err := mb.PublishInbound(context.Background(), msg); err != nil {
+		t.Fatalf("PublishInbound failed: %v", err)
+	}
+	if got := <-mb.InboundChan(); got.TraceID == "" {
+		t.Fatal("expected a generated trace ID")
+	}
+
+	ctx := logger.WithTraceID(context.Background(), "req-123")
+	if err := mb.PublishInbound(ctx, msg); err != nil {
+		t.Fatalf("PublishInbound failed: %v", err)
+	}
+	if got := <-mb.InboundChan(); got.TraceID != "req-123" {
+		t.Fatalf("expected trace ID from context, got %q", got.TraceID)
+	}
+
+	msg.TraceID = "explicit"
+	if err := mb.PublishInbound(ctx, msg); err != nil {
+		t.Fatalf("PublishInbound failed: %v", err)
+	}
+	if got := <-mb.InboundChan(); got.TraceID != "explicit" {
+		t.Fatalf("expected caller-provided trace ID, got %q", got.TraceID)
+	}
+}
+
+func TestPublishOutbound_TakesTraceIDFromContext(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	ctx := logger.WithTraceID(context.Background(), "turn-1")
+	msg := OutboundMessage{Channel: "pico", ChatID: "session-1", Content: "hi"}
+	if err := mb.PublishOutbound(ctx, msg); err != nil {
+		t.Fatalf("PublishOutbound failed: %v", err)
+	}
+	if got := <-mb.OutboundChan(); got.TraceID != "turn-1" {
+		t.Fatalf("expected trace ID turn-1, got %q", got.TraceID)
+	}
+
+	media := OutboundMediaMessage{Channel: "pico", ChatID: "session-1"}
+	if err := mb.PublishOutboundMedia(ctx, media); err != nil {
+		t.Fatalf("PublishOutboundMedia failed: %v", err)
+	}
+	if got := <-mb.OutboundMediaChan(); got.TraceID != "turn-1" {
+		t.Fatalf("expected media trace ID turn-1, got %q", got.TraceID)
+	}
+}
+
 func TestPublishOutboundSubscribe(t *testing.T) {
 	mb := NewMessageBus()
 	defer mb.Close()
@@ -668,3 +721,66 @@ func TestCloseIdempotent(t *testing.T) {
 		t.Fatalf("expected ErrBusClosed after multiple closes, got %v", err)
 	}
 }
+
+func TestPublishInbound_DropsDuplicateMessageIDs(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	ctx := context.Background()
+	msg := InboundMessage{
+		Context: InboundContext{
+			Channel:   "telegram",
+			ChatID:    "chat1",
+			SenderID:  "user1",
+			MessageID: "42",
+		},
+		Content: "hello",
+	}
+
+	for range 2 {
+		if err := mb.PublishInbound(ctx, msg); err != nil {
+			t.Fatalf("PublishInbound failed: %v", err)
+		}
+	}
+	other := msg
+	other.Context.ChatID = "chat2"
+	if err := mb.PublishInbound(ctx, other); err != nil {
+		t.Fatalf("PublishInbound failed: %v", err)
+	}
+	system := msg
+	system.Context.MessageID = ""
+	for range 2 {
+		if err := mb.PublishInbound(ctx, system); err != nil {
+			t.Fatalf("PublishInbound failed: %v", err)
+		}
+	}
+
+	if got := len(mb.InboundChan()); got != 4 {
+		t.Fatalf("queued %d inbound messages, want 4 (one duplicate dropped)", got)
+	}
+	if got := mb.Stats().DuplicatesDropped; got != 1 {
+		t.Fatalf("DuplicatesDropped = %d, want 1", got)
+	}
+
+	mb.SetInboundDedupWindow(0)
+	if err := mb.PublishInbound(ctx, msg); err != nil {
+		t.Fatalf("PublishInbound failed: %v", err)
+	}
+	if got := len(mb.InboundChan()); got != 5 {
+		t.Fatalf("queued %d inbound messages with dedup off, want 5", got)
+	}
+}
+
+func TestDedupWindow_Expires(t *testing.T) {
+	d := newDedupWindow(time.Minute)
+	now := time.Now()
+	if d.seenRecently("a", now) {
+		t.Fatal("first sighting reported as duplicate")
+	}
+	if !d.seenRecently("a", now.Add(30*time.Second)) {
+		t.Fatal("repeat within window not reported as duplicate")
+	}
+	if d.seenRecently("a", now.Add(2*time.Minute)) {
+		t.Fatal("repeat after window reported as duplicate")
+	}
+}
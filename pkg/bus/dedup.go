@@ -0,0 +1,71 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInboundDedupWindow is how long an inbound message ID is
+	// remembered. Platforms redeliver webhooks and events within minutes.
+	DefaultInboundDedupWindow = 5 * time.Minute
+
+	dedupMaxEntries = 10000 // hard cap so a flood of unique IDs stays bounded
+)
+
+// dedupWindow remembers keys for a fixed window. Keys are evicted in
+// insertion order, which is also expiry order, so checks are O(1) amortized.
+type dedupWindow struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]time.Time
+	order  []string
+}
+
+func newDedupWindow(window time.Duration) *dedupWindow {
+	return &dedupWindow{window: window, seen: make(map[string]time.Time)}
+}
+
+// seenRecently records key and reports whether it was already recorded within
+// the window. A zero window disables deduplication.
+func (d *dedupWindow) seenRecently(key string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.window <= 0 {
+		return false
+	}
+	for len(d.order) > 0 {
+		oldest := d.order[0]
+		if now.Sub(d.seen[oldest]) < d.window && len(d.order) < dedupMaxEntries {
+			break
+		}
+		delete(d.seen, oldest)
+		d.order = d.order[1:]
+	}
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+	d.seen[key] = now
+	d.order = append(d.order, key)
+	return false
+}
+
+func (d *dedupWindow) setWindow(window time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.window = window
+	if window <= 0 {
+		clear(d.seen)
+		d.order = nil
+	}
+}
+
+// inboundDedupKey identifies a platform message. Messages without a platform
+// message ID, such as system and voice messages, are never deduplicated.
+func inboundDedupKey(msg InboundMessage) string {
+	if msg.Context.MessageID == "" {
+		return ""
+	}
+	return msg.Context.Channel + "\x00" + msg.Context.Account + "\x00" + msg.Context.ChatID + "\x00" + msg.Context.MessageID
+}
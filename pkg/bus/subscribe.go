@@ -0,0 +1,212 @@
+package bus
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what a publisher does when a subscriber's queue is
+// full.
+type OverflowPolicy int
+
+const (
+	// OverflowDrop discards the message for that subscriber and counts it.
+	// Publishers and other subscribers are never slowed down.
+	OverflowDrop OverflowPolicy = iota
+	// OverflowBlock makes the publisher wait for room, like the primary
+	// consumer. Use it only for subscribers that must see every message.
+	OverflowBlock
+)
+
+// SubscribeOptions configures a Subscription.
+type SubscribeOptions struct {
+	QueueSize int // defaults to the bus buffer size
+	Overflow  OverflowPolicy
+}
+
+// Subscription receives a copy of every message published on one stream
+// after the primary consumer accepted it. Messages arrive in publish order,
+// so messages for the same chat keep their relative order; under
+// OverflowDrop some may be missing.
+type Subscription[T any] struct {
+	name     string
+	ch       chan T
+	overflow OverflowPolicy
+	set      *subscriberSet[T]
+
+	done      chan struct{} // closed by Unsubscribe to release blocked publishers
+	closeOnce sync.Once
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// C returns the subscription's queue. It is closed by Unsubscribe or when
+// the bus closes.
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Unsubscribe stops delivery and closes C. Queued messages can still be read.
+func (s *Subscription[T]) Unsubscribe() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.set.remove(s)
+	})
+}
+
+// SubscriberStats reports one subscription's backpressure.
+type SubscriberStats struct {
+	Name      string `json:"name"`
+	Stream    string `json:"stream"`
+	Queued    int    `json:"queued"`
+	Capacity  int    `json:"capacity"`
+	Delivered uint64 `json:"delivered"`
+	Dropped   uint64 `json:"dropped"`
+}
+
+// subscriberSet fans a stream out to its subscriptions. Publishers hold the
+// read lock while delivering, so removal and close never race a send.
+type subscriberSet[T any] struct {
+	stream string
+	mu     sync.RWMutex
+	subs   []*Subscription[T]
+	closed bool
+}
+
+func (set *subscriberSet[T]) add(name string, opts SubscribeOptions) (*Subscription[T], error) {
+	size := opts.QueueSize
+	if size <= 0 {
+		size = defaultBusBufferSize
+	}
+	s := &Subscription[T]{
+		name:     name,
+		ch:       make(chan T, size),
+		overflow: opts.Overflow,
+		set:      set,
+		done:     make(chan struct{}),
+	}
+
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if set.closed {
+		return nil, ErrBusClosed
+	}
+	set.subs = append(set.subs, s)
+	return s, nil
+}
+
+func (set *subscriberSet[T]) remove(s *Subscription[T]) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if i := slices.Index(set.subs, s); i >= 0 {
+		set.subs = slices.Delete(set.subs, i, i+1)
+		close(s.ch)
+	}
+}
+
+// deliver hands msg to every subscription, honoring each one's overflow
+// policy. It returns early only when ctx is done or the bus closes.
+func (set *subscriberSet[T]) deliver(ctx context.Context, busDone <-chan struct{}, msg T) {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+
+	for _, s := range set.subs {
+		select {
+		case s.ch <- msg:
+			s.delivered.Add(1)
+			continue
+		default:
+		}
+		if s.overflow == OverflowDrop {
+			s.dropped.Add(1)
+			continue
+		}
+		select {
+		case s.ch <- msg:
+			s.delivered.Add(1)
+		case <-s.done:
+			s.dropped.Add(1)
+		case <-ctx.Done():
+			s.dropped.Add(1)
+			return
+		case <-busDone:
+			return
+		}
+	}
+}
+
+func (set *subscriberSet[T]) close() {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	set.closed = true
+	for _, s := range set.subs {
+		close(s.ch)
+	}
+	set.subs = nil
+}
+
+func (set *subscriberSet[T]) stats() []SubscriberStats {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	stats := make([]SubscriberStats, 0, len(set.subs))
+	for _, s := range set.subs {
+		stats = append(stats, SubscriberStats{
+			Name:      s.name,
+			Stream:    set.stream,
+			Queued:    len(s.ch),
+			Capacity:  cap(s.ch),
+			Delivered: s.delivered.Load(),
+			Dropped:   s.dropped.Load(),
+		})
+	}
+	return stats
+}
+
+// SubscribeInbound registers an observer, such as an audit log, that gets a
+// copy of every inbound message after the agent loop's queue accepted it.
+// name identifies the subscription in Stats.
+func (mb *MessageBus) SubscribeInbound(name string, opts SubscribeOptions) (*Subscription[InboundMessage], error) {
+	return mb.inboundSubs.add(name, opts)
+}
+
+// SubscribeOutbound registers an observer that gets a copy of every outbound
+// text message after the channel dispatcher's queue accepted it.
+func (mb *MessageBus) SubscribeOutbound(name string, opts SubscribeOptions) (*Subscription[OutboundMessage], error) {
+	return mb.outboundSubs.add(name, opts)
+}
+
+// SetInboundDedupWindow changes how long inbound message IDs are remembered
+// for deduplication; zero turns deduplication off.
+func (mb *MessageBus) SetInboundDedupWindow(window time.Duration) {
+	mb.dedup.setWindow(window)
+}
+
+// QueueStats reports how full one of the bus's primary queues is.
+type QueueStats struct {
+	Queued   int `json:"queued"`
+	Capacity int `json:"capacity"`
+}
+
+// Stats reports the bus's backpressure: primary queue depths, duplicate
+// inbound messages dropped, and each subscription's queue and drop count.
+type Stats struct {
+	Inbound           QueueStats        `json:"inbound"`
+	Outbound          QueueStats        `json:"outbound"`
+	OutboundMedia     QueueStats        `json:"outbound_media"`
+	DuplicatesDropped uint64            `json:"duplicates_dropped"`
+	Subscribers       []SubscriberStats `json:"subscribers,omitempty"`
+}
+
+// Stats returns a snapshot of the bus's queues and subscriptions.
+func (mb *MessageBus) Stats() Stats {
+	return Stats{
+		Inbound:           QueueStats{Queued: len(mb.inbound), Capacity: cap(mb.inbound)},
+		Outbound:          QueueStats{Queued: len(mb.outbound), Capacity: cap(mb.outbound)},
+		OutboundMedia:     QueueStats{Queued: len(mb.outboundMedia), Capacity: cap(mb.outboundMedia)},
+		DuplicatesDropped: mb.duplicates.Load(),
+		Subscribers:       append(mb.inboundSubs.stats(), mb.outboundSubs.stats()...),
+	}
+}
@@ -0,0 +1,122 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func testOutbound(chatID, content string) OutboundMessage {
+	return OutboundMessage{Context: NewOutboundContext("test", chatID, ""), Content: content}
+}
+
+func TestSubscribeOutbound_DeliversInOrder(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	sub, err := mb.SubscribeOutbound("audit", SubscribeOptions{QueueSize: 16})
+	if err != nil {
+		t.Fatalf("SubscribeOutbound: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := range 5 {
+		if err := mb.PublishOutbound(ctx, testOutbound("chat1", fmt.Sprint(i))); err != nil {
+			t.Fatalf("PublishOutbound: %v", err)
+		}
+		<-mb.OutboundChan()
+	}
+
+	for i := range 5 {
+		got := <-sub.C()
+		if got.Content != fmt.Sprint(i) {
+			t.Fatalf("message %d content = %q, want %q", i, got.Content, fmt.Sprint(i))
+		}
+	}
+}
+
+func TestSubscribeOutbound_SlowDropSubscriberDoesNotBlock(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	slow, _ := mb.SubscribeOutbound("metrics", SubscribeOptions{QueueSize: 1})
+	fast, _ := mb.SubscribeOutbound("audit", SubscribeOptions{QueueSize: 8})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for i := range 3 {
+		if err := mb.PublishOutbound(ctx, testOutbound("chat1", fmt.Sprint(i))); err != nil {
+			t.Fatalf("PublishOutbound blocked on a full drop subscriber: %v", err)
+		}
+		<-mb.OutboundChan()
+	}
+
+	if got := len(fast.C()); got != 3 {
+		t.Errorf("fast subscriber queued %d messages, want 3", got)
+	}
+	var slowStats SubscriberStats
+	for _, s := range mb.Stats().Subscribers {
+		if s.Name == "metrics" {
+			slowStats = s
+		}
+	}
+	if slowStats.Delivered != 1 || slowStats.Dropped != 2 || slowStats.Stream != "outbound" {
+		t.Fatalf("slow subscriber stats = %+v, want 1 delivered, 2 dropped", slowStats)
+	}
+	if got := (<-slow.C()).Content; got != "0" {
+		t.Fatalf("slow subscriber kept %q, want the first message", got)
+	}
+}
+
+func TestSubscribeOutbound_BlockPolicyReleasedByUnsubscribe(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	sub, _ := mb.SubscribeOutbound("audit", SubscribeOptions{QueueSize: 1, Overflow: OverflowBlock})
+
+	ctx := context.Background()
+	_ = mb.PublishOutbound(ctx, testOutbound("chat1", "first"))
+	<-mb.OutboundChan()
+
+	done := make(chan error, 1)
+	go func() { done <- mb.PublishOutbound(ctx, testOutbound("chat1", "second")) }()
+	<-mb.OutboundChan()
+
+	select {
+	case <-done:
+		t.Fatal("publish returned while a blocking subscriber was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	sub.Unsubscribe()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("PublishOutbound: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("publish stayed blocked after Unsubscribe")
+	}
+
+	if got := (<-sub.C()).Content; got != "first" {
+		t.Fatalf("queued message = %q, want %q", got, "first")
+	}
+	if _, ok := <-sub.C(); ok {
+		t.Fatal("subscription channel not closed after Unsubscribe")
+	}
+}
+
+func TestSubscribe_ClosedWithBus(t *testing.T) {
+	mb := NewMessageBus()
+	sub, _ := mb.SubscribeInbound("audit", SubscribeOptions{})
+	mb.Close()
+
+	if _, ok := <-sub.C(); ok {
+		t.Fatal("subscription channel not closed with the bus")
+	}
+	sub.Unsubscribe() // must not panic after the bus closed it
+	if _, err := mb.SubscribeInbound("late", SubscribeOptions{}); err != ErrBusClosed {
+		t.Fatalf("SubscribeInbound after Close error = %v, want ErrBusClosed", err)
+	}
+}
@@ -7,6 +7,7 @@ type SenderInfo struct {
 	CanonicalID string `json:"canonical_id,omitempty"` // "platform:id" format
 	Username    string `json:"username,omitempty"`     // username (e.g. @alice)
 	DisplayName string `json:"display_name,omitempty"` // display name
+	Language    string `json:"language,omitempty"`     // sender's language tag, if the platform reports one (e.g. "zh-hans")
 }
 
 // InboundContext captures the normalized, platform-agnostic facts about an
@@ -48,6 +49,10 @@ type InboundMessage struct {
 	SenderID  string `json:"sender_id"`
 	ChatID    string `json:"chat_id"`
 	MessageID string `json:"message_id,omitempty"` // platform message ID
+
+	// TraceID correlates all log entries of the turn this message starts.
+	// PublishInbound assigns one when the channel did not.
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 // OutboundScope captures the structured session scope associated with an
@@ -70,6 +75,9 @@ type OutboundMessage struct {
 	Scope            *OutboundScope `json:"scope,omitempty"`
 	Content          string         `json:"content"`
 	ReplyToMessageID string         `json:"reply_to_message_id,omitempty"`
+	// TraceID is the correlation ID of the turn that produced the message.
+	// PublishOutbound takes it from the publishing context when unset.
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 // MediaPart describes a single media attachment to send.
@@ -90,6 +98,7 @@ type OutboundMediaMessage struct {
 	SessionKey string         `json:"session_key,omitempty"`
 	Scope      *OutboundScope `json:"scope,omitempty"`
 	Parts      []MediaPart    `json:"parts"`
+	TraceID    string         `json:"trace_id,omitempty"`
 }
 
 // AudioChunk represents a chunk of streaming voice data.
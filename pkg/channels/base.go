@@ -92,6 +92,7 @@ type BaseChannel struct {
 	groupTrigger        config.GroupTriggerConfig
 	mediaStore          media.MediaStore
 	placeholderRecorder PlaceholderRecorder
+	turnCanceler        TurnCanceler
 	owner               Channel // the concrete channel that embeds this BaseChannel
 	reasoningChannelID  string
 }
@@ -247,12 +248,18 @@ func (c *BaseChannel) IsAllowed(senderID string) bool {
 // It delegates to identity.MatchAllowed for each entry, providing unified matching
 // across all legacy formats and the new canonical "platform:id" format.
 func (c *BaseChannel) IsAllowedSender(sender bus.SenderInfo) bool {
+	return c.IsAllowedInChat(sender, "")
+}
+
+// IsAllowedInChat is IsAllowedSender for a message received in chatID, so
+// "chat:<id>" allow-list entries can admit everyone in that chat.
+func (c *BaseChannel) IsAllowedInChat(sender bus.SenderInfo, chatID string) bool {
 	if len(c.allowList) == 0 {
 		return true
 	}
 
 	for _, allowed := range c.allowList {
-		if allowed == "*" || identity.MatchAllowed(sender, allowed) {
+		if identity.MatchAllowedInChat(sender, chatID, allowed) {
 			return true
 		}
 	}
@@ -273,12 +280,16 @@ func (c *BaseChannel) HandleMessageWithContext(
 		sender = senderOpts[0]
 	}
 	senderID := strings.TrimSpace(inboundCtx.SenderID)
+	chatID := inboundCtx.ChatID
+	if chatID == "" {
+		chatID = deliveryChatID
+	}
 	if sender.CanonicalID != "" || sender.PlatformID != "" {
-		if !c.IsAllowedSender(sender) {
+		if !c.IsAllowedInChat(sender, chatID) {
 			return
 		}
 	} else {
-		if !c.IsAllowed(senderID) {
+		if !c.IsAllowed(senderID) && !c.IsAllowedInChat(bus.SenderInfo{}, chatID) {
 			return
 		}
 	}
@@ -303,12 +314,17 @@ func (c *BaseChannel) HandleMessageWithContext(
 
 	scope := BuildMediaScope(c.name, deliveryChatID, inboundCtx.MessageID)
 
+	// The trace ID is assigned here rather than by the bus so that the
+	// typing, reaction and placeholder calls below log under it too.
+	ctx = logger.EnsureTraceID(ctx)
+
 	msg := bus.InboundMessage{
 		Context:    inboundCtx,
 		Sender:     sender,
 		Content:    content,
 		Media:      media,
 		MediaScope: scope,
+		TraceID:    logger.TraceIDFromContext(ctx),
 	}
 	msg = bus.NormalizeInboundMessage(msg)
 
@@ -345,7 +361,7 @@ func (c *BaseChannel) HandleMessageWithContext(
 	}
 
 	if err := c.bus.PublishInbound(ctx, msg); err != nil {
-		logger.ErrorCF("channels", "Failed to publish inbound message", map[string]any{
+		logger.ErrorCtx(ctx, "channels", "Failed to publish inbound message", map[string]any{
 			"channel": c.name,
 			"chat_id": deliveryChatID,
 			"error":   err.Error(),
@@ -385,6 +401,16 @@ func (c *BaseChannel) GetPlaceholderRecorder() PlaceholderRecorder {
 	return c.placeholderRecorder
 }
 
+// SetTurnCanceler injects a TurnCanceler into the channel.
+func (c *BaseChannel) SetTurnCanceler(tc TurnCanceler) {
+	c.turnCanceler = tc
+}
+
+// GetTurnCanceler returns the injected TurnCanceler (may be nil).
+func (c *BaseChannel) GetTurnCanceler() TurnCanceler {
+	return c.turnCanceler
+}
+
 // SetOwner injects the concrete channel that embeds this BaseChannel.
 // This allows HandleMessage to auto-trigger TypingCapable / ReactionCapable / PlaceholderCapable.
 func (c *BaseChannel) SetOwner(ch Channel) {
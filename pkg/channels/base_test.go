@@ -265,6 +265,24 @@ func TestIsAllowedSender(t *testing.T) {
 	}
 }
 
+func TestIsAllowedInChat(t *testing.T) {
+	ch := NewBaseChannel("test", nil, nil, []string{"chat:-1001", "user:42"})
+	stranger := bus.SenderInfo{Platform: "telegram", PlatformID: "7", CanonicalID: "telegram:7"}
+
+	if !ch.IsAllowedInChat(stranger, "-1001") {
+		t.Fatal("sender in allowed chat should be allowed")
+	}
+	if ch.IsAllowedInChat(stranger, "-1002") {
+		t.Fatal("sender in other chat should be rejected")
+	}
+	if ch.IsAllowedSender(stranger) {
+		t.Fatal("chat entries must not match without a chat")
+	}
+	if !ch.IsAllowedInChat(bus.SenderInfo{Platform: "telegram", PlatformID: "42"}, "-1002") {
+		t.Fatal("allowed user should be allowed in any chat")
+	}
+}
+
 func TestHandleInboundContext_PublishesNormalizedContext(t *testing.T) {
 	tests := []struct {
 		name       string
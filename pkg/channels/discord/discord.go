@@ -213,7 +213,7 @@ func (c *DiscordChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMes
 	for _, part := range msg.Parts {
 		localPath, err := store.Resolve(part.Ref)
 		if err != nil {
-			logger.ErrorCF("discord", "Failed to resolve media ref", map[string]any{
+			logger.ErrorCtx(ctx, "discord", "Failed to resolve media ref", map[string]any{
 				"ref":   part.Ref,
 				"error": err.Error(),
 			})
@@ -222,7 +222,7 @@ func (c *DiscordChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMes
 
 		file, err := os.Open(localPath)
 		if err != nil {
-			logger.ErrorCF("discord", "Failed to open media file", map[string]any{
+			logger.ErrorCtx(ctx, "discord", "Failed to open media file", map[string]any{
 				"path":  localPath,
 				"error": err.Error(),
 			})
@@ -393,7 +393,7 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 	}
 	sender.DisplayName = displayName
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedInChat(sender, m.ChannelID) {
 		logger.DebugCF("discord", "Message rejected by allowlist", map[string]any{
 			"user_id": m.Author.ID,
 		})
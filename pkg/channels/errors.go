@@ -8,7 +8,8 @@ var (
 	ErrNotRunning = errors.New("channel not running")
 
 	// ErrRateLimit indicates the platform returned a rate-limit response (e.g. HTTP 429).
-	// Manager will wait a fixed delay and retry.
+	// Manager will wait a fixed delay, or the wait attached by WithRetryAfter,
+	// and retry.
 	ErrRateLimit = errors.New("rate limited")
 
 	// ErrTemporary indicates a transient failure (e.g. network timeout, 5xx).
@@ -1,8 +1,10 @@
 package channels
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // ClassifySendError wraps a raw error with the appropriate sentinel based on
@@ -28,3 +30,32 @@ func ClassifyNetError(err error) error {
 	}
 	return fmt.Errorf("%w: %v", ErrTemporary, err)
 }
+
+// retryAfterError carries the wait a platform asked for before the next
+// attempt.
+type retryAfterError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// WithRetryAfter attaches the wait a platform asked for, such as Telegram's
+// retry_after on a 429, to err. The Manager waits that long before retrying
+// a rate-limited send instead of its fixed delay, up to maxRetryAfter.
+func WithRetryAfter(err error, after time.Duration) error {
+	if err == nil || after <= 0 {
+		return err
+	}
+	return &retryAfterError{err: err, after: after}
+}
+
+// RetryAfter returns the wait attached to err by WithRetryAfter.
+func RetryAfter(err error) (time.Duration, bool) {
+	var ra *retryAfterError
+	if errors.As(err, &ra) {
+		return ra.after, true
+	}
+	return 0, false
+}
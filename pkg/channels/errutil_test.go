@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestClassifySendError(t *testing.T) {
@@ -95,3 +96,25 @@ func TestClassifyNetError(t *testing.T) {
 		}
 	})
 }
+
+func TestWithRetryAfter(t *testing.T) {
+	err := WithRetryAfter(ClassifySendError(429, fmt.Errorf("flood")), 7*time.Second)
+	if !errors.Is(err, ErrRateLimit) {
+		t.Errorf("errors.Is(err, ErrRateLimit) = false; err = %v", err)
+	}
+	if after, ok := RetryAfter(fmt.Errorf("wrapped: %w", err)); !ok || after != 7*time.Second {
+		t.Errorf("RetryAfter() = %v, %v; want 7s, true", after, ok)
+	}
+	if got := rateLimitWait(err); got != 7*time.Second {
+		t.Errorf("rateLimitWait() = %v, want 7s", got)
+	}
+	if got := rateLimitWait(WithRetryAfter(ErrRateLimit, time.Hour)); got != maxRetryAfter {
+		t.Errorf("rateLimitWait() = %v, want the %v cap", got, maxRetryAfter)
+	}
+	if got := rateLimitWait(ErrRateLimit); got != rateLimitDelay {
+		t.Errorf("rateLimitWait() without a hint = %v, want %v", got, rateLimitDelay)
+	}
+	if WithRetryAfter(nil, time.Second) != nil {
+		t.Error("WithRetryAfter(nil) != nil")
+	}
+}
@@ -58,6 +58,7 @@ type cachedMessage struct {
 
 func NewFeishuChannel(bc *config.Channel, cfg *config.FeishuSettings, bus *bus.MessageBus) (*FeishuChannel, error) {
 	base := channels.NewBaseChannel("feishu", cfg, bus, bc.AllowFrom,
+		channels.WithMaxMessageLength(10000), // Card payloads are capped at 30KB; CJK text is 3 bytes per rune
 		channels.WithGroupTrigger(bc.GroupTrigger),
 		channels.WithReasoningChannelID(bc.ReasoningChannelID),
 	)
@@ -168,7 +169,7 @@ func (c *FeishuChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]st
 	isCardLimitError := strings.Contains(errMsg, "11310")
 
 	if isCardLimitError {
-		logger.WarnCF("feishu", "Card send failed (table limit), falling back to text message", map[string]any{
+		logger.WarnCtx(ctx, "feishu", "Card send failed (table limit), falling back to text message", map[string]any{
 			"chat_id": msg.ChatID,
 			"error":   errMsg,
 		})
@@ -214,7 +215,7 @@ func (c *FeishuChannel) EditMessage(ctx context.Context, chatID, messageID, cont
 // Sends an interactive card with placeholder text and returns its message ID.
 func (c *FeishuChannel) SendPlaceholder(ctx context.Context, chatID string) (string, error) {
 	if !c.bc.Placeholder.Enabled {
-		logger.DebugCF("feishu", "Placeholder disabled, skipping", map[string]any{
+		logger.DebugCtx(ctx, "feishu", "Placeholder disabled, skipping", map[string]any{
 			"chat_id": chatID,
 		})
 		return "", nil
@@ -277,7 +278,7 @@ func (c *FeishuChannel) ReactToMessage(ctx context.Context, chatID, messageID st
 
 	resp, err := c.client.Im.V1.MessageReaction.Create(ctx, req)
 	if err != nil {
-		logger.ErrorCF("feishu", "Failed to add reaction", map[string]any{
+		logger.ErrorCtx(ctx, "feishu", "Failed to add reaction", map[string]any{
 			"emoji":      chosenEmoji,
 			"message_id": messageID,
 			"error":      err.Error(),
@@ -286,7 +287,7 @@ func (c *FeishuChannel) ReactToMessage(ctx context.Context, chatID, messageID st
 	}
 	if !resp.Success() {
 		c.invalidateTokenOnAuthError(resp.Code)
-		logger.ErrorCF("feishu", "Reaction API error", map[string]any{
+		logger.ErrorCtx(ctx, "feishu", "Reaction API error", map[string]any{
 			"emoji":      chosenEmoji,
 			"message_id": messageID,
 			"code":       resp.Code,
@@ -420,7 +421,7 @@ func (c *FeishuChannel) handleMessageReceive(ctx context.Context, event *larkim.
 		PlatformID:  senderID,
 		CanonicalID: identity.BuildCanonicalID("feishu", senderID),
 	}
-	if !c.IsAllowedSender(senderInfo) {
+	if !c.IsAllowedInChat(senderInfo, chatID) {
 		return nil
 	}
 
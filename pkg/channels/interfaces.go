@@ -62,6 +62,13 @@ type PlaceholderRecorder interface {
 	RecordReactionUndo(channel, chatID string, undo func())
 }
 
+// TurnCanceler is injected into channels by Manager. Channels call
+// CancelTurns when nobody is left to read a reply, e.g. the last WebChat tab
+// for a chat closed, so the agent stops the turn instead of spending tokens.
+type TurnCanceler interface {
+	CancelTurns(channel, chatID string) int
+}
+
 // CommandRegistrarCapable is implemented by channels that can register
 // command menus with their upstream platform (e.g. Telegram BotCommand).
 // Channels that do not support platform-level command menus can ignore it.
@@ -66,7 +66,7 @@ func (c *IRCChannel) onPrivmsg(conn *ircevent.Connection, e ircmsg.Message) {
 		DisplayName: nick,
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedInChat(sender, chatID) {
 		return
 	}
 
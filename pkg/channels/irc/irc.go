@@ -156,7 +156,7 @@ func (c *IRCChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]strin
 		c.conn.Privmsg(target, line)
 	}
 
-	logger.DebugCF("irc", "Message sent", map[string]any{
+	logger.DebugCtx(ctx, "irc", "Message sent", map[string]any{
 		"target": target,
 		"lines":  len(lines),
 	})
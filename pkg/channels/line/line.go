@@ -528,7 +528,7 @@ func (c *LINEChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]stri
 		tokenEntry := entry.(replyTokenEntry)
 		if time.Since(tokenEntry.timestamp) < lineReplyTokenMaxAge {
 			if err := c.sendReply(ctx, tokenEntry.token, msg.Content, quoteToken); err == nil {
-				logger.DebugCF("line", "Message sent via Reply API", map[string]any{
+				logger.DebugCtx(ctx, "line", "Message sent via Reply API", map[string]any{
 					"chat_id": msg.ChatID,
 					"quoted":  quoteToken != "",
 				})
@@ -638,7 +638,7 @@ func (c *LINEChannel) StartTyping(ctx context.Context, chatID string) (func(), e
 				return
 			case <-ticker.C:
 				if err := c.sendLoading(typingCtx, chatID); err != nil {
-					logger.DebugCF("line", "Failed to refresh loading indicator", map[string]any{
+					logger.DebugCtx(ctx, "line", "Failed to refresh loading indicator", map[string]any{
 						"error": err.Error(),
 					})
 				}
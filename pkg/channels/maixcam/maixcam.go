@@ -2,9 +2,15 @@ package maixcam
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,25 +19,62 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
 )
 
+const (
+	// defaultDeviceID identifies devices that never announce themselves.
+	// Their messages share the "default" chat, as before device IDs existed.
+	defaultDeviceID = "maixcam"
+	defaultChatID   = "default"
+
+	maxImageBytes = 8 << 20
+	writeTimeout  = 10 * time.Second
+)
+
+// MaixCamChannel is a TCP server for MaixCAM devices. Both directions use
+// newline-delimited JSON frames (see MaixCamMessage and the channel README).
 type MaixCamChannel struct {
 	*channels.BaseChannel
 	config     *config.MaixCamSettings
 	listener   net.Listener
 	ctx        context.Context
 	cancel     context.CancelFunc
-	clients    map[net.Conn]bool
+	clients    map[net.Conn]*maixcamClient
+	devices    map[string]*maixcamClient
 	clientsMux sync.RWMutex
 }
 
+// maixcamClient is one device connection. writeMu keeps concurrent replies
+// from interleaving their frames.
+type maixcamClient struct {
+	conn     net.Conn
+	deviceID string
+	writeMu  sync.Mutex
+}
+
+// MaixCamMessage is a frame sent by a device. DeviceID may be set on any
+// frame; a "hello" frame sets it for the rest of the connection.
 type MaixCamMessage struct {
 	Type      string         `json:"type"`
+	DeviceID  string         `json:"device_id,omitempty"`
 	Tips      string         `json:"tips"`
 	Timestamp float64        `json:"timestamp"`
 	Data      map[string]any `json:"data"`
 }
 
+// maixcamReply is a frame sent to a device: a "command" carrying a text
+// reply, or an "image" for the device to display, with Message as its
+// caption.
+type maixcamReply struct {
+	Type        string  `json:"type"`
+	Timestamp   float64 `json:"timestamp"`
+	Message     string  `json:"message"`
+	ChatID      string  `json:"chat_id"`
+	Image       string  `json:"image,omitempty"`
+	ContentType string  `json:"content_type,omitempty"`
+}
+
 func NewMaixCamChannel(
 	bc *config.Channel,
 	cfg *config.MaixCamSettings,
@@ -48,7 +91,8 @@ func NewMaixCamChannel(
 	return &MaixCamChannel{
 		BaseChannel: base,
 		config:      cfg,
-		clients:     make(map[net.Conn]bool),
+		clients:     make(map[net.Conn]*maixcamClient),
+		devices:     make(map[string]*maixcamClient),
 	}, nil
 }
 
@@ -100,27 +144,31 @@ func (c *MaixCamChannel) acceptConnections() {
 				"remote_addr": conn.RemoteAddr().String(),
 			})
 
+			client := &maixcamClient{conn: conn}
 			c.clientsMux.Lock()
-			c.clients[conn] = true
+			c.clients[conn] = client
 			c.clientsMux.Unlock()
 
-			go c.handleConnection(conn)
+			go c.handleConnection(client)
 		}
 	}
 }
 
-func (c *MaixCamChannel) handleConnection(conn net.Conn) {
+func (c *MaixCamChannel) handleConnection(client *maixcamClient) {
 	logger.DebugC("maixcam", "Handling MaixCam connection")
 
 	defer func() {
-		conn.Close()
+		client.conn.Close()
 		c.clientsMux.Lock()
-		delete(c.clients, conn)
+		delete(c.clients, client.conn)
+		if client.deviceID != "" && c.devices[client.deviceID] == client {
+			delete(c.devices, client.deviceID)
+		}
 		c.clientsMux.Unlock()
 		logger.DebugC("maixcam", "Connection closed")
 	}()
 
-	decoder := json.NewDecoder(conn)
+	decoder := json.NewDecoder(client.conn)
 
 	for {
 		select {
@@ -129,7 +177,7 @@ func (c *MaixCamChannel) handleConnection(conn net.Conn) {
 		default:
 			var msg MaixCamMessage
 			if err := decoder.Decode(&msg); err != nil {
-				if err.Error() != "EOF" {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
 					logger.ErrorCF("maixcam", "Failed to decode message", map[string]any{
 						"error": err.Error(),
 					})
@@ -137,15 +185,26 @@ func (c *MaixCamChannel) handleConnection(conn net.Conn) {
 				return
 			}
 
-			c.processMessage(msg, conn)
+			c.processMessage(msg, client)
 		}
 	}
 }
 
-func (c *MaixCamChannel) processMessage(msg MaixCamMessage, conn net.Conn) {
+func (c *MaixCamChannel) processMessage(msg MaixCamMessage, client *maixcamClient) {
+	if msg.DeviceID != "" {
+		c.registerDevice(client, msg.DeviceID)
+	}
+
 	switch msg.Type {
+	case "hello":
+		logger.InfoCF("maixcam", "MaixCam device registered", map[string]any{
+			"device_id":   client.deviceID,
+			"remote_addr": client.conn.RemoteAddr().String(),
+		})
+	case "message":
+		c.handleUserMessage(msg, client)
 	case "person_detected":
-		c.handlePersonDetection(msg)
+		c.handlePersonDetection(msg, client)
 	case "heartbeat":
 		logger.DebugC("maixcam", "Received heartbeat")
 	case "status":
@@ -157,14 +216,141 @@ func (c *MaixCamChannel) processMessage(msg MaixCamMessage, conn net.Conn) {
 	}
 }
 
-func (c *MaixCamChannel) handlePersonDetection(msg MaixCamMessage) {
+// registerDevice binds deviceID to client. A device that reconnects before
+// its old connection has been noticed as dead takes over the ID, and the
+// stale connection is closed so replies stop going to it.
+func (c *MaixCamChannel) registerDevice(client *maixcamClient, deviceID string) {
+	c.clientsMux.Lock()
+	defer c.clientsMux.Unlock()
+
+	if client.deviceID == deviceID {
+		return
+	}
+	if client.deviceID != "" && c.devices[client.deviceID] == client {
+		delete(c.devices, client.deviceID)
+	}
+	if old, ok := c.devices[deviceID]; ok && old != client {
+		logger.InfoCF("maixcam", "MaixCam device reconnected; closing stale connection", map[string]any{
+			"device_id":   deviceID,
+			"remote_addr": old.conn.RemoteAddr().String(),
+		})
+		old.conn.Close()
+	}
+	client.deviceID = deviceID
+	c.devices[deviceID] = client
+}
+
+// identify returns the sender and chat for frames from client.
+func (c *MaixCamChannel) identify(client *maixcamClient) (bus.SenderInfo, string) {
+	c.clientsMux.RLock()
+	deviceID := client.deviceID
+	c.clientsMux.RUnlock()
+
+	chatID := deviceID
+	if deviceID == "" {
+		deviceID, chatID = defaultDeviceID, defaultChatID
+	}
+	return bus.SenderInfo{
+		Platform:    "maixcam",
+		PlatformID:  deviceID,
+		CanonicalID: identity.BuildCanonicalID("maixcam", deviceID),
+	}, chatID
+}
+
+func (c *MaixCamChannel) handleUserMessage(msg MaixCamMessage, client *maixcamClient) {
+	sender, chatID := c.identify(client)
+	if !c.IsAllowedSender(sender) {
+		return
+	}
+
+	text, _ := msg.Data["text"].(string)
+	content := strings.TrimSpace(text)
+
+	var mediaRefs []string
+	if encoded, _ := msg.Data["image"].(string); encoded != "" {
+		ref, err := c.storeImage(chatID, encoded)
+		if err != nil {
+			logger.WarnCF("maixcam", "Failed to store image from MaixCam", map[string]any{
+				"device_id": sender.PlatformID,
+				"error":     err.Error(),
+			})
+		} else {
+			mediaRefs = append(mediaRefs, ref)
+			if content == "" {
+				content = "[image]"
+			}
+		}
+	}
+	if content == "" {
+		return
+	}
+
+	inboundCtx := bus.InboundContext{
+		Channel:  "maixcam",
+		ChatID:   chatID,
+		ChatType: "direct",
+		SenderID: sender.PlatformID,
+		Raw: map[string]string{
+			"timestamp": fmt.Sprintf("%.0f", msg.Timestamp),
+		},
+	}
+
+	c.HandleInboundContext(c.ctx, chatID, content, mediaRefs, inboundCtx, sender)
+}
+
+// storeImage decodes a base64 image from a device into the media store.
+func (c *MaixCamChannel) storeImage(chatID, encoded string) (string, error) {
+	store := c.GetMediaStore()
+	if store == nil {
+		return "", fmt.Errorf("no media store available")
+	}
+	if base64.StdEncoding.DecodedLen(len(encoded)) > maxImageBytes {
+		return "", fmt.Errorf("image exceeds %d bytes", maxImageBytes)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 image: %w", err)
+	}
+
+	contentType := http.DetectContentType(data)
+	ext := ".jpg"
+	if contentType == "image/png" {
+		ext = ".png"
+	}
+	if err := os.MkdirAll(media.TempDir(), 0o700); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(media.TempDir(), "maixcam-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	ref, err := store.Store(f.Name(), media.MediaMeta{
+		Filename:      "maixcam" + ext,
+		ContentType:   contentType,
+		Source:        "maixcam",
+		CleanupPolicy: media.CleanupPolicyDeleteOnCleanup,
+	}, channels.BuildMediaScope("maixcam", chatID, ""))
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return ref, nil
+}
+
+func (c *MaixCamChannel) handlePersonDetection(msg MaixCamMessage, client *maixcamClient) {
 	logger.InfoCF("maixcam", "", map[string]any{
 		"timestamp": msg.Timestamp,
 		"data":      msg.Data,
 	})
 
-	senderID := "maixcam"
-	chatID := "default"
+	sender, chatID := c.identify(client)
 
 	classInfo, ok := msg.Data["class_name"].(string)
 	if !ok {
@@ -190,12 +376,6 @@ func (c *MaixCamChannel) handlePersonDetection(msg MaixCamMessage) {
 		"h":         fmt.Sprintf("%.0f", h),
 	}
 
-	sender := bus.SenderInfo{
-		Platform:    "maixcam",
-		PlatformID:  "maixcam",
-		CanonicalID: identity.BuildCanonicalID("maixcam", "maixcam"),
-	}
-
 	if !c.IsAllowedSender(sender) {
 		return
 	}
@@ -204,7 +384,7 @@ func (c *MaixCamChannel) handlePersonDetection(msg MaixCamMessage) {
 		Channel:  "maixcam",
 		ChatID:   chatID,
 		ChatType: "channel",
-		SenderID: senderID,
+		SenderID: sender.PlatformID,
 		Raw:      metadata,
 	}
 
@@ -236,12 +416,15 @@ func (c *MaixCamChannel) Stop(ctx context.Context) error {
 	for conn := range c.clients {
 		conn.Close()
 	}
-	c.clients = make(map[net.Conn]bool)
+	c.clients = make(map[net.Conn]*maixcamClient)
+	c.devices = make(map[string]*maixcamClient)
 
 	logger.InfoC("maixcam", "MaixCam channel stopped")
 	return nil
 }
 
+// Send delivers a reply to the device that owns msg.ChatID. Replies to the
+// shared "default" chat go to every connected device.
 func (c *MaixCamChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
 	if !c.IsRunning() {
 		return nil, channels.ErrNotRunning
@@ -254,38 +437,141 @@ func (c *MaixCamChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]s
 	default:
 	}
 
-	c.clientsMux.RLock()
-	defer c.clientsMux.RUnlock()
+	return nil, c.deliver(msg.ChatID, maixcamReply{
+		Type:      "command",
+		Timestamp: float64(time.Now().Unix()),
+		Message:   msg.Content,
+		ChatID:    msg.ChatID,
+	})
+}
 
-	if len(c.clients) == 0 {
-		logger.WarnC("maixcam", "No MaixCam devices connected")
-		return nil, fmt.Errorf("no connected MaixCam devices")
+// SendMedia pushes images to the device that owns msg.ChatID for display,
+// one "image" frame per part. Devices have no use for other media, so those
+// parts are skipped.
+func (c *MaixCamChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
 	}
 
-	response := map[string]any{
-		"type":      "command",
-		"timestamp": float64(0),
-		"message":   msg.Content,
-		"chat_id":   msg.ChatID,
+	store := c.GetMediaStore()
+	if store == nil {
+		return nil, fmt.Errorf("no media store available: %w", channels.ErrSendFailed)
 	}
 
-	data, err := json.Marshal(response)
+	sent := 0
+	for _, part := range msg.Parts {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		if part.Type != "image" {
+			logger.WarnCtx(ctx, "maixcam", "Skipping non-image media for MaixCam device", map[string]any{
+				"type":    part.Type,
+				"chat_id": msg.ChatID,
+			})
+			continue
+		}
+
+		data, contentType, err := loadImage(store, part)
+		if err != nil {
+			return nil, fmt.Errorf("maixcam image %q: %v: %w", part.Ref, err, channels.ErrSendFailed)
+		}
+
+		if err := c.deliver(msg.ChatID, maixcamReply{
+			Type:        "image",
+			Timestamp:   float64(time.Now().Unix()),
+			Message:     part.Caption,
+			ChatID:      msg.ChatID,
+			Image:       base64.StdEncoding.EncodeToString(data),
+			ContentType: contentType,
+		}); err != nil {
+			return nil, err
+		}
+		sent++
+	}
+
+	if sent == 0 {
+		return nil, fmt.Errorf("maixcam devices only display images: %w", channels.ErrSendFailed)
+	}
+	return nil, nil
+}
+
+// loadImage reads an image part from the media store, enforcing the same
+// size limit as images sent by devices.
+func loadImage(store media.MediaStore, part bus.MediaPart) ([]byte, string, error) {
+	path, meta, err := store.ResolveWithMeta(part.Ref)
+	if err != nil {
+		return nil, "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.Size() > maxImageBytes {
+		return nil, "", fmt.Errorf("image exceeds %d bytes", maxImageBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType := part.ContentType
+	if contentType == "" {
+		contentType = meta.ContentType
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+// deliver writes frame to the device that owns chatID. Frames for the shared
+// "default" chat go to every connected device.
+func (c *MaixCamChannel) deliver(chatID string, frame maixcamReply) error {
+	data, err := json.Marshal(frame)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	data = append(data, '\n')
+
+	c.clientsMux.RLock()
+	var targets []*maixcamClient
+	if client, ok := c.devices[chatID]; ok {
+		targets = append(targets, client)
+	} else if chatID == defaultChatID || chatID == "" {
+		for _, client := range c.clients {
+			targets = append(targets, client)
+		}
+	}
+	c.clientsMux.RUnlock()
+
+	if len(targets) == 0 {
+		logger.WarnCF("maixcam", "No MaixCam device connected for chat", map[string]any{
+			"chat_id": chatID,
+		})
+		return fmt.Errorf("maixcam device %q not connected: %w", chatID, channels.ErrTemporary)
 	}
 
 	var sendErr error
-	for conn := range c.clients {
-		_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-		if _, err := conn.Write(data); err != nil {
+	for _, client := range targets {
+		if err := client.write(data); err != nil {
 			logger.ErrorCF("maixcam", "Failed to send to client", map[string]any{
-				"client": conn.RemoteAddr().String(),
+				"client": client.conn.RemoteAddr().String(),
 				"error":  err.Error(),
 			})
 			sendErr = fmt.Errorf("maixcam send: %w", channels.ErrTemporary)
 		}
-		_ = conn.SetWriteDeadline(time.Time{})
 	}
+	return sendErr
+}
 
-	return nil, sendErr
+func (cl *maixcamClient) write(data []byte) error {
+	cl.writeMu.Lock()
+	defer cl.writeMu.Unlock()
+	_ = cl.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	defer cl.conn.SetWriteDeadline(time.Time{})
+	_, err := cl.conn.Write(data)
+	return err
 }
@@ -0,0 +1,196 @@
+package maixcam
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+func startTestChannel(t *testing.T, allowFrom ...string) (*MaixCamChannel, *bus.MessageBus) {
+	t.Helper()
+	msgBus := bus.NewMessageBus()
+	bc := &config.Channel{Type: config.ChannelMaixCam, Enabled: true, AllowFrom: allowFrom}
+	ch, err := NewMaixCamChannel(bc, &config.MaixCamSettings{Host: "127.0.0.1", Port: 0}, msgBus)
+	if err != nil {
+		t.Fatalf("NewMaixCamChannel() error = %v", err)
+	}
+	ch.SetMediaStore(media.NewFileMediaStore())
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+	return ch, msgBus
+}
+
+func dialDevice(t *testing.T, ch *MaixCamChannel, frames ...string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", ch.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	for _, frame := range frames {
+		if _, err := fmt.Fprintln(conn, frame); err != nil {
+			t.Fatalf("write frame: %v", err)
+		}
+	}
+	return conn
+}
+
+func nextInbound(t *testing.T, msgBus *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	select {
+	case msg := <-msgBus.InboundChan():
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for inbound message")
+		return bus.InboundMessage{}
+	}
+}
+
+func waitForDevice(t *testing.T, ch *MaixCamChannel, deviceID string, want net.Conn) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		ch.clientsMux.RLock()
+		client, ok := ch.devices[deviceID]
+		ch.clientsMux.RUnlock()
+		if ok && client.conn.RemoteAddr().String() == want.LocalAddr().String() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("device %q was not registered for %s", deviceID, want.LocalAddr())
+}
+
+func TestMaixCam_RoutesTextAndImageByDevice(t *testing.T) {
+	ch, msgBus := startTestChannel(t)
+	image := base64.StdEncoding.EncodeToString([]byte("\xff\xd8\xff\xe0fake jpeg"))
+	conn := dialDevice(t, ch,
+		`{"type":"hello","device_id":"cam-1"}`,
+		`{"type":"message","data":{"text":"what do you see?","image":"`+image+`"}}`,
+	)
+
+	msg := nextInbound(t, msgBus)
+	if msg.ChatID != "cam-1" || msg.SenderID != "cam-1" || msg.Content != "what do you see?" {
+		t.Fatalf("inbound = chat %q sender %q content %q", msg.ChatID, msg.SenderID, msg.Content)
+	}
+	if len(msg.Media) != 1 {
+		t.Fatalf("media = %v, want one image ref", msg.Media)
+	}
+
+	_, err := ch.Send(context.Background(), bus.OutboundMessage{Channel: "maixcam", ChatID: "cam-1", Content: "a cat"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("read reply: %v", err)
+	}
+	var reply maixcamReply
+	if err := json.Unmarshal(line, &reply); err != nil {
+		t.Fatalf("reply %q is not JSON: %v", line, err)
+	}
+	if reply.Type != "command" || reply.Message != "a cat" || reply.ChatID != "cam-1" {
+		t.Fatalf("reply = %+v", reply)
+	}
+
+	if _, err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "cam-2", Content: "x"}); err == nil {
+		t.Fatal("Send() to an unconnected device = nil error")
+	}
+}
+
+func TestMaixCam_AllowFromFiltersDevices(t *testing.T) {
+	ch, msgBus := startTestChannel(t, "cam-ok")
+	dialDevice(t, ch, `{"type":"message","device_id":"cam-blocked","data":{"text":"ignored"}}`)
+	dialDevice(t, ch, `{"type":"message","device_id":"cam-ok","data":{"text":"hello"}}`)
+
+	if msg := nextInbound(t, msgBus); msg.SenderID != "cam-ok" || msg.Content != "hello" {
+		t.Fatalf("inbound = sender %q content %q, want only the allowed device", msg.SenderID, msg.Content)
+	}
+}
+
+func TestMaixCam_ReconnectReplacesStaleConnection(t *testing.T) {
+	ch, _ := startTestChannel(t)
+	old := dialDevice(t, ch, `{"type":"hello","device_id":"cam-1"}`)
+	waitForDevice(t, ch, "cam-1", old)
+	fresh := dialDevice(t, ch, `{"type":"hello","device_id":"cam-1"}`)
+	waitForDevice(t, ch, "cam-1", fresh)
+
+	_ = old.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := old.Read(make([]byte, 1)); err == nil {
+		t.Fatal("stale connection is still open")
+	}
+
+	if _, err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "cam-1", Content: "hi"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	_ = fresh.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := bufio.NewReader(fresh).ReadBytes('\n'); err != nil {
+		t.Fatalf("reconnected device did not get the reply: %v", err)
+	}
+}
+
+func TestMaixCam_SendMediaPushesImageFrame(t *testing.T) {
+	ch, _ := startTestChannel(t)
+	conn := dialDevice(t, ch, `{"type":"hello","device_id":"cam-1"}`)
+	waitForDevice(t, ch, "cam-1", conn)
+
+	png := []byte("\x89PNG\r\n\x1a\nfake chart")
+	path := filepath.Join(t.TempDir(), "chart.png")
+	if err := os.WriteFile(path, png, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := ch.GetMediaStore().Store(path, media.MediaMeta{Filename: "chart.png"}, "test")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	_, err = ch.SendMedia(context.Background(), bus.OutboundMediaMessage{
+		ChatID: "cam-1",
+		Parts: []bus.MediaPart{
+			{Type: "audio", Ref: ref},
+			{Type: "image", Ref: ref, Caption: "sales chart"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendMedia() error = %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	var frame maixcamReply
+	if err := json.Unmarshal(line, &frame); err != nil {
+		t.Fatalf("frame %q is not JSON: %v", line, err)
+	}
+	if frame.Type != "image" || frame.Message != "sales chart" || frame.ContentType != "image/png" {
+		t.Fatalf("frame = type %q message %q content type %q", frame.Type, frame.Message, frame.ContentType)
+	}
+	if got, _ := base64.StdEncoding.DecodeString(frame.Image); string(got) != string(png) {
+		t.Fatalf("frame image = %q, want the stored file", got)
+	}
+
+	_, err = ch.SendMedia(context.Background(), bus.OutboundMediaMessage{
+		ChatID: "cam-1",
+		Parts:  []bus.MediaPart{{Type: "file", Ref: ref}},
+	})
+	if !errors.Is(err, channels.ErrSendFailed) {
+		t.Fatalf("SendMedia() with no images error = %v, want ErrSendFailed", err)
+	}
+}
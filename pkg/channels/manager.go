@@ -15,6 +15,7 @@ import (
 	"net/http"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -32,6 +33,7 @@ const (
 	defaultRateLimit        = 10 // default 10 msg/s
 	maxRetries              = 3
 	rateLimitDelay          = 1 * time.Second
+	maxRetryAfter           = 30 * time.Second
 	baseBackoff             = 500 * time.Millisecond
 	maxBackoff              = 8 * time.Second
 
@@ -93,7 +95,10 @@ type Manager struct {
 	typingStops   sync.Map          // "channel:chatID" → func()
 	reactionUndos sync.Map          // "channel:chatID" → reactionEntry
 	streamActive  sync.Map          // "channel:chatID" → true (set when streamer.Finalize sent the message)
+	sendCounts    sync.Map          // channel name → *sendCounter
 	channelHashes map[string]string // channel name → config hash
+
+	cancelTurns atomic.Pointer[func(channel, chatID string) int]
 }
 
 type asyncTask struct {
@@ -116,6 +121,20 @@ func outboundMediaChatID(msg bus.OutboundMediaMessage) string {
 	return msg.ChatID
 }
 
+// SetTurnCanceler sets how CancelTurns stops the agent's turns for a chat.
+func (m *Manager) SetTurnCanceler(fn func(channel, chatID string) int) {
+	m.cancelTurns.Store(&fn)
+}
+
+// CancelTurns implements TurnCanceler.
+func (m *Manager) CancelTurns(channel, chatID string) int {
+	fn := m.cancelTurns.Load()
+	if fn == nil || *fn == nil {
+		return 0
+	}
+	return (*fn)(channel, chatID)
+}
+
 // RecordPlaceholder registers a placeholder message for later editing.
 // Implements PlaceholderRecorder.
 func (m *Manager) RecordPlaceholder(channel, chatID, placeholderID string) {
@@ -302,7 +321,7 @@ func (m *Manager) GetStreamer(ctx context.Context, channelName, chatID string) (
 
 	streamer, err := sc.BeginStream(ctx, chatID)
 	if err != nil {
-		logger.DebugCF("channels", "Streaming unavailable, falling back to placeholder", map[string]any{
+		logger.DebugCtx(ctx, "channels", "Streaming unavailable, falling back to placeholder", map[string]any{
 			"channel": channelName,
 			"error":   err.Error(),
 		})
@@ -347,7 +366,8 @@ func (m *Manager) initChannel(typeName, channelName string) {
 		"channel": channelName,
 		"type":    typeName,
 	})
-	ch, err := f(channelName, typeName, m.config, m.bus)
+	// Expand ${VAR} references in a copy, so m.config keeps them.
+	ch, err := f(channelName, typeName, m.config.WithChannelEnvExpanded(channelName), m.bus)
 	if err != nil {
 		logger.ErrorCF("channels", "Failed to initialize channel", map[string]any{
 			"channel": channelName,
@@ -365,6 +385,10 @@ func (m *Manager) initChannel(typeName, channelName string) {
 		if setter, ok := ch.(interface{ SetPlaceholderRecorder(r PlaceholderRecorder) }); ok {
 			setter.SetPlaceholderRecorder(m)
 		}
+		// Inject TurnCanceler if channel supports it
+		if setter, ok := ch.(interface{ SetTurnCanceler(tc TurnCanceler) }); ok {
+			setter.SetTurnCanceler(m)
+		}
 		// Inject owner reference so BaseChannel.HandleMessage can auto-trigger typing/reaction
 		if setter, ok := ch.(interface{ SetOwner(ch Channel) }); ok {
 			setter.SetOwner(ch)
@@ -513,7 +537,7 @@ func (m *Manager) registerHTTPHandlersLocked() {
 // registerChannelHTTPHandler registers the webhook/health handlers for a
 // single channel onto m.mux.
 func (m *Manager) registerChannelHTTPHandler(name string, ch Channel) {
-	if wh, ok := ch.(WebhookHandler); ok {
+	if wh, ok := ch.(WebhookHandler); ok && wh.WebhookPath() != "" {
 		m.mux.Handle(wh.WebhookPath(), wh)
 		logger.InfoCF("channels", "Webhook handler registered", map[string]any{
 			"channel": name,
@@ -532,7 +556,7 @@ func (m *Manager) registerChannelHTTPHandler(name string, ch Channel) {
 // unregisterChannelHTTPHandler removes the webhook/health handlers for a
 // single channel from m.mux.
 func (m *Manager) unregisterChannelHTTPHandler(name string, ch Channel) {
-	if wh, ok := ch.(WebhookHandler); ok {
+	if wh, ok := ch.(WebhookHandler); ok && wh.WebhookPath() != "" {
 		m.mux.Unhandle(wh.WebhookPath())
 		logger.InfoCF("channels", "Webhook handler unregistered", map[string]any{
 			"channel": name,
@@ -806,7 +830,7 @@ func splitByLength(content string, maxLen int) []string {
 // sendWithRetry sends a message through the channel with rate limiting and
 // retry logic. It classifies errors to determine the retry strategy:
 //   - ErrNotRunning / ErrSendFailed: permanent, no retry
-//   - ErrRateLimit: fixed delay retry
+//   - ErrRateLimit: retry after the wait set by WithRetryAfter, else a fixed delay
 //   - ErrTemporary / unknown: exponential backoff retry
 func (m *Manager) sendWithRetry(
 	ctx context.Context,
@@ -814,6 +838,9 @@ func (m *Manager) sendWithRetry(
 	w *channelWorker,
 	msg bus.OutboundMessage,
 ) ([]string, bool) {
+	// The channel's own logs for this send carry the turn's trace ID.
+	ctx = logger.WithTraceID(ctx, msg.TraceID)
+
 	// Rate limit: wait for token
 	if err := w.limiter.Wait(ctx); err != nil {
 		// ctx canceled, shutting down
@@ -822,14 +849,18 @@ func (m *Manager) sendWithRetry(
 
 	// Pre-send: stop typing and try to edit placeholder
 	if msgIDs, handled := m.preSend(ctx, name, msg, w.ch); handled {
+		m.sendCounter(name).sent.Add(1)
 		return msgIDs, true
 	}
 
 	var lastErr error
 	var msgIDs []string
+	attempts := 0
 	for attempt := 0; attempt <= maxRetries; attempt++ {
+		attempts++
 		msgIDs, lastErr = w.ch.Send(ctx, msg)
 		if lastErr == nil {
+			m.sendCounter(name).sent.Add(1)
 			return msgIDs, true
 		}
 
@@ -843,10 +874,10 @@ func (m *Manager) sendWithRetry(
 			break
 		}
 
-		// Rate limit error — fixed delay
+		// Rate limit error — the platform's requested wait, or a fixed delay
 		if errors.Is(lastErr, ErrRateLimit) {
 			select {
-			case <-time.After(rateLimitDelay):
+			case <-time.After(rateLimitWait(lastErr)):
 				continue
 			case <-ctx.Done():
 				return nil, false
@@ -863,16 +894,57 @@ func (m *Manager) sendWithRetry(
 	}
 
 	// All retries exhausted or permanent failure
-	logger.ErrorCF("channels", "Send failed", map[string]any{
-		"channel": name,
-		"chat_id": outboundMessageChatID(msg),
-		"error":   lastErr.Error(),
-		"retries": maxRetries,
+	m.sendCounter(name).failed.Add(1)
+	logger.ErrorCtx(ctx, "channels", "Send failed", map[string]any{
+		"channel":  name,
+		"chat_id":  outboundMessageChatID(msg),
+		"error":    lastErr.Error(),
+		"attempts": attempts,
 	})
 
 	return nil, false
 }
 
+// rateLimitWait is how long to wait after a rate-limited send: the wait the
+// platform asked for, capped at maxRetryAfter, or rateLimitDelay.
+func rateLimitWait(err error) time.Duration {
+	if after, ok := RetryAfter(err); ok {
+		return min(after, maxRetryAfter)
+	}
+	return rateLimitDelay
+}
+
+// sendCounter counts outbound deliveries to one channel, text and media
+// alike. Sends abandoned because ctx was cancelled are not counted.
+type sendCounter struct {
+	sent, failed atomic.Uint64
+}
+
+// SendStats reports outbound deliveries to one channel since start.
+type SendStats struct {
+	Sent   uint64 `json:"sent"`
+	Failed uint64 `json:"failed"` // gave up after retries or a permanent error
+}
+
+func (m *Manager) sendCounter(name string) *sendCounter {
+	if c, ok := m.sendCounts.Load(name); ok {
+		return c.(*sendCounter)
+	}
+	c, _ := m.sendCounts.LoadOrStore(name, &sendCounter{})
+	return c.(*sendCounter)
+}
+
+// SendStats returns outbound delivery counts per channel.
+func (m *Manager) SendStats() map[string]SendStats {
+	stats := make(map[string]SendStats)
+	m.sendCounts.Range(func(key, value any) bool {
+		c := value.(*sendCounter)
+		stats[key.(string)] = SendStats{Sent: c.sent.Load(), Failed: c.failed.Load()}
+		return true
+	})
+	return stats
+}
+
 func dispatchLoop[M any](
 	ctx context.Context,
 	m *Manager,
@@ -988,10 +1060,11 @@ func (m *Manager) sendMediaWithRetry(
 	w *channelWorker,
 	msg bus.OutboundMediaMessage,
 ) ([]string, error) {
+	ctx = logger.WithTraceID(ctx, msg.TraceID)
 	ms, ok := w.ch.(MediaSender)
 	if !ok {
 		err := fmt.Errorf("channel %q does not support media sending", name)
-		logger.WarnCF("channels", "Channel does not support MediaSender", map[string]any{
+		logger.WarnCtx(ctx, "channels", "Channel does not support MediaSender", map[string]any{
 			"channel": name,
 			"error":   err.Error(),
 		})
@@ -1008,9 +1081,12 @@ func (m *Manager) sendMediaWithRetry(
 
 	var lastErr error
 	var msgIDs []string
+	attempts := 0
 	for attempt := 0; attempt <= maxRetries; attempt++ {
+		attempts++
 		msgIDs, lastErr = ms.SendMedia(ctx, msg)
 		if lastErr == nil {
+			m.sendCounter(name).sent.Add(1)
 			return msgIDs, nil
 		}
 
@@ -1024,10 +1100,10 @@ func (m *Manager) sendMediaWithRetry(
 			break
 		}
 
-		// Rate limit error — fixed delay
+		// Rate limit error — the platform's requested wait, or a fixed delay
 		if errors.Is(lastErr, ErrRateLimit) {
 			select {
-			case <-time.After(rateLimitDelay):
+			case <-time.After(rateLimitWait(lastErr)):
 				continue
 			case <-ctx.Done():
 				return nil, ctx.Err()
@@ -1044,11 +1120,12 @@ func (m *Manager) sendMediaWithRetry(
 	}
 
 	// All retries exhausted or permanent failure
-	logger.ErrorCF("channels", "SendMedia failed", map[string]any{
-		"channel": name,
-		"chat_id": outboundMediaChatID(msg),
-		"error":   lastErr.Error(),
-		"retries": maxRetries,
+	m.sendCounter(name).failed.Add(1)
+	logger.ErrorCtx(ctx, "channels", "SendMedia failed", map[string]any{
+		"channel":  name,
+		"chat_id":  outboundMediaChatID(msg),
+		"error":    lastErr.Error(),
+		"attempts": attempts,
 	})
 	return nil, lastErr
 }
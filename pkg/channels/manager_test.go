@@ -13,6 +13,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
 // mockChannel is a test double that delegates Send to a configurable function.
@@ -235,6 +236,28 @@ func TestSendWithRetry_Success(t *testing.T) {
 	}
 }
 
+func TestSendWithRetry_PassesTraceIDToChannel(t *testing.T) {
+	m := newTestManager()
+	var traceID string
+	ch := &mockChannel{
+		sendFn: func(ctx context.Context, _ bus.OutboundMessage) error {
+			traceID = logger.TraceIDFromContext(ctx)
+			return nil
+		},
+	}
+	w := &channelWorker{
+		ch:      ch,
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "hello", TraceID: "t-1"})
+	m.sendWithRetry(context.Background(), "test", w, msg)
+
+	if traceID != "t-1" {
+		t.Fatalf("expected Send context to carry trace ID t-1, got %q", traceID)
+	}
+}
+
 func TestSendWithRetry_TemporaryThenSuccess(t *testing.T) {
 	m := newTestManager()
 	var callCount int
@@ -286,6 +309,36 @@ func TestSendWithRetry_PermanentFailure(t *testing.T) {
 	}
 }
 
+func TestSendWithRetry_CountsSentAndFailed(t *testing.T) {
+	m := newTestManager()
+	fail := false
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			if fail {
+				return fmt.Errorf("bad chat ID: %w", ErrSendFailed)
+			}
+			return nil
+		},
+	}
+	w := &channelWorker{
+		ch:      ch,
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+
+	ctx := context.Background()
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "hello"})
+
+	m.sendWithRetry(ctx, "test", w, msg)
+	m.sendWithRetry(ctx, "test", w, msg)
+	fail = true
+	m.sendWithRetry(ctx, "test", w, msg)
+
+	got := m.SendStats()["test"]
+	if got.Sent != 2 || got.Failed != 1 {
+		t.Fatalf("SendStats()[test] = %+v, want 2 sent, 1 failed", got)
+	}
+}
+
 func TestSendWithRetry_NotRunning(t *testing.T) {
 	m := newTestManager()
 	var callCount int
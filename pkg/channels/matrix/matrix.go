@@ -442,7 +442,7 @@ func (c *MatrixChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 
 		localPath, meta, err := store.ResolveWithMeta(part.Ref)
 		if err != nil {
-			logger.ErrorCF("matrix", "Failed to resolve media ref", map[string]any{
+			logger.ErrorCtx(ctx, "matrix", "Failed to resolve media ref", map[string]any{
 				"ref":   part.Ref,
 				"error": err.Error(),
 			})
@@ -451,7 +451,7 @@ func (c *MatrixChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 
 		fileInfo, err := os.Stat(localPath)
 		if err != nil {
-			logger.ErrorCF("matrix", "Failed to stat media file", map[string]any{
+			logger.ErrorCtx(ctx, "matrix", "Failed to stat media file", map[string]any{
 				"path":  localPath,
 				"error": err.Error(),
 			})
@@ -460,7 +460,7 @@ func (c *MatrixChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 
 		file, err := os.Open(localPath)
 		if err != nil {
-			logger.ErrorCF("matrix", "Failed to open media file", map[string]any{
+			logger.ErrorCtx(ctx, "matrix", "Failed to open media file", map[string]any{
 				"path":  localPath,
 				"error": err.Error(),
 			})
@@ -497,7 +497,7 @@ func (c *MatrixChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 		})
 		file.Close()
 		if err != nil {
-			logger.ErrorCF("matrix", "Failed to upload media", map[string]any{
+			logger.ErrorCtx(ctx, "matrix", "Failed to upload media", map[string]any{
 				"path":  localPath,
 				"type":  part.Type,
 				"error": err.Error(),
@@ -517,7 +517,7 @@ func (c *MatrixChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 
 		sendResp, err := c.client.SendMessageEvent(sendCtx, roomID, event.EventMessage, content)
 		if err != nil {
-			logger.ErrorCF("matrix", "Failed to send media message", map[string]any{
+			logger.ErrorCtx(ctx, "matrix", "Failed to send media message", map[string]any{
 				"room_id": roomID.String(),
 				"type":    msgType,
 				"error":   err.Error(),
@@ -705,7 +705,7 @@ func (c *MatrixChannel) handleMessageEvent(ctx context.Context, evt *event.Event
 		DisplayName: senderID,
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedInChat(sender, roomID) {
 		logger.DebugCF("matrix", "Message rejected by allowlist", map[string]any{
 			"sender_id": senderID,
 		})
@@ -102,6 +102,7 @@ func NewOneBotChannel(
 	messageBus *bus.MessageBus,
 ) (*OneBotChannel, error) {
 	base := channels.NewBaseChannel("onebot", cfg, messageBus, bc.AllowFrom,
+		channels.WithMaxMessageLength(4500), // QQ rejects or truncates longer text messages
 		channels.WithGroupTrigger(bc.GroupTrigger),
 		channels.WithReasoningChannelID(bc.ReasoningChannelID),
 	)
@@ -440,7 +441,7 @@ func (c *OneBotChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]st
 	c.writeMu.Unlock()
 
 	if err != nil {
-		logger.ErrorCF("onebot", "Failed to send message", map[string]any{
+		logger.ErrorCtx(ctx, "onebot", "Failed to send message", map[string]any{
 			"error": err.Error(),
 		})
 		return nil, fmt.Errorf("onebot send: %w", channels.ErrTemporary)
@@ -479,7 +480,7 @@ func (c *OneBotChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 	for _, part := range msg.Parts {
 		localPath, err := store.Resolve(part.Ref)
 		if err != nil {
-			logger.ErrorCF("onebot", "Failed to resolve media ref", map[string]any{
+			logger.ErrorCtx(ctx, "onebot", "Failed to resolve media ref", map[string]any{
 				"ref":   part.Ref,
 				"error": err.Error(),
 			})
@@ -551,7 +552,7 @@ func (c *OneBotChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 	c.writeMu.Unlock()
 
 	if err != nil {
-		logger.ErrorCF("onebot", "Failed to send media message", map[string]any{
+		logger.ErrorCtx(ctx, "onebot", "Failed to send media message", map[string]any{
 			"error": err.Error(),
 		})
 		return nil, fmt.Errorf("onebot send media: %w", channels.ErrTemporary)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -29,8 +30,17 @@ type picoConn struct {
 	writeMu   sync.Mutex
 	closed    atomic.Bool
 	cancel    context.CancelFunc // cancels per-connection goroutines (e.g. pingLoop)
+	done      chan struct{}      // closed by close; nil for client connections
 }
 
+// defaultMaxMessageSize bounds a single inbound frame. It leaves room for a
+// few inline base64 images while keeping one client from exhausting memory.
+const defaultMaxMessageSize = 16 << 20
+
+// defaultDisconnectGrace is how long a chat's turns keep running after its
+// last tab closed, so a page reload or a network blip does not abort them.
+const defaultDisconnectGrace = 30 * time.Second
+
 var allowedInlineImageMIMETypes = map[string]struct{}{
 	"image/jpeg": {},
 	"image/png":  {},
@@ -62,6 +72,9 @@ func (pc *picoConn) close() {
 		if pc.cancel != nil {
 			pc.cancel()
 		}
+		if pc.done != nil {
+			close(pc.done)
+		}
 		pc.conn.Close()
 	}
 }
@@ -76,6 +89,9 @@ type PicoChannel struct {
 	connections        map[string]*picoConn            // connID -> *picoConn
 	sessionConnections map[string]map[string]*picoConn // sessionID -> connID -> *picoConn
 	connsMu            sync.RWMutex
+	pendingCancels     map[string]*time.Timer // sessionID -> turn cancellation waiting out the grace period
+	disconnectGrace    time.Duration
+	uploads            sync.Map // media refs issued by handleUpload and not yet sent
 	ctx                context.Context
 	cancel             context.CancelFunc
 }
@@ -92,6 +108,11 @@ func NewPicoChannel(
 
 	base := channels.NewBaseChannel("pico", cfg, messageBus, bc.AllowFrom)
 
+	disconnectGrace := defaultDisconnectGrace
+	if cfg.DisconnectGrace > 0 {
+		disconnectGrace = time.Duration(cfg.DisconnectGrace) * time.Second
+	}
+
 	allowOrigins := cfg.AllowOrigins
 	checkOrigin := func(r *http.Request) bool {
 		if len(allowOrigins) == 0 {
@@ -117,6 +138,8 @@ func NewPicoChannel(
 		},
 		connections:        make(map[string]*picoConn),
 		sessionConnections: make(map[string]map[string]*picoConn),
+		pendingCancels:     make(map[string]*time.Timer),
+		disconnectGrace:    disconnectGrace,
 	}, nil
 }
 
@@ -140,9 +163,15 @@ func (c *PicoChannel) createAndAddConnection(conn *websocket.Conn, sessionID str
 		id:        connID,
 		conn:      conn,
 		sessionID: sessionID,
+		done:      make(chan struct{}),
 	}
 
 	c.connections[pc.id] = pc
+	// The chat is back within the grace period; let its turns finish.
+	if timer := c.pendingCancels[sessionID]; timer != nil {
+		timer.Stop()
+		delete(c.pendingCancels, sessionID)
+	}
 	bySession, ok := c.sessionConnections[pc.sessionID]
 	if !ok {
 		bySession = make(map[string]*picoConn)
@@ -153,25 +182,28 @@ func (c *PicoChannel) createAndAddConnection(conn *websocket.Conn, sessionID str
 	return pc, nil
 }
 
-// removeConnection deletes a connection from indexes and returns it when found.
-func (c *PicoChannel) removeConnection(connID string) *picoConn {
+// removeConnection deletes a connection from indexes and returns it when
+// found, along with whether it was the session's last connection.
+func (c *PicoChannel) removeConnection(connID string) (*picoConn, bool) {
 	c.connsMu.Lock()
 	defer c.connsMu.Unlock()
 
 	pc, ok := c.connections[connID]
 	if !ok {
-		return nil
+		return nil, false
 	}
 
 	delete(c.connections, connID)
+	last := false
 	if bySession, ok := c.sessionConnections[pc.sessionID]; ok {
 		delete(bySession, connID)
 		if len(bySession) == 0 {
 			delete(c.sessionConnections, pc.sessionID)
+			last = true
 		}
 	}
 
-	return pc
+	return pc, last
 }
 
 // takeAllConnections snapshots and clears all connection indexes.
@@ -231,6 +263,12 @@ func (c *PicoChannel) Stop(ctx context.Context) error {
 	for _, pc := range c.takeAllConnections() {
 		pc.close()
 	}
+	c.connsMu.Lock()
+	for sessionID, timer := range c.pendingCancels {
+		timer.Stop()
+		delete(c.pendingCancels, sessionID)
+	}
+	c.connsMu.Unlock()
 
 	if c.cancel != nil {
 		c.cancel()
@@ -240,6 +278,37 @@ func (c *PicoChannel) Stop(ctx context.Context) error {
 	return nil
 }
 
+// scheduleTurnCancel cancels the session's turns once the grace period has
+// passed, unless a connection for the session arrives first.
+func (c *PicoChannel) scheduleTurnCancel(sessionID string) {
+	c.connsMu.Lock()
+	defer c.connsMu.Unlock()
+	if _, connected := c.sessionConnections[sessionID]; connected {
+		return
+	}
+	if timer := c.pendingCancels[sessionID]; timer != nil {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(c.disconnectGrace, func() {
+		c.connsMu.Lock()
+		if c.pendingCancels[sessionID] != timer {
+			c.connsMu.Unlock()
+			return
+		}
+		delete(c.pendingCancels, sessionID)
+		c.connsMu.Unlock()
+
+		if c.ctx.Err() != nil {
+			return
+		}
+		if canceler := c.GetTurnCanceler(); canceler != nil {
+			canceler.CancelTurns(c.Name(), "pico:"+sessionID)
+		}
+	})
+	c.pendingCancels[sessionID] = timer
+}
+
 // WebhookPath implements channels.WebhookHandler.
 func (c *PicoChannel) WebhookPath() string { return "/pico/" }
 
@@ -250,6 +319,8 @@ func (c *PicoChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch path {
 	case "/ws", "/ws/":
 		c.handleWebSocket(w, r)
+	case "/upload":
+		c.handleUpload(w, r)
 	default:
 		http.NotFound(w, r)
 	}
@@ -315,10 +386,14 @@ func (c *PicoChannel) SendPlaceholder(ctx context.Context, chatID string) (strin
 	return msgID, nil
 }
 
+// sessionIDFromChatID strips the "pico:" prefix of a chat ID.
+func sessionIDFromChatID(chatID string) string {
+	return strings.TrimPrefix(chatID, "pico:")
+}
+
 // broadcastToSession sends a message to all connections with a matching session.
 func (c *PicoChannel) broadcastToSession(chatID string, msg PicoMessage) error {
-	// chatID format: "pico:<sessionID>"
-	sessionID := strings.TrimPrefix(chatID, "pico:")
+	sessionID := sessionIDFromChatID(chatID)
 	msg.SessionID = sessionID
 
 	var sent bool
@@ -450,11 +525,19 @@ func (c *PicoChannel) matchedSubprotocol(r *http.Request) string {
 func (c *PicoChannel) readLoop(pc *picoConn) {
 	defer func() {
 		pc.close()
-		if removed := c.removeConnection(pc.id); removed != nil {
-			logger.InfoCF("pico", "WebSocket client disconnected", map[string]any{
-				"conn_id":    removed.id,
-				"session_id": removed.sessionID,
-			})
+		removed, last := c.removeConnection(pc.id)
+		if removed == nil {
+			return
+		}
+		logger.InfoCF("pico", "WebSocket client disconnected", map[string]any{
+			"conn_id":    removed.id,
+			"session_id": removed.sessionID,
+		})
+		// With no tab left to stream to, stop the turn rather than let the
+		// provider keep generating a reply nobody will see. Channel shutdown
+		// is not a user leaving, so it keeps turns running.
+		if last && c.ctx.Err() == nil {
+			c.scheduleTurnCancel(removed.sessionID)
 		}
 	}()
 
@@ -463,6 +546,12 @@ func (c *PicoChannel) readLoop(pc *picoConn) {
 		readTimeout = 60 * time.Second
 	}
 
+	maxMessageSize := c.config.MaxMessageSize
+	if maxMessageSize <= 0 {
+		maxMessageSize = defaultMaxMessageSize
+	}
+	pc.conn.SetReadLimit(maxMessageSize)
+
 	_ = pc.conn.SetReadDeadline(time.Now().Add(readTimeout))
 	pc.conn.SetPongHandler(func(appData string) error {
 		_ = pc.conn.SetReadDeadline(time.Now().Add(readTimeout))
@@ -485,6 +574,13 @@ func (c *PicoChannel) readLoop(pc *picoConn) {
 
 		_, rawMsg, err := pc.conn.ReadMessage()
 		if err != nil {
+			if errors.Is(err, websocket.ErrReadLimit) {
+				logger.WarnCF("pico", "WebSocket message exceeds size limit", map[string]any{
+					"conn_id":   pc.id,
+					"max_bytes": maxMessageSize,
+				})
+				return
+			}
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
 				logger.DebugCF("pico", "WebSocket read error", map[string]any{
 					"conn_id": pc.id,
@@ -507,7 +603,9 @@ func (c *PicoChannel) readLoop(pc *picoConn) {
 	}
 }
 
-// pingLoop sends periodic ping frames to keep the connection alive.
+// pingLoop sends periodic ping frames so proxies and load balancers do not
+// drop the connection while it is quiet, e.g. while the agent thinks. It
+// stops as soon as the connection closes.
 func (c *PicoChannel) pingLoop(pc *picoConn, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -516,6 +614,8 @@ func (c *PicoChannel) pingLoop(pc *picoConn, interval time.Duration) {
 		select {
 		case <-c.ctx.Done():
 			return
+		case <-pc.done:
+			return
 		case <-ticker.C:
 			if pc.closed.Load() {
 				return
@@ -561,6 +661,13 @@ func (c *PicoChannel) handleMessageSend(pc *picoConn, msg PicoMessage) {
 		pc.writeJSON(errMsg)
 		return
 	}
+	if err := c.claimUploads(media); err != nil {
+		errMsg := newErrorWithPayload("invalid_media", err.Error(), map[string]any{
+			"request_id": msg.ID,
+		})
+		pc.writeJSON(errMsg)
+		return
+	}
 
 	if strings.TrimSpace(content) == "" && len(media) == 0 {
 		errMsg := newErrorWithPayload("empty_content", "message content is empty", map[string]any{
@@ -639,7 +746,7 @@ func parseInlineImageMedia(payload map[string]any) ([]string, error) {
 			if err != nil {
 				return nil, fmt.Errorf("media[%d]: %w", i, err)
 			}
-			if err := validateInlineImageDataURL(value); err != nil {
+			if err := validateMediaValue(value); err != nil {
 				return nil, fmt.Errorf("media[%d]: %w", i, err)
 			}
 			media = append(media, value)
@@ -649,7 +756,7 @@ func parseInlineImageMedia(payload map[string]any) ([]string, error) {
 		media := make([]string, 0, len(values))
 		for i, value := range values {
 			value = strings.TrimSpace(value)
-			if err := validateInlineImageDataURL(value); err != nil {
+			if err := validateMediaValue(value); err != nil {
 				return nil, fmt.Errorf("media[%d]: %w", i, err)
 			}
 			media = append(media, value)
@@ -657,7 +764,7 @@ func parseInlineImageMedia(payload map[string]any) ([]string, error) {
 		return media, nil
 	case string:
 		value := strings.TrimSpace(values)
-		if err := validateInlineImageDataURL(value); err != nil {
+		if err := validateMediaValue(value); err != nil {
 			return nil, err
 		}
 		return []string{value}, nil
@@ -686,6 +793,15 @@ func inlineImageValue(item any) (string, error) {
 	}
 }
 
+// validateMediaValue accepts an inline image data URL or a media:// ref
+// returned by the upload endpoint; handleMessageSend checks the ref.
+func validateMediaValue(value string) error {
+	if strings.HasPrefix(value, "media://") {
+		return nil
+	}
+	return validateInlineImageDataURL(value)
+}
+
 func validateInlineImageDataURL(mediaURL string) error {
 	if mediaURL == "" {
 		return fmt.Errorf("image payload is empty")
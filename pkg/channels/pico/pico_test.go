@@ -4,8 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
@@ -85,10 +91,13 @@ func TestRemoveConnection_CleansBothIndexes(t *testing.T) {
 		t.Fatalf("createAndAddConnection: %v", err)
 	}
 
-	removed := ch.removeConnection(pc.id)
+	removed, last := ch.removeConnection(pc.id)
 	if removed == nil {
 		t.Fatal("removeConnection returned nil")
 	}
+	if !last {
+		t.Fatal("removeConnection did not report the session's last connection")
+	}
 
 	ch.connsMu.RLock()
 	defer ch.connsMu.RUnlock()
@@ -123,6 +132,195 @@ func TestBroadcastToSession_TargetsOnlyRequestedSession(t *testing.T) {
 	}
 }
 
+func TestReadLoop_ClosesConnectionOnOversizedMessage(t *testing.T) {
+	bc := &config.Channel{Type: config.ChannelPico, Enabled: true}
+	cfg := &config.PicoSettings{MaxMessageSize: 1024}
+	cfg.SetToken("test-token")
+	ch, err := NewPicoChannel(bc, cfg, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewPicoChannel: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := ch.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer ch.Stop(ctx)
+
+	srv := httptest.NewServer(ch)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/pico/ws"
+	header := http.Header{"Authorization": {"Bearer test-token"}}
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	small := newMessage(TypePing, nil)
+	small.ID = "p1"
+	if err := conn.WriteJSON(small); err != nil {
+		t.Fatalf("write small: %v", err)
+	}
+	var pong PicoMessage
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&pong); err != nil || pong.Type != TypePong {
+		t.Fatalf("expected pong, got %+v err=%v", pong, err)
+	}
+
+	big := newMessage(TypeMessageSend, map[string]any{"content": strings.Repeat("x", 4096)})
+	if err := conn.WriteJSON(big); err != nil {
+		t.Fatalf("write big: %v", err)
+	}
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Fatalf("expected close %d, got %v", websocket.CloseMessageTooBig, err)
+	}
+}
+
+func TestPingLoop_PingsIdleConnection(t *testing.T) {
+	bc := &config.Channel{Type: config.ChannelPico, Enabled: true}
+	cfg := &config.PicoSettings{PingInterval: 1}
+	cfg.SetToken("test-token")
+	ch, err := NewPicoChannel(bc, cfg, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewPicoChannel: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := ch.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer ch.Stop(ctx)
+
+	srv := httptest.NewServer(ch)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/pico/ws"
+	header := http.Header{"Authorization": {"Bearer test-token"}}
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	// Control frames are only handled while reading.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pinged:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no ping within the interval on an idle connection")
+	}
+}
+
+func TestPingLoop_StopsWhenConnectionCloses(t *testing.T) {
+	ch := newTestPicoChannel(t)
+	pc := &picoConn{id: "c1", done: make(chan struct{})}
+
+	stopped := make(chan struct{})
+	go func() {
+		ch.pingLoop(pc, time.Hour)
+		close(stopped)
+	}()
+	close(pc.done)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("pingLoop kept running after the connection closed")
+	}
+}
+
+type recordingTurnCanceler struct {
+	calls chan string
+}
+
+func (r *recordingTurnCanceler) CancelTurns(channel, chatID string) int {
+	r.calls <- channel + "/" + chatID
+	return 1
+}
+
+func TestReadLoop_LastDisconnectCancelsTurns(t *testing.T) {
+	ch := newTestPicoChannel(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := ch.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer ch.Stop(ctx)
+	canceler := &recordingTurnCanceler{calls: make(chan string, 4)}
+	ch.SetTurnCanceler(canceler)
+	ch.disconnectGrace = 100 * time.Millisecond
+
+	srv := httptest.NewServer(ch)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/pico/ws?session_id=tab"
+	header := http.Header{"Authorization": {"Bearer test-token"}}
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(url, header)
+		if err != nil {
+			t.Fatalf("Dial: %v", err)
+		}
+		return conn
+	}
+	waitConns := func(want int) {
+		for ch.currentConnCount() != want {
+			select {
+			case <-ctx.Done():
+				t.Fatalf("connections = %d, want %d", ch.currentConnCount(), want)
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}
+
+	first, second := dial(), dial()
+	waitConns(2)
+
+	first.Close()
+	waitConns(1)
+	select {
+	case call := <-canceler.calls:
+		t.Fatalf("turns cancelled with a tab still open: %s", call)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A reload reconnects within the grace period and keeps the turn.
+	second.Close()
+	waitConns(0)
+	third := dial()
+	waitConns(1)
+	select {
+	case call := <-canceler.calls:
+		t.Fatalf("turns cancelled although the tab reconnected in time: %s", call)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	third.Close()
+	select {
+	case call := <-canceler.calls:
+		if call != ch.Name()+"/pico:tab" {
+			t.Fatalf("CancelTurns(%s), want %s/pico:tab", call, ch.Name())
+		}
+	case <-ctx.Done():
+		t.Fatal("closing the last tab did not cancel turns")
+	}
+}
+
 func (c *PicoChannel) addConnForTest(pc *picoConn) {
 	c.connsMu.Lock()
 	defer c.connsMu.Unlock()
@@ -0,0 +1,99 @@
+package pico
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+// streamUpdateInterval limits how often partial output is pushed to clients;
+// every update carries the full text so far.
+const streamUpdateInterval = 100 * time.Millisecond
+
+// BeginStream implements channels.StreamingCapable. The streamed reply is
+// sent as message.create followed by message.update frames sharing one
+// message_id, and ends with message.done (or message.delete if cancelled).
+func (c *PicoChannel) BeginStream(ctx context.Context, chatID string) (channels.Streamer, error) {
+	if !c.config.Streaming {
+		return nil, fmt.Errorf("streaming disabled in config")
+	}
+	if len(c.sessionConnectionsSnapshot(sessionIDFromChatID(chatID))) == 0 {
+		return nil, fmt.Errorf("no active connections for %s", chatID)
+	}
+	return &picoStreamer{
+		ch:        c,
+		chatID:    chatID,
+		messageID: uuid.New().String(),
+	}, nil
+}
+
+// DeleteMessage implements channels.MessageDeleter.
+func (c *PicoChannel) DeleteMessage(ctx context.Context, chatID string, messageID string) error {
+	return c.broadcastToSession(chatID, newMessage(TypeMessageDelete, map[string]any{
+		"message_id": messageID,
+	}))
+}
+
+type picoStreamer struct {
+	ch        *PicoChannel
+	chatID    string
+	messageID string
+
+	mu      sync.Mutex
+	started bool
+	lastAt  time.Time
+}
+
+func (s *picoStreamer) Update(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started && time.Since(s.lastAt) < streamUpdateInterval {
+		return nil
+	}
+	s.lastAt = time.Now()
+	return s.sendLocked(content)
+}
+
+func (s *picoStreamer) Finalize(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.sendLocked(content); err != nil {
+		return err
+	}
+	return s.ch.broadcastToSession(s.chatID, newMessage(TypeMessageDone, map[string]any{
+		"message_id": s.messageID,
+	}))
+}
+
+func (s *picoStreamer) Cancel(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		_ = s.ch.DeleteMessage(ctx, s.chatID, s.messageID)
+	}
+}
+
+// sendLocked creates the message on first use and updates it afterwards.
+func (s *picoStreamer) sendLocked(content string) error {
+	msgType := TypeMessageUpdate
+	if !s.started {
+		msgType = TypeMessageCreate
+	}
+	err := s.ch.broadcastToSession(s.chatID, newMessage(msgType, map[string]any{
+		PayloadKeyContent: content,
+		PayloadKeyThought: false,
+		"message_id":      s.messageID,
+	}))
+	if err == nil {
+		s.started = true
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package pico
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func dialStreamingPico(t *testing.T) (*PicoChannel, *websocket.Conn) {
+	t.Helper()
+
+	bc := &config.Channel{Type: config.ChannelPico, Enabled: true}
+	cfg := &config.PicoSettings{Streaming: true}
+	cfg.SetToken("test-token")
+	ch, err := NewPicoChannel(bc, cfg, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewPicoChannel: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := ch.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+
+	srv := httptest.NewServer(ch)
+	t.Cleanup(srv.Close)
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/pico/ws?session_id=s1"
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"Bearer test-token"}})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for ch.currentConnCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return ch, conn
+}
+
+func readPico(t *testing.T, conn *websocket.Conn) PicoMessage {
+	t.Helper()
+	var msg PicoMessage
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	return msg
+}
+
+func TestPicoStreamer_StreamsAndFinalizes(t *testing.T) {
+	ch, conn := dialStreamingPico(t)
+	ctx := context.Background()
+
+	streamer, err := ch.BeginStream(ctx, "pico:s1")
+	if err != nil {
+		t.Fatalf("BeginStream: %v", err)
+	}
+	if err := streamer.Update(ctx, "Hel"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	// Throttled: arrives within the update interval and is skipped.
+	_ = streamer.Update(ctx, "Hello")
+	if err := streamer.Finalize(ctx, "Hello, world"); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+
+	create := readPico(t, conn)
+	id, _ := create.Payload["message_id"].(string)
+	if create.Type != TypeMessageCreate || create.Payload[PayloadKeyContent] != "Hel" || id == "" {
+		t.Fatalf("first frame = %+v, want message.create with partial content", create)
+	}
+	update := readPico(t, conn)
+	if update.Type != TypeMessageUpdate || update.Payload["message_id"] != id ||
+		update.Payload[PayloadKeyContent] != "Hello, world" {
+		t.Fatalf("second frame = %+v, want final message.update", update)
+	}
+	done := readPico(t, conn)
+	if done.Type != TypeMessageDone || done.Payload["message_id"] != id {
+		t.Fatalf("third frame = %+v, want message.done", done)
+	}
+}
+
+func TestPicoStreamer_CancelDeletesPartialMessage(t *testing.T) {
+	ch, conn := dialStreamingPico(t)
+	ctx := context.Background()
+
+	streamer, err := ch.BeginStream(ctx, "pico:s1")
+	if err != nil {
+		t.Fatalf("BeginStream: %v", err)
+	}
+	_ = streamer.Update(ctx, "partial")
+	streamer.Cancel(ctx)
+
+	create := readPico(t, conn)
+	del := readPico(t, conn)
+	if del.Type != TypeMessageDelete || del.Payload["message_id"] != create.Payload["message_id"] {
+		t.Fatalf("frame = %+v, want message.delete for %v", del, create.Payload["message_id"])
+	}
+}
+
+func TestPicoBeginStream_RequiresConfigAndConnection(t *testing.T) {
+	ch := newTestPicoChannel(t)
+	if _, err := ch.BeginStream(context.Background(), "pico:s1"); err == nil {
+		t.Fatal("expected error when streaming is disabled")
+	}
+
+	ch.config.Streaming = true
+	if _, err := ch.BeginStream(context.Background(), "pico:nobody"); err == nil {
+		t.Fatal("expected error without an active connection")
+	}
+}
@@ -0,0 +1,231 @@
+package pico
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+// uploadFormField is the multipart field that carries the file.
+const uploadFormField = "file"
+
+// defaultUploadMIMETypes are the file types accepted when upload_mime_types
+// is not configured: images for vision, and documents the agent can read.
+var defaultUploadMIMETypes = []string{"image/*", "text/*", "application/json", "application/pdf"}
+
+// uploadResponse is returned by POST /pico/upload. Ref goes into the media
+// list of a later message.send on the same channel.
+type uploadResponse struct {
+	Ref      string `json:"ref"`
+	Filename string `json:"filename"`
+	MIMEType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+}
+
+func (c *PicoChannel) maxUploadSize() int64 {
+	if c.config.MaxUploadSize > 0 {
+		return c.config.MaxUploadSize
+	}
+	return config.DefaultMaxMediaSize
+}
+
+func (c *PicoChannel) uploadAllowed(mimeType string) bool {
+	allowed := c.config.UploadMIMETypes
+	if len(allowed) == 0 {
+		allowed = defaultUploadMIMETypes
+	}
+	family, _, _ := strings.Cut(mimeType, "/")
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == mimeType || pattern == "*/*" || pattern == family+"/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// handleUpload stores a file sent as multipart/form-data in the media store.
+// The file is kept in the shared media directory, which the file tools may
+// read and the media cleaner expires, and is handed to the agent once a
+// message references it.
+//
+//	POST /pico/upload
+func (c *PicoChannel) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !c.authenticate(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	store := c.GetMediaStore()
+	if !c.IsRunning() || store == nil {
+		http.Error(w, "uploads are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	maxSize := c.maxUploadSize()
+	// Leave room for the multipart framing around the file.
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+64<<10)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "expected a multipart/form-data body", http.StatusBadRequest)
+		return
+	}
+	var part io.ReadCloser
+	var filename string
+	for {
+		p, err := reader.NextPart()
+		if err != nil {
+			http.Error(w, "missing file field", http.StatusBadRequest)
+			return
+		}
+		if p.FormName() == uploadFormField {
+			part, filename = p, filepath.Base(p.FileName())
+			break
+		}
+		p.Close()
+	}
+	defer part.Close()
+	if filename == "" || filename == "." || filename == string(filepath.Separator) {
+		filename = "upload"
+	}
+
+	localPath, size, err := saveUpload(part, filename, maxSize)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var tooLarge *http.MaxBytesError
+		if errors.Is(err, errUploadTooLarge) || errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+			err = fmt.Errorf("file exceeds %d byte limit", maxSize)
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	mimeType := c.detectUploadMIME(localPath, filename)
+	if !c.uploadAllowed(mimeType) {
+		os.Remove(localPath)
+		http.Error(w, fmt.Sprintf("unsupported file type: %s", mimeType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session_id")
+	ref, err := store.Store(localPath, media.MediaMeta{
+		Filename:      filename,
+		ContentType:   mimeType,
+		Source:        "pico",
+		CleanupPolicy: media.CleanupPolicyDeleteOnCleanup,
+	}, channels.BuildMediaScope("pico", "pico:"+sessionID, ""))
+	if err != nil {
+		os.Remove(localPath)
+		http.Error(w, "failed to store upload", http.StatusInternalServerError)
+		return
+	}
+	c.pruneUploads()
+	c.uploads.Store(ref, struct{}{})
+
+	logger.DebugCF("pico", "Stored upload", map[string]any{
+		"session_id": sessionID,
+		"filename":   filename,
+		"mime_type":  mimeType,
+		"size":       size,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uploadResponse{Ref: ref, Filename: filename, MIMEType: mimeType, Size: size})
+}
+
+var errUploadTooLarge = errors.New("upload too large")
+
+// saveUpload copies src into a new file in the media directory, keeping the
+// original extension so file tools and MIME detection can rely on it.
+func saveUpload(src io.Reader, filename string, maxSize int64) (string, int64, error) {
+	if err := os.MkdirAll(media.TempDir(), 0o700); err != nil {
+		return "", 0, err
+	}
+	f, err := os.CreateTemp(media.TempDir(), "pico-upload-*"+filepath.Ext(filename))
+	if err != nil {
+		return "", 0, err
+	}
+	n, err := io.Copy(f, io.LimitReader(src, maxSize+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > maxSize {
+		err = errUploadTooLarge
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", 0, err
+	}
+	return f.Name(), n, nil
+}
+
+// detectUploadMIME sniffs the file's content. Plain text is refined by the
+// file extension, so a CSV or JSON file is not reported as text/plain. The
+// refined type is only used when it is text/* or allowed for upload, so a
+// YAML or shell file is not rejected for an application/* extension type.
+func (c *PicoChannel) detectUploadMIME(localPath, filename string) string {
+	head := make([]byte, 512)
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	n, _ := io.ReadFull(f, head)
+	f.Close()
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if sniffed == "text/plain" {
+		byExt, _, err := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(filename)))
+		if err == nil && (strings.HasPrefix(byExt, "text/") || c.uploadAllowed(byExt)) {
+			return byExt
+		}
+	}
+	return sniffed
+}
+
+// pruneUploads forgets refs that were never sent and have since been removed
+// by the media cleaner.
+func (c *PicoChannel) pruneUploads() {
+	store := c.GetMediaStore()
+	c.uploads.Range(func(key, _ any) bool {
+		if _, err := store.Resolve(key.(string)); err != nil {
+			c.uploads.Delete(key)
+		}
+		return true
+	})
+}
+
+// claimUploads checks that every media:// ref in refs was issued by this
+// channel's upload endpoint, and forgets them so each is sent only once. Each
+// ref is claimed atomically, so two concurrent sends cannot both use it; when
+// one ref is unknown, the refs claimed before it are given back.
+func (c *PicoChannel) claimUploads(refs []string) error {
+	var claimed []string
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref, "media://") {
+			continue
+		}
+		if _, ok := c.uploads.LoadAndDelete(ref); !ok {
+			for _, r := range claimed {
+				c.uploads.Store(r, struct{}{})
+			}
+			return fmt.Errorf("unknown upload %s", ref)
+		}
+		claimed = append(claimed, ref)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package pico
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+func newUploadTestChannel(t *testing.T, cfg *config.PicoSettings) (*PicoChannel, *bus.MessageBus, media.MediaStore) {
+	t.Helper()
+	cfg.SetToken("test-token")
+	mb := bus.NewMessageBus()
+	ch, err := NewPicoChannel(&config.Channel{Type: config.ChannelPico, Enabled: true}, cfg, mb)
+	if err != nil {
+		t.Fatalf("NewPicoChannel() error = %v", err)
+	}
+	store := media.NewFileMediaStore()
+	ch.SetMediaStore(store)
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+	return ch, mb, store
+}
+
+// postUpload sends data to the upload endpoint. Files it stores are removed
+// when the test ends.
+func postUpload(t *testing.T, ch *PicoChannel, token, filename string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, _ := w.CreateFormFile(uploadFormField, filename)
+	part.Write(data)
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/pico/upload?session_id=sess-1", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, req)
+
+	var resp uploadResponse
+	if json.Unmarshal(rec.Body.Bytes(), &resp) == nil && resp.Ref != "" {
+		if path, err := ch.GetMediaStore().Resolve(resp.Ref); err == nil {
+			t.Cleanup(func() { os.Remove(path) })
+		}
+	}
+	return rec
+}
+
+func TestHandleUpload_StoresFileForNextMessage(t *testing.T) {
+	ch, mb, store := newUploadTestChannel(t, &config.PicoSettings{})
+
+	if rec := postUpload(t, ch, "wrong", "data.json", []byte(`{}`)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("upload with a bad token: status = %d", rec.Code)
+	}
+
+	rec := postUpload(t, ch, "test-token", "data.json", []byte(`{"rows": 3}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("upload status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp uploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !strings.HasPrefix(resp.Ref, "media://") || resp.Filename != "data.json" ||
+		resp.MIMEType != "application/json" || resp.Size != 11 {
+		t.Fatalf("response = %+v", resp)
+	}
+	if _, meta, err := store.ResolveWithMeta(resp.Ref); err != nil || meta.ContentType != "application/json" {
+		t.Fatalf("ResolveWithMeta() = %+v, %v", meta, err)
+	}
+
+	ch.handleMessageSend(&picoConn{id: "conn-1", sessionID: "sess-1"}, PicoMessage{
+		ID:      "msg-1",
+		Payload: map[string]any{"content": "summarize this", "media": []any{resp.Ref}},
+	})
+	select {
+	case msg := <-mb.InboundChan():
+		if len(msg.Media) != 1 || msg.Media[0] != resp.Ref {
+			t.Fatalf("msg.Media = %v, want the upload ref", msg.Media)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message with an upload was not published")
+	}
+
+	if err := ch.claimUploads([]string{resp.Ref}); err == nil {
+		t.Fatal("an upload ref was accepted twice")
+	}
+	if err := ch.claimUploads([]string{"media://not-issued"}); err == nil {
+		t.Fatal("a ref that was never uploaded was accepted")
+	}
+}
+
+func TestHandleUpload_EnforcesSizeAndType(t *testing.T) {
+	ch, _, _ := newUploadTestChannel(t, &config.PicoSettings{
+		MaxUploadSize:   16,
+		UploadMIMETypes: []string{"text/*"},
+	})
+
+	if rec := postUpload(t, ch, "test-token", "notes.txt", []byte("short note")); rec.Code != http.StatusOK {
+		t.Fatalf("text upload status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := postUpload(t, ch, "test-token", "big.txt", []byte(strings.Repeat("x", 17))); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized upload status = %d", rec.Code)
+	}
+	for _, name := range []string{"config.yaml", "deploy.sh"} {
+		rec := postUpload(t, ch, "test-token", name, []byte("key: value\n"))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s upload status = %d: %s", name, rec.Code, rec.Body.String())
+		}
+		var resp uploadResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || !strings.HasPrefix(resp.MIMEType, "text/") {
+			t.Fatalf("%s upload = %+v (%v), want a text/* type", name, resp, err)
+		}
+	}
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if rec := postUpload(t, ch, "test-token", "image.png", png); rec.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("disallowed type status = %d", rec.Code)
+	}
+}
@@ -249,7 +249,7 @@ func (c *QQChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string
 	}
 
 	if err != nil {
-		logger.ErrorCF("qq", "Failed to send message", map[string]any{
+		logger.ErrorCtx(ctx, "qq", "Failed to send message", map[string]any{
 			"chat_id":   msg.ChatID,
 			"chat_kind": chatKind,
 			"error":     err.Error(),
@@ -296,7 +296,7 @@ func (c *QQChannel) StartTyping(ctx context.Context, chatID string) (func(), err
 			_, err = c.api.PostC2CMessage(sendCtx, chatID, typingMsg)
 		}
 		if err != nil {
-			logger.DebugCF("qq", "Failed to send typing indicator", map[string]any{
+			logger.DebugCtx(ctx, "qq", "Failed to send typing indicator", map[string]any{
 				"chat_id": chatID,
 				"error":   err.Error(),
 			})
@@ -338,7 +338,7 @@ func (c *QQChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessage)
 	for _, part := range msg.Parts {
 		fileInfo, err := c.uploadMedia(ctx, chatKind, msg.ChatID, part)
 		if err != nil {
-			logger.ErrorCF("qq", "Failed to upload media", map[string]any{
+			logger.ErrorCtx(ctx, "qq", "Failed to upload media", map[string]any{
 				"type":    part.Type,
 				"chat_id": msg.ChatID,
 				"error":   err.Error(),
@@ -351,7 +351,7 @@ func (c *QQChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessage)
 
 		sentMsg, err := c.sendUploadedMedia(ctx, chatKind, msg.ChatID, part, fileInfo)
 		if err != nil {
-			logger.ErrorCF("qq", "Failed to send media", map[string]any{
+			logger.ErrorCtx(ctx, "qq", "Failed to send media", map[string]any{
 				"type":    part.Type,
 				"chat_id": msg.ChatID,
 				"error":   err.Error(),
@@ -29,6 +29,10 @@ type SlackChannel struct {
 	ctx          context.Context
 	cancel       context.CancelFunc
 	pendingAcks  sync.Map
+	handlers     sync.WaitGroup
+
+	queuesMu sync.Mutex
+	queues   map[string][]func() // pending handlers per Slack channel ID
 }
 
 type slackMessageRef struct {
@@ -63,6 +67,7 @@ func NewSlackChannel(
 		config:       cfg,
 		api:          api,
 		socketClient: socketClient,
+		queues:       make(map[string][]func()),
 	}, nil
 }
 
@@ -107,6 +112,21 @@ func (c *SlackChannel) Stop(ctx context.Context) error {
 		c.cancel()
 	}
 
+	// Wait for queued event handlers, but no longer than ctx allows: a
+	// handler stuck on a slow Slack API call must not hang shutdown.
+	done := make(chan struct{})
+	go func() {
+		c.handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.WarnCF("slack", "Stop context ended before all event handlers finished", map[string]any{
+			"error": ctx.Err().Error(),
+		})
+	}
+
 	c.SetRunning(false)
 	logger.InfoC("slack", "Slack channel stopped")
 	return nil
@@ -147,7 +167,7 @@ func (c *SlackChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]str
 		})
 	}
 
-	logger.DebugCF("slack", "Message sent", map[string]any{
+	logger.DebugCtx(ctx, "slack", "Message sent", map[string]any{
 		"channel_id": channelID,
 		"thread_ts":  threadTS,
 	})
@@ -174,7 +194,7 @@ func (c *SlackChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessa
 	for _, part := range msg.Parts {
 		localPath, err := store.Resolve(part.Ref)
 		if err != nil {
-			logger.ErrorCF("slack", "Failed to resolve media ref", map[string]any{
+			logger.ErrorCtx(ctx, "slack", "Failed to resolve media ref", map[string]any{
 				"ref":   part.Ref,
 				"error": err.Error(),
 			})
@@ -199,7 +219,7 @@ func (c *SlackChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessa
 			Title:           title,
 		})
 		if err != nil {
-			logger.ErrorCF("slack", "Failed to upload media", map[string]any{
+			logger.ErrorCtx(ctx, "slack", "Failed to upload media", map[string]any{
 				"filename": filename,
 				"error":    err.Error(),
 			})
@@ -267,11 +287,50 @@ func (c *SlackChannel) handleEventsAPI(event socketmode.Event) {
 		return
 	}
 
+	// The envelope is already acknowledged; handle the event off the event
+	// loop so slow work (file downloads, a full bus) never delays the acks
+	// Slack expects within 3 seconds for subsequent events.
 	switch ev := eventsAPIEvent.InnerEvent.Data.(type) {
 	case *slackevents.MessageEvent:
-		c.handleMessageEvent(ev)
+		c.dispatch(ev.Channel, func() { c.handleMessageEvent(ev) })
 	case *slackevents.AppMentionEvent:
-		c.handleAppMention(ev)
+		c.dispatch(ev.Channel, func() { c.handleAppMention(ev) })
+	}
+}
+
+// dispatch queues fn behind the earlier events of the Slack channel
+// channelID. Each channel's queue is drained by one goroutine, so its events
+// are handled in the order they arrived while a slow channel holds up
+// neither the others nor the event loop. Stop waits for queued handlers to
+// finish.
+func (c *SlackChannel) dispatch(channelID string, fn func()) {
+	c.handlers.Add(1)
+	c.queuesMu.Lock()
+	pending, running := c.queues[channelID]
+	c.queues[channelID] = append(pending, fn)
+	c.queuesMu.Unlock()
+	if !running {
+		go c.drainQueue(channelID)
+	}
+}
+
+// drainQueue runs the queued handlers of channelID one at a time and drops
+// the queue once it is empty.
+func (c *SlackChannel) drainQueue(channelID string) {
+	for {
+		c.queuesMu.Lock()
+		pending := c.queues[channelID]
+		if len(pending) == 0 {
+			delete(c.queues, channelID)
+			c.queuesMu.Unlock()
+			return
+		}
+		fn := pending[0]
+		c.queues[channelID] = pending[1:]
+		c.queuesMu.Unlock()
+
+		fn()
+		c.handlers.Done()
 	}
 }
 
@@ -292,7 +351,7 @@ func (c *SlackChannel) handleMessageEvent(ev *slackevents.MessageEvent) {
 		PlatformID:  ev.User,
 		CanonicalID: identity.BuildCanonicalID("slack", ev.User),
 	}
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedInChat(sender, ev.Channel) {
 		logger.DebugCF("slack", "Message rejected by allowlist", map[string]any{
 			"user_id": ev.User,
 		})
@@ -403,11 +462,11 @@ func (c *SlackChannel) handleAppMention(ev *slackevents.AppMentionEvent) {
 		return
 	}
 
-	if !c.IsAllowedSender(bus.SenderInfo{
+	if !c.IsAllowedInChat(bus.SenderInfo{
 		Platform:    "slack",
 		PlatformID:  ev.User,
 		CanonicalID: identity.BuildCanonicalID("slack", ev.User),
-	}) {
+	}, ev.Channel) {
 		logger.DebugCF("slack", "Mention rejected by allowlist", map[string]any{
 			"user_id": ev.User,
 		})
@@ -482,12 +541,16 @@ func (c *SlackChannel) handleSlashCommand(event socketmode.Event) {
 		c.socketClient.Ack(*event.Request)
 	}
 
+	c.dispatch(cmd.ChannelID, func() { c.handleSlashCommandData(cmd) })
+}
+
+func (c *SlackChannel) handleSlashCommandData(cmd slack.SlashCommand) {
 	cmdSender := bus.SenderInfo{
 		Platform:    "slack",
 		PlatformID:  cmd.UserID,
 		CanonicalID: identity.BuildCanonicalID("slack", cmd.UserID),
 	}
-	if !c.IsAllowedSender(cmdSender) {
+	if !c.IsAllowedInChat(cmdSender, cmd.ChannelID) {
 		logger.DebugCF("slack", "Slash command rejected by allowlist", map[string]any{
 			"user_id": cmd.UserID,
 		})
@@ -1,7 +1,13 @@
 package slack
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -184,3 +190,134 @@ func TestSlackChannelIsAllowed(t *testing.T) {
 		}
 	})
 }
+
+func TestHandleEventsAPI_DispatchesMessageAsync(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	cfg := &config.SlackSettings{}
+	cfg.BotToken = *config.NewSecureString("xoxb-test")
+	cfg.AppToken = *config.NewSecureString("xapp-test")
+	ch, err := NewSlackChannel(&config.Channel{Type: "slack", Enabled: true}, cfg, msgBus)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ch.ctx, ch.cancel = context.WithCancel(t.Context())
+
+	ch.handleEventsAPI(socketmode.Event{
+		Type: socketmode.EventTypeEventsAPI,
+		Data: slackevents.EventsAPIEvent{
+			InnerEvent: slackevents.EventsAPIInnerEvent{
+				Data: &slackevents.MessageEvent{
+					User:      "U123",
+					Channel:   "D456",
+					Text:      "hello",
+					TimeStamp: "1700000000.000100",
+				},
+			},
+		},
+	})
+
+	select {
+	case inbound := <-msgBus.InboundChan():
+		if inbound.Content != "hello" {
+			t.Errorf("content = %q, want %q", inbound.Content, "hello")
+		}
+		if inbound.Context.ChatType != "direct" {
+			t.Errorf("chat type = %q, want %q", inbound.Context.ChatType, "direct")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for dispatched message")
+	}
+
+	if err := ch.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
+
+func TestHandleEventsAPI_KeepsOrderWithinChannel(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	cfg := &config.SlackSettings{}
+	cfg.BotToken = *config.NewSecureString("xoxb-test")
+	cfg.AppToken = *config.NewSecureString("xapp-test")
+	ch, err := NewSlackChannel(&config.Channel{Type: "slack", Enabled: true}, cfg, msgBus)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ch.ctx, ch.cancel = context.WithCancel(t.Context())
+
+	const n = 20
+	for i := range n {
+		ch.handleEventsAPI(socketmode.Event{
+			Type: socketmode.EventTypeEventsAPI,
+			Data: slackevents.EventsAPIEvent{
+				InnerEvent: slackevents.EventsAPIInnerEvent{
+					Data: &slackevents.MessageEvent{
+						User:      "U123",
+						Channel:   "D456",
+						Text:      fmt.Sprintf("msg %d", i),
+						TimeStamp: fmt.Sprintf("1700000000.%06d", i),
+					},
+				},
+			},
+		})
+	}
+
+	for i := range n {
+		select {
+		case inbound := <-msgBus.InboundChan():
+			if want := fmt.Sprintf("msg %d", i); inbound.Content != want {
+				t.Fatalf("message %d: content = %q, want %q", i, inbound.Content, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for message %d", i)
+		}
+	}
+
+	if err := ch.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ch.queuesMu.Lock()
+		left := len(ch.queues)
+		ch.queuesMu.Unlock()
+		if left == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected drained queues to be dropped, %d left", left)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStop_BoundedByContext(t *testing.T) {
+	cfg := &config.SlackSettings{}
+	cfg.BotToken = *config.NewSecureString("xoxb-test")
+	cfg.AppToken = *config.NewSecureString("xapp-test")
+	ch, err := NewSlackChannel(&config.Channel{Type: "slack", Enabled: true}, cfg, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ch.ctx, ch.cancel = context.WithCancel(t.Context())
+
+	// A handler stuck on a call that ignores cancellation.
+	release := make(chan struct{})
+	defer close(release)
+	ch.dispatch("C1", func() { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- ch.Stop(ctx) }()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("Stop() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop() did not return after its context expired")
+	}
+	if ch.IsRunning() {
+		t.Fatal("channel still running after Stop()")
+	}
+}
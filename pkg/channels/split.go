@@ -5,6 +5,8 @@ import (
 )
 
 // SplitMessage splits long messages into chunks, preserving code block integrity.
+// Split points are chosen in order of preference: a paragraph break (blank line)
+// in the second half of the chunk, then a line break, then a space.
 // The maxLen parameter is measured in runes (Unicode characters), not bytes.
 // The function reserves a buffer (10% of maxLen, min 50) to leave room for closing code blocks,
 // but may extend to maxLen when needed.
@@ -42,7 +44,10 @@ func SplitMessage(content string, maxLen int) []string {
 		end := start + effectiveLimit
 
 		// Find natural split point within the effective limit
-		msgEnd := findLastNewlineInRange(runes, start, end, 200)
+		msgEnd := findLastParagraphBreakInRange(runes, start, end, effectiveLimit/2)
+		if msgEnd <= start {
+			msgEnd = findLastNewlineInRange(runes, start, end, 200)
+		}
 		if msgEnd <= start {
 			msgEnd = findLastSpaceInRange(runes, start, end, 100)
 		}
@@ -195,6 +200,19 @@ func findLastNewlineInRange(runes []rune, start, end, searchWindow int) int {
 	return start - 1
 }
 
+// findLastParagraphBreakInRange finds the last blank line ("\n\n") within the last
+// searchWindow runes of the range runes[start:end]. Returns the absolute index of
+// the first newline of the break or start-1 (indicating not found).
+func findLastParagraphBreakInRange(runes []rune, start, end, searchWindow int) int {
+	searchStart := max(end-searchWindow, start)
+	for i := end - 2; i >= searchStart; i-- {
+		if runes[i] == '\n' && runes[i+1] == '\n' {
+			return i
+		}
+	}
+	return start - 1
+}
+
 // findLastSpaceInRange finds the last space/tab within the last searchWindow runes
 // of the range runes[start:end]. Returns the absolute index or start-1 (indicating not found).
 func findLastSpaceInRange(runes []rune, start, end, searchWindow int) int {
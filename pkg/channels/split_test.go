@@ -205,6 +205,52 @@ func TestFindLastSpaceInRange(t *testing.T) {
 	}
 }
 
+func TestFindLastParagraphBreakInRange(t *testing.T) {
+	runes := []rune("ab\n\ncd\nef\n\ngh")
+	// Paragraph breaks start at indices 2 and 9; index 6 is a lone newline.
+
+	tests := []struct {
+		name         string
+		start, end   int
+		searchWindow int
+		want         int
+	}{
+		{"finds last paragraph break", 0, 13, 200, 9},
+		{"single newline is not a break", 4, 9, 200, 3},
+		{"break outside window", 0, 9, 3, -1},
+		{"break at range end needs both newlines", 0, 10, 200, 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := findLastParagraphBreakInRange(runes, tc.start, tc.end, tc.searchWindow)
+			if got != tc.want {
+				t.Errorf("findLastParagraphBreakInRange(runes, %d, %d, %d) = %d, want %d",
+					tc.start, tc.end, tc.searchWindow, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitMessage_PrefersParagraphBreaks(t *testing.T) {
+	first := strings.Repeat("alpha beta gamma. ", 20) // 360 runes
+	second := strings.Repeat("line of text\n", 15)    // 195 runes
+	content := strings.TrimSpace(first) + "\n\n" + second
+
+	chunks := SplitMessage(content, 600)
+	if len(chunks) != 1 {
+		t.Fatalf("expected content under the limit to stay whole, got %d chunks", len(chunks))
+	}
+
+	chunks = SplitMessage(content+strings.Repeat("more words here ", 20), 600)
+	if len(chunks) < 2 {
+		t.Fatalf("expected at least 2 chunks, got %d", len(chunks))
+	}
+	if chunks[0] != strings.TrimSpace(first) {
+		t.Errorf("expected first chunk to end at the paragraph break, got %q", chunks[0])
+	}
+}
+
 func TestFindNewlineFrom(t *testing.T) {
 	runes := []rune("hello\nworld\n")
 
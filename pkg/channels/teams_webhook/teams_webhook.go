@@ -150,7 +150,7 @@ func (c *TeamsWebhookChannel) Send(ctx context.Context, msg bus.OutboundMessage)
 	target, ok := c.config.Webhooks[targetName]
 	if !ok {
 		// Log warning and fall back to default target
-		logger.WarnCF("teams_webhook", "Unknown target, falling back to default", map[string]any{
+		logger.WarnCtx(ctx, "teams_webhook", "Unknown target, falling back to default", map[string]any{
 			"requested": msg.ChatID,
 			"using":     "default",
 		})
@@ -172,7 +172,7 @@ func (c *TeamsWebhookChannel) Send(ctx context.Context, msg bus.OutboundMessage)
 	// Send to Teams
 	if err := c.client.SendWithContext(ctx, target.WebhookURL.String(), teamsMsg); err != nil {
 		// Log without raw error to avoid leaking webhook URL (embedded in net/http errors)
-		logger.ErrorCF("teams_webhook", "Failed to send message to Teams webhook", map[string]any{
+		logger.ErrorCtx(ctx, "teams_webhook", "Failed to send message to Teams webhook", map[string]any{
 			"target": msg.ChatID,
 		})
 		// Classify error based on status code extracted from error message.
@@ -182,7 +182,7 @@ func (c *TeamsWebhookChannel) Send(ctx context.Context, msg bus.OutboundMessage)
 		return nil, fmt.Errorf("teams_webhook: send failed: %w", classifiedErr)
 	}
 
-	logger.DebugCF("teams_webhook", "Message sent successfully", map[string]any{
+	logger.DebugCtx(ctx, "teams_webhook", "Message sent successfully", map[string]any{
 		"target": msg.ChatID,
 	})
 
@@ -43,7 +43,7 @@ func markdownToTelegramHTML(text string) string {
 
 	for i, lnk := range links.links {
 		label := escapeHTML(lnk[0])
-		url := lnk[1]
+		url := escapeHTMLAttr(lnk[1])
 		text = strings.ReplaceAll(text, fmt.Sprintf("\x00LK%d\x00", i), fmt.Sprintf(`<a href="%s">%s</a>`, url, label))
 	}
 
@@ -139,3 +139,9 @@ func escapeHTML(text string) string {
 	text = strings.ReplaceAll(text, ">", "&gt;")
 	return text
 }
+
+// escapeHTMLAttr escapes text for use inside a double-quoted attribute, so a
+// URL containing quotes or angle brackets cannot produce invalid markup.
+func escapeHTMLAttr(text string) string {
+	return strings.ReplaceAll(escapeHTML(text), `"`, "&quot;")
+}
@@ -50,6 +50,11 @@ func Test_markdownToTelegramHTML(t *testing.T) {
 			input:    "[a & b](https://example.com)",
 			expected: `<a href="https://example.com">a &amp; b</a>`,
 		},
+		{
+			name:     "link URL with query and quote is escaped in href",
+			input:    `[search](https://example.com/?q="x"&page=2)`,
+			expected: `<a href="https://example.com/?q=&quot;x&quot;&amp;page=2">search</a>`,
+		},
 		{
 			name:     "HTML special chars in plain text are escaped",
 			input:    "a & b < c > d",
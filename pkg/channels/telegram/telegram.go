@@ -3,7 +3,10 @@ package telegram
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,9 +17,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
 	th "github.com/mymmrac/telego/telegohandler"
 	tu "github.com/mymmrac/telego/telegoutil"
 
@@ -43,6 +48,19 @@ var (
 	reInlineCode = regexp.MustCompile("`([^`]+)`")
 )
 
+// Update delivery modes, set with the channel's mode setting.
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+
+	defaultWebhookPath = "/webhook/telegram"
+	// Telegram sends one update per request; 1 MB is well above any update.
+	maxWebhookBodySize = 1 << 20
+	// webhookDedupSize is how many recent update IDs are remembered to drop
+	// Telegram's redeliveries of webhook updates.
+	webhookDedupSize = 1024
+)
+
 type TelegramChannel struct {
 	*channels.BaseChannel
 	bot     *telego.Bot
@@ -53,6 +71,17 @@ type TelegramChannel struct {
 	cancel  context.CancelFunc
 	tgCfg   *config.TelegramSettings
 
+	// webhook receives the bodies of webhook requests while the channel
+	// runs in webhook mode.
+	webhook atomic.Pointer[telego.WebhookHandler]
+	// webhookSecret is the secret token registered with setWebhook: the
+	// configured webhook_secret, or a random one for this run.
+	webhookSecret string
+
+	updatesMu     sync.Mutex
+	recentUpdates map[int]struct{}
+	updateOrder   []int
+
 	registerFunc     func(context.Context, []commands.Definition) error
 	commandRegCancel context.CancelFunc
 }
@@ -89,6 +118,16 @@ func NewTelegramChannel(
 	}
 	opts = append(opts, telego.WithLogger(logger.NewLogger("telego")))
 
+	switch telegramCfg.Mode {
+	case "", ModePolling:
+	case ModeWebhook:
+		if strings.TrimSpace(telegramCfg.WebhookURL) == "" {
+			return nil, fmt.Errorf("telegram webhook mode requires webhook_url")
+		}
+	default:
+		return nil, fmt.Errorf("invalid telegram mode %q: want %q or %q", telegramCfg.Mode, ModePolling, ModeWebhook)
+	}
+
 	bot, err := telego.NewBot(telegramCfg.Token.String(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create telegram bot: %w", err)
@@ -114,16 +153,23 @@ func NewTelegramChannel(
 }
 
 func (c *TelegramChannel) Start(ctx context.Context) error {
-	logger.InfoC("telegram", "Starting Telegram bot (polling mode)...")
+	mode := c.mode()
+	logger.InfoCF("telegram", "Starting Telegram bot...", map[string]any{"mode": mode})
 
 	c.ctx, c.cancel = context.WithCancel(ctx)
 
-	updates, err := c.bot.UpdatesViaLongPolling(c.ctx, &telego.GetUpdatesParams{
-		Timeout: 30,
-	})
+	// Both modes feed the same update channel, so messages are handled by
+	// the same code however they were delivered.
+	var updates <-chan telego.Update
+	var err error
+	if mode == ModeWebhook {
+		updates, err = c.updatesViaWebhook(c.ctx)
+	} else {
+		updates, err = c.updatesViaPolling(c.ctx)
+	}
 	if err != nil {
 		c.cancel()
-		return fmt.Errorf("failed to start long polling: %w", err)
+		return err
 	}
 
 	bh, err := th.NewBotHandler(c.bot, updates)
@@ -155,6 +201,150 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 	return nil
 }
 
+func (c *TelegramChannel) mode() string {
+	if c.tgCfg != nil && c.tgCfg.Mode == ModeWebhook {
+		return ModeWebhook
+	}
+	return ModePolling
+}
+
+func (c *TelegramChannel) updatesViaPolling(ctx context.Context) (<-chan telego.Update, error) {
+	// getUpdates is refused while a webhook is registered, for example one
+	// left over from running in webhook mode.
+	if err := c.bot.DeleteWebhook(ctx, &telego.DeleteWebhookParams{}); err != nil {
+		logger.WarnCF("telegram", "Failed to delete webhook before polling", map[string]any{
+			"error": err.Error(),
+		})
+	}
+	updates, err := c.bot.UpdatesViaLongPolling(ctx, &telego.GetUpdatesParams{
+		Timeout: 30,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start long polling: %w", err)
+	}
+	return updates, nil
+}
+
+func (c *TelegramChannel) updatesViaWebhook(ctx context.Context) (<-chan telego.Update, error) {
+	// The webhook path is public, so unauthenticated updates must never be
+	// accepted: without a configured secret, register a random one.
+	c.webhookSecret = c.tgCfg.WebhookSecret.String()
+	if c.webhookSecret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		c.webhookSecret = hex.EncodeToString(buf)
+	}
+	updates, err := c.bot.UpdatesViaWebhook(ctx,
+		func(handler telego.WebhookHandler) error {
+			c.webhook.Store(&handler)
+			return nil
+		},
+		telego.WithWebhookSet(ctx, &telego.SetWebhookParams{
+			URL:         c.tgCfg.WebhookURL,
+			SecretToken: c.webhookSecret,
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start webhook: %w", err)
+	}
+	logger.InfoCF("telegram", "Telegram webhook registered", map[string]any{
+		"url":  c.tgCfg.WebhookURL,
+		"path": c.WebhookPath(),
+	})
+	return updates, nil
+}
+
+// WebhookPath implements channels.WebhookHandler. It is empty in polling
+// mode, so no handler is mounted.
+func (c *TelegramChannel) WebhookPath() string {
+	if c.mode() != ModeWebhook {
+		return ""
+	}
+	if c.tgCfg.WebhookPath != "" {
+		return c.tgCfg.WebhookPath
+	}
+	return defaultWebhookPath
+}
+
+// ServeHTTP receives updates Telegram posts to the webhook.
+func (c *TelegramChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	handler := c.webhook.Load()
+	if handler == nil || !c.IsRunning() {
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	// webhookSecret is set before the handler is stored, so it is visible
+	// here and never empty.
+	if subtle.ConstantTimeCompare(
+		[]byte(r.Header.Get("X-Telegram-Bot-Api-Secret-Token")), []byte(c.webhookSecret)) != 1 {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize+1))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxWebhookBodySize {
+		http.Error(w, "Request entity too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	// Telegram redelivers an update when it gets no timely answer; handle
+	// each update ID once.
+	var head struct {
+		UpdateID *int `json:"update_id"`
+	}
+	_ = json.Unmarshal(body, &head)
+	if head.UpdateID != nil && !c.markUpdate(*head.UpdateID) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	// The update is handled after this request returns, so it must not
+	// inherit the request's context.
+	if err := (*handler)(c.ctx, body); err != nil {
+		if head.UpdateID != nil {
+			c.unmarkUpdate(*head.UpdateID)
+		}
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// markUpdate records a webhook update ID and reports whether it is new.
+func (c *TelegramChannel) markUpdate(id int) bool {
+	c.updatesMu.Lock()
+	defer c.updatesMu.Unlock()
+	if _, seen := c.recentUpdates[id]; seen {
+		return false
+	}
+	if c.recentUpdates == nil {
+		c.recentUpdates = make(map[int]struct{}, webhookDedupSize)
+	}
+	if len(c.updateOrder) >= webhookDedupSize {
+		delete(c.recentUpdates, c.updateOrder[0])
+		c.updateOrder = c.updateOrder[1:]
+	}
+	c.recentUpdates[id] = struct{}{}
+	c.updateOrder = append(c.updateOrder, id)
+	return true
+}
+
+// unmarkUpdate forgets an update that failed to parse, so a corrected
+// redelivery is not dropped.
+func (c *TelegramChannel) unmarkUpdate(id int) {
+	c.updatesMu.Lock()
+	defer c.updatesMu.Unlock()
+	delete(c.recentUpdates, id)
+}
+
 func (c *TelegramChannel) Stop(ctx context.Context) error {
 	logger.InfoC("telegram", "Stopping Telegram bot...")
 	c.SetRunning(false)
@@ -164,7 +354,7 @@ func (c *TelegramChannel) Stop(ctx context.Context) error {
 		_ = c.bh.StopWithContext(ctx)
 	}
 
-	// Cancel our context (stops long polling)
+	// Cancel our context (stops long polling or the webhook)
 	if c.cancel != nil {
 		c.cancel()
 	}
@@ -306,19 +496,49 @@ func (c *TelegramChannel) sendChunk(
 
 	pMsg, err := c.bot.SendMessage(ctx, tgMsg)
 	if err != nil {
+		// Rate limits and server errors are not about the markup; resending
+		// as plain text would only fail the same way. Let the manager retry.
+		if code := telegramErrorCode(err); code == http.StatusTooManyRequests || code >= 500 {
+			return "", classifyTelegramError(err)
+		}
 		logParseFailed(err, params.useMarkdownV2)
 
 		tgMsg.Text = params.mdFallback
 		tgMsg.ParseMode = ""
 		pMsg, err = c.bot.SendMessage(ctx, tgMsg)
 		if err != nil {
-			return "", fmt.Errorf("telegram send: %w", channels.ErrTemporary)
+			return "", classifyTelegramError(err)
 		}
 	}
 
 	return strconv.Itoa(pMsg.MessageID), nil
 }
 
+// telegramErrorCode returns the Bot API error code in err, or 0 when err
+// did not come from the API (a network failure, for example).
+func telegramErrorCode(err error) int {
+	var apiErr *ta.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode
+	}
+	return 0
+}
+
+// classifyTelegramError maps a failed Bot API call onto the channel send
+// errors. A 429 carries Telegram's retry_after so the manager waits as long
+// as asked; errors that never reached the API are treated as temporary.
+func classifyTelegramError(err error) error {
+	var apiErr *ta.Error
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("telegram send: %w", channels.ClassifyNetError(err))
+	}
+	classified := fmt.Errorf("telegram send: %w", channels.ClassifySendError(apiErr.ErrorCode, err))
+	if apiErr.Parameters != nil && apiErr.Parameters.RetryAfter > 0 {
+		return channels.WithRetryAfter(classified, time.Duration(apiErr.Parameters.RetryAfter)*time.Second)
+	}
+	return classified
+}
+
 // maxTypingDuration limits how long the typing indicator can run.
 // Prevents endless typing when the LLM fails/hangs and preSend never invokes cancel.
 // Matches channels.Manager's typingStopTTL (5 min) so behavior is consistent.
@@ -405,7 +625,8 @@ func (c *TelegramChannel) EditMessage(ctx context.Context, chatID string, messag
 		}
 
 		if isPostConnectError(err) {
-			logger.WarnCF(
+			logger.WarnCtx(
+				ctx,
 				"telegram",
 				"EditMessage likely landed but result is unknown; swallowing error to prevent duplicate",
 				map[string]any{
@@ -483,7 +704,7 @@ func (c *TelegramChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMe
 	for _, part := range msg.Parts {
 		localPath, err := store.Resolve(part.Ref)
 		if err != nil {
-			logger.ErrorCF("telegram", "Failed to resolve media ref", map[string]any{
+			logger.ErrorCtx(ctx, "telegram", "Failed to resolve media ref", map[string]any{
 				"ref":   part.Ref,
 				"error": err.Error(),
 			})
@@ -492,7 +713,7 @@ func (c *TelegramChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMe
 
 		file, err := os.Open(localPath)
 		if err != nil {
-			logger.ErrorCF("telegram", "Failed to open media file", map[string]any{
+			logger.ErrorCtx(ctx, "telegram", "Failed to open media file", map[string]any{
 				"path":  localPath,
 				"error": err.Error(),
 			})
@@ -568,7 +789,7 @@ func (c *TelegramChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMe
 		file.Close()
 
 		if err != nil {
-			logger.ErrorCF("telegram", "Failed to send media", map[string]any{
+			logger.ErrorCtx(ctx, "telegram", "Failed to send media", map[string]any{
 				"type":  part.Type,
 				"error": err.Error(),
 			})
@@ -596,10 +817,11 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		CanonicalID: identity.BuildCanonicalID("telegram", platformID),
 		Username:    user.Username,
 		DisplayName: user.FirstName,
+		Language:    user.LanguageCode,
 	}
 
 	// check allowlist to avoid downloading attachments for rejected users
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedInChat(sender, fmt.Sprintf("%d", message.Chat.ID)) {
 		logger.DebugCF("telegram", "Message rejected by allowlist", map[string]any{
 			"user_id": platformID,
 		})
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mymmrac/telego"
 	ta "github.com/mymmrac/telego/telegoapi"
@@ -331,6 +332,45 @@ func TestSend_HTMLFallback_BothFail(t *testing.T) {
 	assert.Equal(t, 2, len(caller.calls), "should have HTML attempt + plain text attempt")
 }
 
+func TestSend_RateLimitSkipsFallbackAndCarriesRetryAfter(t *testing.T) {
+	caller := &stubCaller{
+		callFn: func(ctx context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+			return &ta.Response{Ok: false, Error: &ta.Error{
+				ErrorCode:   429,
+				Description: "Too Many Requests: retry after 3",
+				Parameters:  &ta.ResponseParameters{RetryAfter: 3},
+			}}, nil
+		},
+	}
+	ch := newTestChannel(t, caller)
+
+	_, err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "12345", Content: "Hello"})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, channels.ErrRateLimit)
+	after, ok := channels.RetryAfter(err)
+	assert.True(t, ok, "error should carry retry_after")
+	assert.Equal(t, 3*time.Second, after)
+	assert.Equal(t, 1, len(caller.calls), "a rate limit should not trigger the plain text fallback")
+}
+
+func TestSend_PlainTextBadRequestIsPermanent(t *testing.T) {
+	caller := &stubCaller{
+		callFn: func(ctx context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+			return &ta.Response{Ok: false, Error: &ta.Error{
+				ErrorCode:   400,
+				Description: "Bad Request: chat not found",
+			}}, nil
+		},
+	}
+	ch := newTestChannel(t, caller)
+
+	_, err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "12345", Content: "Hello"})
+
+	assert.ErrorIs(t, err, channels.ErrSendFailed)
+	assert.Equal(t, 2, len(caller.calls), "should have HTML attempt + plain text attempt")
+}
+
 func TestSend_LongMessage_HTMLFallback_StopsOnError(t *testing.T) {
 	// With a long message that gets split into 2 chunks, if both HTML and
 	// plain text fail on the first chunk, Send should return early.
@@ -0,0 +1,168 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewTelegramChannel_ValidatesMode(t *testing.T) {
+	bc := &config.Channel{Type: config.ChannelTelegram, Enabled: true}
+	tests := []struct {
+		name    string
+		cfg     config.TelegramSettings
+		wantErr string
+	}{
+		{"default", config.TelegramSettings{}, ""},
+		{"polling", config.TelegramSettings{Mode: ModePolling}, ""},
+		{"webhook", config.TelegramSettings{Mode: ModeWebhook, WebhookURL: "https://bot.example.com/hook"}, ""},
+		{"webhook without url", config.TelegramSettings{Mode: ModeWebhook}, "webhook_url"},
+		{"unknown", config.TelegramSettings{Mode: "push"}, "invalid telegram mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Token = *config.NewSecureString(testToken)
+			_, err := NewTelegramChannel(bc, &tt.cfg, bus.NewMessageBus())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookPath_EmptyInPollingMode(t *testing.T) {
+	ch := &TelegramChannel{tgCfg: &config.TelegramSettings{}}
+	assert.Empty(t, ch.WebhookPath())
+
+	ch.tgCfg = &config.TelegramSettings{Mode: ModeWebhook, WebhookURL: "https://bot.example.com/hook"}
+	assert.Equal(t, "/webhook/telegram", ch.WebhookPath())
+	ch.tgCfg.WebhookPath = "/tg"
+	assert.Equal(t, "/tg", ch.WebhookPath())
+}
+
+func TestWebhookMode_RegistersAndDeliversUpdates(t *testing.T) {
+	var mu sync.Mutex
+	var setWebhook string
+	caller := &stubCaller{
+		callFn: func(_ context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+			if strings.HasSuffix(url, "/setWebhook") {
+				mu.Lock()
+				setWebhook = string(data.BodyRaw)
+				mu.Unlock()
+			}
+			return &ta.Response{Ok: true, Result: []byte("true")}, nil
+		},
+	}
+	bot, err := telego.NewBot(testToken, telego.WithAPICaller(caller), telego.WithDiscardLogger())
+	require.NoError(t, err)
+
+	messageBus := bus.NewMessageBus()
+	ch := &TelegramChannel{
+		BaseChannel: channels.NewBaseChannel("telegram", nil, messageBus, nil),
+		bot:         bot,
+		bc:          &config.Channel{Type: config.ChannelTelegram, Enabled: true},
+		chatIDs:     make(map[string]int64),
+		tgCfg: &config.TelegramSettings{
+			Mode:          ModeWebhook,
+			WebhookURL:    "https://bot.example.com/webhook/telegram",
+			WebhookSecret: *config.NewSecureString("s3cret"),
+		},
+		registerFunc: func(context.Context, []commands.Definition) error { return nil },
+	}
+	require.NoError(t, ch.Start(context.Background()))
+	defer ch.Stop(context.Background())
+
+	mu.Lock()
+	assert.Contains(t, setWebhook, `"url":"https://bot.example.com/webhook/telegram"`)
+	assert.Contains(t, setWebhook, `"secret_token":"s3cret"`)
+	mu.Unlock()
+
+	update := `{"update_id":1,"message":{"message_id":7,"date":1,"text":"hello",` +
+		`"chat":{"id":123,"type":"private"},"from":{"id":42,"is_bot":false,"first_name":"Alice"}}}`
+	post := func(secret string) int {
+		req := httptest.NewRequest(http.MethodPost, ch.WebhookPath(), strings.NewReader(update))
+		req.Header.Set("X-Telegram-Bot-Api-Secret-Token", secret)
+		rec := httptest.NewRecorder()
+		ch.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, post("wrong"))
+	require.Equal(t, http.StatusOK, post("s3cret"))
+
+	select {
+	case inbound := <-messageBus.InboundChan():
+		assert.Equal(t, "telegram", inbound.Channel)
+		assert.Equal(t, "hello", inbound.Content)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook update was not forwarded to the bus")
+	}
+
+	// A redelivery of the same update_id is acknowledged but not handled again.
+	require.Equal(t, http.StatusOK, post("s3cret"))
+	select {
+	case inbound := <-messageBus.InboundChan():
+		t.Fatalf("redelivered update was handled again: %q", inbound.Content)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWebhookMode_GeneratesSecretWhenUnset(t *testing.T) {
+	var mu sync.Mutex
+	var setWebhook string
+	caller := &stubCaller{
+		callFn: func(_ context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+			if strings.HasSuffix(url, "/setWebhook") {
+				mu.Lock()
+				setWebhook = string(data.BodyRaw)
+				mu.Unlock()
+			}
+			return &ta.Response{Ok: true, Result: []byte("true")}, nil
+		},
+	}
+	bot, err := telego.NewBot(testToken, telego.WithAPICaller(caller), telego.WithDiscardLogger())
+	require.NoError(t, err)
+
+	ch := &TelegramChannel{
+		BaseChannel: channels.NewBaseChannel("telegram", nil, bus.NewMessageBus(), nil),
+		bot:         bot,
+		bc:          &config.Channel{Type: config.ChannelTelegram, Enabled: true},
+		chatIDs:     make(map[string]int64),
+		tgCfg: &config.TelegramSettings{
+			Mode:       ModeWebhook,
+			WebhookURL: "https://bot.example.com/webhook/telegram",
+		},
+		registerFunc: func(context.Context, []commands.Definition) error { return nil },
+	}
+	require.NoError(t, ch.Start(context.Background()))
+	defer ch.Stop(context.Background())
+
+	require.NotEmpty(t, ch.webhookSecret)
+	mu.Lock()
+	assert.Contains(t, setWebhook, `"secret_token":"`+ch.webhookSecret+`"`)
+	mu.Unlock()
+
+	// Without the secret header a forged update is refused.
+	req := httptest.NewRequest(http.MethodPost, ch.WebhookPath(),
+		strings.NewReader(`{"update_id":1,"message":{"message_id":1,"date":1,"text":"hi",`+
+			`"chat":{"id":1,"type":"private"},"from":{"id":1,"is_bot":false,"first_name":"Mallory"}}}`))
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -230,7 +230,7 @@ func (c *VKChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string
 
 		resp, err := c.vk.MessagesSend(b.Params)
 		if err != nil {
-			logger.ErrorCF("vk", "Failed to send message", map[string]any{
+			logger.ErrorCtx(ctx, "vk", "Failed to send message", map[string]any{
 				"error":   err.Error(),
 				"peer_id": peerID,
 			})
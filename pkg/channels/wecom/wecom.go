@@ -249,7 +249,7 @@ func (c *WeComChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessa
 
 			uploaded, uploadErr := c.uploadOutboundMedia(ctx, localPath, filename, contentType, part)
 			if uploadErr != nil {
-				logger.WarnCF("wecom", "Falling back to placeholder after media upload failure", map[string]any{
+				logger.WarnCtx(ctx, "wecom", "Falling back to placeholder after media upload failure", map[string]any{
 					"chat_id":      chatID,
 					"ref":          part.Ref,
 					"filename":     filename,
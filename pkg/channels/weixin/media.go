@@ -1045,7 +1045,7 @@ func (c *WeixinChannel) StartTyping(ctx context.Context, chatID string) (func(),
 		if ticket == "" {
 			return func() {}, err
 		}
-		logger.DebugCF("weixin", "GetConfig refresh failed; using cached typing ticket", map[string]any{
+		logger.DebugCtx(ctx, "weixin", "GetConfig refresh failed; using cached typing ticket", map[string]any{
 			"chat_id": chatID,
 			"error":   err.Error(),
 		})
@@ -1062,7 +1062,7 @@ func (c *WeixinChannel) StartTyping(ctx context.Context, chatID string) (func(),
 			stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer stopCancel()
 			if err := c.sendTypingStatus(stopCtx, chatID, ticket, TypingStatusCancel); err != nil {
-				logger.DebugCF("weixin", "Failed to cancel typing indicator", map[string]any{
+				logger.DebugCtx(ctx, "weixin", "Failed to cancel typing indicator", map[string]any{
 					"chat_id": chatID,
 					"error":   err.Error(),
 				})
@@ -1084,7 +1084,7 @@ func (c *WeixinChannel) StartTyping(ctx context.Context, chatID string) (func(),
 				return
 			case <-ticker.C:
 				if err := c.sendTypingStatus(typingCtx, chatID, ticket, TypingStatusTyping); err != nil {
-					logger.DebugCF("weixin", "Failed to refresh typing indicator", map[string]any{
+					logger.DebugCtx(ctx, "weixin", "Failed to refresh typing indicator", map[string]any{
 						"chat_id": chatID,
 						"error":   err.Error(),
 					})
@@ -1120,7 +1120,7 @@ func (c *WeixinChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 	for _, part := range msg.Parts {
 		localPath, filename, contentType, cleanup, err := c.resolveOutboundPart(ctx, part)
 		if err != nil {
-			logger.ErrorCF("weixin", "Failed to resolve outbound media", map[string]any{
+			logger.ErrorCtx(ctx, "weixin", "Failed to resolve outbound media", map[string]any{
 				"chat_id": msg.ChatID,
 				"ref":     part.Ref,
 				"error":   err.Error(),
@@ -1141,7 +1141,7 @@ func (c *WeixinChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 			err = c.sendUploadedMedia(ctx, msg.ChatID, contextToken, part.Caption, kind, uploaded)
 		}()
 		if err != nil {
-			logger.ErrorCF("weixin", "Failed to send outbound media", map[string]any{
+			logger.ErrorCtx(ctx, "weixin", "Failed to send outbound media", map[string]any{
 				"chat_id": msg.ChatID,
 				"ref":     part.Ref,
 				"error":   err.Error(),
@@ -418,14 +418,14 @@ func (c *WeixinChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]st
 	// If we don't have a context token for this user, we cannot send a valid reply.
 	// Treat this as a non-temporary error so the manager doesn't keep retrying.
 	if contextToken == "" {
-		logger.ErrorCF("weixin", "Missing context token, cannot send message", map[string]any{
+		logger.ErrorCtx(ctx, "weixin", "Missing context token, cannot send message", map[string]any{
 			"to_user_id": toUserID,
 		})
 		return nil, fmt.Errorf("weixin send: %w: missing context token for chat %s", channels.ErrSendFailed, toUserID)
 	}
 
 	if err := c.sendTextMessage(ctx, toUserID, contextToken, msg.Content); err != nil {
-		logger.ErrorCF("weixin", "Failed to send message", map[string]any{
+		logger.ErrorCtx(ctx, "weixin", "Failed to send message", map[string]any{
 			"to_user_id": toUserID,
 			"error":      err.Error(),
 		})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -17,6 +18,11 @@ import (
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
+const (
+	reconnectInitialBackoff = 1 * time.Second
+	reconnectMaxBackoff     = 30 * time.Second
+)
+
 type WhatsAppChannel struct {
 	*channels.BaseChannel
 	conn      *websocket.Conn
@@ -57,16 +63,30 @@ func (c *WhatsAppChannel) Start(ctx context.Context) error {
 
 	c.ctx, c.cancel = context.WithCancel(ctx)
 
-	dialer := websocket.DefaultDialer
+	if err := c.connect(); err != nil {
+		c.cancel()
+		return fmt.Errorf("failed to connect to WhatsApp bridge: %w", err)
+	}
+
+	c.SetRunning(true)
+	logger.InfoC("whatsapp", "WhatsApp channel connected")
+
+	go c.listen()
+
+	return nil
+}
+
+// connect dials the bridge and installs the new connection.
+func (c *WhatsAppChannel) connect() error {
+	dialer := *websocket.DefaultDialer
 	dialer.HandshakeTimeout = 10 * time.Second
 
-	conn, resp, err := dialer.Dial(c.url, nil)
+	conn, resp, err := dialer.DialContext(c.ctx, c.url, nil)
 	if resp != nil {
 		resp.Body.Close()
 	}
 	if err != nil {
-		c.cancel()
-		return fmt.Errorf("failed to connect to WhatsApp bridge: %w", err)
+		return err
 	}
 
 	c.mu.Lock()
@@ -74,12 +94,76 @@ func (c *WhatsAppChannel) Start(ctx context.Context) error {
 	c.connected = true
 	c.mu.Unlock()
 
-	c.SetRunning(true)
-	logger.InfoC("whatsapp", "WhatsApp channel connected")
+	return nil
+}
 
-	go c.listen()
+// dropConn closes conn if it is still the active connection.
+func (c *WhatsAppChannel) dropConn(conn *websocket.Conn, reason error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return nil
+	if c.conn == conn {
+		c.closeConnLocked(reason)
+	}
+}
+
+// closeConnLocked closes the active connection; the listen loop notices and
+// reconnects. c.mu must be held.
+func (c *WhatsAppChannel) closeConnLocked(reason error) {
+	_ = c.conn.Close()
+	c.conn = nil
+	c.connected = false
+	logger.WarnCF("whatsapp", "WhatsApp bridge disconnected", map[string]any{
+		"bridge_url": c.url,
+		"error":      reason.Error(),
+	})
+}
+
+// reconnectDelay spreads retries over [0.5, 1.0) of backoff so several
+// instances behind one bridge do not re-dial in lockstep.
+func reconnectDelay(backoff time.Duration) time.Duration {
+	return time.Duration(float64(backoff) * (0.5 + rand.Float64()*0.5))
+}
+
+// reconnect re-dials the bridge with exponential backoff until it succeeds
+// or the channel context is canceled. It reports whether a connection was
+// re-established.
+func (c *WhatsAppChannel) reconnect() bool {
+	backoff := reconnectInitialBackoff
+	delay := reconnectDelay(backoff)
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-c.ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+
+		logger.InfoCF("whatsapp", "Reconnecting to WhatsApp bridge", map[string]any{
+			"bridge_url": c.url,
+			"attempt":    attempt,
+		})
+		err := c.connect()
+		if err == nil {
+			logger.InfoCF("whatsapp", "WhatsApp bridge reconnected", map[string]any{
+				"attempts": attempt,
+				"downtime": time.Since(start).Round(time.Second).String(),
+			})
+			return true
+		}
+		if c.ctx.Err() != nil {
+			return false
+		}
+
+		backoff = min(backoff*2, reconnectMaxBackoff)
+		delay = reconnectDelay(backoff)
+		logger.WarnCF("whatsapp", "WhatsApp bridge reconnect failed", map[string]any{
+			"error":      err.Error(),
+			"attempt":    attempt,
+			"next_retry": delay.Round(time.Millisecond).String(),
+		})
+	}
 }
 
 func (c *WhatsAppChannel) Stop(ctx context.Context) error {
@@ -124,7 +208,7 @@ func (c *WhatsAppChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]
 	defer c.mu.Unlock()
 
 	if c.conn == nil {
-		return nil, fmt.Errorf("whatsapp connection not established: %w", channels.ErrTemporary)
+		return nil, fmt.Errorf("whatsapp bridge disconnected, reconnecting: %w", channels.ErrTemporary)
 	}
 
 	payload := map[string]any{
@@ -140,8 +224,10 @@ func (c *WhatsAppChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]
 
 	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		_ = c.conn.SetWriteDeadline(time.Time{})
-		return nil, fmt.Errorf("whatsapp send: %w", channels.ErrTemporary)
+		// A failed write leaves the socket unusable; drop it so the listen
+		// loop reconnects instead of waiting for a read to fail.
+		c.closeConnLocked(err)
+		return nil, fmt.Errorf("whatsapp send: %v: %w", err, channels.ErrTemporary)
 	}
 	_ = c.conn.SetWriteDeadline(time.Time{})
 
@@ -154,40 +240,43 @@ func (c *WhatsAppChannel) listen() {
 		case <-c.ctx.Done():
 			return
 		default:
-			c.mu.Lock()
-			conn := c.conn
-			c.mu.Unlock()
+		}
 
-			if conn == nil {
-				time.Sleep(1 * time.Second)
-				continue
-			}
+		c.mu.Lock()
+		conn := c.conn
+		c.mu.Unlock()
 
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				logger.ErrorCF("whatsapp", "WhatsApp read error", map[string]any{
-					"error": err.Error(),
-				})
-				time.Sleep(2 * time.Second)
-				continue
+		if conn == nil {
+			if !c.reconnect() {
+				return
 			}
+			continue
+		}
 
-			var msg map[string]any
-			if err := json.Unmarshal(message, &msg); err != nil {
-				logger.ErrorCF("whatsapp", "Failed to unmarshal WhatsApp message", map[string]any{
-					"error": err.Error(),
-				})
-				continue
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if c.ctx.Err() != nil {
+				return
 			}
+			c.dropConn(conn, err)
+			continue
+		}
 
-			msgType, ok := msg["type"].(string)
-			if !ok {
-				continue
-			}
+		var msg map[string]any
+		if err := json.Unmarshal(message, &msg); err != nil {
+			logger.ErrorCF("whatsapp", "Failed to unmarshal WhatsApp message", map[string]any{
+				"error": err.Error(),
+			})
+			continue
+		}
 
-			if msgType == "message" {
-				c.handleIncomingMessage(msg)
-			}
+		msgType, ok := msg["type"].(string)
+		if !ok {
+			continue
+		}
+
+		if msgType == "message" {
+			c.handleIncomingMessage(msg)
 		}
 	}
 }
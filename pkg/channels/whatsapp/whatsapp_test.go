@@ -0,0 +1,117 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestWhatsAppChannel_ReconnectsAfterBridgeDrop(t *testing.T) {
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		if connections.Add(1) == 1 {
+			// Simulate the bridge dropping the first connection.
+			return
+		}
+		_ = conn.WriteMessage(websocket.TextMessage,
+			[]byte(`{"type":"message","id":"m1","from":"user1","chat":"user1","content":"hello again"}`))
+		// Keep the connection open until the client goes away.
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	messageBus := bus.NewMessageBus()
+	ch, err := NewWhatsAppChannel(
+		&config.Channel{},
+		&config.WhatsAppSettings{BridgeURL: "ws" + strings.TrimPrefix(server.URL, "http")},
+		messageBus,
+	)
+	if err != nil {
+		t.Fatalf("NewWhatsAppChannel() error = %v", err)
+	}
+
+	if err := ch.Start(t.Context()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer ch.Stop(context.Background())
+
+	select {
+	case inbound := <-messageBus.InboundChan():
+		if inbound.Content != "hello again" {
+			t.Fatalf("content = %q, want %q", inbound.Content, "hello again")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for message after reconnect")
+	}
+
+	if got := connections.Load(); got < 2 {
+		t.Fatalf("bridge connections = %d, want at least 2", got)
+	}
+}
+
+func TestWhatsAppChannel_ReconnectStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := &WhatsAppChannel{url: "ws://127.0.0.1:1", ctx: ctx}
+
+	done := make(chan bool, 1)
+	go func() { done <- ch.reconnect() }()
+	cancel()
+
+	select {
+	case ok := <-done:
+		if ok {
+			t.Fatal("reconnect() = true after cancel, want false")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reconnect() did not return after context cancel")
+	}
+}
+
+func TestWhatsAppChannel_SendWhileDisconnectedIsTemporary(t *testing.T) {
+	ch, err := NewWhatsAppChannel(
+		&config.Channel{},
+		&config.WhatsAppSettings{BridgeURL: "ws://127.0.0.1:1"},
+		bus.NewMessageBus(),
+	)
+	if err != nil {
+		t.Fatalf("NewWhatsAppChannel() error = %v", err)
+	}
+	ch.SetRunning(true)
+
+	_, err = ch.Send(context.Background(), bus.OutboundMessage{ChatID: "user1", Content: "hi"})
+	if !errors.Is(err, channels.ErrTemporary) {
+		t.Fatalf("Send() error = %v, want ErrTemporary", err)
+	}
+}
+
+func TestReconnectDelay_Jitter(t *testing.T) {
+	for range 100 {
+		if d := reconnectDelay(reconnectMaxBackoff); d < reconnectMaxBackoff/2 || d >= reconnectMaxBackoff {
+			t.Fatalf("reconnectDelay(%s) = %s, want within [%s, %s)",
+				reconnectMaxBackoff, d, reconnectMaxBackoff/2, reconnectMaxBackoff)
+		}
+	}
+}
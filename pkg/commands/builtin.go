@@ -12,9 +12,12 @@ func BuiltinDefinitions() []Definition {
 		listCommand(),
 		useCommand(),
 		btwCommand(),
+		chatCommand(),
 		switchCommand(),
 		checkCommand(),
 		clearCommand(),
+		usageCommand(),
+		thinkCommand(),
 		subagentsCommand(),
 		reloadCommand(),
 	}
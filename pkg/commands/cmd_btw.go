@@ -3,6 +3,8 @@ package commands
 import (
 	"context"
 	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
 )
 
 func btwCommand() Definition {
@@ -11,15 +13,13 @@ func btwCommand() Definition {
 		Description: "Ask a side question without changing session history",
 		Usage:       "/btw <question>",
 		Handler: func(ctx context.Context, req Request, rt *Runtime) error {
-			const emptyAnswerMsg = "The model returned an empty response. This may indicate a provider error or token limit."
-
 			if rt == nil || rt.AskSideQuestion == nil {
-				return req.Reply(unavailableMsg)
+				return req.Reply(req.text(i18n.CommandUnavailable))
 			}
 
 			question := sideQuestionText(req.Text)
 			if question == "" {
-				return req.Reply("Usage: /btw <question>")
+				return req.Reply(req.textf(i18n.CommandUsage, "/btw <question>"))
 			}
 
 			answer, err := rt.AskSideQuestion(ctx, question)
@@ -27,7 +27,7 @@ func btwCommand() Definition {
 				return req.Reply(err.Error())
 			}
 			if strings.TrimSpace(answer) == "" {
-				return req.Reply(emptyAnswerMsg)
+				return req.Reply(req.text(i18n.BtwEmptyAnswer))
 			}
 
 			return req.Reply(answer)
@@ -0,0 +1,9 @@
+package commands
+
+func chatCommand() Definition {
+	return Definition{
+		Name:        "chat",
+		Description: "Send one message to the model without any tools",
+		Usage:       "/chat <message>",
+	}
+}
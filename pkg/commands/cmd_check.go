@@ -2,7 +2,8 @@ package commands
 
 import (
 	"context"
-	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
 )
 
 func checkCommand() Definition {
@@ -16,16 +17,16 @@ func checkCommand() Definition {
 				ArgsUsage:   "<name>",
 				Handler: func(_ context.Context, req Request, rt *Runtime) error {
 					if rt == nil || rt.SwitchChannel == nil {
-						return req.Reply(unavailableMsg)
+						return req.Reply(req.text(i18n.CommandUnavailable))
 					}
 					value := nthToken(req.Text, 2)
 					if value == "" {
-						return req.Reply("Usage: /check channel <name>")
+						return req.Reply(req.textf(i18n.CommandUsage, "/check channel <name>"))
 					}
 					if err := rt.SwitchChannel(value); err != nil {
 						return req.Reply(err.Error())
 					}
-					return req.Reply(fmt.Sprintf("Channel '%s' is available and enabled", value))
+					return req.Reply(req.textf(i18n.CheckChannelOK, value))
 				},
 			},
 		},
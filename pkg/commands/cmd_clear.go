@@ -1,20 +1,25 @@
 package commands
 
-import "context"
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
+)
 
 func clearCommand() Definition {
 	return Definition{
 		Name:        "clear",
 		Description: "Clear the chat history",
 		Usage:       "/clear",
+		Aliases:     []string{"reset"},
 		Handler: func(_ context.Context, req Request, rt *Runtime) error {
 			if rt == nil || rt.ClearHistory == nil {
-				return req.Reply(unavailableMsg)
+				return req.Reply(req.text(i18n.CommandUnavailable))
 			}
 			if err := rt.ClearHistory(); err != nil {
-				return req.Reply("Failed to clear chat history: " + err.Error())
+				return req.Reply(req.textf(i18n.ClearFailed, err))
 			}
-			return req.Reply("Chat history cleared!")
+			return req.Reply(req.text(i18n.ClearDone))
 		},
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
 )
 
 func helpCommand() Definition {
@@ -18,14 +20,16 @@ func helpCommand() Definition {
 			} else {
 				defs = BuiltinDefinitions()
 			}
-			return req.Reply(formatHelpMessage(defs))
+			return req.Reply(formatHelpMessage(req.Locale, defs))
 		},
 	}
 }
 
-func formatHelpMessage(defs []Definition) string {
+// formatHelpMessage lists defs one per line. Built-in commands use the
+// locale's description when its catalog has one.
+func formatHelpMessage(locale string, defs []Definition) string {
 	if len(defs) == 0 {
-		return "No commands available."
+		return i18n.Text(locale, i18n.HelpEmpty)
 	}
 
 	lines := make([]string, 0, len(defs))
@@ -35,8 +39,11 @@ func formatHelpMessage(defs []Definition) string {
 			usage = "/" + def.Name
 		}
 		desc := def.Description
+		if text, ok := i18n.Lookup(locale, i18n.CommandDescription(def.Name)); ok {
+			desc = text
+		}
 		if desc == "" {
-			desc = "No description"
+			desc = i18n.Text(locale, i18n.HelpNoDescription)
 		}
 		lines = append(lines, fmt.Sprintf("%s - %s", usage, desc))
 	}
@@ -2,8 +2,9 @@ package commands
 
 import (
 	"context"
-	"fmt"
 	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
 )
 
 func listCommand() Definition {
@@ -16,16 +17,13 @@ func listCommand() Definition {
 				Description: "Configured models",
 				Handler: func(_ context.Context, req Request, rt *Runtime) error {
 					if rt == nil || rt.GetModelInfo == nil {
-						return req.Reply(unavailableMsg)
+						return req.Reply(req.text(i18n.CommandUnavailable))
 					}
 					name, provider := rt.GetModelInfo()
 					if provider == "" {
-						provider = "configured default"
+						provider = req.text(i18n.ListDefaultProvider)
 					}
-					return req.Reply(fmt.Sprintf(
-						"Configured Model: %s\nProvider: %s\n\nTo change models, update config.json",
-						name, provider,
-					))
+					return req.Reply(req.textf(i18n.ListModels, name, provider))
 				},
 			},
 			{
@@ -33,13 +31,13 @@ func listCommand() Definition {
 				Description: "Enabled channels",
 				Handler: func(_ context.Context, req Request, rt *Runtime) error {
 					if rt == nil || rt.GetEnabledChannels == nil {
-						return req.Reply(unavailableMsg)
+						return req.Reply(req.text(i18n.CommandUnavailable))
 					}
 					enabled := rt.GetEnabledChannels()
 					if len(enabled) == 0 {
-						return req.Reply("No channels enabled")
+						return req.Reply(req.text(i18n.ListNoChannels))
 					}
-					return req.Reply(fmt.Sprintf("Enabled Channels:\n- %s", strings.Join(enabled, "\n- ")))
+					return req.Reply(req.textf(i18n.ListChannels, strings.Join(enabled, "\n- ")))
 				},
 			},
 			{
@@ -47,21 +45,32 @@ func listCommand() Definition {
 				Description: "Registered agents",
 				Handler:     agentsHandler(),
 			},
+			{
+				Name:        "tools",
+				Description: "Tools available to the agent",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ListToolNames == nil {
+						return req.Reply(req.text(i18n.CommandUnavailable))
+					}
+					names := rt.ListToolNames()
+					if len(names) == 0 {
+						return req.Reply(req.text(i18n.ListNoTools))
+					}
+					return req.Reply(req.textf(i18n.ListTools, strings.Join(names, "\n- ")))
+				},
+			},
 			{
 				Name:        "skills",
 				Description: "Installed skills",
 				Handler: func(_ context.Context, req Request, rt *Runtime) error {
 					if rt == nil || rt.ListSkillNames == nil {
-						return req.Reply(unavailableMsg)
+						return req.Reply(req.text(i18n.CommandUnavailable))
 					}
 					names := rt.ListSkillNames()
 					if len(names) == 0 {
-						return req.Reply("No installed skills")
+						return req.Reply(req.text(i18n.ListNoSkills))
 					}
-					return req.Reply(fmt.Sprintf(
-						"Installed Skills:\n- %s\n\nUse /use <skill> <message> to force one for a single request, or /use <skill> to apply it to your next message.",
-						strings.Join(names, "\n- "),
-					))
+					return req.Reply(req.textf(i18n.ListSkills, strings.Join(names, "\n- ")))
 				},
 			},
 		},
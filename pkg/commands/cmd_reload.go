@@ -1,6 +1,10 @@
 package commands
 
-import "context"
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
+)
 
 func reloadCommand() Definition {
 	return Definition{
@@ -9,12 +13,12 @@ func reloadCommand() Definition {
 		Usage:       "/reload",
 		Handler: func(_ context.Context, req Request, rt *Runtime) error {
 			if rt == nil || rt.ReloadConfig == nil {
-				return req.Reply(unavailableMsg)
+				return req.Reply(req.text(i18n.CommandUnavailable))
 			}
 			if err := rt.ReloadConfig(); err != nil {
-				return req.Reply("Failed to reload configuration: " + err.Error())
+				return req.Reply(req.textf(i18n.ReloadFailed, err))
 			}
-			return req.Reply("Config reload triggered!")
+			return req.Reply(req.text(i18n.ReloadDone))
 		},
 	}
 }
@@ -2,7 +2,8 @@ package commands
 
 import (
 	"context"
-	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
 )
 
 func showCommand() Definition {
@@ -15,17 +16,17 @@ func showCommand() Definition {
 				Description: "Current model and provider",
 				Handler: func(_ context.Context, req Request, rt *Runtime) error {
 					if rt == nil || rt.GetModelInfo == nil {
-						return req.Reply(unavailableMsg)
+						return req.Reply(req.text(i18n.CommandUnavailable))
 					}
 					name, provider := rt.GetModelInfo()
-					return req.Reply(fmt.Sprintf("Current Model: %s (Provider: %s)", name, provider))
+					return req.Reply(req.textf(i18n.ShowModel, name, provider))
 				},
 			},
 			{
 				Name:        "channel",
 				Description: "Current channel",
 				Handler: func(_ context.Context, req Request, _ *Runtime) error {
-					return req.Reply(fmt.Sprintf("Current Channel: %s", req.Channel))
+					return req.Reply(req.textf(i18n.ShowChannel, req.Channel))
 				},
 			},
 			{
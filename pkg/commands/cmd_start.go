@@ -1,14 +1,23 @@
 package commands
 
-import "context"
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
+)
 
 func startCommand() Definition {
 	return Definition{
 		Name:        "start",
 		Description: "Start the bot",
 		Usage:       "/start",
-		Handler: func(_ context.Context, req Request, _ *Runtime) error {
-			return req.Reply("Hello! I am PicoClaw 🦞")
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt != nil && rt.WelcomeMessage != nil {
+				if welcome := rt.WelcomeMessage(); welcome != "" {
+					return req.Reply(welcome)
+				}
+			}
+			return req.Reply(req.text(i18n.StartGreeting))
 		},
 	}
 }
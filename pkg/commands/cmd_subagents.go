@@ -2,7 +2,8 @@ package commands
 
 import (
 	"context"
-	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
 )
 
 // TurnInfo is a mirrored struct from agent.TurnInfo to avoid circular dependencies.
@@ -21,22 +22,22 @@ func subagentsCommand() Definition {
 		Handler: func(ctx context.Context, req Request, rt *Runtime) error {
 			getTurnFn := rt.GetActiveTurn
 			if getTurnFn == nil {
-				return req.Reply("Runtime does not support querying active turns.")
+				return req.Reply(req.text(i18n.SubagentsUnsupported))
 			}
 
 			turnRaw := getTurnFn()
 			if turnRaw == nil {
-				return req.Reply("No active tasks running in this session.")
+				return req.Reply(req.text(i18n.SubagentsNone))
 			}
 
 			if treeStr, ok := turnRaw.(string); ok {
 				if treeStr == "" {
-					return req.Reply("No active tasks running in this session.")
+					return req.Reply(req.text(i18n.SubagentsNone))
 				}
-				return req.Reply(fmt.Sprintf("🤖 **Active Subagents Tree**\n```text\n%s\n```", treeStr))
+				return req.Reply(req.textf(i18n.SubagentsTree, treeStr))
 			}
 
-			return req.Reply(fmt.Sprintf("🤖 **Active Subagents List**\n```text\n%+v\n```", turnRaw))
+			return req.Reply(req.textf(i18n.SubagentsList, turnRaw))
 		},
 	}
 }
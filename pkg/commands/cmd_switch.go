@@ -2,7 +2,8 @@ package commands
 
 import (
 	"context"
-	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
 )
 
 func switchCommand() Definition {
@@ -16,25 +17,25 @@ func switchCommand() Definition {
 				ArgsUsage:   "to <name>",
 				Handler: func(_ context.Context, req Request, rt *Runtime) error {
 					if rt == nil || rt.SwitchModel == nil {
-						return req.Reply(unavailableMsg)
+						return req.Reply(req.text(i18n.CommandUnavailable))
 					}
 					// Parse: /switch model to <value>
 					value := nthToken(req.Text, 3) // tokens: [/switch, model, to, <value>]
 					if nthToken(req.Text, 2) != "to" || value == "" {
-						return req.Reply("Usage: /switch model to <name>")
+						return req.Reply(req.textf(i18n.CommandUsage, "/switch model to <name>"))
 					}
 					oldModel, err := rt.SwitchModel(value)
 					if err != nil {
 						return req.Reply(err.Error())
 					}
-					return req.Reply(fmt.Sprintf("Switched model from %s to %s", oldModel, value))
+					return req.Reply(req.textf(i18n.SwitchModelDone, oldModel, value))
 				},
 			},
 			{
 				Name:        "channel",
 				Description: "Moved to /check channel",
 				Handler: func(_ context.Context, req Request, _ *Runtime) error {
-					return req.Reply("This command has moved. Please use: /check channel <name>")
+					return req.Reply(req.text(i18n.SwitchChannelMoved))
 				},
 			},
 		},
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
+)
+
+func thinkCommand() Definition {
+	return Definition{
+		Name:        "think",
+		Description: "Show or set the reasoning budget for this session",
+		Usage:       "/think [<tokens>|off|reset]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.GetThinking == nil || rt.SetThinkingBudget == nil {
+				return req.Reply(req.text(i18n.CommandUnavailable))
+			}
+			switch arg := strings.ToLower(nthToken(req.Text, 1)); arg {
+			case "":
+				level, budget := rt.GetThinking()
+				switch {
+				case budget > 0:
+					return req.Reply(withThinkingSupport(req, rt, req.textf(i18n.ThinkBudget, budget)))
+				case level != "" && level != "off":
+					return req.Reply(withThinkingSupport(req, rt, req.textf(i18n.ThinkLevel, level)))
+				default:
+					return req.Reply(req.text(i18n.ThinkOff))
+				}
+			case "off":
+				rt.SetThinkingBudget(0)
+				return req.Reply(req.text(i18n.ThinkTurnedOff))
+			case "reset":
+				rt.SetThinkingBudget(-1)
+				return req.Reply(req.text(i18n.ThinkRestored))
+			default:
+				budget, err := strconv.Atoi(arg)
+				if err != nil || budget <= 0 {
+					return req.Reply(req.textf(i18n.CommandUsage, "/think [<tokens>|off|reset]"))
+				}
+				rt.SetThinkingBudget(budget)
+				return req.Reply(withThinkingSupport(req, rt, req.textf(i18n.ThinkBudgetSet, budget)))
+			}
+		},
+	}
+}
+
+// withThinkingSupport appends a notice to reply when the session's model
+// cannot use thinking, so the setting would silently do nothing.
+func withThinkingSupport(req Request, rt *Runtime, reply string) string {
+	if rt.ThinkingSupported == nil || rt.ThinkingSupported() {
+		return reply
+	}
+	return reply + "\n" + req.text(i18n.ThinkUnsupported)
+}
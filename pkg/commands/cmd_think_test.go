@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"testing"
+)
+
+func TestThink_SetShowAndReset(t *testing.T) {
+	level, budget := "medium", 0
+	rt := &Runtime{
+		GetThinking: func() (string, int) { return level, budget },
+		SetThinkingBudget: func(b int) {
+			switch {
+			case b < 0:
+				level, budget = "medium", 0
+			case b == 0:
+				level, budget = "off", 0
+			default:
+				budget = b
+			}
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+	run := func(text string) string {
+		t.Helper()
+		var reply string
+		res := ex.Execute(context.Background(), Request{
+			Text:  text,
+			Reply: func(s string) error { reply = s; return nil },
+		})
+		if res.Outcome != OutcomeHandled {
+			t.Fatalf("%s: outcome=%v, want=%v", text, res.Outcome, OutcomeHandled)
+		}
+		return reply
+	}
+
+	steps := []struct{ text, want string }{
+		{"/think", "Thinking level: medium"},
+		{"/think 8000", "Thinking budget set to 8000 tokens for this session."},
+		{"/think", "Thinking budget: 8000 tokens"},
+		{"/think off", "Thinking turned off for this session."},
+		{"/think", "Thinking is off."},
+		{"/think reset", "Thinking restored to the configured settings."},
+		{"/think", "Thinking level: medium"},
+		{"/think lots", "Usage: /think [<tokens>|off|reset]"},
+	}
+	for _, step := range steps {
+		if got := run(step.text); got != step.want {
+			t.Fatalf("%s: reply=%q, want=%q", step.text, got, step.want)
+		}
+	}
+}
+
+func TestThink_ReportsUnsupportedModel(t *testing.T) {
+	rt := &Runtime{
+		GetThinking:       func() (string, int) { return "off", 0 },
+		SetThinkingBudget: func(int) {},
+		ThinkingSupported: func() bool { return false },
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+	var reply string
+	ex.Execute(context.Background(), Request{
+		Text:  "/think 2000",
+		Reply: func(s string) error { reply = s; return nil },
+	})
+	want := "Thinking budget set to 2000 tokens for this session.\n" +
+		"The current model does not support thinking, so this setting has no effect."
+	if reply != want {
+		t.Fatalf("reply=%q, want=%q", reply, want)
+	}
+}
+
+func TestThink_Unavailable(t *testing.T) {
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), &Runtime{})
+	var reply string
+	ex.Execute(context.Background(), Request{
+		Text:  "/think 1000",
+		Reply: func(s string) error { reply = s; return nil },
+	})
+	if want := "Command unavailable in current context."; reply != want {
+		t.Fatalf("reply=%q, want=%q", reply, want)
+	}
+}
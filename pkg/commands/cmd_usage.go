@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
+)
+
+func usageCommand() Definition {
+	return Definition{
+		Name:        "usage",
+		Description: "Show token usage for this session",
+		Usage:       "/usage",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.GetSessionUsage == nil {
+				return req.Reply(req.text(i18n.CommandUnavailable))
+			}
+			u := rt.GetSessionUsage()
+			if u.Requests == 0 {
+				return req.Reply(req.text(i18n.UsageNone))
+			}
+			reply := req.textf(i18n.UsageSummary, u.Requests, u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+			if u.CacheReadTokens > 0 || u.CacheWriteTokens > 0 {
+				reply += req.textf(i18n.UsageCached, u.CacheReadTokens, u.CacheWriteTokens)
+			}
+			return req.Reply(reply)
+		},
+	}
+}
@@ -2,20 +2,21 @@ package commands
 
 import (
 	"context"
-	"fmt"
 	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
 )
 
 // agentsHandler returns a shared handler for both /show agents and /list agents.
 func agentsHandler() Handler {
 	return func(_ context.Context, req Request, rt *Runtime) error {
 		if rt == nil || rt.ListAgentIDs == nil {
-			return req.Reply(unavailableMsg)
+			return req.Reply(req.text(i18n.CommandUnavailable))
 		}
 		ids := rt.ListAgentIDs()
 		if len(ids) == 0 {
-			return req.Reply("No agents registered")
+			return req.Reply(req.text(i18n.AgentsNone))
 		}
-		return req.Reply(fmt.Sprintf("Registered agents: %s", strings.Join(ids, ", ")))
+		return req.Reply(req.textf(i18n.AgentsList, strings.Join(ids, ", ")))
 	}
 }
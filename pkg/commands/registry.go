@@ -1,6 +1,12 @@
 package commands
 
+import (
+	"fmt"
+	"sync"
+)
+
 type Registry struct {
+	mu    sync.RWMutex
 	defs  []Definition
 	index map[string]int
 }
@@ -25,6 +31,8 @@ func NewRegistry(defs []Definition) *Registry {
 // Definitions returns all registered command definitions.
 // Command availability is global and no longer channel-scoped.
 func (r *Registry) Definitions() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	out := make([]Definition, len(r.defs))
 	copy(out, r.defs)
 	return out
@@ -36,6 +44,8 @@ func (r *Registry) Lookup(name string) (Definition, bool) {
 	if key == "" {
 		return Definition{}, false
 	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	idx, ok := r.index[key]
 	if !ok {
 		return Definition{}, false
@@ -43,6 +53,32 @@ func (r *Registry) Lookup(name string) (Definition, bool) {
 	return r.defs[idx], true
 }
 
+// Register adds a command definition at runtime. It fails when the command
+// name is empty or already taken by another command or alias; aliases that
+// collide with existing names are skipped, matching NewRegistry.
+func (r *Registry) Register(def Definition) error {
+	key := normalizeCommandName(def.Name)
+	if key == "" {
+		return fmt.Errorf("command name is required")
+	}
+	if def.Handler == nil && len(def.SubCommands) == 0 {
+		return fmt.Errorf("command %q has no handler", def.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.index[key]; exists {
+		return fmt.Errorf("command %q is already registered", def.Name)
+	}
+	r.defs = append(r.defs, def)
+	idx := len(r.defs) - 1
+	registerCommandName(r.index, def.Name, idx)
+	for _, alias := range def.Aliases {
+		registerCommandName(r.index, alias, idx)
+	}
+	return nil
+}
+
 func registerCommandName(index map[string]int, name string, defIndex int) {
 	key := normalizeCommandName(name)
 	if key == "" {
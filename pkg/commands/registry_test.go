@@ -1,6 +1,9 @@
 package commands
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestRegistry_Definitions_ReturnsCopy(t *testing.T) {
 	defs := []Definition{
@@ -47,3 +50,28 @@ func TestRegistry_Lookup_MatchesByLowercaseNameAndAlias(t *testing.T) {
 		t.Fatalf("lookup by uppercase alias failed: ok=%v def=%+v", ok, def)
 	}
 }
+
+func TestRegistry_Register_AddsCommandAndRejectsDuplicates(t *testing.T) {
+	noop := func(context.Context, Request, *Runtime) error { return nil }
+	r := NewRegistry([]Definition{{Name: "help", Aliases: []string{"h"}, Handler: noop}})
+
+	if err := r.Register(Definition{Name: "Deploy", Aliases: []string{"ship", "h"}, Handler: noop}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if def, ok := r.Lookup("ship"); !ok || def.Name != "Deploy" {
+		t.Fatalf("lookup by new alias failed: ok=%v def=%+v", ok, def)
+	}
+	if def, ok := r.Lookup("h"); !ok || def.Name != "help" {
+		t.Fatalf("colliding alias should keep existing owner, got ok=%v def=%+v", ok, def)
+	}
+	if len(r.Definitions()) != 2 {
+		t.Fatalf("definitions len = %d, want 2", len(r.Definitions()))
+	}
+
+	if err := r.Register(Definition{Name: "HELP", Handler: noop}); err == nil {
+		t.Fatal("expected duplicate name to be rejected")
+	}
+	if err := r.Register(Definition{Name: "empty"}); err == nil {
+		t.Fatal("expected command without handler to be rejected")
+	}
+}
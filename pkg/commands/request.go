@@ -3,6 +3,8 @@ package commands
 import (
 	"context"
 	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
 )
 
 type Handler func(ctx context.Context, req Request, rt *Runtime) error
@@ -12,10 +14,19 @@ type Request struct {
 	ChatID   string
 	SenderID string
 	Text     string
+	Locale   string // locale for built-in replies; "" means English
 	Reply    func(text string) error
 }
 
-const unavailableMsg = "Command unavailable in current context."
+// text returns a built-in reply in the request's locale.
+func (r Request) text(id i18n.Message) string {
+	return i18n.Text(r.Locale, id)
+}
+
+// textf formats a built-in reply in the request's locale.
+func (r Request) textf(id i18n.Message, args ...any) string {
+	return i18n.Sprintf(r.Locale, id, args...)
+}
 
 var commandPrefixes = []string{"/", "!"}
 
@@ -16,10 +16,26 @@ type Runtime struct {
 	ListAgentIDs       func() []string
 	ListDefinitions    func() []Definition
 	ListSkillNames     func() []string
+	ListToolNames      func() []string
 	GetEnabledChannels func() []string
 	GetActiveTurn      func() any // Returning any to avoid circular dependency with agent package
 	SwitchModel        func(value string) (oldModel string, err error)
 	SwitchChannel      func(value string) error
 	ClearHistory       func() error
+	GetSessionUsage    func() TokenUsage
+	GetThinking        func() (level string, budget int) // effective thinking for this session
+	SetThinkingBudget  func(budget int)                  // 0 turns thinking off, negative restores config
+	ThinkingSupported  func() bool                       // whether this session's model can use thinking
 	ReloadConfig       func() error
+	WelcomeMessage     func() string // the channel's rendered welcome, "" if none
+}
+
+// TokenUsage is the accumulated model usage reported for one session.
+type TokenUsage struct {
+	Requests         int
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+	CacheReadTokens  int64 // prompt tokens served from the provider's cache
+	CacheWriteTokens int64 // prompt tokens written to the provider's cache
 }
@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -246,6 +247,105 @@ type SubTurnConfig struct {
 	ConcurrencyTimeoutSec int `json:"concurrency_timeout_sec" env:"PICOCLAW_AGENTS_DEFAULTS_SUBTURN_CONCURRENCY_TIMEOUT_SEC"`
 }
 
+// BudgetConfig caps how much one session may spend across the LLM calls of
+// all its turns. A turn that finds its session over either limit stops
+// before running more tools and returns its partial answer. Zero disables a
+// limit.
+type BudgetConfig struct {
+	MaxTokens  int     `json:"max_tokens,omitempty"   env:"PICOCLAW_AGENTS_DEFAULTS_BUDGET_MAX_TOKENS"`
+	MaxCostUSD float64 `json:"max_cost_usd,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_BUDGET_MAX_COST_USD"`
+	// Prices used to estimate cost, in USD per million tokens.
+	InputCostPerMTok  float64 `json:"input_cost_per_mtok,omitempty"  env:"PICOCLAW_AGENTS_DEFAULTS_BUDGET_INPUT_COST_PER_MTOK"`
+	OutputCostPerMTok float64 `json:"output_cost_per_mtok,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_BUDGET_OUTPUT_COST_PER_MTOK"`
+}
+
+// Output filter types accepted in agents.defaults.output_filters.
+const (
+	OutputFilterRegex     = "regex"
+	OutputFilterMaxLength = "max_length"
+	OutputFilterPII       = "pii"
+	OutputFilterWords     = "words"
+)
+
+// OutputFilter is one step of the chain applied to the agent's final reply
+// before it is delivered. Filters run in the order they are listed.
+type OutputFilter struct {
+	Type        string   `json:"type"`
+	Pattern     string   `json:"pattern,omitempty"`     // regex: RE2 pattern to replace
+	Replacement string   `json:"replacement,omitempty"` // regex, pii, words: text substituted for each match
+	MaxLength   int      `json:"max_length,omitempty"`  // max_length: characters kept
+	Kinds       []string `json:"kinds,omitempty"`       // pii: "email", "phone", "card" (default all)
+	Words       []string `json:"words,omitempty"`       // words: whole words masked case-insensitively
+}
+
+// Validate checks that the filter has a known type and the fields it needs.
+func (f *OutputFilter) Validate() error {
+	switch f.Type {
+	case OutputFilterRegex:
+		if f.Pattern == "" {
+			return fmt.Errorf("regex filter requires pattern")
+		}
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	case OutputFilterMaxLength:
+		if f.MaxLength <= 0 {
+			return fmt.Errorf("max_length filter requires max_length > 0")
+		}
+	case OutputFilterPII:
+		for _, kind := range f.Kinds {
+			switch kind {
+			case "email", "phone", "card":
+			default:
+				return fmt.Errorf("unknown pii kind %q", kind)
+			}
+		}
+	case OutputFilterWords:
+		if len(f.Words) == 0 {
+			return fmt.Errorf("words filter requires words")
+		}
+	default:
+		return fmt.Errorf("unknown output filter type %q", f.Type)
+	}
+	return nil
+}
+
+// FallbackRaceConfig enables hedged requests across the model fallback chain.
+// When enabled, the first fallback is started if the primary has not answered
+// within HedgeDelayMs, and whichever answers first wins. This can double
+// provider cost for slow requests.
+type FallbackRaceConfig struct {
+	Enabled      bool `json:"enabled"                  env:"PICOCLAW_AGENTS_DEFAULTS_FALLBACK_RACE_ENABLED"`
+	HedgeDelayMs int  `json:"hedge_delay_ms,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_FALLBACK_RACE_HEDGE_DELAY_MS"`
+}
+
+// DefaultFallbackHedgeDelay is used when racing is enabled without a delay.
+const DefaultFallbackHedgeDelay = 3 * time.Second
+
+// HedgeDelay returns how long to wait for the primary before racing the first
+// fallback, or 0 when racing is disabled.
+func (c FallbackRaceConfig) HedgeDelay() time.Duration {
+	if !c.Enabled {
+		return 0
+	}
+	if c.HedgeDelayMs > 0 {
+		return time.Duration(c.HedgeDelayMs) * time.Millisecond
+	}
+	return DefaultFallbackHedgeDelay
+}
+
+// RetryQueueConfig keeps a message whose turn failed because every model in
+// the fallback chain was unavailable, and retries it in the background until
+// a provider recovers. The reply is then sent to the chat the message came
+// from. Only messages that failed before any tool ran are queued.
+type RetryQueueConfig struct {
+	Enabled         bool `json:"enabled"                     env:"PICOCLAW_AGENTS_DEFAULTS_RETRY_QUEUE_ENABLED"`
+	MaxSize         int  `json:"max_size,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_RETRY_QUEUE_MAX_SIZE"`          // Messages kept at once (default 50)
+	TTLMinutes      int  `json:"ttl_minutes,omitempty"       env:"PICOCLAW_AGENTS_DEFAULTS_RETRY_QUEUE_TTL_MINUTES"`       // Minutes before a queued message is given up (default 360)
+	InitialDelaySec int  `json:"initial_delay_sec,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_RETRY_QUEUE_INITIAL_DELAY_SEC"` // Delay before the first retry, doubled after each failure (default 30)
+	MaxDelaySec     int  `json:"max_delay_sec,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_RETRY_QUEUE_MAX_DELAY_SEC"`     // Upper bound on the delay between retries (default 600)
+}
+
 type ToolFeedbackConfig struct {
 	Enabled       bool `json:"enabled"         env:"PICOCLAW_AGENTS_DEFAULTS_TOOL_FEEDBACK_ENABLED"`
 	MaxArgsLength int  `json:"max_args_length" env:"PICOCLAW_AGENTS_DEFAULTS_TOOL_FEEDBACK_MAX_ARGS_LENGTH"`
@@ -258,20 +358,32 @@ type AgentDefaults struct {
 	Provider                  string             `json:"provider"                         env:"PICOCLAW_AGENTS_DEFAULTS_PROVIDER"`
 	ModelName                 string             `json:"model_name"                       env:"PICOCLAW_AGENTS_DEFAULTS_MODEL_NAME"`
 	ModelFallbacks            []string           `json:"model_fallbacks,omitempty"`
+	FallbackRace              FallbackRaceConfig `json:"fallback_race,omitempty"`
+	RetryQueue                RetryQueueConfig   `json:"retry_queue,omitempty"`
+	RetryEmptyResponse        bool               `json:"retry_empty_response,omitempty"   env:"PICOCLAW_AGENTS_DEFAULTS_RETRY_EMPTY_RESPONSE"` // Ask the next fallback model once when a reply is empty or content-filtered
 	ImageModel                string             `json:"image_model,omitempty"            env:"PICOCLAW_AGENTS_DEFAULTS_IMAGE_MODEL"`
 	ImageModelFallbacks       []string           `json:"image_model_fallbacks,omitempty"`
 	MaxTokens                 int                `json:"max_tokens"                       env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS"`
 	ContextWindow             int                `json:"context_window,omitempty"         env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_WINDOW"`
 	Temperature               *float64           `json:"temperature,omitempty"            env:"PICOCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
+	ThinkingBudget            int                `json:"thinking_budget,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_THINKING_BUDGET"` // Reasoning token budget for thinking-capable models (0 = use thinking_level)
 	MaxToolIterations         int                `json:"max_tool_iterations"              env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
+	DisableTools              bool               `json:"disable_tools,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_DISABLE_TOOLS"` // never send tool definitions; plain chat completions
 	SummarizeMessageThreshold int                `json:"summarize_message_threshold"      env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARIZE_MESSAGE_THRESHOLD"`
 	SummarizeTokenPercent     int                `json:"summarize_token_percent"          env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARIZE_TOKEN_PERCENT"`
 	MaxMediaSize              int                `json:"max_media_size,omitempty"         env:"PICOCLAW_AGENTS_DEFAULTS_MAX_MEDIA_SIZE"`
 	Routing                   *RoutingConfig     `json:"routing,omitempty"`
-	SteeringMode              string             `json:"steering_mode,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_STEERING_MODE"`      // "one-at-a-time" (default) or "all"
-	MaxParallelTurns          int                `json:"max_parallel_turns,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_MAX_PARALLEL_TURNS"` // Max concurrent turns (0 or 1 = sequential)
+	SteeringMode              string             `json:"steering_mode,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_STEERING_MODE"`            // "one-at-a-time" (default) or "all"
+	MaxParallelTurns          int                `json:"max_parallel_turns,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_MAX_PARALLEL_TURNS"`       // Max concurrent turns (0 or 1 = sequential)
+	MaxParallelToolCalls      int                `json:"max_parallel_tool_calls,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_MAX_PARALLEL_TOOL_CALLS"` // Max concurrent tool calls per LLM response (0 or 1 = sequential)
+	TurnQueueTimeout          int                `json:"turn_queue_timeout,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_TURN_QUEUE_TIMEOUT"`       // Seconds a message waits for a free turn slot before a busy reply (0 = no limit, -1 = reply busy at once)
+	SessionIdleMinutes        int                `json:"session_idle_minutes,omitempty"   env:"PICOCLAW_AGENTS_DEFAULTS_SESSION_IDLE_MINUTES"`     // Minutes before an idle session's in-memory state is released (0 = never)
 	SubTurn                   SubTurnConfig      `json:"subturn"                                                                                      envPrefix:"PICOCLAW_AGENTS_DEFAULTS_SUBTURN_"`
 	ToolFeedback              ToolFeedbackConfig `json:"tool_feedback,omitempty"`
+	Messages                  ReplyTextConfig    `json:"messages,omitzero"`
+	Locale                    string             `json:"locale,omitempty"                 env:"PICOCLAW_AGENTS_DEFAULTS_LOCALE"` // Locale for built-in replies when neither the channel nor the sender sets one
+	Budget                    BudgetConfig       `json:"budget,omitempty"`
+	OutputFilters             []OutputFilter     `json:"output_filters,omitempty"`
 	SplitOnMarker             bool               `json:"split_on_marker"                  env:"PICOCLAW_AGENTS_DEFAULTS_SPLIT_ON_MARKER"` // split messages on <|[SPLIT]|> marker
 	ContextManager            string             `json:"context_manager,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_MANAGER"`
 	ContextManagerConfig      json.RawMessage    `json:"context_manager_config,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_MANAGER_CONFIG"`
@@ -322,6 +434,24 @@ type PlaceholderConfig struct {
 	Text    FlexibleStringSlice `json:"text,omitempty"`
 }
 
+// WelcomeConfig greets a sender the first time they message the bot, and
+// answers /start. Message may use {bot_name}, {user} and {commands}.
+type WelcomeConfig struct {
+	Message string `json:"message,omitempty"`
+}
+
+// ReplyTextConfig overrides the fixed replies the agent sends when it
+// cannot answer. Error may use {category} and {message}; empty fields keep
+// the next level's text. Empty is sent when the model returns no text and
+// no tool calls, Refused when the provider's content filter withheld the
+// reply.
+type ReplyTextConfig struct {
+	Error   string `json:"error,omitempty"`
+	Busy    string `json:"busy,omitempty"`
+	Empty   string `json:"empty,omitempty"`
+	Refused string `json:"refused,omitempty"`
+}
+
 // GetRandomText returns a random placeholder text, or default if none set.
 func (p *PlaceholderConfig) GetRandomText() string {
 	if len(p.Text) == 0 {
@@ -352,6 +482,13 @@ type TelegramSettings struct {
 	Proxy         string          `json:"proxy"               yaml:"-"               env:"PICOCLAW_CHANNELS_TELEGRAM_PROXY"`
 	Streaming     StreamingConfig `json:"streaming,omitempty" yaml:"-"`
 	UseMarkdownV2 bool            `json:"use_markdown_v2"     yaml:"-"               env:"PICOCLAW_CHANNELS_TELEGRAM_USE_MARKDOWN_V2"`
+	// Mode is how updates arrive: "polling" (default) fetches them with
+	// getUpdates; "webhook" registers WebhookURL with Telegram and receives
+	// them on the gateway's shared HTTP server at WebhookPath.
+	Mode          string       `json:"mode,omitempty"          yaml:"-"                        env:"PICOCLAW_CHANNELS_TELEGRAM_MODE"`
+	WebhookURL    string       `json:"webhook_url,omitempty"   yaml:"-"                        env:"PICOCLAW_CHANNELS_TELEGRAM_WEBHOOK_URL"`
+	WebhookPath   string       `json:"webhook_path,omitempty"  yaml:"-"                        env:"PICOCLAW_CHANNELS_TELEGRAM_WEBHOOK_PATH"`
+	WebhookSecret SecureString `json:"webhook_secret,omitzero" yaml:"webhook_secret,omitempty" env:"PICOCLAW_CHANNELS_TELEGRAM_WEBHOOK_SECRET"`
 }
 
 type FeishuSettings struct {
@@ -454,6 +591,11 @@ type PicoSettings struct {
 	ReadTimeout     int          `json:"read_timeout,omitempty"      yaml:"-"`
 	WriteTimeout    int          `json:"write_timeout,omitempty"     yaml:"-"`
 	MaxConnections  int          `json:"max_connections,omitempty"   yaml:"-"`
+	MaxMessageSize  int64        `json:"max_message_size,omitempty"  yaml:"-"`
+	Streaming       bool         `json:"streaming,omitempty"         yaml:"-"`
+	MaxUploadSize   int64        `json:"max_upload_size,omitempty"   yaml:"-"` // Bytes accepted per file upload (default 20 MB)
+	UploadMIMETypes []string     `json:"upload_mime_types,omitempty" yaml:"-"` // Accepted upload types; "text/*" matches a family
+	DisconnectGrace int          `json:"disconnect_grace,omitempty"  yaml:"-"` // Seconds a chat's turns keep running after its last tab closed (default 30)
 }
 
 // SetToken sets the Pico token and marks it as dirty for security saving
@@ -538,6 +680,10 @@ type ModelConfig struct {
 	Proxy     string   `json:"proxy,omitempty"`     // HTTP proxy URL
 	Fallbacks []string `json:"fallbacks,omitempty"` // Fallback model names for failover
 
+	// APIVersion selects the classic Azure OpenAI deployments endpoint
+	// (api-version query parameter + api-key header) for azure models.
+	APIVersion string `json:"api_version,omitempty"`
+
 	// Special providers (CLI-based, OAuth, etc.)
 	AuthMethod  string `json:"auth_method,omitempty"`  // Authentication method: oauth, token
 	ConnectMode string `json:"connect_mode,omitempty"` // Connection mode: stdio, grpc
@@ -551,8 +697,47 @@ type ModelConfig struct {
 	ExtraBody      map[string]any    `json:"extra_body,omitempty"`     // Additional fields to inject into request body
 	CustomHeaders  map[string]string `json:"custom_headers,omitempty"` // Additional headers to inject into every HTTP request
 
+	// ThinkingBudget is the reasoning token budget sent to thinking-capable
+	// providers for this model, in place of the budget its thinking_level
+	// implies. It overrides agents.defaults.thinking_budget.
+	ThinkingBudget int `json:"thinking_budget,omitempty"`
+
+	// PromptCaching controls the prompt cache breakpoints Anthropic
+	// providers put on the system prompt and tool definitions. Unset means
+	// on; false sends no cache_control, for endpoints that reject it.
+	PromptCaching *bool `json:"prompt_caching,omitempty"`
+
+	// WireLog logs every request and response body of this model with API
+	// keys and bearer tokens redacted, for debugging provider interop.
+	// WireLogMaxBytes caps each logged body (default 8192).
+	WireLog         bool `json:"wire_log,omitempty"`
+	WireLogMaxBytes int  `json:"wire_log_max_bytes,omitempty"`
+
+	// Router configures a "router/..." model, which asks a classifier model
+	// to label each request and forwards it to the model mapped to that label.
+	Router *ModelRouterConfig `json:"router,omitempty"`
+
+	// OAuth configures auth_method "oauth" for HTTP providers behind a
+	// gateway that issues bearer tokens through the client-credentials grant.
+	// The token replaces the api key.
+	OAuth *ModelOAuthConfig `json:"oauth,omitempty"`
+
+	// MaxTokens and Temperature replace agents.defaults.max_tokens and
+	// temperature when this model is called. MaxTemperature caps the
+	// temperature sent, overriding the model's built-in known limit.
+	MaxTokens      int      `json:"max_tokens,omitempty"`
+	Temperature    *float64 `json:"temperature,omitempty"`
+	MaxTemperature *float64 `json:"max_temperature,omitempty"`
+
 	APIKeys SecureStrings `json:"api_keys,omitzero" yaml:"api_keys,omitempty"` // API authentication keys (multiple keys for failover)
 
+	// KeyPool spreads requests across api_keys round-robin instead of
+	// expanding them into fallback models that are tried in order. A key
+	// that is rate limited (HTTP 429) is skipped for KeyCooldown seconds
+	// (default 60).
+	KeyPool     bool `json:"key_pool,omitempty"`
+	KeyCooldown int  `json:"key_cooldown,omitempty"`
+
 	// Enabled indicates whether this model entry is active. When omitted in
 	// existing configs, the field is inferred during load: models with API keys
 	// or the reserved "local-model" name are auto-enabled.
@@ -563,6 +748,8 @@ type ModelConfig struct {
 	// isVirtual marks this model as a virtual model generated from multi-key expansion.
 	// Virtual models should not be persisted to config files.
 	isVirtual bool
+	// envExpanded marks a copy made by WithEnvExpanded.
+	envExpanded bool
 }
 
 // APIKey returns the first API key from apiKeys
@@ -586,6 +773,78 @@ func (c *ModelConfig) Validate() error {
 	if c.Model == "" {
 		return fmt.Errorf("model is required")
 	}
+	if strings.HasPrefix(c.Model, "router/") {
+		if c.Router == nil {
+			return fmt.Errorf("router model %q requires a router section", c.ModelName)
+		}
+		if err := c.Router.Validate(); err != nil {
+			return fmt.Errorf("router: %w", err)
+		}
+	}
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must not be negative")
+	}
+	if c.ThinkingBudget < 0 {
+		return fmt.Errorf("thinking_budget must not be negative")
+	}
+	if c.Temperature != nil && (*c.Temperature < 0 || *c.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	if c.MaxTemperature != nil && *c.MaxTemperature < 0 {
+		return fmt.Errorf("max_temperature must not be negative")
+	}
+	if c.OAuth != nil {
+		if c.OAuth.TokenURL == "" || c.OAuth.ClientID == "" {
+			return fmt.Errorf("oauth requires token_url and client_id")
+		}
+		if c.AuthMethod != "oauth" {
+			return fmt.Errorf("oauth requires auth_method \"oauth\"")
+		}
+	}
+	return nil
+}
+
+// UsesClientCredentials reports whether the model authenticates with the
+// OAuth client-credentials grant instead of a static api key.
+func (c *ModelConfig) UsesClientCredentials() bool {
+	return c.AuthMethod == "oauth" && c.OAuth != nil && c.OAuth.TokenURL != ""
+}
+
+// ModelOAuthConfig is an OAuth client-credentials grant. Tokens are cached
+// and refreshed shortly before they expire.
+type ModelOAuthConfig struct {
+	TokenURL     string       `json:"token_url"`
+	ClientID     string       `json:"client_id"`
+	ClientSecret SecureString `json:"client_secret,omitzero" yaml:"client_secret,omitempty"`
+	Scopes       []string     `json:"scopes,omitempty"`
+}
+
+// ModelRouterConfig maps classifier labels to model_list entries.
+type ModelRouterConfig struct {
+	ClassifierModel string            `json:"classifier_model"`            // model_name that labels each request
+	Prompt          string            `json:"prompt,omitempty"`            // classification instructions; the label list is appended
+	Routes          map[string]string `json:"routes"`                      // label -> model_name
+	DefaultRoute    string            `json:"default_route"`               // label used when the classifier fails or answers off-list
+	CacheTTLSeconds int               `json:"cache_ttl_seconds,omitempty"` // how long a label is reused for identical input (default 300, -1 = off)
+}
+
+// Validate checks that the router has a classifier and that the default
+// route is one of its labels.
+func (c *ModelRouterConfig) Validate() error {
+	if strings.TrimSpace(c.ClassifierModel) == "" {
+		return fmt.Errorf("classifier_model is required")
+	}
+	if len(c.Routes) == 0 {
+		return fmt.Errorf("routes must not be empty")
+	}
+	for label, model := range c.Routes {
+		if strings.TrimSpace(label) == "" || strings.TrimSpace(model) == "" {
+			return fmt.Errorf("routes must map non-empty labels to model names")
+		}
+	}
+	if _, ok := c.Routes[c.DefaultRoute]; !ok {
+		return fmt.Errorf("default_route %q is not one of the routes", c.DefaultRoute)
+	}
 	return nil
 }
 
@@ -610,9 +869,10 @@ type ToolConfig struct {
 }
 
 type BraveConfig struct {
-	Enabled    bool          `json:"enabled"           yaml:"-"                  env:"PICOCLAW_TOOLS_WEB_BRAVE_ENABLED"`
-	APIKeys    SecureStrings `json:"api_keys,omitzero" yaml:"api_keys,omitempty" env:"PICOCLAW_TOOLS_WEB_BRAVE_API_KEYS"`
-	MaxResults int           `json:"max_results"       yaml:"-"                  env:"PICOCLAW_TOOLS_WEB_BRAVE_MAX_RESULTS"`
+	Enabled    bool          `json:"enabled"            yaml:"-"                  env:"PICOCLAW_TOOLS_WEB_BRAVE_ENABLED"`
+	APIKeys    SecureStrings `json:"api_keys,omitzero"  yaml:"api_keys,omitempty" env:"PICOCLAW_TOOLS_WEB_BRAVE_API_KEYS"`
+	BaseURL    string        `json:"base_url,omitempty" yaml:"-"                  env:"PICOCLAW_TOOLS_WEB_BRAVE_BASE_URL"`
+	MaxResults int           `json:"max_results"        yaml:"-"                  env:"PICOCLAW_TOOLS_WEB_BRAVE_MAX_RESULTS"`
 }
 
 // APIKey returns the Brave API key
@@ -733,6 +993,7 @@ type WebToolsConfig struct {
 	// For authenticated proxies, prefer HTTP_PROXY/HTTPS_PROXY env vars instead of embedding credentials in config.
 	Proxy                string              `yaml:"-" json:"proxy,omitempty"                  env:"PICOCLAW_TOOLS_WEB_PROXY"`
 	FetchLimitBytes      int64               `yaml:"-" json:"fetch_limit_bytes,omitempty"      env:"PICOCLAW_TOOLS_WEB_FETCH_LIMIT_BYTES"`
+	FetchMaxChars        int                 `yaml:"-" json:"fetch_max_chars,omitempty"        env:"PICOCLAW_TOOLS_WEB_FETCH_MAX_CHARS"`
 	Format               string              `yaml:"-" json:"format,omitempty"                 env:"PICOCLAW_TOOLS_WEB_FORMAT"`
 	PrivateHostWhitelist FlexibleStringSlice `yaml:"-" json:"private_host_whitelist,omitempty" env:"PICOCLAW_TOOLS_WEB_PRIVATE_HOST_WHITELIST"`
 }
@@ -750,6 +1011,38 @@ type ExecConfig struct {
 	CustomDenyPatterns  []string `                                 json:"custom_deny_patterns"  env:"PICOCLAW_TOOLS_EXEC_CUSTOM_DENY_PATTERNS"`
 	CustomAllowPatterns []string `                                 json:"custom_allow_patterns" env:"PICOCLAW_TOOLS_EXEC_CUSTOM_ALLOW_PATTERNS"`
 	TimeoutSeconds      int      `                                 json:"timeout_seconds"       env:"PICOCLAW_TOOLS_EXEC_TIMEOUT_SECONDS"` // 0 means use default (60s)
+	// AllowlistPatterns, when set, restricts exec to commands matching at
+	// least one of these regexes. Empty allows every command that passes
+	// the deny checks.
+	AllowlistPatterns []string `json:"allowlist_patterns" env:"PICOCLAW_TOOLS_EXEC_ALLOWLIST_PATTERNS"`
+	// IsolateEnv stops commands from inheriting the host environment; they
+	// get only PATH, HOME and similar basics plus Env and the variables the
+	// agent saved with set_env.
+	IsolateEnv bool              `json:"isolate_env"   env:"PICOCLAW_TOOLS_EXEC_ISOLATE_ENV"`
+	Env        map[string]string `json:"env,omitempty"`
+	// ReportExitCode ends every command result with its exit code, including
+	// successful ones, so a command that prints nothing returns just the
+	// "[Command exited with code 0]" marker instead of "(no output)".
+	ReportExitCode bool `json:"report_exit_code" env:"PICOCLAW_TOOLS_EXEC_REPORT_EXIT_CODE"`
+	// ResultFormat is "text" (default) or "json". JSON results carry the
+	// exit code, stdout, stderr, duration and whether the command timed out
+	// as separate fields.
+	ResultFormat string `json:"result_format,omitempty" env:"PICOCLAW_TOOLS_EXEC_RESULT_FORMAT"`
+	// BackgroundLogs also appends the output of background sessions to
+	// .logs/<command hash>.log in the working directory, so early output
+	// is still readable after the session buffer was drained or filled.
+	// A log is rotated once it exceeds BackgroundLogMaxKB (default 5120).
+	BackgroundLogs     bool `json:"background_logs"                 env:"PICOCLAW_TOOLS_EXEC_BACKGROUND_LOGS"`
+	BackgroundLogMaxKB int  `json:"background_log_max_kb,omitempty" env:"PICOCLAW_TOOLS_EXEC_BACKGROUND_LOG_MAX_KB"`
+	// CommandHistory records every command run, with its exit code and
+	// start time, in .exec_history.jsonl in the working directory, and
+	// enables the history action. Only the last CommandHistorySize
+	// commands (default 200) are kept.
+	CommandHistory     bool `json:"command_history"                env:"PICOCLAW_TOOLS_EXEC_COMMAND_HISTORY"`
+	CommandHistorySize int  `json:"command_history_size,omitempty" env:"PICOCLAW_TOOLS_EXEC_COMMAND_HISTORY_SIZE"`
+	// SerializeCommands runs commands one at a time per working directory,
+	// across every chat and agent, so concurrent builds cannot share a tree.
+	SerializeCommands bool `json:"serialize_commands" env:"PICOCLAW_TOOLS_EXEC_SERIALIZE_COMMANDS"`
 }
 
 type SkillsToolsConfig struct {
@@ -767,6 +1060,32 @@ type MediaCleanupConfig struct {
 	Interval   int `                                    json:"interval_minutes" env:"PICOCLAW_MEDIA_CLEANUP_INTERVAL"`
 }
 
+// ToolTraceConfig controls recording of tool invocations to per-session
+// JSONL trace files for debugging.
+type ToolTraceConfig struct {
+	Enabled bool `json:"enabled"       env:"PICOCLAW_TOOLS_TRACE_ENABLED"`
+	// Dir overrides where trace files are written. Default: <workspace>/traces.
+	Dir string `json:"dir,omitempty" env:"PICOCLAW_TOOLS_TRACE_DIR"`
+}
+
+// ToolConfirmConfig controls whether destructive tool calls wait for an
+// explicit yes/no before they run.
+type ToolConfirmConfig struct {
+	Enabled bool `json:"enabled"              env:"PICOCLAW_TOOLS_CONFIRM_ENABLED"`
+	// Autonomous decides calls made without a human in the loop (cron,
+	// heartbeat and other internal channels): "deny" (default) or "approve".
+	Autonomous string `json:"autonomous,omitempty" env:"PICOCLAW_TOOLS_CONFIRM_AUTONOMOUS"`
+}
+
+// ImageGenToolConfig configures the image_gen tool. ModelName names the
+// model_list entry that supplies the image model, API key and base URL.
+type ImageGenToolConfig struct {
+	ToolConfig        `       yaml:"-" envPrefix:"PICOCLAW_TOOLS_IMAGE_GEN_"`
+	ModelName         string `json:"model_name"            yaml:"-" env:"PICOCLAW_TOOLS_IMAGE_GEN_MODEL_NAME"`
+	Size              string `json:"size,omitempty"        yaml:"-" env:"PICOCLAW_TOOLS_IMAGE_GEN_SIZE"`
+	MaxPerUserPerHour int    `json:"max_per_user_per_hour" yaml:"-" env:"PICOCLAW_TOOLS_IMAGE_GEN_MAX_PER_USER_PER_HOUR"`
+}
+
 type ReadFileToolConfig struct {
 	Enabled         bool   `json:"enabled"`
 	Mode            string `json:"mode"`
@@ -796,6 +1115,21 @@ type ToolsConfig struct {
 	// tokens, secrets) from tool results before sending to the LLM.
 	// Default: true (enabled)
 	FilterSensitiveData bool `json:"filter_sensitive_data" yaml:"-" env:"PICOCLAW_TOOLS_FILTER_SENSITIVE_DATA"`
+	// CallTimeoutSeconds caps how long the agent loop waits for one tool
+	// call before abandoning it and telling the model it timed out.
+	// CallTimeouts overrides it per tool name. 0 means no limit.
+	CallTimeoutSeconds int            `json:"call_timeout_seconds,omitempty" yaml:"-" env:"PICOCLAW_TOOLS_CALL_TIMEOUT_SECONDS"`
+	CallTimeouts       map[string]int `json:"call_timeouts,omitempty"        yaml:"-"`
+	// MaxConcurrent caps how many calls to a tool run at once across all
+	// sessions, e.g. {"claude_code": 2}. Tools not listed are unlimited.
+	// A call over the cap waits up to ConcurrencyWaitSeconds (default 60)
+	// for a free slot, then the model is told the tool is busy.
+	MaxConcurrent          map[string]int `json:"max_concurrent,omitempty"           yaml:"-"`
+	ConcurrencyWaitSeconds int            `json:"concurrency_wait_seconds,omitempty" yaml:"-" env:"PICOCLAW_TOOLS_CONCURRENCY_WAIT_SECONDS"`
+	// MaxResultChars is the largest tool result passed to the model as is.
+	// Longer results are saved to the workspace and the model gets a head
+	// and tail excerpt plus the file path. 0 disables this.
+	MaxResultChars int `json:"max_result_chars,omitempty" yaml:"-" env:"PICOCLAW_TOOLS_MAX_RESULT_CHARS"`
 	// FilterMinLength is the minimum content length required for filtering.
 	// Content shorter than this will be returned unchanged for performance.
 	// Default: 8
@@ -806,12 +1140,23 @@ type ToolsConfig struct {
 	Skills          SkillsToolsConfig  `json:"skills"            yaml:"skills,omitempty"`
 	MediaCleanup    MediaCleanupConfig `json:"media_cleanup"     yaml:"-"`
 	MCP             MCPConfig          `json:"mcp"               yaml:"-"`
+	Trace           ToolTraceConfig    `json:"trace"             yaml:"-"`
+	Confirm         ToolConfirmConfig  `json:"confirm"           yaml:"-"`
 	AppendFile      ToolConfig         `json:"append_file"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_APPEND_FILE_"`
 	EditFile        ToolConfig         `json:"edit_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_EDIT_FILE_"`
+	Diff            ToolConfig         `json:"diff"              yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_DIFF_"`
+	Encode          ToolConfig         `json:"encode"            yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_ENCODE_"`
 	FindSkills      ToolConfig         `json:"find_skills"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_FIND_SKILLS_"`
+	Grep            ToolConfig         `json:"grep"              yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_GREP_"`
+	HTTPRequest     ToolConfig         `json:"http_request"      yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_HTTP_REQUEST_"`
+	ImageGen        ImageGenToolConfig `json:"image_gen"         yaml:"-"`
 	I2C             ToolConfig         `json:"i2c"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_I2C_"`
 	InstallSkill    ToolConfig         `json:"install_skill"     yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_INSTALL_SKILL_"`
 	ListDir         ToolConfig         `json:"list_dir"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_LIST_DIR_"`
+	MCPAdmin        ToolConfig         `json:"mcp_admin"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_MCP_ADMIN_"`
+	Model           ToolConfig         `json:"model"             yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_MODEL_"`
+	Project         ToolConfig         `json:"project"           yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_PROJECT_"`
+	Memory          ToolConfig         `json:"memory"            yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_MEMORY_"`
 	Message         ToolConfig         `json:"message"           yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_MESSAGE_"`
 	ReadFile        ReadFileToolConfig `json:"read_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_READ_FILE_"`
 	SendFile        ToolConfig         `json:"send_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_FILE_"`
@@ -820,10 +1165,36 @@ type ToolsConfig struct {
 	SpawnStatus     ToolConfig         `json:"spawn_status"      yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPAWN_STATUS_"`
 	SPI             ToolConfig         `json:"spi"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPI_"`
 	Subagent        ToolConfig         `json:"subagent"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SUBAGENT_"`
+	TailFile        ToolConfig         `json:"tail_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_TAIL_FILE_"`
 	WebFetch        ToolConfig         `json:"web_fetch"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_WEB_FETCH_"`
 	WriteFile       ToolConfig         `json:"write_file"        yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_WRITE_FILE_"`
 }
 
+// CallTimeout returns the agent-level timeout for a call to the named tool,
+// or 0 when calls to it are not limited.
+func (c *ToolsConfig) CallTimeout(name string) time.Duration {
+	seconds, ok := c.CallTimeouts[name]
+	if !ok {
+		seconds = c.CallTimeoutSeconds
+	}
+	return time.Duration(max(seconds, 0)) * time.Second
+}
+
+// ConcurrencyLimit returns how many calls to the named tool may run at once,
+// or 0 when the tool is not limited.
+func (c *ToolsConfig) ConcurrencyLimit(name string) int {
+	return max(c.MaxConcurrent[name], 0)
+}
+
+// ConcurrencyWait returns how long a call waits for a free slot under
+// MaxConcurrent before it is reported as busy (default: 60s).
+func (c *ToolsConfig) ConcurrencyWait() time.Duration {
+	if c.ConcurrencyWaitSeconds <= 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(c.ConcurrencyWaitSeconds) * time.Second
+}
+
 // IsFilterSensitiveDataEnabled returns true if sensitive data filtering is enabled
 func (c *ToolsConfig) IsFilterSensitiveDataEnabled() bool {
 	return c.FilterSensitiveData
@@ -946,6 +1317,21 @@ type MCPServerConfig struct {
 	URL string `json:"url,omitempty"`
 	// Headers are HTTP headers to send with requests (sse/http only)
 	Headers map[string]string `json:"headers,omitempty"`
+	// PingInterval, in seconds, enables a periodic MCP "ping". When a ping
+	// fails or exceeds PingTimeout (default 10s) the server is reconnected.
+	// 0 disables pinging, for servers that do not implement it.
+	PingInterval int `json:"ping_interval,omitempty"`
+	PingTimeout  int `json:"ping_timeout,omitempty"`
+	// Framing is how stdio messages are delimited: "auto" (default) detects
+	// it from the server's first output, "newline" or "content-length"
+	// (LSP-style headers) force one.
+	Framing string `json:"framing,omitempty"`
+	// ReadBufferSize is the stdio read buffer in bytes (default 64 KiB).
+	ReadBufferSize int `json:"read_buffer_size,omitempty"`
+	// ProtocolVersion pins the MCP protocol version requested at
+	// initialization, e.g. "2024-11-05", for servers that mishandle
+	// negotiation. Empty requests the newest version the client supports.
+	ProtocolVersion string `json:"protocol_version,omitempty"`
 }
 
 // MCPConfig defines configuration for all MCP servers
@@ -1166,6 +1552,11 @@ func LoadConfig(path string) (*Config, error) {
 	if err = cfg.ValidateModelList(); err != nil {
 		return nil, err
 	}
+	for i := range cfg.Agents.Defaults.OutputFilters {
+		if err = cfg.Agents.Defaults.OutputFilters[i].Validate(); err != nil {
+			return nil, fmt.Errorf("agents.defaults.output_filters[%d]: %w", i, err)
+		}
+	}
 
 	// Ensure Workspace has a default if not set
 	if cfg.Agents.Defaults.Workspace == "" {
@@ -1393,8 +1784,8 @@ func expandMultiKeyModels(models []*ModelConfig) []*ModelConfig {
 	for _, m := range models {
 		keys := m.APIKeys.Values()
 
-		// Single key or no keys: keep as-is
-		if len(keys) <= 1 {
+		// Single key, no keys or a key pool: keep as-is
+		if len(keys) <= 1 || m.KeyPool {
 			expanded = append(expanded, m)
 			continue
 		}
@@ -1410,22 +1801,31 @@ func expandMultiKeyModels(models []*ModelConfig) []*ModelConfig {
 
 			// Create a copy for the additional key
 			additionalEntry := &ModelConfig{
-				ModelName:      expandedName,
-				Model:          m.Model,
-				APIBase:        m.APIBase,
-				APIKeys:        SimpleSecureStrings(keys[i]),
-				Proxy:          m.Proxy,
-				AuthMethod:     m.AuthMethod,
-				ConnectMode:    m.ConnectMode,
-				Workspace:      m.Workspace,
-				RPM:            m.RPM,
-				MaxTokensField: m.MaxTokensField,
-				RequestTimeout: m.RequestTimeout,
-				ThinkingLevel:  m.ThinkingLevel,
-				ExtraBody:      m.ExtraBody,
-				CustomHeaders:  m.CustomHeaders,
-				UserAgent:      m.UserAgent,
-				isVirtual:      true,
+				ModelName:       expandedName,
+				Model:           m.Model,
+				APIBase:         m.APIBase,
+				APIVersion:      m.APIVersion,
+				APIKeys:         SimpleSecureStrings(keys[i]),
+				Proxy:           m.Proxy,
+				AuthMethod:      m.AuthMethod,
+				ConnectMode:     m.ConnectMode,
+				Workspace:       m.Workspace,
+				RPM:             m.RPM,
+				MaxTokensField:  m.MaxTokensField,
+				RequestTimeout:  m.RequestTimeout,
+				ThinkingLevel:   m.ThinkingLevel,
+				ThinkingBudget:  m.ThinkingBudget,
+				PromptCaching:   m.PromptCaching,
+				ExtraBody:       m.ExtraBody,
+				CustomHeaders:   m.CustomHeaders,
+				WireLog:         m.WireLog,
+				WireLogMaxBytes: m.WireLogMaxBytes,
+				UserAgent:       m.UserAgent,
+				MaxTokens:       m.MaxTokens,
+				Temperature:     m.Temperature,
+				MaxTemperature:  m.MaxTemperature,
+				OAuth:           m.OAuth,
+				isVirtual:       true,
 			}
 			expanded = append(expanded, additionalEntry)
 			fallbackNames = append(fallbackNames, expandedName)
@@ -1433,21 +1833,30 @@ func expandMultiKeyModels(models []*ModelConfig) []*ModelConfig {
 
 		// Create the primary entry with first key and fallbacks
 		primaryEntry := &ModelConfig{
-			ModelName:      originalName,
-			Model:          m.Model,
-			APIBase:        m.APIBase,
-			Proxy:          m.Proxy,
-			AuthMethod:     m.AuthMethod,
-			ConnectMode:    m.ConnectMode,
-			Workspace:      m.Workspace,
-			RPM:            m.RPM,
-			MaxTokensField: m.MaxTokensField,
-			RequestTimeout: m.RequestTimeout,
-			ThinkingLevel:  m.ThinkingLevel,
-			ExtraBody:      m.ExtraBody,
-			CustomHeaders:  m.CustomHeaders,
-			UserAgent:      m.UserAgent,
-			APIKeys:        SimpleSecureStrings(keys[0]),
+			ModelName:       originalName,
+			Model:           m.Model,
+			APIBase:         m.APIBase,
+			APIVersion:      m.APIVersion,
+			Proxy:           m.Proxy,
+			AuthMethod:      m.AuthMethod,
+			ConnectMode:     m.ConnectMode,
+			Workspace:       m.Workspace,
+			RPM:             m.RPM,
+			MaxTokensField:  m.MaxTokensField,
+			RequestTimeout:  m.RequestTimeout,
+			ThinkingLevel:   m.ThinkingLevel,
+			ThinkingBudget:  m.ThinkingBudget,
+			PromptCaching:   m.PromptCaching,
+			ExtraBody:       m.ExtraBody,
+			CustomHeaders:   m.CustomHeaders,
+			WireLog:         m.WireLog,
+			WireLogMaxBytes: m.WireLogMaxBytes,
+			UserAgent:       m.UserAgent,
+			MaxTokens:       m.MaxTokens,
+			Temperature:     m.Temperature,
+			MaxTemperature:  m.MaxTemperature,
+			OAuth:           m.OAuth,
+			APIKeys:         SimpleSecureStrings(keys[0]),
 		}
 
 		// Prepend new fallbacks to existing ones
@@ -1487,6 +1896,8 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.InstallSkill.Enabled
 	case "list_dir":
 		return t.ListDir.Enabled
+	case "memory":
+		return t.Memory.Enabled
 	case "message":
 		return t.Message.Enabled
 	case "read_file":
@@ -1499,16 +1910,34 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.SPI.Enabled
 	case "subagent":
 		return t.Subagent.Enabled
+	case "tail_file":
+		return t.TailFile.Enabled
+	case "grep":
+		return t.Grep.Enabled
+	case "diff":
+		return t.Diff.Enabled
+	case "encode":
+		return t.Encode.Enabled
 	case "web_fetch":
 		return t.WebFetch.Enabled
+	case "http_request":
+		return t.HTTPRequest.Enabled
 	case "send_file":
 		return t.SendFile.Enabled
 	case "send_tts":
 		return t.SendTTS.Enabled
+	case "image_gen":
+		return t.ImageGen.Enabled
 	case "write_file":
 		return t.WriteFile.Enabled
 	case "mcp":
 		return t.MCP.Enabled
+	case "mcp_admin":
+		return t.MCPAdmin.Enabled && t.MCP.Enabled
+	case "model":
+		return t.Model.Enabled
+	case "project":
+		return t.Project.Enabled
 	default:
 		return true
 	}
@@ -223,6 +223,9 @@ type Channel struct {
 	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty" yaml:"-"`
 	Typing             TypingConfig        `json:"typing,omitempty"        yaml:"-"`
 	Placeholder        PlaceholderConfig   `json:"placeholder,omitempty"   yaml:"-"`
+	Welcome            WelcomeConfig       `json:"welcome,omitzero"        yaml:"-"`
+	Messages           ReplyTextConfig     `json:"messages,omitzero"       yaml:"-"`
+	Locale             string              `json:"locale,omitempty"        yaml:"-"`
 	Settings           RawNode             `json:"settings,omitzero"       yaml:"settings,omitempty"`
 	extend             any
 }
@@ -318,8 +318,14 @@ func (s *SecureString) UnmarshalText(text []byte) error {
 
 type SecureModelList []*ModelConfig
 
+// modelSecureData is the part of a model_list entry kept in the security file.
+type modelSecureData struct {
+	APIKeys           SecureStrings `yaml:"api_keys,omitempty"`
+	OAuthClientSecret *SecureString `yaml:"oauth_client_secret,omitempty"`
+}
+
 func (v *SecureModelList) UnmarshalYAML(value *yaml.Node) error {
-	mm := make(map[string]*ModelConfig)
+	mm := make(map[string]*modelSecureData)
 	if err := value.Decode(&mm); err != nil {
 		logger.Errorf("Decode error: %v", err)
 		return err
@@ -332,21 +338,23 @@ func (v *SecureModelList) UnmarshalYAML(value *yaml.Node) error {
 		}
 		if sec != nil {
 			m.APIKeys = sec.APIKeys
+			if sec.OAuthClientSecret != nil && m.OAuth != nil {
+				m.OAuth.ClientSecret = *sec.OAuthClientSecret
+			}
 		}
 	}
 	return nil
 }
 
 func (v SecureModelList) MarshalYAML() (any, error) {
-	type onlySecureData struct {
-		APIKeys SecureStrings `yaml:"api_keys,omitempty"`
-	}
-	mm := make(map[string]onlySecureData)
+	mm := make(map[string]modelSecureData)
 	nameList := toNameIndex(v)
 	for i, m := range v {
-		mm[nameList[i]] = onlySecureData{
-			APIKeys: m.APIKeys,
+		data := modelSecureData{APIKeys: m.APIKeys}
+		if m.OAuth != nil && m.OAuth.ClientSecret.String() != "" {
+			data.OAuthClientSecret = &m.OAuth.ClientSecret
 		}
+		mm[nameList[i]] = data
 	}
 
 	return mm, nil
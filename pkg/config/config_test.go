@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
@@ -2332,3 +2333,48 @@ func testChannelsConfigWithTokens() ChannelsConfig {
 	}
 	return channels
 }
+
+func TestOutputFilter_Validate(t *testing.T) {
+	valid := []OutputFilter{
+		{Type: OutputFilterRegex, Pattern: `secret-\w+`},
+		{Type: OutputFilterMaxLength, MaxLength: 2000},
+		{Type: OutputFilterPII},
+		{Type: OutputFilterPII, Kinds: []string{"email", "card"}},
+		{Type: OutputFilterWords, Words: []string{"darn"}},
+	}
+	for _, f := range valid {
+		if err := f.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", f, err)
+		}
+	}
+
+	invalid := []OutputFilter{
+		{Type: OutputFilterRegex},
+		{Type: OutputFilterRegex, Pattern: "("},
+		{Type: OutputFilterMaxLength},
+		{Type: OutputFilterPII, Kinds: []string{"ssn"}},
+		{Type: OutputFilterWords},
+		{Type: "shout"},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", f)
+		}
+	}
+}
+
+func TestToolsConfig_CallTimeout(t *testing.T) {
+	tools := ToolsConfig{
+		CallTimeoutSeconds: 600,
+		CallTimeouts:       map[string]int{"subagent": 1200, "web_fetch": 0},
+	}
+	if got := tools.CallTimeout("read_file"); got != 10*time.Minute {
+		t.Errorf("CallTimeout(read_file) = %s, want 10m", got)
+	}
+	if got := tools.CallTimeout("subagent"); got != 20*time.Minute {
+		t.Errorf("CallTimeout(subagent) = %s, want 20m", got)
+	}
+	if got := tools.CallTimeout("web_fetch"); got != 0 {
+		t.Errorf("CallTimeout(web_fetch) = %s, want 0 (override disables the limit)", got)
+	}
+}
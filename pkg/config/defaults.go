@@ -267,6 +267,8 @@ func DefaultConfig() *Config {
 		Tools: ToolsConfig{
 			FilterSensitiveData: true,
 			FilterMinLength:     8,
+			CallTimeoutSeconds:  600,
+			MaxResultChars:      60000,
 			MediaCleanup: MediaCleanupConfig{
 				ToolConfig: ToolConfig{
 					Enabled: true,
@@ -282,6 +284,7 @@ func DefaultConfig() *Config {
 				PreferNative:    true,
 				Proxy:           "",
 				FetchLimitBytes: 10 * 1024 * 1024, // 10MB by default
+				FetchMaxChars:   50000,
 				Format:          "plaintext",
 				Brave: BraveConfig{
 					Enabled:    false,
@@ -397,6 +400,9 @@ func DefaultConfig() *Config {
 			ListDir: ToolConfig{
 				Enabled: true,
 			},
+			Memory: ToolConfig{
+				Enabled: true,
+			},
 			Message: ToolConfig{
 				Enabled: true,
 			},
@@ -417,9 +423,37 @@ func DefaultConfig() *Config {
 			Subagent: ToolConfig{
 				Enabled: true,
 			},
+			TailFile: ToolConfig{
+				Enabled: true,
+			},
+			Grep: ToolConfig{
+				Enabled: true,
+			},
+			Diff: ToolConfig{
+				Enabled: true,
+			},
+			MCPAdmin: ToolConfig{
+				Enabled: false, // Lets the model restart MCP servers; opt in
+			},
+			Model: ToolConfig{
+				Enabled: false, // Lets the model pick a costlier model; opt in
+			},
+			Project: ToolConfig{
+				Enabled: true,
+			},
+			Encode: ToolConfig{
+				Enabled: true,
+			},
 			WebFetch: ToolConfig{
 				Enabled: true,
 			},
+			HTTPRequest: ToolConfig{
+				Enabled: false, // Arbitrary API calls with bodies; opt in
+			},
+			ImageGen: ImageGenToolConfig{
+				ToolConfig:        ToolConfig{Enabled: false}, // Paid per image; opt in
+				MaxPerUserPerHour: 10,
+			},
 			WriteFile: ToolConfig{
 				Enabled: true,
 			},
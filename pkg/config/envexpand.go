@@ -0,0 +1,144 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// reEnvRef matches "$${" (an escaped reference) and "${NAME}".
+var reEnvRef = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnvRefs replaces ${VAR} references in s with the value of the
+// environment variable. Any other "$" is kept, so header values, passwords
+// and prices need no escaping; "$${" yields a literal "${". A reference to
+// an undefined variable is kept as written and logged with the field it
+// was found in.
+func expandEnvRefs(s, field string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return reEnvRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		name := ref[2 : len(ref)-1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			logger.WarnF("config references undefined environment variable",
+				map[string]any{"field": field, "var": name})
+			return ref
+		}
+		return value
+	})
+}
+
+// Environment references are resolved where a value is used, on copies of
+// the loaded config, never on the loaded config itself: the launcher loads,
+// edits and saves config.json, and must write the ${VAR} text back rather
+// than the values it stands for.
+
+// WithEnvExpanded returns a copy of m with environment references in
+// api_base, proxy and custom_headers expanded. Calling it on a copy it
+// returned is a no-op, so "$${" is never expanded twice.
+func (m *ModelConfig) WithEnvExpanded() *ModelConfig {
+	if m == nil || m.envExpanded {
+		return m
+	}
+	out := *m
+	out.APIBase = expandEnvRefs(m.APIBase, "model_list."+m.ModelName+".api_base")
+	out.Proxy = expandEnvRefs(m.Proxy, "model_list."+m.ModelName+".proxy")
+	out.CustomHeaders = expandMapValues(m.CustomHeaders, "model_list."+m.ModelName+".custom_headers")
+	out.envExpanded = true
+	return &out
+}
+
+// WithEnvExpanded returns a copy of s, the MCP server called name, with
+// environment references in command, args, env, env_file, url and headers
+// expanded.
+func (s MCPServerConfig) WithEnvExpanded(name string) MCPServerConfig {
+	prefix := "tools.mcp.servers." + name
+	s.Command = expandEnvRefs(s.Command, prefix+".command")
+	s.EnvFile = expandEnvRefs(s.EnvFile, prefix+".env_file")
+	s.URL = expandEnvRefs(s.URL, prefix+".url")
+	if len(s.Args) > 0 {
+		args := make([]string, len(s.Args))
+		for i, arg := range s.Args {
+			args[i] = expandEnvRefs(arg, prefix+".args")
+		}
+		s.Args = args
+	}
+	s.Env = expandMapValues(s.Env, prefix+".env")
+	s.Headers = expandMapValues(s.Headers, prefix+".headers")
+	return s
+}
+
+// WithChannelEnvExpanded returns a shallow copy of c in which the settings
+// of the named channel have environment references expanded in fields
+// named url or ending in _url. c and its channels are left untouched.
+func (c *Config) WithChannelEnvExpanded(name string) *Config {
+	bc := c.Channels[name]
+	if bc == nil {
+		return c
+	}
+	decoded, err := bc.GetDecoded()
+	if err != nil || decoded == nil {
+		return c
+	}
+	v := reflect.ValueOf(decoded)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return c
+	}
+	settings := reflect.New(v.Elem().Type())
+	settings.Elem().Set(v.Elem())
+	expandURLFields(settings.Elem(), "channel_list."+name+".settings")
+
+	channel := *bc
+	channel.extend = settings.Interface()
+	out := *c
+	out.Channels = maps.Clone(c.Channels)
+	out.Channels[name] = &channel
+	return &out
+}
+
+func expandMapValues(m map[string]string, field string) map[string]string {
+	if len(m) == 0 {
+		return m
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = expandEnvRefs(v, field+"."+k)
+	}
+	return out
+}
+
+// expandURLFields expands string fields of the addressable settings struct
+// v whose JSON name is "url" or ends in "_url". Structs behind pointers are
+// copied before they are changed, so the settings v was copied from keep
+// their references.
+func expandURLFields(v reflect.Value, field string) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		fv := v.Field(i)
+		switch {
+		case fv.Kind() == reflect.String && (name == "url" || strings.HasSuffix(name, "_url")):
+			fv.SetString(expandEnvRefs(fv.String(), field+"."+name))
+		case fv.Kind() == reflect.Struct:
+			expandURLFields(fv, field+"."+name)
+		case fv.Kind() == reflect.Ptr && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct:
+			cp := reflect.New(fv.Elem().Type())
+			cp.Elem().Set(fv.Elem())
+			expandURLFields(cp.Elem(), field+"."+name)
+			fv.Set(cp)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnvRefs(t *testing.T) {
+	t.Setenv("PICO_TEST_HOST", "bridge.local")
+	t.Setenv("PICO_TEST_PORT", "3001")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"ws://${PICO_TEST_HOST}:${PICO_TEST_PORT}/ws", "ws://bridge.local:3001/ws"},
+		{"no refs", "no refs"},
+		{"price $5", "price $5"},
+		{"$PICO_TEST_HOST", "$PICO_TEST_HOST"},
+		{"pa$$w$PICO_TEST_PORT", "pa$$w$PICO_TEST_PORT"},
+		{"$${PICO_TEST_HOST}", "${PICO_TEST_HOST}"},
+		{"http://${PICO_TEST_UNDEFINED_VAR}/x", "http://${PICO_TEST_UNDEFINED_VAR}/x"},
+		{"trailing $", "trailing $"},
+		{"$1 stays", "$1 stays"},
+	}
+	for _, tt := range tests {
+		if got := expandEnvRefs(tt.in, "test"); got != tt.want {
+			t.Errorf("expandEnvRefs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfig_KeepsEnvRefsForSave(t *testing.T) {
+	t.Setenv("PICO_TEST_BRIDGE", "ws://bridge.local:3001")
+	t.Setenv("PICO_TEST_API", "https://llm.local/v1")
+	t.Setenv("PICO_TEST_BIN", "/opt/mcp/bin")
+	t.Setenv("PICO_TEST_ROUTE", "eu-west")
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	raw := `{
+  "version": 3,
+  "model_list": [{"model_name": "m", "model": "openai/gpt-4o", "api_base": "${PICO_TEST_API}",
+    "custom_headers": {"X-Route": "${PICO_TEST_ROUTE}", "X-Title": "PicoClaw", "X-Signature": "sha256=$PICO_TEST_ROUTE$1"}}],
+  "channel_list": {
+    "whatsapp": {"enabled": false, "type": "whatsapp", "settings": {"bridge_url": "${PICO_TEST_BRIDGE}/ws"}}
+  },
+  "tools": {"mcp": {"servers": {"fs": {
+    "enabled": true,
+    "command": "${PICO_TEST_BIN}/server",
+    "args": ["--root", "${PICO_TEST_BIN}", "$literal"],
+    "env": {"HOME_DIR": "${PICO_TEST_BIN}"}
+  }}}}
+}`
+	if err := os.WriteFile(configPath, []byte(raw), 0o600); err != nil {
+		t.Fatalf("WriteFile(configPath): %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+
+	model := cfg.ModelList[0]
+	if model.APIBase != "${PICO_TEST_API}" {
+		t.Errorf("loaded api_base = %q, want the reference kept", model.APIBase)
+	}
+	expanded := model.WithEnvExpanded()
+	if expanded.APIBase != "https://llm.local/v1" {
+		t.Errorf("api_base = %q", expanded.APIBase)
+	}
+	if got := expanded.CustomHeaders; got["X-Route"] != "eu-west" || got["X-Title"] != "PicoClaw" ||
+		got["X-Signature"] != "sha256=$PICO_TEST_ROUTE$1" {
+		t.Errorf("custom_headers = %v", got)
+	}
+	if model.CustomHeaders["X-Route"] != "${PICO_TEST_ROUTE}" {
+		t.Errorf("loaded custom_headers = %v, want the reference kept", model.CustomHeaders)
+	}
+
+	decoded, err := cfg.WithChannelEnvExpanded("whatsapp").Channels["whatsapp"].GetDecoded()
+	if err != nil {
+		t.Fatalf("GetDecoded() error: %v", err)
+	}
+	if got := decoded.(*WhatsAppSettings).BridgeURL; got != "ws://bridge.local:3001/ws" {
+		t.Errorf("bridge_url = %q", got)
+	}
+	original, _ := cfg.Channels["whatsapp"].GetDecoded()
+	if got := original.(*WhatsAppSettings).BridgeURL; got != "${PICO_TEST_BRIDGE}/ws" {
+		t.Errorf("loaded bridge_url = %q, want the reference kept", got)
+	}
+
+	srv := cfg.Tools.MCP.Servers["fs"].WithEnvExpanded("fs")
+	if srv.Command != "/opt/mcp/bin/server" {
+		t.Errorf("command = %q", srv.Command)
+	}
+	if srv.Args[1] != "/opt/mcp/bin" || srv.Args[2] != "$literal" {
+		t.Errorf("args = %q", srv.Args)
+	}
+	if srv.Env["HOME_DIR"] != "/opt/mcp/bin" {
+		t.Errorf("env = %v", srv.Env)
+	}
+	if cfg.Tools.MCP.Servers["fs"].Command != "${PICO_TEST_BIN}/server" {
+		t.Errorf("loaded command = %q, want the reference kept", cfg.Tools.MCP.Servers["fs"].Command)
+	}
+
+	// Saving what was loaded, as the launcher does after an edit, must
+	// write the references back rather than the values.
+	if err := SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error: %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile(configPath): %v", err)
+	}
+	for _, ref := range []string{"${PICO_TEST_API}", "${PICO_TEST_ROUTE}", "${PICO_TEST_BRIDGE}/ws", "${PICO_TEST_BIN}/server"} {
+		if !strings.Contains(string(data), ref) {
+			t.Errorf("saved config lost %q:\n%s", ref, data)
+		}
+	}
+	if strings.Contains(string(data), "llm.local") || strings.Contains(string(data), "eu-west") {
+		t.Errorf("saved config contains expanded values:\n%s", data)
+	}
+}
+
+func TestModelConfigWithEnvExpanded_ExpandsOnce(t *testing.T) {
+	t.Setenv("PICO_TEST_HOST", "llm.local")
+	m := &ModelConfig{ModelName: "m", APIBase: "https://$${PICO_TEST_HOST}/${PICO_TEST_HOST}"}
+	got := m.WithEnvExpanded().WithEnvExpanded().APIBase
+	if got != "https://${PICO_TEST_HOST}/llm.local" {
+		t.Errorf("api_base = %q", got)
+	}
+}
@@ -16,6 +16,24 @@ type GatewayConfig struct {
 	Port      int    `json:"port"                env:"PICOCLAW_GATEWAY_PORT"`
 	HotReload bool   `json:"hot_reload"          env:"PICOCLAW_GATEWAY_HOT_RELOAD"`
 	LogLevel  string `json:"log_level,omitempty" env:"PICOCLAW_LOG_LEVEL"`
+	// LogFormat selects console log output: "text" (default) or "json".
+	LogFormat string `json:"log_format,omitempty" env:"PICOCLAW_LOG_FORMAT"`
+	// ShutdownTimeout is how long, in seconds, shutdown waits for in-flight
+	// agent turns to finish before stopping channels. 0 uses the default (15s).
+	ShutdownTimeout int `json:"shutdown_timeout,omitempty" env:"PICOCLAW_GATEWAY_SHUTDOWN_TIMEOUT"`
+	// MirrorTo, when set, copies every outbound reply to one audit chat.
+	MirrorTo *MirrorTarget `json:"mirror_to,omitempty"`
+}
+
+// MirrorTarget names the chat that receives copies of outbound replies.
+type MirrorTarget struct {
+	Channel string `json:"channel"`
+	ChatID  string `json:"chat_id"`
+}
+
+// Enabled reports whether the target names a chat.
+func (t *MirrorTarget) Enabled() bool {
+	return t != nil && strings.TrimSpace(t.Channel) != "" && strings.TrimSpace(t.ChatID) != ""
 }
 
 func canonicalGatewayLogLevel(level logger.LogLevel) string {
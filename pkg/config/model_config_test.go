@@ -177,6 +177,35 @@ func TestModelConfig_Validate(t *testing.T) {
 			config:  ModelConfig{},
 			wantErr: true,
 		},
+		{
+			name: "oauth client credentials",
+			config: ModelConfig{
+				ModelName:  "test",
+				Model:      "openai/gpt-4o",
+				AuthMethod: "oauth",
+				OAuth:      &ModelOAuthConfig{TokenURL: "https://idp.example.com/token", ClientID: "picoclaw"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "oauth missing client_id",
+			config: ModelConfig{
+				ModelName:  "test",
+				Model:      "openai/gpt-4o",
+				AuthMethod: "oauth",
+				OAuth:      &ModelOAuthConfig{TokenURL: "https://idp.example.com/token"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "oauth without auth_method",
+			config: ModelConfig{
+				ModelName: "test",
+				Model:     "openai/gpt-4o",
+				OAuth:     &ModelOAuthConfig{TokenURL: "https://idp.example.com/token", ClientID: "picoclaw"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -244,6 +273,45 @@ func TestConfig_ValidateModelList(t *testing.T) {
 			},
 			wantErr: false, // Changed: duplicates are allowed for load balancing
 		},
+		{
+			name: "router without router section",
+			config: &Config{
+				ModelList: []*ModelConfig{{ModelName: "auto", Model: "router/auto"}},
+			},
+			wantErr: true,
+			errMsg:  "requires a router section",
+		},
+		{
+			name: "router default route not in routes",
+			config: &Config{
+				ModelList: []*ModelConfig{{
+					ModelName: "auto",
+					Model:     "router/auto",
+					Router: &ModelRouterConfig{
+						ClassifierModel: "small",
+						Routes:          map[string]string{"chat": "small"},
+						DefaultRoute:    "coding",
+					},
+				}},
+			},
+			wantErr: true,
+			errMsg:  "default_route",
+		},
+		{
+			name: "valid router",
+			config: &Config{
+				ModelList: []*ModelConfig{{
+					ModelName: "auto",
+					Model:     "router/auto",
+					Router: &ModelRouterConfig{
+						ClassifierModel: "small",
+						Routes:          map[string]string{"chat": "small", "coding": "large"},
+						DefaultRoute:    "chat",
+					},
+				}},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -357,3 +357,24 @@ func TestMergeAPIKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandMultiKeyModels_KeyPoolNotExpanded(t *testing.T) {
+	models := []*ModelConfig{
+		{
+			ModelName: "pooled",
+			Model:     "openai/gpt-4o",
+			APIKeys:   SimpleSecureStrings("key-1", "key-2"),
+			KeyPool:   true,
+		},
+	}
+
+	result := expandMultiKeyModels(models)
+
+	if len(result) != 1 {
+		t.Fatalf("expected 1 model, got %d", len(result))
+	}
+	if len(result[0].APIKeys) != 2 || len(result[0].Fallbacks) != 0 {
+		t.Errorf("expected both keys kept and no fallbacks, got %d keys, fallbacks %v",
+			len(result[0].APIKeys), result[0].Fallbacks)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -15,6 +16,16 @@ import (
 	"github.com/sipeed/picoclaw/pkg/fileutil"
 )
 
+// ErrTargetUnavailable is returned by a JobHandler when the job's target
+// channel is offline. The job is retried after deferRetryDelay instead of
+// being treated as run, for up to maxDeferral after it first came due.
+var ErrTargetUnavailable = errors.New("target channel unavailable")
+
+const (
+	deferRetryDelay = time.Minute
+	maxDeferral     = 24 * time.Hour
+)
+
 type CronSchedule struct {
 	Kind    string `json:"kind"`
 	AtMS    *int64 `json:"atMs,omitempty"`
@@ -36,6 +47,12 @@ type CronJobState struct {
 	LastRunAtMS *int64 `json:"lastRunAtMs,omitempty"`
 	LastStatus  string `json:"lastStatus,omitempty"`
 	LastError   string `json:"lastError,omitempty"`
+	// DeferredAtMS records when a run was first deferred because the target
+	// channel was unavailable; nil when no run is pending delivery.
+	DeferredAtMS *int64 `json:"deferredAtMs,omitempty"`
+	// RetryAtMS is when the deferred run is retried. It is kept apart from
+	// NextRunAtMS so a recurring job stays on its schedule meanwhile.
+	RetryAtMS *int64 `json:"retryAtMs,omitempty"`
 }
 
 type CronJob struct {
@@ -179,24 +196,29 @@ func (cs *CronService) checkJobs() {
 
 	now := time.Now().UnixMilli()
 	var dueJobIDs []string
+	// scheduled marks the due jobs whose scheduled run came due; the others
+	// are only retrying a deferred run.
+	scheduled := make(map[string]bool)
 
 	// Collect jobs that are due (we need to copy them to execute outside lock)
+	// and reset their due times before unlocking to avoid duplicate execution.
+	// A scheduled run that comes due while a retry is pending covers it.
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
-		if job.Enabled && job.State.NextRunAtMS != nil && *job.State.NextRunAtMS <= now {
-			dueJobIDs = append(dueJobIDs, job.ID)
+		if !job.Enabled {
+			continue
 		}
-	}
-
-	// Reset next run for due jobs before unlocking to avoid duplicate execution.
-	dueMap := make(map[string]bool, len(dueJobIDs))
-	for _, jobID := range dueJobIDs {
-		dueMap[jobID] = true
-	}
-	for i := range cs.store.Jobs {
-		if dueMap[cs.store.Jobs[i].ID] {
-			cs.store.Jobs[i].State.NextRunAtMS = nil
+		runDue := job.State.NextRunAtMS != nil && *job.State.NextRunAtMS <= now
+		retryDue := job.State.RetryAtMS != nil && *job.State.RetryAtMS <= now
+		if !runDue && !retryDue {
+			continue
+		}
+		dueJobIDs = append(dueJobIDs, job.ID)
+		if runDue {
+			scheduled[job.ID] = true
+			job.State.NextRunAtMS = nil
 		}
+		job.State.RetryAtMS = nil
 	}
 
 	if err := cs.saveStoreUnsafe(); err != nil {
@@ -207,11 +229,14 @@ func (cs *CronService) checkJobs() {
 
 	// Execute jobs outside lock.
 	for _, jobID := range dueJobIDs {
-		cs.executeJobByID(jobID)
+		cs.executeJobByID(jobID, scheduled[jobID])
 	}
 }
 
-func (cs *CronService) executeJobByID(jobID string) {
+// executeJobByID runs a job and updates its state. scheduled is false when
+// the run only retries a deferred one, so a recurring job's next scheduled
+// run is left as it is.
+func (cs *CronService) executeJobByID(jobID string, scheduled bool) {
 	startTime := time.Now().UnixMilli()
 
 	cs.mu.RLock()
@@ -258,9 +283,34 @@ func (cs *CronService) executeJobByID(jobID string) {
 		return
 	}
 
-	job.State.LastRunAtMS = &startTime
 	job.UpdatedAtMS = time.Now().UnixMilli()
 
+	if errors.Is(err, ErrTargetUnavailable) {
+		if job.State.DeferredAtMS == nil {
+			job.State.DeferredAtMS = &startTime
+		}
+		if time.Duration(startTime-*job.State.DeferredAtMS)*time.Millisecond < maxDeferral {
+			retryAt := time.Now().Add(deferRetryDelay).UnixMilli()
+			job.State.RetryAtMS = &retryAt
+			// Only this run moves; a recurring job keeps its schedule.
+			if scheduled && job.Schedule.Kind != "at" {
+				job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
+			}
+			job.State.LastStatus = "deferred"
+			job.State.LastError = err.Error()
+			log.Printf("[cron] ⏸ job '%s' deferred: %v, retrying at %s",
+				job.Name, err, time.UnixMilli(retryAt).Format("2006-01-02 15:04:05"))
+			if err := cs.saveStoreUnsafe(); err != nil {
+				log.Printf("[cron] failed to save store: %v", err)
+			}
+			return
+		}
+		err = fmt.Errorf("%w for more than %s, giving up", err, maxDeferral)
+	}
+	job.State.DeferredAtMS = nil
+	job.State.RetryAtMS = nil
+	job.State.LastRunAtMS = &startTime
+
 	if err != nil {
 		job.State.LastStatus = "error"
 		job.State.LastError = err.Error()
@@ -282,8 +332,11 @@ func (cs *CronService) executeJobByID(jobID string) {
 			nextRunStr = "(disabled)"
 		}
 	} else {
-		nextRun := cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
-		job.State.NextRunAtMS = nextRun
+		nextRun := job.State.NextRunAtMS
+		if scheduled {
+			nextRun = cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
+			job.State.NextRunAtMS = nextRun
+		}
 		if nextRun != nil {
 			nextRunStr = time.UnixMilli(*nextRun).Format("2006-01-02 15:04:05")
 		} else {
@@ -347,8 +400,14 @@ func (cs *CronService) recomputeNextRuns() {
 	now := time.Now().UnixMilli()
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
-		if job.Enabled {
-			job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+		if !job.Enabled {
+			continue
+		}
+		job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+		if job.State.DeferredAtMS != nil {
+			// A deferred run is still owed; retry it right away.
+			retryAt := now
+			job.State.RetryAtMS = &retryAt
 		}
 	}
 }
@@ -356,9 +415,12 @@ func (cs *CronService) recomputeNextRuns() {
 func (cs *CronService) getNextWakeMS() *int64 {
 	var nextWake *int64
 	for _, job := range cs.store.Jobs {
-		if job.Enabled && job.State.NextRunAtMS != nil {
-			if nextWake == nil || *job.State.NextRunAtMS < *nextWake {
-				nextWake = job.State.NextRunAtMS
+		if !job.Enabled {
+			continue
+		}
+		for _, at := range []*int64{job.State.NextRunAtMS, job.State.RetryAtMS} {
+			if at != nil && (nextWake == nil || *at < *nextWake) {
+				nextWake = at
 			}
 		}
 	}
@@ -507,6 +569,8 @@ func (cs *CronService) EnableJob(jobID string, enabled bool) *CronJob {
 				job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
 			} else {
 				job.State.NextRunAtMS = nil
+				job.State.RetryAtMS = nil
+				job.State.DeferredAtMS = nil
 			}
 
 			if err := cs.saveStoreUnsafe(); err != nil {
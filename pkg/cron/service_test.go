@@ -235,3 +235,133 @@ func TestCronService_ConcurrentAccess(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestCronService_DefersWhenTargetUnavailable(t *testing.T) {
+	online := false
+	handler := func(job *CronJob) (string, error) {
+		if !online {
+			return "", ErrTargetUnavailable
+		}
+		return "ok", nil
+	}
+
+	cs, path := setupService(handler)
+	defer os.Remove(path)
+
+	at := time.Now().Add(time.Hour).UnixMilli()
+	job, err := cs.AddJob("Remind", CronSchedule{Kind: "at", AtMS: &at}, "ping", "telegram", "42")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	before := time.Now()
+	cs.executeJobByID(job.ID, true)
+
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 {
+		t.Fatalf("deferred job should be kept, got %d jobs", len(jobs))
+	}
+	state := jobs[0].State
+	if !jobs[0].Enabled || state.LastStatus != "deferred" || state.DeferredAtMS == nil {
+		t.Fatalf("unexpected state after deferral: enabled=%v state=%+v", jobs[0].Enabled, state)
+	}
+	if state.LastRunAtMS != nil {
+		t.Error("deferred run must not be recorded as a run")
+	}
+	if state.RetryAtMS == nil || *state.RetryAtMS < before.Add(deferRetryDelay).UnixMilli() {
+		t.Errorf("retry should be scheduled after %s, got %v", deferRetryDelay, state.RetryAtMS)
+	}
+
+	online = true
+	cs.executeJobByID(job.ID, false)
+
+	if n := len(cs.ListJobs(true)); n != 0 {
+		t.Errorf("job should be deleted after a delivered run, got %d jobs", n)
+	}
+}
+
+func TestCronService_GivesUpAfterMaxDeferral(t *testing.T) {
+	handler := func(job *CronJob) (string, error) {
+		return "", ErrTargetUnavailable
+	}
+
+	cs, path := setupService(handler)
+	defer os.Remove(path)
+
+	job, err := cs.AddJob("Tick", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "ping", "telegram", "42")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	longAgo := time.Now().Add(-maxDeferral - time.Minute).UnixMilli()
+	cs.store.Jobs[0].State.DeferredAtMS = &longAgo
+
+	cs.executeJobByID(job.ID, false)
+
+	state := cs.ListJobs(true)[0].State
+	if state.LastStatus != "error" || state.DeferredAtMS != nil || state.LastRunAtMS == nil {
+		t.Fatalf("expected the run to be abandoned as an error, got %+v", state)
+	}
+}
+
+func TestCronService_StartRetriesDeferredJobs(t *testing.T) {
+	cs, path := setupService(nil)
+	defer os.Remove(path)
+
+	past := time.Now().Add(-time.Hour).UnixMilli()
+	job, err := cs.AddJob("Missed", CronSchedule{Kind: "at", AtMS: &past}, "ping", "telegram", "42")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	cs.store.Jobs[0].State.DeferredAtMS = &past
+
+	cs.mu.Lock()
+	cs.recomputeNextRuns()
+	cs.mu.Unlock()
+
+	got := cs.ListJobs(true)[0]
+	if got.ID != job.ID || got.State.RetryAtMS == nil {
+		t.Fatal("deferred one-shot job should be retried on start")
+	}
+}
+
+func TestCronService_DeferralKeepsRecurringSchedule(t *testing.T) {
+	online := false
+	handler := func(job *CronJob) (string, error) {
+		if !online {
+			return "", ErrTargetUnavailable
+		}
+		return "ok", nil
+	}
+
+	cs, path := setupService(handler)
+	defer os.Remove(path)
+
+	job, err := cs.AddJob("Hourly", CronSchedule{Kind: "every", EveryMS: int64Ptr(time.Hour.Milliseconds())},
+		"ping", "telegram", "42")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	before := time.Now()
+	cs.executeJobByID(job.ID, true)
+
+	state := cs.ListJobs(true)[0].State
+	if state.RetryAtMS == nil || *state.RetryAtMS > before.Add(time.Hour).UnixMilli() {
+		t.Fatalf("expected a retry within the hour, got %v", state.RetryAtMS)
+	}
+	if state.NextRunAtMS == nil || *state.NextRunAtMS < before.Add(time.Hour).UnixMilli() {
+		t.Fatalf("the next scheduled run should stay an hour out, got %v", state.NextRunAtMS)
+	}
+	nextRun := *state.NextRunAtMS
+
+	online = true
+	cs.executeJobByID(job.ID, false)
+
+	state = cs.ListJobs(true)[0].State
+	if state.LastStatus != "ok" || state.RetryAtMS != nil || state.DeferredAtMS != nil {
+		t.Fatalf("expected the retried run to be delivered, got %+v", state)
+	}
+	if state.NextRunAtMS == nil || *state.NextRunAtMS != nextRun {
+		t.Errorf("a retry must not move the schedule: next run %v, want %d", state.NextRunAtMS, nextRun)
+	}
+}
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -69,11 +70,22 @@ type services struct {
 	DeviceService    *devices.Service
 	HealthServer     *health.Server
 	VoiceAgentCancel context.CancelFunc
+	ReplyMirror      *bus.Subscription[bus.OutboundMessage]
 	manualReloadChan chan struct{}
 	reloading        atomic.Bool
 	authToken        string
 }
 
+// channelAvailable reports whether the named channel is started and able to
+// send. Before the channel manager exists every channel is treated as online.
+func (s *services) channelAvailable(channel string) bool {
+	if s.ChannelManager == nil {
+		return true
+	}
+	ch, ok := s.ChannelManager.GetChannel(channel)
+	return ok && ch.IsRunning()
+}
+
 type startupBlockedProvider struct {
 	reason string
 }
@@ -127,6 +139,7 @@ func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) (runEr
 	}
 	defer logger.DisableFileLogging()
 
+	logger.SetFormat(os.Getenv("PICOCLAW_LOG_FORMAT"))
 	if debug {
 		logger.SetLevel(logger.DEBUG)
 	} else {
@@ -152,6 +165,7 @@ func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) (runEr
 	if err = preCheckConfig(cfg); err != nil {
 		return fmt.Errorf("config pre-check failed: %w", err)
 	}
+	logger.SetFormat(cfg.Gateway.LogFormat)
 
 	// Debug mode permanently overrides the config log level to DEBUG.
 	if debug {
@@ -237,6 +251,7 @@ func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) (runEr
 	}
 	runningServices.HealthServer.SetReloadFunc(reloadTrigger)
 	agentLoop.SetReloadFunc(reloadTrigger)
+	agentLoop.SetConfigPath(configPath)
 
 	for _, bindHost := range listenResult.BindHosts {
 		fmt.Printf("✓ Gateway started on %s\n", net.JoinHostPort(bindHost, strconv.Itoa(cfg.Gateway.Port)))
@@ -356,6 +371,7 @@ func setupAndStartServices(
 		cfg.Agents.Defaults.RestrictToWorkspace,
 		execTimeout,
 		cfg,
+		runningServices.channelAvailable,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error setting up cron service: %w", err)
@@ -416,6 +432,48 @@ func setupAndStartServices(
 
 	runningServices.authToken = authToken
 	runningServices.HealthServer = health.NewServer(listenResult.ProbeHost, cfg.Gateway.Port, authToken)
+	runningServices.HealthServer.SetStatsFunc("agent_turns", func() any { return agentLoop.TurnStats() })
+	runningServices.HealthServer.SetStatsFunc("sessions", func() any { return agentLoop.SessionStats() })
+	runningServices.HealthServer.SetAdminStatsFunc("channels", func() any {
+		return channelStats(agentLoop.ChannelMessageCounts(), runningServices.ChannelManager.SendStats())
+	})
+	runningServices.HealthServer.SetAdminStatsFunc("providers", func() any { return agentLoop.ProviderStats() })
+	runningServices.HealthServer.SetAdminStatsFunc("mcp", func() any { return agentLoop.MCPServerStatus() })
+	runningServices.HealthServer.SetAdminStatsFunc("tools", func() any { return agentLoop.ToolConcurrency() })
+	runningServices.HealthServer.SetAdminStatsFunc("bus", func() any { return msgBus.Stats() })
+	runningServices.HealthServer.SetSessionFuncs(
+		func() any { return agentLoop.ListSessions() },
+		func(ctx context.Context, key string) error {
+			err := agentLoop.ResetSession(ctx, key)
+			switch {
+			case errors.Is(err, agent.ErrSessionBusy):
+				return fmt.Errorf("%w: %w", health.ErrSessionBusy, err)
+			case errors.Is(err, agent.ErrSessionNotFound):
+				return fmt.Errorf("%w: %w", health.ErrSessionNotFound, err)
+			}
+			return err
+		},
+	)
+	runningServices.HealthServer.SetSessionExportFunc(func(key, format string, includeTools bool) ([]byte, error) {
+		body, err := agentLoop.ExportSession(key, format, includeTools)
+		if errors.Is(err, agent.ErrSessionNotFound) {
+			return nil, fmt.Errorf("%w: %w", health.ErrSessionNotFound, err)
+		}
+		return body, err
+	})
+	runningServices.HealthServer.SetPushFunc(func(ctx context.Context, req health.PushRequest) error {
+		ch, ok := runningServices.ChannelManager.GetChannel(req.Channel)
+		if !ok {
+			return fmt.Errorf("%w: %s", health.ErrPushChannelNotFound, req.Channel)
+		}
+		if !ch.IsRunning() {
+			return fmt.Errorf("%w: %s", health.ErrPushChannelUnavailable, req.Channel)
+		}
+		return msgBus.PublishOutbound(ctx, bus.OutboundMessage{
+			Context: bus.NewOutboundContext(req.Channel, req.ChatID, ""),
+			Content: req.Message,
+		})
+	})
 
 	var listenAddr string
 	if len(listenResult.Listeners) > 0 {
@@ -432,6 +490,7 @@ func setupAndStartServices(
 	if err = runningServices.ChannelManager.StartAll(context.Background()); err != nil {
 		return nil, fmt.Errorf("error starting channels: %w", err)
 	}
+	runningServices.startReplyMirror(msgBus, cfg.Gateway.MirrorTo)
 
 	logChannelVoiceCapabilities(runningServices.ChannelManager, transcriber != nil, ttsAvailable)
 
@@ -464,6 +523,30 @@ func setupAndStartServices(
 	return runningServices, nil
 }
 
+// channelTraffic is one channel's entry under "channels" on GET /stats.
+type channelTraffic struct {
+	Received uint64 `json:"received"`
+	Sent     uint64 `json:"sent"`
+	Failed   uint64 `json:"send_failed"`
+}
+
+// channelStats merges inbound counts from the agent loop with outbound
+// delivery counts from the channel manager.
+func channelStats(received map[string]uint64, sent map[string]channels.SendStats) map[string]channelTraffic {
+	stats := make(map[string]channelTraffic, len(received))
+	for name, n := range received {
+		t := stats[name]
+		t.Received = n
+		stats[name] = t
+	}
+	for name, s := range sent {
+		t := stats[name]
+		t.Sent, t.Failed = s.Sent, s.Failed
+		stats[name] = t
+	}
+	return stats
+}
+
 func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Duration, isReload bool) {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
@@ -472,6 +555,9 @@ func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Dura
 	if !isReload && runningServices.ChannelManager != nil {
 		runningServices.ChannelManager.StopAll(shutdownCtx)
 	}
+	if !isReload && runningServices.ReplyMirror != nil {
+		runningServices.ReplyMirror.Unsubscribe()
+	}
 	if runningServices.VoiceAgentCancel != nil {
 		runningServices.VoiceAgentCancel()
 	}
@@ -497,13 +583,24 @@ func shutdownGateway(
 	provider providers.LLMProvider,
 	fullShutdown bool,
 ) {
-	if cp, ok := provider.(providers.StatefulProvider); ok && fullShutdown {
-		cp.Close()
+	// Stop taking new messages and let in-flight turns finish while channels
+	// are still up to deliver their replies.
+	drainTimeout := gracefulShutdownTimeout
+	if cfg := agentLoop.GetConfig(); cfg != nil && cfg.Gateway.ShutdownTimeout > 0 {
+		drainTimeout = time.Duration(cfg.Gateway.ShutdownTimeout) * time.Second
+	}
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), drainTimeout)
+	if err := agentLoop.Drain(drainCtx); err == nil {
+		logger.Info("✓ In-flight agent turns drained")
 	}
+	drainCancel()
 
 	stopAndCleanupServices(runningServices, gracefulShutdownTimeout, false)
 
-	agentLoop.Stop()
+	if cp, ok := provider.(providers.StatefulProvider); ok && fullShutdown {
+		cp.Close()
+	}
+
 	agentLoop.Close()
 
 	logger.Info("✓ Gateway stopped")
@@ -567,6 +664,8 @@ func handleConfigReload(
 
 	logger.Info("  ✓ Provider, configuration, and services reloaded successfully (thread-safe)")
 
+	logger.SetFormat(newCfg.Gateway.LogFormat)
+
 	// Debug mode permanently overrides the config log level to DEBUG.
 	if !debug {
 		// Update log level last so that reload-related info/warn logs above are not suppressed.
@@ -594,6 +693,7 @@ func restartServices(
 		cfg.Agents.Defaults.RestrictToWorkspace,
 		execTimeout,
 		cfg,
+		runningServices.channelAvailable,
 	)
 	if err != nil {
 		return fmt.Errorf("error restarting cron service: %w", err)
@@ -631,6 +731,7 @@ func restartServices(
 		return fmt.Errorf("error reload channels: %w", err)
 	}
 	fmt.Println("  ✓ Channels restarted.")
+	runningServices.startReplyMirror(msgBus, cfg.Gateway.MirrorTo)
 
 	enabledChannels := runningServices.ChannelManager.GetEnabledChannels()
 	if len(enabledChannels) > 0 {
@@ -762,6 +863,7 @@ func setupCronTool(
 	restrict bool,
 	execTimeout time.Duration,
 	cfg *config.Config,
+	channelAvailable func(channel string) bool,
 ) (*cron.CronService, error) {
 	cronStorePath := filepath.Join(workspace, "cron", "jobs.json")
 
@@ -775,11 +877,15 @@ func setupCronTool(
 			return nil, fmt.Errorf("critical error during CronTool initialization: %w", err)
 		}
 
+		cronTool.SetChannelChecker(channelAvailable)
 		agentLoop.RegisterTool(cronTool)
 	}
 
 	if cronTool != nil {
 		cronService.SetOnJob(func(job *cron.CronJob) (string, error) {
+			if err := cronTool.CheckTarget(job); err != nil {
+				return "", err
+			}
 			result := cronTool.ExecuteJob(context.Background(), job)
 			return result, nil
 		})
@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const mirrorPublishTimeout = 10 * time.Second
+
+// startReplyMirror replaces any running reply mirror with one for target.
+// A nil or incomplete target just stops the old one.
+func (s *services) startReplyMirror(msgBus *bus.MessageBus, target *config.MirrorTarget) {
+	if s.ReplyMirror != nil {
+		s.ReplyMirror.Unsubscribe()
+		s.ReplyMirror = nil
+	}
+	if !target.Enabled() {
+		return
+	}
+
+	// Drop rather than block: an audit copy must never hold up real replies.
+	sub, err := msgBus.SubscribeOutbound("mirror", bus.SubscribeOptions{Overflow: bus.OverflowDrop})
+	if err != nil {
+		logger.WarnCF("gateway", "Reply mirror not started", map[string]any{"error": err.Error()})
+		return
+	}
+	s.ReplyMirror = sub
+
+	mirror := config.MirrorTarget{
+		Channel: strings.TrimSpace(target.Channel),
+		ChatID:  strings.TrimSpace(target.ChatID),
+	}
+	go func() {
+		for msg := range sub.C() {
+			copied, ok := mirrorMessage(msg, mirror)
+			if !ok {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), mirrorPublishTimeout)
+			err := msgBus.PublishOutbound(ctx, copied)
+			cancel()
+			if errors.Is(err, bus.ErrBusClosed) {
+				return
+			}
+			if err != nil {
+				logger.WarnCF("gateway", "Failed to mirror reply", map[string]any{
+					"channel": msg.Channel,
+					"chat_id": msg.ChatID,
+					"error":   err.Error(),
+				})
+			}
+		}
+	}()
+	logger.InfoCF("gateway", "Mirroring replies", map[string]any{
+		"channel": mirror.Channel,
+		"chat_id": mirror.ChatID,
+	})
+}
+
+// mirrorMessage builds the audit copy of msg. Messages already addressed to
+// the mirror chat are skipped, which covers the copies themselves and keeps
+// a mirror that is also a live conversation from echoing forever.
+func mirrorMessage(msg bus.OutboundMessage, target config.MirrorTarget) (bus.OutboundMessage, bool) {
+	msg = bus.NormalizeOutboundMessage(msg)
+	if msg.Content == "" {
+		return bus.OutboundMessage{}, false
+	}
+	if msg.Channel == target.Channel && msg.ChatID == target.ChatID {
+		return bus.OutboundMessage{}, false
+	}
+
+	header := fmt.Sprintf("[%s:%s", msg.Channel, msg.ChatID)
+	if msg.AgentID != "" {
+		header += " · agent " + msg.AgentID
+	}
+	header += "]"
+	return bus.NormalizeOutboundMessage(bus.OutboundMessage{
+		Context: bus.NewOutboundContext(target.Channel, target.ChatID, ""),
+		Content: header + "\n" + msg.Content,
+	}), true
+}
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestMirrorMessage(t *testing.T) {
+	target := config.MirrorTarget{Channel: "telegram", ChatID: "-100audit"}
+
+	copied, ok := mirrorMessage(bus.OutboundMessage{
+		Context: bus.NewOutboundContext("slack", "C123", "m1"),
+		AgentID: "main",
+		Content: "hello",
+	}, target)
+	if !ok {
+		t.Fatal("reply to another chat was not mirrored")
+	}
+	if copied.Channel != "telegram" || copied.ChatID != "-100audit" {
+		t.Fatalf("copy addressed to %s:%s, want telegram:-100audit", copied.Channel, copied.ChatID)
+	}
+	if copied.Context.ReplyToMessageID != "" {
+		t.Fatalf("copy kept reply target %q", copied.Context.ReplyToMessageID)
+	}
+	if want := "[slack:C123 · agent main]\nhello"; copied.Content != want {
+		t.Fatalf("Content = %q, want %q", copied.Content, want)
+	}
+
+	if _, ok := mirrorMessage(bus.OutboundMessage{
+		Context: bus.NewOutboundContext("telegram", "-100audit", ""),
+		Content: "already in the audit chat",
+	}, target); ok {
+		t.Fatal("message addressed to the mirror chat was mirrored")
+	}
+	if _, ok := mirrorMessage(bus.OutboundMessage{
+		Context: bus.NewOutboundContext("telegram", "42", ""),
+	}, target); ok {
+		t.Fatal("empty message was mirrored")
+	}
+}
+
+func TestStartReplyMirror_CopiesOnceWithoutLooping(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+
+	s := &services{}
+	s.startReplyMirror(msgBus, &config.MirrorTarget{Channel: "telegram", ChatID: "audit"})
+	if s.ReplyMirror == nil {
+		t.Fatal("mirror not started")
+	}
+	defer s.ReplyMirror.Unsubscribe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := msgBus.PublishOutbound(ctx, bus.OutboundMessage{
+		Context: bus.NewOutboundContext("discord", "chan1", ""),
+		Content: "reply",
+	}); err != nil {
+		t.Fatalf("PublishOutbound() error = %v", err)
+	}
+
+	var got []bus.OutboundMessage
+	for len(got) < 2 {
+		select {
+		case msg := <-msgBus.OutboundChan():
+			got = append(got, msg)
+		case <-ctx.Done():
+			t.Fatalf("received %d outbound messages, want 2", len(got))
+		}
+	}
+	if got[1].Channel != "telegram" || got[1].ChatID != "audit" || got[1].Content != "[discord:chan1]\nreply" {
+		t.Fatalf("mirror copy = %+v", got[1])
+	}
+
+	select {
+	case msg := <-msgBus.OutboundChan():
+		t.Fatalf("unexpected extra outbound message %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestStartReplyMirror_DisabledStopsPrevious(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+
+	s := &services{}
+	s.startReplyMirror(msgBus, &config.MirrorTarget{Channel: "telegram", ChatID: "audit"})
+	old := s.ReplyMirror
+	s.startReplyMirror(msgBus, nil)
+	if s.ReplyMirror != nil {
+		t.Fatal("mirror still set after disabling")
+	}
+	if _, open := <-old.C(); open {
+		t.Fatal("old subscription still open")
+	}
+}
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	maxPushBodyBytes = 64 << 10
+	maxPushChatIDLen = 256
+
+	// pushRate and pushBurst bound how fast /push accepts messages: bursts
+	// of alerts get through, a runaway script does not flood a chat.
+	pushRate  = rate.Limit(1)
+	pushBurst = 10
+)
+
+var (
+	// ErrPushChannelNotFound tells /push to answer 404 Not Found.
+	ErrPushChannelNotFound = errors.New("channel not found")
+	// ErrPushChannelUnavailable tells /push to answer 503 Service Unavailable.
+	ErrPushChannelUnavailable = errors.New("channel not running")
+)
+
+// PushRequest is the body of POST /push.
+type PushRequest struct {
+	Channel string `json:"channel"`
+	ChatID  string `json:"chat_id"`
+	Message string `json:"message"`
+}
+
+// SetPushFunc enables POST /push, which sends a message to a chat without a
+// prompt from a user. push may return ErrPushChannelNotFound or
+// ErrPushChannelUnavailable (optionally wrapped).
+func (s *Server) SetPushFunc(push func(ctx context.Context, req PushRequest) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.push = push
+	if s.pushLimiter == nil {
+		s.pushLimiter = rate.NewLimiter(pushRate, pushBurst)
+	}
+}
+
+func (s *Server) pushHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed, use POST"})
+		return
+	}
+
+	s.mu.RLock()
+	push := s.push
+	limiter := s.pushLimiter
+	s.mu.RUnlock()
+
+	// Unlike the other admin endpoints, /push is never open: it lets the
+	// caller speak as the bot.
+	if !s.authorizeRequired(w, r, "push requires a gateway token") {
+		return
+	}
+	if push == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "push not configured"})
+		return
+	}
+	if reservation := limiter.Reserve(); reservation.Delay() > 0 {
+		reservation.Cancel()
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(reservation.Delay().Seconds()))))
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "rate limited"})
+		return
+	}
+
+	var req PushRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPushBodyBytes)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	if err := push(ctx, req); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrPushChannelNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrPushChannelUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued", "channel": req.Channel, "chat_id": req.ChatID})
+}
+
+// validate checks the fields are present and the chat ID looks like one:
+// no surrounding whitespace, no control characters, and a sane length.
+func (req *PushRequest) validate() error {
+	switch {
+	case strings.TrimSpace(req.Channel) == "":
+		return errors.New("channel is required")
+	case req.ChatID == "":
+		return errors.New("chat_id is required")
+	case strings.TrimSpace(req.Message) == "":
+		return errors.New("message is required")
+	case len(req.ChatID) > maxPushChatIDLen || strings.TrimSpace(req.ChatID) != req.ChatID:
+		return errors.New("chat_id is not a valid chat ID")
+	}
+	for _, r := range req.ChatID {
+		if unicode.IsControl(r) {
+			return errors.New("chat_id is not a valid chat ID")
+		}
+	}
+	return nil
+}
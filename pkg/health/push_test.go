@@ -0,0 +1,82 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func doPush(s *Server, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/push", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.pushHandler(w, req)
+	return w
+}
+
+func TestPushHandler_ValidatesAndDelivers(t *testing.T) {
+	s := newTestServer()
+	var got []PushRequest
+	s.SetPushFunc(func(_ context.Context, req PushRequest) error {
+		if req.Channel == "irc" {
+			return fmt.Errorf("%w: irc", ErrPushChannelNotFound)
+		}
+		got = append(got, req)
+		return nil
+	})
+
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		want  int
+	}{
+		{"no token", "", `{"channel":"telegram","chat_id":"42","message":"hi"}`, http.StatusUnauthorized},
+		{"wrong token", "nope", `{"channel":"telegram","chat_id":"42","message":"hi"}`, http.StatusUnauthorized},
+		{"bad json", "test", `{"channel":`, http.StatusBadRequest},
+		{"missing message", "test", `{"channel":"telegram","chat_id":"42"}`, http.StatusBadRequest},
+		{"control char in chat", "test", `{"channel":"telegram","chat_id":"42\n43","message":"hi"}`, http.StatusBadRequest},
+		{"padded chat", "test", `{"channel":"telegram","chat_id":" 42","message":"hi"}`, http.StatusBadRequest},
+		{"unknown channel", "test", `{"channel":"irc","chat_id":"#ops","message":"hi"}`, http.StatusNotFound},
+		{"ok", "test", `{"channel":"telegram","chat_id":"-100123","message":"disk is full"}`, http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		if w := doPush(s, tt.token, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body.String())
+		}
+	}
+	if len(got) != 1 || got[0].ChatID != "-100123" || got[0].Message != "disk is full" {
+		t.Fatalf("delivered = %+v, want only the valid request", got)
+	}
+}
+
+func TestPushHandler_RequiresConfiguredToken(t *testing.T) {
+	s := newTestServer()
+	s.authToken = ""
+	s.SetPushFunc(func(context.Context, PushRequest) error { return nil })
+
+	if w := doPush(s, "", `{"channel":"telegram","chat_id":"42","message":"hi"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d when the gateway has no token", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestPushHandler_RateLimits(t *testing.T) {
+	s := newTestServer()
+	s.SetPushFunc(func(context.Context, PushRequest) error { return nil })
+
+	body := `{"channel":"telegram","chat_id":"42","message":"hi"}`
+	for i := range pushBurst {
+		if w := doPush(s, "test", body); w.Code != http.StatusAccepted {
+			t.Fatalf("request %d status = %d, want %d", i, w.Code, http.StatusAccepted)
+		}
+	}
+	w := doPush(s, "test", body)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("status = %d, Retry-After = %q; want 429 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"strconv"
 	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 type Server struct {
@@ -20,7 +22,18 @@ type Server struct {
 	checks     map[string]Check
 	startTime  time.Time
 	reloadFunc func() error
-	authToken  string // optional bearer token for protected endpoints
+	statsFuncs map[string]func() any
+	// Session admin callbacks, see SetSessionFuncs.
+	listSessions func() any
+	resetSession func(ctx context.Context, key string) error
+	// Session export callback, see SetSessionExportFunc.
+	exportSession func(key, format string, includeTools bool) ([]byte, error)
+	authToken     string // optional bearer token for protected endpoints
+	// Proactive message callback and its rate limit, see SetPushFunc.
+	push        func(ctx context.Context, req PushRequest) error
+	pushLimiter *rate.Limiter
+	// Values reported only on the protected /stats, see SetAdminStatsFunc.
+	adminStatsFuncs map[string]func() any
 }
 
 type Check struct {
@@ -35,6 +48,7 @@ type StatusResponse struct {
 	Uptime string           `json:"uptime"`
 	PID    int              `json:"pid,omitempty"`
 	Checks map[string]Check `json:"checks,omitempty"`
+	Stats  map[string]any   `json:"stats,omitempty"`
 }
 
 func NewServer(host string, port int, token string) *Server {
@@ -49,6 +63,11 @@ func NewServer(host string, port int, token string) *Server {
 	mux.HandleFunc("/health", s.healthHandler)
 	mux.HandleFunc("/ready", s.readyHandler)
 	mux.HandleFunc("/reload", s.reloadHandler)
+	mux.HandleFunc("/sessions", s.sessionsHandler)
+	mux.HandleFunc("/sessions/reset", s.sessionResetHandler)
+	mux.HandleFunc("/sessions/export", s.sessionExportHandler)
+	mux.HandleFunc("/push", s.pushHandler)
+	mux.HandleFunc("/stats", s.statsHandler)
 
 	addr := net.JoinHostPort(host, strconv.Itoa(port))
 	s.server = &http.Server{
@@ -119,6 +138,30 @@ func (s *Server) SetReloadFunc(fn func() error) {
 	s.reloadFunc = fn
 }
 
+// SetStatsFunc registers a live value reported under "stats" on /health.
+// fn is called on every request, so it must be cheap and goroutine-safe.
+func (s *Server) SetStatsFunc(name string, fn func() any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.statsFuncs == nil {
+		s.statsFuncs = make(map[string]func() any)
+	}
+	s.statsFuncs[name] = fn
+}
+
+func (s *Server) stats() map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.statsFuncs) == 0 {
+		return nil
+	}
+	stats := make(map[string]any, len(s.statsFuncs))
+	for name, fn := range s.statsFuncs {
+		stats[name] = fn()
+	}
+	return stats
+}
+
 func (s *Server) reloadHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Content-Type", "application/json")
@@ -174,6 +217,7 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 		Status: "ok",
 		Uptime: uptime.String(),
 		PID:    os.Getpid(),
+		Stats:  s.stats(),
 	}
 
 	json.NewEncoder(w).Encode(resp)
@@ -225,12 +269,17 @@ type HandlerMux interface {
 	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
 }
 
-// RegisterOnMux registers /health, /ready and /reload handlers onto the given mux.
+// RegisterOnMux registers /health, /ready, /reload, /sessions and /push handlers onto the given mux.
 // This allows the health endpoints to be served by a shared HTTP server.
 func (s *Server) RegisterOnMux(mux HandlerMux) {
 	mux.HandleFunc("/health", s.healthHandler)
 	mux.HandleFunc("/ready", s.readyHandler)
 	mux.HandleFunc("/reload", s.reloadHandler)
+	mux.HandleFunc("/sessions", s.sessionsHandler)
+	mux.HandleFunc("/sessions/reset", s.sessionResetHandler)
+	mux.HandleFunc("/sessions/export", s.sessionExportHandler)
+	mux.HandleFunc("/push", s.pushHandler)
+	mux.HandleFunc("/stats", s.statsHandler)
 }
 
 func statusString(ok bool) string {
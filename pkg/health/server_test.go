@@ -356,3 +356,27 @@ func TestStatusString(t *testing.T) {
 		}
 	}
 }
+
+func TestHealthHandler_IncludesStats(t *testing.T) {
+	s := newTestServer()
+	calls := 0
+	s.SetStatsFunc("agent_turns", func() any {
+		calls++
+		return map[string]int{"running": calls}
+	})
+
+	for want := 1; want <= 2; want++ {
+		w := httptest.NewRecorder()
+		s.healthHandler(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		var resp struct {
+			Stats map[string]map[string]int `json:"stats"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if got := resp.Stats["agent_turns"]["running"]; got != want {
+			t.Errorf("stats running = %d, want %d (evaluated per request)", got, want)
+		}
+	}
+}
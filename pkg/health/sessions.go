@@ -0,0 +1,200 @@
+package health
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSessionBusy tells /sessions/reset to answer 409 Conflict.
+	ErrSessionBusy = errors.New("session busy")
+	// ErrSessionNotFound tells /sessions/reset to answer 404 Not Found.
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// SetSessionFuncs enables the /sessions admin endpoints. list backs
+// GET /sessions; reset backs POST /sessions/reset?key=<session key> and may
+// return ErrSessionBusy or ErrSessionNotFound (optionally wrapped).
+func (s *Server) SetSessionFuncs(list func() any, reset func(ctx context.Context, key string) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listSessions = list
+	s.resetSession = reset
+}
+
+func (s *Server) sessionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed, use GET"})
+		return
+	}
+	if !s.authorize(w, r) {
+		return
+	}
+
+	s.mu.RLock()
+	list := s.listSessions
+	s.mu.RUnlock()
+	if list == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "sessions not configured"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": list()})
+}
+
+func (s *Server) sessionResetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed, use POST"})
+		return
+	}
+	if !s.authorize(w, r) {
+		return
+	}
+
+	s.mu.RLock()
+	reset := s.resetSession
+	s.mu.RUnlock()
+	if reset == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "sessions not configured"})
+		return
+	}
+
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	if key == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing key parameter"})
+		return
+	}
+
+	if err := reset(r.Context(), key); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrSessionBusy):
+			status = http.StatusConflict
+		case errors.Is(err, ErrSessionNotFound):
+			status = http.StatusNotFound
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "session reset", "key": key})
+}
+
+// SetSessionExportFunc enables GET /sessions/export?key=<session key>, which
+// downloads a session as format=markdown (the default) or format=json, with
+// tool calls and results when tools=true. export may return
+// ErrSessionNotFound (optionally wrapped).
+func (s *Server) SetSessionExportFunc(export func(key, format string, includeTools bool) ([]byte, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exportSession = export
+}
+
+func (s *Server) sessionExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed, use GET"})
+		return
+	}
+	// Like /push, export is never open: it hands out whole conversations.
+	if !s.authorizeRequired(w, r, "session export requires a gateway token") {
+		return
+	}
+
+	s.mu.RLock()
+	export := s.exportSession
+	s.mu.RUnlock()
+	if export == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "sessions not configured"})
+		return
+	}
+
+	query := r.URL.Query()
+	key := strings.TrimSpace(query.Get("key"))
+	if key == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing key parameter"})
+		return
+	}
+	format, contentType, ext := "markdown", "text/markdown; charset=utf-8", "md"
+	switch strings.ToLower(strings.TrimSpace(query.Get("format"))) {
+	case "", "markdown", "md":
+	case "json":
+		format, contentType, ext = "json", "application/json", "json"
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "format must be markdown or json"})
+		return
+	}
+	includeTools := false
+	if raw := query.Get("tools"); raw != "" {
+		var err error
+		if includeTools, err = strconv.ParseBool(raw); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "tools must be true or false"})
+			return
+		}
+	}
+
+	body, err := export(key, format, includeTools)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, exportFilename(key), ext))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// exportFilename reduces a session key to characters safe in a
+// Content-Disposition filename.
+func exportFilename(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// authorize enforces the optional bearer token and writes 401 on mismatch.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.RLock()
+	requiredToken := s.authToken
+	s.mu.RUnlock()
+
+	if requiredToken == "" {
+		return true
+	}
+	given := extractBearerToken(r.Header.Get("Authorization"))
+	if given == "" || subtle.ConstantTimeCompare([]byte(given), []byte(requiredToken)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return false
+	}
+	return true
+}
+
+// authorizeRequired is authorize for endpoints that must never be open: it
+// writes 403 with reason when no token is configured.
+func (s *Server) authorizeRequired(w http.ResponseWriter, r *http.Request, reason string) bool {
+	s.mu.RLock()
+	requiredToken := s.authToken
+	s.mu.RUnlock()
+
+	if requiredToken == "" {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": reason})
+		return false
+	}
+	return s.authorize(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,145 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSessionsHandler_ListsSessions(t *testing.T) {
+	s := newTestServer()
+	s.SetSessionFuncs(func() any { return []string{"a", "b"} }, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+	w := httptest.NewRecorder()
+	s.sessionsHandler(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("sessions without token = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	req.Header.Set("Authorization", "Bearer test")
+	w = httptest.NewRecorder()
+	s.sessionsHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("sessions status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var body struct {
+		Sessions []string `json:"sessions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Sessions) != 2 {
+		t.Fatalf("sessions = %v, want 2 entries", body.Sessions)
+	}
+}
+
+func TestSessionResetHandler_MapsErrors(t *testing.T) {
+	s := newTestServer()
+	var gotKey string
+	s.SetSessionFuncs(nil, func(_ context.Context, key string) error {
+		gotKey = key
+		switch key {
+		case "busy":
+			return fmt.Errorf("%w: turn running", ErrSessionBusy)
+		case "missing":
+			return ErrSessionNotFound
+		}
+		return nil
+	})
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"agent:main:chat-1", http.StatusOK},
+		{"busy", http.StatusConflict},
+		{"missing", http.StatusNotFound},
+		{"", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/sessions/reset?key="+tt.key, nil)
+		req.Header.Set("Authorization", "Bearer test")
+		w := httptest.NewRecorder()
+		s.sessionResetHandler(w, req)
+		if w.Code != tt.want {
+			t.Errorf("reset %q status = %d, want %d", tt.key, w.Code, tt.want)
+		}
+	}
+	if gotKey != "missing" {
+		t.Errorf("last reset key = %q, want missing", gotKey)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/sessions/reset?key=x", nil)
+	w := httptest.NewRecorder()
+	s.sessionResetHandler(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("reset GET status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestSessionExportHandler(t *testing.T) {
+	s := newTestServer()
+	var gotFormat string
+	var gotTools bool
+	s.SetSessionExportFunc(func(key, format string, includeTools bool) ([]byte, error) {
+		if key == "missing" {
+			return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, key)
+		}
+		gotFormat, gotTools = format, includeTools
+		return []byte("# Session " + key), nil
+	})
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"key=agent:main:chat-1", http.StatusOK},
+		{"key=agent:main:chat-1&format=json&tools=true", http.StatusOK},
+		{"key=missing", http.StatusNotFound},
+		{"key=x&format=pdf", http.StatusBadRequest},
+		{"key=x&tools=maybe", http.StatusBadRequest},
+		{"", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/sessions/export?"+tt.query, nil)
+		req.Header.Set("Authorization", "Bearer test")
+		w := httptest.NewRecorder()
+		s.sessionExportHandler(w, req)
+		if w.Code != tt.want {
+			t.Errorf("export %q status = %d, want %d", tt.query, w.Code, tt.want)
+		}
+	}
+	if gotFormat != "json" || !gotTools {
+		t.Errorf("last export format = %q tools = %v, want json and true", gotFormat, gotTools)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/sessions/export?key=agent:main:chat-1", nil)
+	req.Header.Set("Authorization", "Bearer test")
+	w := httptest.NewRecorder()
+	s.sessionExportHandler(w, req)
+	if ct := w.Header().Get("Content-Type"); ct != "text/markdown; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want text/markdown", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="agent_main_chat-1.md"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/sessions/export?key=agent:main:chat-1", nil)
+	w = httptest.NewRecorder()
+	s.sessionExportHandler(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("export without token = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	s.authToken = ""
+	req = httptest.NewRequest(http.MethodGet, "/sessions/export?key=agent:main:chat-1", nil)
+	w = httptest.NewRecorder()
+	s.sessionExportHandler(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("export with no gateway token configured = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
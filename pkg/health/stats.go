@@ -0,0 +1,56 @@
+package health
+
+import (
+	"maps"
+	"net/http"
+	"os"
+	"time"
+)
+
+// StatsResponse is the body of GET /stats.
+type StatsResponse struct {
+	Uptime        string         `json:"uptime"`
+	UptimeSeconds int64          `json:"uptime_seconds"`
+	PID           int            `json:"pid"`
+	Stats         map[string]any `json:"stats"`
+}
+
+// SetAdminStatsFunc registers a value reported under "stats" on GET /stats
+// only. Use it instead of SetStatsFunc for anything that should not be on the
+// unauthenticated /health, such as error messages. fn is called on every
+// request, so it must be cheap and goroutine-safe.
+func (s *Server) SetAdminStatsFunc(name string, fn func() any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.adminStatsFuncs == nil {
+		s.adminStatsFuncs = make(map[string]func() any)
+	}
+	s.adminStatsFuncs[name] = fn
+}
+
+func (s *Server) statsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed, use GET"})
+		return
+	}
+	if !s.authorizeRequired(w, r, "stats require a gateway token") {
+		return
+	}
+
+	stats := make(map[string]any)
+	maps.Copy(stats, s.stats())
+	s.mu.RLock()
+	adminFuncs := maps.Clone(s.adminStatsFuncs)
+	s.mu.RUnlock()
+	for name, fn := range adminFuncs {
+		stats[name] = fn()
+	}
+
+	uptime := time.Since(s.startTime)
+	writeJSON(w, http.StatusOK, StatsResponse{
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		PID:           os.Getpid(),
+		Stats:         stats,
+	})
+}
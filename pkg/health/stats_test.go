@@ -0,0 +1,71 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatsHandler_RequiresTokenAndMergesStats(t *testing.T) {
+	s := newTestServer()
+	s.SetStatsFunc("sessions", func() any { return map[string]int{"in_memory": 3} })
+	s.SetAdminStatsFunc("mcp", func() any { return []string{"github"} })
+
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	w := httptest.NewRecorder()
+	s.statsHandler(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status without token = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/stats", nil)
+	req.Header.Set("Authorization", "Bearer test")
+	w = httptest.NewRecorder()
+	s.statsHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp StatsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Uptime == "" || resp.PID == 0 {
+		t.Errorf("missing uptime or pid: %+v", resp)
+	}
+	if _, ok := resp.Stats["sessions"]; !ok {
+		t.Errorf("stats missing public value: %v", resp.Stats)
+	}
+	if _, ok := resp.Stats["mcp"]; !ok {
+		t.Errorf("stats missing admin value: %v", resp.Stats)
+	}
+
+	// Admin-only values must not leak onto the unauthenticated /health.
+	if _, ok := s.stats()["mcp"]; ok {
+		t.Error("admin stats reported on /health")
+	}
+}
+
+func TestStatsHandler_RequiresConfiguredToken(t *testing.T) {
+	s := newTestServer()
+	s.authToken = ""
+
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	w := httptest.NewRecorder()
+	s.statsHandler(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d when the gateway has no token", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestStatsHandler_RejectsNonGet(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodPost, "/stats", nil)
+	req.Header.Set("Authorization", "Bearer test")
+	w := httptest.NewRecorder()
+	s.statsHandler(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
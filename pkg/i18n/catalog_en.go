@@ -0,0 +1,73 @@
+package i18n
+
+var english = map[Message]string{
+	ReplyError:        "Error processing message: {message}",
+	ReplyBusy:         "I'm busy with other conversations right now. Please try again in a moment.",
+	ReplyQueued:       "The AI provider is unavailable right now. I'll answer this message once it's back.",
+	ReplyRetryExpired: "The AI provider stayed unavailable, so I gave up on your earlier message. Please send it again.",
+	ReplyEmpty:        "The model returned an empty response. Please try again or rephrase your message.",
+	ReplyRefused:      "The AI provider's content filter blocked the response. Please rephrase your message.",
+	ReplyToolLimit:    "⚠️ Stopped after %d tool steps without a final answer. Ask me to continue, or increase `max_tool_iterations` in config.json if this task needs more tool steps.",
+	ReplyToolLimitOne: "⚠️ Stopped after 1 tool step without a final answer. Ask me to continue, or increase `max_tool_iterations` in config.json if this task needs more tool steps.",
+	ReplyTokenBudget:  "⚠️ Stopped early: this conversation reached its token budget. Use /clear to start a new one.",
+	ReplyCostBudget:   "⚠️ Stopped early: this conversation reached its cost budget. Use /clear to start a new one.",
+
+	ProviderError("rate_limit"): "The AI provider is rate limiting requests. Please try again shortly.",
+	ProviderError("auth"):       "The AI provider rejected the credentials. Please check the API key.",
+	ProviderError("quota"):      "The AI provider account is out of credits or quota.",
+	ProviderError("model"):      "The AI provider does not know the configured model.",
+	ProviderError("timeout"):    "The AI provider timed out. Please try again.",
+	ProviderError("overloaded"): "The AI provider is overloaded. Please try again shortly.",
+	ProviderError("server"):     "The AI provider had an internal error. Please try again.",
+	ProviderError("request"):    "The AI provider rejected the request.",
+
+	CommandUnavailable:   "Command unavailable in current context.",
+	CommandUsage:         "Usage: %s",
+	CommandUnknownOption: "Unknown option: %s. Usage: %s",
+	CommandFailed:        "Failed to execute /%s: %v",
+	CommandFailedUnnamed: "Failed to execute command: %v",
+	HelpEmpty:            "No commands available.",
+	HelpNoDescription:    "No description",
+	StartGreeting:        "Hello! I am PicoClaw 🦞",
+	BtwEmptyAnswer:       "The model returned an empty response. This may indicate a provider error or token limit.",
+	CheckChannelOK:       "Channel '%s' is available and enabled",
+	ClearFailed:          "Failed to clear chat history: %v",
+	ClearDone:            "Chat history cleared!",
+	ListModels:           "Configured Model: %s\nProvider: %s\n\nTo change models, update config.json",
+	ListDefaultProvider:  "configured default",
+	ListNoChannels:       "No channels enabled",
+	ListChannels:         "Enabled Channels:\n- %s",
+	ListNoTools:          "No tools available",
+	ListTools:            "Available Tools:\n- %s",
+	ListNoSkills:         "No installed skills",
+	ListSkills:           "Installed Skills:\n- %s\n\nUse /use <skill> <message> to force one for a single request, or /use <skill> to apply it to your next message.",
+	ReloadFailed:         "Failed to reload configuration: %v",
+	ReloadDone:           "Config reload triggered!",
+	ShowModel:            "Current Model: %s (Provider: %s)",
+	ShowChannel:          "Current Channel: %s",
+	SubagentsUnsupported: "Runtime does not support querying active turns.",
+	SubagentsNone:        "No active tasks running in this session.",
+	SubagentsTree:        "🤖 **Active Subagents Tree**\n```text\n%s\n```",
+	SubagentsList:        "🤖 **Active Subagents List**\n```text\n%+v\n```",
+	SwitchModelDone:      "Switched model from %s to %s",
+	SwitchChannelMoved:   "This command has moved. Please use: /check channel <name>",
+	ThinkBudget:          "Thinking budget: %d tokens",
+	ThinkLevel:           "Thinking level: %s",
+	ThinkOff:             "Thinking is off.",
+	ThinkTurnedOff:       "Thinking turned off for this session.",
+	ThinkRestored:        "Thinking restored to the configured settings.",
+	ThinkBudgetSet:       "Thinking budget set to %d tokens for this session.",
+	ThinkUnsupported:     "The current model does not support thinking, so this setting has no effect.",
+	UsageNone:            "No model calls in this session yet.",
+	UsageSummary:         "Session Usage:\nModel calls: %d\nPrompt tokens: %d\nCompletion tokens: %d\nTotal tokens: %d",
+	UsageCached:          "\nCached prompt tokens: %d read, %d written",
+	AgentsNone:           "No agents registered",
+	AgentsList:           "Registered agents: %s",
+	UseUnavailable:       "Skill selection is unavailable in the current context.",
+	UseUsage:             "Usage: /use <skill> [message]",
+	UseNoSkills:          "Usage: /use <skill> [message]\nNo installed skills found.",
+	UseSkills:            "Usage: /use <skill> [message]\n\nInstalled Skills:\n- %s\n\nUse /use <skill> to apply a skill to your next message, or /use <skill> <message> to force it immediately.",
+	UseUnknownSkill:      "Unknown skill: %s\nUse /list skills to see installed skills.",
+	UseArmed:             "Skill %q is armed for your next message. Send your next prompt normally, or use /use clear to cancel.",
+	UseCleared:           "Cleared pending skill override.",
+}
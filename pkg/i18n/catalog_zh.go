@@ -0,0 +1,90 @@
+package i18n
+
+// chinese is the Simplified Chinese catalog. It is also used for other zh
+// variants until they get their own.
+var chinese = map[Message]string{
+	ReplyError:        "处理消息时出错：{message}",
+	ReplyBusy:         "我正在处理其他对话，请稍后再试。",
+	ReplyQueued:       "AI 服务暂时不可用，恢复后我会回复这条消息。",
+	ReplyRetryExpired: "AI 服务一直不可用，之前的消息已放弃处理，请重新发送。",
+	ReplyEmpty:        "模型返回了空回复，请重试或换个说法。",
+	ReplyRefused:      "AI 服务的内容过滤拦截了回复，请换个说法。",
+	ReplyToolLimit:    "⚠️ 已执行 %d 步工具调用，仍未得到最终答案，已停止。可以让我继续；如果这个任务需要更多步骤，请在 config.json 中调大 `max_tool_iterations`。",
+	ReplyToolLimitOne: "⚠️ 已执行 1 步工具调用，仍未得到最终答案，已停止。可以让我继续；如果这个任务需要更多步骤，请在 config.json 中调大 `max_tool_iterations`。",
+	ReplyTokenBudget:  "⚠️ 已提前停止：本次对话的 token 预算已用完。使用 /clear 开始新的对话。",
+	ReplyCostBudget:   "⚠️ 已提前停止：本次对话的费用预算已用完。使用 /clear 开始新的对话。",
+
+	ProviderError("rate_limit"): "AI 服务请求过于频繁，请稍后再试。",
+	ProviderError("auth"):       "AI 服务拒绝了凭据，请检查 API 密钥。",
+	ProviderError("quota"):      "AI 服务账户的额度或配额已用完。",
+	ProviderError("model"):      "AI 服务不支持所配置的模型。",
+	ProviderError("timeout"):    "AI 服务响应超时，请重试。",
+	ProviderError("overloaded"): "AI 服务当前负载过高，请稍后再试。",
+	ProviderError("server"):     "AI 服务发生内部错误，请重试。",
+	ProviderError("request"):    "AI 服务拒绝了该请求。",
+
+	CommandUnavailable:   "当前上下文中无法使用此命令。",
+	CommandUsage:         "用法：%s",
+	CommandUnknownOption: "未知选项：%s。用法：%s",
+	CommandFailed:        "执行 /%s 失败：%v",
+	CommandFailedUnnamed: "执行命令失败：%v",
+	HelpEmpty:            "没有可用的命令。",
+	HelpNoDescription:    "暂无说明",
+	StartGreeting:        "你好！我是 PicoClaw 🦞",
+	BtwEmptyAnswer:       "模型返回了空回复，可能是服务出错或达到了 token 上限。",
+	CheckChannelOK:       "频道 '%s' 可用且已启用",
+	ClearFailed:          "清除聊天记录失败：%v",
+	ClearDone:            "聊天记录已清除！",
+	ListModels:           "当前模型：%s\n服务商：%s\n\n如需更换模型，请修改 config.json",
+	ListDefaultProvider:  "默认配置",
+	ListNoChannels:       "没有已启用的频道",
+	ListChannels:         "已启用的频道：\n- %s",
+	ListNoTools:          "没有可用的工具",
+	ListTools:            "可用工具：\n- %s",
+	ListNoSkills:         "没有已安装的技能",
+	ListSkills:           "已安装的技能：\n- %s\n\n使用 /use <技能> <消息> 为单次请求指定技能，或使用 /use <技能> 将其应用于下一条消息。",
+	ReloadFailed:         "重新加载配置失败：%v",
+	ReloadDone:           "已开始重新加载配置！",
+	ShowModel:            "当前模型：%s（服务商：%s）",
+	ShowChannel:          "当前频道：%s",
+	SubagentsUnsupported: "当前运行环境不支持查询进行中的任务。",
+	SubagentsNone:        "此会话中没有正在运行的任务。",
+	SubagentsTree:        "🤖 **子代理任务树**\n```text\n%s\n```",
+	SubagentsList:        "🤖 **子代理列表**\n```text\n%+v\n```",
+	SwitchModelDone:      "已将模型从 %s 切换为 %s",
+	SwitchChannelMoved:   "此命令已迁移，请使用：/check channel <名称>",
+	ThinkBudget:          "思考预算：%d tokens",
+	ThinkLevel:           "思考级别：%s",
+	ThinkOff:             "思考已关闭。",
+	ThinkTurnedOff:       "已为此会话关闭思考。",
+	ThinkRestored:        "思考设置已恢复为配置值。",
+	ThinkBudgetSet:       "已将此会话的思考预算设为 %d tokens。",
+	ThinkUnsupported:     "当前模型不支持思考，此设置不会生效。",
+	UsageNone:            "此会话尚未调用模型。",
+	UsageSummary:         "会话用量：\n模型调用：%d\n提示 tokens：%d\n回复 tokens：%d\n总 tokens：%d",
+	UsageCached:          "\n缓存的提示 tokens：读取 %d，写入 %d",
+	AgentsNone:           "没有已注册的代理",
+	AgentsList:           "已注册的代理：%s",
+	UseUnavailable:       "当前环境无法选择技能。",
+	UseUsage:             "用法：/use <技能> [消息]",
+	UseNoSkills:          "用法：/use <技能> [消息]\n没有已安装的技能。",
+	UseSkills:            "用法：/use <技能> [消息]\n\n已安装的技能：\n- %s\n\n使用 /use <技能> 将技能应用于下一条消息，或使用 /use <技能> <消息> 立即使用。",
+	UseUnknownSkill:      "未知技能：%s\n使用 /list skills 查看已安装的技能。",
+	UseArmed:             "技能 %q 将用于你的下一条消息。请照常发送消息，或使用 /use clear 取消。",
+	UseCleared:           "已取消待用的技能。",
+
+	CommandDescription("btw"):       "提一个旁支问题，不改变会话历史",
+	CommandDescription("chat"):      "向模型发送一条不使用工具的消息",
+	CommandDescription("check"):     "检查频道是否可用",
+	CommandDescription("clear"):     "清除聊天记录",
+	CommandDescription("help"):      "显示此帮助信息",
+	CommandDescription("list"):      "列出可用选项",
+	CommandDescription("reload"):    "重新加载配置文件",
+	CommandDescription("show"):      "显示当前配置",
+	CommandDescription("start"):     "开始使用机器人",
+	CommandDescription("subagents"): "显示运行中的子代理和任务树",
+	CommandDescription("switch"):    "切换模型",
+	CommandDescription("think"):     "查看或设置此会话的推理预算",
+	CommandDescription("usage"):     "显示此会话的 token 用量",
+	CommandDescription("use"):       "为单次请求指定已安装的技能",
+}
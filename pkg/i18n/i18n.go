@@ -0,0 +1,82 @@
+// Package i18n looks up the bot's built-in user-facing strings by message ID
+// and locale. Every message has an English text; other catalogs may cover
+// only part of it, and missing entries fall back to English.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Message identifies a built-in string.
+type Message string
+
+// English is the fallback locale; its catalog holds every message.
+const English = "en"
+
+var catalogs = map[string]map[Message]string{
+	English: english,
+	"zh":    chinese,
+}
+
+// Normalize maps a language tag such as "zh-Hans", "zh_CN" or "EN-us" to a
+// supported locale, or returns "" when no catalog matches.
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if _, ok := catalogs[tag]; ok {
+		return tag
+	}
+	return ""
+}
+
+// Supported returns the locales that have a catalog.
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Lookup returns the text of id in locale's own catalog, without falling
+// back to English.
+func Lookup(locale string, id Message) (string, bool) {
+	text, ok := catalogs[Normalize(locale)][id]
+	return text, ok
+}
+
+// Text returns the text of id in locale, or in English when locale is
+// unsupported or lacks the message. Unknown IDs are returned as is.
+func Text(locale string, id Message) string {
+	if text, ok := Lookup(locale, id); ok {
+		return text
+	}
+	if text, ok := english[id]; ok {
+		return text
+	}
+	return string(id)
+}
+
+// Sprintf formats the text of id in locale with args.
+func Sprintf(locale string, id Message, args ...any) string {
+	return fmt.Sprintf(Text(locale, id), args...)
+}
+
+type localeKey struct{}
+
+// WithLocale returns a context carrying the locale replies should use.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale set by WithLocale, or "".
+func FromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
@@ -0,0 +1,84 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"zh":      "zh",
+		"zh-Hans": "zh",
+		"zh_CN":   "zh",
+		"EN-us":   "en",
+		" en ":    "en",
+		"de":      "",
+		"":        "",
+	}
+	for tag, want := range tests {
+		if got := Normalize(tag); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestText_FallsBackToEnglish(t *testing.T) {
+	if got := Text("zh-CN", ReplyBusy); got != chinese[ReplyBusy] {
+		t.Errorf("Text(zh-CN) = %q", got)
+	}
+	for _, locale := range []string{"", "de", "en"} {
+		if got := Text(locale, ReplyBusy); got != english[ReplyBusy] {
+			t.Errorf("Text(%q) = %q, want English", locale, got)
+		}
+	}
+	if got := Text("zh", Message("no.such.message")); got != "no.such.message" {
+		t.Errorf("unknown message = %q, want its ID", got)
+	}
+	if got := Sprintf("zh", CommandUsage, "/think"); got != "用法：/think" {
+		t.Errorf("Sprintf = %q", got)
+	}
+}
+
+func TestLookup_DoesNotFallBack(t *testing.T) {
+	if _, ok := Lookup("en", CommandDescription("help")); ok {
+		t.Error("English catalog should not carry command descriptions")
+	}
+	if text, ok := Lookup("zh", CommandDescription("help")); !ok || text == "" {
+		t.Errorf("Lookup(zh, help) = %q, %v", text, ok)
+	}
+}
+
+var verbPattern = regexp.MustCompile(`%[+#]?[a-z]|\{[a-z_]+\}`)
+
+// TestCatalogs_MatchEnglish keeps translations usable: each one must
+// translate a known message and use the same format verbs and placeholders.
+func TestCatalogs_MatchEnglish(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for id, text := range catalog {
+			if strings.HasPrefix(string(id), "command.") && strings.HasSuffix(string(id), ".description") {
+				continue
+			}
+			en, ok := english[id]
+			if !ok {
+				t.Errorf("%s: %q has no English text", locale, id)
+				continue
+			}
+			if got, want := verbPattern.FindAllString(text, -1), verbPattern.FindAllString(en, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %q uses %v, English uses %v", locale, id, got, want)
+			}
+		}
+	}
+}
+
+func TestContextLocale(t *testing.T) {
+	ctx := context.Background()
+	if got := FromContext(ctx); got != "" {
+		t.Errorf("FromContext(empty) = %q", got)
+	}
+	if got := FromContext(WithLocale(ctx, "zh")); got != "zh" {
+		t.Errorf("FromContext = %q, want zh", got)
+	}
+}
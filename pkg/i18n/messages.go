@@ -0,0 +1,79 @@
+package i18n
+
+// Agent replies. ReplyError may use {category} and {message}.
+const (
+	ReplyError        Message = "reply.error"
+	ReplyBusy         Message = "reply.busy"
+	ReplyQueued       Message = "reply.queued"
+	ReplyRetryExpired Message = "reply.retry_expired"
+	ReplyEmpty        Message = "reply.empty"
+	ReplyRefused      Message = "reply.refused"
+	ReplyToolLimit    Message = "reply.tool_limit"
+	ReplyToolLimitOne Message = "reply.tool_limit_one"
+	ReplyTokenBudget  Message = "reply.token_budget"
+	ReplyCostBudget   Message = "reply.cost_budget"
+)
+
+// ProviderError returns the ID of the plain explanation for a provider
+// failure category, as reported by ProviderError.Category.
+func ProviderError(category string) Message {
+	return Message("provider." + category)
+}
+
+// CommandDescription returns the ID of a built-in command's description.
+func CommandDescription(name string) Message {
+	return Message("command." + name + ".description")
+}
+
+// Slash command replies.
+const (
+	CommandUnavailable   Message = "command.unavailable"
+	CommandUsage         Message = "command.usage"
+	CommandUnknownOption Message = "command.unknown_option"
+	CommandFailed        Message = "command.failed"
+	CommandFailedUnnamed Message = "command.failed_unnamed"
+	HelpEmpty            Message = "help.empty"
+	HelpNoDescription    Message = "help.no_description"
+	StartGreeting        Message = "start.greeting"
+	BtwEmptyAnswer       Message = "btw.empty_answer"
+	CheckChannelOK       Message = "check.channel_ok"
+	ClearFailed          Message = "clear.failed"
+	ClearDone            Message = "clear.done"
+	ListModels           Message = "list.models"
+	ListDefaultProvider  Message = "list.default_provider"
+	ListNoChannels       Message = "list.no_channels"
+	ListChannels         Message = "list.channels"
+	ListNoTools          Message = "list.no_tools"
+	ListTools            Message = "list.tools"
+	ListNoSkills         Message = "list.no_skills"
+	ListSkills           Message = "list.skills"
+	ReloadFailed         Message = "reload.failed"
+	ReloadDone           Message = "reload.done"
+	ShowModel            Message = "show.model"
+	ShowChannel          Message = "show.channel"
+	SubagentsUnsupported Message = "subagents.unsupported"
+	SubagentsNone        Message = "subagents.none"
+	SubagentsTree        Message = "subagents.tree"
+	SubagentsList        Message = "subagents.list"
+	SwitchModelDone      Message = "switch.model_done"
+	SwitchChannelMoved   Message = "switch.channel_moved"
+	ThinkBudget          Message = "think.budget"
+	ThinkLevel           Message = "think.level"
+	ThinkOff             Message = "think.off"
+	ThinkTurnedOff       Message = "think.turned_off"
+	ThinkRestored        Message = "think.restored"
+	ThinkBudgetSet       Message = "think.budget_set"
+	ThinkUnsupported     Message = "think.unsupported"
+	UsageNone            Message = "usage.none"
+	UsageSummary         Message = "usage.summary"
+	UsageCached          Message = "usage.cached"
+	AgentsNone           Message = "agents.none"
+	AgentsList           Message = "agents.list"
+	UseUnavailable       Message = "use.unavailable"
+	UseUsage             Message = "use.usage"
+	UseNoSkills          Message = "use.no_skills"
+	UseSkills            Message = "use.skills"
+	UseUnknownSkill      Message = "use.unknown_skill"
+	UseArmed             Message = "use.armed"
+	UseCleared           Message = "use.cleared"
+)
@@ -38,11 +38,68 @@ func ParseCanonicalID(canonical string) (platform, id string, ok bool) {
 //   - "@alice"              → matches sender.Username
 //   - "123456|alice"        → matches PlatformID or Username
 //   - "telegram:123456"     → exact match on sender.CanonicalID
+//   - "user:123456"         → matches sender.PlatformID
+//
+// Entries scoped to a chat ("chat:<id>") never match here; use
+// MatchAllowedInChat when the chat is known.
 func MatchAllowed(sender bus.SenderInfo, allowed string) bool {
+	return MatchAllowedInChat(sender, "", allowed)
+}
+
+// MatchAllowedInChat is MatchAllowed for a message received in chatID. In
+// addition to the sender formats it accepts "chat:<id>", which allows
+// everyone in that chat (including its topics, "<id>/<topic>"). The values
+// of "chat:", "user:" and "@" entries may use "*" as a wildcard, e.g.
+// "@team_*" or "chat:-100*".
+func MatchAllowedInChat(sender bus.SenderInfo, chatID, allowed string) bool {
 	allowed = strings.TrimSpace(allowed)
 	if allowed == "" {
 		return false
 	}
+	if allowed == "*" {
+		return true
+	}
+
+	if pattern, ok := strings.CutPrefix(allowed, "chat:"); ok {
+		if chatID == "" {
+			return false
+		}
+		base, _, _ := strings.Cut(chatID, "/")
+		return matchWildcard(pattern, chatID) || matchWildcard(pattern, base)
+	}
+	if pattern, ok := strings.CutPrefix(allowed, "user:"); ok {
+		return sender.PlatformID != "" && matchWildcard(pattern, sender.PlatformID)
+	}
+	if pattern, ok := strings.CutPrefix(allowed, "@"); ok && strings.Contains(pattern, "*") {
+		return sender.Username != "" && matchWildcard(pattern, sender.Username)
+	}
+
+	return matchSender(sender, allowed)
+}
+
+// matchWildcard reports whether value matches pattern, where "*" matches any
+// run of characters. Patterns without "*" must match exactly.
+func matchWildcard(pattern, value string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == value
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+	return strings.HasSuffix(value, last)
+}
+
+func matchSender(sender bus.SenderInfo, allowed string) bool {
 
 	// Try canonical match first: "platform:id" format
 	if platform, id, ok := ParseCanonicalID(allowed); ok {
@@ -236,6 +236,65 @@ func TestMatchAllowed(t *testing.T) {
 	}
 }
 
+func TestMatchAllowedInChat(t *testing.T) {
+	sender := bus.SenderInfo{
+		Platform:    "telegram",
+		PlatformID:  "123456",
+		CanonicalID: "telegram:123456",
+		Username:    "team_alice",
+	}
+
+	tests := []struct {
+		name    string
+		chatID  string
+		allowed string
+		want    bool
+	}{
+		{name: "chat entry matches chat", chatID: "-1001", allowed: "chat:-1001", want: true},
+		{name: "chat entry matches topic of chat", chatID: "-1001/42", allowed: "chat:-1001", want: true},
+		{name: "chat entry other chat", chatID: "-1002", allowed: "chat:-1001", want: false},
+		{name: "chat entry without chat", chatID: "", allowed: "chat:-1001", want: false},
+		{name: "chat wildcard", chatID: "-1009", allowed: "chat:-100*", want: true},
+		{name: "user entry matches PlatformID", chatID: "-1001", allowed: "user:123456", want: true},
+		{name: "user entry other user", chatID: "-1001", allowed: "user:654321", want: false},
+		{name: "user wildcard", allowed: "user:123*", want: true},
+		{name: "username wildcard", allowed: "@team_*", want: true},
+		{name: "username wildcard no match", allowed: "@ops_*", want: false},
+		{name: "bare wildcard", allowed: "*", want: true},
+		{name: "legacy entry still matches", chatID: "-1001", allowed: "@team_alice", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchAllowedInChat(sender, tt.chatID, tt.allowed)
+			if got != tt.want {
+				t.Errorf("MatchAllowedInChat(%q, %q) = %v, want %v", tt.chatID, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"abc", "abc", true},
+		{"abc", "abcd", false},
+		{"a*", "abc", true},
+		{"*c", "abc", true},
+		{"a*c*e", "abcde", true},
+		{"a*c*e", "abde", false},
+		{"ab*ba", "aba", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchWildcard(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("matchWildcard(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
 func TestIsNumeric(t *testing.T) {
 	tests := []struct {
 		input string
@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -24,6 +27,14 @@ const (
 	FATAL = zerolog.FatalLevel
 
 	Component = "component"
+	// TraceID is the field carrying the correlation ID of the current turn.
+	TraceID = "trace_id"
+
+	// FormatText is the default human-readable console output.
+	FormatText = "text"
+	// FormatJSON writes one JSON object per line to stdout, with time, level,
+	// component, caller, message and the event's fields as top-level keys.
+	FormatJSON = "json"
 )
 
 var (
@@ -42,6 +53,10 @@ var (
 	mu            sync.RWMutex
 	writers       []io.Writer
 	consoleWriter zerolog.ConsoleWriter
+	// console is the writer EnableConsole restores: consoleWriter, or stdout
+	// itself in JSON format.
+	console io.Writer
+	stdout  io.Writer = os.Stdout
 )
 
 func init() {
@@ -73,7 +88,8 @@ func init() {
 			NoColor: !isTTY,
 		}
 
-		writers = append(writers, consoleWriter)
+		console = consoleWriter
+		writers = append(writers, console)
 
 		logger = zerolog.New(io.MultiWriter(writers...)).With().Timestamp().Caller().Logger()
 	})
@@ -133,10 +149,33 @@ func DisableConsole() {
 func EnableConsole() {
 	mu.Lock()
 	defer mu.Unlock()
-	writers[0] = consoleWriter
+	writers[0] = console
 	logger = logger.Output(io.MultiWriter(writers...))
 }
 
+// SetFormat selects the console output format, FormatText or FormatJSON
+// (case-insensitive). Empty or unknown values keep the current format. File
+// logs are always JSON. A disabled console stays disabled.
+func SetFormat(format string) {
+	var out io.Writer
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatText:
+		out = consoleWriter
+	case FormatJSON:
+		out = stdout
+	default:
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	console = out
+	if writers[0] != io.Discard {
+		writers[0] = console
+		logger = logger.Output(io.MultiWriter(writers...))
+	}
+}
+
 func GetLevel() LogLevel {
 	mu.RLock()
 	defer mu.RUnlock()
@@ -218,6 +257,8 @@ func DisableFileLogging() {
 }
 
 func ConfigureFromEnv() {
+	SetFormat(os.Getenv("PICOCLAW_LOG_FORMAT"))
+
 	if logFile := os.Getenv("PICOCLAW_LOG_FILE"); logFile != "" {
 		if strings.HasPrefix(logFile, "~/") {
 			if home := os.Getenv("HOME"); home != "" {
@@ -302,7 +343,7 @@ func getEvent(logger zerolog.Logger, level LogLevel) *zerolog.Event {
 	}
 }
 
-func logMessage(level LogLevel, component string, message string, fields map[string]any) {
+func logMessage(level LogLevel, traceID, component, message string, fields map[string]any) {
 	if level < currentLevel {
 		return
 	}
@@ -316,6 +357,9 @@ func logMessage(level LogLevel, component string, message string, fields map[str
 	}
 
 	event.Str(Component, component)
+	if traceID != "" {
+		event.Str(TraceID, traceID)
+	}
 
 	appendFields(event, fields)
 
@@ -345,101 +389,153 @@ func appendFields(event *zerolog.Event, fields map[string]any) {
 }
 
 func Debug(message string) {
-	logMessage(DEBUG, "", message, nil)
+	logMessage(DEBUG, "", "", message, nil)
 }
 
 func DebugC(component string, message string) {
-	logMessage(DEBUG, component, message, nil)
+	logMessage(DEBUG, "", component, message, nil)
 }
 
 func Debugf(message string, ss ...any) {
-	logMessage(DEBUG, "", fmt.Sprintf(message, ss...), nil)
+	logMessage(DEBUG, "", "", fmt.Sprintf(message, ss...), nil)
 }
 
 func DebugF(message string, fields map[string]any) {
-	logMessage(DEBUG, "", message, fields)
+	logMessage(DEBUG, "", "", message, fields)
 }
 
 func DebugCF(component string, message string, fields map[string]any) {
-	logMessage(DEBUG, component, message, fields)
+	logMessage(DEBUG, "", component, message, fields)
 }
 
 func Info(message string) {
-	logMessage(INFO, "", message, nil)
+	logMessage(INFO, "", "", message, nil)
 }
 
 func InfoC(component string, message string) {
-	logMessage(INFO, component, message, nil)
+	logMessage(INFO, "", component, message, nil)
 }
 
 func InfoF(message string, fields map[string]any) {
-	logMessage(INFO, "", message, fields)
+	logMessage(INFO, "", "", message, fields)
 }
 
 func Infof(message string, ss ...any) {
-	logMessage(INFO, "", fmt.Sprintf(message, ss...), nil)
+	logMessage(INFO, "", "", fmt.Sprintf(message, ss...), nil)
 }
 
 func InfoCF(component string, message string, fields map[string]any) {
-	logMessage(INFO, component, message, fields)
+	logMessage(INFO, "", component, message, fields)
 }
 
 func Warn(message string) {
-	logMessage(WARN, "", message, nil)
+	logMessage(WARN, "", "", message, nil)
 }
 
 func WarnC(component string, message string) {
-	logMessage(WARN, component, message, nil)
+	logMessage(WARN, "", component, message, nil)
 }
 
 func WarnF(message string, fields map[string]any) {
-	logMessage(WARN, "", message, fields)
+	logMessage(WARN, "", "", message, fields)
 }
 
 func WarnCF(component string, message string, fields map[string]any) {
-	logMessage(WARN, component, message, fields)
+	logMessage(WARN, "", component, message, fields)
 }
 
 func Warnf(message string, ss ...any) {
-	logMessage(WARN, "", fmt.Sprintf(message, ss...), nil)
+	logMessage(WARN, "", "", fmt.Sprintf(message, ss...), nil)
 }
 
 func Error(message string) {
-	logMessage(ERROR, "", message, nil)
+	logMessage(ERROR, "", "", message, nil)
 }
 
 func ErrorC(component string, message string) {
-	logMessage(ERROR, component, message, nil)
+	logMessage(ERROR, "", component, message, nil)
 }
 
 func Errorf(message string, ss ...any) {
-	logMessage(ERROR, "", fmt.Sprintf(message, ss...), nil)
+	logMessage(ERROR, "", "", fmt.Sprintf(message, ss...), nil)
 }
 
 func ErrorF(message string, fields map[string]any) {
-	logMessage(ERROR, "", message, fields)
+	logMessage(ERROR, "", "", message, fields)
 }
 
 func ErrorCF(component string, message string, fields map[string]any) {
-	logMessage(ERROR, component, message, fields)
+	logMessage(ERROR, "", component, message, fields)
 }
 
 func Fatal(message string) {
-	logMessage(FATAL, "", message, nil)
+	logMessage(FATAL, "", "", message, nil)
 }
 
 func FatalC(component string, message string) {
-	logMessage(FATAL, component, message, nil)
+	logMessage(FATAL, "", component, message, nil)
 }
 
 func Fatalf(message string, ss ...any) {
-	logMessage(FATAL, "", fmt.Sprintf(message, ss...), nil)
+	logMessage(FATAL, "", "", fmt.Sprintf(message, ss...), nil)
 }
 
 func FatalF(message string, fields map[string]any) {
-	logMessage(FATAL, "", message, fields)
+	logMessage(FATAL, "", "", message, fields)
 }
 
 func FatalCF(component string, message string, fields map[string]any) {
-	logMessage(FATAL, component, message, fields)
+	logMessage(FATAL, "", component, message, fields)
+}
+
+type traceIDKey struct{}
+
+// NewTraceID returns a random 16-character hex correlation ID.
+func NewTraceID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// WithTraceID returns a copy of ctx carrying the correlation ID id. The *Ctx
+// logging functions add it to every entry as the trace_id field.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceIDFromContext returns the correlation ID stored in ctx, or "".
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// EnsureTraceID returns ctx unchanged when it already has a correlation ID,
+// otherwise a copy carrying a new one.
+func EnsureTraceID(ctx context.Context) context.Context {
+	if TraceIDFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithTraceID(ctx, NewTraceID())
+}
+
+func DebugCtx(ctx context.Context, component string, message string, fields map[string]any) {
+	logMessage(DEBUG, TraceIDFromContext(ctx), component, message, fields)
+}
+
+func InfoCtx(ctx context.Context, component string, message string, fields map[string]any) {
+	logMessage(INFO, TraceIDFromContext(ctx), component, message, fields)
+}
+
+func WarnCtx(ctx context.Context, component string, message string, fields map[string]any) {
+	logMessage(WARN, TraceIDFromContext(ctx), component, message, fields)
+}
+
+func ErrorCtx(ctx context.Context, component string, message string, fields map[string]any) {
+	logMessage(ERROR, TraceIDFromContext(ctx), component, message, fields)
 }
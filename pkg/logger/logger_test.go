@@ -2,6 +2,7 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -431,3 +432,102 @@ func TestGetPackageNameFromFile(t *testing.T) {
 		})
 	}
 }
+
+func TestSetFormatJSON(t *testing.T) {
+	var buf bytes.Buffer
+	origStdout := stdout
+	stdout = &buf
+	origLevel := GetLevel()
+	t.Cleanup(func() {
+		SetFormat(FormatText)
+		stdout = origStdout
+		SetLevel(origLevel)
+	})
+
+	EnableConsole()
+	SetLevel(INFO)
+	SetFormat("JSON")
+	InfoCF("agent", "turn finished", map[string]any{"session_key": "s1", "tokens": 42})
+	DebugCF("agent", "filtered out", nil)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1: %s", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(lines[0], &entry); err != nil {
+		t.Fatalf("unmarshal log line %q: %v", lines[0], err)
+	}
+	for key, want := range map[string]any{
+		"level":       "info",
+		Component:     "agent",
+		"message":     "turn finished",
+		"session_key": "s1",
+		"tokens":      float64(42),
+	} {
+		if entry[key] != want {
+			t.Errorf("%s = %#v, want %#v", key, entry[key], want)
+		}
+	}
+	if _, ok := entry["time"]; !ok {
+		t.Error("expected a time field")
+	}
+
+	buf.Reset()
+	SetFormat("bogus")
+	Info("still json")
+	if !json.Valid(bytes.TrimSpace(buf.Bytes())) {
+		t.Errorf("unknown format should keep JSON output, got %q", buf.String())
+	}
+}
+
+func TestCtxLoggingAddsTraceID(t *testing.T) {
+	var buf bytes.Buffer
+	origStdout := stdout
+	stdout = &buf
+	origLevel := GetLevel()
+	t.Cleanup(func() {
+		SetFormat(FormatText)
+		stdout = origStdout
+		SetLevel(origLevel)
+	})
+	EnableConsole()
+	SetLevel(INFO)
+	SetFormat(FormatJSON)
+
+	ctx := WithTraceID(context.Background(), "abc123")
+	InfoCtx(ctx, "agent", "with trace", map[string]any{"k": "v"})
+	InfoCtx(context.Background(), "agent", "without trace", nil)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %s", len(lines), buf.String())
+	}
+	var first, second map[string]any
+	if err := json.Unmarshal(lines[0], &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(lines[1], &second); err != nil {
+		t.Fatal(err)
+	}
+	if first[TraceID] != "abc123" || first["k"] != "v" {
+		t.Errorf("first entry = %v, want trace_id and fields", first)
+	}
+	if _, ok := second[TraceID]; ok {
+		t.Errorf("second entry = %v, want no trace_id", second)
+	}
+}
+
+func TestEnsureTraceID(t *testing.T) {
+	ctx := EnsureTraceID(context.Background())
+	id := TraceIDFromContext(ctx)
+	if len(id) != 16 {
+		t.Fatalf("generated trace ID = %q, want 16 hex chars", id)
+	}
+	if got := TraceIDFromContext(EnsureTraceID(ctx)); got != id {
+		t.Errorf("EnsureTraceID replaced existing ID %q with %q", id, got)
+	}
+	if got := TraceIDFromContext(WithTraceID(ctx, "")); got != id {
+		t.Errorf("WithTraceID(\"\") = %q, want existing ID kept", got)
+	}
+}
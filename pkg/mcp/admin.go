@@ -0,0 +1,261 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxStderrTail is how much of a stdio server's stderr is kept for status.
+const maxStderrTail = 4 << 10
+
+// ServerStatus describes one configured MCP server.
+type ServerStatus struct {
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	Transport string `json:"transport"`
+	ToolCount int    `json:"tool_count"`
+	// ProtocolVersion is the MCP protocol version negotiated with a
+	// connected server.
+	ProtocolVersion string `json:"protocol_version,omitempty"`
+	LastError       string `json:"last_error,omitempty"`
+	Stderr          string `json:"stderr,omitempty"`
+}
+
+// ReloadResult lists the servers a Reload changed.
+type ReloadResult struct {
+	Added     []string `json:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+	Restarted []string `json:"restarted,omitempty"`
+	Failed    []string `json:"failed,omitempty"`
+}
+
+type serverFailure struct {
+	err    error
+	stderr *tailBuffer
+}
+
+// ServerStatus reports every server the manager knows about, sorted by name.
+func (m *Manager) ServerStatus() []ServerStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	statuses := make([]ServerStatus, 0, len(m.configs))
+	for name, cfg := range m.configs {
+		st := ServerStatus{Name: name, Transport: transportName(cfg)}
+		var stderr *tailBuffer
+		if conn, ok := m.servers[name]; ok {
+			st.Connected = true
+			st.ToolCount = len(conn.Tools)
+			st.ProtocolVersion = conn.ProtocolVersion
+			stderr = conn.stderr
+		} else if failure, ok := m.failures[name]; ok {
+			st.LastError = failure.err.Error()
+			stderr = failure.stderr
+		}
+		if stderr != nil {
+			st.Stderr = stderr.String()
+		}
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Reconnect closes the named server's connection, if any, and connects it
+// again with its last configuration. Failed servers can be reconnected too.
+func (m *Manager) Reconnect(ctx context.Context, name string) error {
+	if m.closed.Load() {
+		return fmt.Errorf("manager is closed")
+	}
+	m.mu.RLock()
+	cfg, ok := m.configs[name]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("server %s not found", name)
+	}
+
+	m.disconnect(name)
+	logger.InfoCF("mcp", "Reconnecting MCP server", map[string]any{"server": name})
+	// Stdio processes must outlive the caller's context, as in keepalive.
+	return m.connectWithin(ctx, name, cfg)
+}
+
+// Disconnect closes the named server and forgets its configuration.
+func (m *Manager) Disconnect(name string) {
+	m.disconnect(name)
+	m.mu.Lock()
+	delete(m.configs, name)
+	delete(m.failures, name)
+	m.mu.Unlock()
+}
+
+// Reload brings the manager in line with mcpCfg: servers that are new or
+// enabled are connected, servers that are gone or disabled are closed, and
+// servers whose configuration changed (or that had failed) are reconnected.
+// Servers that are unchanged and connected are left alone.
+func (m *Manager) Reload(ctx context.Context, mcpCfg config.MCPConfig, workspacePath string) (ReloadResult, error) {
+	var result ReloadResult
+	if m.closed.Load() {
+		return result, fmt.Errorf("manager is closed")
+	}
+
+	wanted := make(map[string]config.MCPServerConfig)
+	if mcpCfg.Enabled {
+		for name, serverCfg := range mcpCfg.Servers {
+			if !serverCfg.Enabled {
+				continue
+			}
+			resolved, err := resolveServerConfig(name, serverCfg, workspacePath)
+			if err != nil {
+				return result, err
+			}
+			wanted[name] = resolved
+		}
+	}
+
+	m.mu.RLock()
+	current := make(map[string]config.MCPServerConfig, len(m.configs))
+	for name, cfg := range m.configs {
+		current[name] = cfg
+	}
+	connected := make(map[string]bool, len(m.servers))
+	for name := range m.servers {
+		connected[name] = true
+	}
+	m.mu.RUnlock()
+
+	for name := range current {
+		if _, ok := wanted[name]; !ok {
+			m.Disconnect(name)
+			result.Removed = append(result.Removed, name)
+		}
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for name, cfg := range wanted {
+		old, known := current[name]
+		if known && connected[name] && reflect.DeepEqual(old, cfg) {
+			continue
+		}
+		if known {
+			result.Restarted = append(result.Restarted, name)
+			m.disconnect(name)
+		} else {
+			result.Added = append(result.Added, name)
+		}
+		wg.Add(1)
+		go func(name string, cfg config.MCPServerConfig) {
+			defer wg.Done()
+			if err := m.connectWithin(ctx, name, cfg); err != nil {
+				logger.ErrorCF("mcp", "Failed to connect to MCP server",
+					map[string]any{"server": name, "error": err.Error()})
+				mu.Lock()
+				result.Failed = append(result.Failed, name)
+				mu.Unlock()
+			}
+		}(name, cfg)
+	}
+	wg.Wait()
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Restarted)
+	sort.Strings(result.Failed)
+	return result, nil
+}
+
+// connectWithin connects a server on the manager's context, so the stdio
+// process outlives ctx, while still giving up when ctx is done. The attempt
+// carries on after that; if it succeeds, the tools-changed handler is called
+// so the server's tools are registered after all.
+func (m *Manager) connectWithin(ctx context.Context, name string, cfg config.MCPServerConfig) error {
+	done := make(chan error, 1)
+	go func() { done <- m.ConnectServer(m.ctx, name, cfg) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		go func() {
+			if err := <-done; err != nil {
+				return
+			}
+			logger.InfoCF("mcp", "MCP server connected after the caller gave up",
+				map[string]any{"server": name})
+			m.mu.RLock()
+			handler := m.onToolsChanged
+			m.mu.RUnlock()
+			if handler != nil {
+				handler(name)
+			}
+		}()
+		return fmt.Errorf("connecting to server %s: %w", name, ctx.Err())
+	}
+}
+
+// disconnect stops the named server's keepalive and closes its session.
+func (m *Manager) disconnect(name string) {
+	m.mu.Lock()
+	conn, ok := m.servers[name]
+	if ok {
+		delete(m.servers, name)
+		if conn.stopKeepalive != nil {
+			conn.stopKeepalive()
+		}
+	}
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	if err := conn.Session.Close(); err != nil {
+		logger.WarnCF("mcp", "Failed to close MCP server connection",
+			map[string]any{"server": name, "error": err.Error()})
+	}
+}
+
+func transportName(cfg config.MCPServerConfig) string {
+	switch {
+	case cfg.Type != "":
+		return cfg.Type
+	case cfg.URL != "":
+		return "sse"
+	default:
+		return "stdio"
+	}
+}
+
+// tailBuffer is an io.Writer that keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.TrimSpace(string(b.buf))
+}
@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestReconnect_ReplacesConnection(t *testing.T) {
+	var hang atomic.Bool
+	var initializes atomic.Int32
+	srv := newHangingMCPServer(t, &hang, &initializes)
+
+	mgr := NewManager()
+	defer mgr.Close()
+	cfg := config.MCPServerConfig{Enabled: true, Type: "http", URL: srv.URL}
+	if err := mgr.ConnectServer(t.Context(), "remote", cfg); err != nil {
+		t.Fatalf("ConnectServer() error = %v", err)
+	}
+	first, _ := mgr.GetServer("remote")
+
+	if err := mgr.Reconnect(t.Context(), "remote"); err != nil {
+		t.Fatalf("Reconnect() error = %v", err)
+	}
+	second, ok := mgr.GetServer("remote")
+	if !ok || second == first || initializes.Load() != 2 {
+		t.Fatalf("connected = %v, replaced = %v, initializes = %d", ok, second != first, initializes.Load())
+	}
+
+	if err := mgr.Reconnect(t.Context(), "missing"); err == nil {
+		t.Fatal("Reconnect() of an unknown server = nil error")
+	}
+}
+
+func TestConnectWithin_SyncsToolsWhenLateConnectSucceeds(t *testing.T) {
+	server := sdkmcp.NewServer(&sdkmcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	sdkmcp.AddTool(server, &sdkmcp.Tool{Name: "late"},
+		func(context.Context, *sdkmcp.CallToolRequest, struct{}) (*sdkmcp.CallToolResult, any, error) {
+			return &sdkmcp.CallToolResult{}, nil, nil
+		})
+	handler := sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server { return server }, nil)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	mgr := NewManager()
+	defer mgr.Close()
+	changed := make(chan string, 1)
+	mgr.SetToolsChangedHandler(func(name string) { changed <- name })
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	cfg := config.MCPServerConfig{Enabled: true, Type: "http", URL: srv.URL}
+	if err := mgr.connectWithin(ctx, "slow", cfg); err == nil {
+		t.Fatal("connectWithin() = nil error past the deadline")
+	}
+	close(release)
+
+	select {
+	case name := <-changed:
+		if name != "slow" {
+			t.Fatalf("changed handler got server %q, want slow", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("late connection did not call the tools-changed handler")
+	}
+	if conn, ok := mgr.GetServer("slow"); !ok || len(conn.Tools) != 1 {
+		t.Fatalf("server connected = %v after the late connect", ok)
+	}
+}
+
+func TestServerStatus_ReportsFailureWithStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	mgr := NewManager()
+	defer mgr.Close()
+	cfg := config.MCPServerConfig{Enabled: true, Command: "sh", Args: []string{"-c", "echo 'token missing' >&2; exit 1"}}
+	if err := mgr.ConnectServer(t.Context(), "broken", cfg); err == nil {
+		t.Fatal("ConnectServer() = nil error for a server that exits")
+	}
+
+	var st ServerStatus
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		statuses := mgr.ServerStatus()
+		if len(statuses) != 1 {
+			t.Fatalf("statuses = %+v, want the failed server", statuses)
+		}
+		st = statuses[0]
+		if st.Stderr != "" || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if st.Connected || st.Transport != "stdio" || st.LastError == "" || st.Stderr != "token missing" {
+		t.Fatalf("status = %+v", st)
+	}
+}
+
+func TestReload_AddsRemovesAndRestarts(t *testing.T) {
+	var hang atomic.Bool
+	var initializes atomic.Int32
+	srv := newHangingMCPServer(t, &hang, &initializes)
+
+	mgr := NewManager()
+	defer mgr.Close()
+	server := config.MCPServerConfig{Enabled: true, Type: "http", URL: srv.URL}
+	if err := mgr.ConnectServer(t.Context(), "old", server); err != nil {
+		t.Fatalf("ConnectServer() error = %v", err)
+	}
+
+	mcpCfg := config.MCPConfig{
+		ToolConfig: config.ToolConfig{Enabled: true},
+		Servers: map[string]config.MCPServerConfig{
+			"new":      server,
+			"disabled": {Enabled: false, Command: "never-started"},
+		},
+	}
+	result, err := mgr.Reload(t.Context(), mcpCfg, "")
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	want := ReloadResult{Added: []string{"new"}, Removed: []string{"old"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("Reload() = %+v, want %+v", result, want)
+	}
+
+	// Unchanged and connected: nothing to do.
+	if result, _ = mgr.Reload(t.Context(), mcpCfg, ""); !reflect.DeepEqual(result, ReloadResult{}) {
+		t.Fatalf("second Reload() = %+v, want no changes", result)
+	}
+
+	changed := server
+	changed.Headers = map[string]string{"X-Env": "staging"}
+	mcpCfg.Servers["new"] = changed
+	if result, _ = mgr.Reload(t.Context(), mcpCfg, ""); !reflect.DeepEqual(result.Restarted, []string{"new"}) {
+		t.Fatalf("Reload() after config change = %+v, want new restarted", result)
+	}
+
+	names := make([]string, 0)
+	for _, st := range mgr.ServerStatus() {
+		names = append(names, st.Name)
+	}
+	if strings.Join(names, ",") != "new" {
+		t.Fatalf("servers after reload = %v, want [new]", names)
+	}
+}
+
+func TestTailBuffer_KeepsLastBytes(t *testing.T) {
+	b := newTailBuffer(8)
+	b.Write([]byte("0123456789"))
+	b.Write([]byte("ab"))
+	if got := b.String(); got != "456789ab" {
+		t.Fatalf("String() = %q, want %q", got, "456789ab")
+	}
+}
@@ -1,12 +1,16 @@
 package mcp
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os/exec"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -18,11 +22,43 @@ import (
 
 var isolatedCommandTerminateDuration = 5 * time.Second
 
+// Stdio message framings. Most servers write one JSON message per line;
+// some use the LSP-style "Content-Length: N\r\n\r\n<body>" framing.
+const (
+	FramingAuto          = "auto"
+	FramingNewline       = "newline"
+	FramingContentLength = "content-length"
+)
+
+const (
+	defaultReadBufferSize = 64 * 1024
+	// maxFramedMessageSize bounds the body a Content-Length header may
+	// announce, so a corrupt header cannot make us allocate gigabytes.
+	maxFramedMessageSize = 64 * 1024 * 1024
+)
+
+// validFraming reports whether framing is a known stdio framing; empty
+// means auto.
+func validFraming(framing string) bool {
+	switch framing {
+	case "", FramingAuto, FramingNewline, FramingContentLength:
+		return true
+	}
+	return false
+}
+
 // isolatedCommandTransport mirrors the SDK command transport but routes
 // process startup through pkg/isolation so Windows post-start hooks run too.
 type isolatedCommandTransport struct {
 	Command           *exec.Cmd
 	TerminateDuration time.Duration
+	// Framing is one of the Framing constants; empty means auto.
+	Framing string
+	// ReadBufferSize is the stdout buffer size; 0 means the default.
+	ReadBufferSize int
+	// ProtocolVersion, if set, replaces the version the initialize request
+	// asks for.
+	ProtocolVersion string
 }
 
 func (t *isolatedCommandTransport) Connect(ctx context.Context) (sdkmcp.Connection, error) {
@@ -42,7 +78,10 @@ func (t *isolatedCommandTransport) Connect(ctx context.Context) (sdkmcp.Connecti
 	if td <= 0 {
 		td = isolatedCommandTerminateDuration
 	}
-	return newIsolatedIOConn(&isolatedPipeRWC{cmd: t.Command, stdout: stdout, stdin: stdin, terminateDuration: td}), nil
+	rwc := &isolatedPipeRWC{cmd: t.Command, stdout: stdout, stdin: stdin, terminateDuration: td}
+	conn := newIsolatedIOConn(rwc, t.Framing, t.ReadBufferSize)
+	conn.protocolVersion = t.ProtocolVersion
+	return conn, nil
 }
 
 type isolatedPipeRWC struct {
@@ -101,6 +140,11 @@ type isolatedIOConn struct {
 	closeOnce sync.Once
 	closed    chan struct{}
 	closeErr  error
+	// framed is set when messages are written with Content-Length
+	// headers: when configured, or once the server is seen using them.
+	framed atomic.Bool
+	// protocolVersion is pinned into the initialize request when set.
+	protocolVersion string
 }
 
 type isolatedMsgOrErr struct {
@@ -108,35 +152,130 @@ type isolatedMsgOrErr struct {
 	err error
 }
 
-func newIsolatedIOConn(rwc io.ReadWriteCloser) *isolatedIOConn {
+func newIsolatedIOConn(rwc io.ReadWriteCloser, framing string, readBufferSize int) *isolatedIOConn {
+	if readBufferSize <= 0 {
+		readBufferSize = defaultReadBufferSize
+	}
 	incoming := make(chan isolatedMsgOrErr)
-	closed := make(chan struct{})
-	go func() {
-		dec := json.NewDecoder(rwc)
+	c := &isolatedIOConn{rwc: rwc, incoming: incoming, closed: make(chan struct{})}
+	c.framed.Store(framing == FramingContentLength)
+	go c.readLoop(bufio.NewReaderSize(rwc, readBufferSize), framing, incoming)
+	return c
+}
+
+func (c *isolatedIOConn) readLoop(r *bufio.Reader, framing string, incoming chan<- isolatedMsgOrErr) {
+	send := func(v isolatedMsgOrErr) bool {
+		select {
+		case incoming <- v:
+			return v.err == nil
+		case <-c.closed:
+			return false
+		}
+	}
+
+	framed := framing == FramingContentLength
+	if framing == "" || framing == FramingAuto {
+		detected, err := detectFraming(r)
+		if err != nil {
+			send(isolatedMsgOrErr{err: err})
+			return
+		}
+		framed = detected
+		if framed {
+			c.framed.Store(true)
+		}
+	}
+
+	if framed {
 		for {
-			var raw json.RawMessage
-			err := dec.Decode(&raw)
-			if err == nil {
-				var tr [1]byte
-				if n, readErr := dec.Buffered().Read(tr[:]); n > 0 {
-					if tr[0] != '\n' && tr[0] != '\r' {
-						err = fmt.Errorf("invalid trailing data at the end of stream")
-					}
-				} else if readErr != nil && readErr != io.EOF {
-					err = readErr
+			msg, err := readFramedMessage(r)
+			if !send(isolatedMsgOrErr{msg: msg, err: err}) {
+				return
+			}
+		}
+	}
+
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == nil {
+			var tr [1]byte
+			if n, readErr := dec.Buffered().Read(tr[:]); n > 0 {
+				if tr[0] != '\n' && tr[0] != '\r' {
+					err = fmt.Errorf("invalid trailing data at the end of stream")
 				}
+			} else if readErr != nil && readErr != io.EOF {
+				err = readErr
 			}
-			select {
-			case incoming <- isolatedMsgOrErr{msg: raw, err: err}:
-			case <-closed:
-				return
+		}
+		if !send(isolatedMsgOrErr{msg: raw, err: err}) {
+			return
+		}
+	}
+}
+
+// detectFraming looks at the server's first output: a JSON message starts
+// with '{' or '[', anything else is taken as a Content-Length header.
+func detectFraming(r *bufio.Reader) (bool, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return false, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = r.ReadByte()
+		case '{', '[':
+			return false, nil
+		default:
+			return true, nil
+		}
+	}
+}
+
+// readFramedMessage reads one Content-Length framed message body.
+func readFramedMessage(r *bufio.Reader) (json.RawMessage, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				err = io.ErrUnexpectedEOF
 			}
-			if err != nil {
-				return
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if length < 0 {
+				// Blank lines between messages.
+				continue
 			}
+			break
 		}
-	}()
-	return &isolatedIOConn{rwc: rwc, incoming: incoming, closed: closed}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid message header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+			if n > maxFramedMessageSize {
+				return nil, fmt.Errorf("message of %d bytes exceeds the %d byte limit", n, maxFramedMessageSize)
+			}
+			length = n
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return body, nil
 }
 
 func (c *isolatedIOConn) SessionID() string { return "" }
@@ -207,7 +346,12 @@ func (c *isolatedIOConn) Write(ctx context.Context, msg jsonrpc.Message) error {
 	if err != nil {
 		return fmt.Errorf("marshaling message: %v", err)
 	}
-	data = append(data, '\n')
+	data = pinProtocolVersion(data, c.protocolVersion)
+	if c.framed.Load() {
+		data = append([]byte(fmt.Sprintf("Content-Length: %d\r\n\r\n", len(data))), data...)
+	} else {
+		data = append(data, '\n')
+	}
 	_, err = c.rwc.Write(data)
 	return err
 }
@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// scriptedRWC replays canned server output and records what is written.
+type scriptedRWC struct {
+	io.Reader
+	mu      sync.Mutex
+	written bytes.Buffer
+}
+
+func (s *scriptedRWC) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.written.Write(p)
+}
+
+func (s *scriptedRWC) Close() error { return nil }
+
+func (s *scriptedRWC) Written() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.written.String()
+}
+
+func framed(body string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func readResponseID(t *testing.T, conn *isolatedIOConn) any {
+	t.Helper()
+	msg, err := conn.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	resp, ok := msg.(*jsonrpc.Response)
+	if !ok {
+		t.Fatalf("Read() = %T, want *jsonrpc.Response", msg)
+	}
+	return resp.ID.Raw()
+}
+
+func writePing(t *testing.T, conn *isolatedIOConn) {
+	t.Helper()
+	id, err := jsonrpc.MakeID(float64(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Write(context.Background(), &jsonrpc.Request{ID: id, Method: "ping"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+}
+
+func TestIsolatedIOConn_DetectsContentLengthFraming(t *testing.T) {
+	rwc := &scriptedRWC{Reader: strings.NewReader(
+		framed(`{"jsonrpc":"2.0","id":1,"result":{}}`) +
+			"Content-Type: application/vscode-jsonrpc; charset=utf-8\r\n" +
+			framed(`{"jsonrpc":"2.0","id":2,"result":{}}`),
+	)}
+	conn := newIsolatedIOConn(rwc, "", 0)
+	defer conn.Close()
+
+	for _, want := range []int64{1, 2} {
+		if got := readResponseID(t, conn); got != want {
+			t.Fatalf("response id = %v, want %d", got, want)
+		}
+	}
+
+	// Once the server is seen framing its output, requests are framed too.
+	writePing(t, conn)
+	if got := rwc.Written(); !strings.HasPrefix(got, "Content-Length: ") || strings.HasSuffix(got, "}\n") {
+		t.Fatalf("written = %q, want a Content-Length framed message", got)
+	}
+}
+
+func TestIsolatedIOConn_NewlineFramingWithSmallBuffer(t *testing.T) {
+	large := strings.Repeat("x", 100*1024)
+	rwc := &scriptedRWC{Reader: strings.NewReader(
+		"\n" + `{"jsonrpc":"2.0","id":1,"result":{"text":"` + large + `"}}` + "\n" +
+			`{"jsonrpc":"2.0","id":2,"result":{}}` + "\n",
+	)}
+	conn := newIsolatedIOConn(rwc, FramingAuto, 16)
+	defer conn.Close()
+
+	for _, want := range []int64{1, 2} {
+		if got := readResponseID(t, conn); got != want {
+			t.Fatalf("response id = %v, want %d", got, want)
+		}
+	}
+	writePing(t, conn)
+	if got := rwc.Written(); strings.HasPrefix(got, "Content-Length") || !strings.HasSuffix(got, "}\n") {
+		t.Fatalf("written = %q, want a newline-delimited message", got)
+	}
+}
+
+func TestIsolatedIOConn_ConfiguredContentLengthFramesFirstWrite(t *testing.T) {
+	// The server has said nothing yet; the first request must already be
+	// framed for servers that only accept framed input.
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	rwc := &scriptedRWC{Reader: pr}
+	conn := newIsolatedIOConn(rwc, FramingContentLength, 0)
+	defer conn.Close()
+
+	writePing(t, conn)
+	if got := rwc.Written(); !strings.HasPrefix(got, "Content-Length: ") {
+		t.Fatalf("written = %q, want a Content-Length framed message", got)
+	}
+}
+
+func TestIsolatedIOConn_RejectsBadContentLength(t *testing.T) {
+	for name, output := range map[string]string{
+		"too large":    fmt.Sprintf("Content-Length: %d\r\n\r\n{}", maxFramedMessageSize+1),
+		"not a number": "Content-Length: ten\r\n\r\n{}",
+		"truncated":    "Content-Length: 50\r\n\r\n{}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			conn := newIsolatedIOConn(&scriptedRWC{Reader: strings.NewReader(output)}, "", 0)
+			defer conn.Close()
+			if _, err := conn.Read(context.Background()); err == nil {
+				t.Fatal("Read() succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const defaultPingTimeout = 10 * time.Second
+
+// startKeepalive starts pinging conn when its config sets a ping interval.
+// The caller must hold m.mu.
+func (m *Manager) startKeepalive(conn *ServerConnection) {
+	if conn.config.PingInterval <= 0 || m.ctx == nil {
+		return
+	}
+	interval := time.Duration(conn.config.PingInterval) * time.Second
+	timeout := defaultPingTimeout
+	if conn.config.PingTimeout > 0 {
+		timeout = time.Duration(conn.config.PingTimeout) * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	conn.stopKeepalive = cancel
+	go m.keepalive(ctx, conn, interval, timeout)
+}
+
+// keepalive pings the server every interval. A process can stay alive while
+// its stdio is wedged, so a ping that fails or times out is treated as a dead
+// connection and the server is reconnected. The loop ends once a new
+// connection (with its own keepalive) has replaced conn.
+func (m *Manager) keepalive(ctx context.Context, conn *ServerConnection, interval, timeout time.Duration) {
+	defer conn.stopKeepalive()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := conn.Session.Ping(pingCtx, nil)
+		cancel()
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.WarnCF("mcp", "MCP server did not answer ping, reconnecting",
+			map[string]any{
+				"server": conn.Name,
+				"error":  err.Error(),
+			})
+		_ = conn.Session.Close()
+		if err := m.ConnectServer(m.ctx, conn.Name, conn.config); err != nil {
+			logger.ErrorCF("mcp", "Failed to reconnect MCP server, retrying at next interval",
+				map[string]any{
+					"server": conn.Name,
+					"error":  err.Error(),
+				})
+			continue
+		}
+		return
+	}
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// newHangingMCPServer serves a minimal MCP server over streamable HTTP. While
+// hang is set, ping requests never get an answer.
+func newHangingMCPServer(t *testing.T, hang *atomic.Bool, initializes *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := sdkmcp.NewServer(&sdkmcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	handler := sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server { return server }, nil)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			body, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if bytes.Contains(body, []byte(`"method":"initialize"`)) {
+				initializes.Add(1)
+			}
+			if hang.Load() && bytes.Contains(body, []byte(`"method":"ping"`)) {
+				<-r.Context().Done()
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestKeepaliveReconnectsUnresponsiveServer(t *testing.T) {
+	var hang atomic.Bool
+	var initializes atomic.Int32
+	srv := newHangingMCPServer(t, &hang, &initializes)
+
+	mgr := NewManager()
+	defer mgr.Close()
+	cfg := config.MCPServerConfig{Enabled: true, Type: "http", URL: srv.URL, PingInterval: 1, PingTimeout: 1}
+	if err := mgr.ConnectServer(t.Context(), "wedged", cfg); err != nil {
+		t.Fatalf("ConnectServer() error = %v", err)
+	}
+	first, _ := mgr.GetServer("wedged")
+
+	hang.Store(true)
+	deadline := time.Now().Add(10 * time.Second)
+	for initializes.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	hang.Store(false)
+	if initializes.Load() < 2 {
+		t.Fatal("expected a reconnect after the ping timed out")
+	}
+
+	current, ok := mgr.GetServer("wedged")
+	if !ok || current == first {
+		t.Fatal("expected the stale connection to be replaced")
+	}
+	if err := current.Session.Ping(t.Context(), nil); err != nil {
+		t.Fatalf("Ping() on new connection error = %v", err)
+	}
+}
+
+func TestKeepaliveDisabledByDefault(t *testing.T) {
+	var hang atomic.Bool
+	var initializes atomic.Int32
+	srv := newHangingMCPServer(t, &hang, &initializes)
+
+	mgr := NewManager()
+	defer mgr.Close()
+	if err := mgr.ConnectServer(t.Context(), "plain", config.MCPServerConfig{Type: "http", URL: srv.URL}); err != nil {
+		t.Fatalf("ConnectServer() error = %v", err)
+	}
+	conn, _ := mgr.GetServer("plain")
+	if conn.stopKeepalive != nil {
+		t.Fatal("keepalive started without ping_interval")
+	}
+}
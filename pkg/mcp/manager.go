@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -46,6 +47,7 @@ func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 // Each line should be in the format: KEY=value
 // Lines starting with # are comments
 // Empty lines are ignored
+// ${VAR} and $VAR are expanded unless the value is single-quoted
 func loadEnvFile(path string) (map[string]string, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -79,13 +81,19 @@ func loadEnvFile(path string) (map[string]string, error) {
 			return nil, fmt.Errorf("invalid format at line %d: empty key", lineNum)
 		}
 
-		// Remove surrounding quotes if present
+		// Remove surrounding quotes if present. Single-quoted values are
+		// taken literally; everything else has references expanded.
+		literal := false
 		if len(value) >= 2 {
 			if (value[0] == '"' && value[len(value)-1] == '"') ||
 				(value[0] == '\'' && value[len(value)-1] == '\'') {
+				literal = value[0] == '\''
 				value = value[1 : len(value)-1]
 			}
 		}
+		if !literal {
+			value = expandEnvValue(value, envVars)
+		}
 
 		envVars[key] = value
 	}
@@ -97,12 +105,55 @@ func loadEnvFile(path string) (map[string]string, error) {
 	return envVars, nil
 }
 
+// expandEnvValue expands ${VAR} in an env file value, preferring variables
+// defined earlier in the same file over the process environment. Any other
+// "$" is kept, so passwords and prices need no escaping; "$${" yields a
+// literal "${". Undefined variables expand to "".
+func expandEnvValue(value string, fileVars map[string]string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(value, "${")
+		if i < 0 {
+			b.WriteString(value)
+			return b.String()
+		}
+		if i > 0 && value[i-1] == '$' {
+			b.WriteString(value[:i-1] + "${")
+			value = value[i+2:]
+			continue
+		}
+		end := strings.IndexByte(value[i+2:], '}')
+		if end < 0 {
+			b.WriteString(value)
+			return b.String()
+		}
+		b.WriteString(value[:i])
+		name := value[i+2 : i+2+end]
+		if v, ok := fileVars[name]; ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString(os.Getenv(name))
+		}
+		value = value[i+3+end:]
+	}
+}
+
 // ServerConnection represents a connection to an MCP server
 type ServerConnection struct {
 	Name    string
 	Client  *mcp.Client
 	Session *mcp.ClientSession
 	Tools   []*mcp.Tool
+	// ProtocolVersion is the MCP protocol version negotiated with the server.
+	ProtocolVersion string
+
+	config        config.MCPServerConfig // used to reconnect
+	stopKeepalive context.CancelFunc
+	stderr        *tailBuffer // nil for HTTP transports
+	refreshMu     sync.Mutex  // serializes tool list refreshes
 }
 
 // Manager manages multiple MCP server connections
@@ -111,12 +162,31 @@ type Manager struct {
 	mu      sync.RWMutex
 	closed  atomic.Bool    // changed from bool to atomic.Bool to avoid TOCTOU race
 	wg      sync.WaitGroup // tracks in-flight CallTool calls
+
+	// ctx outlives individual connections; keepalive loops and reconnected
+	// stdio processes are bound to it and stop on Close.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// configs holds every server ConnectServer was asked for, connected or
+	// not, so failed servers can be reported and restarted.
+	configs  map[string]config.MCPServerConfig
+	failures map[string]serverFailure
+
+	// onToolsChanged is called after a server's tool list was refreshed in
+	// response to a tools/list_changed notification.
+	onToolsChanged func(serverName string)
 }
 
 // NewManager creates a new MCP manager
 func NewManager() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Manager{
-		servers: make(map[string]*ServerConnection),
+		servers:  make(map[string]*ServerConnection),
+		ctx:      ctx,
+		cancel:   cancel,
+		configs:  make(map[string]config.MCPServerConfig),
+		failures: make(map[string]serverFailure),
 	}
 }
 
@@ -165,24 +235,16 @@ func (m *Manager) LoadFromMCPConfig(
 		go func(name string, serverCfg config.MCPServerConfig, workspace string) {
 			defer wg.Done()
 
-			// Resolve relative envFile paths relative to workspace
-			if serverCfg.EnvFile != "" && !filepath.IsAbs(serverCfg.EnvFile) {
-				if workspace == "" {
-					err := fmt.Errorf(
-						"workspace path is empty while resolving relative envFile %q for server %s",
-						serverCfg.EnvFile,
-						name,
-					)
-					logger.ErrorCF("mcp", "Invalid MCP server configuration",
-						map[string]any{
-							"server":   name,
-							"env_file": serverCfg.EnvFile,
-							"error":    err.Error(),
-						})
-					errs <- err
-					return
-				}
-				serverCfg.EnvFile = filepath.Join(workspace, serverCfg.EnvFile)
+			serverCfg, err := resolveServerConfig(name, serverCfg, workspace)
+			if err != nil {
+				logger.ErrorCF("mcp", "Invalid MCP server configuration",
+					map[string]any{
+						"server":   name,
+						"env_file": serverCfg.EnvFile,
+						"error":    err.Error(),
+					})
+				errs <- err
+				return
 			}
 
 			if err := m.ConnectServer(ctx, name, serverCfg); err != nil {
@@ -236,12 +298,54 @@ func (m *Manager) LoadFromMCPConfig(
 	return nil
 }
 
-// ConnectServer connects to a single MCP server
+// resolveServerConfig resolves a relative envFile against the workspace.
+func resolveServerConfig(name string, cfg config.MCPServerConfig, workspace string) (config.MCPServerConfig, error) {
+	if cfg.EnvFile == "" || filepath.IsAbs(cfg.EnvFile) {
+		return cfg, nil
+	}
+	if workspace == "" {
+		return cfg, fmt.Errorf(
+			"workspace path is empty while resolving relative envFile %q for server %s",
+			cfg.EnvFile,
+			name,
+		)
+	}
+	cfg.EnvFile = filepath.Join(workspace, cfg.EnvFile)
+	return cfg, nil
+}
+
+// ConnectServer connects to a single MCP server. A failure is remembered
+// with the server's stderr so ServerStatus can report it.
 func (m *Manager) ConnectServer(
 	ctx context.Context,
 	name string,
 	cfg config.MCPServerConfig,
 ) error {
+	m.mu.Lock()
+	m.configs[name] = cfg
+	m.mu.Unlock()
+
+	var stderr *tailBuffer
+	err := m.connectServer(ctx, name, cfg, &stderr)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.failures[name] = serverFailure{err: err, stderr: stderr}
+	} else {
+		delete(m.failures, name)
+	}
+	return err
+}
+
+func (m *Manager) connectServer(
+	ctx context.Context,
+	name string,
+	cfg config.MCPServerConfig,
+	stderr **tailBuffer,
+) error {
+	// m.configs keeps the ${VAR} references; only the connection sees values.
+	cfg = cfg.WithEnvExpanded(name)
 	logger.InfoCF("mcp", "Connecting to MCP server",
 		map[string]any{
 			"server":     name,
@@ -249,11 +353,22 @@ func (m *Manager) ConnectServer(
 			"args_count": len(cfg.Args),
 		})
 
+	if !validProtocolVersion(cfg.ProtocolVersion) {
+		return fmt.Errorf("unsupported protocol_version: %s (supported: %s)",
+			cfg.ProtocolVersion, strings.Join(protocolVersions, ", "))
+	}
+
 	// Create client
 	client := mcp.NewClient(&mcp.Implementation{
 		Name:    "picoclaw",
 		Version: "1.0.0",
-	}, nil)
+	}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(_ context.Context, req *mcp.ToolListChangedRequest) {
+			// Listing tools is a request on the same session, so it must not
+			// block the notification handler.
+			go m.refreshTools(name, req.Session)
+		},
+	})
 
 	// Create transport based on configuration
 	// Auto-detect transport type if not explicitly specified
@@ -297,14 +412,12 @@ func (m *Manager) ConnectServer(
 			DisableStandaloneSSE: disableStandaloneSSE,
 		}
 
+		var roundTripper http.RoundTripper = http.DefaultTransport
 		// Add custom headers if provided
 		if len(cfg.Headers) > 0 {
-			// Create a custom HTTP client with header-injecting transport
-			sseTransport.HTTPClient = &http.Client{
-				Transport: &headerTransport{
-					base:    http.DefaultTransport,
-					headers: cfg.Headers,
-				},
+			roundTripper = &headerTransport{
+				base:    roundTripper,
+				headers: cfg.Headers,
 			}
 			logger.DebugCF("mcp", "Added custom HTTP headers",
 				map[string]any{
@@ -312,12 +425,22 @@ func (m *Manager) ConnectServer(
 					"header_count": len(cfg.Headers),
 				})
 		}
+		if cfg.ProtocolVersion != "" {
+			roundTripper = &protocolVersionTransport{base: roundTripper, version: cfg.ProtocolVersion}
+		}
+		if roundTripper != http.DefaultTransport {
+			sseTransport.HTTPClient = &http.Client{Transport: roundTripper}
+		}
 
 		transport = sseTransport
 	case "stdio":
 		if cfg.Command == "" {
 			return fmt.Errorf("command is required for stdio transport")
 		}
+		framing := strings.ToLower(strings.TrimSpace(cfg.Framing))
+		if !validFraming(framing) {
+			return fmt.Errorf("unsupported framing: %s (supported: auto, newline, content-length)", cfg.Framing)
+		}
 		logger.DebugCF("mcp", "Using stdio transport",
 			map[string]any{
 				"server":  name,
@@ -365,7 +488,14 @@ func (m *Manager) ConnectServer(
 			env = append(env, fmt.Sprintf("%s=%s", k, v))
 		}
 		cmd.Env = env
-		transport = &isolatedCommandTransport{Command: cmd}
+		*stderr = newTailBuffer(maxStderrTail)
+		cmd.Stderr = *stderr
+		transport = &isolatedCommandTransport{
+			Command:         cmd,
+			Framing:         framing,
+			ReadBufferSize:  cfg.ReadBufferSize,
+			ProtocolVersion: cfg.ProtocolVersion,
+		}
 	default:
 		return fmt.Errorf(
 			"unsupported transport type: %s (supported: stdio, sse, http)",
@@ -392,38 +522,100 @@ func (m *Manager) ConnectServer(
 	// List available tools if supported
 	var tools []*mcp.Tool
 	if initResult.Capabilities.Tools != nil {
-		for tool, err := range session.Tools(ctx, nil) {
-			if err != nil {
-				logger.WarnCF("mcp", "Error listing tool",
-					map[string]any{
-						"server": name,
-						"error":  err.Error(),
-					})
-				continue
-			}
-			tools = append(tools, tool)
-		}
-
-		logger.InfoCF("mcp", "Listed tools from MCP server",
-			map[string]any{
-				"server":    name,
-				"toolCount": len(tools),
-			})
+		tools = listTools(ctx, name, session)
 	}
 
 	// Store connection
+	conn := &ServerConnection{
+		Name:            name,
+		Client:          client,
+		Session:         session,
+		Tools:           tools,
+		ProtocolVersion: initResult.ProtocolVersion,
+		config:          cfg,
+		stderr:          *stderr,
+	}
 	m.mu.Lock()
-	m.servers[name] = &ServerConnection{
-		Name:    name,
-		Client:  client,
-		Session: session,
-		Tools:   tools,
+	if m.closed.Load() {
+		m.mu.Unlock()
+		_ = session.Close()
+		return fmt.Errorf("manager is closed")
 	}
+	m.servers[name] = conn
+	m.startKeepalive(conn)
 	m.mu.Unlock()
 
 	return nil
 }
 
+func listTools(ctx context.Context, name string, session *mcp.ClientSession) []*mcp.Tool {
+	var tools []*mcp.Tool
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			logger.WarnCF("mcp", "Error listing tool",
+				map[string]any{
+					"server": name,
+					"error":  err.Error(),
+				})
+			continue
+		}
+		tools = append(tools, tool)
+	}
+
+	logger.InfoCF("mcp", "Listed tools from MCP server",
+		map[string]any{
+			"server":    name,
+			"toolCount": len(tools),
+		})
+	return tools
+}
+
+// SetToolsChangedHandler sets the function called after a server announced
+// that its tool list changed and the new list has been fetched, and after a
+// reconnect or reload finished connecting a server past its deadline.
+func (m *Manager) SetToolsChangedHandler(fn func(serverName string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onToolsChanged = fn
+}
+
+// refreshTools re-lists the tools of the server owning session and replaces
+// its tool list, so tools the server dropped disappear as well.
+func (m *Manager) refreshTools(name string, session *mcp.ClientSession) {
+	m.mu.RLock()
+	conn, ok := m.servers[name]
+	m.mu.RUnlock()
+	if !ok || conn.Session != session {
+		return
+	}
+
+	conn.refreshMu.Lock()
+	defer conn.refreshMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
+	defer cancel()
+	tools := listTools(ctx, name, session)
+	if ctx.Err() != nil {
+		logger.WarnCF("mcp", "Failed to refresh MCP server tools",
+			map[string]any{"server": name, "error": ctx.Err().Error()})
+		return
+	}
+
+	m.mu.Lock()
+	if m.servers[name] != conn {
+		// Reconnected or closed meanwhile; the new connection has fresh tools.
+		m.mu.Unlock()
+		return
+	}
+	conn.Tools = tools
+	handler := m.onToolsChanged
+	m.mu.Unlock()
+
+	if handler != nil {
+		handler(name)
+	}
+}
+
 // GetServers returns all connected servers
 func (m *Manager) GetServers() map[string]*ServerConnection {
 	m.mu.RLock()
@@ -494,6 +686,11 @@ func (m *Manager) Close() error {
 		return nil // already closed
 	}
 
+	// Stop keepalive loops so none of them reconnects while closing.
+	if m.cancel != nil {
+		m.cancel()
+	}
+
 	// Wait for all in-flight CallTool calls to finish before closing sessions
 	// After closed=true is set, no new CallTool can start (they check closed first)
 	m.wg.Wait()
@@ -519,6 +716,8 @@ func (m *Manager) Close() error {
 	}
 
 	m.servers = make(map[string]*ServerConnection)
+	m.configs = make(map[string]config.MCPServerConfig)
+	m.failures = make(map[string]serverFailure)
 
 	if len(errs) > 0 {
 		return fmt.Errorf("failed to close %d server(s): %w", len(errs), errors.Join(errs...))
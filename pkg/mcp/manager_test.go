@@ -2,10 +2,14 @@ package mcp
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -129,6 +133,46 @@ PORT =8080`,
 	}
 }
 
+func TestLoadEnvFileExpandsReferences(t *testing.T) {
+	t.Setenv("PICOCLAW_TEST_TOKEN", "secret")
+	t.Setenv("PICOCLAW_TEST_HOST", "process-host")
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	content := `HOST=db.internal
+URL=postgres://${HOST}:5432
+AUTH="Bearer ${PICOCLAW_TEST_TOKEN}"
+FROM_PROCESS=${PICOCLAW_TEST_HOST}
+LITERAL='${PICOCLAW_TEST_TOKEN}'
+PRICE=$5
+PASSWORD=pa$$w$HOST
+ESCAPED=$${HOST}
+MISSING=${PICOCLAW_TEST_UNDEFINED}`
+	if err := os.WriteFile(envFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to create .env file: %v", err)
+	}
+
+	got, err := loadEnvFile(envFile)
+	if err != nil {
+		t.Fatalf("loadEnvFile() error = %v", err)
+	}
+	want := map[string]string{
+		"HOST":         "db.internal",
+		"URL":          "postgres://db.internal:5432",
+		"AUTH":         "Bearer secret",
+		"FROM_PROCESS": "process-host",
+		"LITERAL":      "${PICOCLAW_TEST_TOKEN}",
+		"PRICE":        "$5",
+		"PASSWORD":     "pa$$w$HOST",
+		"ESCAPED":      "${HOST}",
+		"MISSING":      "",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
 func TestLoadEnvFileNotFound(t *testing.T) {
 	_, err := loadEnvFile("/nonexistent/file.env")
 	if err == nil {
@@ -306,3 +350,45 @@ func TestClose_IdempotentOnEmptyManager(t *testing.T) {
 		t.Fatalf("second close should be idempotent, got: %v", err)
 	}
 }
+
+func TestToolListChanged_RefreshesTools(t *testing.T) {
+	server := sdkmcp.NewServer(&sdkmcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	noop := func(context.Context, *sdkmcp.CallToolRequest, struct{}) (*sdkmcp.CallToolResult, any, error) {
+		return &sdkmcp.CallToolResult{}, nil, nil
+	}
+	sdkmcp.AddTool(server, &sdkmcp.Tool{Name: "old"}, noop)
+	sdkmcp.AddTool(server, &sdkmcp.Tool{Name: "kept"}, noop)
+	srv := httptest.NewServer(sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server { return server }, nil))
+	defer srv.Close()
+
+	mgr := NewManager()
+	defer mgr.Close()
+	changed := make(chan string, 4)
+	mgr.SetToolsChangedHandler(func(name string) { changed <- name })
+	if err := mgr.ConnectServer(t.Context(), "live", config.MCPServerConfig{Enabled: true, Type: "sse", URL: srv.URL}); err != nil {
+		t.Fatalf("ConnectServer() error = %v", err)
+	}
+
+	server.RemoveTools("old")
+	sdkmcp.AddTool(server, &sdkmcp.Tool{Name: "new"}, noop)
+
+	deadline := time.After(5 * time.Second)
+	for {
+		var names []string
+		for _, tool := range mgr.GetAllTools()["live"] {
+			names = append(names, tool.Name)
+		}
+		sort.Strings(names)
+		if strings.Join(names, ",") == "kept,new" {
+			break
+		}
+		select {
+		case name := <-changed:
+			if name != "live" {
+				t.Fatalf("changed handler got server %q, want live", name)
+			}
+		case <-deadline:
+			t.Fatalf("tools = %v, want [kept new]", names)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// protocolVersions are the MCP protocol versions the SDK can work with.
+// The SDK requests the newest one and rejects servers that answer with a
+// version outside this list.
+var protocolVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18", "2025-11-25"}
+
+// validProtocolVersion reports whether version can be pinned; empty means
+// no pin.
+func validProtocolVersion(version string) bool {
+	return version == "" || slices.Contains(protocolVersions, version)
+}
+
+// pinProtocolVersion rewrites data, one encoded JSON-RPC message, so that an
+// initialize request asks for version instead of the SDK's latest. Any other
+// message, or one that does not decode, is returned unchanged.
+func pinProtocolVersion(data []byte, version string) []byte {
+	if version == "" || !bytes.Contains(data, []byte(`"initialize"`)) {
+		return data
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return data
+	}
+	var method string
+	if err := json.Unmarshal(msg["method"], &method); err != nil || method != "initialize" {
+		return data
+	}
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(msg["params"], &params); err != nil || params == nil {
+		return data
+	}
+	params["protocolVersion"], _ = json.Marshal(version)
+	msg["params"], _ = json.Marshal(params)
+	out, err := json.Marshal(msg)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// protocolVersionTransport pins the protocol version of the initialize
+// request sent over the SSE/HTTP transports.
+type protocolVersionTransport struct {
+	base    http.RoundTripper
+	version string
+}
+
+func (t *protocolVersionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost && req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = pinProtocolVersion(body, t.version)
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	return t.base.RoundTrip(req)
+}
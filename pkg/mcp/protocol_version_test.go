@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestProtocolVersion_PinnedAndReported(t *testing.T) {
+	server := sdkmcp.NewServer(&sdkmcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	srv := httptest.NewServer(sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server { return server }, nil))
+	defer srv.Close()
+
+	mgr := NewManager()
+	defer mgr.Close()
+	for name, version := range map[string]string{"latest": "", "pinned": "2024-11-05"} {
+		cfg := config.MCPServerConfig{Enabled: true, Type: "http", URL: srv.URL, ProtocolVersion: version}
+		if err := mgr.ConnectServer(t.Context(), name, cfg); err != nil {
+			t.Fatalf("ConnectServer(%s) error = %v", name, err)
+		}
+	}
+
+	got := map[string]string{}
+	for _, st := range mgr.ServerStatus() {
+		got[st.Name] = st.ProtocolVersion
+	}
+	want := map[string]string{
+		"latest": protocolVersions[len(protocolVersions)-1],
+		"pinned": "2024-11-05",
+	}
+	for name, version := range want {
+		if got[name] != version {
+			t.Errorf("%s: protocol version = %q, want %q", name, got[name], version)
+		}
+	}
+
+	err := mgr.ConnectServer(t.Context(), "bad", config.MCPServerConfig{
+		Enabled: true, Type: "http", URL: srv.URL, ProtocolVersion: "2023-01-01",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported protocol_version") {
+		t.Fatalf("ConnectServer() with an unknown version error = %v", err)
+	}
+}
+
+func TestPinProtocolVersion_OnlyRewritesInitialize(t *testing.T) {
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-11-25","capabilities":{}}}`
+	got := string(pinProtocolVersion([]byte(initialize), "2025-03-26"))
+	if !strings.Contains(got, `"protocolVersion":"2025-03-26"`) || !strings.Contains(got, `"capabilities":{}`) {
+		t.Fatalf("pinned initialize = %s", got)
+	}
+
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"initialize"}}`,
+		`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"initialize"}}`,
+		`not json "initialize"`,
+	} {
+		if got := string(pinProtocolVersion([]byte(msg), "2025-03-26")); got != msg {
+			t.Errorf("pinProtocolVersion(%s) = %s, want it unchanged", msg, got)
+		}
+	}
+	if got := string(pinProtocolVersion([]byte(initialize), "")); got != initialize {
+		t.Errorf("empty version changed the message: %s", got)
+	}
+}
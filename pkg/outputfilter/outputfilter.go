@@ -0,0 +1,270 @@
+// Package outputfilter post-processes agent replies before they reach a
+// channel: redacting secrets and personal data, masking words and enforcing a
+// maximum length. Filters are composed into a Chain that applies them in a
+// fixed order.
+package outputfilter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// Filter transforms a reply. Apply must be safe for concurrent use.
+type Filter interface {
+	Name() string
+	Apply(content string) string
+}
+
+// Chain applies filters in order, each one seeing the previous one's output.
+// A nil Chain leaves content unchanged.
+type Chain struct {
+	filters []Filter
+}
+
+// NewChain returns a Chain that runs filters in the given order.
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{filters: filters}
+}
+
+// FromConfig builds a Chain from configured filters, keeping their order.
+func FromConfig(cfgs []config.OutputFilter) (*Chain, error) {
+	filters := make([]Filter, 0, len(cfgs))
+	for i := range cfgs {
+		f, err := newFromConfig(&cfgs[i])
+		if err != nil {
+			return nil, fmt.Errorf("output_filters[%d]: %w", i, err)
+		}
+		filters = append(filters, f)
+	}
+	return NewChain(filters...), nil
+}
+
+func newFromConfig(cfg *config.OutputFilter) (Filter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Type {
+	case config.OutputFilterRegex:
+		return NewRegex(cfg.Pattern, cfg.Replacement)
+	case config.OutputFilterMaxLength:
+		return NewMaxLength(cfg.MaxLength), nil
+	case config.OutputFilterPII:
+		return NewPII(cfg.Replacement, cfg.Kinds...)
+	default: // config.OutputFilterWords
+		return NewWords(cfg.Words, cfg.Replacement)
+	}
+}
+
+// Len returns the number of filters in the chain.
+func (c *Chain) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.filters)
+}
+
+// Apply runs content through every filter.
+func (c *Chain) Apply(content string) string {
+	if c == nil {
+		return content
+	}
+	for _, f := range c.filters {
+		content = f.Apply(content)
+	}
+	return content
+}
+
+// Regex replaces every match of a pattern. The replacement may reference
+// capture groups ($1, ${name}); an empty replacement deletes the match.
+type Regex struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+func NewRegex(pattern, replacement string) (*Regex, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return &Regex{re: re, replacement: replacement}, nil
+}
+
+func (f *Regex) Name() string { return config.OutputFilterRegex }
+
+func (f *Regex) Apply(content string) string {
+	return f.re.ReplaceAllString(content, f.replacement)
+}
+
+// MaxLength truncates content to a number of characters, marking the cut
+// with an ellipsis.
+type MaxLength struct {
+	limit int
+}
+
+func NewMaxLength(limit int) *MaxLength {
+	return &MaxLength{limit: limit}
+}
+
+func (f *MaxLength) Name() string { return config.OutputFilterMaxLength }
+
+func (f *MaxLength) Apply(content string) string {
+	if f.limit <= 0 || utf8.RuneCountInString(content) <= f.limit {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:f.limit-1]) + "…"
+}
+
+var piiPatterns = map[string]*regexp.Regexp{
+	"email": regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	"phone": regexp.MustCompile(`(?:\+\d{1,3}[\s.\-]?|\b)(?:\(\d{2,4}\)|\d{2,4})[\s.\-]?\d{3,4}[\s.\-]?\d{3,4}\b`),
+	"card":  regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+}
+
+// piiOrder fixes the order kinds are scrubbed in: card numbers first so the
+// phone pattern does not claim a fragment of them.
+var piiOrder = []string{"email", "card", "phone"}
+
+// PII replaces email addresses, phone numbers and payment card numbers.
+// Card candidates must pass the Luhn check, so ordinary long numbers such as
+// order IDs are left alone.
+type PII struct {
+	kinds       []string
+	replacement string
+}
+
+// NewPII scrubs the given kinds ("email", "phone", "card"), or all of them
+// when none are given. An empty replacement uses "[REDACTED <kind>]".
+func NewPII(replacement string, kinds ...string) (*PII, error) {
+	enabled := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		if _, ok := piiPatterns[kind]; !ok {
+			return nil, fmt.Errorf("unknown pii kind %q", kind)
+		}
+		enabled[kind] = true
+	}
+	f := &PII{replacement: replacement}
+	for _, kind := range piiOrder {
+		if len(enabled) == 0 || enabled[kind] {
+			f.kinds = append(f.kinds, kind)
+		}
+	}
+	return f, nil
+}
+
+func (f *PII) Name() string { return config.OutputFilterPII }
+
+func (f *PII) Apply(content string) string {
+	for _, kind := range f.kinds {
+		replacement := f.replacement
+		if replacement == "" {
+			replacement = "[REDACTED " + kind + "]"
+		}
+		content = piiPatterns[kind].ReplaceAllStringFunc(content, func(match string) string {
+			if kind == "card" && !luhnValid(match) {
+				return match
+			}
+			return replacement
+		})
+	}
+	return content
+}
+
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// Words masks whole-word, case-insensitive occurrences of a word list, such
+// as profanity or internal project names.
+type Words struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// NewWords masks words with replacement, or with asterisks of the same
+// length when replacement is empty.
+func NewWords(words []string, replacement string) (*Words, error) {
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil, fmt.Errorf("words filter requires words")
+	}
+	// Longest first, so "badword" is tried before its prefix "bad".
+	sort.SliceStable(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	// Go's \b only understands ASCII word characters, so word boundaries are
+	// checked in Apply instead.
+	re, err := regexp.Compile(`(?i)` + strings.Join(quoted, "|"))
+	if err != nil {
+		return nil, err
+	}
+	return &Words{re: re, replacement: replacement}, nil
+}
+
+func (f *Words) Name() string { return config.OutputFilterWords }
+
+func (f *Words) Apply(content string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range f.re.FindAllStringIndex(content, -1) {
+		start, end := loc[0], loc[1]
+		if !isWordBoundary(content, start, end) {
+			continue
+		}
+		b.WriteString(content[last:start])
+		if f.replacement != "" {
+			b.WriteString(f.replacement)
+		} else {
+			b.WriteString(strings.Repeat("*", utf8.RuneCountInString(content[start:end])))
+		}
+		last = end
+	}
+	if last == 0 {
+		return content
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+func isWordBoundary(s string, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(s[:start]); isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(s) {
+		if r, _ := utf8.DecodeRuneInString(s[end:]); isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,78 @@
+package outputfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestRegex_RedactsAndStrips(t *testing.T) {
+	redact, err := NewRegex(`sk-[A-Za-z0-9]{8,}`, "[REDACTED]")
+	require.NoError(t, err)
+	assert.Equal(t, "key: [REDACTED].", redact.Apply("key: sk-abcdef123456."))
+
+	strip, err := NewRegex(`(?s)\s*<internal>.*?</internal>`, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Done.", strip.Apply("Done. <internal>\nrouting notes\n</internal>"))
+
+	_, err = NewRegex("(", "")
+	assert.Error(t, err)
+}
+
+func TestMaxLength(t *testing.T) {
+	f := NewMaxLength(5)
+	assert.Equal(t, "héllo", f.Apply("héllo"))
+	assert.Equal(t, "héll…", f.Apply("héllo world"))
+}
+
+func TestPII(t *testing.T) {
+	f, err := NewPII("")
+	require.NoError(t, err)
+
+	out := f.Apply("Mail jane.doe@example.com, call +1 415-555-0134, card 4111 1111 1111 1111, order 1234567890123.")
+	assert.Equal(t,
+		"Mail [REDACTED email], call [REDACTED phone], card [REDACTED card], order 1234567890123.",
+		out)
+
+	emailOnly, err := NewPII("<hidden>", "email")
+	require.NoError(t, err)
+	assert.Equal(t, "<hidden> or 415-555-0134", emailOnly.Apply("a@b.io or 415-555-0134"))
+
+	_, err = NewPII("", "ssn")
+	assert.Error(t, err)
+}
+
+func TestWords(t *testing.T) {
+	f, err := NewWords([]string{"darn", "darnit", "Zephyr"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "**** it, ****** and ****** but darning is fine",
+		f.Apply("Darn it, darnit and zephyr but darning is fine"))
+
+	replaced, err := NewWords([]string{"zephyr"}, "[project]")
+	require.NoError(t, err)
+	assert.Equal(t, "see [project].", replaced.Apply("see Zephyr."))
+}
+
+func TestChain_AppliesInOrder(t *testing.T) {
+	chain, err := FromConfig([]config.OutputFilter{
+		{Type: config.OutputFilterRegex, Pattern: `secret-\w+`, Replacement: "[x]"},
+		{Type: config.OutputFilterMaxLength, MaxLength: 10},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, chain.Len())
+	assert.Equal(t, "token [x]…", chain.Apply("token secret-abc and more text"))
+
+	var nilChain *Chain
+	assert.Equal(t, "unchanged", nilChain.Apply("unchanged"))
+}
+
+func TestFromConfig_RejectsInvalid(t *testing.T) {
+	_, err := FromConfig([]config.OutputFilter{{Type: config.OutputFilterMaxLength}})
+	assert.ErrorContains(t, err, "output_filters[0]")
+
+	_, err = FromConfig([]config.OutputFilter{{Type: "shout"}})
+	assert.ErrorContains(t, err, "unknown output filter type")
+}
@@ -3,13 +3,16 @@ package anthropicprovider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 
+	"github.com/sipeed/picoclaw/pkg/providers/common"
 	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
@@ -21,6 +24,7 @@ type (
 	Message                = protocoltypes.Message
 	ToolDefinition         = protocoltypes.ToolDefinition
 	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
+	ModelInfo              = protocoltypes.ModelInfo
 )
 
 const (
@@ -46,6 +50,9 @@ func NewProviderWithBaseURL(token, apiBase string) *Provider {
 	client := anthropic.NewClient(
 		option.WithAuthToken(token),
 		option.WithBaseURL(baseURL),
+		// The SDK applies its own request timeouts; only share the pooled
+		// provider transport.
+		option.WithHTTPClient(&http.Client{Transport: common.SharedTransport("")}),
 	)
 	return &Provider{
 		client:  &client,
@@ -101,7 +108,7 @@ func (p *Provider) Chat(
 
 	resp, err := p.client.Messages.New(ctx, params, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("claude API call: %w", err)
+		return nil, apiCallError(err)
 	}
 
 	return parseResponse(resp), nil
@@ -123,12 +130,28 @@ func (p *Provider) chatStreaming(
 		}
 	}
 	if err := stream.Err(); err != nil {
-		return nil, fmt.Errorf("claude API call: %w", err)
+		return nil, apiCallError(err)
 	}
 
 	return parseResponse(&msg), nil
 }
 
+// apiCallError wraps a Messages API failure, as a ProviderError when the
+// API answered with an error status.
+func apiCallError(err error) error {
+	wrapped := fmt.Errorf("claude API call: %w", err)
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		return protocoltypes.NewProviderError("anthropic", apiErr.StatusCode, []byte(apiErr.RawJSON()), wrapped)
+	}
+	return wrapped
+}
+
+// ListModels implements providers.ModelLister with the curated Anthropic list.
+func (p *Provider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return common.AnthropicModels(), nil
+}
+
 func (p *Provider) GetDefaultModel() string {
 	return "claude-sonnet-4.6"
 }
@@ -145,6 +168,7 @@ func buildParams(
 ) (anthropic.MessageNewParams, error) {
 	var system []anthropic.TextBlockParam
 	var anthropicMessages []anthropic.MessageParam
+	caching := common.PromptCachingEnabled(options)
 
 	for _, msg := range messages {
 		switch msg.Role {
@@ -155,7 +179,7 @@ func buildParams(
 			if len(msg.SystemParts) > 0 {
 				for _, part := range msg.SystemParts {
 					block := anthropic.TextBlockParam{Text: part.Text}
-					if part.CacheControl != nil && part.CacheControl.Type == "ephemeral" {
+					if caching && part.CacheControl != nil && part.CacheControl.Type == "ephemeral" {
 						block.CacheControl = anthropic.NewCacheControlEphemeralParam()
 					}
 					system = append(system, block)
@@ -168,6 +192,12 @@ func buildParams(
 				anthropicMessages = append(anthropicMessages,
 					anthropic.NewUserMessage(anthropic.NewToolResultBlock(msg.ToolCallID, msg.Content, false)),
 				)
+			} else if images := buildImageBlocks(msg.Media); len(images) > 0 {
+				blocks := images
+				if msg.Content != "" {
+					blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
+				}
+				anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(blocks...))
 			} else {
 				anthropicMessages = append(anthropicMessages,
 					anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)),
@@ -233,13 +263,21 @@ func buildParams(
 
 	if len(tools) > 0 {
 		params.Tools = translateTools(tools)
+		if caching {
+			// A breakpoint on the last tool caches the whole tool schema,
+			// which is the first part of the prompt prefix.
+			params.Tools[len(params.Tools)-1].OfTool.CacheControl = anthropic.NewCacheControlEphemeralParam()
+		}
 	}
 
 	// Extended Thinking / Adaptive Thinking
 	// The thinking_level value directly determines the API parameter format:
 	//   "adaptive" → {thinking: {type: "adaptive"}} + output_config.effort
 	//   "low/medium/high/xhigh" → {thinking: {type: "enabled", budget_tokens: N}}
-	if level, ok := options["thinking_level"].(string); ok && level != "" && level != "off" {
+	// An explicit thinking_budget replaces the level's budget.
+	if budget, ok := common.AsInt(options["thinking_budget"]); ok && budget > 0 {
+		applyThinkingBudget(&params, int64(budget))
+	} else if level, ok := options["thinking_level"].(string); ok && level != "" && level != "off" {
 		applyThinkingConfig(&params, level)
 	}
 
@@ -269,11 +307,28 @@ func applyThinkingConfig(params *anthropic.MessageNewParams, level string) {
 		return
 	}
 
-	budget := int64(levelToBudget(level))
-	if budget <= 0 {
-		return
+	if budget := int64(common.ThinkingLevelBudget(level)); budget > 0 {
+		setThinkingBudget(params, budget)
+	}
+}
+
+// applyThinkingBudget enables thinking with an explicit budget_tokens.
+func applyThinkingBudget(params *anthropic.MessageNewParams, budget int64) {
+	if params.Temperature.Valid() {
+		log.Printf("anthropic: temperature cleared because thinking is enabled (budget=%d)", budget)
+	}
+	params.Temperature = anthropic.MessageNewParams{}.Temperature
+	// The API rejects budgets below its minimum.
+	if budget < minThinkingBudget {
+		budget = minThinkingBudget
 	}
+	setThinkingBudget(params, budget)
+}
+
+// minThinkingBudget is the smallest budget_tokens the API accepts.
+const minThinkingBudget = 1024
 
+func setThinkingBudget(params *anthropic.MessageNewParams, budget int64) {
 	// budget_tokens must be < max_tokens; clamp to respect user's max_tokens setting.
 	if budget >= params.MaxTokens {
 		log.Printf("anthropic: budget_tokens (%d) clamped to %d (max_tokens-1)", budget, params.MaxTokens-1)
@@ -285,28 +340,18 @@ func applyThinkingConfig(params *anthropic.MessageNewParams, level string) {
 	params.Thinking = anthropic.ThinkingConfigParamOfEnabled(budget)
 }
 
-// levelToBudget maps a thinking level to budget_tokens.
-// Values are based on Anthropic's recommendations and community best practices:
-//
-//	low    =  4,096  — simple reasoning, quick debugging (Claude Code "think")
-//	medium = 16,384  — Anthropic recommended sweet spot for most tasks
-//	high   = 32,000  — complex architecture, deep analysis (diminishing returns above this)
-//	xhigh  = 64,000  — extreme reasoning, research problems, benchmarks
-//
-// Note: For Claude 4.6+, prefer adaptive thinking over manual budget_tokens.
-func levelToBudget(level string) int {
-	switch level {
-	case "low":
-		return 4096
-	case "medium":
-		return 16384
-	case "high":
-		return 32000
-	case "xhigh":
-		return 64000
-	default:
-		return 0
+// buildImageBlocks converts image media (base64 data URLs or http(s) URLs)
+// into Anthropic image content blocks. Non-image media is ignored.
+func buildImageBlocks(media []string) []anthropic.ContentBlockParamUnion {
+	var blocks []anthropic.ContentBlockParamUnion
+	for _, src := range common.ImageSources(media) {
+		if src.URL != "" {
+			blocks = append(blocks, anthropic.NewImageBlock(anthropic.URLImageSourceParam{URL: src.URL}))
+			continue
+		}
+		blocks = append(blocks, anthropic.NewImageBlockBase64(src.MediaType, src.Data))
 	}
+	return blocks
 }
 
 func translateTools(tools []ToolDefinition) []anthropic.ToolUnionParam {
@@ -371,6 +416,8 @@ func parseResponse(resp *anthropic.Message) *LLMResponse {
 		finishReason = "length"
 	case anthropic.StopReasonEndTurn:
 		finishReason = "stop"
+	case anthropic.StopReasonRefusal:
+		finishReason = "content_filter"
 	}
 
 	return &LLMResponse{
@@ -378,11 +425,20 @@ func parseResponse(resp *anthropic.Message) *LLMResponse {
 		Reasoning:    reasoning.String(),
 		ToolCalls:    toolCalls,
 		FinishReason: finishReason,
-		Usage: &UsageInfo{
-			PromptTokens:     int(resp.Usage.InputTokens),
-			CompletionTokens: int(resp.Usage.OutputTokens),
-			TotalTokens:      int(resp.Usage.InputTokens + resp.Usage.OutputTokens),
-		},
+		Usage:        parseUsage(resp.Usage),
+	}
+}
+
+// parseUsage converts Anthropic usage. input_tokens excludes the prompt
+// tokens read from or written to the cache, so they are added back.
+func parseUsage(usage anthropic.Usage) *UsageInfo {
+	prompt := usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens
+	return &UsageInfo{
+		PromptTokens:     int(prompt),
+		CompletionTokens: int(usage.OutputTokens),
+		TotalTokens:      int(prompt + usage.OutputTokens),
+		CacheReadTokens:  int(usage.CacheReadInputTokens),
+		CacheWriteTokens: int(usage.CacheCreationInputTokens),
 	}
 }
 
@@ -52,6 +52,43 @@ func TestBuildParams_SystemMessage(t *testing.T) {
 	}
 }
 
+func TestBuildParams_UserMessageWithImages(t *testing.T) {
+	messages := []Message{
+		{
+			Role:    "user",
+			Content: "What is in this picture?",
+			Media: []string{
+				"data:image/png;base64,iVBORw0KGgo=",
+				"https://example.com/cat.jpg",
+				"data:audio/ogg;base64,T2dnUw==",
+			},
+		},
+	}
+	params, err := buildParams(messages, nil, "claude-sonnet-4.6", map[string]any{})
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if len(params.Messages) != 1 {
+		t.Fatalf("len(Messages) = %d, want 1", len(params.Messages))
+	}
+	blocks := params.Messages[0].Content
+	if len(blocks) != 3 {
+		t.Fatalf("len(Content) = %d, want 3 (2 images + text)", len(blocks))
+	}
+	if blocks[0].OfImage == nil || blocks[0].OfImage.Source.OfBase64 == nil {
+		t.Fatal("Content[0] should be a base64 image block")
+	}
+	if got := string(blocks[0].OfImage.Source.OfBase64.MediaType); got != "image/png" {
+		t.Errorf("MediaType = %q, want %q", got, "image/png")
+	}
+	if blocks[1].OfImage == nil || blocks[1].OfImage.Source.OfURL == nil {
+		t.Fatal("Content[1] should be a URL image block")
+	}
+	if blocks[2].OfText == nil || blocks[2].OfText.Text != "What is in this picture?" {
+		t.Error("Content[2] should be the text block")
+	}
+}
+
 func TestBuildParams_ToolCallMessage(t *testing.T) {
 	messages := []Message{
 		{Role: "user", Content: "What's the weather?"},
@@ -131,6 +168,7 @@ func TestParseResponse_StopReasons(t *testing.T) {
 		{anthropic.StopReasonEndTurn, "stop"},
 		{anthropic.StopReasonMaxTokens, "length"},
 		{anthropic.StopReasonToolUse, "tool_calls"},
+		{anthropic.StopReasonRefusal, "content_filter"},
 	}
 	for _, tt := range tests {
 		resp := &anthropic.Message{
@@ -94,29 +94,6 @@ func TestApplyThinkingConfig_UnknownLevel(t *testing.T) {
 	}
 }
 
-func TestLevelToBudget(t *testing.T) {
-	tests := []struct {
-		name  string
-		level string
-		want  int
-	}{
-		{"low", "low", 4096},
-		{"medium", "medium", 16384},
-		{"high", "high", 32000},
-		{"xhigh", "xhigh", 64000},
-		{"off", "off", 0},
-		{"empty", "", 0},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := levelToBudget(tt.level); got != tt.want {
-				t.Errorf("levelToBudget(%q) = %d, want %d", tt.level, got, tt.want)
-			}
-		})
-	}
-}
-
 func TestBuildParams_ThinkingClearsTemperature(t *testing.T) {
 	msgs := []Message{{Role: "user", Content: "hello"}}
 	opts := map[string]any{
@@ -141,6 +118,31 @@ func TestBuildParams_ThinkingClearsTemperature(t *testing.T) {
 	}
 }
 
+func TestBuildParams_ThinkingBudgetOverridesLevel(t *testing.T) {
+	msgs := []Message{{Role: "user", Content: "hello"}}
+	for budget, want := range map[int]int64{8000: 8000, 100: minThinkingBudget} {
+		opts := map[string]any{
+			"max_tokens":      32000,
+			"temperature":     0.8,
+			"thinking_level":  "high",
+			"thinking_budget": budget,
+		}
+		params, err := buildParams(msgs, nil, "claude-sonnet-4-6", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if params.Temperature.Valid() {
+			t.Error("temperature should be cleared when thinking_budget is set")
+		}
+		if params.Thinking.OfEnabled == nil {
+			t.Fatal("expected enabled thinking")
+		}
+		if got := params.Thinking.OfEnabled.BudgetTokens; got != want {
+			t.Errorf("thinking_budget %d: budget_tokens = %d, want %d", budget, got, want)
+		}
+	}
+}
+
 // unmarshalBlocks constructs []ContentBlockUnion via JSON round-trip so that
 // the internal JSON.raw field is populated (required by AsText/AsThinking).
 func unmarshalBlocks(t *testing.T, jsonStr string) []anthropic.ContentBlockUnion {
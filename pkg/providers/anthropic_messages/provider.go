@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/providers/common"
 	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
@@ -27,12 +28,19 @@ type (
 	Message                = protocoltypes.Message
 	ToolDefinition         = protocoltypes.ToolDefinition
 	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
+	ModelInfo              = protocoltypes.ModelInfo
 )
 
 const (
 	defaultAPIVersion     = "2023-06-01"
 	defaultBaseURL        = "https://api.anthropic.com/v1"
 	defaultRequestTimeout = 120 * time.Second
+	// defaultMaxTokens is sent when the caller sets no limit; the Messages
+	// API rejects requests without max_tokens.
+	defaultMaxTokens = 8192
+	oauthBetaHeader  = "oauth-2025-04-20"
+	// minThinkingBudget is the smallest budget_tokens the API accepts.
+	minThinkingBudget = 1024
 )
 
 // Provider implements Anthropic Messages API via HTTP (without SDK).
@@ -42,6 +50,8 @@ type Provider struct {
 	apiBase    string
 	httpClient *http.Client
 	userAgent  string
+	headers    map[string]string
+	bearer     bool
 }
 
 // NewProvider creates a new Anthropic Messages API provider.
@@ -58,15 +68,35 @@ func NewProviderWithTimeout(apiKey, apiBase, userAgent string, timeoutSeconds in
 	}
 
 	return &Provider{
-		apiKey:    apiKey,
-		apiBase:   baseURL,
-		userAgent: userAgent,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
+		apiKey:     apiKey,
+		apiBase:    baseURL,
+		userAgent:  userAgent,
+		httpClient: common.NewHTTPClientWithTimeout("", timeout),
 	}
 }
 
+// SetCustomHeaders sets extra headers sent with every request. They are
+// applied last, so they override the built-in headers.
+func (p *Provider) SetCustomHeaders(headers map[string]string) {
+	p.headers = headers
+}
+
+// SetAuthMethod selects how the API key is sent. "oauth" and "token" send it
+// as a bearer token, as Claude OAuth and setup tokens require; anything else
+// sends it in the x-api-key header.
+func (p *Provider) SetAuthMethod(method string) {
+	p.bearer = method == "oauth" || method == "token"
+}
+
+// EnableClientCredentials authenticates with an OAuth client-credentials
+// grant instead of the API key.
+func (p *Provider) EnableClientCredentials(creds common.ClientCredentials) {
+	common.EnableClientCredentials(p.httpClient, creds)
+}
+
+// SupportsThinking implements providers.ThinkingCapable.
+func (p *Provider) SupportsThinking() bool { return true }
+
 // Chat sends messages to the Anthropic Messages API and returns the response.
 func (p *Provider) Chat(
 	ctx context.Context,
@@ -105,11 +135,21 @@ func (p *Provider) Chat(
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-Key", p.apiKey) //nolint:canonicalheader // Anthropic API requires exact header name
+	if p.bearer {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+		req.Header.Set("Anthropic-Beta", oauthBetaHeader)
+	} else {
+		req.Header.Set("X-API-Key", p.apiKey) //nolint:canonicalheader // Anthropic API requires exact header name
+	}
 	req.Header.Set("Anthropic-Version", defaultAPIVersion)
 	if p.userAgent != "" {
 		req.Header.Set("User-Agent", p.userAgent)
 	}
+	for k, v := range p.headers {
+		if strings.TrimSpace(k) != "" {
+			req.Header.Set(k, v)
+		}
+	}
 
 	// Execute request
 	resp, err := p.httpClient.Do(req)
@@ -124,28 +164,36 @@ func (p *Provider) Chat(
 		return nil, fmt.Errorf("reading response body: %w", err)
 	}
 
-	// Check for HTTP errors with detailed messages
-	switch resp.StatusCode {
+	if resp.StatusCode != http.StatusOK {
+		return nil, protocoltypes.NewProviderError("anthropic", resp.StatusCode, body, statusError(resp.StatusCode, body))
+	}
+
+	// Parse response
+	return parseResponseBody(body)
+}
+
+// statusError describes an HTTP error response with a detailed message.
+func statusError(status int, body []byte) error {
+	switch status {
 	case http.StatusUnauthorized:
-		return nil, fmt.Errorf("authentication failed (401): check your API key")
+		return fmt.Errorf("authentication failed (401): check your API key")
 	case http.StatusTooManyRequests:
-		return nil, fmt.Errorf("rate limited (429): %s", string(body))
+		return fmt.Errorf("rate limited (429): %s", string(body))
 	case http.StatusBadRequest:
-		return nil, fmt.Errorf("bad request (400): %s", string(body))
+		return fmt.Errorf("bad request (400): %s", string(body))
 	case http.StatusNotFound:
-		return nil, fmt.Errorf("endpoint not found (404): %s", string(body))
+		return fmt.Errorf("endpoint not found (404): %s", string(body))
 	case http.StatusInternalServerError:
-		return nil, fmt.Errorf("internal server error (500): %s", string(body))
+		return fmt.Errorf("internal server error (500): %s", string(body))
 	case http.StatusServiceUnavailable:
-		return nil, fmt.Errorf("service unavailable (503): %s", string(body))
-	default:
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-		}
+		return fmt.Errorf("service unavailable (503): %s", string(body))
 	}
+	return fmt.Errorf("API request failed with status %d: %s", status, string(body))
+}
 
-	// Parse response
-	return parseResponseBody(body)
+// ListModels implements providers.ModelLister with the curated Anthropic list.
+func (p *Provider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return common.AnthropicModels(), nil
 }
 
 // GetDefaultModel returns the default model for this provider.
@@ -160,10 +208,10 @@ func buildRequestBody(
 	model string,
 	options map[string]any,
 ) (map[string]any, error) {
-	// max_tokens is required and guaranteed by agent loop
+	// max_tokens is required by the API; the agent loop normally sets it.
 	maxTokens, ok := asInt(options["max_tokens"])
-	if !ok {
-		return nil, fmt.Errorf("max_tokens is required in options")
+	if !ok || maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
 	}
 
 	result := map[string]any{
@@ -179,6 +227,9 @@ func buildRequestBody(
 
 	// Process messages
 	var systemPrompt string
+	var systemBlocks []map[string]any
+	systemCached := false
+	caching := common.PromptCachingEnabled(options)
 	var apiMessages []any
 
 	for _, msg := range messages {
@@ -190,6 +241,19 @@ func buildRequestBody(
 			} else {
 				systemPrompt = msg.Content
 			}
+			// Keep the structured parts too, in case one carries a cache
+			// breakpoint and the system prompt must be sent as blocks.
+			if len(msg.SystemParts) == 0 {
+				systemBlocks = append(systemBlocks, map[string]any{"type": "text", "text": msg.Content})
+			}
+			for _, part := range msg.SystemParts {
+				block := map[string]any{"type": "text", "text": part.Text}
+				if caching && part.CacheControl != nil && part.CacheControl.Type == "ephemeral" {
+					block["cache_control"] = map[string]any{"type": "ephemeral"}
+					systemCached = true
+				}
+				systemBlocks = append(systemBlocks, block)
+			}
 
 		case "user":
 			if msg.ToolCallID != "" {
@@ -211,6 +275,19 @@ func buildRequestBody(
 					"role":    "user",
 					"content": []map[string]any{toolResultBlock},
 				})
+			} else if images := buildImageBlocks(msg.Media); len(images) > 0 {
+				// User message with attached images
+				content := images
+				if msg.Content != "" {
+					content = append(content, map[string]any{
+						"type": "text",
+						"text": msg.Content,
+					})
+				}
+				apiMessages = append(apiMessages, map[string]any{
+					"role":    "user",
+					"content": content,
+				})
 			} else {
 				// Regular user message
 				apiMessages = append(apiMessages, map[string]any{
@@ -281,18 +358,72 @@ func buildRequestBody(
 	result["messages"] = apiMessages
 
 	// Set system prompt if present
-	if systemPrompt != "" {
+	if systemCached {
+		result["system"] = systemBlocks
+	} else if systemPrompt != "" {
 		result["system"] = systemPrompt
 	}
 
 	// Add tools if present
 	if len(tools) > 0 {
-		result["tools"] = buildTools(tools)
+		apiTools := buildTools(tools)
+		if caching {
+			// A breakpoint on the last tool caches the whole tool schema,
+			// which is the first part of the prompt prefix.
+			apiTools[len(apiTools)-1].(map[string]any)["cache_control"] = map[string]any{"type": "ephemeral"}
+		}
+		result["tools"] = apiTools
+	}
+
+	// An explicit thinking_budget replaces the level's budget.
+	if budget, ok := asInt(options["thinking_budget"]); ok && budget > 0 {
+		applyThinking(result, budget, maxTokens)
+	} else if level, ok := options["thinking_level"].(string); ok && level != "" && level != "off" {
+		if level == "adaptive" {
+			delete(result, "temperature")
+			result["thinking"] = map[string]any{"type": "adaptive"}
+			result["output_config"] = map[string]any{"effort": "high"}
+		} else if budget := common.ThinkingLevelBudget(level); budget > 0 {
+			applyThinking(result, budget, maxTokens)
+		}
 	}
 
 	return result, nil
 }
 
+// applyThinking enables extended thinking with budget_tokens, raised to the
+// API minimum and kept below max_tokens as the API requires. Temperature
+// cannot be combined with thinking and is dropped.
+func applyThinking(body map[string]any, budget, maxTokens int) {
+	budget = max(budget, minThinkingBudget)
+	if budget >= maxTokens {
+		budget = maxTokens - 1
+	}
+	delete(body, "temperature")
+	body["thinking"] = map[string]any{"type": "enabled", "budget_tokens": int64(budget)}
+}
+
+// buildImageBlocks converts image media (base64 data URLs or http(s) URLs)
+// into Anthropic image content blocks. Non-image media is ignored.
+func buildImageBlocks(media []string) []any {
+	var blocks []any
+	for _, src := range common.ImageSources(media) {
+		source := map[string]any{
+			"type":       "base64",
+			"media_type": src.MediaType,
+			"data":       src.Data,
+		}
+		if src.URL != "" {
+			source = map[string]any{
+				"type": "url",
+				"url":  src.URL,
+			}
+		}
+		blocks = append(blocks, map[string]any{"type": "image", "source": source})
+	}
+	return blocks
+}
+
 // buildTools converts tool definitions to Anthropic format.
 func buildTools(tools []ToolDefinition) []any {
 	result := make([]any, len(tools))
@@ -315,13 +446,15 @@ func parseResponseBody(body []byte) (*LLMResponse, error) {
 	}
 
 	// Extract content and tool calls
-	var content strings.Builder
+	var content, reasoning strings.Builder
 	toolCalls := make([]ToolCall, 0) // Initialize as empty slice (not nil) for consistent JSON serialization
 
 	for _, block := range resp.Content {
 		switch block.Type {
 		case "text":
 			content.WriteString(block.Text)
+		case "thinking":
+			reasoning.WriteString(block.Thinking)
 		case "tool_use":
 			argsJSON, _ := json.Marshal(block.Input)
 			toolCalls = append(toolCalls, ToolCall{
@@ -347,20 +480,32 @@ func parseResponseBody(body []byte) (*LLMResponse, error) {
 		finishReason = "stop"
 	case "stop_sequence":
 		finishReason = "stop"
+	case "refusal":
+		finishReason = "content_filter"
 	}
 
 	return &LLMResponse{
 		Content:      content.String(),
+		Reasoning:    reasoning.String(),
 		ToolCalls:    toolCalls,
 		FinishReason: finishReason,
-		Usage: &UsageInfo{
-			PromptTokens:     int(resp.Usage.InputTokens),
-			CompletionTokens: int(resp.Usage.OutputTokens),
-			TotalTokens:      int(resp.Usage.InputTokens + resp.Usage.OutputTokens),
-		},
+		Usage:        parseUsage(resp.Usage),
 	}, nil
 }
 
+// parseUsage converts Anthropic usage. input_tokens excludes the prompt
+// tokens read from or written to the cache, so they are added back.
+func parseUsage(usage usageInfo) *UsageInfo {
+	prompt := usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens
+	return &UsageInfo{
+		PromptTokens:     int(prompt),
+		CompletionTokens: int(usage.OutputTokens),
+		TotalTokens:      int(prompt + usage.OutputTokens),
+		CacheReadTokens:  int(usage.CacheReadInputTokens),
+		CacheWriteTokens: int(usage.CacheCreationInputTokens),
+	}
+}
+
 // normalizeBaseURL ensures the base URL is properly formatted.
 // It removes /v1 suffix if present (to avoid duplication) and always appends /v1.
 // This handles edge cases like "https://api.example.com/v1/proxy" correctly.
@@ -429,14 +574,17 @@ type anthropicMessageResponse struct {
 }
 
 type contentBlock struct {
-	Type  string         `json:"type"`
-	Text  string         `json:"text,omitempty"`
-	ID    string         `json:"id,omitempty"`
-	Name  string         `json:"name,omitempty"`
-	Input map[string]any `json:"input,omitempty"`
+	Type     string         `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	Thinking string         `json:"thinking,omitempty"`
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name,omitempty"`
+	Input    map[string]any `json:"input,omitempty"`
 }
 
 type usageInfo struct {
-	InputTokens  int64 `json:"input_tokens"`
-	OutputTokens int64 `json:"output_tokens"`
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
 }
@@ -8,9 +8,13 @@ package anthropicmessages
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestBuildRequestBody(t *testing.T) {
@@ -118,14 +122,22 @@ func TestBuildRequestBody(t *testing.T) {
 			},
 		},
 		{
-			name: "missing max_tokens returns error",
+			name: "missing max_tokens uses default",
 			messages: []Message{
 				{Role: "user", Content: "Test"},
 			},
 			model:   "test-model",
 			options: map[string]any{},
-			want:    nil,
-			wantErr: true,
+			want: map[string]any{
+				"model":      "test-model",
+				"max_tokens": int64(defaultMaxTokens),
+				"messages": []any{
+					map[string]any{
+						"role":    "user",
+						"content": "Test",
+					},
+				},
+			},
 		},
 		{
 			name: "with tools",
@@ -175,6 +187,7 @@ func TestBuildRequestBody(t *testing.T) {
 								},
 							},
 						},
+						"cache_control": map[string]any{"type": "ephemeral"},
 					},
 				},
 			},
@@ -234,6 +247,22 @@ func TestParseResponseBody(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "refusal maps to content_filter",
+			body: []byte(`{
+				"content": [],
+				"stop_reason": "refusal",
+				"usage": {"input_tokens": 3, "output_tokens": 0}
+			}`),
+			want: &LLMResponse{
+				ToolCalls:    []ToolCall{},
+				FinishReason: "content_filter",
+				Usage: &UsageInfo{
+					PromptTokens: 3,
+					TotalTokens:  3,
+				},
+			},
+		},
 		{
 			name: "response with tool use",
 			body: []byte(`{
@@ -423,6 +452,53 @@ func TestNewProvider(t *testing.T) {
 	}
 }
 
+func TestProviderChat_CustomHeaders(t *testing.T) {
+	var captured http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	provider := NewProvider("test-key", server.URL, "")
+	provider.SetCustomHeaders(map[string]string{"HTTP-Referer": "https://example.com", "X-Title": "PicoClaw"})
+	if _, err := provider.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "claude", map[string]any{"max_tokens": 16}); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if captured.Get("HTTP-Referer") != "https://example.com" || captured.Get("X-Title") != "PicoClaw" {
+		t.Errorf("headers = %v, want custom headers", captured)
+	}
+	if captured.Get("X-API-Key") != "test-key" {
+		t.Errorf("X-API-Key = %q, want built-in header kept", captured.Get("X-API-Key"))
+	}
+}
+
+func TestProviderChat_BearerAuthMethod(t *testing.T) {
+	var captured http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	provider := NewProvider("sk-ant-oat-token", server.URL, "")
+	provider.SetAuthMethod("token")
+	if _, err := provider.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "claude", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if got := captured.Get("Authorization"); got != "Bearer sk-ant-oat-token" {
+		t.Errorf("Authorization = %q, want bearer token", got)
+	}
+	if got := captured.Get("X-API-Key"); got != "" {
+		t.Errorf("X-API-Key = %q, want unset with bearer auth", got)
+	}
+	if got := captured.Get("Anthropic-Beta"); got != oauthBetaHeader {
+		t.Errorf("Anthropic-Beta = %q, want %q", got, oauthBetaHeader)
+	}
+}
+
 func TestGetDefaultModel(t *testing.T) {
 	provider := NewProvider("test-key", "", "")
 	got := provider.GetDefaultModel()
@@ -562,6 +638,42 @@ func TestBuildRequestBodyEdgeCases(t *testing.T) {
 	}
 }
 
+func TestBuildRequestBody_UserMessageWithImages(t *testing.T) {
+	messages := []Message{
+		{
+			Role:    "user",
+			Content: "Describe this",
+			Media:   []string{"data:image/jpeg;base64,/9j/4AAQ", "data:text/plain;base64,aGk="},
+		},
+	}
+
+	got, err := buildRequestBody(messages, nil, "test-model", map[string]any{"max_tokens": 1024})
+	if err != nil {
+		t.Fatalf("buildRequestBody() error: %v", err)
+	}
+
+	apiMessages := got["messages"].([]any)
+	msg := apiMessages[0].(map[string]any)
+	content, ok := msg["content"].([]any)
+	if !ok {
+		t.Fatalf("content is not []any: %T", msg["content"])
+	}
+	if len(content) != 2 {
+		t.Fatalf("expected image + text blocks, got %d", len(content))
+	}
+	image := content[0].(map[string]any)
+	if image["type"] != "image" {
+		t.Errorf("content[0].type = %v, want image", image["type"])
+	}
+	source := image["source"].(map[string]any)
+	if source["media_type"] != "image/jpeg" || source["data"] != "/9j/4AAQ" {
+		t.Errorf("unexpected image source: %v", source)
+	}
+	if text := content[1].(map[string]any); text["text"] != "Describe this" {
+		t.Errorf("content[1] = %v, want text block", text)
+	}
+}
+
 func TestBuildRequestBody_ConsecutiveToolResultsMerged(t *testing.T) {
 	// Consecutive tool results (role "tool") should be merged into a single "user" message
 	messages := []Message{
@@ -755,3 +867,112 @@ func TestProviderChatErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildRequestBody_PromptCaching(t *testing.T) {
+	messages := []Message{
+		{
+			Role:    "system",
+			Content: "static\n\ndynamic",
+			SystemParts: []protocoltypes.ContentBlock{
+				{Type: "text", Text: "static", CacheControl: &protocoltypes.CacheControl{Type: "ephemeral"}},
+				{Type: "text", Text: "dynamic"},
+			},
+		},
+		{Role: "user", Content: "hi"},
+	}
+	tools := []ToolDefinition{
+		{Type: "function", Function: ToolFunctionDefinition{Name: "a"}},
+		{Type: "function", Function: ToolFunctionDefinition{Name: "b"}},
+	}
+
+	got, err := buildRequestBody(messages, tools, "claude", map[string]any{"max_tokens": 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	system, ok := got["system"].([]map[string]any)
+	if !ok || len(system) != 2 {
+		t.Fatalf("system = %#v, want two blocks", got["system"])
+	}
+	if system[0]["cache_control"] == nil || system[1]["cache_control"] != nil {
+		t.Errorf("system cache_control = %#v, want only on the static block", system)
+	}
+	apiTools := got["tools"].([]any)
+	if apiTools[0].(map[string]any)["cache_control"] != nil || apiTools[1].(map[string]any)["cache_control"] == nil {
+		t.Errorf("tools = %#v, want cache_control on the last tool only", apiTools)
+	}
+
+	got, err = buildRequestBody(messages, tools, "claude", map[string]any{"max_tokens": 1024, "prompt_caching": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["system"] != "static\n\ndynamic" {
+		t.Errorf("system = %#v, want a plain string with caching off", got["system"])
+	}
+	for _, tool := range got["tools"].([]any) {
+		if tool.(map[string]any)["cache_control"] != nil {
+			t.Errorf("tool %#v has cache_control with caching off", tool)
+		}
+	}
+}
+
+func TestParseResponseBody_CacheUsage(t *testing.T) {
+	resp, err := parseResponseBody([]byte(`{
+		"content": [{"type": "text", "text": "ok"}],
+		"stop_reason": "end_turn",
+		"usage": {"input_tokens": 10, "cache_read_input_tokens": 900, "cache_creation_input_tokens": 90, "output_tokens": 5}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UsageInfo{PromptTokens: 1000, CompletionTokens: 5, TotalTokens: 1005, CacheReadTokens: 900, CacheWriteTokens: 90}
+	if *resp.Usage != want {
+		t.Errorf("Usage = %+v, want %+v", *resp.Usage, want)
+	}
+}
+
+func TestBuildRequestBody_Thinking(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "hi"}}
+
+	got, err := buildRequestBody(messages, nil, "claude", map[string]any{
+		"max_tokens": 20000, "temperature": 0.7, "thinking_level": "medium",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	thinking, _ := got["thinking"].(map[string]any)
+	if thinking["type"] != "enabled" || thinking["budget_tokens"] != int64(16384) {
+		t.Errorf("thinking = %#v, want enabled with the medium budget", got["thinking"])
+	}
+	if _, ok := got["temperature"]; ok {
+		t.Error("temperature is still set alongside thinking")
+	}
+
+	got, err = buildRequestBody(messages, nil, "claude", map[string]any{
+		"max_tokens": 4000, "thinking_level": "low", "thinking_budget": 9000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budget := got["thinking"].(map[string]any)["budget_tokens"]; budget != int64(3999) {
+		t.Errorf("budget_tokens = %v, want the budget clamped below max_tokens", budget)
+	}
+
+	got, err = buildRequestBody(messages, nil, "claude", map[string]any{"max_tokens": 4000, "thinking_level": "off"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["thinking"]; ok {
+		t.Errorf("thinking = %#v, want none when off", got["thinking"])
+	}
+
+	resp, err := parseResponseBody([]byte(`{
+		"content": [{"type": "thinking", "thinking": "let me see"}, {"type": "text", "text": "ok"}],
+		"stop_reason": "end_turn"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Reasoning != "let me see" || resp.Content != "ok" {
+		t.Errorf("response = %+v, want the thinking block as reasoning", resp)
+	}
+}
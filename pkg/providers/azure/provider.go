@@ -27,16 +27,24 @@ type (
 const (
 	defaultRequestTimeout = common.DefaultRequestTimeout
 	responsesAPIPath      = "openai/v1/responses"
+	deploymentsAPIPath    = "openai/deployments"
 )
 
 // Provider implements the LLM provider interface for Azure OpenAI endpoints.
 // It handles Azure-specific authentication (Bearer token), URL construction
 // (Responses API), and request/response formatting.
+//
+// When an API version is configured, the provider instead targets the classic
+// deployment-based Chat Completions endpoint
+// ({endpoint}/openai/deployments/{deployment}/chat/completions?api-version=...)
+// and authenticates with the api-key header.
 type Provider struct {
 	apiKey     string
 	apiBase    string
+	apiVersion string
 	httpClient *http.Client
 	userAgent  string
+	headers    map[string]string
 }
 
 // Option configures the Azure Provider.
@@ -58,6 +66,22 @@ func WithUserAgent(userAgent string) Option {
 	}
 }
 
+// WithAPIVersion switches the provider to the deployment-based Chat
+// Completions endpoint using the given api-version (e.g. "2024-10-21").
+func WithAPIVersion(apiVersion string) Option {
+	return func(p *Provider) {
+		p.apiVersion = strings.TrimSpace(apiVersion)
+	}
+}
+
+// WithCustomHeaders sets extra headers sent with every request. They are
+// applied last, so they override the built-in headers.
+func WithCustomHeaders(headers map[string]string) Option {
+	return func(p *Provider) {
+		p.headers = headers
+	}
+}
+
 // NewProvider creates a new Azure OpenAI provider.
 func NewProvider(apiKey, apiBase, proxy, userAgent string, opts ...Option) *Provider {
 	p := &Provider{
@@ -76,6 +100,11 @@ func NewProvider(apiKey, apiBase, proxy, userAgent string, opts ...Option) *Prov
 	return p
 }
 
+// EnableWireLog logs request and response bodies with credentials redacted.
+func (p *Provider) EnableWireLog(maxBytes int) {
+	common.EnableWireLog(p.httpClient, p.apiBase, maxBytes)
+}
+
 // NewProviderWithTimeout creates a new Azure OpenAI provider with a custom request timeout in seconds.
 func NewProviderWithTimeout(apiKey, apiBase, proxy, userAgent string, requestTimeoutSeconds int) *Provider {
 	return NewProvider(
@@ -97,6 +126,10 @@ func (p *Provider) Chat(
 		return nil, fmt.Errorf("Azure API base not configured")
 	}
 
+	if p.apiVersion != "" {
+		return p.chatDeployment(ctx, messages, tools, model, options)
+	}
+
 	requestURL, err := url.JoinPath(p.apiBase, responsesAPIPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build Azure request URL: %w", err)
@@ -153,6 +186,7 @@ func (p *Provider) Chat(
 	if p.userAgent != "" {
 		req.Header.Set("User-Agent", p.userAgent)
 	}
+	p.applyCustomHeaders(req)
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
@@ -161,13 +195,86 @@ func (p *Provider) Chat(
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, common.HandleErrorResponse(resp, p.apiBase)
+		return nil, common.HandleErrorResponse(resp, "azure", p.apiBase)
 	}
 
 	return orc.ParseResponseBody(resp.Body)
 }
 
+// chatDeployment sends a request to the classic Azure OpenAI Chat Completions
+// endpoint. The model parameter is used as the deployment name.
+func (p *Provider) chatDeployment(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	deployment string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	if deployment == "" {
+		return nil, fmt.Errorf("Azure deployment name not configured")
+	}
+
+	requestURL, err := url.JoinPath(p.apiBase, deploymentsAPIPath, deployment, "chat/completions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Azure request URL: %w", err)
+	}
+	requestURL += "?" + url.Values{"api-version": {p.apiVersion}}.Encode()
+
+	requestBody := map[string]any{
+		"messages": common.SerializeMessages(messages),
+	}
+	if len(tools) > 0 {
+		requestBody["tools"] = tools
+		requestBody["tool_choice"] = "auto"
+	}
+	if maxTokens, ok := common.AsInt(options["max_tokens"]); ok {
+		requestBody["max_completion_tokens"] = maxTokens
+	}
+	if temperature, ok := common.AsFloat(options["temperature"]); ok {
+		requestBody["temperature"] = temperature
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("api-key", p.apiKey)
+	}
+	if p.userAgent != "" {
+		req.Header.Set("User-Agent", p.userAgent)
+	}
+	p.applyCustomHeaders(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, common.HandleErrorResponse(resp, "azure", p.apiBase)
+	}
+
+	return common.ReadAndParseResponse(resp, p.apiBase)
+}
+
 // GetDefaultModel returns an empty string as Azure deployments are user-configured.
 func (p *Provider) GetDefaultModel() string {
 	return ""
 }
+
+func (p *Provider) applyCustomHeaders(req *http.Request) {
+	for k, v := range p.headers {
+		if strings.TrimSpace(k) != "" {
+			req.Header.Set(k, v)
+		}
+	}
+}
@@ -83,6 +83,29 @@ func TestProviderChat_AzureAuthHeader(t *testing.T) {
 	}
 }
 
+func TestProviderChat_AzureCustomHeaders(t *testing.T) {
+	var captured http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = r.Header.Clone()
+		writeValidResponse(w)
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, "", "picoclaw", WithCustomHeaders(map[string]string{
+		"X-Route":    "eu-west",
+		"User-Agent": "gateway-client",
+	}))
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "deployment", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if captured.Get("X-Route") != "eu-west" || captured.Get("User-Agent") != "gateway-client" {
+		t.Errorf("headers = %v, want custom headers applied over built-ins", captured)
+	}
+}
+
 func TestProviderChat_AzureRequestBodyContainsModel(t *testing.T) {
 	var requestBody map[string]any
 
@@ -415,3 +438,60 @@ func TestProviderChat_AzureNoNativeWebSearch(t *testing.T) {
 		t.Errorf("tool type = %v, want %q", tool["type"], "function")
 	}
 }
+
+func TestProviderChat_DeploymentEndpointWithAPIVersion(t *testing.T) {
+	var capturedPath, capturedVersion, capturedAPIKey, capturedAuth string
+	var requestBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedPath = r.URL.Path
+		capturedVersion = r.URL.Query().Get("api-version")
+		capturedAPIKey = r.Header.Get("Api-Key")
+		capturedAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{
+					"message":       map[string]any{"content": "ok"},
+					"finish_reason": "stop",
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-azure-key", server.URL, "", "", WithAPIVersion("2024-10-21"))
+	out, err := p.Chat(
+		t.Context(),
+		[]Message{{Role: "user", Content: "hi"}},
+		nil,
+		"my-deployment",
+		map[string]any{"max_tokens": 512},
+	)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if capturedPath != "/openai/deployments/my-deployment/chat/completions" {
+		t.Errorf("URL path = %q", capturedPath)
+	}
+	if capturedVersion != "2024-10-21" {
+		t.Errorf("api-version = %q, want %q", capturedVersion, "2024-10-21")
+	}
+	if capturedAPIKey != "test-azure-key" {
+		t.Errorf("api-key header = %q, want %q", capturedAPIKey, "test-azure-key")
+	}
+	if capturedAuth != "" {
+		t.Errorf("Authorization header should be empty, got %q", capturedAuth)
+	}
+	if _, ok := requestBody["model"]; ok {
+		t.Error("deployment requests should not send a model field")
+	}
+	if requestBody["max_completion_tokens"] != float64(512) {
+		t.Errorf("max_completion_tokens = %v, want 512", requestBody["max_completion_tokens"])
+	}
+	if out.Content != "ok" {
+		t.Errorf("Content = %q, want %q", out.Content, "ok")
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -21,6 +22,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
@@ -213,13 +215,38 @@ func (p *Provider) Chat(
 				err,
 			)
 		}
-		return nil, fmt.Errorf("bedrock converse: %w", err)
+		return nil, converseError(err)
 	}
 
 	// Parse the response
 	return parseResponse(output)
 }
 
+// converseError wraps a Converse failure, as a ProviderError when Bedrock
+// answered with an error status.
+func converseError(err error) error {
+	wrapped := fmt.Errorf("bedrock converse: %w", err)
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) {
+		return wrapped
+	}
+	pe := &protocoltypes.ProviderError{
+		Provider: "bedrock",
+		Status:   respErr.HTTPStatusCode(),
+		Err:      wrapped,
+	}
+	// Bedrock's modeled exceptions implement smithy.APIError.
+	var apiErr interface {
+		ErrorCode() string
+		ErrorMessage() string
+	}
+	if errors.As(err, &apiErr) {
+		pe.Code = apiErr.ErrorCode()
+		pe.Message = apiErr.ErrorMessage()
+	}
+	return pe
+}
+
 // GetDefaultModel returns an empty string as Bedrock models are user-configured.
 func (p *Provider) GetDefaultModel() string {
 	return ""
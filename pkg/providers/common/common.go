@@ -15,7 +15,6 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
@@ -34,34 +33,29 @@ type (
 	ExtraContent           = protocoltypes.ExtraContent
 	GoogleExtra            = protocoltypes.GoogleExtra
 	ReasoningDetail        = protocoltypes.ReasoningDetail
+	ModelInfo              = protocoltypes.ModelInfo
+	ProviderError          = protocoltypes.ProviderError
 )
 
 const DefaultRequestTimeout = 120 * time.Second
 
-// NewHTTPClient creates an *http.Client with an optional proxy and the default timeout.
+// NewHTTPClient creates an *http.Client on the shared provider transport with
+// an optional proxy and the default timeout.
 func NewHTTPClient(proxy string) *http.Client {
-	client := &http.Client{
-		Timeout: DefaultRequestTimeout,
+	return NewHTTPClientWithTimeout(proxy, DefaultRequestTimeout)
+}
+
+// NewHTTPClientWithTimeout is like NewHTTPClient with an overall request
+// timeout. A non-positive timeout selects DefaultRequestTimeout. Requests
+// made with a context are still cancelled as soon as the context is done.
+func NewHTTPClientWithTimeout(proxy string, timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
 	}
-	if proxy != "" {
-		parsed, err := url.Parse(proxy)
-		if err == nil {
-			// Preserve http.DefaultTransport settings (TLS, HTTP/2, timeouts, etc.)
-			if base, ok := http.DefaultTransport.(*http.Transport); ok {
-				tr := base.Clone()
-				tr.Proxy = http.ProxyURL(parsed)
-				client.Transport = tr
-			} else {
-				// Fallback: minimal transport if DefaultTransport is not *http.Transport.
-				client.Transport = &http.Transport{
-					Proxy: http.ProxyURL(parsed),
-				}
-			}
-		} else {
-			log.Printf("common: invalid proxy URL %q: %v", proxy, err)
-		}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: SharedTransport(proxy),
 	}
-	return client
 }
 
 // --- Message serialization ---
@@ -163,6 +157,48 @@ func parseDataAudioURL(mediaURL string) (format, data string, ok bool) {
 	return format, data, true
 }
 
+// ImageSource is an image attachment for APIs that take inline base64 data
+// and image URLs as separate source kinds, such as Anthropic's. Either Data
+// (with MediaType) or URL is set.
+type ImageSource struct {
+	MediaType string
+	Data      string
+	URL       string
+}
+
+// ImageSources picks the images out of media: base64 "data:image/" URLs and
+// http(s) URLs. Other media is skipped.
+func ImageSources(media []string) []ImageSource {
+	var sources []ImageSource
+	for _, mediaURL := range media {
+		if mediaType, data, ok := parseDataImageURL(mediaURL); ok {
+			sources = append(sources, ImageSource{MediaType: mediaType, Data: data})
+			continue
+		}
+		if strings.HasPrefix(mediaURL, "https://") || strings.HasPrefix(mediaURL, "http://") {
+			sources = append(sources, ImageSource{URL: mediaURL})
+		}
+	}
+	return sources
+}
+
+// parseDataImageURL splits a "data:image/<type>;base64,<data>" URL into its
+// media type and base64 payload.
+func parseDataImageURL(mediaURL string) (mediaType, data string, ok bool) {
+	if !strings.HasPrefix(mediaURL, "data:image/") {
+		return "", "", false
+	}
+	meta, data, found := strings.Cut(strings.TrimPrefix(mediaURL, "data:"), ",")
+	if !found || data == "" {
+		return "", "", false
+	}
+	mediaType, encoding, _ := strings.Cut(meta, ";")
+	if encoding != "base64" {
+		return "", "", false
+	}
+	return mediaType, data, true
+}
+
 // --- Response parsing ---
 
 // ParseResponse parses a JSON chat completion response body into an LLMResponse.
@@ -251,9 +287,11 @@ func ParseResponse(body io.Reader) (*LLMResponse, error) {
 }
 
 // normalizeFinishReason normalizes finish_reason values across providers.
-// Converts "length" to "truncated" for consistent handling.
+// Converts "length" (and Mistral's "model_length") to "truncated" for
+// consistent handling.
 func normalizeFinishReason(reason string) string {
-	if reason == "length" {
+	switch reason {
+	case "length", "model_length":
 		return "truncated"
 	}
 	return reason
@@ -295,21 +333,24 @@ func DecodeToolCallArguments(raw json.RawMessage, name string) map[string]any {
 
 // --- HTTP response helpers ---
 
-// HandleErrorResponse reads a non-200 response body and returns an appropriate error.
-func HandleErrorResponse(resp *http.Response, apiBase string) error {
+// HandleErrorResponse reads a non-200 response body and returns a
+// *ProviderError for it. provider names the API protocol, e.g. "openai".
+func HandleErrorResponse(resp *http.Response, provider, apiBase string) error {
 	contentType := resp.Header.Get("Content-Type")
-	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 256))
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, 4096))
 	if readErr != nil {
-		return fmt.Errorf("failed to read response: %w", readErr)
+		return protocoltypes.NewProviderError(provider, resp.StatusCode, nil,
+			fmt.Errorf("failed to read response: %w", readErr))
 	}
 	if LooksLikeHTML(body, contentType) {
-		return WrapHTMLResponseError(resp.StatusCode, body, contentType, apiBase)
+		return protocoltypes.NewProviderError(provider, resp.StatusCode, nil,
+			WrapHTMLResponseError(resp.StatusCode, body, contentType, apiBase))
 	}
-	return fmt.Errorf(
+	return protocoltypes.NewProviderError(provider, resp.StatusCode, body, fmt.Errorf(
 		"API request failed:\n  Status: %d\n  Body:   %s",
 		resp.StatusCode,
 		ResponsePreview(body, 128),
-	)
+	))
 }
 
 // ReadAndParseResponse peeks at the response body to detect HTML errors,
@@ -385,6 +426,14 @@ func leadingTrimmedPrefix(body []byte, maxLen int) []byte {
 	return nil
 }
 
+// PromptCachingEnabled reports whether a request may mark prompt cache
+// breakpoints. Caching is on unless the "prompt_caching" option is false,
+// which the agent sets from the model's prompt_caching config.
+func PromptCachingEnabled(options map[string]any) bool {
+	enabled, ok := options["prompt_caching"].(bool)
+	return !ok || enabled
+}
+
 // --- Numeric helpers ---
 
 // AsInt converts various numeric types to int.
@@ -1,12 +1,15 @@
 package common
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
@@ -38,8 +41,52 @@ func TestNewHTTPClient_WithProxy(t *testing.T) {
 
 func TestNewHTTPClient_NoProxy(t *testing.T) {
 	client := NewHTTPClient("")
-	if client.Transport != nil {
-		t.Errorf("expected nil transport without proxy, got %T", client.Transport)
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok || transport != SharedTransport("") {
+		t.Fatalf("expected the shared transport without proxy, got %T", client.Transport)
+	}
+	if transport.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost ||
+		transport.TLSHandshakeTimeout != DefaultTLSHandshakeTimeout {
+		t.Errorf("shared transport not tuned: %+v", transport)
+	}
+}
+
+func TestNewHTTPClient_SharesTransportPerProxy(t *testing.T) {
+	a := NewHTTPClient("http://127.0.0.1:8080")
+	b := NewHTTPClientWithTimeout("http://127.0.0.1:8080", 5*time.Second)
+	if a.Transport != b.Transport {
+		t.Error("clients with the same proxy should share one transport")
+	}
+	if a.Transport == NewHTTPClient("").Transport {
+		t.Error("proxied and direct clients must not share a transport")
+	}
+	if b.Timeout != 5*time.Second {
+		t.Errorf("timeout = %v, want 5s", b.Timeout)
+	}
+}
+
+func TestNewHTTPClient_RespectsContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequestWithContext() error = %v", err)
+	}
+
+	start := time.Now()
+	_, err = NewHTTPClient("").Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do() error = %v, want context deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("request took %v after the context expired", elapsed)
 	}
 }
 
@@ -192,6 +239,17 @@ func TestParseResponse_EmptyChoices(t *testing.T) {
 	}
 }
 
+func TestParseResponse_MistralModelLengthIsTruncated(t *testing.T) {
+	body := `{"choices":[{"message":{"content":"partial"},"finish_reason":"model_length"}]}`
+	out, err := ParseResponse(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("ParseResponse() error = %v", err)
+	}
+	if out.FinishReason != "truncated" {
+		t.Errorf("FinishReason = %q, want %q", out.FinishReason, "truncated")
+	}
+}
+
 func TestParseResponse_WithToolCalls(t *testing.T) {
 	body := `{"choices":[{"message":{"content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"SF\"}"}}]},"finish_reason":"tool_calls"}]}`
 	out, err := ParseResponse(strings.NewReader(body))
@@ -337,7 +395,7 @@ func TestHandleErrorResponse_JSONError(t *testing.T) {
 		t.Fatalf("http.Get() error = %v", err)
 	}
 	defer resp.Body.Close()
-	err = HandleErrorResponse(resp, server.URL)
+	err = HandleErrorResponse(resp, "openai", server.URL)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -349,6 +407,34 @@ func TestHandleErrorResponse_JSONError(t *testing.T) {
 	}
 }
 
+func TestHandleErrorResponse_ReturnsProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"Rate limit reached for gpt-4o in organization org-123 on tokens per min","code":"rate_limit_exceeded"}}`))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("http.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	err = HandleErrorResponse(resp, "openai", server.URL)
+
+	pe, ok := protocoltypes.AsProviderError(err)
+	if !ok {
+		t.Fatalf("error %T is not a ProviderError", err)
+	}
+	if pe.Provider != "openai" || pe.Status != 429 || pe.Code != "rate_limit_exceeded" || !pe.Retryable() {
+		t.Fatalf("ProviderError = %+v", pe)
+	}
+	// The message is complete even though the error text only previews the body.
+	if !strings.HasSuffix(pe.Message, "tokens per min") {
+		t.Fatalf("Message = %q", pe.Message)
+	}
+}
+
 func TestHandleErrorResponse_HTMLError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
@@ -362,7 +448,7 @@ func TestHandleErrorResponse_HTMLError(t *testing.T) {
 		t.Fatalf("http.Get() error = %v", err)
 	}
 	defer resp.Body.Close()
-	err = HandleErrorResponse(resp, server.URL)
+	err = HandleErrorResponse(resp, "openai", server.URL)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -575,7 +661,7 @@ func TestHandleErrorResponse_EmptyBody(t *testing.T) {
 		t.Fatalf("http.Get() error = %v", err)
 	}
 	defer resp.Body.Close()
-	err = HandleErrorResponse(resp, server.URL)
+	err = HandleErrorResponse(resp, "openai", server.URL)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -626,3 +712,25 @@ func TestParseResponse_WithThoughtSignature(t *testing.T) {
 			out.ToolCalls[0].ExtraContent.Google.ThoughtSignature, "sig123")
 	}
 }
+
+func TestImageSources(t *testing.T) {
+	got := ImageSources([]string{
+		"data:image/png;base64,iVBORw0KGgo=",
+		"https://example.com/cat.jpg",
+		"data:audio/wav;base64,UklGRg==",
+		"data:image/png,notbase64",
+		"media://abc",
+	})
+	want := []ImageSource{
+		{MediaType: "image/png", Data: "iVBORw0KGgo="},
+		{URL: "https://example.com/cat.jpg"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ImageSources() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ImageSources()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package common
+
+// anthropicModels is the curated list returned by Anthropic providers, which
+// do not query a models endpoint. Keep it to current, generally available IDs.
+var anthropicModels = []string{
+	"claude-opus-4-5",
+	"claude-sonnet-4-6",
+	"claude-sonnet-4-5",
+	"claude-haiku-4-5",
+	"claude-opus-4-1",
+	"claude-sonnet-4-20250514",
+}
+
+// AnthropicModels returns the curated Anthropic model list.
+func AnthropicModels() []ModelInfo {
+	models := make([]ModelInfo, 0, len(anthropicModels))
+	for _, id := range anthropicModels {
+		models = append(models, ModelInfo{ID: id, OwnedBy: "anthropic"})
+	}
+	return models
+}
@@ -0,0 +1,26 @@
+package common
+
+import "testing"
+
+func TestThinkingLevelBudget(t *testing.T) {
+	tests := []struct {
+		name  string
+		level string
+		want  int
+	}{
+		{"low", "low", 4096},
+		{"medium", "medium", 16384},
+		{"high", "high", 32000},
+		{"xhigh", "xhigh", 64000},
+		{"off", "off", 0},
+		{"empty", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ThinkingLevelBudget(tt.level); got != tt.want {
+				t.Errorf("ThinkingLevelBudget(%q) = %d, want %d", tt.level, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"crypto/tls"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Connection settings of the shared provider transport. The overall request
+// timeout is set per client (see NewHTTPClientWithTimeout); these bound the
+// individual phases so a dead endpoint fails fast instead of hanging.
+const (
+	DefaultDialTimeout         = 10 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 16
+)
+
+var (
+	sharedTransportsMu sync.Mutex
+	sharedTransports   = map[string]*http.Transport{}
+)
+
+// SharedTransport returns the pooled transport used by all providers for the
+// given proxy URL ("" means direct, honoring the proxy environment
+// variables). Providers that share a proxy share keep-alive connections.
+// An invalid proxy URL is logged and ignored.
+func SharedTransport(proxy string) *http.Transport {
+	var proxyURL *url.URL
+	if proxy != "" {
+		parsed, err := url.Parse(proxy)
+		if err != nil {
+			log.Printf("common: invalid proxy URL %q: %v", proxy, err)
+			proxy = ""
+		} else {
+			proxyURL = parsed
+		}
+	}
+
+	sharedTransportsMu.Lock()
+	defer sharedTransportsMu.Unlock()
+	if tr, ok := sharedTransports[proxy]; ok {
+		return tr
+	}
+	tr := newProviderTransport(proxyURL)
+	sharedTransports[proxy] = tr
+	return tr
+}
+
+func newProviderTransport(proxyURL *url.URL) *http.Transport {
+	var tr *http.Transport
+	// Preserve http.DefaultTransport settings (proxy from environment, HTTP/2)
+	// and tighten the timeouts that matter for provider calls.
+	if base, ok := http.DefaultTransport.(*http.Transport); ok {
+		tr = base.Clone()
+	} else {
+		tr = &http.Transport{Proxy: http.ProxyFromEnvironment}
+	}
+	if proxyURL != nil {
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+	tr.DialContext = (&net.Dialer{
+		Timeout:   DefaultDialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	tr.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	tr.IdleConnTimeout = DefaultIdleConnTimeout
+	tr.MaxIdleConns = DefaultMaxIdleConns
+	tr.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	// A custom dialer or TLS config disables HTTP/2 unless forced.
+	tr.ForceAttemptHTTP2 = true
+	return tr
+}
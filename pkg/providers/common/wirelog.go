@@ -0,0 +1,198 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// DefaultWireLogMaxBytes caps each logged request or response body.
+const DefaultWireLogMaxBytes = 8192
+
+const wireLogRedacted = "[REDACTED]"
+
+// wireLogSecretHeaders are replaced before a request is logged.
+var wireLogSecretHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Api-Key",
+	"X-Api-Key",
+	"X-Goog-Api-Key",
+	"Cookie",
+}
+
+// wireLogSecretQuery are query parameters that carry credentials.
+var wireLogSecretQuery = []string{"key", "api_key", "api-key", "access_token"}
+
+var (
+	reWireBearer    = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+	reWireKeyField  = regexp.MustCompile(`(?i)("(?:api_?key|access_token|secret|password|token)"\s*:\s*")[^"]*(")`)
+	reWireSecretKey = regexp.MustCompile(`\b(sk-[A-Za-z0-9_-]{4})[A-Za-z0-9_-]{8,}`)
+	reWireFormField = regexp.MustCompile(
+		`(?i)\b((?:client_secret|password|refresh_token|access_token|api_?key)=)[^&\s"]*`)
+)
+
+// EnableWireLog wraps the client's transport so every request and response
+// body is logged at info level, truncated to maxBytes and with credentials
+// redacted. label identifies the provider in the log, usually its API base.
+func EnableWireLog(client *http.Client, label string, maxBytes int) {
+	if client == nil {
+		return
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultWireLogMaxBytes
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &wireLogTransport{base: base, label: label, maxBytes: maxBytes}
+}
+
+type wireLogTransport struct {
+	base     http.RoundTripper
+	label    string
+	maxBytes int
+}
+
+func (t *wireLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	logger.InfoCtx(req.Context(), "provider.wire", "Provider request", map[string]any{
+		"provider": t.label,
+		"method":   req.Method,
+		"url":      redactWireURL(req),
+		"headers":  redactWireHeaders(req.Header),
+		"body":     t.truncate(reqBody),
+	})
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		logger.InfoCtx(req.Context(), "provider.wire", "Provider request failed", map[string]any{
+			"provider":    t.label,
+			"error":       err.Error(),
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+		return nil, err
+	}
+
+	resp.Body = &wireLogBody{
+		ReadCloser: resp.Body,
+		ctx:        req.Context(),
+		transport:  t,
+		status:     resp.StatusCode,
+		start:      start,
+	}
+	return resp, nil
+}
+
+func (t *wireLogTransport) truncate(body []byte) string {
+	s := redactWireText(string(body))
+	if len(s) > t.maxBytes {
+		return s[:t.maxBytes] + "...[truncated]"
+	}
+	return s
+}
+
+// wireLogBody captures the response body as the provider reads it, so
+// streamed responses are logged without being buffered up front. The log is
+// written once, when the body is closed.
+type wireLogBody struct {
+	io.ReadCloser
+	ctx       context.Context
+	transport *wireLogTransport
+	status    int
+	start     time.Time
+
+	mu       sync.Mutex
+	captured bytes.Buffer
+	total    int64
+	once     sync.Once
+}
+
+func (b *wireLogBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.mu.Lock()
+		b.total += int64(n)
+		// Keep a little extra so redaction never splits a secret at the cap.
+		if room := b.transport.maxBytes + 256 - b.captured.Len(); room > 0 {
+			b.captured.Write(p[:min(n, room)])
+		}
+		b.mu.Unlock()
+	}
+	return n, err
+}
+
+func (b *wireLogBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		logger.InfoCtx(b.ctx, "provider.wire", "Provider response", map[string]any{
+			"provider":    b.transport.label,
+			"status":      b.status,
+			"bytes":       b.total,
+			"duration_ms": time.Since(b.start).Milliseconds(),
+			"body":        b.transport.truncate(b.captured.Bytes()),
+		})
+	})
+	return err
+}
+
+func redactWireHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		out[name] = strings.Join(values, ", ")
+	}
+	for _, name := range wireLogSecretHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(name)]; ok {
+			out[http.CanonicalHeaderKey(name)] = wireLogRedacted
+		}
+	}
+	return out
+}
+
+func redactWireURL(req *http.Request) string {
+	if req.URL == nil {
+		return ""
+	}
+	u := *req.URL
+	u.User = nil
+	q := u.Query()
+	changed := false
+	for _, name := range wireLogSecretQuery {
+		if q.Has(name) {
+			q.Set(name, wireLogRedacted)
+			changed = true
+		}
+	}
+	if changed {
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+func redactWireText(s string) string {
+	s = reWireBearer.ReplaceAllString(s, "${1}"+wireLogRedacted)
+	s = reWireKeyField.ReplaceAllString(s, "${1}"+wireLogRedacted+"${2}")
+	s = reWireFormField.ReplaceAllString(s, "${1}"+wireLogRedacted)
+	return reWireSecretKey.ReplaceAllString(s, "${1}"+wireLogRedacted)
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+func TestRedactWireText(t *testing.T) {
+	in := `{"api_key":"abc123","messages":[{"content":"Authorization: Bearer tok.en-1"}],"note":"sk-proj1234567890abcdef"}`
+	got := redactWireText(in)
+
+	for _, secret := range []string{"abc123", "tok.en-1", "1234567890abcdef"} {
+		if strings.Contains(got, secret) {
+			t.Fatalf("redactWireText left %q in %s", secret, got)
+		}
+	}
+	if !strings.Contains(got, `"api_key":"[REDACTED]"`) || !strings.Contains(got, "Bearer [REDACTED]") {
+		t.Fatalf("unexpected redaction: %s", got)
+	}
+}
+
+func TestRedactWireText_FormFields(t *testing.T) {
+	got := redactWireText("grant_type=client_credentials&client_id=pico&client_secret=s3cr3t&scope=llm")
+	if strings.Contains(got, "s3cr3t") {
+		t.Fatalf("redactWireText left the client secret in %s", got)
+	}
+	if !strings.Contains(got, "client_secret=[REDACTED]&scope=llm") || !strings.Contains(got, "client_id=pico") {
+		t.Fatalf("unexpected redaction: %s", got)
+	}
+}
+
+func TestClientCredentials_SecretNeverInWireLog(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Refuse HTTP Basic auth so the client falls back to sending the
+		// secret in the form body.
+		if err := r.ParseForm(); err != nil || r.Form.Get("client_secret") != "s3cr3t" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"gateway-token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer apiServer.Close()
+
+	logPath := filepath.Join(t.TempDir(), "wire.log")
+	if err := logger.EnableFileLogging(logPath); err != nil {
+		t.Fatalf("EnableFileLogging() error = %v", err)
+	}
+	defer logger.DisableFileLogging()
+
+	client := NewHTTPClient("")
+	EnableWireLog(client, apiServer.URL, 0)
+	EnableClientCredentials(client, ClientCredentials{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "pico",
+		ClientSecret: "s3cr3t",
+	})
+
+	resp, err := client.Post(apiServer.URL, "application/json", strings.NewReader(`{"model":"m"}`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	_, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	logged, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(logged), "Provider request") {
+		t.Fatalf("expected the API request in the wire log, got %s", logged)
+	}
+	if strings.Contains(string(logged), "s3cr3t") {
+		t.Fatalf("client secret leaked into the wire log: %s", logged)
+	}
+}
+
+func TestRedactWireRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/v1/models?key=secret&alt=sse", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Goog-Api-Key", "secret")
+	req.Header.Set("Content-Type", "application/json")
+
+	headers := redactWireHeaders(req.Header)
+	if headers["Authorization"] != wireLogRedacted || headers["X-Goog-Api-Key"] != wireLogRedacted {
+		t.Fatalf("credential headers not redacted: %v", headers)
+	}
+	if headers["Content-Type"] != "application/json" {
+		t.Fatalf("Content-Type = %q", headers["Content-Type"])
+	}
+	if u := redactWireURL(req); strings.Contains(u, "secret") || !strings.Contains(u, "alt=sse") {
+		t.Fatalf("redactWireURL = %q", u)
+	}
+}
+
+func TestEnableWireLog_PassesBodiesThrough(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(append([]byte("echo:"), body...))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("")
+	EnableWireLog(client, server.URL, 4)
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"model":"m"}`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(body) != `echo:{"model":"m"}` {
+		t.Fatalf("body = %q, wire logging must not alter payloads", body)
+	}
+
+	tr, ok := client.Transport.(*wireLogTransport)
+	if !ok {
+		t.Fatalf("transport = %T, want *wireLogTransport", client.Transport)
+	}
+	if got := tr.truncate([]byte("0123456789")); got != "0123...[truncated]" {
+		t.Fatalf("truncate() = %q", got)
+	}
+}
@@ -2,12 +2,23 @@ package providers
 
 import (
 	"math"
+	"sort"
 	"sync"
 	"time"
 )
 
 const (
 	defaultFailureWindow = 24 * time.Hour
+	// defaultProbeTimeout bounds how long a half-open probe may stay
+	// unresolved before another request is allowed to probe.
+	defaultProbeTimeout = 5 * time.Minute
+)
+
+// Circuit breaker states reported by CooldownTracker.Status.
+const (
+	BreakerClosed   = "closed"    // provider is used normally
+	BreakerOpen     = "open"      // provider is skipped until its cooldown ends
+	BreakerHalfOpen = "half_open" // cooldown ended; one request probes recovery
 )
 
 // CooldownTracker manages per-provider cooldown state for the fallback chain.
@@ -16,6 +27,7 @@ type CooldownTracker struct {
 	mu            sync.RWMutex
 	entries       map[string]*cooldownEntry
 	failureWindow time.Duration
+	probeTimeout  time.Duration
 	nowFunc       func() time.Time // for testing
 }
 
@@ -26,6 +38,16 @@ type cooldownEntry struct {
 	DisabledUntil  time.Time      // billing-specific disable expiry
 	DisabledReason FailoverReason // reason for disable (billing)
 	LastFailure    time.Time
+	ProbeStarted   time.Time // set while a half-open probe is in flight
+}
+
+// ProviderHealth is a snapshot of one provider's breaker state.
+type ProviderHealth struct {
+	Provider          string
+	State             string
+	ErrorCount        int
+	CooldownRemaining time.Duration
+	LastFailure       time.Time
 }
 
 // NewCooldownTracker creates a tracker with default 24h failure window.
@@ -33,6 +55,7 @@ func NewCooldownTracker() *CooldownTracker {
 	return &CooldownTracker{
 		entries:       make(map[string]*cooldownEntry),
 		failureWindow: defaultFailureWindow,
+		probeTimeout:  defaultProbeTimeout,
 		nowFunc:       time.Now,
 	}
 }
@@ -55,6 +78,7 @@ func (ct *CooldownTracker) MarkFailure(provider string, reason FailoverReason) {
 	entry.ErrorCount++
 	entry.FailureCounts[reason]++
 	entry.LastFailure = now
+	entry.ProbeStarted = time.Time{}
 
 	if reason == FailoverBilling {
 		billingCount := entry.FailureCounts[FailoverBilling]
@@ -80,6 +104,77 @@ func (ct *CooldownTracker) MarkSuccess(provider string) {
 	entry.CooldownEnd = time.Time{}
 	entry.DisabledUntil = time.Time{}
 	entry.DisabledReason = ""
+	entry.ProbeStarted = time.Time{}
+}
+
+// Allow reports whether a request may be sent to the provider, acting as a
+// circuit breaker: it is false while the provider is in cooldown (open).
+// Once the cooldown ends the breaker is half-open and only the first caller
+// is allowed through to probe recovery; others keep skipping the provider
+// until MarkSuccess or MarkFailure resolves the probe. A caller that is
+// allowed but does not send the request must call ReleaseProbe.
+func (ct *CooldownTracker) Allow(provider string) bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	entry := ct.entries[provider]
+	if entry == nil || entry.ErrorCount == 0 {
+		return true
+	}
+
+	now := ct.nowFunc()
+	if ct.inCooldown(entry, now) {
+		return false
+	}
+	if !entry.ProbeStarted.IsZero() && now.Sub(entry.ProbeStarted) < ct.probeTimeout {
+		return false
+	}
+	entry.ProbeStarted = now
+	return true
+}
+
+// ReleaseProbe gives up a half-open probe acquired via Allow without
+// recording an outcome, so the next request can probe instead.
+func (ct *CooldownTracker) ReleaseProbe(provider string) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if entry := ct.entries[provider]; entry != nil {
+		entry.ProbeStarted = time.Time{}
+	}
+}
+
+// Status returns the breaker state of every provider that has failed since
+// its last success, sorted by provider key.
+func (ct *CooldownTracker) Status() []ProviderHealth {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	now := ct.nowFunc()
+	out := make([]ProviderHealth, 0, len(ct.entries))
+	for provider, entry := range ct.entries {
+		if entry.ErrorCount == 0 {
+			continue
+		}
+		health := ProviderHealth{
+			Provider:    provider,
+			State:       BreakerHalfOpen,
+			ErrorCount:  entry.ErrorCount,
+			LastFailure: entry.LastFailure,
+		}
+		if ct.inCooldown(entry, now) {
+			health.State = BreakerOpen
+			health.CooldownRemaining = max(entry.CooldownEnd.Sub(now), entry.DisabledUntil.Sub(now))
+		}
+		out = append(out, health)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+func (ct *CooldownTracker) inCooldown(entry *cooldownEntry, now time.Time) bool {
+	return (!entry.DisabledUntil.IsZero() && now.Before(entry.DisabledUntil)) ||
+		(!entry.CooldownEnd.IsZero() && now.Before(entry.CooldownEnd))
 }
 
 // IsAvailable returns true if the provider is not in cooldown or disabled.
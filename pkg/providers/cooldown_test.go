@@ -267,3 +267,70 @@ func TestCooldown_MultipleProviders(t *testing.T) {
 		t.Error("groq should be available")
 	}
 }
+
+func TestCooldown_HalfOpenAllowsSingleProbe(t *testing.T) {
+	now := time.Now()
+	ct, current := newTestTracker(now)
+
+	if !ct.Allow("openai") {
+		t.Fatal("healthy provider should be allowed")
+	}
+	ct.MarkFailure("openai", FailoverTimeout)
+	if ct.Allow("openai") {
+		t.Fatal("provider in cooldown should not be allowed")
+	}
+	if got := ct.Status(); len(got) != 1 || got[0].State != BreakerOpen {
+		t.Fatalf("Status() = %+v, want one open breaker", got)
+	}
+
+	*current = now.Add(61 * time.Second)
+	if !ct.Allow("openai") {
+		t.Fatal("first request after cooldown should probe")
+	}
+	if ct.Allow("openai") {
+		t.Fatal("only one request should probe at a time")
+	}
+	if got := ct.Status(); got[0].State != BreakerHalfOpen {
+		t.Fatalf("State = %q, want %q", got[0].State, BreakerHalfOpen)
+	}
+
+	ct.ReleaseProbe("openai")
+	if !ct.Allow("openai") {
+		t.Fatal("released probe should let the next request probe")
+	}
+	ct.MarkSuccess("openai")
+	if !ct.Allow("openai") || !ct.Allow("openai") {
+		t.Fatal("successful probe should close the breaker")
+	}
+	if got := ct.Status(); len(got) != 0 {
+		t.Fatalf("Status() = %+v, want no failing providers", got)
+	}
+}
+
+func TestCooldown_FailedProbeReopens(t *testing.T) {
+	now := time.Now()
+	ct, current := newTestTracker(now)
+
+	ct.MarkFailure("openai", FailoverTimeout)
+	*current = now.Add(61 * time.Second)
+	if !ct.Allow("openai") {
+		t.Fatal("first request after cooldown should probe")
+	}
+	ct.MarkFailure("openai", FailoverTimeout)
+	if ct.Allow("openai") {
+		t.Fatal("failed probe should reopen the breaker")
+	}
+	if got := ct.CooldownRemaining("openai"); got != 5*time.Minute {
+		t.Fatalf("CooldownRemaining() = %v, want 5m", got)
+	}
+
+	// An abandoned probe eventually expires.
+	*current = current.Add(5*time.Minute + time.Second)
+	if !ct.Allow("openai") {
+		t.Fatal("expected probe after second cooldown")
+	}
+	*current = current.Add(defaultProbeTimeout)
+	if !ct.Allow("openai") {
+		t.Fatal("stale probe should not block new probes")
+	}
+}
@@ -161,6 +161,12 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 	}
 
 	msg := strings.ToLower(err.Error())
+	// A structured provider error has the real status and the API's full
+	// error text, which the error string may only show a preview of.
+	pe, structured := AsProviderError(err)
+	if structured && (pe.Code != "" || pe.Message != "") {
+		msg += " " + strings.ToLower(pe.Code+" "+pe.Message)
+	}
 
 	// Concrete transport errors should continue the fallback chain even when
 	// providers do not expose a structured HTTP status.
@@ -184,7 +190,11 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 	}
 
 	// Try HTTP status code extraction first.
-	if status := extractHTTPStatus(msg); status > 0 {
+	status := extractHTTPStatus(msg)
+	if structured && pe.Status > 0 {
+		status = pe.Status
+	}
+	if status > 0 {
 		if reason := classifyByStatus(status); reason != "" {
 			return &FailoverError{
 				Reason:   reason,
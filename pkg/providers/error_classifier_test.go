@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"syscall"
 	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 type stubNetError struct {
@@ -498,3 +500,42 @@ func TestIsImageSizeError(t *testing.T) {
 		t.Error("should not match normal error")
 	}
 }
+
+func TestClassifyError_ProviderErrorStatusWins(t *testing.T) {
+	// The body mentions 500, but the response status was 401.
+	err := newTestProviderError(401, `{"error":{"message":"key revoked after 500 failed attempts"}}`)
+	result := ClassifyError(err, "openai", "gpt-4")
+	if result == nil || result.Reason != FailoverAuth || result.Status != 401 {
+		t.Fatalf("ClassifyError() = %+v, want auth with status 401", result)
+	}
+}
+
+func TestClassifyError_ProviderErrorFullMessage(t *testing.T) {
+	// The context overflow phrase sits past the 128-byte preview in the
+	// error text, so only the structured message reveals it.
+	pe := &ProviderError{
+		Provider: "openai",
+		Message:  "This model's maximum context length is 8192 tokens",
+		Err:      errors.New("API request failed"),
+	}
+	result := ClassifyError(fmt.Errorf("wrapped: %w", pe), "openai", "gpt-4")
+	if result == nil || result.Reason != FailoverContextOverflow {
+		t.Fatalf("ClassifyError() = %+v, want context_overflow", result)
+	}
+}
+
+func TestFallbackExhaustedError_UnwrapsProviderError(t *testing.T) {
+	err := &FallbackExhaustedError{Attempts: []FallbackAttempt{
+		{Provider: "a", Model: "m", Skipped: true},
+		{Provider: "b", Model: "m", Error: newTestProviderError(429, "")},
+	}}
+	pe, ok := AsProviderError(err)
+	if !ok || pe.Status != 429 {
+		t.Fatalf("AsProviderError() = %+v, %v", pe, ok)
+	}
+}
+
+func newTestProviderError(status int, body string) error {
+	return protocoltypes.NewProviderError("openai", status, []byte(body),
+		fmt.Errorf("API request failed: %s", body))
+}
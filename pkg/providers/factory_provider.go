@@ -12,9 +12,11 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	anthropicmessages "github.com/sipeed/picoclaw/pkg/providers/anthropic_messages"
 	"github.com/sipeed/picoclaw/pkg/providers/azure"
 	"github.com/sipeed/picoclaw/pkg/providers/bedrock"
+	"github.com/sipeed/picoclaw/pkg/providers/common"
 )
 
 type protocolMeta struct {
@@ -58,6 +60,8 @@ var protocolMetaByName = map[string]protocolMeta{
 	"longcat":                  {defaultAPIBase: "https://api.longcat.chat/openai"},
 	"modelscope":               {defaultAPIBase: "https://api-inference.modelscope.cn/v1"},
 	"mimo":                     {defaultAPIBase: "https://api.xiaomimimo.com/v1"},
+	// custom has no default endpoint; api_base is always required.
+	"custom": {emptyAPIKeyAllowed: true},
 }
 
 // createClaudeAuthProvider creates a Claude provider using OAuth credentials from auth store.
@@ -105,6 +109,7 @@ func ResolveAPIBase(cfg *config.ModelConfig) string {
 	if cfg == nil {
 		return ""
 	}
+	cfg = cfg.WithEnvExpanded()
 	if apiBase := strings.TrimSpace(cfg.APIBase); apiBase != "" {
 		return strings.TrimRight(apiBase, "/")
 	}
@@ -112,6 +117,22 @@ func ResolveAPIBase(cfg *config.ModelConfig) string {
 	return strings.TrimRight(getDefaultAPIBase(protocol), "/")
 }
 
+// wireLogProvider is implemented by providers built on the shared HTTP client
+// that can log their wire payloads.
+type wireLogProvider interface {
+	EnableWireLog(maxBytes int)
+}
+
+// clientCredentialsProvider is implemented by HTTP providers that can
+// authenticate with an OAuth client-credentials grant.
+type clientCredentialsProvider interface {
+	EnableClientCredentials(creds common.ClientCredentials)
+}
+
+// clientCredentialsPlaceholderKey satisfies the api_key checks of the
+// HTTP protocols; the grant's token replaces it on every request.
+const clientCredentialsPlaceholderKey = "oauth-client-credentials"
+
 // CreateProviderFromConfig creates a provider based on the ModelConfig.
 // It uses the protocol prefix in the Model field to determine which provider to create.
 // Supported protocol families include OpenAI-compatible prefixes (e.g., openai, openrouter, groq),
@@ -119,6 +140,52 @@ func ResolveAPIBase(cfg *config.ModelConfig) string {
 // See the switch on protocol in this function for the authoritative list.
 // Returns the provider, the model ID (without protocol prefix), and any error.
 func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, error) {
+	if cfg != nil && cfg.KeyPool && len(cfg.APIKeys) > 1 {
+		return createKeyPoolProvider(cfg)
+	}
+	build := cfg
+	if cfg != nil && cfg.UsesClientCredentials() {
+		// Build the provider as for a keyed model, so auth_method "oauth"
+		// does not select the auth store login of openai or anthropic.
+		keyed := *cfg
+		keyed.AuthMethod = ""
+		if keyed.APIKey() == "" {
+			keyed.APIKeys = config.SimpleSecureStrings(clientCredentialsPlaceholderKey)
+		}
+		build = &keyed
+	}
+
+	provider, modelID, err := createProviderFromConfig(build)
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg.WireLog {
+		if wl, ok := provider.(wireLogProvider); ok {
+			wl.EnableWireLog(cfg.WireLogMaxBytes)
+		} else {
+			logger.WarnCF("provider", "wire_log is not supported by this provider", map[string]any{
+				"model": cfg.Model,
+			})
+		}
+	}
+	if build != cfg {
+		// Applied after wire logging so the log shows the token actually
+		// sent (redacted) rather than the placeholder key.
+		cc, ok := provider.(clientCredentialsProvider)
+		if !ok {
+			return nil, "", fmt.Errorf("auth_method oauth with a token_url is not supported for model %s", cfg.Model)
+		}
+		cc.EnableClientCredentials(common.ClientCredentials{
+			TokenURL:     cfg.OAuth.TokenURL,
+			ClientID:     cfg.OAuth.ClientID,
+			ClientSecret: cfg.OAuth.ClientSecret.String(),
+			Scopes:       cfg.OAuth.Scopes,
+		})
+	}
+	return provider, modelID, nil
+}
+
+func createProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, error) {
 	if cfg == nil {
 		return nil, "", fmt.Errorf("config is nil")
 	}
@@ -126,6 +193,7 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 	if cfg.Model == "" {
 		return nil, "", fmt.Errorf("model is required")
 	}
+	cfg = cfg.WithEnvExpanded()
 
 	protocol, modelID := ExtractProtocol(cfg.Model)
 
@@ -164,8 +232,9 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		), modelID, nil
 
 	case "azure", "azure-openai":
-		// Azure OpenAI uses deployment-based URLs, api-key header auth,
-		// and always sends max_completion_tokens.
+		// Azure OpenAI uses the v1 Responses API by default. Setting api_version
+		// selects the deployment-based Chat Completions endpoint with api-key
+		// header auth; the model ID is then used as the deployment name.
 		if cfg.APIKey() == "" {
 			return nil, "", fmt.Errorf("api_key is required for azure protocol")
 		}
@@ -174,12 +243,14 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 				"api_base is required for azure protocol (e.g., https://your-resource.openai.azure.com)",
 			)
 		}
-		return azure.NewProviderWithTimeout(
+		return azure.NewProvider(
 			cfg.APIKey(),
 			cfg.APIBase,
 			cfg.Proxy,
 			userAgent,
-			cfg.RequestTimeout,
+			azure.WithRequestTimeout(time.Duration(cfg.RequestTimeout)*time.Second),
+			azure.WithAPIVersion(cfg.APIVersion),
+			azure.WithCustomHeaders(cfg.CustomHeaders),
 		), modelID, nil
 
 	case "bedrock":
@@ -242,6 +313,24 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 			cfg.CustomHeaders,
 		), modelID, nil
 
+	case "custom":
+		// Any OpenAI-compatible endpoint without a dedicated protocol. Everything
+		// after "custom/" is sent as the model ID, so "custom/org/model" requests
+		// "org/model". The API key is optional.
+		if cfg.APIBase == "" {
+			return nil, "", fmt.Errorf("api_base is required for custom protocol (model: %s)", cfg.Model)
+		}
+		return NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
+			cfg.APIKey(),
+			cfg.APIBase,
+			cfg.Proxy,
+			cfg.MaxTokensField,
+			userAgent,
+			cfg.RequestTimeout,
+			cfg.ExtraBody,
+			cfg.CustomHeaders,
+		), modelID, nil
+
 	case "gemini":
 		if cfg.APIKey() == "" && cfg.APIBase == "" {
 			return nil, "", fmt.Errorf("api_key or api_base is required for gemini protocol (model: %s)", cfg.Model)
@@ -324,12 +413,15 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if cfg.APIKey() == "" {
 			return nil, "", fmt.Errorf("api_key is required for anthropic-messages protocol (model: %s)", cfg.Model)
 		}
-		return anthropicmessages.NewProviderWithTimeout(
+		provider := anthropicmessages.NewProviderWithTimeout(
 			cfg.APIKey(),
 			apiBase,
 			userAgent,
 			cfg.RequestTimeout,
-		), modelID, nil
+		)
+		provider.SetAuthMethod(cfg.AuthMethod)
+		provider.SetCustomHeaders(cfg.CustomHeaders)
+		return provider, modelID, nil
 
 	case "coding-plan-anthropic", "alibaba-coding-anthropic":
 		// Alibaba Coding Plan with Anthropic-compatible API
@@ -340,12 +432,14 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if cfg.APIKey() == "" {
 			return nil, "", fmt.Errorf("api_key is required for %q protocol (model: %s)", protocol, cfg.Model)
 		}
-		return anthropicmessages.NewProviderWithTimeout(
+		provider := anthropicmessages.NewProviderWithTimeout(
 			cfg.APIKey(),
 			apiBase,
 			userAgent,
 			cfg.RequestTimeout,
-		), modelID, nil
+		)
+		provider.SetCustomHeaders(cfg.CustomHeaders)
+		return provider, modelID, nil
 
 	case "antigravity":
 		return NewAntigravityProvider(), modelID, nil
@@ -379,6 +473,11 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		}
 		return provider, modelID, nil
 
+	case "router":
+		// A router resolves other model_list entries, so it is built by
+		// CreateProvider, which has the whole config.
+		return nil, "", fmt.Errorf("router model %q can only be used as the default model", cfg.ModelName)
+
 	default:
 		return nil, "", fmt.Errorf("unknown protocol %q in model %q", protocol, cfg.Model)
 	}
@@ -650,6 +650,27 @@ func TestCreateProviderFromConfig_AzureOpenAIAlias(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_AzureWithAPIVersion(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName:  "azure-gpt4o",
+		Model:      "azure/gpt-4o-prod",
+		APIBase:    "https://my-resource.openai.azure.com",
+		APIVersion: "2024-10-21",
+	}
+	cfg.SetAPIKey("test-azure-key")
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if provider == nil {
+		t.Fatal("CreateProviderFromConfig() returned nil provider")
+	}
+	if modelID != "gpt-4o-prod" {
+		t.Errorf("modelID = %q, want %q", modelID, "gpt-4o-prod")
+	}
+}
+
 func TestCreateProviderFromConfig_AzureMissingAPIKey(t *testing.T) {
 	cfg := &config.ModelConfig{
 		ModelName: "azure-gpt5",
@@ -903,6 +924,7 @@ func TestCreateProviderFromConfig_MinimaxPreservesUserExtraBody(t *testing.T) {
 }
 
 func TestCreateProviderFromConfig_CustomHeaders(t *testing.T) {
+	t.Setenv("PICO_TEST_GATEWAY_TOKEN", "config-auth")
 	var gotSource, gotAuth string
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -917,7 +939,7 @@ func TestCreateProviderFromConfig_CustomHeaders(t *testing.T) {
 		ModelName:     "test-headers",
 		Model:         "openai/gpt-4o",
 		APIBase:       server.URL,
-		CustomHeaders: map[string]string{"X-Source": "coding-plan", "Authorization": "Token config-auth"},
+		CustomHeaders: map[string]string{"X-Source": "coding-plan", "Authorization": "Token ${PICO_TEST_GATEWAY_TOKEN}"},
 	}
 	cfg.SetAPIKey("test-key")
 
@@ -943,6 +965,11 @@ func TestCreateProviderFromConfig_CustomHeaders(t *testing.T) {
 	if gotAuth != "Token config-auth" {
 		t.Fatalf("Authorization = %q, want %q", gotAuth, "Token config-auth")
 	}
+	// The header is expanded for the request only; the config keeps the
+	// reference so saving it does not write the token to disk.
+	if got := cfg.CustomHeaders["Authorization"]; got != "Token ${PICO_TEST_GATEWAY_TOKEN}" {
+		t.Fatalf("config Authorization = %q, want the reference kept", got)
+	}
 }
 
 // openaiCompatResponse is the JSON response used by OpenAI-compatible providers.
@@ -1120,3 +1147,121 @@ func TestCreateProviderFromConfig_BedrockWithEndpointURL(t *testing.T) {
 	// Unexpected error - fail the test
 	t.Errorf("unexpected error from bedrock provider: %v", err)
 }
+
+func TestCreateProviderFromConfig_Custom(t *testing.T) {
+	var (
+		requestPath string
+		requestAuth string
+		requestBody map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		requestAuth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	// No API key: custom endpoints may be unauthenticated.
+	cfg := &config.ModelConfig{
+		ModelName: "my-endpoint",
+		Model:     "custom/meta-llama/Llama-3.3-70B",
+		APIBase:   server.URL + "/v1",
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*HTTPProvider); !ok {
+		t.Fatalf("expected *HTTPProvider, got %T", provider)
+	}
+	if modelID != "meta-llama/Llama-3.3-70B" {
+		t.Errorf("modelID = %q, want %q", modelID, "meta-llama/Llama-3.3-70B")
+	}
+
+	if _, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, modelID, nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if requestPath != "/v1/chat/completions" {
+		t.Errorf("request path = %q, want /v1/chat/completions", requestPath)
+	}
+	if requestBody["model"] != "meta-llama/Llama-3.3-70B" {
+		t.Errorf("request model = %v, want meta-llama/Llama-3.3-70B", requestBody["model"])
+	}
+	if requestAuth != "" {
+		t.Errorf("Authorization = %q, want none without api_key", requestAuth)
+	}
+}
+
+func TestCreateProviderFromConfig_CustomRequiresAPIBase(t *testing.T) {
+	cfg := &config.ModelConfig{ModelName: "my-endpoint", Model: "custom/some-model"}
+	cfg.SetAPIKey("test-key")
+
+	if _, _, err := CreateProviderFromConfig(cfg); err == nil {
+		t.Fatal("expected error when api_base is missing")
+	}
+}
+
+func TestCreateProviderFromConfig_OAuthClientCredentials(t *testing.T) {
+	var tokenRequests int
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %v", err)
+		}
+		if got := r.Form.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("grant_type = %q, want client_credentials", got)
+		}
+		if got := r.Form.Get("scope"); got != "llm.invoke" {
+			t.Errorf("scope = %q, want llm.invoke", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"gateway-token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	var authHeaders []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(openaiCompatResponse))
+	}))
+	defer apiServer.Close()
+
+	cfg := &config.ModelConfig{
+		ModelName:  "gateway",
+		Model:      "openai/gpt-4o",
+		APIBase:    apiServer.URL,
+		AuthMethod: "oauth",
+		OAuth: &config.ModelOAuthConfig{
+			TokenURL: tokenServer.URL,
+			ClientID: "picoclaw",
+			Scopes:   []string{"llm.invoke"},
+		},
+	}
+	cfg.OAuth.ClientSecret.Set("secret")
+
+	provider, _, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	for range 2 {
+		if _, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+
+	if tokenRequests != 1 {
+		t.Errorf("token requests = %d, want 1 (token cached)", tokenRequests)
+	}
+	for _, got := range authHeaders {
+		if got != "Bearer gateway-token" {
+			t.Errorf("Authorization = %q, want the gateway token", got)
+		}
+	}
+}
@@ -9,8 +9,9 @@ import (
 
 // FallbackChain orchestrates model fallback across multiple candidates.
 type FallbackChain struct {
-	cooldown *CooldownTracker
-	rl       *RateLimiterRegistry
+	cooldown   *CooldownTracker
+	rl         *RateLimiterRegistry
+	hedgeDelay time.Duration // race mode when > 0, see SetHedgeDelay
 }
 
 // FallbackCandidate represents one model/provider to try.
@@ -54,6 +55,12 @@ func NewFallbackChain(cooldown *CooldownTracker, rl *RateLimiterRegistry) *Fallb
 	return &FallbackChain{cooldown: cooldown, rl: rl}
 }
 
+// Status returns the circuit breaker state of candidates that have failed,
+// keyed by their stable candidate identity.
+func (fc *FallbackChain) Status() []ProviderHealth {
+	return fc.cooldown.Status()
+}
+
 // ResolveCandidates parses model config into a deduplicated candidate list.
 func ResolveCandidates(cfg ModelConfig, defaultProvider string) []FallbackCandidate {
 	return ResolveCandidatesWithLookup(cfg, defaultProvider, nil)
@@ -111,6 +118,8 @@ func ResolveCandidatesWithLookup(
 //   - Retriable errors trigger fallback to next candidate.
 //   - Success marks provider as good (resets cooldown).
 //   - If all fail, returns aggregate error with all attempts.
+//   - In race mode (SetHedgeDelay), fallbacks start in parallel once the
+//     primary is slower than the hedge delay.
 func (fc *FallbackChain) Execute(
 	ctx context.Context,
 	candidates []FallbackCandidate,
@@ -119,6 +128,17 @@ func (fc *FallbackChain) Execute(
 	if len(candidates) == 0 {
 		return nil, fmt.Errorf("fallback: no candidates configured")
 	}
+	if fc.hedgeDelay > 0 && len(candidates) > 1 {
+		return fc.executeRace(ctx, candidates, run)
+	}
+	return fc.executeSequential(ctx, candidates, run)
+}
+
+func (fc *FallbackChain) executeSequential(
+	ctx context.Context,
+	candidates []FallbackCandidate,
+	run func(ctx context.Context, provider, model string) (*LLMResponse, error),
+) (*FallbackResult, error) {
 
 	result := &FallbackResult{
 		Attempts: make([]FallbackAttempt, 0, len(candidates)),
@@ -132,19 +152,19 @@ func (fc *FallbackChain) Execute(
 
 		// Check cooldown per stable candidate identity, not just provider/model.
 		// This allows aliases and multi-key configs to fail over independently.
+		// A recovering candidate is probed by one request at a time.
 		cooldownKey := candidate.StableKey()
-		if !fc.cooldown.IsAvailable(cooldownKey) {
-			remaining := fc.cooldown.CooldownRemaining(cooldownKey)
+		if !fc.cooldown.Allow(cooldownKey) {
+			skipErr := fmt.Errorf("%s recovery probe in progress", cooldownKey)
+			if remaining := fc.cooldown.CooldownRemaining(cooldownKey); remaining > 0 {
+				skipErr = fmt.Errorf("%s in cooldown (%s remaining)", cooldownKey, remaining.Round(time.Second))
+			}
 			result.Attempts = append(result.Attempts, FallbackAttempt{
 				Provider: candidate.Provider,
 				Model:    candidate.Model,
 				Skipped:  true,
 				Reason:   FailoverRateLimit,
-				Error: fmt.Errorf(
-					"%s in cooldown (%s remaining)",
-					cooldownKey,
-					remaining.Round(time.Second),
-				),
+				Error:    skipErr,
 			})
 			continue
 		}
@@ -154,6 +174,7 @@ func (fc *FallbackChain) Execute(
 		if fc.rl != nil {
 			if !fc.rl.TryAcquire(cooldownKey) {
 				if i < len(candidates)-1 {
+					fc.cooldown.ReleaseProbe(cooldownKey)
 					result.Attempts = append(result.Attempts, FallbackAttempt{
 						Provider: candidate.Provider,
 						Model:    candidate.Model,
@@ -164,6 +185,7 @@ func (fc *FallbackChain) Execute(
 					continue
 				}
 				if waitErr := fc.rl.Wait(ctx, cooldownKey); waitErr != nil {
+					fc.cooldown.ReleaseProbe(cooldownKey)
 					result.Attempts = append(result.Attempts, FallbackAttempt{
 						Provider: candidate.Provider,
 						Model:    candidate.Model,
@@ -192,6 +214,7 @@ func (fc *FallbackChain) Execute(
 
 		// Context cancellation: abort immediately, no fallback.
 		if ctx.Err() == context.Canceled {
+			fc.cooldown.ReleaseProbe(cooldownKey)
 			result.Attempts = append(result.Attempts, FallbackAttempt{
 				Provider: candidate.Provider,
 				Model:    candidate.Model,
@@ -206,6 +229,7 @@ func (fc *FallbackChain) Execute(
 
 		if failErr == nil {
 			// Unclassifiable error: do not fallback, return immediately.
+			fc.cooldown.ReleaseProbe(cooldownKey)
 			result.Attempts = append(result.Attempts, FallbackAttempt{
 				Provider: candidate.Provider,
 				Model:    candidate.Model,
@@ -218,6 +242,7 @@ func (fc *FallbackChain) Execute(
 
 		// Non-retriable error: abort immediately.
 		if !failErr.IsRetriable() {
+			fc.cooldown.ReleaseProbe(cooldownKey)
 			result.Attempts = append(result.Attempts, FallbackAttempt{
 				Provider: candidate.Provider,
 				Model:    candidate.Model,
@@ -370,3 +395,15 @@ func (e *FallbackExhaustedError) Error() string {
 	}
 	return sb.String()
 }
+
+// Unwrap exposes the attempts' errors, so errors.As can find e.g. the
+// *ProviderError behind an exhausted chain.
+func (e *FallbackExhaustedError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Attempts))
+	for _, a := range e.Attempts {
+		if a.Error != nil {
+			errs = append(errs, a.Error)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,94 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SetHedgeDelay enables race mode for Execute: when the primary candidate has
+// not answered within d, the remaining candidates are started in parallel and
+// the first successful response wins. The losing request is cancelled through
+// its context. Zero (the default) keeps the chain strictly sequential.
+func (fc *FallbackChain) SetHedgeDelay(d time.Duration) {
+	fc.hedgeDelay = max(d, 0)
+}
+
+// raceLane is the outcome of running one part of the chain.
+type raceLane struct {
+	primary bool
+	result  *FallbackResult
+	err     error
+}
+
+// executeRace runs the primary candidate and, after the hedge delay (or as
+// soon as the primary fails over), the rest of the chain. Each lane goes
+// through the regular sequential Execute, so cooldowns, rate limits and error
+// classification apply unchanged.
+func (fc *FallbackChain) executeRace(
+	ctx context.Context,
+	candidates []FallbackCandidate,
+	run func(ctx context.Context, provider, model string) (*LLMResponse, error),
+) (*FallbackResult, error) {
+	primaryCtx, cancelPrimary := context.WithCancel(ctx)
+	defer cancelPrimary()
+	fallbackCtx, cancelFallback := context.WithCancel(ctx)
+	defer cancelFallback()
+
+	// Buffered so a lane that finishes after the race is decided can still
+	// deliver its outcome and exit.
+	lanes := make(chan raceLane, 2)
+	startLane := func(laneCtx context.Context, primary bool, laneCandidates []FallbackCandidate) {
+		go func() {
+			result, err := fc.executeSequential(laneCtx, laneCandidates, run)
+			lanes <- raceLane{primary: primary, result: result, err: err}
+		}()
+	}
+
+	startLane(primaryCtx, true, candidates[:1])
+	hedge := time.NewTimer(fc.hedgeDelay)
+	defer hedge.Stop()
+
+	var attempts []FallbackAttempt
+	running, fallbackStarted := 1, false
+	startFallback := func() {
+		if !fallbackStarted {
+			fallbackStarted = true
+			running++
+			startLane(fallbackCtx, false, candidates[1:])
+		}
+	}
+
+	for running > 0 {
+		select {
+		case <-hedge.C:
+			startFallback()
+		case lane := <-lanes:
+			running--
+			if lane.err == nil {
+				// Winner: cancel the other lane.
+				if lane.primary {
+					cancelFallback()
+				} else {
+					cancelPrimary()
+				}
+				lane.result.Attempts = append(attempts, lane.result.Attempts...)
+				return lane.result, nil
+			}
+
+			var exhausted *FallbackExhaustedError
+			if !errors.As(lane.err, &exhausted) {
+				// Cancellation or a non-retriable error ends the whole chain,
+				// as it does in sequential mode.
+				return nil, lane.err
+			}
+			attempts = append(attempts, exhausted.Attempts...)
+			if lane.primary {
+				// The primary failed over before the hedge fired.
+				startFallback()
+			}
+		}
+	}
+
+	return nil, &FallbackExhaustedError{Attempts: attempts}
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFallbackRace_SlowPrimaryLosesToFallback(t *testing.T) {
+	fc := NewFallbackChain(NewCooldownTracker(), nil)
+	fc.SetHedgeDelay(10 * time.Millisecond)
+
+	primaryDone := make(chan error, 1)
+	run := func(ctx context.Context, provider, model string) (*LLMResponse, error) {
+		if provider == "openai" {
+			<-ctx.Done()
+			primaryDone <- ctx.Err()
+			return nil, ctx.Err()
+		}
+		return &LLMResponse{
+			Content:   "fallback",
+			ToolCalls: []ToolCall{{ID: "call_1", Name: "read_file", Arguments: map[string]any{"path": "a.txt"}}},
+		}, nil
+	}
+
+	candidates := []FallbackCandidate{makeCandidate("openai", "gpt-4"), makeCandidate("anthropic", "claude")}
+	result, err := fc.Execute(context.Background(), candidates, run)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "anthropic" {
+		t.Fatalf("provider = %q, want anthropic", result.Provider)
+	}
+	if len(result.Response.ToolCalls) != 1 || result.Response.ToolCalls[0].Arguments["path"] != "a.txt" {
+		t.Fatalf("tool calls = %+v, want winner's tool call intact", result.Response.ToolCalls)
+	}
+
+	select {
+	case err := <-primaryDone:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("primary ctx err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("losing primary request was not cancelled")
+	}
+	if got := fc.Status(); len(got) != 0 {
+		t.Fatalf("cancelled loser must not be marked as failed: %+v", got)
+	}
+}
+
+func TestFallbackRace_FastPrimaryDoesNotStartFallback(t *testing.T) {
+	fc := NewFallbackChain(NewCooldownTracker(), nil)
+	fc.SetHedgeDelay(time.Second)
+
+	run := func(ctx context.Context, provider, model string) (*LLMResponse, error) {
+		if provider != "openai" {
+			t.Errorf("fallback %s should not run", provider)
+		}
+		return &LLMResponse{Content: "primary"}, nil
+	}
+
+	candidates := []FallbackCandidate{makeCandidate("openai", "gpt-4"), makeCandidate("anthropic", "claude")}
+	result, err := fc.Execute(context.Background(), candidates, run)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "openai" {
+		t.Fatalf("provider = %q, want openai", result.Provider)
+	}
+}
+
+func TestFallbackRace_PrimaryFailureStartsFallbackImmediately(t *testing.T) {
+	fc := NewFallbackChain(NewCooldownTracker(), nil)
+	fc.SetHedgeDelay(time.Hour)
+
+	run := func(ctx context.Context, provider, model string) (*LLMResponse, error) {
+		if provider == "openai" {
+			return nil, errors.New("rate limit exceeded")
+		}
+		return &LLMResponse{Content: "fallback"}, nil
+	}
+
+	candidates := []FallbackCandidate{makeCandidate("openai", "gpt-4"), makeCandidate("anthropic", "claude")}
+	result, err := fc.Execute(context.Background(), candidates, run)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "anthropic" || len(result.Attempts) != 1 {
+		t.Fatalf("result = %s with %d attempts, want anthropic after 1 attempt", result.Provider, len(result.Attempts))
+	}
+}
+
+func TestFallbackRace_AllFail(t *testing.T) {
+	fc := NewFallbackChain(NewCooldownTracker(), nil)
+	fc.SetHedgeDelay(time.Millisecond)
+
+	run := func(ctx context.Context, provider, model string) (*LLMResponse, error) {
+		return nil, errors.New("rate limit exceeded")
+	}
+
+	candidates := []FallbackCandidate{makeCandidate("openai", "gpt-4"), makeCandidate("anthropic", "claude")}
+	_, err := fc.Execute(context.Background(), candidates, run)
+	var exhausted *FallbackExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("err = %v, want FallbackExhaustedError", err)
+	}
+	if len(exhausted.Attempts) != 2 {
+		t.Fatalf("attempts = %d, want 2", len(exhausted.Attempts))
+	}
+}
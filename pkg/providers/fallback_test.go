@@ -191,6 +191,35 @@ func TestFallback_CooldownSkip(t *testing.T) {
 	}
 }
 
+func TestFallback_HalfOpenProbeReleasedOnAbort(t *testing.T) {
+	now := time.Now()
+	ct, current := newTestTracker(now)
+	fc := NewFallbackChain(ct, nil)
+
+	key := ModelKey("openai", "gpt-4")
+	ct.MarkFailure(key, FailoverTimeout)
+	*current = now.Add(2 * time.Minute)
+
+	candidates := []FallbackCandidate{
+		makeCandidate("openai", "gpt-4"),
+		makeCandidate("anthropic", "claude"),
+	}
+	run := func(ctx context.Context, provider, model string) (*LLMResponse, error) {
+		return nil, errors.New("invalid request format")
+	}
+
+	if _, err := fc.Execute(context.Background(), candidates, run); err == nil {
+		t.Fatal("expected non-retriable error")
+	}
+	// The aborted probe recorded no outcome, so the next request probes again.
+	if !ct.Allow(key) {
+		t.Fatal("aborted probe should be released")
+	}
+	if got := fc.Status(); len(got) != 1 || got[0].Provider != key || got[0].State != BreakerHalfOpen {
+		t.Fatalf("Status() = %+v, want %s half-open", got, key)
+	}
+}
+
 func TestFallback_AllInCooldown(t *testing.T) {
 	ct := NewCooldownTracker()
 	fc := NewFallbackChain(ct, nil)
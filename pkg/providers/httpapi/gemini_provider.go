@@ -55,6 +55,16 @@ func NewGeminiProvider(
 	}
 }
 
+// EnableWireLog logs request and response bodies with credentials redacted.
+func (p *GeminiProvider) EnableWireLog(maxBytes int) {
+	common.EnableWireLog(p.httpClient, p.apiBase, maxBytes)
+}
+
+// EnableClientCredentials authenticates with an OAuth client-credentials grant.
+func (p *GeminiProvider) EnableClientCredentials(creds common.ClientCredentials) {
+	common.EnableClientCredentials(p.httpClient, creds)
+}
+
 func (p *GeminiProvider) GetDefaultModel() string {
 	return geminiDefaultModel
 }
@@ -96,7 +106,7 @@ func (p *GeminiProvider) Chat(
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, common.HandleErrorResponse(resp, p.apiBase)
+		return nil, common.HandleErrorResponse(resp, "gemini", p.apiBase)
 	}
 
 	var apiResp geminiGenerateContentResponse
@@ -144,7 +154,7 @@ func (p *GeminiProvider) ChatStream(
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, common.HandleErrorResponse(resp, p.apiBase)
+		return nil, common.HandleErrorResponse(resp, "gemini", p.apiBase)
 	}
 
 	return parseGeminiStreamResponse(ctx, resp.Body, onChunk)
@@ -335,6 +345,18 @@ func buildGeminiThinkingConfig(model string, options map[string]any) map[string]
 	}
 
 	config := map[string]any{}
+	if budget, ok := common.AsInt(options["thinking_budget"]); ok && budget > 0 {
+		// An explicit budget replaces the level. Gemini 3 only takes a
+		// level, so the budget is mapped onto the nearest one.
+		config["includeThoughts"] = true
+		if isGemini25Model(model) {
+			config["thinkingBudget"] = budget
+		} else {
+			config["thinkingLevel"] = geminiBudgetThinkingLevel(budget)
+		}
+		return config
+	}
+
 	rawLevel, _ := options["thinking_level"].(string)
 	rawLevel = strings.ToLower(strings.TrimSpace(rawLevel))
 	if rawLevel == "" {
@@ -387,6 +409,19 @@ func isGemini3ProModel(model string) bool {
 	return strings.Contains(lowerModel, "gemini-3") && strings.Contains(lowerModel, "pro")
 }
 
+// geminiBudgetThinkingLevel maps a token budget onto the Gemini 3 levels,
+// using the budgets mapGeminiThinkingBudget gives those levels.
+func geminiBudgetThinkingLevel(budget int) string {
+	switch {
+	case budget <= 1024:
+		return "low"
+	case budget <= 4096:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
 func mapGeminiThinkingBudget(level string) (int, bool) {
 	level = strings.ToLower(strings.TrimSpace(level))
 	if level == "" {
@@ -446,8 +481,15 @@ func parseGeminiResponse(resp *geminiGenerateContentResponse) *LLMResponse {
 		}
 	}
 
+	content := strings.Join(contentParts, "")
+	if content == "" && len(toolCalls) == 0 {
+		if notice, blockReason := geminiBlockNotice(resp.PromptFeedback, finishReason); notice != "" {
+			content, finishReason = notice, blockReason
+		}
+	}
+
 	return &LLMResponse{
-		Content:          strings.Join(contentParts, ""),
+		Content:          content,
 		ReasoningContent: strings.Join(reasoningParts, ""),
 		ToolCalls:        toolCalls,
 		FinishReason:     normalizeGeminiFinishReason(finishReason, len(toolCalls)),
@@ -464,6 +506,7 @@ func parseGeminiStreamResponse(
 	var reasoningBuilder strings.Builder
 	var finishReason string
 	var usage *UsageInfo
+	var promptFeedback geminiPromptFeedback
 
 	toolCallsByID := make(map[string]ToolCall)
 	toolCallOrder := make([]string, 0)
@@ -537,6 +580,10 @@ func parseGeminiStreamResponse(
 			}
 		}
 
+		if chunk.PromptFeedback.BlockReason != "" {
+			promptFeedback = chunk.PromptFeedback
+		}
+
 		if chunk.UsageMetadata.TotalTokenCount > 0 {
 			usage = &UsageInfo{
 				PromptTokens:     chunk.UsageMetadata.PromptTokenCount,
@@ -555,8 +602,15 @@ func parseGeminiStreamResponse(
 		toolCalls = append(toolCalls, toolCallsByID[key])
 	}
 
+	content := contentBuilder.String()
+	if content == "" && len(toolCalls) == 0 {
+		if notice, blockReason := geminiBlockNotice(promptFeedback, finishReason); notice != "" {
+			content, finishReason = notice, blockReason
+		}
+	}
+
 	return &LLMResponse{
-		Content:          contentBuilder.String(),
+		Content:          content,
 		ReasoningContent: reasoningBuilder.String(),
 		ToolCalls:        toolCalls,
 		FinishReason:     normalizeGeminiFinishReason(finishReason, len(toolCalls)),
@@ -569,7 +623,11 @@ func normalizeGeminiFinishReason(reason string, toolCalls int) string {
 		return "tool_calls"
 	}
 
-	switch strings.ToUpper(strings.TrimSpace(reason)) {
+	upper := strings.ToUpper(strings.TrimSpace(reason))
+	if geminiBlockReasons[upper] {
+		return "content_filter"
+	}
+	switch upper {
 	case "MAX_TOKENS":
 		return "length"
 	case "", "STOP":
@@ -579,6 +637,36 @@ func normalizeGeminiFinishReason(reason string, toolCalls int) string {
 	}
 }
 
+// geminiBlockReasons are the finish and block reasons meaning Gemini
+// withheld content, reported as the OpenAI-style "content_filter".
+var geminiBlockReasons = map[string]bool{
+	"SAFETY":             true,
+	"RECITATION":         true,
+	"BLOCKLIST":          true,
+	"PROHIBITED_CONTENT": true,
+	"SPII":               true,
+	"IMAGE_SAFETY":       true,
+}
+
+// geminiBlockNotice explains an empty response that Gemini blocked, either
+// the prompt (promptFeedback) or the candidate (finishReason). Without it the
+// user would only see a generic empty reply. It returns the notice and the
+// reason to report, or "" when nothing was blocked.
+func geminiBlockNotice(feedback geminiPromptFeedback, finishReason string) (string, string) {
+	if reason := strings.TrimSpace(feedback.BlockReason); reason != "" {
+		notice := fmt.Sprintf("Gemini blocked the prompt (%s).", reason)
+		if msg := strings.TrimSpace(feedback.BlockReasonMessage); msg != "" {
+			notice = fmt.Sprintf("Gemini blocked the prompt (%s): %s", reason, msg)
+		}
+		// Any prompt block is a content filter, whatever its reason name.
+		return notice, "SAFETY"
+	}
+	if geminiBlockReasons[strings.ToUpper(strings.TrimSpace(finishReason))] {
+		return fmt.Sprintf("Gemini withheld the response (%s).", finishReason), finishReason
+	}
+	return "", ""
+}
+
 func buildGeminiToolCall(part geminiPart) ToolCall {
 	if part.FunctionCall == nil {
 		return ToolCall{}
@@ -740,13 +828,19 @@ type geminiGenerateContentResponse struct {
 		} `json:"content"`
 		FinishReason string `json:"finishReason"`
 	} `json:"candidates"`
-	UsageMetadata struct {
+	PromptFeedback geminiPromptFeedback `json:"promptFeedback"`
+	UsageMetadata  struct {
 		PromptTokenCount     int `json:"promptTokenCount"`
 		CandidatesTokenCount int `json:"candidatesTokenCount"`
 		TotalTokenCount      int `json:"totalTokenCount"`
 	} `json:"usageMetadata"`
 }
 
+type geminiPromptFeedback struct {
+	BlockReason        string `json:"blockReason"`
+	BlockReasonMessage string `json:"blockReasonMessage"`
+}
+
 type geminiContent struct {
 	Role  string       `json:"role,omitempty"`
 	Parts []geminiPart `json:"parts"`
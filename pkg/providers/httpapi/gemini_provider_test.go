@@ -496,6 +496,37 @@ func TestGeminiProvider_BuildRequestBody_UsesThinkingBudgetForGemini25(t *testin
 	}
 }
 
+func TestGeminiProvider_BuildRequestBody_ThinkingBudgetOverridesLevel(t *testing.T) {
+	provider := NewGeminiProvider("test-key", "https://example.com/v1beta", "", "", 0, nil, nil)
+	tests := []struct {
+		model string
+		key   string
+		want  any
+	}{
+		{model: "gemini-2.5-flash", key: "thinkingBudget", want: 2000},
+		{model: "gemini-3-flash-preview", key: "thinkingLevel", want: "medium"},
+	}
+	for _, tt := range tests {
+		body := provider.buildRequestBody(
+			[]Message{{Role: "user", Content: "hello"}},
+			nil,
+			tt.model,
+			map[string]any{"thinking_level": "off", "thinking_budget": 2000},
+		)
+		generationConfig, _ := body["generationConfig"].(map[string]any)
+		thinkingConfig, ok := generationConfig["thinkingConfig"].(map[string]any)
+		if !ok {
+			t.Fatalf("%s: thinkingConfig = %#v, want map", tt.model, generationConfig["thinkingConfig"])
+		}
+		if got := thinkingConfig[tt.key]; got != tt.want {
+			t.Errorf("%s: %s = %#v, want %#v", tt.model, tt.key, got, tt.want)
+		}
+		if thinkingConfig["includeThoughts"] != true {
+			t.Errorf("%s: includeThoughts = %#v, want true", tt.model, thinkingConfig["includeThoughts"])
+		}
+	}
+}
+
 func TestGeminiProvider_BuildRequestBody_OmitsThinkingConfigForGemini20(t *testing.T) {
 	provider := NewGeminiProvider("test-key", "https://example.com/v1beta", "", "", 0, nil, nil)
 	body := provider.buildRequestBody(
@@ -761,3 +792,56 @@ func TestGeminiProvider_ChatAllowsMissingAPIKeyForCustomAPIBase(t *testing.T) {
 		t.Fatalf("Content = %q, want %q", resp.Content, "ok")
 	}
 }
+
+func TestGeminiProvider_ChatReportsBlockedPrompt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"promptFeedback":{"blockReason":"PROHIBITED_CONTENT","blockReasonMessage":"not allowed"}}`)
+	}))
+	defer server.Close()
+
+	provider := NewGeminiProvider("test-key", server.URL, "", "", 0, nil, nil)
+	resp, err := provider.Chat(
+		t.Context(),
+		[]Message{{Role: "user", Content: "hello"}},
+		nil,
+		"gemini-2.5-flash",
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.FinishReason != "content_filter" {
+		t.Fatalf("FinishReason = %q, want content_filter", resp.FinishReason)
+	}
+	if !strings.Contains(resp.Content, "PROHIBITED_CONTENT") || !strings.Contains(resp.Content, "not allowed") {
+		t.Fatalf("Content = %q, want the block reason and message", resp.Content)
+	}
+}
+
+func TestGeminiProvider_ChatStreamReportsSafetyFinish(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, `data: {"candidates":[{"content":{"parts":[]},"finishReason":"SAFETY"}]}`+"\n\n")
+	}))
+	defer server.Close()
+
+	provider := NewGeminiProvider("test-key", server.URL, "", "", 0, nil, nil)
+	resp, err := provider.ChatStream(
+		t.Context(),
+		[]Message{{Role: "user", Content: "hello"}},
+		nil,
+		"gemini-2.5-flash",
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if resp.FinishReason != "content_filter" {
+		t.Fatalf("FinishReason = %q, want content_filter", resp.FinishReason)
+	}
+	if !strings.Contains(resp.Content, "SAFETY") {
+		t.Fatalf("Content = %q, want the finish reason", resp.Content)
+	}
+}
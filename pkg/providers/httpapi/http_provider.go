@@ -10,6 +10,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/providers/common"
 	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
 )
 
@@ -47,6 +48,16 @@ func NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
 	}
 }
 
+// EnableWireLog logs request and response bodies with credentials redacted.
+func (p *HTTPProvider) EnableWireLog(maxBytes int) {
+	p.delegate.EnableWireLog(maxBytes)
+}
+
+// EnableClientCredentials authenticates with an OAuth client-credentials grant.
+func (p *HTTPProvider) EnableClientCredentials(creds common.ClientCredentials) {
+	p.delegate.EnableClientCredentials(creds)
+}
+
 func (p *HTTPProvider) Chat(
 	ctx context.Context,
 	messages []Message,
@@ -70,6 +81,11 @@ func (p *HTTPProvider) ChatStream(
 	return p.delegate.ChatStream(ctx, messages, tools, model, options, onChunk)
 }
 
+// ListModels implements providers.ModelLister via the /models endpoint.
+func (p *HTTPProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return p.delegate.ListModels(ctx)
+}
+
 func (p *HTTPProvider) GetDefaultModel() string {
 	return ""
 }
@@ -77,3 +93,7 @@ func (p *HTTPProvider) GetDefaultModel() string {
 func (p *HTTPProvider) SupportsNativeSearch() bool {
 	return p.delegate.SupportsNativeSearch()
 }
+
+func (p *HTTPProvider) SupportsThinking() bool {
+	return p.delegate.SupportsThinking()
+}
@@ -0,0 +1,203 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const defaultKeyCooldown = time.Minute
+
+// KeyPool spreads requests for one model across providers that differ only
+// in their API key, to get past a per-key rate limit. Keys are used
+// round-robin; a key that answers with a rate limit is quarantined for the
+// cooldown and the request moves on to the next key. When every key is
+// quarantined the pool fails with a rate-limit error, so the fallback chain
+// can move on to the next model.
+type KeyPool struct {
+	providers []LLMProvider
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	next  int
+	until []time.Time // quarantine end per key
+	now   func() time.Time
+}
+
+// NewKeyPool returns a pool over providers, one per key. A cooldown of zero
+// uses the default of one minute.
+func NewKeyPool(providers []LLMProvider, cooldown time.Duration) (*KeyPool, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("key pool: at least one provider is required")
+	}
+	if cooldown <= 0 {
+		cooldown = defaultKeyCooldown
+	}
+	return &KeyPool{
+		providers: providers,
+		cooldown:  cooldown,
+		until:     make([]time.Time, len(providers)),
+		now:       time.Now,
+	}, nil
+}
+
+func (p *KeyPool) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.do(ctx, func(provider LLMProvider) (*LLMResponse, error) {
+		return provider.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+// ChatStream is Chat for keys whose provider can stream. Other providers
+// answer in one piece.
+func (p *KeyPool) ChatStream(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onChunk func(accumulated string),
+) (*LLMResponse, error) {
+	return p.do(ctx, func(provider LLMProvider) (*LLMResponse, error) {
+		if sp, ok := provider.(StreamingProvider); ok {
+			return sp.ChatStream(ctx, messages, tools, model, options, onChunk)
+		}
+		return provider.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+func (p *KeyPool) GetDefaultModel() string {
+	return p.providers[0].GetDefaultModel()
+}
+
+// ListModels lists the models of the first key; every key reaches the same
+// endpoint.
+func (p *KeyPool) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	lister, ok := p.providers[0].(ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support listing models")
+	}
+	return lister.ListModels(ctx)
+}
+
+func (p *KeyPool) SupportsThinking() bool {
+	tc, ok := p.providers[0].(ThinkingCapable)
+	return ok && tc.SupportsThinking()
+}
+
+func (p *KeyPool) SupportsNativeSearch() bool {
+	ns, ok := p.providers[0].(NativeSearchCapable)
+	return ok && ns.SupportsNativeSearch()
+}
+
+// Close releases stateful providers behind the pool.
+func (p *KeyPool) Close() {
+	for _, provider := range p.providers {
+		if sp, ok := provider.(StatefulProvider); ok {
+			sp.Close()
+		}
+	}
+}
+
+// do runs call on the next available key, rotating past keys that are rate
+// limited. Errors other than rate limits are returned as they are, and
+// rotation stops once ctx is done.
+func (p *KeyPool) do(ctx context.Context, call func(LLMProvider) (*LLMResponse, error)) (*LLMResponse, error) {
+	var lastErr error
+	for range p.providers {
+		if err := ctx.Err(); err != nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, err
+		}
+		idx, wait := p.acquire()
+		if idx < 0 {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, &ProviderError{
+				Provider: "key_pool",
+				Status:   http.StatusTooManyRequests,
+				Message: fmt.Sprintf("all %d API keys are rate limited; the first resets in %s",
+					len(p.providers), wait.Round(time.Second)),
+			}
+		}
+		resp, err := call(p.providers[idx])
+		if err == nil {
+			return resp, nil
+		}
+		if fe := ClassifyError(err, "", ""); fe == nil || fe.Reason != FailoverRateLimit {
+			return nil, err
+		}
+		p.quarantine(idx)
+		logger.WarnCF("provider", "API key rate limited, rotating to the next key", map[string]any{
+			"key_index": idx,
+			"cooldown":  p.cooldown.String(),
+		})
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// acquire returns the next key that is not quarantined and advances the
+// rotation past it. When every key is quarantined it returns -1 and the time
+// until the first one is released.
+func (p *KeyPool) acquire() (int, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	var wait time.Duration
+	for i := range p.providers {
+		idx := (p.next + i) % len(p.providers)
+		remaining := p.until[idx].Sub(now)
+		if remaining <= 0 {
+			p.next = idx + 1
+			return idx, 0
+		}
+		if wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	return -1, wait
+}
+
+func (p *KeyPool) quarantine(idx int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.until[idx] = p.now().Add(p.cooldown)
+}
+
+// createKeyPoolProvider builds one provider per api_keys entry of a model
+// with key_pool set and pools them.
+func createKeyPoolProvider(cfg *config.ModelConfig) (LLMProvider, string, error) {
+	keys := cfg.APIKeys.Values()
+	providers := make([]LLMProvider, 0, len(keys))
+	var modelID string
+	for i, key := range keys {
+		sub := *cfg
+		sub.KeyPool = false
+		sub.APIKeys = config.SimpleSecureStrings(key)
+		provider, id, err := CreateProviderFromConfig(&sub)
+		if err != nil {
+			return nil, "", fmt.Errorf("api_keys[%d]: %w", i, err)
+		}
+		providers = append(providers, provider)
+		modelID = id
+	}
+	pool, err := NewKeyPool(providers, time.Duration(cfg.KeyCooldown)*time.Second)
+	if err != nil {
+		return nil, "", err
+	}
+	return pool, modelID, nil
+}
@@ -0,0 +1,174 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+type keyStubProvider struct {
+	name   string
+	err    error
+	calls  int
+	onCall func()
+}
+
+func (p *keyStubProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	p.calls++
+	if p.onCall != nil {
+		p.onCall()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &LLMResponse{Content: p.name}, nil
+}
+
+func (p *keyStubProvider) GetDefaultModel() string { return "stub" }
+
+func (p *keyStubProvider) ListModels(context.Context) ([]ModelInfo, error) {
+	return []ModelInfo{{ID: p.name + "-model"}}, nil
+}
+
+func chatContent(t *testing.T, pool *KeyPool) string {
+	t.Helper()
+	resp, err := pool.Chat(context.Background(), nil, nil, "stub", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	return resp.Content
+}
+
+func TestKeyPool_RoundRobin(t *testing.T) {
+	pool, err := NewKeyPool([]LLMProvider{
+		&keyStubProvider{name: "a"},
+		&keyStubProvider{name: "b"},
+		&keyStubProvider{name: "c"},
+	}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyPool() error = %v", err)
+	}
+	var got []string
+	for range 4 {
+		got = append(got, chatContent(t, pool))
+	}
+	if want := []string{"a", "b", "c", "a"}; !slices.Equal(got, want) {
+		t.Fatalf("keys used = %v, want %v", got, want)
+	}
+}
+
+func TestKeyPool_QuarantinesRateLimitedKey(t *testing.T) {
+	limited := &keyStubProvider{name: "a", err: errors.New("http error: status 429 - rate limit exceeded")}
+	healthy := &keyStubProvider{name: "b"}
+	pool, _ := NewKeyPool([]LLMProvider{limited, healthy}, 30*time.Second)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	for range 3 {
+		if got := chatContent(t, pool); got != "b" {
+			t.Fatalf("Chat() answered from %q, want b", got)
+		}
+	}
+	if limited.calls != 1 {
+		t.Fatalf("rate-limited key was called %d times, want 1 while quarantined", limited.calls)
+	}
+
+	// Once the cooldown has passed the key is tried again.
+	now = now.Add(31 * time.Second)
+	limited.err = nil
+	seen := map[string]bool{}
+	for range 2 {
+		seen[chatContent(t, pool)] = true
+	}
+	if !seen["a"] {
+		t.Fatal("key was not used again after its cooldown")
+	}
+}
+
+func TestKeyPool_AllKeysRateLimited(t *testing.T) {
+	rateLimited := errors.New("http error: status 429 - rate limit exceeded")
+	pool, _ := NewKeyPool([]LLMProvider{
+		&keyStubProvider{err: rateLimited},
+		&keyStubProvider{err: rateLimited},
+	}, 0)
+
+	if _, err := pool.Chat(context.Background(), nil, nil, "stub", nil); !errors.Is(err, rateLimited) {
+		t.Fatalf("Chat() error = %v, want the last rate-limit error", err)
+	}
+	_, err := pool.Chat(context.Background(), nil, nil, "stub", nil)
+	fe := ClassifyError(err, "p", "m")
+	if fe == nil || fe.Reason != FailoverRateLimit {
+		t.Fatalf("Chat() with every key quarantined: error = %v, want a rate-limit error", err)
+	}
+}
+
+func TestKeyPool_OtherErrorsDoNotRotate(t *testing.T) {
+	bad := &keyStubProvider{err: errors.New("http error: status 400 - invalid request")}
+	other := &keyStubProvider{name: "b"}
+	pool, _ := NewKeyPool([]LLMProvider{bad, other}, 0)
+
+	if _, err := pool.Chat(context.Background(), nil, nil, "stub", nil); err == nil {
+		t.Fatal("Chat() error = nil, want the request error")
+	}
+	if other.calls != 0 {
+		t.Fatal("a non-rate-limit error rotated to the next key")
+	}
+}
+
+func TestKeyPool_StopsRotatingWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rateLimited := errors.New("http error: status 429 - rate limit exceeded")
+	first := &keyStubProvider{err: rateLimited, onCall: cancel}
+	second := &keyStubProvider{name: "b"}
+	pool, _ := NewKeyPool([]LLMProvider{first, second}, 0)
+
+	if _, err := pool.Chat(ctx, nil, nil, "stub", nil); !errors.Is(err, rateLimited) {
+		t.Fatalf("Chat() error = %v, want the rate-limit error", err)
+	}
+	if second.calls != 0 {
+		t.Fatal("the pool rotated to the next key after the context was canceled")
+	}
+	if _, err := pool.Chat(ctx, nil, nil, "stub", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Chat() with a canceled context: error = %v, want context.Canceled", err)
+	}
+}
+
+func TestKeyPool_ListModelsUsesFirstKey(t *testing.T) {
+	pool, _ := NewKeyPool([]LLMProvider{&keyStubProvider{name: "a"}, &keyStubProvider{name: "b"}}, 0)
+	var lister ModelLister = pool
+	models, err := lister.ListModels(context.Background())
+	if err != nil || len(models) != 1 || models[0].ID != "a-model" {
+		t.Fatalf("ListModels() = %v, %v; want the first key's models", models, err)
+	}
+}
+
+func TestCreateProviderFromConfig_KeyPool(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "pooled",
+		Model:     "openai/gpt-4o",
+		APIBase:   "https://api.example.com/v1",
+		APIKeys:   config.SimpleSecureStrings("key-1", "key-2"),
+		KeyPool:   true,
+	}
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	pool, ok := provider.(*KeyPool)
+	if !ok {
+		t.Fatalf("provider = %T, want *KeyPool", provider)
+	}
+	if len(pool.providers) != 2 || modelID != "gpt-4o" {
+		t.Fatalf("pool has %d providers, modelID %q", len(pool.providers), modelID)
+	}
+}
@@ -34,8 +34,15 @@ func CreateProvider(cfg *config.Config) (LLMProvider, string, error) {
 		modelCfg.Workspace = cfg.WorkspacePath()
 	}
 
-	// Use factory to create provider
-	provider, modelID, err := CreateProviderFromConfig(modelCfg)
+	// Use factory to create provider. Routers need the whole model_list to
+	// build the providers they route to.
+	create := CreateProviderFromConfig
+	if protocol, _ := ExtractProtocol(modelCfg.Model); protocol == "router" {
+		create = func(mc *config.ModelConfig) (LLMProvider, string, error) {
+			return newRouterProviderFromConfig(cfg, mc)
+		}
+	}
+	provider, modelID, err := create(modelCfg)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create provider for model %q: %w", model, err)
 	}
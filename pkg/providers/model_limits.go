@@ -0,0 +1,41 @@
+package providers
+
+import "strings"
+
+// ModelLimits are generation constraints a model's API is known to enforce.
+type ModelLimits struct {
+	// MaxTemperature is the highest temperature the API accepts; 0 means no
+	// known cap.
+	MaxTemperature float64
+	// NoTemperature is set for models that reject any explicit temperature.
+	NoTemperature bool
+}
+
+// KnownModelLimits returns the limits of well-known model families, matched on
+// the model ID without its protocol or vendor prefix (so "o3-mini",
+// "openai/o3-mini" and "azure/o3-mini" all match).
+func KnownModelLimits(model string) ModelLimits {
+	id := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:]
+	}
+
+	switch {
+	case isOpenAIReasoningModel(id):
+		// The o-series and GPT-5 reasoning models only accept the default
+		// temperature and answer anything else with a 400.
+		return ModelLimits{NoTemperature: true}
+	case strings.HasPrefix(id, "claude"):
+		return ModelLimits{MaxTemperature: 1}
+	}
+	return ModelLimits{}
+}
+
+func isOpenAIReasoningModel(id string) bool {
+	for _, prefix := range []string{"o1", "o3", "o4"} {
+		if id == prefix || strings.HasPrefix(id, prefix+"-") {
+			return true
+		}
+	}
+	return strings.HasPrefix(id, "gpt-5") && !strings.Contains(id, "chat")
+}
@@ -0,0 +1,27 @@
+package providers
+
+import "testing"
+
+func TestKnownModelLimits(t *testing.T) {
+	tests := []struct {
+		model string
+		want  ModelLimits
+	}{
+		{"o1", ModelLimits{NoTemperature: true}},
+		{"o3-mini", ModelLimits{NoTemperature: true}},
+		{"openai/o4-mini", ModelLimits{NoTemperature: true}},
+		{"gpt-5", ModelLimits{NoTemperature: true}},
+		{"GPT-5-mini", ModelLimits{NoTemperature: true}},
+		{"gpt-5-chat-latest", ModelLimits{}},
+		{"claude-sonnet-4.6", ModelLimits{MaxTemperature: 1}},
+		{"anthropic/claude-opus", ModelLimits{MaxTemperature: 1}},
+		{"gpt-4o", ModelLimits{}},
+		{"olmo-2", ModelLimits{}},
+		{"", ModelLimits{}},
+	}
+	for _, tt := range tests {
+		if got := KnownModelLimits(tt.model); got != tt.want {
+			t.Errorf("KnownModelLimits(%q) = %+v, want %+v", tt.model, got, tt.want)
+		}
+	}
+}
@@ -14,6 +14,8 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 const (
@@ -36,9 +38,7 @@ type AntigravityProvider struct {
 func NewAntigravityProvider() *AntigravityProvider {
 	return &AntigravityProvider{
 		tokenSource: createAntigravityTokenSource(),
-		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		httpClient:  common.NewHTTPClient(""),
 	}
 }
 
@@ -127,7 +127,8 @@ func (p *AntigravityProvider) Chat(
 			"model":       model,
 		})
 
-		return nil, p.parseAntigravityError(resp.StatusCode, respBody)
+		return nil, protocoltypes.NewProviderError(
+			"antigravity", resp.StatusCode, respBody, p.parseAntigravityError(resp.StatusCode, respBody))
 	}
 
 	// Response is always SSE from streamGenerateContent — each line is "data: {...}"
@@ -640,7 +641,7 @@ func FetchAntigravityProjectID(accessToken string) (string, error) {
 	req.Header.Set("User-Agent", antigravityUserAgent)
 	req.Header.Set("X-Goog-Api-Client", antigravityXGoogClient)
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := common.NewHTTPClientWithTimeout("", 15*time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -684,7 +685,7 @@ func FetchAntigravityModels(accessToken, projectID string) ([]AntigravityModelIn
 	req.Header.Set("User-Agent", antigravityUserAgent)
 	req.Header.Set("X-Goog-Api-Client", antigravityXGoogClient)
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := common.NewHTTPClientWithTimeout("", 15*time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
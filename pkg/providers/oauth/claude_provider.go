@@ -56,6 +56,11 @@ func (p *ClaudeProvider) GetDefaultModel() string {
 	return p.delegate.GetDefaultModel()
 }
 
+// ListModels implements providers.ModelLister.
+func (p *ClaudeProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return p.delegate.ListModels(ctx)
+}
+
 func CreateClaudeTokenSource(getCredential func(string) (*auth.AuthCredential, error)) func() (string, error) {
 	return func() (string, error) {
 		cred, err := getCredential("anthropic")
@@ -139,6 +139,19 @@ func (p *CodexProvider) Chat(
 			}
 		}
 		logger.ErrorCF("provider.codex", "Codex API call failed", fields)
+		if apiErr != nil {
+			code := apiErr.Code
+			if code == "" {
+				code = apiErr.Type
+			}
+			return nil, &ProviderError{
+				Provider: "codex",
+				Status:   apiErr.StatusCode,
+				Code:     code,
+				Message:  apiErr.Message,
+				Err:      fmt.Errorf("codex API call: %w", err),
+			}
+		}
 		return nil, fmt.Errorf("codex API call: %w", err)
 	}
 	if resp == nil {
@@ -0,0 +1,89 @@
+package openai_compat
+
+import (
+	"crypto/sha256"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// mistralToolCallIDLen is the exact tool call ID length accepted by Mistral.
+const mistralToolCallIDLen = 9
+
+const mistralToolCallIDAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// isMistralHost reports whether apiBase points at Mistral's hosted API.
+func isMistralHost(apiBase string) bool {
+	u, err := url.Parse(apiBase)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	return host == "api.mistral.ai" || strings.HasSuffix(host, ".mistral.ai")
+}
+
+// normalizeMistralToolCallIDs rewrites tool call IDs into the 9-character
+// alphanumeric form Mistral requires. IDs produced by other providers (for
+// example "call_..." or "toolu_...") are rejected with a 400 when a
+// FallbackProvider chain hands an existing conversation over to Mistral.
+// The mapping is deterministic so assistant tool_calls and the matching
+// tool results stay paired. The input slice is never mutated.
+func normalizeMistralToolCallIDs(messages []Message) []Message {
+	needsRewrite := false
+	for _, m := range messages {
+		if m.ToolCallID != "" && !isMistralToolCallID(m.ToolCallID) {
+			needsRewrite = true
+			break
+		}
+		for _, tc := range m.ToolCalls {
+			if !isMistralToolCallID(tc.ID) {
+				needsRewrite = true
+				break
+			}
+		}
+	}
+	if !needsRewrite {
+		return messages
+	}
+
+	out := make([]Message, len(messages))
+	for i, m := range messages {
+		if m.ToolCallID != "" {
+			m.ToolCallID = mistralToolCallID(m.ToolCallID)
+		}
+		if len(m.ToolCalls) > 0 {
+			m.ToolCalls = slices.Clone(m.ToolCalls)
+			for j := range m.ToolCalls {
+				m.ToolCalls[j].ID = mistralToolCallID(m.ToolCalls[j].ID)
+			}
+		}
+		out[i] = m
+	}
+	return out
+}
+
+func isMistralToolCallID(id string) bool {
+	if len(id) != mistralToolCallIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if !strings.ContainsRune(mistralToolCallIDAlphabet, rune(id[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// mistralToolCallID maps an arbitrary tool call ID onto Mistral's ID format.
+// Already-valid IDs are returned unchanged.
+func mistralToolCallID(id string) string {
+	if isMistralToolCallID(id) {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	b := make([]byte, mistralToolCallIDLen)
+	for i := range b {
+		b[i] = mistralToolCallIDAlphabet[int(sum[i])%len(mistralToolCallIDAlphabet)]
+	}
+	return string(b)
+}
@@ -0,0 +1,106 @@
+package openai_compat
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIsMistralHost(t *testing.T) {
+	tests := []struct {
+		apiBase string
+		want    bool
+	}{
+		{"https://api.mistral.ai/v1", true},
+		{"https://codestral.mistral.ai/v1", true},
+		{"https://api.openai.com/v1", false},
+		{"https://openrouter.ai/api/v1", false},
+		{"://bad", false},
+	}
+	for _, tt := range tests {
+		if got := isMistralHost(tt.apiBase); got != tt.want {
+			t.Errorf("isMistralHost(%q) = %v, want %v", tt.apiBase, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeMistralToolCallIDs_RewritesForeignIDs(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "weather?"},
+		{
+			Role: "assistant",
+			ToolCalls: []ToolCall{
+				{ID: "call_abc123XYZ", Type: "function", Function: &FunctionCall{Name: "get_weather"}},
+				{ID: "toolu_01ABCDEF", Type: "function", Function: &FunctionCall{Name: "get_time"}},
+			},
+		},
+		{Role: "tool", Content: "sunny", ToolCallID: "call_abc123XYZ"},
+		{Role: "tool", Content: "noon", ToolCallID: "toolu_01ABCDEF"},
+	}
+
+	out := normalizeMistralToolCallIDs(messages)
+
+	first := out[1].ToolCalls[0].ID
+	second := out[1].ToolCalls[1].ID
+	for _, id := range []string{first, second} {
+		if !isMistralToolCallID(id) {
+			t.Fatalf("rewritten ID %q is not a valid Mistral tool call ID", id)
+		}
+	}
+	if first == second {
+		t.Fatalf("distinct IDs collapsed to %q", first)
+	}
+	if out[2].ToolCallID != first || out[3].ToolCallID != second {
+		t.Fatalf("tool results not paired with calls: got %q/%q, want %q/%q",
+			out[2].ToolCallID, out[3].ToolCallID, first, second)
+	}
+
+	// The caller's history must not be mutated.
+	if messages[1].ToolCalls[0].ID != "call_abc123XYZ" || messages[2].ToolCallID != "call_abc123XYZ" {
+		t.Fatal("normalizeMistralToolCallIDs mutated its input")
+	}
+}
+
+func TestNormalizeMistralToolCallIDs_KeepsValidIDs(t *testing.T) {
+	messages := []Message{
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "D681PevKs", Type: "function"}}},
+		{Role: "tool", Content: "ok", ToolCallID: "D681PevKs"},
+	}
+	out := normalizeMistralToolCallIDs(messages)
+	if out[0].ToolCalls[0].ID != "D681PevKs" || out[1].ToolCallID != "D681PevKs" {
+		t.Fatalf("valid Mistral IDs should pass through unchanged, got %+v", out)
+	}
+}
+
+func TestBuildRequestBody_MistralNormalizesToolCallIDs(t *testing.T) {
+	p := NewProvider("key", "https://api.mistral.ai/v1", "")
+	body := p.buildRequestBody(
+		[]Message{
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Type: "function"}}},
+			{Role: "tool", Content: "ok", ToolCallID: "call_1"},
+		},
+		nil,
+		"mistral/mistral-small-latest",
+		nil,
+	)
+
+	if body["model"] != "mistral-small-latest" {
+		t.Fatalf("model = %v, want mistral-small-latest", body["model"])
+	}
+	data, err := json.Marshal(body["messages"])
+	if err != nil {
+		t.Fatalf("marshal messages: %v", err)
+	}
+	var msgs []struct {
+		ToolCalls []struct {
+			ID string `json:"id"`
+		} `json:"tool_calls"`
+		ToolCallID string `json:"tool_call_id"`
+	}
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		t.Fatalf("unmarshal messages: %v", err)
+	}
+	want := mistralToolCallID("call_1")
+	if msgs[0].ToolCalls[0].ID != want || msgs[1].ToolCallID != want {
+		t.Fatalf("tool call IDs = %q/%q, want %q", msgs[0].ToolCalls[0].ID, msgs[1].ToolCallID, want)
+	}
+}
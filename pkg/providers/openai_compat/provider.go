@@ -7,10 +7,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -29,6 +29,7 @@ type (
 	ExtraContent           = protocoltypes.ExtraContent
 	GoogleExtra            = protocoltypes.GoogleExtra
 	ReasoningDetail        = protocoltypes.ReasoningDetail
+	ModelInfo              = protocoltypes.ModelInfo
 )
 
 type Provider struct {
@@ -111,6 +112,18 @@ func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
 	return p
 }
 
+// EnableWireLog logs request and response bodies of this provider with
+// credentials redacted. See common.EnableWireLog.
+func (p *Provider) EnableWireLog(maxBytes int) {
+	common.EnableWireLog(p.httpClient, p.apiBase, maxBytes)
+}
+
+// EnableClientCredentials authenticates with an OAuth client-credentials
+// grant instead of the API key. See common.EnableClientCredentials.
+func (p *Provider) EnableClientCredentials(creds common.ClientCredentials) {
+	common.EnableClientCredentials(p.httpClient, creds)
+}
+
 func NewProviderWithMaxTokensField(apiKey, apiBase, proxy, maxTokensField string) *Provider {
 	return NewProvider(apiKey, apiBase, proxy, WithMaxTokensField(maxTokensField))
 }
@@ -134,6 +147,10 @@ func (p *Provider) buildRequestBody(
 ) map[string]any {
 	model = normalizeModel(model, p.apiBase)
 
+	if isMistralHost(p.apiBase) {
+		messages = normalizeMistralToolCallIDs(messages)
+	}
+
 	requestBody := map[string]any{
 		"model":    model,
 		"messages": common.SerializeMessages(messages),
@@ -180,6 +197,11 @@ func (p *Provider) buildRequestBody(
 		}
 	}
 
+	// Reasoning models take a coarse effort rather than a token budget.
+	if effort := reasoningEffort(options); effort != "" && supportsReasoningEffort(p.apiBase) {
+		requestBody["reasoning_effort"] = effort
+	}
+
 	// Merge extra body fields configured per-provider/model.
 	// These are injected last so they take precedence over defaults.
 	maps.Copy(requestBody, p.extraBody)
@@ -235,12 +257,51 @@ func (p *Provider) Chat(
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, common.HandleErrorResponse(resp, p.apiBase)
+		return nil, common.HandleErrorResponse(resp, "openai", p.apiBase)
 	}
 
 	return common.ReadAndParseResponse(resp, p.apiBase)
 }
 
+// ListModels returns the models reported by the OpenAI-compatible /models
+// endpoint, sorted by ID.
+func (p *Provider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiBase+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if p.userAgent != "" {
+		req.Header.Set("User-Agent", p.userAgent)
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	p.applyCustomHeaders(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, common.HandleErrorResponse(resp, "openai", p.apiBase)
+	}
+
+	var out struct {
+		Data []ModelInfo `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode models response: %w", err)
+	}
+	slices.SortFunc(out.Data, func(a, b ModelInfo) int { return strings.Compare(a.ID, b.ID) })
+	return out.Data, nil
+}
+
 // ChatStream implements streaming via OpenAI-compatible SSE (stream: true).
 // onChunk receives the accumulated text so far on each text delta.
 func (p *Provider) ChatStream(
@@ -289,7 +350,7 @@ func (p *Provider) ChatStream(
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, common.HandleErrorResponse(resp, p.apiBase)
+		return nil, common.HandleErrorResponse(resp, "openai", p.apiBase)
 	}
 
 	return parseStreamResponse(ctx, resp.Body, onChunk)
@@ -302,16 +363,12 @@ func parseStreamResponse(
 	onChunk func(accumulated string),
 ) (*LLMResponse, error) {
 	var textContent strings.Builder
+	// Reasoning deltas are kept apart from answer text and never passed to
+	// onChunk, so streamed previews only show the answer.
+	var reasoningContent, reasoning strings.Builder
 	var finishReason string
 	var usage *UsageInfo
-
-	// Tool call assembly: OpenAI streams tool calls as incremental deltas
-	type toolAccum struct {
-		id       string
-		name     string
-		argsJSON strings.Builder
-	}
-	activeTools := map[int]*toolAccum{}
+	var tools toolCallAccumulator
 
 	scanner := bufio.NewScanner(reader)
 	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024) // 1MB initial, 10MB max
@@ -334,15 +391,10 @@ func parseStreamResponse(
 		var chunk struct {
 			Choices []struct {
 				Delta struct {
-					Content   string `json:"content"`
-					ToolCalls []struct {
-						Index    int    `json:"index"`
-						ID       string `json:"id"`
-						Function *struct {
-							Name      string `json:"name"`
-							Arguments string `json:"arguments"`
-						} `json:"function"`
-					} `json:"tool_calls"`
+					Content          string          `json:"content"`
+					ReasoningContent string          `json:"reasoning_content"`
+					Reasoning        string          `json:"reasoning"`
+					ToolCalls        []toolCallDelta `json:"tool_calls"`
 				} `json:"delta"`
 				FinishReason *string `json:"finish_reason"`
 			} `json:"choices"`
@@ -363,6 +415,9 @@ func parseStreamResponse(
 
 		choice := chunk.Choices[0]
 
+		reasoningContent.WriteString(choice.Delta.ReasoningContent)
+		reasoning.WriteString(choice.Delta.Reasoning)
+
 		// Accumulate text content
 		if choice.Delta.Content != "" {
 			textContent.WriteString(choice.Delta.Content)
@@ -371,24 +426,8 @@ func parseStreamResponse(
 			}
 		}
 
-		// Accumulate tool call deltas
 		for _, tc := range choice.Delta.ToolCalls {
-			acc, ok := activeTools[tc.Index]
-			if !ok {
-				acc = &toolAccum{}
-				activeTools[tc.Index] = acc
-			}
-			if tc.ID != "" {
-				acc.id = tc.ID
-			}
-			if tc.Function != nil {
-				if tc.Function.Name != "" {
-					acc.name = tc.Function.Name
-				}
-				if tc.Function.Arguments != "" {
-					acc.argsJSON.WriteString(tc.Function.Arguments)
-				}
-			}
+			tools.add(tc)
 		}
 
 		if choice.FinishReason != nil {
@@ -400,37 +439,17 @@ func parseStreamResponse(
 		return nil, fmt.Errorf("streaming read error: %w", err)
 	}
 
-	// Assemble tool calls from accumulated deltas
-	var toolCalls []ToolCall
-	for i := 0; i < len(activeTools); i++ {
-		acc, ok := activeTools[i]
-		if !ok {
-			continue
-		}
-		args := make(map[string]any)
-		raw := acc.argsJSON.String()
-		if raw != "" {
-			if err := json.Unmarshal([]byte(raw), &args); err != nil {
-				log.Printf("openai_compat stream: failed to decode tool call arguments for %q: %v", acc.name, err)
-				args["raw"] = raw
-			}
-		}
-		toolCalls = append(toolCalls, ToolCall{
-			ID:        acc.id,
-			Name:      acc.name,
-			Arguments: args,
-		})
-	}
-
 	if finishReason == "" {
 		finishReason = "stop"
 	}
 
 	return &LLMResponse{
-		Content:      textContent.String(),
-		ToolCalls:    toolCalls,
-		FinishReason: finishReason,
-		Usage:        usage,
+		Content:          textContent.String(),
+		ReasoningContent: reasoningContent.String(),
+		Reasoning:        reasoning.String(),
+		ToolCalls:        tools.toolCalls(),
+		FinishReason:     finishReason,
+		Usage:            usage,
 	}, nil
 }
 
@@ -479,6 +498,52 @@ func isNativeSearchHost(apiBase string) bool {
 	return host == "api.openai.com" || strings.HasSuffix(host, ".openai.azure.com")
 }
 
+// SupportsThinking implements providers.ThinkingCapable for hosts that accept
+// reasoning_effort.
+func (p *Provider) SupportsThinking() bool {
+	return supportsReasoningEffort(p.apiBase)
+}
+
+// reasoningEffort maps the thinking_budget or thinking_level option to a
+// reasoning_effort value. A budget picks the effort whose level budget covers
+// it; "adaptive" and "off" leave the choice to the model.
+func reasoningEffort(options map[string]any) string {
+	if budget, ok := common.AsInt(options["thinking_budget"]); ok && budget > 0 {
+		switch {
+		case budget <= common.ThinkingLevelBudget("low"):
+			return "low"
+		case budget <= common.ThinkingLevelBudget("medium"):
+			return "medium"
+		default:
+			return "high"
+		}
+	}
+	switch level, _ := options["thinking_level"].(string); level {
+	case "low", "medium", "high":
+		return level
+	case "xhigh":
+		return "high"
+	default:
+		return ""
+	}
+}
+
+// supportsReasoningEffort reports whether the API base is known to accept
+// the reasoning_effort request field. Hosts not listed here may reject it as
+// an unknown field.
+func supportsReasoningEffort(apiBase string) bool {
+	u, err := url.Parse(apiBase)
+	if err != nil {
+		return false
+	}
+	switch host := u.Hostname(); host {
+	case "api.openai.com", "generativelanguage.googleapis.com", "api.groq.com", "openrouter.ai", "api.x.ai":
+		return true
+	default:
+		return strings.HasSuffix(host, ".openai.azure.com")
+	}
+}
+
 // supportsPromptCacheKey reports whether the given API base is known to
 // support the prompt_cache_key request field. Currently only OpenAI's own
 // API and Azure OpenAI support this. All other OpenAI-compatible providers
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -1050,6 +1051,33 @@ func TestSupportsPromptCacheKey(t *testing.T) {
 	}
 }
 
+func TestBuildRequestBody_ReasoningEffort(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiBase string
+		options map[string]any
+		want    any
+	}{
+		{"level", "https://api.openai.com/v1", map[string]any{"thinking_level": "medium"}, "medium"},
+		{"xhigh capped", "https://api.openai.com/v1", map[string]any{"thinking_level": "xhigh"}, "high"},
+		{"budget", "https://api.openai.com/v1", map[string]any{"thinking_level": "high", "thinking_budget": 2000}, "low"},
+		{"adaptive", "https://api.openai.com/v1", map[string]any{"thinking_level": "adaptive"}, nil},
+		{"unknown host", "https://api.mistral.ai/v1", map[string]any{"thinking_level": "high"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider("key", tt.apiBase, "")
+			body := p.buildRequestBody([]Message{{Role: "user", Content: "hi"}}, nil, "o3", tt.options)
+			if got := body["reasoning_effort"]; got != tt.want {
+				t.Errorf("reasoning_effort = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if NewProvider("key", "https://api.mistral.ai/v1", "").SupportsThinking() {
+		t.Error("SupportsThinking() = true for a host without reasoning_effort")
+	}
+}
+
 func TestBuildToolsList_NativeSearchAddsWebSearchPreview(t *testing.T) {
 	tools := []ToolDefinition{
 		{Type: "function", Function: ToolFunctionDefinition{Name: "read_file", Description: "read"}},
@@ -1294,3 +1322,155 @@ func TestSerializeMessages_StripsSystemParts(t *testing.T) {
 		t.Fatal("system_parts should not appear in serialized output")
 	}
 }
+
+func TestParseStreamResponse_KeepsReasoningSeparateFromContent(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"choices":[{"delta":{"reasoning_content":"Let me "}}]}`,
+		`data: {"choices":[{"delta":{"reasoning_content":"think."}}]}`,
+		`data: {"choices":[{"delta":{"content":"Hello"}}]}`,
+		`data: {"choices":[{"delta":{"content":" world"},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	}, "\n")
+
+	var chunks []string
+	out, err := parseStreamResponse(t.Context(), strings.NewReader(stream), func(acc string) {
+		chunks = append(chunks, acc)
+	})
+	if err != nil {
+		t.Fatalf("parseStreamResponse() error = %v", err)
+	}
+	if out.Content != "Hello world" {
+		t.Fatalf("Content = %q, want %q", out.Content, "Hello world")
+	}
+	if out.ReasoningContent != "Let me think." {
+		t.Fatalf("ReasoningContent = %q, want %q", out.ReasoningContent, "Let me think.")
+	}
+	if len(chunks) != 2 || chunks[1] != "Hello world" {
+		t.Fatalf("onChunk received %q, want only answer text", chunks)
+	}
+}
+
+func TestParseStreamResponse_ReassemblesFragmentedToolCalls(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"choices":[{"delta":{"content":"Checking."}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"read_file","arguments":""}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"pa"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":1,"id":"call_b","function":{"name":"exec","arguments":"{\"command\":"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"th\":\"a.txt\"}"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"ls\"}"}}]}}]}`,
+		`data: {"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+		`data: [DONE]`,
+	}, "\n")
+
+	out, err := parseStreamResponse(t.Context(), strings.NewReader(stream), nil)
+	if err != nil {
+		t.Fatalf("parseStreamResponse() error = %v", err)
+	}
+	if out.Content != "Checking." || out.FinishReason != "tool_calls" {
+		t.Fatalf("Content = %q, FinishReason = %q", out.Content, out.FinishReason)
+	}
+	want := []ToolCall{
+		{ID: "call_a", Name: "read_file", Arguments: map[string]any{"path": "a.txt"}},
+		{ID: "call_b", Name: "exec", Arguments: map[string]any{"command": "ls"}},
+	}
+	if !reflect.DeepEqual(out.ToolCalls, want) {
+		t.Fatalf("ToolCalls = %#v, want %#v", out.ToolCalls, want)
+	}
+}
+
+func TestToolCallAccumulator_LenientIndexes(t *testing.T) {
+	delta := func(raw string) toolCallDelta {
+		t.Helper()
+		var d toolCallDelta
+		if err := json.Unmarshal([]byte(raw), &d); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name   string
+		deltas []string
+		want   []string
+	}{
+		{
+			name: "sparse indexes",
+			deltas: []string{
+				`{"index":1,"id":"a","function":{"name":"one","arguments":"{}"}}`,
+				`{"index":3,"id":"b","function":{"name":"two","arguments":"{}"}}`,
+			},
+			want: []string{"a:one", "b:two"},
+		},
+		{
+			name: "every call at index 0",
+			deltas: []string{
+				`{"index":0,"id":"a","function":{"name":"one","arguments":"{}"}}`,
+				`{"index":0,"id":"b","function":{"name":"two","arguments":"{}"}}`,
+			},
+			want: []string{"a:one", "b:two"},
+		},
+		{
+			name: "no index",
+			deltas: []string{
+				`{"id":"a","function":{"name":"one","arguments":"{\"x\":"}}`,
+				`{"function":{"arguments":"1}"}}`,
+				`{"id":"b","function":{"name":"two"}}`,
+			},
+			want: []string{"a:one", "b:two"},
+		},
+		{
+			name: "nameless call dropped",
+			deltas: []string{
+				`{"index":0,"id":"a","function":{"arguments":"{}"}}`,
+				`{"index":1,"id":"b","function":{"name":"two","arguments":"{}"}}`,
+			},
+			want: []string{"b:two"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acc toolCallAccumulator
+			for _, raw := range tt.deltas {
+				acc.add(delta(raw))
+			}
+			var got []string
+			for _, tc := range acc.toolCalls() {
+				got = append(got, tc.ID+":"+tc.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("calls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/models" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"gpt-5","owned_by":"openai"},{"id":"gpt-4o","created":1715367049}]}`)
+	}))
+	defer server.Close()
+
+	models, err := NewProvider("key", server.URL, "").ListModels(t.Context())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "gpt-4o" || models[1].ID != "gpt-5" {
+		t.Fatalf("models = %+v, want gpt-4o and gpt-5 sorted by ID", models)
+	}
+	if models[1].OwnedBy != "openai" || models[0].Created != 1715367049 {
+		t.Fatalf("models = %+v, metadata not decoded", models)
+	}
+
+	if _, err := NewProvider("wrong", server.URL, "").ListModels(t.Context()); err == nil {
+		t.Fatal("expected error for rejected credentials")
+	}
+}
@@ -0,0 +1,109 @@
+package openai_compat
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+)
+
+// toolCallDelta is one streamed fragment of a tool call, from
+// choices[].delta.tool_calls[].
+type toolCallDelta struct {
+	Index    *int   `json:"index"`
+	ID       string `json:"id"`
+	Function *struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+	ExtraContent *struct {
+		Google *struct {
+			ThoughtSignature string `json:"thought_signature"`
+		} `json:"google"`
+	} `json:"extra_content"`
+}
+
+// toolCallAccumulator reassembles streamed tool calls. OpenAI-style APIs send
+// a call's id and name in its first delta and its JSON arguments in pieces
+// over later ones, each tagged with the call's index in the response.
+//
+// Not every compatible server follows that exactly: some omit the index, and
+// some send every complete call with index 0. A delta that carries a new id
+// therefore always starts a new call, and one without an index continues the
+// latest call.
+type toolCallAccumulator struct {
+	calls   []*partialToolCall // in order of first appearance
+	byIndex map[int]*partialToolCall
+}
+
+type partialToolCall struct {
+	id               string
+	name             string
+	thoughtSignature string
+	args             strings.Builder
+}
+
+func (a *toolCallAccumulator) add(d toolCallDelta) {
+	call := a.callFor(d)
+	if d.ID != "" {
+		call.id = d.ID
+	}
+	if d.Function != nil {
+		if d.Function.Name != "" {
+			call.name = d.Function.Name
+		}
+		call.args.WriteString(d.Function.Arguments)
+	}
+	if d.ExtraContent != nil && d.ExtraContent.Google != nil && d.ExtraContent.Google.ThoughtSignature != "" {
+		call.thoughtSignature = d.ExtraContent.Google.ThoughtSignature
+	}
+}
+
+// callFor returns the call a delta belongs to, starting one if needed.
+func (a *toolCallAccumulator) callFor(d toolCallDelta) *partialToolCall {
+	var call *partialToolCall
+	if d.Index != nil {
+		call = a.byIndex[*d.Index]
+	} else if len(a.calls) > 0 {
+		call = a.calls[len(a.calls)-1]
+	}
+	if call != nil && (d.ID == "" || call.id == "" || call.id == d.ID) {
+		return call
+	}
+
+	call = &partialToolCall{}
+	a.calls = append(a.calls, call)
+	if d.Index != nil {
+		if a.byIndex == nil {
+			a.byIndex = make(map[int]*partialToolCall)
+		}
+		a.byIndex[*d.Index] = call
+	}
+	return call
+}
+
+// toolCalls returns the finished calls in the order they started. A call
+// that never received a name cannot be run and is dropped.
+func (a *toolCallAccumulator) toolCalls() []ToolCall {
+	var out []ToolCall
+	for _, call := range a.calls {
+		if call.name == "" {
+			log.Printf("openai_compat stream: dropping tool call %q without a name", call.id)
+			continue
+		}
+		tc := ToolCall{
+			ID:               call.id,
+			Name:             call.name,
+			Arguments:        common.DecodeToolCallArguments(json.RawMessage(call.args.String()), call.name),
+			ThoughtSignature: call.thoughtSignature,
+		}
+		if call.thoughtSignature != "" {
+			tc.ExtraContent = &ExtraContent{
+				Google: &GoogleExtra{ThoughtSignature: call.thoughtSignature},
+			}
+		}
+		out = append(out, tc)
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+package protocoltypes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
+)
+
+// ProviderError is returned by providers when an LLM API answers with an
+// error status. It keeps the provider's original error text while exposing
+// the HTTP status and the API's own error code and message, so callers can
+// tell rate limits from auth failures without parsing strings.
+type ProviderError struct {
+	Provider string // API protocol, e.g. "openai", "anthropic", "gemini"
+	Status   int    // HTTP status, 0 when unknown
+	Code     string // API error code or type, e.g. "rate_limit_exceeded"
+	Message  string // API error message
+	Err      error  // provider's original error
+}
+
+// NewProviderError builds a ProviderError for an error response, extracting
+// the code and message from the common JSON error body shapes (OpenAI,
+// Anthropic, Gemini). err carries the provider's usual error text.
+func NewProviderError(provider string, status int, body []byte, err error) *ProviderError {
+	code, message := parseAPIError(body)
+	return &ProviderError{
+		Provider: provider,
+		Status:   status,
+		Code:     code,
+		Message:  message,
+		Err:      err,
+	}
+}
+
+func (e *ProviderError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	msg := fmt.Sprintf("%s API error (HTTP %d)", e.Provider, e.Status)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the same request may succeed if sent again
+// later: rate limits, timeouts, and server-side failures.
+func (e *ProviderError) Retryable() bool {
+	switch {
+	case e.Status == 408, e.Status == 409, e.Status == 425, e.Status == 429:
+		return true
+	case e.Status >= 500:
+		return e.Status != 501
+	}
+	return false
+}
+
+// UserMessage is a short explanation of the failure suitable for showing
+// to a chat user, without provider internals. It is in English; callers
+// that know the user's locale look up i18n.ProviderError(e.Category()).
+func (e *ProviderError) UserMessage() string {
+	return i18n.Text(i18n.English, i18n.ProviderError(e.Category()))
+}
+
+// Category names the kind of failure for reply templates and logs:
+// rate_limit, auth, quota, model, timeout, overloaded, server or request.
+func (e *ProviderError) Category() string {
+	switch {
+	case e.Status == 429:
+		return "rate_limit"
+	case e.Status == 401 || e.Status == 403:
+		return "auth"
+	case e.Status == 402:
+		return "quota"
+	case e.Status == 404:
+		return "model"
+	case e.Status == 408 || e.Status == 504:
+		return "timeout"
+	case e.Status == 529 || e.Status == 503:
+		return "overloaded"
+	case e.Status >= 500:
+		return "server"
+	}
+	return "request"
+}
+
+// AsProviderError returns the ProviderError in err's chain, if any.
+func AsProviderError(err error) (*ProviderError, bool) {
+	var pe *ProviderError
+	if errors.As(err, &pe) {
+		return pe, true
+	}
+	return nil, false
+}
+
+// parseAPIError reads an error code and message from the usual shapes:
+//
+//	{"error": {"message": "...", "type": "...", "code": "..."}}  OpenAI
+//	{"type": "error", "error": {"type": "...", "message": "..."}} Anthropic
+//	{"error": {"code": 429, "message": "...", "status": "..."}}   Gemini
+//	{"error": "...", "message": "..."}                            others
+func parseAPIError(body []byte) (code, message string) {
+	body = []byte(strings.TrimSpace(string(body)))
+	if len(body) > 0 && body[0] == '[' {
+		// Gemini sometimes wraps the payload in a one-element array.
+		var list []json.RawMessage
+		if json.Unmarshal(body, &list) != nil || len(list) == 0 {
+			return "", ""
+		}
+		body = list[0]
+	}
+	var raw struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+		Code    json.RawMessage `json:"code"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &raw) != nil {
+		return "", ""
+	}
+	var inner struct {
+		Message string          `json:"message"`
+		Type    string          `json:"type"`
+		Code    json.RawMessage `json:"code"`
+		Status  string          `json:"status"`
+	}
+	var errString string
+	if json.Unmarshal(raw.Error, &inner) != nil {
+		_ = json.Unmarshal(raw.Error, &errString)
+	}
+
+	message = firstNonEmpty(inner.Message, raw.Message, errString)
+	// A string code is the most specific; Gemini's numeric code only repeats
+	// the HTTP status, so its status name comes first.
+	code = firstNonEmpty(
+		jsonString(inner.Code), inner.Status, inner.Type,
+		jsonString(raw.Code), jsonNumber(inner.Code), jsonNumber(raw.Code),
+	)
+	return code, message
+}
+
+func jsonString(raw json.RawMessage) string {
+	var s string
+	if len(raw) == 0 || json.Unmarshal(raw, &s) != nil {
+		return ""
+	}
+	return s
+}
+
+func jsonNumber(raw json.RawMessage) string {
+	var n json.Number
+	if len(raw) == 0 || json.Unmarshal(raw, &n) != nil {
+		return ""
+	}
+	return n.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package protocoltypes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestProviderError_StatusMapping(t *testing.T) {
+	tests := []struct {
+		status    int
+		retryable bool
+		userMsg   string
+	}{
+		{400, false, "rejected the request"},
+		{401, false, "credentials"},
+		{402, false, "credits"},
+		{403, false, "credentials"},
+		{404, false, "model"},
+		{408, true, "timed out"},
+		{422, false, "rejected the request"},
+		{429, true, "rate limiting"},
+		{500, true, "internal error"},
+		{501, false, "internal error"},
+		{502, true, "internal error"},
+		{503, true, "overloaded"},
+		{504, true, "timed out"},
+		{529, true, "overloaded"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.status), func(t *testing.T) {
+			pe := &ProviderError{Provider: "openai", Status: tt.status}
+			if got := pe.Retryable(); got != tt.retryable {
+				t.Errorf("Retryable() = %v, want %v", got, tt.retryable)
+			}
+			if got := pe.UserMessage(); !strings.Contains(got, tt.userMsg) {
+				t.Errorf("UserMessage() = %q, want it to mention %q", got, tt.userMsg)
+			}
+		})
+	}
+}
+
+func TestNewProviderError_ParsesBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			name:        "openai",
+			body:        `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`,
+			wantCode:    "rate_limit_exceeded",
+			wantMessage: "Rate limit reached",
+		},
+		{
+			name:        "openai without code",
+			body:        `{"error":{"message":"bad model","type":"invalid_request_error","code":null}}`,
+			wantCode:    "invalid_request_error",
+			wantMessage: "bad model",
+		},
+		{
+			name:        "anthropic",
+			body:        `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			wantCode:    "overloaded_error",
+			wantMessage: "Overloaded",
+		},
+		{
+			name:        "gemini",
+			body:        `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`,
+			wantCode:    "RESOURCE_EXHAUSTED",
+			wantMessage: "Quota exceeded",
+		},
+		{
+			name:        "gemini array",
+			body:        `[{"error":{"code":404,"message":"model not found","status":"NOT_FOUND"}}]`,
+			wantCode:    "NOT_FOUND",
+			wantMessage: "model not found",
+		},
+		{
+			name:        "plain string",
+			body:        `{"error":"invalid api key"}`,
+			wantMessage: "invalid api key",
+		},
+		{name: "not json", body: `upstream connect error`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pe := NewProviderError("test", 400, []byte(tt.body), nil)
+			if pe.Code != tt.wantCode || pe.Message != tt.wantMessage {
+				t.Fatalf("Code, Message = %q, %q; want %q, %q", pe.Code, pe.Message, tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestProviderError_WrapsOriginal(t *testing.T) {
+	orig := errors.New("API request failed: status 429")
+	err := fmt.Errorf("LLM call failed: %w", NewProviderError("openai", 429, nil, orig))
+
+	if err.Error() != "LLM call failed: API request failed: status 429" {
+		t.Fatalf("Error() = %q, want the original text", err.Error())
+	}
+	if !errors.Is(err, orig) {
+		t.Fatal("errors.Is does not reach the original error")
+	}
+	pe, ok := AsProviderError(err)
+	if !ok || pe.Status != 429 {
+		t.Fatalf("AsProviderError() = %+v, %v", pe, ok)
+	}
+
+	bare := &ProviderError{Provider: "anthropic", Status: 529, Code: "overloaded_error", Message: "Overloaded"}
+	if want := "anthropic API error (HTTP 529) overloaded_error: Overloaded"; bare.Error() != want {
+		t.Fatalf("Error() = %q, want %q", bare.Error(), want)
+	}
+}
@@ -0,0 +1,349 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	defaultRouterCacheTTL   = 5 * time.Minute
+	maxRouterCacheEntries   = 256
+	defaultRouterPrompt     = "Classify the user's request into exactly one category."
+	routerClassifierTimeout = 15 * time.Second
+)
+
+// RouterRoute is the provider and model a label is forwarded to.
+type RouterRoute struct {
+	Provider LLMProvider
+	Model    string
+}
+
+// RouterOptions configures a RouterProvider.
+type RouterOptions struct {
+	Classifier      LLMProvider
+	ClassifierModel string
+	Prompt          string
+	Routes          map[string]RouterRoute
+	DefaultRoute    string
+	// CacheTTL is how long a label is reused for identical input. Zero uses
+	// the default; a negative value disables the cache.
+	CacheTTL time.Duration
+}
+
+type routerCacheEntry struct {
+	label   string
+	expires time.Time
+}
+
+// RouterProvider asks a (cheap) classifier model to label each request and
+// forwards the request to the provider and model mapped to that label. The
+// label is derived from the latest user message, so every LLM call within
+// one turn goes to the same model, and the cache means only the first call
+// pays for classification.
+type RouterProvider struct {
+	classifier      LLMProvider
+	classifierModel string
+	prompt          string
+	labels          []string
+	routes          map[string]RouterRoute
+	defaultRoute    string
+	cacheTTL        time.Duration
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]routerCacheEntry
+	now   func() time.Time
+}
+
+func NewRouterProvider(opts RouterOptions) (*RouterProvider, error) {
+	if opts.Classifier == nil {
+		return nil, fmt.Errorf("router: classifier provider is required")
+	}
+	if _, ok := opts.Routes[opts.DefaultRoute]; !ok {
+		return nil, fmt.Errorf("router: default route %q is not one of the routes", opts.DefaultRoute)
+	}
+	labels := make([]string, 0, len(opts.Routes))
+	for label, route := range opts.Routes {
+		if route.Provider == nil {
+			return nil, fmt.Errorf("router: route %q has no provider", label)
+		}
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	prompt := strings.TrimSpace(opts.Prompt)
+	if prompt == "" {
+		prompt = defaultRouterPrompt
+	}
+	ttl := opts.CacheTTL
+	if ttl == 0 {
+		ttl = defaultRouterCacheTTL
+	}
+	return &RouterProvider{
+		classifier:      opts.Classifier,
+		classifierModel: opts.ClassifierModel,
+		prompt:          prompt,
+		labels:          labels,
+		routes:          opts.Routes,
+		defaultRoute:    opts.DefaultRoute,
+		cacheTTL:        ttl,
+		cache:           make(map[[sha256.Size]byte]routerCacheEntry),
+		now:             time.Now,
+	}, nil
+}
+
+// Chat classifies the request and forwards it to the mapped model. The model
+// argument names the router itself and is ignored. Tokens spent on
+// classification are added to the returned usage.
+func (r *RouterProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	route, usage := r.route(ctx, messages)
+	resp, err := route.Provider.Chat(ctx, messages, tools, route.Model, options)
+	return addRouterUsage(resp, usage), err
+}
+
+// ChatStream is Chat for routes whose provider can stream. Other routes
+// answer in one piece.
+func (r *RouterProvider) ChatStream(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onChunk func(accumulated string),
+) (*LLMResponse, error) {
+	route, usage := r.route(ctx, messages)
+	sp, ok := route.Provider.(StreamingProvider)
+	if !ok {
+		resp, err := route.Provider.Chat(ctx, messages, tools, route.Model, options)
+		return addRouterUsage(resp, usage), err
+	}
+	resp, err := sp.ChatStream(ctx, messages, tools, route.Model, options, onChunk)
+	return addRouterUsage(resp, usage), err
+}
+
+func (r *RouterProvider) GetDefaultModel() string {
+	return r.routes[r.defaultRoute].Model
+}
+
+// Close releases stateful providers behind the router.
+func (r *RouterProvider) Close() {
+	closed := make(map[LLMProvider]bool)
+	closeOnce := func(p LLMProvider) {
+		if sp, ok := p.(StatefulProvider); ok && !closed[p] {
+			closed[p] = true
+			sp.Close()
+		}
+	}
+	closeOnce(r.classifier)
+	for _, label := range r.labels {
+		closeOnce(r.routes[label].Provider)
+	}
+}
+
+func (r *RouterProvider) route(ctx context.Context, messages []Message) (RouterRoute, *UsageInfo) {
+	label, usage := r.classify(ctx, lastUserContent(messages))
+	logger.DebugCF("provider", "Router selected route", map[string]any{
+		"label": label,
+		"model": r.routes[label].Model,
+	})
+	return r.routes[label], usage
+}
+
+// classify returns the label for input, from the cache when possible. It
+// falls back to the default route when there is nothing to classify or the
+// classifier fails; failures are not cached.
+func (r *RouterProvider) classify(ctx context.Context, input string) (string, *UsageInfo) {
+	if strings.TrimSpace(input) == "" {
+		return r.defaultRoute, nil
+	}
+	key := sha256.Sum256([]byte(input))
+	if label, ok := r.cached(key); ok {
+		return label, nil
+	}
+
+	classifyCtx, cancel := context.WithTimeout(ctx, routerClassifierTimeout)
+	defer cancel()
+	resp, err := r.classifier.Chat(classifyCtx, []Message{
+		{Role: "system", Content: r.systemPrompt()},
+		{Role: "user", Content: input},
+	}, nil, r.classifierModel, map[string]any{"max_tokens": 16, "temperature": 0.0})
+	if err != nil || resp == nil {
+		fields := map[string]any{"default_route": r.defaultRoute}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		logger.WarnCF("provider", "Router classification failed; using default route", fields)
+		return r.defaultRoute, nil
+	}
+
+	label, ok := r.matchLabel(resp.Content)
+	if !ok {
+		logger.DebugCF("provider", "Router classifier answered off-list; using default route",
+			map[string]any{"answer": resp.Content, "default_route": r.defaultRoute})
+		label = r.defaultRoute
+	}
+	r.store(key, label)
+	return label, resp.Usage
+}
+
+func (r *RouterProvider) systemPrompt() string {
+	return fmt.Sprintf("%s\n\nCategories: %s\n\nAnswer with the category name only.",
+		r.prompt, strings.Join(r.labels, ", "))
+}
+
+// matchLabel finds the label in a classifier answer: an exact match first,
+// then the label mentioned earliest in the answer.
+func (r *RouterProvider) matchLabel(answer string) (string, bool) {
+	answer = strings.ToLower(strings.Trim(strings.TrimSpace(answer), "\"'`.*"))
+	best, bestAt := "", -1
+	for _, label := range r.labels {
+		lower := strings.ToLower(label)
+		if answer == lower {
+			return label, true
+		}
+		if at := strings.Index(answer, lower); at >= 0 && (bestAt < 0 || at < bestAt) {
+			best, bestAt = label, at
+		}
+	}
+	return best, bestAt >= 0
+}
+
+func (r *RouterProvider) cached(key [sha256.Size]byte) (string, bool) {
+	if r.cacheTTL < 0 {
+		return "", false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.cache[key]
+	if !ok || r.now().After(entry.expires) {
+		return "", false
+	}
+	return entry.label, true
+}
+
+func (r *RouterProvider) store(key [sha256.Size]byte, label string) {
+	if r.cacheTTL < 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if len(r.cache) >= maxRouterCacheEntries {
+		for k, entry := range r.cache {
+			if now.After(entry.expires) {
+				delete(r.cache, k)
+			}
+		}
+		// Still full: drop an arbitrary entry rather than grow without bound.
+		for k := range r.cache {
+			if len(r.cache) < maxRouterCacheEntries {
+				break
+			}
+			delete(r.cache, k)
+		}
+	}
+	r.cache[key] = routerCacheEntry{label: label, expires: now.Add(r.cacheTTL)}
+}
+
+func lastUserContent(messages []Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+func addRouterUsage(resp *LLMResponse, usage *UsageInfo) *LLMResponse {
+	if resp == nil || usage == nil {
+		return resp
+	}
+	if resp.Usage == nil {
+		resp.Usage = &UsageInfo{}
+	}
+	resp.Usage.PromptTokens += usage.PromptTokens
+	resp.Usage.CompletionTokens += usage.CompletionTokens
+	resp.Usage.TotalTokens += usage.TotalTokens
+	resp.Usage.CacheReadTokens += usage.CacheReadTokens
+	resp.Usage.CacheWriteTokens += usage.CacheWriteTokens
+	return resp
+}
+
+// newRouterProviderFromConfig builds a RouterProvider for a "router/..."
+// model_list entry, creating the classifier and route providers from the
+// model_list entries they name.
+func newRouterProviderFromConfig(cfg *config.Config, modelCfg *config.ModelConfig) (LLMProvider, string, error) {
+	rc := modelCfg.Router
+	if rc == nil {
+		return nil, "", fmt.Errorf("router model %q requires a router section", modelCfg.ModelName)
+	}
+	if err := rc.Validate(); err != nil {
+		return nil, "", fmt.Errorf("router model %q: %w", modelCfg.ModelName, err)
+	}
+
+	created := make(map[string]RouterRoute)
+	resolve := func(name string) (RouterRoute, error) {
+		if route, ok := created[name]; ok {
+			return route, nil
+		}
+		mc, err := cfg.GetModelConfig(name)
+		if err != nil {
+			return RouterRoute{}, err
+		}
+		sub := *mc
+		if sub.Workspace == "" {
+			sub.Workspace = cfg.WorkspacePath()
+		}
+		provider, modelID, err := CreateProviderFromConfig(&sub)
+		if err != nil {
+			return RouterRoute{}, fmt.Errorf("model %q: %w", name, err)
+		}
+		route := RouterRoute{Provider: provider, Model: modelID}
+		created[name] = route
+		return route, nil
+	}
+
+	classifier, err := resolve(rc.ClassifierModel)
+	if err != nil {
+		return nil, "", fmt.Errorf("router classifier: %w", err)
+	}
+	routes := make(map[string]RouterRoute, len(rc.Routes))
+	for label, name := range rc.Routes {
+		route, err := resolve(name)
+		if err != nil {
+			return nil, "", fmt.Errorf("router route %q: %w", label, err)
+		}
+		routes[label] = route
+	}
+
+	ttl := time.Duration(rc.CacheTTLSeconds) * time.Second
+	if rc.CacheTTLSeconds < 0 {
+		ttl = -1
+	}
+	router, err := NewRouterProvider(RouterOptions{
+		Classifier:      classifier.Provider,
+		ClassifierModel: classifier.Model,
+		Prompt:          rc.Prompt,
+		Routes:          routes,
+		DefaultRoute:    rc.DefaultRoute,
+		CacheTTL:        ttl,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	_, modelID := ExtractProtocol(modelCfg.Model)
+	return router, modelID, nil
+}
@@ -0,0 +1,194 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+type routerStubProvider struct {
+	mu      sync.Mutex
+	content string
+	err     error
+	usage   *UsageInfo
+	models  []string
+	prompts []string
+}
+
+func (p *routerStubProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.models = append(p.models, model)
+	if len(messages) > 0 {
+		p.prompts = append(p.prompts, messages[0].Content)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	var usage *UsageInfo
+	if p.usage != nil {
+		u := *p.usage
+		usage = &u
+	}
+	return &LLMResponse{Content: p.content, Usage: usage}, nil
+}
+
+func (p *routerStubProvider) GetDefaultModel() string { return "stub" }
+
+func (p *routerStubProvider) calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.models)
+}
+
+func newTestRouter(t *testing.T, classifier *routerStubProvider, ttl time.Duration) (*RouterProvider, *routerStubProvider, *routerStubProvider) {
+	t.Helper()
+	cheap := &routerStubProvider{content: "cheap answer"}
+	strong := &routerStubProvider{content: "strong answer", usage: &UsageInfo{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120}}
+	router, err := NewRouterProvider(RouterOptions{
+		Classifier:      classifier,
+		ClassifierModel: "tiny",
+		Prompt:          "Pick a category.",
+		Routes: map[string]RouterRoute{
+			"chat":   {Provider: cheap, Model: "small"},
+			"coding": {Provider: strong, Model: "large"},
+		},
+		DefaultRoute: "chat",
+		CacheTTL:     ttl,
+	})
+	if err != nil {
+		t.Fatalf("NewRouterProvider() error = %v", err)
+	}
+	return router, cheap, strong
+}
+
+func TestRouterProvider_RoutesByClassifierLabel(t *testing.T) {
+	classifier := &routerStubProvider{content: "Coding.", usage: &UsageInfo{PromptTokens: 10, CompletionTokens: 1, TotalTokens: 11}}
+	router, cheap, strong := newTestRouter(t, classifier, 0)
+
+	resp, err := router.Chat(context.Background(), []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "Fix this Go build error"},
+	}, nil, "auto", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "strong answer" || strong.models[0] != "large" || cheap.calls() != 0 {
+		t.Fatalf("resp = %q, strong models = %v, cheap calls = %d", resp.Content, strong.models, cheap.calls())
+	}
+	if resp.Usage.TotalTokens != 131 || resp.Usage.PromptTokens != 110 {
+		t.Fatalf("usage = %+v, want classifier tokens added", resp.Usage)
+	}
+	if classifier.models[0] != "tiny" {
+		t.Fatalf("classifier model = %q, want tiny", classifier.models[0])
+	}
+	want := "Pick a category.\n\nCategories: chat, coding\n\nAnswer with the category name only."
+	if classifier.prompts[0] != want {
+		t.Fatalf("classifier prompt = %q, want %q", classifier.prompts[0], want)
+	}
+}
+
+func TestRouterProvider_CachesIdenticalInput(t *testing.T) {
+	classifier := &routerStubProvider{content: "coding"}
+	router, _, strong := newTestRouter(t, classifier, 0)
+	now := time.Now()
+	router.now = func() time.Time { return now }
+
+	msgs := []Message{{Role: "user", Content: "refactor main.go"}}
+	for range 3 {
+		if _, err := router.Chat(context.Background(), msgs, nil, "auto", nil); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	// A tool result after the user message still routes by the user message.
+	msgs = append(msgs, Message{Role: "tool", Content: "file contents"})
+	if _, err := router.Chat(context.Background(), msgs, nil, "auto", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if classifier.calls() != 1 || strong.calls() != 4 {
+		t.Fatalf("classifier calls = %d, strong calls = %d; want 1 and 4", classifier.calls(), strong.calls())
+	}
+
+	now = now.Add(defaultRouterCacheTTL + time.Second)
+	if _, err := router.Chat(context.Background(), msgs, nil, "auto", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if classifier.calls() != 2 {
+		t.Fatalf("classifier calls after expiry = %d, want 2", classifier.calls())
+	}
+}
+
+func TestRouterProvider_FallsBackToDefaultRoute(t *testing.T) {
+	tests := []struct {
+		name       string
+		classifier *routerStubProvider
+	}{
+		{name: "classifier error", classifier: &routerStubProvider{err: errors.New("boom")}},
+		{name: "off-list answer", classifier: &routerStubProvider{content: "poetry"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, cheap, _ := newTestRouter(t, tt.classifier, -1)
+			resp, err := router.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "auto", nil)
+			if err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			if resp.Content != "cheap answer" || cheap.models[0] != "small" {
+				t.Fatalf("resp = %q, cheap models = %v; want default route", resp.Content, cheap.models)
+			}
+		})
+	}
+}
+
+func TestCreateProvider_BuildsRouterFromModelList(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.ModelName = "auto"
+	small := &config.ModelConfig{ModelName: "small", Model: "openai/gpt-small", APIBase: "http://localhost:1"}
+	small.SetAPIKey("sk-small")
+	large := &config.ModelConfig{ModelName: "large", Model: "openai/gpt-large", APIBase: "http://localhost:1"}
+	large.SetAPIKey("sk-large")
+	cfg.ModelList = []*config.ModelConfig{
+		{
+			ModelName: "auto",
+			Model:     "router/auto",
+			Router: &config.ModelRouterConfig{
+				ClassifierModel: "small",
+				Routes:          map[string]string{"chat": "small", "coding": "large"},
+				DefaultRoute:    "chat",
+			},
+		},
+		small,
+		large,
+	}
+
+	provider, modelID, err := CreateProvider(cfg)
+	if err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+	router, ok := provider.(*RouterProvider)
+	if !ok {
+		t.Fatalf("provider type = %T, want *RouterProvider", provider)
+	}
+	if modelID != "auto" || router.routes["coding"].Model != "gpt-large" || router.GetDefaultModel() != "gpt-small" {
+		t.Fatalf("modelID = %q, routes = %+v", modelID, router.routes)
+	}
+
+	cfg.ModelList[0].Router.Routes["coding"] = "missing"
+	if _, _, err := CreateProvider(cfg); err == nil {
+		t.Fatal("CreateProvider() with unknown route model = nil error")
+	}
+
+	if _, _, err := CreateProviderFromConfig(cfg.ModelList[0]); err == nil {
+		t.Fatal("CreateProviderFromConfig(router) = nil error, want it to require CreateProvider")
+	}
+}
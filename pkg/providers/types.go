@@ -19,8 +19,13 @@ type (
 	GoogleExtra            = protocoltypes.GoogleExtra
 	ContentBlock           = protocoltypes.ContentBlock
 	CacheControl           = protocoltypes.CacheControl
+	ModelInfo              = protocoltypes.ModelInfo
+	ProviderError          = protocoltypes.ProviderError
 )
 
+// AsProviderError returns the *ProviderError in err's chain, if any.
+var AsProviderError = protocoltypes.AsProviderError
+
 type LLMProvider interface {
 	Chat(
 		ctx context.Context,
@@ -51,6 +56,13 @@ type StreamingProvider interface {
 	) (*LLMResponse, error)
 }
 
+// ModelLister is an optional interface for providers that can report which
+// model IDs they accept, e.g. from an OpenAI-compatible /models endpoint.
+// Providers without such an endpoint may return a curated static list.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+}
+
 // ThinkingCapable is an optional interface for providers that support
 // extended thinking (e.g. Anthropic). Used by the agent loop to warn
 // when thinking_level is configured but the active provider cannot use it.
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/memory"
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -118,6 +119,35 @@ func (b *JSONLBackend) GetSessionScope(sessionKey string) *SessionScope {
 	return CloneScope(&scope)
 }
 
+// LastActivity returns the metadata update time for a session key or alias.
+func (b *JSONLBackend) LastActivity(sessionKey string) time.Time {
+	metaStore, ok := b.store.(metaAwareStore)
+	if !ok {
+		return time.Time{}
+	}
+	meta, err := metaStore.GetSessionMeta(context.Background(), b.resolveSessionKey(sessionKey))
+	if err != nil {
+		log.Printf("session: get session metadata: %v", err)
+		return time.Time{}
+	}
+	return meta.UpdatedAt
+}
+
+// SessionStats returns the message count and last update recorded in a
+// session's metadata, without reading its history.
+func (b *JSONLBackend) SessionStats(sessionKey string) (int, time.Time) {
+	metaStore, ok := b.store.(metaAwareStore)
+	if !ok {
+		return len(b.GetHistory(sessionKey)), time.Time{}
+	}
+	meta, err := metaStore.GetSessionMeta(context.Background(), b.resolveSessionKey(sessionKey))
+	if err != nil {
+		log.Printf("session: get session metadata: %v", err)
+		return 0, time.Time{}
+	}
+	return max(meta.Count-meta.Skip, 0), meta.UpdatedAt
+}
+
 func (b *JSONLBackend) AddMessage(sessionKey, role, content string) {
 	sessionKey = b.resolveSessionKey(sessionKey)
 	if err := b.store.AddMessage(context.Background(), sessionKey, role, content); err != nil {
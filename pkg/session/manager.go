@@ -21,13 +21,17 @@ type Session struct {
 
 type SessionManager struct {
 	sessions map[string]*Session
-	mu       sync.RWMutex
-	storage  string
+	// evicted holds keys flushed to disk and dropped from memory by
+	// EvictIdle; they are reloaded on next access.
+	evicted map[string]struct{}
+	mu      sync.RWMutex
+	storage string
 }
 
 func NewSessionManager(storage string) *SessionManager {
 	sm := &SessionManager{
 		sessions: make(map[string]*Session),
+		evicted:  make(map[string]struct{}),
 		storage:  storage,
 	}
 
@@ -43,7 +47,7 @@ func (sm *SessionManager) GetOrCreate(key string) *Session {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	session, ok := sm.sessions[key]
+	session, ok := sm.lookupLocked(key)
 	if ok {
 		return session
 	}
@@ -72,7 +76,7 @@ func (sm *SessionManager) AddFullMessage(sessionKey string, msg providers.Messag
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	session, ok := sm.sessions[sessionKey]
+	session, ok := sm.lookupLocked(sessionKey)
 	if !ok {
 		session = &Session{
 			Key:      sessionKey,
@@ -87,6 +91,7 @@ func (sm *SessionManager) AddFullMessage(sessionKey string, msg providers.Messag
 }
 
 func (sm *SessionManager) GetHistory(key string) []providers.Message {
+	sm.restore(key)
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
@@ -101,6 +106,7 @@ func (sm *SessionManager) GetHistory(key string) []providers.Message {
 }
 
 func (sm *SessionManager) GetSummary(key string) string {
+	sm.restore(key)
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
@@ -111,11 +117,50 @@ func (sm *SessionManager) GetSummary(key string) string {
 	return session.Summary
 }
 
+// LastActivity returns when the session was last updated.
+func (sm *SessionManager) LastActivity(key string) time.Time {
+	sm.restore(key)
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return time.Time{}
+	}
+	return session.Updated
+}
+
+// SessionStats returns the message count and last update of a session. An
+// evicted session is read from disk but stays evicted.
+func (sm *SessionManager) SessionStats(key string) (int, time.Time) {
+	sm.mu.RLock()
+	session, ok := sm.sessions[key]
+	if ok {
+		defer sm.mu.RUnlock()
+		return len(session.Messages), session.Updated
+	}
+	_, evicted := sm.evicted[key]
+	sm.mu.RUnlock()
+	if !evicted {
+		return 0, time.Time{}
+	}
+
+	data, err := os.ReadFile(filepath.Join(sm.storage, sanitizeFilename(key)+".json"))
+	if err != nil {
+		return 0, time.Time{}
+	}
+	var stored Session
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return 0, time.Time{}
+	}
+	return len(stored.Messages), stored.Updated
+}
+
 func (sm *SessionManager) SetSummary(key string, summary string) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	session, ok := sm.sessions[key]
+	session, ok := sm.lookupLocked(key)
 	if ok {
 		session.Summary = summary
 		session.Updated = time.Now()
@@ -126,7 +171,7 @@ func (sm *SessionManager) TruncateHistory(key string, keepLast int) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	session, ok := sm.sessions[key]
+	session, ok := sm.lookupLocked(key)
 	if !ok {
 		return
 	}
@@ -148,10 +193,13 @@ func (sm *SessionManager) TruncateHistory(key string, keepLast int) {
 func (sm *SessionManager) ListSessions() []string {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
-	keys := make([]string, 0, len(sm.sessions))
+	keys := make([]string, 0, len(sm.sessions)+len(sm.evicted))
 	for k := range sm.sessions {
 		keys = append(keys, k)
 	}
+	for k := range sm.evicted {
+		keys = append(keys, k)
+	}
 	return keys
 }
 
@@ -277,6 +325,92 @@ func (sm *SessionManager) loadSessions() error {
 	return nil
 }
 
+// lookupLocked returns the in-memory session for key, reloading it from disk
+// first if EvictIdle dropped it. The caller must hold sm.mu for writing.
+func (sm *SessionManager) lookupLocked(key string) (*Session, bool) {
+	if session, ok := sm.sessions[key]; ok {
+		return session, true
+	}
+	if _, ok := sm.evicted[key]; !ok {
+		return nil, false
+	}
+	delete(sm.evicted, key)
+
+	data, err := os.ReadFile(filepath.Join(sm.storage, sanitizeFilename(key)+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, false
+	}
+	sm.sessions[key] = &session
+	return &session, true
+}
+
+// restore reloads an evicted session ahead of a read-locked access.
+func (sm *SessionManager) restore(key string) {
+	sm.mu.RLock()
+	_, evicted := sm.evicted[key]
+	sm.mu.RUnlock()
+	if !evicted {
+		return
+	}
+	sm.mu.Lock()
+	sm.lookupLocked(key)
+	sm.mu.Unlock()
+}
+
+// EvictIdle drops sessions not updated within idle from memory, skipping
+// keys for which keep returns true. With disk storage each session is saved
+// first and transparently reloaded on next access; without storage its
+// history is discarded. A session modified while being flushed is kept.
+// It returns the number of sessions evicted.
+func (sm *SessionManager) EvictIdle(idle time.Duration, keep func(sessionKey string) bool) int {
+	type candidate struct {
+		key     string
+		session *Session
+		updated time.Time
+	}
+	cutoff := time.Now().Add(-idle)
+
+	sm.mu.RLock()
+	var candidates []candidate
+	for key, session := range sm.sessions {
+		if session.Updated.Before(cutoff) && (keep == nil || !keep(key)) {
+			candidates = append(candidates, candidate{key: key, session: session, updated: session.Updated})
+		}
+	}
+	sm.mu.RUnlock()
+
+	evicted := 0
+	for _, c := range candidates {
+		key := c.key
+		if sm.storage != "" {
+			if err := sm.Save(key); err != nil {
+				continue
+			}
+		}
+		sm.mu.Lock()
+		if current, ok := sm.sessions[key]; ok && current == c.session && current.Updated.Equal(c.updated) {
+			delete(sm.sessions, key)
+			if sm.storage != "" {
+				sm.evicted[key] = struct{}{}
+			}
+			evicted++
+		}
+		sm.mu.Unlock()
+	}
+	return evicted
+}
+
+// LoadedCount returns the number of sessions currently held in memory.
+func (sm *SessionManager) LoadedCount() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return len(sm.sessions)
+}
+
 // Close is a no-op for the in-memory SessionManager; it satisfies the
 // SessionStore interface so callers can release resources uniformly.
 func (sm *SessionManager) Close() error {
@@ -288,7 +422,7 @@ func (sm *SessionManager) SetHistory(key string, history []providers.Message) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	session, ok := sm.sessions[key]
+	session, ok := sm.lookupLocked(key)
 	if ok {
 		// Create a deep copy to strictly isolate internal state
 		// from the caller's slice.
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestSanitizeFilename(t *testing.T) {
@@ -83,3 +84,97 @@ func TestSave_RejectsPathTraversal(t *testing.T) {
 		t.Errorf("expected foo_bar.json in storage (sanitized from foo/bar)")
 	}
 }
+
+func TestEvictIdle_FlushesAndReloadsOnAccess(t *testing.T) {
+	sm := NewSessionManager(t.TempDir())
+	sm.AddMessage("idle", "user", "hello")
+	sm.SetSummary("idle", "greeting")
+	sm.AddMessage("busy", "user", "still here")
+	sm.AddMessage("fresh", "user", "just now")
+
+	// Backdate the sessions so they look idle.
+	sm.mu.Lock()
+	for _, key := range []string{"idle", "busy"} {
+		sm.sessions[key].Updated = time.Now().Add(-2 * time.Hour)
+	}
+	sm.mu.Unlock()
+
+	n := sm.EvictIdle(time.Hour, func(key string) bool { return key == "busy" })
+	if n != 1 {
+		t.Fatalf("EvictIdle() = %d, want 1", n)
+	}
+	if got := sm.LoadedCount(); got != 2 {
+		t.Fatalf("LoadedCount() = %d, want 2", got)
+	}
+	if keys := sm.ListSessions(); len(keys) != 3 {
+		t.Fatalf("ListSessions() = %v, want evicted key still listed", keys)
+	}
+
+	history := sm.GetHistory("idle")
+	if len(history) != 1 || history[0].Content != "hello" {
+		t.Fatalf("GetHistory() after eviction = %+v, want reloaded message", history)
+	}
+	if got := sm.GetSummary("idle"); got != "greeting" {
+		t.Fatalf("GetSummary() after eviction = %q, want greeting", got)
+	}
+	if got := sm.LoadedCount(); got != 3 {
+		t.Fatalf("LoadedCount() after reload = %d, want 3", got)
+	}
+}
+
+func TestSessionStats_DoesNotReloadEvictedSession(t *testing.T) {
+	sm := NewSessionManager(t.TempDir())
+	sm.AddMessage("idle", "user", "hello")
+	sm.AddMessage("idle", "assistant", "hi")
+	updated := time.Now().Add(-2 * time.Hour).Round(0)
+	sm.mu.Lock()
+	sm.sessions["idle"].Updated = updated
+	sm.mu.Unlock()
+
+	if n := sm.EvictIdle(time.Hour, nil); n != 1 {
+		t.Fatalf("EvictIdle() = %d, want 1", n)
+	}
+	count, last := sm.SessionStats("idle")
+	if count != 2 || !last.Equal(updated) {
+		t.Fatalf("SessionStats() = %d, %v; want 2, %v", count, last, updated)
+	}
+	if got := sm.LoadedCount(); got != 0 {
+		t.Fatalf("LoadedCount() = %d, want the session to stay evicted", got)
+	}
+}
+
+func TestEvictIdle_AppendAfterEvictionKeepsHistory(t *testing.T) {
+	sm := NewSessionManager(t.TempDir())
+	sm.AddMessage("chat", "user", "first")
+	sm.mu.Lock()
+	sm.sessions["chat"].Updated = time.Now().Add(-2 * time.Hour)
+	sm.mu.Unlock()
+
+	if n := sm.EvictIdle(time.Hour, nil); n != 1 {
+		t.Fatalf("EvictIdle() = %d, want 1", n)
+	}
+	sm.AddMessage("chat", "assistant", "second")
+
+	history := sm.GetHistory("chat")
+	if len(history) != 2 || history[0].Content != "first" || history[1].Content != "second" {
+		t.Fatalf("history = %+v, want both messages", history)
+	}
+}
+
+func TestEvictIdle_WithoutStorageDropsHistory(t *testing.T) {
+	sm := NewSessionManager("")
+	sm.AddMessage("chat", "user", "hello")
+	sm.mu.Lock()
+	sm.sessions["chat"].Updated = time.Now().Add(-2 * time.Hour)
+	sm.mu.Unlock()
+
+	if n := sm.EvictIdle(time.Hour, nil); n != 1 {
+		t.Fatalf("EvictIdle() = %d, want 1", n)
+	}
+	if got := sm.GetHistory("chat"); len(got) != 0 {
+		t.Fatalf("GetHistory() = %+v, want empty", got)
+	}
+	if keys := sm.ListSessions(); len(keys) != 0 {
+		t.Fatalf("ListSessions() = %v, want none", keys)
+	}
+}
@@ -1,6 +1,10 @@
 package session
 
-import "github.com/sipeed/picoclaw/pkg/providers"
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
 
 // SessionStore defines the persistence operations used by the agent loop.
 // Both SessionManager (legacy JSON backend) and JSONLBackend satisfy this
@@ -32,3 +36,22 @@ type SessionStore interface {
 	// Close releases resources held by the store.
 	Close() error
 }
+
+// ActivityAwareSessionStore reports when a session was last modified.
+// Stores that cannot tell return the zero time.
+type ActivityAwareSessionStore interface {
+	LastActivity(sessionKey string) time.Time
+}
+
+// StatsAwareSessionStore reports a session's message count and last update
+// without loading its history, so listing sessions stays cheap.
+type StatsAwareSessionStore interface {
+	SessionStats(sessionKey string) (messages int, updated time.Time)
+}
+
+// EvictableSessionStore is implemented by stores that hold sessions in
+// memory and can release idle ones.
+type EvictableSessionStore interface {
+	EvictIdle(idle time.Duration, keep func(sessionKey string) bool) int
+	LoadedCount() int
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -23,14 +24,37 @@ type State struct {
 
 	// Timestamp is the last time this state was updated
 	Timestamp time.Time `json:"timestamp"`
+
+	// GreetedSenders maps "channel:sender" to when the sender was first
+	// sent the channel's welcome message
+	GreetedSenders map[string]time.Time `json:"greeted_senders,omitempty"`
+
+	// SessionModels maps a session key to the model chosen for it with the
+	// model tool
+	SessionModels map[string]string `json:"session_models,omitempty"`
 }
 
+const (
+	// maxGreetedSenders caps GreetedSenders. Past it, the senders greeted
+	// longest ago are forgotten, down to nine tenths of the cap, and would
+	// be welcomed again if they wrote.
+	maxGreetedSenders = 10000
+	// greetedSaveDelay batches the saves of newly greeted senders, so a
+	// burst of new senders rewrites the state file once.
+	greetedSaveDelay = 5 * time.Second
+)
+
 // Manager manages persistent state with atomic saves.
 type Manager struct {
 	workspace string
 	state     *State
 	mu        sync.RWMutex
 	stateFile string
+
+	// dirty is set while a change is waiting for the deferred save that
+	// saveTimer will run.
+	dirty     bool
+	saveTimer *time.Timer
 }
 
 // NewManager creates a new state manager for the given workspace.
@@ -108,6 +132,108 @@ func (sm *Manager) SetLastChatID(chatID string) error {
 	return nil
 }
 
+// MarkGreeted records that the sender identified by key has been welcomed
+// and reports whether this was the first time. The record is saved within
+// greetedSaveDelay, or by the next save or Flush, whichever comes first.
+func (sm *Manager) MarkGreeted(key string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, ok := sm.state.GreetedSenders[key]; ok {
+		return false
+	}
+	if sm.state.GreetedSenders == nil {
+		sm.state.GreetedSenders = make(map[string]time.Time)
+	}
+	sm.state.GreetedSenders[key] = time.Now()
+	pruneGreeted(sm.state.GreetedSenders)
+
+	sm.dirty = true
+	if sm.saveTimer == nil {
+		sm.saveTimer = time.AfterFunc(greetedSaveDelay, sm.saveDeferred)
+	}
+	return true
+}
+
+// pruneGreeted forgets the senders greeted longest ago once greeted holds
+// more than maxGreetedSenders.
+func pruneGreeted(greeted map[string]time.Time) {
+	if len(greeted) <= maxGreetedSenders {
+		return
+	}
+	keys := make([]string, 0, len(greeted))
+	for k := range greeted {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return greeted[keys[i]].Before(greeted[keys[j]]) })
+	for _, k := range keys[:len(keys)-maxGreetedSenders*9/10] {
+		delete(greeted, k)
+	}
+}
+
+func (sm *Manager) saveDeferred() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.saveTimer = nil
+	if !sm.dirty {
+		return
+	}
+	if err := sm.saveAtomic(); err != nil {
+		log.Printf("[WARN] state: failed to save state: %v", err)
+	}
+}
+
+// Flush saves any change still waiting for its deferred save. Call it
+// before the process exits.
+func (sm *Manager) Flush() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.saveTimer != nil {
+		sm.saveTimer.Stop()
+		sm.saveTimer = nil
+	}
+	if !sm.dirty {
+		return nil
+	}
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return nil
+}
+
+// SetSessionModel records the model chosen for a session and saves the
+// state. An empty model clears the choice.
+func (sm *Manager) SetSessionModel(sessionKey, model string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if model == "" {
+		if _, ok := sm.state.SessionModels[sessionKey]; !ok {
+			return nil
+		}
+		delete(sm.state.SessionModels, sessionKey)
+	} else {
+		if sm.state.SessionModels == nil {
+			sm.state.SessionModels = make(map[string]string)
+		}
+		sm.state.SessionModels[sessionKey] = model
+	}
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return nil
+}
+
+// GetSessionModel returns the model chosen for a session, or "" if none.
+func (sm *Manager) GetSessionModel(sessionKey string) string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.state.SessionModels[sessionKey]
+}
+
 // GetLastChannel returns the last channel from the state.
 func (sm *Manager) GetLastChannel() string {
 	sm.mu.RLock()
@@ -145,7 +271,11 @@ func (sm *Manager) saveAtomic() error {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	return fileutil.WriteFileAtomic(sm.stateFile, data, 0o600)
+	if err := fileutil.WriteFileAtomic(sm.stateFile, data, 0o600); err != nil {
+		return err
+	}
+	sm.dirty = false
+	return nil
 }
 
 // load loads the state from disk.
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestAtomicSave(t *testing.T) {
@@ -49,6 +50,101 @@ func TestAtomicSave(t *testing.T) {
 	}
 }
 
+func TestMarkGreeted_PersistsAcrossManagers(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sm := NewManager(tmpDir)
+	if !sm.MarkGreeted("telegram:1") {
+		t.Fatal("MarkGreeted() = false for a new sender")
+	}
+	if sm.MarkGreeted("telegram:1") {
+		t.Fatal("MarkGreeted() reported the same sender as new twice")
+	}
+	if err := sm.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	sm2 := NewManager(tmpDir)
+	if sm2.MarkGreeted("telegram:1") {
+		t.Fatal("greeted sender was forgotten after reload")
+	}
+	if !sm2.MarkGreeted("discord:1") {
+		t.Fatal("a different sender was treated as already greeted")
+	}
+}
+
+func TestMarkGreeted_BatchesSaves(t *testing.T) {
+	tmpDir := t.TempDir()
+	stateFile := filepath.Join(tmpDir, "state", "state.json")
+
+	sm := NewManager(tmpDir)
+	for i := range 100 {
+		sm.MarkGreeted(fmt.Sprintf("telegram:%d", i))
+	}
+	if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+		t.Fatalf("state file written before the deferred save: %v", err)
+	}
+
+	// Any other save writes the pending senders along with it.
+	if err := sm.SetLastChannel("telegram"); err != nil {
+		t.Fatalf("SetLastChannel() error = %v", err)
+	}
+	if NewManager(tmpDir).MarkGreeted("telegram:99") {
+		t.Fatal("pending greeted senders were not saved with the next save")
+	}
+	if err := sm.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+}
+
+func TestMarkGreeted_ForgetsOldestPastCap(t *testing.T) {
+	sm := NewManager(t.TempDir())
+	defer sm.Flush()
+
+	start := time.Now().Add(-time.Hour)
+	sm.state.GreetedSenders = make(map[string]time.Time, maxGreetedSenders)
+	for i := range maxGreetedSenders {
+		sm.state.GreetedSenders[fmt.Sprintf("telegram:%d", i)] = start.Add(time.Duration(i) * time.Millisecond)
+	}
+	if !sm.MarkGreeted("telegram:new") {
+		t.Fatal("MarkGreeted() = false for a new sender")
+	}
+
+	if got, want := len(sm.state.GreetedSenders), maxGreetedSenders*9/10; got != want {
+		t.Fatalf("kept %d greeted senders, want %d", got, want)
+	}
+	if _, ok := sm.state.GreetedSenders["telegram:0"]; ok {
+		t.Fatal("the oldest greeted sender was kept")
+	}
+	if _, ok := sm.state.GreetedSenders["telegram:new"]; !ok {
+		t.Fatal("the newest greeted sender was dropped")
+	}
+}
+
+func TestSetSessionModel_PersistsAcrossManagers(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sm := NewManager(tmpDir)
+	if err := sm.SetSessionModel("agent:main:direct", "fast"); err != nil {
+		t.Fatalf("SetSessionModel() error = %v", err)
+	}
+
+	sm2 := NewManager(tmpDir)
+	if got := sm2.GetSessionModel("agent:main:direct"); got != "fast" {
+		t.Fatalf("GetSessionModel() = %q, want %q", got, "fast")
+	}
+	if got := sm2.GetSessionModel("agent:main:other"); got != "" {
+		t.Fatalf("GetSessionModel() for another session = %q, want empty", got)
+	}
+
+	if err := sm2.SetSessionModel("agent:main:direct", ""); err != nil {
+		t.Fatalf("SetSessionModel(\"\") error = %v", err)
+	}
+	if got := NewManager(tmpDir).GetSessionModel("agent:main:direct"); got != "" {
+		t.Fatalf("cleared session model = %q, want empty", got)
+	}
+}
+
 func TestSetLastChatID(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "state-test-*")
 	if err != nil {
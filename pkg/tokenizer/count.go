@@ -0,0 +1,191 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// encoding approximates one model family's tokenizer without its vocabulary.
+// Text is split the way BPE pre-tokenizers split it (words, digit groups,
+// punctuation, whitespace, CJK characters) and each piece is costed with the
+// family's typical rates. That lands close to the real count for ordinary
+// prose and code without shipping megabytes of BPE ranks.
+type encoding struct {
+	wordChars  float64 // ASCII letters per token inside a word
+	otherChars float64 // letters per token for non-Latin, non-CJK scripts
+	cjkTokens  float64 // tokens per CJK character
+	perMessage int     // role and separator tokens added to every message
+}
+
+var (
+	// cl100k_base: GPT-4, GPT-3.5 and the text-embedding-3 models.
+	encCL100K = &encoding{wordChars: 6, otherChars: 2, cjkTokens: 1.2, perMessage: 4}
+	// o200k_base: GPT-4o, GPT-4.1, GPT-5 and the o-series.
+	encO200K = &encoding{wordChars: 6, otherChars: 3, cjkTokens: 0.9, perMessage: 4}
+	// Claude's tokenizer splits English slightly finer than tiktoken.
+	encClaude = &encoding{wordChars: 5, otherChars: 2, cjkTokens: 1.3, perMessage: 5}
+	// Gemini and Gemma use a SentencePiece vocabulary that is dense for
+	// most scripts.
+	encGemini = &encoding{wordChars: 6, otherChars: 3, cjkTokens: 0.9, perMessage: 4}
+)
+
+// encodingFor picks the encoding for a model name, with or without a
+// provider prefix ("openai/gpt-4o"). It returns nil for unknown families.
+func encodingFor(model string) *encoding {
+	m := strings.ToLower(model)
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(m, "gpt-4o"), strings.HasPrefix(m, "gpt-4.1"), strings.HasPrefix(m, "gpt-5"),
+		strings.HasPrefix(m, "chatgpt"), strings.HasPrefix(m, "gpt-oss"),
+		strings.HasPrefix(m, "o1"), strings.HasPrefix(m, "o3"), strings.HasPrefix(m, "o4"):
+		return encO200K
+	case strings.HasPrefix(m, "gpt-4"), strings.HasPrefix(m, "gpt-3.5"), strings.HasPrefix(m, "text-embedding"):
+		return encCL100K
+	case strings.Contains(m, "claude"):
+		return encClaude
+	case strings.HasPrefix(m, "gemini"), strings.HasPrefix(m, "gemma"):
+		return encGemini
+	default:
+		return nil
+	}
+}
+
+// EstimateTokens estimates the prompt tokens messages cost for model.
+// OpenAI, Claude and Gemini models get a tokenizer-shaped estimate; other
+// models fall back to the character heuristic of EstimateMessageTokens.
+func EstimateTokens(messages []providers.Message, model string) int {
+	enc := encodingFor(model)
+	total := 0
+	for _, msg := range messages {
+		if enc == nil {
+			total += EstimateMessageTokens(msg)
+		} else {
+			total += enc.messageTokens(msg)
+		}
+	}
+	return total
+}
+
+// EstimateTextTokens estimates the tokens of a bare string for model.
+func EstimateTextTokens(text, model string) int {
+	enc := encodingFor(model)
+	if enc == nil {
+		return utf8.RuneCountInString(text) * 2 / 5
+	}
+	return enc.textTokens(text)
+}
+
+func (e *encoding) messageTokens(msg providers.Message) int {
+	content := e.textTokens(msg.Content)
+	// SystemParts repeat Content in blocks; count whichever is larger, as
+	// EstimateMessageTokens does.
+	if len(msg.SystemParts) > 0 {
+		parts := 0
+		for _, part := range msg.SystemParts {
+			parts += e.textTokens(part.Text) + 3
+		}
+		content = max(content, parts)
+	}
+
+	tokens := e.perMessage + content + e.textTokens(msg.ReasoningContent)
+	for _, tc := range msg.ToolCalls {
+		name, args := tc.Name, ""
+		if tc.Function != nil {
+			name, args = tc.Function.Name, tc.Function.Arguments
+		} else if tc.Arguments != nil {
+			if data, err := json.Marshal(tc.Arguments); err == nil {
+				args = string(data)
+			}
+		}
+		tokens += 3 + e.textTokens(name) + e.textTokens(args)
+	}
+	if msg.ToolCallID != "" {
+		tokens += e.textTokens(msg.ToolCallID)
+	}
+
+	const mediaTokensPerItem = 256
+	return tokens + len(msg.Media)*mediaTokensPerItem
+}
+
+type runKind int
+
+const (
+	runNone runKind = iota
+	runWord
+	runOther // letters outside ASCII and CJK
+	runDigit
+	runPunct
+	runSpace
+	runNewline
+)
+
+func (e *encoding) textTokens(s string) int {
+	if s == "" {
+		return 0
+	}
+
+	var tokens float64
+	kind, n := runNone, 0
+	flush := func() {
+		switch kind {
+		case runWord:
+			tokens += math.Ceil(float64(n) / e.wordChars)
+		case runOther:
+			tokens += math.Ceil(float64(n) / e.otherChars)
+		case runDigit:
+			tokens += math.Ceil(float64(n) / 3) // digits are split in groups of up to 3
+		case runPunct:
+			tokens += math.Ceil(float64(n) / 2)
+		case runSpace:
+			// A single space joins the following word; indentation is its
+			// own token.
+			if n > 1 {
+				tokens++
+			}
+		case runNewline:
+			tokens++
+		}
+		kind, n = runNone, 0
+	}
+
+	for _, r := range s {
+		var k runKind
+		switch {
+		case isCJK(r):
+			flush()
+			tokens += e.cjkTokens
+			continue
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || r == '_'):
+			k = runWord
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			k = runOther
+		case unicode.IsDigit(r):
+			k = runDigit
+		case r == '\n' || r == '\r':
+			k = runNewline
+		case unicode.IsSpace(r):
+			k = runSpace
+		default:
+			k = runPunct
+		}
+		if k != kind {
+			flush()
+			kind = k
+		}
+		n++
+	}
+	flush()
+	return int(math.Ceil(tokens))
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
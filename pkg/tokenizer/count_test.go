@@ -0,0 +1,80 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestEstimateTextTokens_TracksTiktoken(t *testing.T) {
+	// Reference counts from tiktoken's cl100k_base.
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"The quick brown fox jumps over the lazy dog.", 10},
+		{"Hello, world!", 4},
+		{"12345678", 3},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got := EstimateTextTokens(tt.text, "gpt-4")
+		if diff := got - tt.want; diff < -1 || diff > 1 {
+			t.Errorf("EstimateTextTokens(%q) = %d, want %d±1", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateTokens_ModelFamilies(t *testing.T) {
+	for model, want := range map[string]*encoding{
+		"gpt-4o-mini":                 encO200K,
+		"openai/o3-mini":              encO200K,
+		"gpt-4-turbo":                 encCL100K,
+		"anthropic/claude-sonnet-4-5": encClaude,
+		"gemini-2.5-pro":              encGemini,
+		"qwen/qwen3-235b-a22b":        nil,
+		"":                            nil,
+	} {
+		if got := encodingFor(model); got != want {
+			t.Errorf("encodingFor(%q) = %+v, want %+v", model, got, want)
+		}
+	}
+
+	msgs := []providers.Message{
+		{Role: "system", Content: "You are a helpful assistant."},
+		{Role: "user", Content: "Summarize the attached report in three bullet points."},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{
+			ID:   "call_1",
+			Name: "read_file",
+			Function: &providers.FunctionCall{
+				Name:      "read_file",
+				Arguments: `{"path":"report.md"}`,
+			},
+		}}},
+	}
+
+	// Unknown families use the character heuristic unchanged.
+	fallback := 0
+	for _, m := range msgs {
+		fallback += EstimateMessageTokens(m)
+	}
+	if got := EstimateTokens(msgs, "some-local-model"); got != fallback {
+		t.Errorf("EstimateTokens(unknown model) = %d, want heuristic %d", got, fallback)
+	}
+
+	gpt := EstimateTokens(msgs, "gpt-4o")
+	if gpt <= 0 || gpt >= fallback {
+		t.Errorf("EstimateTokens(gpt-4o) = %d, want a positive count below the conservative heuristic %d",
+			gpt, fallback)
+	}
+	if claude := EstimateTokens(msgs, "claude-opus-4"); claude < gpt {
+		t.Errorf("EstimateTokens(claude) = %d, want at least the gpt-4o count %d", claude, gpt)
+	}
+}
+
+func TestEstimateTextTokens_CJK(t *testing.T) {
+	// "Hello, world" in Chinese: about one token per character.
+	if got := EstimateTextTokens("你好，世界", "gpt-4"); got < 4 || got > 7 {
+		t.Errorf("EstimateTextTokens(cl100k, CJK) = %d, want 4..7", got)
+	}
+}
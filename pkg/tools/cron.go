@@ -31,6 +31,7 @@ type CronTool struct {
 	execTool     *ExecTool
 	allowCommand bool
 	execEnabled  bool
+	channelCheck func(channel string) bool
 }
 
 // NewCronTool creates a new CronTool
@@ -292,6 +293,25 @@ func (t *CronTool) enableJob(args map[string]any, enable bool) *ToolResult {
 	return SilentResult(fmt.Sprintf("Cron job '%s' %s", job.Name, status))
 }
 
+// SetChannelChecker installs a callback reporting whether a channel is
+// currently able to deliver messages. Without one, targets are assumed online.
+func (t *CronTool) SetChannelChecker(check func(channel string) bool) {
+	t.channelCheck = check
+}
+
+// CheckTarget returns cron.ErrTargetUnavailable when the job's target channel
+// is offline, so the cron service can defer the run instead of dropping it.
+func (t *CronTool) CheckTarget(job *cron.CronJob) error {
+	channel := job.Payload.Channel
+	if t.channelCheck == nil || channel == "" || constants.IsInternalChannel(channel) {
+		return nil
+	}
+	if !t.channelCheck(channel) {
+		return fmt.Errorf("%w: %s", cron.ErrTargetUnavailable, channel)
+	}
+	return nil
+}
+
 // ExecuteJob executes a cron job through the agent
 func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 	// Get channel/chatID from job payload
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -345,3 +346,23 @@ func TestCronTool_ExecuteJobReturnsErrorWithoutPublish(t *testing.T) {
 		t.Fatalf("unexpected publish on error path: %q", executor.publishedResp)
 	}
 }
+
+func TestCronTool_CheckTarget(t *testing.T) {
+	tool := newTestCronTool(t)
+	job := &cron.CronJob{Payload: cron.CronPayload{Channel: "telegram", To: "42"}}
+
+	if err := tool.CheckTarget(job); err != nil {
+		t.Fatalf("CheckTarget() without checker = %v, want nil", err)
+	}
+
+	tool.SetChannelChecker(func(channel string) bool { return channel != "telegram" })
+	if err := tool.CheckTarget(job); !errors.Is(err, cron.ErrTargetUnavailable) {
+		t.Fatalf("CheckTarget() = %v, want ErrTargetUnavailable", err)
+	}
+
+	internal := &cron.CronJob{Payload: cron.CronPayload{Channel: "cli", To: "direct"}}
+	tool.SetChannelChecker(func(string) bool { return false })
+	if err := tool.CheckTarget(internal); err != nil {
+		t.Fatalf("CheckTarget() for internal channel = %v, want nil", err)
+	}
+}
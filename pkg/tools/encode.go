@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// encodeToolMaxInputBytes caps the input of one call; larger data belongs in
+// a file and a shell pipeline.
+const encodeToolMaxInputBytes = 1 << 20
+
+var encodeOperations = []string{
+	"base64_encode", "base64_decode",
+	"hex_encode", "hex_decode",
+	"url_encode", "url_decode",
+	"json_pretty", "json_minify",
+	"md5", "sha1", "sha256", "sha512",
+}
+
+// EncodeTool converts and hashes text in-process: base64, hex and URL
+// encoding, JSON formatting, and message digests. It runs no subprocess and
+// touches no files, so it is safe on every channel.
+type EncodeTool struct{}
+
+func NewEncodeTool() *EncodeTool {
+	return &EncodeTool{}
+}
+
+func (t *EncodeTool) Name() string {
+	return "encode"
+}
+
+func (t *EncodeTool) Description() string {
+	return "Encode, decode or hash text without running a command: base64, hex and URL " +
+		"encoding and decoding, JSON pretty-printing and minifying, and MD5, SHA-1, SHA-256 " +
+		"or SHA-512 digests (as lowercase hex)."
+}
+
+func (t *EncodeTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"operation": map[string]any{
+				"type":        "string",
+				"enum":        encodeOperations,
+				"description": "Transform to apply to input",
+			},
+			"input": map[string]any{
+				"type":        "string",
+				"description": "Text to transform (up to 1 MiB)",
+			},
+		},
+		"required": []string{"operation", "input"},
+	}
+}
+
+func (t *EncodeTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	operation, _ := args["operation"].(string)
+	input, ok := args["input"].(string)
+	if !ok {
+		return ErrorResult("input is required")
+	}
+	if len(input) > encodeToolMaxInputBytes {
+		return ErrorResult(fmt.Sprintf("input exceeds %d bytes", encodeToolMaxInputBytes))
+	}
+
+	switch operation {
+	case "base64_encode":
+		return SilentResult(base64.StdEncoding.EncodeToString([]byte(input)))
+	case "base64_decode":
+		data, err := decodeBase64(input)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("invalid base64: %v", err))
+		}
+		return decodedResult(data, "hex", hex.EncodeToString)
+	case "hex_encode":
+		return SilentResult(hex.EncodeToString([]byte(input)))
+	case "hex_decode":
+		data, err := hex.DecodeString(strings.Join(strings.Fields(input), ""))
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("invalid hex: %v", err))
+		}
+		return decodedResult(data, "base64", base64.StdEncoding.EncodeToString)
+	case "url_encode":
+		return SilentResult(url.QueryEscape(input))
+	case "url_decode":
+		out, err := url.QueryUnescape(input)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("invalid URL encoding: %v", err))
+		}
+		return SilentResult(out)
+	case "json_pretty", "json_minify":
+		var buf bytes.Buffer
+		var err error
+		if operation == "json_pretty" {
+			err = json.Indent(&buf, []byte(input), "", "  ")
+		} else {
+			err = json.Compact(&buf, []byte(input))
+		}
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("invalid JSON: %v", err))
+		}
+		return SilentResult(buf.String())
+	case "md5":
+		return digestResult(md5.New(), input)
+	case "sha1":
+		return digestResult(sha1.New(), input)
+	case "sha256":
+		return digestResult(sha256.New(), input)
+	case "sha512":
+		return digestResult(sha512.New(), input)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown operation %q (expected one of %s)",
+			operation, strings.Join(encodeOperations, ", ")))
+	}
+}
+
+// decodeBase64 accepts the standard and URL-safe alphabets, with or without
+// padding, ignoring whitespace such as the line breaks of wrapped output.
+func decodeBase64(input string) ([]byte, error) {
+	s := strings.Join(strings.Fields(input), "")
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(s, "=") {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	return enc.DecodeString(s)
+}
+
+// decodedResult returns decoded data as text, or re-encoded with the named
+// encoding when it is binary and cannot be shown as is.
+func decodedResult(data []byte, name string, encode func([]byte) string) *ToolResult {
+	if utf8.Valid(data) {
+		return SilentResult(string(data))
+	}
+	return SilentResult(fmt.Sprintf("Decoded %d bytes of binary data, shown as %s:\n%s",
+		len(data), name, encode(data)))
+}
+
+func digestResult(h hash.Hash, input string) *ToolResult {
+	h.Write([]byte(input))
+	return SilentResult(hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEncodeTool_Operations(t *testing.T) {
+	tool := NewEncodeTool()
+	tests := []struct {
+		operation, input, want string
+	}{
+		{"base64_encode", "hello?", "aGVsbG8/"},
+		{"base64_decode", "aGVsbG8/", "hello?"},
+		{"base64_decode", "aGVsbG8_", "hello?"},
+		{"base64_decode", "aGk", "hi"},
+		{"base64_decode", "aGVs\nbG8=", "hello"},
+		{"hex_encode", "hi", "6869"},
+		{"hex_decode", "68 69", "hi"},
+		{"url_encode", "a b&c=d", "a+b%26c%3Dd"},
+		{"url_decode", "a+b%26c%3Dd", "a b&c=d"},
+		{"json_pretty", `{"a":[1,2]}`, "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{"json_minify", "{\n  \"a\": 1\n}", `{"a":1}`},
+		{"md5", "abc", "900150983cd24fb0d6963f7d28e17f72"},
+		{"sha1", "abc", "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"sha256", "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+	for _, tt := range tests {
+		res := tool.Execute(context.Background(), map[string]any{"operation": tt.operation, "input": tt.input})
+		if res.IsError {
+			t.Fatalf("%s(%q) failed: %s", tt.operation, tt.input, res.ForLLM)
+		}
+		if res.ForLLM != tt.want {
+			t.Errorf("%s(%q) = %q, want %q", tt.operation, tt.input, res.ForLLM, tt.want)
+		}
+	}
+}
+
+func TestEncodeTool_BinaryDecodeIsReencoded(t *testing.T) {
+	res := NewEncodeTool().Execute(context.Background(), map[string]any{"operation": "base64_decode", "input": "/w=="})
+	if res.IsError || !strings.HasSuffix(res.ForLLM, "shown as hex:\nff") {
+		t.Fatalf("binary base64_decode = %q", res.ForLLM)
+	}
+}
+
+func TestEncodeTool_InvalidInput(t *testing.T) {
+	tool := NewEncodeTool()
+	for _, tt := range []struct {
+		operation, input, want string
+	}{
+		{"base64_decode", "not base64!", "invalid base64"},
+		{"hex_decode", "zz", "invalid hex"},
+		{"url_decode", "%zz", "invalid URL encoding"},
+		{"json_pretty", "{oops", "invalid JSON"},
+		{"rot13", "x", "unknown operation"},
+	} {
+		res := tool.Execute(context.Background(), map[string]any{"operation": tt.operation, "input": tt.input})
+		if !res.IsError || !strings.Contains(res.ForLLM, tt.want) {
+			t.Errorf("%s(%q) = %q (error %t), want an error containing %q",
+				tt.operation, tt.input, res.ForLLM, res.IsError, tt.want)
+		}
+	}
+}
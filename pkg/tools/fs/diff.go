@@ -0,0 +1,383 @@
+package fstools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	defaultDiffContext = 3
+	maxDiffContext     = 20
+	maxDiffFiles       = 500
+)
+
+// DiffTool shows a unified diff between two files, two directories, or a
+// file and a snapshot of its earlier content, so the agent can summarise
+// exactly what an edit changed.
+type DiffTool struct {
+	fs      fileSystem
+	maxSize int64
+}
+
+func NewDiffTool(
+	workspace string,
+	restrict bool,
+	maxReadFileSize int,
+	allowPaths ...[]*regexp.Regexp,
+) *DiffTool {
+	var patterns []*regexp.Regexp
+	if len(allowPaths) > 0 {
+		patterns = allowPaths[0]
+	}
+
+	maxSize := int64(maxReadFileSize)
+	if maxSize <= 0 {
+		maxSize = MaxReadFileSize
+	}
+
+	return &DiffTool{
+		fs:      buildFs(workspace, restrict, patterns),
+		maxSize: maxSize,
+	}
+}
+
+func (t *DiffTool) Name() string {
+	return "diff"
+}
+
+func (t *DiffTool) Description() string {
+	return "Show a unified diff. Compare `path` with `other_path` (two files or two directories), or compare the current content of `path` with an earlier `snapshot` of it."
+}
+
+func (t *DiffTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File or directory to compare. With other_path it is the old side; with snapshot it is the new side.",
+			},
+			"other_path": map[string]any{
+				"type":        "string",
+				"description": "File or directory to compare path against (the new side).",
+			},
+			"snapshot": map[string]any{
+				"type":        "string",
+				"description": "Earlier content of the file at path. The diff shows how the file changed since.",
+			},
+			"context_lines": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Unchanged lines shown around each change (max %d).", maxDiffContext),
+				"default":     defaultDiffContext,
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *DiffTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	p, ok := args["path"].(string)
+	if !ok || p == "" {
+		return ErrorResult("path is required")
+	}
+	other, _ := args["other_path"].(string)
+	snapshot, hasSnapshot := args["snapshot"].(string)
+	if (other == "") == !hasSnapshot {
+		return ErrorResult("exactly one of other_path or snapshot is required")
+	}
+
+	contextLines, err := getInt64Arg(args, "context_lines", defaultDiffContext)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if contextLines < 0 {
+		return ErrorResult("context_lines must be >= 0")
+	}
+	contextLines = min(contextLines, maxDiffContext)
+
+	d := &differ{fs: t.fs, maxSize: t.maxSize, context: int(contextLines)}
+	if hasSnapshot {
+		current, err := d.readText(p)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		d.diffText(snapshot, current, "a/"+filepath.ToSlash(p)+" (snapshot)", "b/"+filepath.ToSlash(p))
+	} else {
+		leftDir, err := d.isDir(p)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		rightDir, err := d.isDir(other)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		switch {
+		case leftDir && rightDir:
+			if err := d.diffDirs(ctx, p, other); err != nil {
+				return ErrorResult(err.Error())
+			}
+		case leftDir || rightDir:
+			return ErrorResult("path and other_path must both be files or both be directories")
+		default:
+			if err := d.diffFiles(p, other, filepath.ToSlash(p), filepath.ToSlash(other)); err != nil {
+				return ErrorResult(err.Error())
+			}
+		}
+	}
+
+	logger.DebugCF("tool", "DiffTool execution completed successfully",
+		map[string]any{
+			"path":       p,
+			"other_path": other,
+			"snapshot":   hasSnapshot,
+			"bytes":      d.out.Len(),
+		})
+
+	if d.out.Len() == 0 {
+		return NewToolResult("No differences.")
+	}
+	return NewToolResult(d.result())
+}
+
+// differ accumulates the diff output and stops adding to it once the read
+// budget is spent, so a large change cannot flood the context.
+type differ struct {
+	fs      fileSystem
+	maxSize int64
+	context int
+
+	out       strings.Builder
+	truncated bool
+}
+
+func (d *differ) result() string {
+	s := d.out.String()
+	if d.truncated {
+		s += fmt.Sprintf("\n[diff truncated at %d bytes; compare narrower paths to see the rest.]", d.maxSize)
+	}
+	return s
+}
+
+func (d *differ) write(s string) {
+	if d.truncated {
+		return
+	}
+	if room := int(d.maxSize) - d.out.Len(); len(s) > room {
+		// Cut at a line boundary so the last hunk line is whole.
+		s = s[:max(room, 0)]
+		if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+			s = s[:i+1]
+		}
+		d.truncated = true
+	}
+	d.out.WriteString(s)
+}
+
+func (d *differ) isDir(p string) (bool, error) {
+	f, err := d.fs.Open(p)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", p, err)
+	}
+	return info.IsDir(), nil
+}
+
+// readText returns the content of a text file, refusing binary files and
+// files over the read budget.
+func (d *differ) readText(p string) (string, error) {
+	f, err := d.fs.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", p, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", p)
+	}
+	if info.Size() > d.maxSize {
+		return "", fmt.Errorf("%s is too large to diff (%d bytes, limit %d)", p, info.Size(), d.maxSize)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	if isBinaryReadFileData(data) {
+		return "", errBinary
+	}
+	return string(data), nil
+}
+
+var errBinary = errors.New("file appears to be binary; diff only compares text files")
+
+func (d *differ) diffFiles(a, b, labelA, labelB string) error {
+	textA, err := d.readText(a)
+	if errors.Is(err, errBinary) {
+		d.write(fmt.Sprintf("Binary files %s and %s may differ\n", labelA, labelB))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	textB, err := d.readText(b)
+	if errors.Is(err, errBinary) {
+		d.write(fmt.Sprintf("Binary files %s and %s may differ\n", labelA, labelB))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	d.diffText(textA, textB, "a/"+labelA, "b/"+labelB)
+	return nil
+}
+
+func (d *differ) diffText(a, b, labelA, labelB string) {
+	if a == b {
+		return
+	}
+	out, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitDiffLines(a),
+		B:        splitDiffLines(b),
+		FromFile: labelA,
+		ToFile:   labelB,
+		Context:  d.context,
+	})
+	d.write(out)
+}
+
+// splitDiffLines splits text into lines that keep their newline and marks a
+// missing final newline the way diff(1) does.
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n\\ No newline at end of file\n"
+	}
+	return lines
+}
+
+// diffDirs compares two directory trees file by file. Files present on only
+// one side are reported as added or removed.
+func (d *differ) diffDirs(ctx context.Context, a, b string) error {
+	filesA, err := d.listFiles(a)
+	if err != nil {
+		return err
+	}
+	filesB, err := d.listFiles(b)
+	if err != nil {
+		return err
+	}
+
+	inA, inB := toSet(filesA), toSet(filesB)
+	names := append([]string(nil), filesA...)
+	for _, name := range filesB {
+		if !inA[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.truncated {
+			break
+		}
+		pathA := filepath.Join(a, filepath.FromSlash(name))
+		pathB := filepath.Join(b, filepath.FromSlash(name))
+		switch {
+		case inA[name] && inB[name]:
+			if err := d.diffFiles(pathA, pathB, name, name); err != nil {
+				d.write(fmt.Sprintf("Cannot compare %s: %v\n", name, err))
+			}
+		case inA[name]:
+			d.diffOneSide(pathA, name, true)
+		default:
+			d.diffOneSide(pathB, name, false)
+		}
+	}
+	return nil
+}
+
+func (d *differ) diffOneSide(p, name string, removed bool) {
+	text, err := d.readText(p)
+	switch {
+	case errors.Is(err, errBinary) && removed:
+		d.write(fmt.Sprintf("Binary file %s removed\n", name))
+	case errors.Is(err, errBinary):
+		d.write(fmt.Sprintf("Binary file %s added\n", name))
+	case err != nil:
+		d.write(fmt.Sprintf("Cannot compare %s: %v\n", name, err))
+	case removed:
+		d.diffText(text, "", "a/"+name, "/dev/null")
+	default:
+		d.diffText("", text, "/dev/null", "b/"+name)
+	}
+}
+
+// listFiles returns the regular files under root as slash-separated paths
+// relative to it. Hidden directories such as .git are skipped.
+func (d *differ) listFiles(root string) ([]string, error) {
+	var files []string
+	var walk func(rel string) error
+	walk = func(rel string) error {
+		entries, err := d.fs.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", root, err)
+		}
+		for _, entry := range entries {
+			name := path.Join(rel, entry.Name())
+			if entry.IsDir() {
+				if strings.HasPrefix(entry.Name(), ".") {
+					continue
+				}
+				if err := walk(name); err != nil {
+					return err
+				}
+				continue
+			}
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			if len(files) >= maxDiffFiles {
+				return fmt.Errorf("%s has more than %d files; compare narrower directories", root, maxDiffFiles)
+			}
+			files = append(files, name)
+		}
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
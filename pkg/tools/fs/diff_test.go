@@ -0,0 +1,122 @@
+package fstools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTool_Files(t *testing.T) {
+	dir := writeGrepTestTree(t, map[string]string{
+		"old.txt": "one\ntwo\nthree\n",
+		"new.txt": "one\n2\nthree\n",
+	})
+	tool := NewDiffTool(dir, true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"path":       "old.txt",
+		"other_path": "new.txt",
+	})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Equal(t, "--- a/old.txt\n+++ b/new.txt\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n", result.ForLLM)
+
+	result = tool.Execute(context.Background(), map[string]any{
+		"path":       "old.txt",
+		"other_path": "old.txt",
+	})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Equal(t, "No differences.", result.ForLLM)
+}
+
+func TestDiffTool_Snapshot(t *testing.T) {
+	dir := writeGrepTestTree(t, map[string]string{"main.go": "package main\n\nfunc main() {}\n"})
+	tool := NewDiffTool(dir, true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"path":          "main.go",
+		"snapshot":      "package main\n",
+		"context_lines": 0,
+	})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Equal(t,
+		"--- a/main.go (snapshot)\n+++ b/main.go\n@@ -1,0 +2,2 @@\n+\n+func main() {}\n",
+		result.ForLLM)
+}
+
+func TestDiffTool_Directories(t *testing.T) {
+	dir := writeGrepTestTree(t, map[string]string{
+		"before/same.txt":    "same\n",
+		"before/changed.txt": "a\n",
+		"before/gone.txt":    "bye\n",
+		"before/.git/HEAD":   "x\n",
+		"after/same.txt":     "same\n",
+		"after/changed.txt":  "b",
+		"after/sub/new.txt":  "hi\n",
+		"after/.git/HEAD":    "y\n",
+	})
+	tool := NewDiffTool(dir, true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"path":       "before",
+		"other_path": "after",
+	})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Equal(t, strings.Join([]string{
+		"--- a/changed.txt",
+		"+++ b/changed.txt",
+		"@@ -1 +1 @@",
+		"-a",
+		"+b",
+		`\ No newline at end of file`,
+		"--- a/gone.txt",
+		"+++ /dev/null",
+		"@@ -1 +0,0 @@",
+		"-bye",
+		"--- /dev/null",
+		"+++ b/sub/new.txt",
+		"@@ -0,0 +1 @@",
+		"+hi",
+		"",
+	}, "\n"), result.ForLLM)
+}
+
+func TestDiffTool_TruncatesLargeOutput(t *testing.T) {
+	var before, after strings.Builder
+	for i := 0; i < 100; i++ {
+		before.WriteString("old line\n")
+		after.WriteString("new line\n")
+	}
+	dir := writeGrepTestTree(t, map[string]string{"a.txt": before.String(), "b.txt": after.String()})
+	tool := NewDiffTool(dir, true, 1024)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": "a.txt", "other_path": "b.txt"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "[diff truncated at 1024 bytes")
+	assert.LessOrEqual(t, strings.Index(result.ForLLM, "\n[diff truncated"), 1024)
+}
+
+func TestDiffTool_RejectsBadArguments(t *testing.T) {
+	dir := writeGrepTestTree(t, map[string]string{"a.txt": "a\n", "sub/b.txt": "b\n"})
+	tool := NewDiffTool(dir, true, 0)
+
+	cases := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"neither", map[string]any{"path": "a.txt"}, "exactly one of"},
+		{"both", map[string]any{"path": "a.txt", "other_path": "a.txt", "snapshot": ""}, "exactly one of"},
+		{"mixed kinds", map[string]any{"path": "a.txt", "other_path": "sub"}, "both be files"},
+		{"outside workspace", map[string]any{"path": "a.txt", "other_path": "../../etc/passwd"}, "escapes workspace"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tool.Execute(context.Background(), tc.args)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.ForLLM, tc.want)
+		})
+	}
+}
@@ -7,8 +7,13 @@ import (
 	"io/fs"
 	"regexp"
 	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
+// confirmPreviewMaxLen bounds the text quoted in a confirmation preview.
+const confirmPreviewMaxLen = 500
+
 // EditFileTool edits a file by replacing old_text with new_text.
 // The old_text must exist exactly in the file.
 type EditFileTool struct {
@@ -28,6 +33,23 @@ func (t *EditFileTool) Name() string {
 	return "edit_file"
 }
 
+// Sequential implements SequentialTool.
+func (t *EditFileTool) Sequential() bool {
+	return true
+}
+
+// ConfirmationPreview implements ConfirmableTool.
+func (t *EditFileTool) ConfirmationPreview(args map[string]any) string {
+	path, _ := args["path"].(string)
+	oldText, _ := args["old_text"].(string)
+	newText, _ := args["new_text"].(string)
+	if path == "" {
+		return ""
+	}
+	return fmt.Sprintf("Edit %s, replacing:\n%s\nwith:\n%s",
+		path, utils.Truncate(oldText, confirmPreviewMaxLen), utils.Truncate(newText, confirmPreviewMaxLen))
+}
+
 func (t *EditFileTool) Description() string {
 	return "Edit a file by replacing old_text with new_text. The old_text must exist exactly in the file. Standard JSON escaping applies: \\n for newline and \\\\n for literal backslash-n."
 }
@@ -91,6 +113,11 @@ func (t *AppendFileTool) Name() string {
 	return "append_file"
 }
 
+// Sequential implements SequentialTool.
+func (t *AppendFileTool) Sequential() bool {
+	return true
+}
+
 func (t *AppendFileTool) Description() string {
 	return "Append content to the end of a file. Standard JSON escaping applies: \\n for newline and \\\\n for literal backslash-n."
 }
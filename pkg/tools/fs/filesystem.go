@@ -881,6 +881,26 @@ func (t *WriteFileTool) Name() string {
 	return "write_file"
 }
 
+// Sequential implements SequentialTool.
+func (t *WriteFileTool) Sequential() bool {
+	return true
+}
+
+// ConfirmationPreview implements ConfirmableTool. Only overwriting an
+// existing file needs confirmation; creating a new file is harmless.
+func (t *WriteFileTool) ConfirmationPreview(args map[string]any) string {
+	path, _ := args["path"].(string)
+	content, _ := args["content"].(string)
+	if overwrite, _ := args["overwrite"].(bool); !overwrite || path == "" {
+		return ""
+	}
+	existing, err := t.fs.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("Overwrite %s (%d bytes) with %d bytes of new content.", path, len(existing), len(content))
+}
+
 func (t *WriteFileTool) Description() string {
 	return "Write content to a file. Content is written byte-for-byte after argument decoding. Standard JSON escaping applies: \\n for newline and \\\\n for a literal backslash-n sequence. If the file already exists, you must set overwrite=true to replace it."
 }
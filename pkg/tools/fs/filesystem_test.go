@@ -128,6 +128,29 @@ func TestFilesystemTool_WriteFile_Success(t *testing.T) {
 	}
 }
 
+// TestFilesystemTool_WriteFile_ConfirmationPreview verifies only overwrites of
+// existing files ask for confirmation.
+func TestFilesystemTool_WriteFile_ConfirmationPreview(t *testing.T) {
+	tmpDir := t.TempDir()
+	existing := filepath.Join(tmpDir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("old"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tool := NewWriteFileTool("", false)
+
+	assert.Empty(t, tool.ConfirmationPreview(map[string]any{
+		"path": filepath.Join(tmpDir, "new.txt"), "content": "x", "overwrite": true,
+	}))
+	assert.Empty(t, tool.ConfirmationPreview(map[string]any{
+		"path": existing, "content": "x",
+	}))
+	assert.Equal(t,
+		"Overwrite "+existing+" (3 bytes) with 5 bytes of new content.",
+		tool.ConfirmationPreview(map[string]any{"path": existing, "content": "hello", "overwrite": true}),
+	)
+}
+
 // TestFilesystemTool_WriteFile_LiteralBackslashN verifies write_file keeps
 // literal backslash sequences unchanged when they are passed as plain text.
 func TestFilesystemTool_WriteFile_LiteralBackslashN(t *testing.T) {
@@ -0,0 +1,350 @@
+package fstools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	defaultGrepResults = 100
+	maxGrepResults     = 500
+	maxGrepContext     = 5
+	maxGrepLineRunes   = 300
+)
+
+// GrepTool searches file contents under the workspace for a regular
+// expression. Unlike the other file tools it is always confined to the
+// workspace: the walk runs inside an os.Root, so neither paths nor symlinks
+// can reach outside it.
+type GrepTool struct {
+	workspace string
+	maxSize   int64
+}
+
+func NewGrepTool(workspace string, maxReadFileSize int) *GrepTool {
+	maxSize := int64(maxReadFileSize)
+	if maxSize <= 0 {
+		maxSize = MaxReadFileSize
+	}
+	return &GrepTool{workspace: workspace, maxSize: maxSize}
+}
+
+func (t *GrepTool) Name() string {
+	return "grep"
+}
+
+func (t *GrepTool) Description() string {
+	return "Search file contents across the workspace with a regular expression (RE2 syntax) and return file:line matches. Hidden directories and binary files are skipped. Use include/exclude globs to narrow the files searched."
+}
+
+func (t *GrepTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Regular expression to search for.",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Directory or file inside the workspace to search. Defaults to the whole workspace.",
+			},
+			"include": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Only search files matching one of these globs, e.g. [\"*.go\", \"docs/*.md\"]. Globs without a slash match the file name; globs with a slash match the workspace-relative path.",
+			},
+			"exclude": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Skip files and directories matching any of these globs, e.g. [\"vendor\", \"*_test.go\"].",
+			},
+			"ignore_case": map[string]any{
+				"type":        "boolean",
+				"description": "Match case-insensitively.",
+			},
+			"context_lines": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Lines of context to show before and after each match (max %d).", maxGrepContext),
+				"default":     0,
+			},
+			"max_results": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of matching lines to return (max %d).", maxGrepResults),
+				"default":     defaultGrepResults,
+			},
+		},
+		"required": []string{"pattern"},
+	}
+}
+
+type grepOptions struct {
+	re       *regexp.Regexp
+	include  []string
+	exclude  []string
+	context  int
+	limit    int
+	maxBytes int64
+}
+
+type grepStats struct {
+	files     int
+	matched   int
+	matches   int
+	truncated bool
+}
+
+func (t *GrepTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	pattern, ok := args["pattern"].(string)
+	if !ok || pattern == "" {
+		return ErrorResult("pattern is required")
+	}
+	if ignoreCase, _ := args["ignore_case"].(bool); ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid pattern: %v", err))
+	}
+
+	contextLines, err := getInt64Arg(args, "context_lines", 0)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	limit, err := getInt64Arg(args, "max_results", defaultGrepResults)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if limit <= 0 {
+		return ErrorResult("max_results must be > 0")
+	}
+
+	include, err := getStringSliceArg(args, "include")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	exclude, err := getStringSliceArg(args, "exclude")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	for _, glob := range append(append([]string(nil), include...), exclude...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return ErrorResult(fmt.Sprintf("invalid glob %q: %v", glob, err))
+		}
+	}
+
+	start := "."
+	if p, ok := args["path"].(string); ok && strings.TrimSpace(p) != "" {
+		rel, err := getSafeRelPath(t.workspace, strings.TrimSpace(p))
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		start = filepath.ToSlash(rel)
+	}
+
+	root, err := os.OpenRoot(t.workspace)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to open workspace: %v", err))
+	}
+	defer root.Close()
+
+	opts := grepOptions{
+		re:       re,
+		include:  include,
+		exclude:  exclude,
+		context:  int(min(max(contextLines, 0), maxGrepContext)),
+		limit:    int(min(limit, maxGrepResults)),
+		maxBytes: t.maxSize,
+	}
+	var out strings.Builder
+	var stats grepStats
+	fsys := root.FS()
+
+	walkErr := fs.WalkDir(fsys, start, func(name string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if name == start {
+				return err
+			}
+			return nil // unreadable entry; keep searching
+		}
+		if d.IsDir() {
+			if name != start && (strings.HasPrefix(d.Name(), ".") || matchesAnyGlob(opts.exclude, name)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if matchesAnyGlob(opts.exclude, name) {
+			return nil
+		}
+		if len(opts.include) > 0 && !matchesAnyGlob(opts.include, name) {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > opts.maxBytes {
+			return nil
+		}
+
+		stats.files++
+		if grepFile(fsys, name, opts, &out, &stats) {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if walkErr != nil {
+		if ctx.Err() != nil {
+			return ErrorResult(fmt.Sprintf("search canceled: %v", ctx.Err()))
+		}
+		return ErrorResult(fmt.Sprintf("failed to search %s: %v", start, walkErr))
+	}
+
+	logger.DebugCF("tool", "GrepTool execution completed",
+		map[string]any{
+			"pattern": pattern,
+			"path":    start,
+			"files":   stats.files,
+			"matches": stats.matches,
+		})
+
+	header := fmt.Sprintf("[%d matches in %d files | searched %d files]", stats.matches, stats.matched, stats.files)
+	if stats.truncated {
+		header += fmt.Sprintf("\n[Stopped at %d matches. Narrow the pattern, path or include globs to see more.]", opts.limit)
+	}
+	if stats.matches == 0 {
+		return NewToolResult(header)
+	}
+	return NewToolResult(header + "\n\n" + strings.TrimRight(out.String(), "\n"))
+}
+
+// grepFile appends the matches in one file to out in ripgrep's format:
+// "path:line:text" for matches, "path-line-text" for context, and "--"
+// between non-adjacent groups. It reports whether the result limit was hit.
+func grepFile(fsys fs.FS, name string, opts grepOptions, out *strings.Builder, stats *grepStats) bool {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	if head, _ := reader.Peek(512); isBinaryReadFileData(head) {
+		return false
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), int(min(opts.maxBytes, 4*1024*1024)))
+
+	var before []string // ring of preceding lines for context
+	lineNo, lastPrinted, afterLeft := 0, 0, 0
+	fileMatched := false
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+
+		if opts.re.MatchString(line) {
+			if stats.matches >= opts.limit {
+				stats.truncated = true
+				return true
+			}
+			if !fileMatched {
+				fileMatched = true
+				stats.matched++
+			}
+			first := lineNo - len(before)
+			if lastPrinted > 0 && first > lastPrinted+1 {
+				out.WriteString("--\n")
+			}
+			for i, ctxLine := range before {
+				writeGrepLine(out, name, first+i, '-', ctxLine)
+			}
+			before = before[:0]
+			writeGrepLine(out, name, lineNo, ':', line)
+			stats.matches++
+			lastPrinted = lineNo
+			afterLeft = opts.context
+			continue
+		}
+
+		if afterLeft > 0 {
+			writeGrepLine(out, name, lineNo, '-', line)
+			lastPrinted = lineNo
+			afterLeft--
+			continue
+		}
+		if opts.context > 0 {
+			if len(before) == opts.context {
+				before = append(before[:0], before[1:]...)
+			}
+			before = append(before, line)
+		}
+	}
+	// A scanner error (e.g. a line over the buffer limit) ends this file's
+	// search early but keeps the matches already found.
+	return false
+}
+
+func writeGrepLine(out *strings.Builder, name string, lineNo int, sep byte, text string) {
+	if runes := []rune(text); len(runes) > maxGrepLineRunes {
+		text = string(runes[:maxGrepLineRunes]) + "…"
+	}
+	fmt.Fprintf(out, "%s%c%d%c%s\n", name, sep, lineNo, sep, text)
+}
+
+// matchesAnyGlob reports whether name (a slash-separated workspace-relative
+// path) matches one of the globs. Globs without a slash are matched against
+// the base name only.
+func matchesAnyGlob(globs []string, name string) bool {
+	for _, glob := range globs {
+		target := name
+		if !strings.Contains(glob, "/") {
+			target = path.Base(name)
+		}
+		if ok, _ := path.Match(glob, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getStringSliceArg extracts an optional list of strings. A single string is
+// accepted as a one-element list.
+func getStringSliceArg(args map[string]any, key string) ([]string, error) {
+	raw, exists := args[key]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	switch v := raw.(type) {
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", key)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list of strings", key)
+	}
+}
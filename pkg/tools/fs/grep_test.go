@@ -0,0 +1,108 @@
+package fstools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeGrepTestTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestGrepTool_FindsMatchesWithFilters(t *testing.T) {
+	dir := writeGrepTestTree(t, map[string]string{
+		"main.go":          "package main\n// TODO: wire config\nfunc main() {}\n",
+		"pkg/util.go":      "package pkg\n// todo lowercase\n",
+		"pkg/util_test.go": "package pkg\n// TODO: add cases\n",
+		"notes.md":         "TODO: docs\n",
+		".git/config":      "TODO: hidden\n",
+		"bin/tool":         "TODO\x00binary",
+	})
+	tool := NewGrepTool(dir, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"pattern":     "todo",
+		"ignore_case": true,
+		"include":     []any{"*.go"},
+		"exclude":     []any{"*_test.go"},
+	})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "[2 matches in 2 files")
+	assert.Contains(t, result.ForLLM, "main.go:2:// TODO: wire config")
+	assert.Contains(t, result.ForLLM, "pkg/util.go:2:// todo lowercase")
+	assert.NotContains(t, result.ForLLM, "util_test.go")
+	assert.NotContains(t, result.ForLLM, "notes.md")
+	assert.NotContains(t, result.ForLLM, "hidden")
+}
+
+func TestGrepTool_ContextAndLimit(t *testing.T) {
+	dir := writeGrepTestTree(t, map[string]string{
+		"log.txt": "a\nerror 1\nb\nc\nd\nerror 2\ne\nerror 3\n",
+	})
+	tool := NewGrepTool(dir, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"pattern":       "^error",
+		"context_lines": 1,
+		"max_results":   2,
+	})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "Stopped at 2 matches")
+	body := result.ForLLM[strings.Index(result.ForLLM, "\n\n")+2:]
+	assert.Equal(t, strings.Join([]string{
+		"log.txt-1-a",
+		"log.txt:2:error 1",
+		"log.txt-3-b",
+		"--",
+		"log.txt-5-d",
+		"log.txt:6:error 2",
+		"log.txt-7-e",
+	}, "\n"), body)
+}
+
+func TestGrepTool_StaysInsideWorkspace(t *testing.T) {
+	outside := writeGrepTestTree(t, map[string]string{"secret.txt": "password=hunter2\n"})
+	dir := writeGrepTestTree(t, map[string]string{"readme.txt": "nothing here\n"})
+	tool := NewGrepTool(dir, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{"pattern": "password", "path": "../"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "escapes workspace")
+
+	result = tool.Execute(context.Background(), map[string]any{"pattern": "password", "path": outside})
+	assert.True(t, result.IsError)
+
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	result = tool.Execute(context.Background(), map[string]any{"pattern": "password"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.NotContains(t, result.ForLLM, "hunter2")
+
+	result = tool.Execute(context.Background(), map[string]any{"pattern": "password", "path": "link"})
+	assert.True(t, result.IsError, result.ForLLM)
+}
+
+func TestGrepTool_InvalidArguments(t *testing.T) {
+	tool := NewGrepTool(t.TempDir(), 0)
+
+	assert.True(t, tool.Execute(context.Background(), map[string]any{}).IsError)
+	assert.True(t, tool.Execute(context.Background(), map[string]any{"pattern": "("}).IsError)
+	assert.True(t, tool.Execute(context.Background(), map[string]any{"pattern": "x", "include": []any{"["}}).IsError)
+	assert.True(t, tool.Execute(context.Background(), map[string]any{"pattern": "x", "max_results": 0}).IsError)
+}
@@ -0,0 +1,215 @@
+package fstools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	defaultTailLines = 50
+	maxTailLines     = 1000
+)
+
+// TailFileTool returns the last lines of a text file, or the content appended
+// since a previous call, which lets the agent follow a growing log file.
+type TailFileTool struct {
+	fs      fileSystem
+	maxSize int64
+}
+
+func NewTailFileTool(
+	workspace string,
+	restrict bool,
+	maxReadFileSize int,
+	allowPaths ...[]*regexp.Regexp,
+) *TailFileTool {
+	var patterns []*regexp.Regexp
+	if len(allowPaths) > 0 {
+		patterns = allowPaths[0]
+	}
+
+	maxSize := int64(maxReadFileSize)
+	if maxSize <= 0 {
+		maxSize = MaxReadFileSize
+	}
+
+	return &TailFileTool{
+		fs:      buildFs(workspace, restrict, patterns),
+		maxSize: maxSize,
+	}
+}
+
+func (t *TailFileTool) Name() string {
+	return "tail_file"
+}
+
+func (t *TailFileTool) Description() string {
+	return "Show the last lines of a text file, such as a log. To follow a file, pass the `since_offset` reported by the previous call to read only the content written since then."
+}
+
+func (t *TailFileTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path to the file to read.",
+			},
+			"lines": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Number of lines to return from the end of the file (max %d). Ignored when since_offset is set.", maxTailLines),
+				"default":     defaultTailLines,
+			},
+			"since_offset": map[string]any{
+				"type":        "integer",
+				"description": "Byte offset returned by a previous tail_file call. Only content written after it is returned.",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *TailFileTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	path, ok := args["path"].(string)
+	if !ok {
+		return ErrorResult("path is required")
+	}
+
+	lines, err := getInt64Arg(args, "lines", defaultTailLines)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if lines <= 0 {
+		return ErrorResult("lines must be > 0")
+	}
+	if lines > maxTailLines {
+		lines = maxTailLines
+	}
+
+	since := int64(-1)
+	if raw, exists := args["since_offset"]; exists && raw != nil {
+		since, err = getInt64Arg(args, "since_offset", 0)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		if since < 0 {
+			return ErrorResult("since_offset must be >= 0")
+		}
+	}
+
+	file, err := t.fs.Open(path)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to stat file: %v", err))
+	}
+	if info.IsDir() {
+		return ErrorResult(fmt.Sprintf("failed to open file: path is a directory: %s", path))
+	}
+	seeker, ok := file.(io.Seeker)
+	if !ok {
+		return ErrorResult("tail_file requires a seekable file")
+	}
+	size := info.Size()
+
+	sample := make([]byte, 512)
+	sampleN, readErr := file.Read(sample)
+	if readErr != nil && readErr != io.EOF {
+		return ErrorResult(fmt.Sprintf("failed to read file: %v", readErr))
+	}
+	if isBinaryReadFileData(sample[:sampleN]) {
+		return ErrorResult("file appears to be binary; tail_file only reads text files")
+	}
+
+	var notes []string
+	start := since
+	if since > size {
+		notes = append(notes, "[file is smaller than since_offset; it was truncated or rotated, reading from the start.]")
+		start = 0
+	}
+	if start < 0 {
+		start = 0
+	}
+	if size-start > t.maxSize {
+		skipped := size - t.maxSize - start
+		start = size - t.maxSize
+		if since >= 0 {
+			notes = append(notes, fmt.Sprintf("[SKIPPED - %d bytes of new content exceeded the read budget.]", skipped))
+		}
+	}
+
+	if _, err = seeker.Seek(start, io.SeekStart); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to seek to offset %d: %v", start, err))
+	}
+	data := make([]byte, size-start)
+	n, err := io.ReadFull(file, data)
+	if err != nil && err != io.EOF && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorResult(fmt.Sprintf("failed to read file content: %v", err))
+	}
+	data = data[:n]
+	end := start + int64(n)
+
+	// A window that starts mid-file may begin inside a line; drop that
+	// partial line unless we are following from a known offset.
+	if since < 0 && start > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	if since >= 0 {
+		// Following returns everything new; the byte budget bounds it.
+		lines = math.MaxInt64
+	}
+	data, shown := lastLines(data, lines)
+
+	header := fmt.Sprintf("[file: %s | size: %d bytes | showing: %d lines]", filepath.Base(path), size, shown)
+	for _, note := range notes {
+		header += "\n" + note
+	}
+	header += fmt.Sprintf("\n[Call tail_file again with since_offset=%d to read only new content.]", end)
+
+	logger.DebugCF("tool", "TailFileTool execution completed successfully",
+		map[string]any{
+			"path":  path,
+			"lines": shown,
+			"start": start,
+			"end":   end,
+		})
+
+	if shown == 0 {
+		return NewToolResult(header + "\n\n[no new content]")
+	}
+	return NewToolResult(header + "\n\n" + string(data))
+}
+
+// lastLines returns the last n lines of data and how many lines that is. A
+// trailing newline does not start an extra empty line.
+func lastLines(data []byte, n int64) ([]byte, int64) {
+	body := bytes.TrimSuffix(data, []byte("\n"))
+	if len(body) == 0 {
+		return nil, 0
+	}
+	cut := len(body)
+	for count := int64(1); ; count++ {
+		i := bytes.LastIndexByte(body[:cut], '\n')
+		if i < 0 {
+			return data, count
+		}
+		if count == n {
+			return data[i+1:], count
+		}
+		cut = i
+	}
+}
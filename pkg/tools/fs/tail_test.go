@@ -0,0 +1,85 @@
+package fstools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTailTestFile(t *testing.T, content string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return dir, path
+}
+
+func TestTailFileTool_LastLines(t *testing.T) {
+	dir, path := writeTailTestFile(t, "one\ntwo\nthree\nfour\n")
+	tool := NewTailFileTool(dir, true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": path, "lines": 2})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "showing: 2 lines")
+	assert.Contains(t, result.ForLLM, "since_offset=19")
+	assert.True(t, strings.HasSuffix(result.ForLLM, "\n\nthree\nfour\n"), result.ForLLM)
+}
+
+func TestTailFileTool_FollowsNewContent(t *testing.T) {
+	dir, path := writeTailTestFile(t, "started\n")
+	tool := NewTailFileTool(dir, true, 0)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("request 1\nrequest 2\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	result := tool.Execute(context.Background(), map[string]any{"path": path, "since_offset": 8, "lines": 1})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.True(t, strings.HasSuffix(result.ForLLM, "\n\nrequest 1\nrequest 2\n"), result.ForLLM)
+
+	result = tool.Execute(context.Background(), map[string]any{"path": path, "since_offset": 28})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "[no new content]")
+}
+
+func TestTailFileTool_RotatedFileRestartsFromBeginning(t *testing.T) {
+	dir, path := writeTailTestFile(t, "fresh\n")
+	tool := NewTailFileTool(dir, true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": path, "since_offset": 500})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "truncated or rotated")
+	assert.True(t, strings.HasSuffix(result.ForLLM, "\n\nfresh\n"), result.ForLLM)
+}
+
+func TestTailFileTool_ReadBudgetDropsPartialLine(t *testing.T) {
+	dir, path := writeTailTestFile(t, "aaaaaaaaaa\nbbbb\ncccc\n")
+	tool := NewTailFileTool(dir, true, 12)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": path, "lines": 10})
+
+	require.False(t, result.IsError, result.ForLLM)
+	assert.True(t, strings.HasSuffix(result.ForLLM, "\n\nbbbb\ncccc\n"), result.ForLLM)
+	assert.NotContains(t, result.ForLLM, "a\n")
+}
+
+func TestTailFileTool_RejectsBinaryAndEscapes(t *testing.T) {
+	dir, path := writeTailTestFile(t, "ELF\x00\x01\x02")
+	tool := NewTailFileTool(dir, true, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": path})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "binary")
+
+	result = tool.Execute(context.Background(), map[string]any{"path": filepath.Join(dir, "..", "outside.log")})
+	assert.True(t, result.IsError)
+}
@@ -14,6 +14,9 @@ type (
 	ListDirTool       = fstools.ListDirTool
 	EditFileTool      = fstools.EditFileTool
 	AppendFileTool    = fstools.AppendFileTool
+	TailFileTool      = fstools.TailFileTool
+	GrepTool          = fstools.GrepTool
+	DiffTool          = fstools.DiffTool
 	LoadImageTool     = fstools.LoadImageTool
 	SendFileTool      = fstools.SendFileTool
 )
@@ -47,6 +50,28 @@ func NewReadFileLinesTool(
 	return fstools.NewReadFileLinesTool(workspace, restrict, maxReadFileSize, allowPaths...)
 }
 
+func NewTailFileTool(
+	workspace string,
+	restrict bool,
+	maxReadFileSize int,
+	allowPaths ...[]*regexp.Regexp,
+) *TailFileTool {
+	return fstools.NewTailFileTool(workspace, restrict, maxReadFileSize, allowPaths...)
+}
+
+func NewGrepTool(workspace string, maxReadFileSize int) *GrepTool {
+	return fstools.NewGrepTool(workspace, maxReadFileSize)
+}
+
+func NewDiffTool(
+	workspace string,
+	restrict bool,
+	maxReadFileSize int,
+	allowPaths ...[]*regexp.Regexp,
+) *DiffTool {
+	return fstools.NewDiffTool(workspace, restrict, maxReadFileSize, allowPaths...)
+}
+
 func NewWriteFileTool(
 	workspace string,
 	restrict bool,
@@ -0,0 +1,302 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	httpRequestTimeout          = 30 * time.Second
+	httpRequestMaxTimeout       = 120 * time.Second
+	httpRequestMaxResponseBytes = 1 << 20
+	httpRequestMaxBodyBytes     = 1 << 20
+)
+
+var httpRequestMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// HTTPRequestToolOptions configures NewHTTPRequestTool. Zero values pick the
+// defaults.
+type HTTPRequestToolOptions struct {
+	Proxy                string
+	PrivateHostWhitelist []string
+	MaxResponseBytes     int64
+	MaxChars             int
+}
+
+// HTTPRequestTool makes arbitrary HTTP API calls for the agent. Unlike
+// web_fetch it sends any method, headers and body, and reports the raw
+// status and headers instead of extracting readable text. It shares
+// web_fetch's SSRF protection: private and local hosts are refused unless
+// whitelisted, both before the request and at connect time.
+type HTTPRequestTool struct {
+	client           *http.Client
+	whitelist        *privateHostWhitelist
+	maxResponseBytes int64
+	maxChars         int
+}
+
+func NewHTTPRequestTool(opts HTTPRequestToolOptions) (*HTTPRequestTool, error) {
+	whitelist, err := newPrivateHostWhitelist(opts.PrivateHostWhitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse http_request private host whitelist: %w", err)
+	}
+	// The per-call context enforces the timeout; the client limit is only a
+	// backstop for the longest allowed call.
+	client, err := utils.CreateHTTPClient(opts.Proxy, httpRequestMaxTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for http_request: %w", err)
+	}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		dialer := &net.Dialer{
+			Timeout:   15 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		transport.DialContext = newSafeDialContext(dialer, whitelist)
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		if isObviousPrivateHost(req.URL.Hostname(), whitelist) {
+			return fmt.Errorf("redirect target is private or local network host")
+		}
+		allowConfiguredProxyFirstHop(req, client.Transport)
+		return nil
+	}
+
+	maxResponseBytes := opts.MaxResponseBytes
+	if maxResponseBytes <= 0 {
+		maxResponseBytes = httpRequestMaxResponseBytes
+	}
+	maxChars := opts.MaxChars
+	if maxChars <= 0 {
+		maxChars = defaultMaxChars
+	}
+	return &HTTPRequestTool{
+		client:           client,
+		whitelist:        whitelist,
+		maxResponseBytes: maxResponseBytes,
+		maxChars:         maxChars,
+	}, nil
+}
+
+func (t *HTTPRequestTool) Name() string {
+	return "http_request"
+}
+
+func (t *HTTPRequestTool) Description() string {
+	return "Call an HTTP API with any method, headers and body, and get back the status, response headers and body (JSON is pretty-printed). Use this for REST APIs; use web_fetch to read web pages."
+}
+
+func (t *HTTPRequestTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"method": map[string]any{
+				"type":        "string",
+				"enum":        httpRequestMethods,
+				"description": "HTTP method (default GET)",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "Full http or https URL, including any query string",
+			},
+			"headers": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Request headers, e.g. {\"Authorization\": \"Bearer ...\"}",
+			},
+			"body": map[string]any{
+				"description": "Request body. A string is sent as-is; an object or array is sent as JSON with Content-Type application/json unless headers set one.",
+			},
+			"timeout_seconds": map[string]any{
+				"type":        "integer",
+				"description": "Request timeout in seconds (default 30, max 120)",
+				"minimum":     1.0,
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+
+// ConfirmationPreview implements ConfirmableTool. Reads are harmless; any
+// other method may change state on the remote side.
+func (t *HTTPRequestTool) ConfirmationPreview(args map[string]any) string {
+	method := httpRequestMethod(args)
+	if method == "GET" || method == "HEAD" || method == "OPTIONS" {
+		return ""
+	}
+	urlStr, _ := args["url"].(string)
+	body, _, _ := httpRequestBody(args["body"])
+	return fmt.Sprintf("%s %s with a %d-byte body.", method, urlStr, len(body))
+}
+
+func httpRequestMethod(args map[string]any) string {
+	method, _ := args["method"].(string)
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		return "GET"
+	}
+	return method
+}
+
+// httpRequestBody encodes the body argument. It reports whether the body
+// was encoded from structured JSON.
+func httpRequestBody(raw any) ([]byte, bool, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, false, nil
+	case string:
+		return []byte(v), false, nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, false, fmt.Errorf("body cannot be encoded as JSON: %w", err)
+		}
+		return data, true, nil
+	}
+}
+
+func (t *HTTPRequestTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	method := httpRequestMethod(args)
+	if !slices.Contains(httpRequestMethods, method) {
+		return ErrorResult(fmt.Sprintf("unsupported method %q; use one of %s", method, strings.Join(httpRequestMethods, ", ")))
+	}
+
+	urlStr, _ := args["url"].(string)
+	if urlStr == "" {
+		return ErrorResult("url is required")
+	}
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid URL: %v", err))
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return ErrorResult("only http/https URLs are allowed")
+	}
+	if parsedURL.Host == "" {
+		return ErrorResult("missing domain in URL")
+	}
+	// Pre-flight check only; newSafeDialContext enforces it at connect time.
+	if isObviousPrivateHost(parsedURL.Hostname(), t.whitelist) {
+		return ErrorResult("requests to private or local network hosts are not allowed")
+	}
+
+	body, isJSON, err := httpRequestBody(args["body"])
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if len(body) > httpRequestMaxBodyBytes {
+		return ErrorResult(fmt.Sprintf("body is %d bytes; the limit is %d", len(body), httpRequestMaxBodyBytes))
+	}
+
+	timeout := httpRequestTimeout
+	if secs, ok := args["timeout_seconds"].(float64); ok && secs >= 1 {
+		timeout = min(time.Duration(secs)*time.Second, httpRequestMaxTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, bodyReader)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to create request: %v", err))
+	}
+	allowConfiguredProxyFirstHop(req, t.client.Transport)
+	req.Header.Set("User-Agent", fmt.Sprintf(userAgentHonest, config.Version))
+	if headers, ok := args["headers"].(map[string]any); ok {
+		for name, value := range headers {
+			s, ok := value.(string)
+			if !ok {
+				return ErrorResult(fmt.Sprintf("header %q must be a string", name))
+			}
+			req.Header.Set(name, s)
+		}
+	}
+	if isJSON && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return ErrorResult(fmt.Sprintf("request timed out after %s", timeout))
+		}
+		return ErrorResult(fmt.Sprintf("request failed: %v", err))
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, t.maxResponseBytes+1))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read response: %v", err))
+	}
+	elapsed := time.Since(start)
+	sizeCapped := int64(len(respBody)) > t.maxResponseBytes
+	if sizeCapped {
+		respBody = respBody[:t.maxResponseBytes]
+	}
+
+	text := formatHTTPResponseBody(resp.Header.Get("Content-Type"), respBody, sizeCapped)
+	charCapped := utf8.RuneCountInString(text) > t.maxChars
+	if charCapped {
+		text = string([]rune(text)[:t.maxChars])
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s\n", method, urlStr)
+	fmt.Fprintf(&sb, "HTTP %s (%d ms)\n", resp.Status, elapsed.Milliseconds())
+	for _, name := range slices.Sorted(maps.Keys(resp.Header)) {
+		value := strings.Join(resp.Header.Values(name), ", ")
+		if strings.EqualFold(name, "Set-Cookie") {
+			value = "[redacted]"
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", name, value)
+	}
+	sb.WriteString("\n")
+	sb.WriteString(text)
+	switch {
+	case sizeCapped:
+		fmt.Fprintf(&sb, "\n[Response truncated at %d bytes]", t.maxResponseBytes)
+	case charCapped:
+		sb.WriteString("\n[Content truncated due to size limit]")
+	}
+
+	// Error statuses are still successful calls: the model needs to read them.
+	return &ToolResult{
+		ForLLM:  sb.String(),
+		ForUser: fmt.Sprintf("%s %s → %s", method, urlStr, resp.Status),
+	}
+}
+
+// formatHTTPResponseBody pretty-prints complete JSON bodies and returns
+// anything else as text.
+func formatHTTPResponseBody(contentType string, body []byte, truncated bool) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	isJSON := mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+	if isJSON && !truncated {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "", "  "); err == nil {
+			return pretty.String()
+		}
+	}
+	return string(body)
+}
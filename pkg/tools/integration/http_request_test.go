@@ -0,0 +1,141 @@
+package integrationtools
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func newTestHTTPRequestTool(t *testing.T, opts HTTPRequestToolOptions) *HTTPRequestTool {
+	t.Helper()
+	opts.PrivateHostWhitelist = append(opts.PrivateHostWhitelist, "127.0.0.1")
+	tool, err := NewHTTPRequestTool(opts)
+	if err != nil {
+		t.Fatalf("NewHTTPRequestTool() error = %v", err)
+	}
+	return tool
+}
+
+func TestHTTPRequestTool_SendsJSONBodyAndFormatsResponse(t *testing.T) {
+	var gotMethod, gotAuth, gotContentType, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotAuth = r.Header.Get("Authorization")
+		gotContentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":7,"tags":["a"]}`))
+	}))
+	defer server.Close()
+
+	tool := newTestHTTPRequestTool(t, HTTPRequestToolOptions{})
+	result := tool.Execute(context.Background(), map[string]any{
+		"method":  "post",
+		"url":     server.URL + "/items",
+		"headers": map[string]any{"Authorization": "Bearer tok"},
+		"body":    map[string]any{"name": "widget"},
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+
+	if gotMethod != http.MethodPost || gotAuth != "Bearer tok" || gotContentType != "application/json" {
+		t.Errorf("request = %s auth=%q content-type=%q", gotMethod, gotAuth, gotContentType)
+	}
+	if gotBody != `{"name":"widget"}` {
+		t.Errorf("request body = %q", gotBody)
+	}
+	for _, want := range []string{
+		"HTTP 201 Created",
+		"Content-Type: application/json",
+		"Set-Cookie: [redacted]",
+		"{\n  \"id\": 7,\n  \"tags\": [\n    \"a\"\n  ]\n}",
+	} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("result missing %q:\n%s", want, result.ForLLM)
+		}
+	}
+	if strings.Contains(result.ForLLM, "secret") {
+		t.Errorf("result leaks cookie value:\n%s", result.ForLLM)
+	}
+}
+
+func TestHTTPRequestTool_TruncatesLargeResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":"` + strings.Repeat("x", 500) + `"}`))
+	}))
+	defer server.Close()
+
+	tool := newTestHTTPRequestTool(t, HTTPRequestToolOptions{MaxResponseBytes: 100})
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "[Response truncated at 100 bytes]") {
+		t.Fatalf("expected truncation marker:\n%s", result.ForLLM)
+	}
+}
+
+func TestHTTPRequestTool_TruncatesOnRuneBoundary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(strings.Repeat("é", 50)))
+	}))
+	defer server.Close()
+
+	tool := newTestHTTPRequestTool(t, HTTPRequestToolOptions{MaxChars: 15})
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if !utf8.ValidString(result.ForLLM) || !strings.Contains(result.ForLLM, strings.Repeat("é", 15)+"\n") {
+		t.Fatalf("expected 15 whole characters:\n%q", result.ForLLM)
+	}
+}
+
+func TestHTTPRequestTool_BlocksPrivateHostsByDefault(t *testing.T) {
+	tool, err := NewHTTPRequestTool(HTTPRequestToolOptions{})
+	if err != nil {
+		t.Fatalf("NewHTTPRequestTool() error = %v", err)
+	}
+	for _, target := range []string{"http://127.0.0.1:8080/", "http://localhost/admin", "http://169.254.169.254/latest/meta-data"} {
+		result := tool.Execute(context.Background(), map[string]any{"url": target})
+		if !result.IsError || !strings.Contains(result.ForLLM, "private or local") {
+			t.Errorf("%s: expected private host error, got %q", target, result.ForLLM)
+		}
+	}
+}
+
+func TestHTTPRequestTool_RejectsBadInput(t *testing.T) {
+	tool := newTestHTTPRequestTool(t, HTTPRequestToolOptions{})
+	tests := []map[string]any{
+		{},
+		{"url": "ftp://example.com/file"},
+		{"url": "https://example.com", "method": "TRACE"},
+		{"url": "https://example.com", "headers": map[string]any{"X-Count": 3.0}},
+	}
+	for _, args := range tests {
+		if result := tool.Execute(context.Background(), args); !result.IsError {
+			t.Errorf("Execute(%v) succeeded, want error", args)
+		}
+	}
+}
+
+func TestHTTPRequestTool_ConfirmationPreview(t *testing.T) {
+	tool := newTestHTTPRequestTool(t, HTTPRequestToolOptions{})
+	if got := tool.ConfirmationPreview(map[string]any{"url": "https://api.example.com"}); got != "" {
+		t.Errorf("GET preview = %q, want none", got)
+	}
+	got := tool.ConfirmationPreview(map[string]any{"method": "DELETE", "url": "https://api.example.com/items/7"})
+	if got != "DELETE https://api.example.com/items/7 with a 0-byte body." {
+		t.Errorf("DELETE preview = %q", got)
+	}
+}
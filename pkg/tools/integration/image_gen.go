@@ -0,0 +1,419 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	imageGenTimeout       = 120 * time.Second
+	imageGenMaxImageBytes = 20 << 20
+	imageGenRateWindow    = time.Hour
+	imageGenDir           = "images"
+
+	defaultOpenAIImageBase = "https://api.openai.com/v1"
+	defaultGeminiImageBase = "https://generativelanguage.googleapis.com/v1beta"
+)
+
+// ImageGenToolOptions configures NewImageGenTool. Protocol "gemini" calls the
+// Gemini generateContent API; anything else calls an OpenAI-compatible
+// /images/generations endpoint.
+type ImageGenToolOptions struct {
+	Protocol          string
+	APIBase           string
+	APIKey            string
+	Model             string
+	Proxy             string
+	Size              string
+	Workspace         string
+	MaxPerUserPerHour int // 0 disables the limit
+}
+
+// ImageGenTool generates an image from a prompt, saves it under the
+// workspace's images directory, and attaches it to the reply when the
+// current channel can take media.
+type ImageGenTool struct {
+	opts       ImageGenToolOptions
+	client     *http.Client
+	mediaStore media.MediaStore
+
+	mu     sync.Mutex
+	recent map[string][]time.Time // per-user generation times within the window
+	now    func() time.Time
+}
+
+func NewImageGenTool(opts ImageGenToolOptions, store media.MediaStore) (*ImageGenTool, error) {
+	if strings.TrimSpace(opts.APIKey) == "" {
+		return nil, fmt.Errorf("image_gen model has no API key")
+	}
+	if strings.TrimSpace(opts.Model) == "" {
+		return nil, fmt.Errorf("image_gen model is not set")
+	}
+	if opts.APIBase == "" {
+		opts.APIBase = defaultOpenAIImageBase
+		if opts.Protocol == "gemini" {
+			opts.APIBase = defaultGeminiImageBase
+		}
+	}
+	opts.APIBase = strings.TrimSuffix(opts.APIBase, "/")
+	client, err := utils.CreateHTTPClient(opts.Proxy, imageGenTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for image_gen: %w", err)
+	}
+	return &ImageGenTool{
+		opts:       opts,
+		client:     client,
+		mediaStore: store,
+		recent:     make(map[string][]time.Time),
+		now:        time.Now,
+	}, nil
+}
+
+func (t *ImageGenTool) Name() string { return "image_gen" }
+
+func (t *ImageGenTool) Description() string {
+	return "Generate an image from a text description, save it in the workspace and send it to the user. Each image costs money, so call it once per request unless the user asks for variations."
+}
+
+func (t *ImageGenTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"prompt": map[string]any{
+				"type":        "string",
+				"description": "Detailed description of the image to generate, in English.",
+			},
+			"size": map[string]any{
+				"type":        "string",
+				"description": "Optional image size such as 1024x1024. Ignored by models that do not support it.",
+			},
+		},
+		"required": []string{"prompt"},
+	}
+}
+
+func (t *ImageGenTool) SetMediaStore(store media.MediaStore) {
+	t.mediaStore = store
+}
+
+func (t *ImageGenTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	prompt, _ := args["prompt"].(string)
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return ErrorResult("prompt is required")
+	}
+	size, _ := args["size"].(string)
+	if size = strings.TrimSpace(size); size == "" {
+		size = t.opts.Size
+	}
+
+	channel := ToolChannel(ctx)
+	chatID := ToolChatID(ctx)
+	refund, wait, ok := t.allow(imageGenUserKey(channel, chatID, ToolSenderID(ctx)))
+	if !ok {
+		return ErrorResult(fmt.Sprintf(
+			"image generation limit reached (%d per hour); try again in %s",
+			t.opts.MaxPerUserPerHour, wait.Round(time.Minute),
+		))
+	}
+
+	var (
+		data     []byte
+		mimeType string
+		err      error
+	)
+	if t.opts.Protocol == "gemini" {
+		data, mimeType, err = t.generateGemini(ctx, prompt)
+	} else {
+		data, mimeType, err = t.generateOpenAI(ctx, prompt, size)
+	}
+	if err != nil {
+		// No image was produced, so the attempt does not count.
+		refund()
+		return ErrorResult(fmt.Sprintf("image generation failed: %v", err)).WithError(err)
+	}
+
+	relPath, err := t.save(prompt, data, mimeType)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	absPath := filepath.Join(t.opts.Workspace, relPath)
+
+	if t.mediaStore == nil || channel == "" || chatID == "" {
+		return NewToolResult(fmt.Sprintf("Image saved to %s (%d bytes). It was not sent: no chat channel is available.", relPath, len(data)))
+	}
+	ref, err := t.mediaStore.Store(absPath, media.MediaMeta{
+		Filename:      filepath.Base(relPath),
+		ContentType:   mimeType,
+		Source:        "tool:image_gen",
+		CleanupPolicy: media.CleanupPolicyForgetOnly,
+	}, fmt.Sprintf("tool:image_gen:%s:%s", channel, chatID))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("image saved to %s but could not be attached: %v", relPath, err))
+	}
+	return MediaResult(fmt.Sprintf("Image saved to %s and sent to the user.", relPath), []string{ref}).
+		WithResponseHandled()
+}
+
+// allow reserves a generation for key and reports whether it is within the
+// hourly limit. When it is not, it returns how long until a slot frees up.
+// The reservation holds the slot while the image is generated, so parallel
+// calls cannot overrun the limit; refund gives it back when generation
+// fails.
+func (t *ImageGenTool) allow(key string) (refund func(), wait time.Duration, ok bool) {
+	limit := t.opts.MaxPerUserPerHour
+	if limit <= 0 {
+		return func() {}, 0, true
+	}
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(now)
+	times := t.recent[key]
+	if len(times) >= limit {
+		return nil, imageGenRateWindow - now.Sub(times[0]), false
+	}
+	t.recent[key] = append(times, now)
+	return func() { t.refund(key, now) }, 0, true
+}
+
+// prune drops generations that left the window, and users with none left.
+// The caller holds t.mu.
+func (t *ImageGenTool) prune(now time.Time) {
+	for key, times := range t.recent {
+		for len(times) > 0 && now.Sub(times[0]) >= imageGenRateWindow {
+			times = times[1:]
+		}
+		if len(times) == 0 {
+			delete(t.recent, key)
+		} else {
+			t.recent[key] = times
+		}
+	}
+}
+
+func (t *ImageGenTool) refund(key string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	times := t.recent[key]
+	if i := slices.IndexFunc(times, at.Equal); i >= 0 {
+		times = slices.Delete(times, i, i+1)
+	}
+	if len(times) == 0 {
+		delete(t.recent, key)
+	} else {
+		t.recent[key] = times
+	}
+}
+
+// imageGenUserKey identifies who the rate limit applies to. Without a sender
+// ID, such as in the CLI, the whole chat shares one budget.
+func imageGenUserKey(channel, chatID, senderID string) string {
+	if senderID != "" {
+		return channel + ":" + senderID
+	}
+	return channel + ":chat:" + chatID
+}
+
+func (t *ImageGenTool) generateOpenAI(ctx context.Context, prompt, size string) ([]byte, string, error) {
+	reqBody := map[string]any{
+		"model":  t.opts.Model,
+		"prompt": prompt,
+		"n":      1,
+	}
+	if size != "" {
+		reqBody["size"] = size
+	}
+	// gpt-image models always return base64 and reject response_format.
+	if !strings.HasPrefix(t.opts.Model, "gpt-image") {
+		reqBody["response_format"] = "b64_json"
+	}
+	var resp struct {
+		Data []struct {
+			B64JSON string `json:"b64_json"`
+			URL     string `json:"url"`
+		} `json:"data"`
+	}
+	headers := map[string]string{"Authorization": "Bearer " + t.opts.APIKey}
+	if err := t.postJSON(ctx, t.opts.APIBase+"/images/generations", headers, reqBody, &resp); err != nil {
+		return nil, "", err
+	}
+	if len(resp.Data) == 0 {
+		return nil, "", fmt.Errorf("response contained no image")
+	}
+	if resp.Data[0].B64JSON != "" {
+		data, err := base64.StdEncoding.DecodeString(resp.Data[0].B64JSON)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid base64 image: %w", err)
+		}
+		return data, http.DetectContentType(data), nil
+	}
+	if resp.Data[0].URL != "" {
+		return t.download(ctx, resp.Data[0].URL)
+	}
+	return nil, "", fmt.Errorf("response contained no image")
+}
+
+func (t *ImageGenTool) generateGemini(ctx context.Context, prompt string) ([]byte, string, error) {
+	reqBody := map[string]any{
+		"contents": []map[string]any{
+			{"parts": []map[string]any{{"text": prompt}}},
+		},
+		"generationConfig": map[string]any{
+			"responseModalities": []string{"TEXT", "IMAGE"},
+		},
+	}
+	var resp struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					InlineData *struct {
+						MimeType string `json:"mimeType"`
+						Data     string `json:"data"`
+					} `json:"inlineData"`
+				} `json:"parts"`
+			} `json:"content"`
+		} `json:"candidates"`
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", t.opts.APIBase, t.opts.Model)
+	headers := map[string]string{"x-goog-api-key": t.opts.APIKey}
+	if err := t.postJSON(ctx, endpoint, headers, reqBody, &resp); err != nil {
+		return nil, "", err
+	}
+	for _, c := range resp.Candidates {
+		for _, part := range c.Content.Parts {
+			if part.InlineData == nil || part.InlineData.Data == "" {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid base64 image: %w", err)
+			}
+			mimeType := part.InlineData.MimeType
+			if mimeType == "" {
+				mimeType = http.DetectContentType(data)
+			}
+			return data, mimeType, nil
+		}
+	}
+	return nil, "", fmt.Errorf("response contained no image")
+}
+
+func (t *ImageGenTool) postJSON(ctx context.Context, endpoint string, headers map[string]string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Base64 inflates images by a third; leave room for the JSON around it.
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, imageGenMaxImageBytes*2))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, utils.Truncate(string(respBody), 300))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func (t *ImageGenTool) download(ctx context.Context, imageURL string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, imageGenMaxImageBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image: %w", err)
+	}
+	if len(data) > imageGenMaxImageBytes {
+		return nil, "", fmt.Errorf("image exceeds %d bytes", imageGenMaxImageBytes)
+	}
+	return data, http.DetectContentType(data), nil
+}
+
+// save writes the image under <workspace>/images and returns its path
+// relative to the workspace.
+func (t *ImageGenTool) save(prompt string, data []byte, mimeType string) (string, error) {
+	if len(data) > imageGenMaxImageBytes {
+		return "", fmt.Errorf("image exceeds %d bytes", imageGenMaxImageBytes)
+	}
+	if err := os.MkdirAll(filepath.Join(t.opts.Workspace, imageGenDir), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create images directory: %w", err)
+	}
+	ext := ".png"
+	switch mimeType {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/webp":
+		ext = ".webp"
+	}
+	name := t.now().Format("20060102-150405") + "-" + imageGenSlug(prompt) + ext
+	relPath := filepath.Join(imageGenDir, name)
+	if err := os.WriteFile(filepath.Join(t.opts.Workspace, relPath), data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to save image: %w", err)
+	}
+	return relPath, nil
+}
+
+// imageGenSlug turns the start of a prompt into a short file-name-safe tag.
+func imageGenSlug(prompt string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(prompt) {
+		if b.Len() >= 40 {
+			break
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "image"
+	}
+	return slug
+}
@@ -0,0 +1,224 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+// pngHeader is enough for http.DetectContentType to report image/png.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestImageGenTool_OpenAISavesAndAttaches(t *testing.T) {
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/images/generations" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"b64_json": base64.StdEncoding.EncodeToString(pngHeader)}},
+		})
+	}))
+	defer server.Close()
+
+	workspace := t.TempDir()
+	store := media.NewFileMediaStore()
+	tool, err := NewImageGenTool(ImageGenToolOptions{
+		APIBase:   server.URL + "/v1",
+		APIKey:    "sk-test",
+		Model:     "dall-e-3",
+		Size:      "1024x1024",
+		Workspace: workspace,
+	}, store)
+	if err != nil {
+		t.Fatalf("NewImageGenTool() error = %v", err)
+	}
+
+	ctx := WithToolContext(context.Background(), "telegram", "42")
+	result := tool.Execute(ctx, map[string]any{"prompt": "A red fox, sitting in snow!"})
+	if result.IsError {
+		t.Fatalf("Execute() error = %s", result.ForLLM)
+	}
+	if gotBody["model"] != "dall-e-3" || gotBody["size"] != "1024x1024" || gotBody["response_format"] != "b64_json" {
+		t.Fatalf("request body = %v", gotBody)
+	}
+	if len(result.Media) != 1 || !result.ResponseHandled {
+		t.Fatalf("result = %+v, want one handled attachment", result)
+	}
+	_, meta, err := store.ResolveWithMeta(result.Media[0])
+	if err != nil {
+		t.Fatalf("ResolveWithMeta() error = %v", err)
+	}
+	if meta.ContentType != "image/png" || !strings.HasSuffix(meta.Filename, "-a-red-fox-sitting-in-snow.png") {
+		t.Fatalf("meta = %+v", meta)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(workspace, imageGenDir, meta.Filename))
+	if err != nil {
+		t.Fatalf("saved image: %v", err)
+	}
+	if string(saved) != string(pngHeader) {
+		t.Fatal("saved image does not match the generated bytes")
+	}
+	if !strings.Contains(result.ForLLM, filepath.Join(imageGenDir, meta.Filename)) {
+		t.Fatalf("ForLLM = %q, want the saved path", result.ForLLM)
+	}
+}
+
+func TestImageGenTool_Gemini(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-2.5-flash-image:generateContent" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "g-key" {
+			t.Errorf("x-goog-api-key = %q", got)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []map[string]any{{
+				"content": map[string]any{"parts": []map[string]any{
+					{"text": "Here you go"},
+					{"inlineData": map[string]any{
+						"mimeType": "image/jpeg",
+						"data":     base64.StdEncoding.EncodeToString([]byte("jpeg-bytes")),
+					}},
+				}},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	workspace := t.TempDir()
+	tool, err := NewImageGenTool(ImageGenToolOptions{
+		Protocol:  "gemini",
+		APIBase:   server.URL + "/v1beta",
+		APIKey:    "g-key",
+		Model:     "gemini-2.5-flash-image",
+		Workspace: workspace,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewImageGenTool() error = %v", err)
+	}
+
+	// Without a media store or chat the image is only saved.
+	result := tool.Execute(context.Background(), map[string]any{"prompt": "cat"})
+	if result.IsError || len(result.Media) != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+	matches, _ := filepath.Glob(filepath.Join(workspace, imageGenDir, "*-cat.jpg"))
+	if len(matches) != 1 {
+		t.Fatalf("saved files = %v, want one *-cat.jpg", matches)
+	}
+}
+
+func TestImageGenTool_APIErrorIsReported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"content policy"}}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	tool, err := NewImageGenTool(ImageGenToolOptions{
+		APIBase: server.URL, APIKey: "k", Model: "gpt-image-1", Workspace: t.TempDir(),
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewImageGenTool() error = %v", err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{"prompt": "x"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "content policy") {
+		t.Fatalf("result = %+v, want API error", result)
+	}
+}
+
+func TestImageGenTool_FailuresDoNotCountTowardLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, `{"error":{"message":"overloaded"}}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tool, err := NewImageGenTool(ImageGenToolOptions{
+		APIBase: server.URL, APIKey: "k", Model: "gpt-image-1", Workspace: t.TempDir(), MaxPerUserPerHour: 1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewImageGenTool() error = %v", err)
+	}
+	for range 2 {
+		result := tool.Execute(context.Background(), map[string]any{"prompt": "x"})
+		if !result.IsError || strings.Contains(result.ForLLM, "limit reached") {
+			t.Fatalf("result = %q, want the API error rather than the limit", result.ForLLM)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("API calls = %d, want 2", calls.Load())
+	}
+	if len(tool.recent) != 0 {
+		t.Fatalf("failed generations were recorded: %v", tool.recent)
+	}
+}
+
+func TestImageGenTool_RateLimitPerUser(t *testing.T) {
+	tool, err := NewImageGenTool(ImageGenToolOptions{APIKey: "k", Model: "m", MaxPerUserPerHour: 2}, nil)
+	if err != nil {
+		t.Fatalf("NewImageGenTool() error = %v", err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tool.now = func() time.Time { return now }
+
+	alice := imageGenUserKey("telegram", "group", "alice")
+	bob := imageGenUserKey("telegram", "group", "bob")
+	for i := range 2 {
+		if _, _, ok := tool.allow(alice); !ok {
+			t.Fatalf("call %d refused", i+1)
+		}
+		now = now.Add(10 * time.Minute)
+	}
+	_, wait, ok := tool.allow(alice)
+	if ok {
+		t.Fatal("third call within the hour was allowed")
+	}
+	if wait != 40*time.Minute {
+		t.Fatalf("wait = %s, want 40m", wait)
+	}
+	if _, _, ok := tool.allow(bob); !ok {
+		t.Fatal("another user shares alice's limit")
+	}
+
+	now = now.Add(40 * time.Minute)
+	if _, _, ok := tool.allow(alice); !ok {
+		t.Fatal("call refused after the oldest one left the window")
+	}
+
+	now = now.Add(2 * time.Hour)
+	tool.allow(alice)
+	if _, kept := tool.recent[bob]; kept || len(tool.recent) != 1 {
+		t.Fatalf("users idle for the whole window were kept: %v", tool.recent)
+	}
+}
+
+func TestImageGenSlug(t *testing.T) {
+	tests := map[string]string{
+		"A red fox, sitting in snow!": "a-red-fox-sitting-in-snow",
+		"  ":                          "image",
+		"日本の猫":                        "image",
+		strings.Repeat("abc ", 20):    "abc-abc-abc-abc-abc-abc-abc-abc-abc-abc",
+	}
+	for prompt, want := range tests {
+		if got := imageGenSlug(prompt); got != want {
+			t.Errorf("imageGenSlug(%q) = %q, want %q", prompt, got, want)
+		}
+	}
+}
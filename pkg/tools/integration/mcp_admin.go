@@ -0,0 +1,142 @@
+package integrationtools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	picomcp "github.com/sipeed/picoclaw/pkg/mcp"
+)
+
+// MCPAdmin is the runtime control over MCP servers that MCPAdminTool drives.
+type MCPAdmin interface {
+	ServerStatus() ([]picomcp.ServerStatus, error)
+	RestartServer(ctx context.Context, name string) error
+	ReloadServers(ctx context.Context) (picomcp.ReloadResult, error)
+}
+
+// MCPAdminTool lists, restarts and reloads MCP servers without restarting
+// PicoClaw.
+type MCPAdminTool struct {
+	admin MCPAdmin
+}
+
+func NewMCPAdminTool(admin MCPAdmin) *MCPAdminTool {
+	return &MCPAdminTool{admin: admin}
+}
+
+func (t *MCPAdminTool) Name() string {
+	return "mcp_admin"
+}
+
+func (t *MCPAdminTool) Description() string {
+	return "Manage MCP servers at runtime. 'list' shows each server's state, tool count and recent stderr; " +
+		"'restart' reconnects one server; 'reload' re-reads the MCP config, connecting added servers and " +
+		"disconnecting removed ones."
+}
+
+func (t *MCPAdminTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "restart", "reload"},
+				"description": "What to do",
+			},
+			"server": map[string]any{
+				"type":        "string",
+				"description": "Server name, required for restart",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *MCPAdminTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	server, _ := args["server"].(string)
+	server = strings.TrimSpace(server)
+
+	switch action {
+	case "list":
+		return t.list()
+	case "restart":
+		if server == "" {
+			return ErrorResult("server is required for restart")
+		}
+		if err := t.admin.RestartServer(ctx, server); err != nil {
+			return ErrorResult(fmt.Sprintf("restarting MCP server %s: %v", server, err)).WithError(err)
+		}
+		return t.list()
+	case "reload":
+		result, err := t.admin.ReloadServers(ctx)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("reloading MCP servers: %v", err)).WithError(err)
+		}
+		status := t.list()
+		if status.IsError {
+			return status
+		}
+		return NewToolResult(formatReloadResult(result) + "\n\n" + status.ForLLM)
+	default:
+		return ErrorResult("action must be one of: list, restart, reload")
+	}
+}
+
+func (t *MCPAdminTool) list() *ToolResult {
+	statuses, err := t.admin.ServerStatus()
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	if len(statuses) == 0 {
+		return NewToolResult("No MCP servers configured.")
+	}
+
+	var b strings.Builder
+	for i, st := range statuses {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if st.Connected {
+			fmt.Fprintf(&b, "%s (%s): connected, %d tools", st.Name, st.Transport, st.ToolCount)
+			if st.ProtocolVersion != "" {
+				fmt.Fprintf(&b, ", protocol %s", st.ProtocolVersion)
+			}
+			b.WriteString("\n")
+		} else {
+			fmt.Fprintf(&b, "%s (%s): disconnected\n", st.Name, st.Transport)
+		}
+		if st.LastError != "" {
+			fmt.Fprintf(&b, "  last error: %s\n", st.LastError)
+		}
+		if st.Stderr != "" {
+			b.WriteString("  stderr:\n")
+			for _, line := range strings.Split(st.Stderr, "\n") {
+				b.WriteString("    " + line + "\n")
+			}
+		}
+	}
+	return NewToolResult(strings.TrimRight(b.String(), "\n"))
+}
+
+func formatReloadResult(r picomcp.ReloadResult) string {
+	var parts []string
+	for _, group := range []struct {
+		label string
+		names []string
+	}{
+		{"added", r.Added},
+		{"removed", r.Removed},
+		{"restarted", r.Restarted},
+		{"failed", r.Failed},
+	} {
+		if len(group.names) > 0 {
+			parts = append(parts, group.label+": "+strings.Join(group.names, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		return "MCP config reloaded; no servers changed."
+	}
+	return "MCP config reloaded. " + strings.Join(parts, "; ") + "."
+}
@@ -0,0 +1,94 @@
+package integrationtools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	picomcp "github.com/sipeed/picoclaw/pkg/mcp"
+)
+
+type fakeMCPAdmin struct {
+	statuses  []picomcp.ServerStatus
+	restarted []string
+	reload    picomcp.ReloadResult
+	err       error
+}
+
+func (f *fakeMCPAdmin) ServerStatus() ([]picomcp.ServerStatus, error) {
+	return f.statuses, nil
+}
+
+func (f *fakeMCPAdmin) RestartServer(_ context.Context, name string) error {
+	f.restarted = append(f.restarted, name)
+	return f.err
+}
+
+func (f *fakeMCPAdmin) ReloadServers(context.Context) (picomcp.ReloadResult, error) {
+	return f.reload, f.err
+}
+
+func TestMCPAdminTool_ListShowsStateAndStderr(t *testing.T) {
+	tool := NewMCPAdminTool(&fakeMCPAdmin{statuses: []picomcp.ServerStatus{
+		{Name: "github", Connected: true, Transport: "stdio", ToolCount: 12},
+		{Name: "jira", Transport: "stdio", LastError: "EOF", Stderr: "JIRA_TOKEN is not set"},
+	}})
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "list"})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", result.ForLLM)
+	}
+	for _, want := range []string{
+		"github (stdio): connected, 12 tools",
+		"jira (stdio): disconnected",
+		"last error: EOF",
+		"    JIRA_TOKEN is not set",
+	} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("list output missing %q:\n%s", want, result.ForLLM)
+		}
+	}
+}
+
+func TestMCPAdminTool_Restart(t *testing.T) {
+	admin := &fakeMCPAdmin{}
+	tool := NewMCPAdminTool(admin)
+
+	if result := tool.Execute(context.Background(), map[string]any{"action": "restart"}); !result.IsError {
+		t.Fatal("expected an error when server is missing")
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "restart", "server": " jira "})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", result.ForLLM)
+	}
+	if len(admin.restarted) != 1 || admin.restarted[0] != "jira" {
+		t.Fatalf("restarted = %v, want [jira]", admin.restarted)
+	}
+
+	admin.err = errors.New("exec: not found")
+	result = tool.Execute(context.Background(), map[string]any{"action": "restart", "server": "jira"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "exec: not found") {
+		t.Fatalf("expected restart error to be reported, got: %s", result.ForLLM)
+	}
+}
+
+func TestMCPAdminTool_ReloadSummarizesChanges(t *testing.T) {
+	tool := NewMCPAdminTool(&fakeMCPAdmin{reload: picomcp.ReloadResult{
+		Added:  []string{"linear"},
+		Failed: []string{"jira"},
+	}})
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "reload"})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", result.ForLLM)
+	}
+	if !strings.HasPrefix(result.ForLLM, "MCP config reloaded. added: linear; failed: jira.") {
+		t.Fatalf("unexpected reload output:\n%s", result.ForLLM)
+	}
+
+	if got := formatReloadResult(picomcp.ReloadResult{}); got != "MCP config reloaded; no servers changed." {
+		t.Fatalf("formatReloadResult(empty) = %q", got)
+	}
+}
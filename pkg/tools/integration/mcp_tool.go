@@ -118,6 +118,11 @@ func sanitizeIdentifierComponent(s string) string {
 	return result
 }
 
+// ServerName returns the MCP server the tool belongs to.
+func (t *MCPTool) ServerName() string {
+	return t.serverName
+}
+
 // Name returns the tool name, prefixed with the server name.
 // The total length is capped at 64 characters (OpenAI-compatible API limit).
 // A short hash of the original (unsanitized) server and tool names is appended
@@ -32,6 +32,11 @@ func (t *MessageTool) Name() string {
 	return "message"
 }
 
+// Sequential implements SequentialTool.
+func (t *MessageTool) Sequential() bool {
+	return true
+}
+
 func (t *MessageTool) Description() string {
 	return "Send a message to user on a chat channel. Use this when you want to communicate something."
 }
@@ -0,0 +1,131 @@
+package integrationtools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ModelChoices is what ModelTool's list action reports.
+type ModelChoices struct {
+	Current    string   // model in effect for the session
+	Default    string   // agent's model, used when the session has no choice
+	Configured []string // model_list names
+	Available  []string // model IDs reported by the active provider
+	ListError  string   // why Available is empty, when the provider failed
+}
+
+// ModelSwitcher is the per-session model control that ModelTool drives.
+// The session comes from the tool context.
+type ModelSwitcher interface {
+	ListModels(ctx context.Context) (ModelChoices, error)
+	// SetModel switches the session to model and returns the model it used
+	// before. An empty model goes back to the agent's default.
+	SetModel(ctx context.Context, model string) (string, error)
+}
+
+// ModelTool shows and changes the model used for the current conversation.
+type ModelTool struct {
+	switcher ModelSwitcher
+}
+
+func NewModelTool(switcher ModelSwitcher) *ModelTool {
+	return &ModelTool{switcher: switcher}
+}
+
+func (t *ModelTool) Name() string {
+	return "model"
+}
+
+func (t *ModelTool) Description() string {
+	return "Show or change the model used for this conversation. 'get' shows the current model; " +
+		"'list' shows the configured models and those the active provider offers; 'set' switches to " +
+		"one of them from the next message on, keeping the conversation history; 'reset' goes back " +
+		"to the default model."
+}
+
+func (t *ModelTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"get", "list", "set", "reset"},
+				"description": "What to do",
+			},
+			"model": map[string]any{
+				"type":        "string",
+				"description": "Model to switch to, required for set. A name from 'list'.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ModelTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	model, _ := args["model"].(string)
+	model = strings.TrimSpace(model)
+
+	switch action {
+	case "get":
+		choices, err := t.switcher.ListModels(ctx)
+		if err != nil {
+			return ErrorResult(err.Error()).WithError(err)
+		}
+		if choices.Current == choices.Default {
+			return NewToolResult(fmt.Sprintf("Current model: %s (default)", choices.Current))
+		}
+		return NewToolResult(fmt.Sprintf("Current model: %s (default: %s)", choices.Current, choices.Default))
+	case "list":
+		choices, err := t.switcher.ListModels(ctx)
+		if err != nil {
+			return ErrorResult(err.Error()).WithError(err)
+		}
+		return NewToolResult(formatModelChoices(choices))
+	case "set":
+		if model == "" {
+			return ErrorResult("model is required for set")
+		}
+		return t.set(ctx, model)
+	case "reset":
+		return t.set(ctx, "")
+	default:
+		return ErrorResult("action must be one of: get, list, set, reset")
+	}
+}
+
+func (t *ModelTool) set(ctx context.Context, model string) *ToolResult {
+	previous, err := t.switcher.SetModel(ctx, model)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("switching model: %v", err)).WithError(err)
+	}
+	if model == "" {
+		return NewToolResult(fmt.Sprintf(
+			"Model reset to the default. Previous model: %s. Takes effect from the next message.", previous))
+	}
+	return NewToolResult(fmt.Sprintf(
+		"Model switched from %s to %s. Takes effect from the next message.", previous, model))
+}
+
+func formatModelChoices(c ModelChoices) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Current model: %s\n", c.Current)
+	fmt.Fprintf(&b, "Default model: %s\n", c.Default)
+	if len(c.Configured) > 0 {
+		b.WriteString("\nConfigured models:\n")
+		for _, name := range c.Configured {
+			fmt.Fprintf(&b, "- %s\n", name)
+		}
+	}
+	switch {
+	case len(c.Available) > 0:
+		b.WriteString("\nModels offered by the active provider:\n")
+		for _, id := range c.Available {
+			fmt.Fprintf(&b, "- %s\n", id)
+		}
+	case c.ListError != "":
+		fmt.Fprintf(&b, "\nThe active provider's models could not be listed: %s\n", c.ListError)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,90 @@
+package integrationtools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeModelSwitcher struct {
+	choices ModelChoices
+	set     []string
+	err     error
+}
+
+func (f *fakeModelSwitcher) ListModels(context.Context) (ModelChoices, error) {
+	return f.choices, nil
+}
+
+func (f *fakeModelSwitcher) SetModel(_ context.Context, model string) (string, error) {
+	f.set = append(f.set, model)
+	if f.err != nil {
+		return "", f.err
+	}
+	previous := f.choices.Current
+	f.choices.Current = model
+	if model == "" {
+		f.choices.Current = f.choices.Default
+	}
+	return previous, nil
+}
+
+func TestModelTool_ListShowsConfiguredAndProviderModels(t *testing.T) {
+	tool := NewModelTool(&fakeModelSwitcher{choices: ModelChoices{
+		Current:    "fast",
+		Default:    "smart",
+		Configured: []string{"fast", "smart"},
+		Available:  []string{"gpt-4o", "gpt-4o-mini"},
+	}})
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "list"})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", result.ForLLM)
+	}
+	for _, want := range []string{
+		"Current model: fast",
+		"Default model: smart",
+		"Configured models:\n- fast\n- smart",
+		"active provider:\n- gpt-4o\n- gpt-4o-mini",
+	} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("list output missing %q:\n%s", want, result.ForLLM)
+		}
+	}
+}
+
+func TestModelTool_SetReportsPreviousAndNewModel(t *testing.T) {
+	switcher := &fakeModelSwitcher{choices: ModelChoices{Current: "smart", Default: "smart"}}
+	tool := NewModelTool(switcher)
+
+	if result := tool.Execute(context.Background(), map[string]any{"action": "set"}); !result.IsError {
+		t.Fatal("expected an error when model is missing")
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "set", "model": " fast "})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "from smart to fast") {
+		t.Errorf("set output = %q, want previous and new model", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"action": "reset"})
+	if result.IsError || !strings.Contains(result.ForLLM, "Previous model: fast") {
+		t.Errorf("reset output = %q", result.ForLLM)
+	}
+	if got := strings.Join(switcher.set, ","); got != "fast," {
+		t.Errorf("SetModel calls = %q, want %q", got, "fast,")
+	}
+}
+
+func TestModelTool_SetError(t *testing.T) {
+	err := errors.New(`model "nope" is not available`)
+	tool := NewModelTool(&fakeModelSwitcher{err: err})
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "set", "model": "nope"})
+	if !result.IsError || !errors.Is(result.Err, err) {
+		t.Fatalf("expected the switcher's error, got %+v", result)
+	}
+}
@@ -44,6 +44,10 @@ func ToolMessageID(ctx context.Context) string {
 	return toolshared.ToolMessageID(ctx)
 }
 
+func ToolSenderID(ctx context.Context) string {
+	return toolshared.ToolSenderID(ctx)
+}
+
 func ToolAgentID(ctx context.Context) string {
 	return toolshared.ToolAgentID(ctx)
 }
@@ -273,6 +273,7 @@ func GetPreferredWebSearchLanguage() string {
 
 type BraveSearchProvider struct {
 	keyPool *APIKeyPool
+	baseURL string
 	proxy   string
 	client  *http.Client
 }
@@ -287,8 +288,11 @@ func (p *BraveSearchProvider) Search(
 		return "", errors.New("no API key provided")
 	}
 
-	searchURL := fmt.Sprintf("https://api.search.brave.com/res/v1/web/search?q=%s&count=%d",
-		url.QueryEscape(query), count)
+	baseURL := p.baseURL
+	if baseURL == "" {
+		baseURL = "https://api.search.brave.com/res/v1/web/search"
+	}
+	searchURL := fmt.Sprintf("%s?q=%s&count=%d", baseURL, url.QueryEscape(query), count)
 	if freshness := mapBraveFreshness(rangeCode); freshness != "" {
 		searchURL += "&freshness=" + url.QueryEscape(freshness)
 	}
@@ -1085,6 +1089,7 @@ type WebSearchTool struct {
 type WebSearchToolOptions struct {
 	Provider              string
 	BraveAPIKeys          []string
+	BraveBaseURL          string
 	BraveMaxResults       int
 	BraveEnabled          bool
 	TavilyAPIKeys         []string
@@ -1164,6 +1169,7 @@ func (opts WebSearchToolOptions) providerByName(name string) (SearchProvider, in
 		}
 		return &BraveSearchProvider{
 			keyPool: NewAPIKeyPool(opts.BraveAPIKeys),
+			baseURL: opts.BraveBaseURL,
 			proxy:   opts.Proxy,
 			client:  client,
 		}, maxResults, nil
@@ -1556,7 +1562,7 @@ func (t *WebFetchTool) Parameters() map[string]any {
 			},
 			"maxChars": map[string]any{
 				"type":        "integer",
-				"description": "Maximum characters to extract",
+				"description": "Maximum characters to extract (capped by the configured limit)",
 				"minimum":     100.0,
 			},
 		},
@@ -1590,10 +1596,11 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		return ErrorResult("fetching private or local network hosts is not allowed")
 	}
 
+	// The model may ask for less than the configured limit, never more.
 	maxChars := t.maxChars
 	if mc, ok := args["maxChars"].(float64); ok {
 		if int(mc) > 100 {
-			maxChars = int(mc)
+			maxChars = min(int(mc), t.maxChars)
 		}
 	}
 
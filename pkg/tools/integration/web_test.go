@@ -221,6 +221,37 @@ func TestWebTool_WebFetch_Truncation(t *testing.T) {
 	}
 }
 
+// TestWebTool_WebFetch_MaxCharsArgCappedByConfig verifies the model cannot raise the configured limit.
+func TestWebTool_WebFetch_MaxCharsArgCappedByConfig(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("y", 20000)))
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(1000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("NewWebFetchTool() error: %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"url":      server.URL,
+		"maxChars": float64(15000),
+	})
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %s", result.ForLLM)
+	}
+
+	resultMap := make(map[string]any)
+	json.Unmarshal([]byte(result.ForLLM), &resultMap)
+	text, _ := resultMap["text"].(string)
+	if len(text) > 1100 {
+		t.Errorf("maxChars arg should be capped at 1000, got %d chars", len(text))
+	}
+}
+
 // TestWebTool_WebFetch_TruncationNotice verifies the truncation notice is appended
 // for all content formats (text/plain, text/html, markdown, application/json).
 func TestWebTool_WebFetch_TruncationNotice(t *testing.T) {
@@ -415,6 +446,39 @@ func TestWebTool_WebSearch_NoApiKey(t *testing.T) {
 	}
 }
 
+// TestWebTool_WebSearch_BraveCustomBaseURL verifies the Brave endpoint can be overridden.
+func TestWebTool_WebSearch_BraveCustomBaseURL(t *testing.T) {
+	var gotQuery, gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("q")
+		gotToken = r.Header.Get("X-Subscription-Token")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"web":{"results":[{"title":"PicoClaw","url":"https://example.com","description":"tiny agent"}]}}`))
+	}))
+	defer server.Close()
+
+	tool, err := NewWebSearchTool(WebSearchToolOptions{
+		BraveEnabled:    true,
+		BraveAPIKeys:    []string{"test-key"},
+		BraveBaseURL:    server.URL + "/search",
+		BraveMaxResults: 3,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"query": "picoclaw"})
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %s", result.ForLLM)
+	}
+	if gotQuery != "picoclaw" || gotToken != "test-key" {
+		t.Fatalf("unexpected request: q=%q token=%q", gotQuery, gotToken)
+	}
+	if !strings.Contains(result.ForLLM, "https://example.com") || !strings.Contains(result.ForLLM, "tiny agent") {
+		t.Fatalf("unexpected result: %s", result.ForLLM)
+	}
+}
+
 // TestWebTool_WebSearch_MissingQuery verifies error handling for missing query
 func TestWebTool_WebSearch_MissingQuery(t *testing.T) {
 	tool, err := NewWebSearchTool(WebSearchToolOptions{
@@ -14,6 +14,11 @@ type (
 	ReactionCallback         = integrationtools.ReactionCallback
 	MCPManager               = integrationtools.MCPManager
 	MCPTool                  = integrationtools.MCPTool
+	MCPAdmin                 = integrationtools.MCPAdmin
+	MCPAdminTool             = integrationtools.MCPAdminTool
+	ModelChoices             = integrationtools.ModelChoices
+	ModelSwitcher            = integrationtools.ModelSwitcher
+	ModelTool                = integrationtools.ModelTool
 	FindSkillsTool           = integrationtools.FindSkillsTool
 	InstallSkillTool         = integrationtools.InstallSkillTool
 	MessageTool              = integrationtools.MessageTool
@@ -34,12 +39,24 @@ type (
 	WebSearchTool            = integrationtools.WebSearchTool
 	WebSearchToolOptions     = integrationtools.WebSearchToolOptions
 	WebFetchTool             = integrationtools.WebFetchTool
+	HTTPRequestTool          = integrationtools.HTTPRequestTool
+	HTTPRequestToolOptions   = integrationtools.HTTPRequestToolOptions
+	ImageGenTool             = integrationtools.ImageGenTool
+	ImageGenToolOptions      = integrationtools.ImageGenToolOptions
 )
 
 func NewMCPTool(manager MCPManager, serverName string, tool *mcp.Tool) *MCPTool {
 	return integrationtools.NewMCPTool(manager, serverName, tool)
 }
 
+func NewMCPAdminTool(admin MCPAdmin) *MCPAdminTool {
+	return integrationtools.NewMCPAdminTool(admin)
+}
+
+func NewModelTool(switcher ModelSwitcher) *ModelTool {
+	return integrationtools.NewModelTool(switcher)
+}
+
 func NewFindSkillsTool(registryMgr *skills.RegistryManager, cache *skills.SearchCache) *FindSkillsTool {
 	return integrationtools.NewFindSkillsTool(registryMgr, cache)
 }
@@ -99,3 +116,11 @@ func NewWebFetchToolWithConfig(
 ) (*WebFetchTool, error) {
 	return integrationtools.NewWebFetchToolWithConfig(maxChars, proxy, format, fetchLimitBytes, privateHostWhitelist)
 }
+
+func NewHTTPRequestTool(opts HTTPRequestToolOptions) (*HTTPRequestTool, error) {
+	return integrationtools.NewHTTPRequestTool(opts)
+}
+
+func NewImageGenTool(opts ImageGenToolOptions, store media.MediaStore) (*ImageGenTool, error) {
+	return integrationtools.NewImageGenTool(opts, store)
+}
@@ -0,0 +1,302 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	memoryToolMaxEntries    = 500
+	memoryToolMaxKeyLen     = 128
+	memoryToolMaxValueBytes = 4096
+
+	memoryScopeSession = "session"
+	memoryScopeShared  = "shared"
+	memorySharedNS     = "_shared"
+)
+
+// memoryFileLocks serializes read-modify-write cycles per store file so that
+// several agents (or tool instances) sharing a workspace never lose updates.
+var memoryFileLocks sync.Map // path -> *sync.Mutex
+
+type memoryEntry struct {
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// memoryFile is the on-disk layout: namespace -> key -> entry.
+type memoryFile map[string]map[string]memoryEntry
+
+// MemoryTool is a persistent key-value store the agent can use to remember
+// preferences, facts, and task state across conversations. Entries live in
+// memory/kv.json under the workspace and are scoped either to the current
+// session or to a namespace shared by all sessions.
+type MemoryTool struct {
+	path string
+}
+
+// NewMemoryTool creates a MemoryTool backed by <workspace>/memory/kv.json.
+func NewMemoryTool(workspace string) *MemoryTool {
+	return &MemoryTool{path: filepath.Join(workspace, "memory", "kv.json")}
+}
+
+func (t *MemoryTool) Name() string {
+	return "memory"
+}
+
+func (t *MemoryTool) Description() string {
+	return "Persistent key-value memory that survives across conversations. " +
+		"Use 'set' to remember a fact, preference, or task state, 'get' to recall it, " +
+		"'list' to see stored keys, and 'delete' to forget. " +
+		"scope 'session' (default) is private to this conversation; 'shared' is visible to all conversations."
+}
+
+func (t *MemoryTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"set", "get", "list", "delete"},
+				"description": "Operation to perform",
+			},
+			"key": map[string]any{
+				"type":        "string",
+				"description": "Entry key (required for set, get, delete)",
+			},
+			"value": map[string]any{
+				"type":        "string",
+				"description": "Value to store (required for set)",
+			},
+			"scope": map[string]any{
+				"type":        "string",
+				"enum":        []string{memoryScopeSession, memoryScopeShared},
+				"description": "Namespace to use: 'session' (default) or 'shared'",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *MemoryTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	key, _ := args["key"].(string)
+	key = strings.TrimSpace(key)
+
+	namespace, err := memoryNamespace(ctx, args)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	switch action {
+	case "set":
+		value, ok := args["value"].(string)
+		if !ok {
+			return ErrorResult("value is required for set")
+		}
+		return t.set(namespace, key, value)
+	case "get":
+		return t.get(namespace, key)
+	case "list":
+		return t.list(namespace)
+	case "delete":
+		return t.delete(namespace, key)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action %q (expected set, get, list, or delete)", action))
+	}
+}
+
+func memoryNamespace(ctx context.Context, args map[string]any) (string, error) {
+	scope, _ := args["scope"].(string)
+	switch strings.TrimSpace(scope) {
+	case "", memoryScopeSession:
+		if sessionKey := ToolSessionKey(ctx); sessionKey != "" {
+			return sessionKey, nil
+		}
+		// No session (e.g. direct CLI invocation): fall back to shared.
+		return memorySharedNS, nil
+	case memoryScopeShared:
+		return memorySharedNS, nil
+	default:
+		return "", fmt.Errorf("scope must be %q or %q", memoryScopeSession, memoryScopeShared)
+	}
+}
+
+func validateMemoryKey(key string) error {
+	if key == "" {
+		return errors.New("key is required")
+	}
+	if len(key) > memoryToolMaxKeyLen {
+		return fmt.Errorf("key exceeds %d characters", memoryToolMaxKeyLen)
+	}
+	return nil
+}
+
+func (t *MemoryTool) set(namespace, key, value string) *ToolResult {
+	if err := validateMemoryKey(key); err != nil {
+		return ErrorResult(err.Error())
+	}
+	if len(value) > memoryToolMaxValueBytes {
+		return ErrorResult(fmt.Sprintf("value exceeds %d bytes", memoryToolMaxValueBytes))
+	}
+
+	err := t.update(func(data memoryFile) error {
+		entries := data[namespace]
+		if entries == nil {
+			entries = make(map[string]memoryEntry)
+			data[namespace] = entries
+		}
+		if _, exists := entries[key]; !exists && countMemoryEntries(data) >= memoryToolMaxEntries {
+			return fmt.Errorf("memory is full (%d entries); delete unused keys first", memoryToolMaxEntries)
+		}
+		entries[key] = memoryEntry{Value: value, UpdatedAt: time.Now().UTC()}
+		return nil
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	return SilentResult(fmt.Sprintf("Stored %q", key))
+}
+
+func (t *MemoryTool) get(namespace, key string) *ToolResult {
+	if err := validateMemoryKey(key); err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	var (
+		entry memoryEntry
+		found bool
+	)
+	err := t.view(func(data memoryFile) {
+		entry, found = data[namespace][key]
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if !found {
+		return SilentResult(fmt.Sprintf("No value stored for %q", key))
+	}
+	return SilentResult(entry.Value)
+}
+
+func (t *MemoryTool) list(namespace string) *ToolResult {
+	var keys []string
+	err := t.view(func(data memoryFile) {
+		for k := range data[namespace] {
+			keys = append(keys, k)
+		}
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if len(keys) == 0 {
+		return SilentResult("No stored keys")
+	}
+	sort.Strings(keys)
+	return SilentResult(strings.Join(keys, "\n"))
+}
+
+func (t *MemoryTool) delete(namespace, key string) *ToolResult {
+	if err := validateMemoryKey(key); err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	var found bool
+	err := t.update(func(data memoryFile) error {
+		if _, found = data[namespace][key]; found {
+			delete(data[namespace], key)
+			if len(data[namespace]) == 0 {
+				delete(data, namespace)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if !found {
+		return SilentResult(fmt.Sprintf("No value stored for %q", key))
+	}
+	return SilentResult(fmt.Sprintf("Deleted %q", key))
+}
+
+func countMemoryEntries(data memoryFile) int {
+	n := 0
+	for _, entries := range data {
+		n += len(entries)
+	}
+	return n
+}
+
+func (t *MemoryTool) lock() func() {
+	v, _ := memoryFileLocks.LoadOrStore(t.path, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (t *MemoryTool) view(fn func(memoryFile)) error {
+	unlock := t.lock()
+	defer unlock()
+
+	data, err := t.load()
+	if err != nil {
+		return err
+	}
+	fn(data)
+	return nil
+}
+
+func (t *MemoryTool) update(fn func(memoryFile) error) error {
+	unlock := t.lock()
+	defer unlock()
+
+	data, err := t.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(data); err != nil {
+		return err
+	}
+
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode memory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create memory directory: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(t.path, encoded, 0o600); err != nil {
+		return fmt.Errorf("failed to write memory: %w", err)
+	}
+	return nil
+}
+
+func (t *MemoryTool) load() (memoryFile, error) {
+	raw, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(memoryFile), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory: %w", err)
+	}
+
+	data := make(memoryFile)
+	if len(raw) == 0 {
+		return data, nil
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse memory file %s: %w", t.path, err)
+	}
+	return data, nil
+}
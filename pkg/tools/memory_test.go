@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func memoryCtx(sessionKey string) context.Context {
+	return WithToolSessionContext(context.Background(), "main", sessionKey, nil)
+}
+
+func TestMemoryTool_SetGetDelete(t *testing.T) {
+	tool := NewMemoryTool(t.TempDir())
+	ctx := memoryCtx("s1")
+
+	res := tool.Execute(ctx, map[string]any{"action": "set", "key": "lang", "value": "Go"})
+	if res.IsError {
+		t.Fatalf("set failed: %s", res.ForLLM)
+	}
+
+	res = tool.Execute(ctx, map[string]any{"action": "get", "key": "lang"})
+	if res.IsError || res.ForLLM != "Go" {
+		t.Fatalf("get = %q (error=%v), want Go", res.ForLLM, res.IsError)
+	}
+
+	res = tool.Execute(ctx, map[string]any{"action": "delete", "key": "lang"})
+	if res.IsError {
+		t.Fatalf("delete failed: %s", res.ForLLM)
+	}
+
+	res = tool.Execute(ctx, map[string]any{"action": "get", "key": "lang"})
+	if !strings.Contains(res.ForLLM, "No value stored") {
+		t.Fatalf("get after delete = %q", res.ForLLM)
+	}
+}
+
+func TestMemoryTool_PersistsAcrossInstances(t *testing.T) {
+	workspace := t.TempDir()
+	ctx := memoryCtx("s1")
+
+	NewMemoryTool(workspace).Execute(ctx, map[string]any{"action": "set", "key": "k", "value": "v"})
+
+	res := NewMemoryTool(workspace).Execute(ctx, map[string]any{"action": "get", "key": "k"})
+	if res.ForLLM != "v" {
+		t.Fatalf("get from new instance = %q, want v", res.ForLLM)
+	}
+}
+
+func TestMemoryTool_Scopes(t *testing.T) {
+	tool := NewMemoryTool(t.TempDir())
+
+	tool.Execute(memoryCtx("s1"), map[string]any{"action": "set", "key": "private", "value": "a"})
+	tool.Execute(memoryCtx("s1"), map[string]any{"action": "set", "key": "common", "value": "b", "scope": "shared"})
+
+	res := tool.Execute(memoryCtx("s2"), map[string]any{"action": "get", "key": "private"})
+	if res.ForLLM == "a" {
+		t.Fatal("session-scoped value leaked into another session")
+	}
+
+	res = tool.Execute(memoryCtx("s2"), map[string]any{"action": "get", "key": "common", "scope": "shared"})
+	if res.ForLLM != "b" {
+		t.Fatalf("shared get = %q, want b", res.ForLLM)
+	}
+
+	res = tool.Execute(memoryCtx("s1"), map[string]any{"action": "list"})
+	if res.ForLLM != "private" {
+		t.Fatalf("list = %q, want only session keys", res.ForLLM)
+	}
+}
+
+func TestMemoryTool_Limits(t *testing.T) {
+	tool := NewMemoryTool(t.TempDir())
+	ctx := memoryCtx("s1")
+
+	res := tool.Execute(ctx, map[string]any{
+		"action": "set",
+		"key":    "big",
+		"value":  strings.Repeat("x", memoryToolMaxValueBytes+1),
+	})
+	if !res.IsError {
+		t.Fatal("expected oversized value to be rejected")
+	}
+
+	res = tool.Execute(ctx, map[string]any{
+		"action": "set",
+		"key":    strings.Repeat("k", memoryToolMaxKeyLen+1),
+		"value":  "v",
+	})
+	if !res.IsError {
+		t.Fatal("expected oversized key to be rejected")
+	}
+
+	for i := range memoryToolMaxEntries {
+		res = tool.Execute(ctx, map[string]any{"action": "set", "key": fmt.Sprintf("k%d", i), "value": "v"})
+		if res.IsError {
+			t.Fatalf("set %d failed: %s", i, res.ForLLM)
+		}
+	}
+	res = tool.Execute(ctx, map[string]any{"action": "set", "key": "overflow", "value": "v"})
+	if !res.IsError {
+		t.Fatal("expected set beyond entry cap to be rejected")
+	}
+	// Overwriting an existing key is still allowed when full.
+	res = tool.Execute(ctx, map[string]any{"action": "set", "key": "k0", "value": "updated"})
+	if res.IsError {
+		t.Fatalf("overwrite at cap failed: %s", res.ForLLM)
+	}
+}
+
+func TestMemoryTool_ConcurrentSets(t *testing.T) {
+	workspace := t.TempDir()
+	ctx := memoryCtx("s1")
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			NewMemoryTool(workspace).Execute(ctx, map[string]any{
+				"action": "set",
+				"key":    fmt.Sprintf("k%02d", i),
+				"value":  "v",
+			})
+		}()
+	}
+	wg.Wait()
+
+	res := NewMemoryTool(workspace).Execute(ctx, map[string]any{"action": "list"})
+	if got := len(strings.Split(res.ForLLM, "\n")); got != 20 {
+		t.Fatalf("listed %d keys after concurrent sets, want 20", got)
+	}
+}
+
+func TestMemoryTool_InvalidInput(t *testing.T) {
+	tool := NewMemoryTool(t.TempDir())
+	ctx := memoryCtx("s1")
+
+	cases := []map[string]any{
+		{"action": "bogus"},
+		{"action": "get"},
+		{"action": "set", "key": "k"},
+		{"action": "list", "scope": "global"},
+	}
+	for _, args := range cases {
+		if res := tool.Execute(ctx, args); !res.IsError {
+			t.Errorf("Execute(%v) succeeded, want error", args)
+		}
+	}
+}
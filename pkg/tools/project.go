@@ -0,0 +1,487 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	projectToolMaxProjects   = 100
+	projectToolMaxDirs       = 32
+	projectToolMaxNameLen    = 64
+	projectToolMaxNotesBytes = 8192
+
+	// projectNoSession keys the current project of calls made without a
+	// session, such as direct CLI invocations.
+	projectNoSession = "_default"
+)
+
+// projectFileLocks serializes read-modify-write cycles per projects file, as
+// memoryFileLocks does for the memory store.
+var projectFileLocks sync.Map // path -> *sync.Mutex
+
+// projectFileCache keeps the last parsed projects file per path, so exec
+// only stats the file instead of parsing it on every command.
+var projectFileCache sync.Map // path -> *cachedProjectFile
+
+type cachedProjectFile struct {
+	modTime time.Time
+	size    int64
+	data    *projectFile
+}
+
+// project groups working directories of the workspace. Dirs are relative to
+// the workspace; Active is the one exec runs in when no cwd is given.
+type project struct {
+	Dirs      []string  `json:"dirs,omitempty"`
+	Active    string    `json:"active,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// projectFile is the on-disk layout of projects/projects.json.
+type projectFile struct {
+	Projects map[string]*project `json:"projects"`
+	Current  map[string]string   `json:"current,omitempty"` // session key -> project name
+}
+
+// ProjectTool groups the working directories the agent builds and tests in
+// into named projects, stored in projects/projects.json under the workspace.
+// Each session picks a current project, and exec runs in that project's
+// active directory when the model gives no cwd.
+type ProjectTool struct {
+	workspace string
+	path      string
+}
+
+// NewProjectTool creates a ProjectTool backed by
+// <workspace>/projects/projects.json.
+func NewProjectTool(workspace string) *ProjectTool {
+	return &ProjectTool{workspace: workspace, path: projectFilePath(workspace)}
+}
+
+func projectFilePath(workspace string) string {
+	return filepath.Join(workspace, "projects", "projects.json")
+}
+
+func (t *ProjectTool) Name() string {
+	return "project"
+}
+
+func (t *ProjectTool) Description() string {
+	return "Group the workspace directories of a multi-directory task into a named project. " +
+		"'create' makes a project and selects it for this conversation, 'use' selects an existing one, " +
+		"'attach' and 'detach' add or remove directories, 'activate' picks the directory exec runs in " +
+		"when no cwd is given, 'note' saves project notes, and 'show' and 'list' report the projects."
+}
+
+func (t *ProjectTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"create", "use", "attach", "detach", "activate", "note", "show", "list"},
+				"description": "Operation to perform",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Project name (required for create and use; defaults to the current project otherwise)",
+			},
+			"dir": map[string]any{
+				"type":        "string",
+				"description": "Directory relative to the workspace (required for attach, detach and activate). Created if missing.",
+			},
+			"notes": map[string]any{
+				"type":        "string",
+				"description": "Project notes, e.g. how to build and test (for create and note; replaces earlier notes)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ProjectTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	name, _ := args["name"].(string)
+	dir, _ := args["dir"].(string)
+	notes, notesSet := args["notes"].(string)
+	name = strings.TrimSpace(name)
+
+	session := ToolSessionKey(ctx)
+	if session == "" {
+		session = projectNoSession
+	}
+
+	switch action {
+	case "create":
+		return t.create(session, name, notes)
+	case "use":
+		return t.use(session, name)
+	case "attach", "detach", "activate":
+		return t.changeDirs(session, action, name, dir)
+	case "note":
+		if !notesSet {
+			return ErrorResult("notes is required for note")
+		}
+		return t.note(session, name, notes)
+	case "show":
+		return t.show(session, name)
+	case "list":
+		return t.list(session)
+	default:
+		return ErrorResult(fmt.Sprintf(
+			"unknown action %q (expected create, use, attach, detach, activate, note, show, or list)", action))
+	}
+}
+
+func validateProjectName(name string) error {
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if len(name) > projectToolMaxNameLen {
+		return fmt.Errorf("name exceeds %d characters", projectToolMaxNameLen)
+	}
+	return nil
+}
+
+func (t *ProjectTool) create(session, name, notes string) *ToolResult {
+	if err := validateProjectName(name); err != nil {
+		return ErrorResult(err.Error())
+	}
+	if len(notes) > projectToolMaxNotesBytes {
+		return ErrorResult(fmt.Sprintf("notes exceed %d bytes", projectToolMaxNotesBytes))
+	}
+	err := t.update(func(data *projectFile) error {
+		if _, exists := data.Projects[name]; exists {
+			return fmt.Errorf("project %q already exists; select it with use", name)
+		}
+		if len(data.Projects) >= projectToolMaxProjects {
+			return fmt.Errorf("too many projects (%d)", projectToolMaxProjects)
+		}
+		data.Projects[name] = &project{Notes: notes, UpdatedAt: time.Now().UTC()}
+		data.Current[session] = name
+		return nil
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	return SilentResult(fmt.Sprintf("Created project %q and selected it for this conversation", name))
+}
+
+func (t *ProjectTool) use(session, name string) *ToolResult {
+	if err := validateProjectName(name); err != nil {
+		return ErrorResult(err.Error())
+	}
+	var p *project
+	err := t.update(func(data *projectFile) error {
+		if p = data.Projects[name]; p == nil {
+			return fmt.Errorf("project %q not found", name)
+		}
+		data.Current[session] = name
+		return nil
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	return SilentResult(fmt.Sprintf("Selected project %q\n%s", name, formatProject(name, p)))
+}
+
+// resolveProject returns the named project, or the session's current one
+// when name is empty.
+func resolveProject(data *projectFile, session, name string) (string, *project, error) {
+	if name == "" {
+		name = data.Current[session]
+		if name == "" {
+			return "", nil, errors.New("no project selected; pass name, or create or use a project first")
+		}
+	}
+	p := data.Projects[name]
+	if p == nil {
+		return "", nil, fmt.Errorf("project %q not found", name)
+	}
+	return name, p, nil
+}
+
+func (t *ProjectTool) changeDirs(session, action, name, dir string) *ToolResult {
+	rel, err := t.relativeDir(dir)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	var msg string
+	err = t.update(func(data *projectFile) error {
+		name, p, err := resolveProject(data, session, name)
+		if err != nil {
+			return err
+		}
+		attached := slices.Contains(p.Dirs, rel)
+		switch action {
+		case "attach", "activate":
+			if !attached {
+				if len(p.Dirs) >= projectToolMaxDirs {
+					return fmt.Errorf("project %q already has %d directories", name, projectToolMaxDirs)
+				}
+				p.Dirs = append(p.Dirs, rel)
+			}
+			// The first directory becomes the working directory by default.
+			if action == "activate" || p.Active == "" {
+				p.Active = rel
+			}
+			msg = fmt.Sprintf("Attached %s to project %q", rel, name)
+			if action == "activate" {
+				msg = fmt.Sprintf("exec now runs in %s for project %q", rel, name)
+			}
+		case "detach":
+			if !attached {
+				return fmt.Errorf("%s is not attached to project %q", rel, name)
+			}
+			p.Dirs = slices.DeleteFunc(p.Dirs, func(d string) bool { return d == rel })
+			if p.Active == rel {
+				p.Active = ""
+				if len(p.Dirs) > 0 {
+					p.Active = p.Dirs[0]
+				}
+			}
+			msg = fmt.Sprintf("Detached %s from project %q", rel, name)
+		}
+		p.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if action != "detach" {
+		if err := os.MkdirAll(filepath.Join(t.workspace, filepath.FromSlash(rel)), 0o755); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to create %s: %v", rel, err))
+		}
+	}
+	return SilentResult(msg)
+}
+
+// relativeDir cleans a directory argument to a path relative to the
+// workspace, rejecting anything outside it.
+func (t *ProjectTool) relativeDir(dir string) (string, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return "", errors.New("dir is required")
+	}
+	rel := dir
+	if filepath.IsAbs(dir) {
+		var err error
+		if rel, err = filepath.Rel(t.workspace, dir); err != nil {
+			return "", fmt.Errorf("dir %q is outside the workspace", dir)
+		}
+	}
+	rel = filepath.Clean(rel)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("dir %q is outside the workspace", dir)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func (t *ProjectTool) note(session, name, notes string) *ToolResult {
+	if len(notes) > projectToolMaxNotesBytes {
+		return ErrorResult(fmt.Sprintf("notes exceed %d bytes", projectToolMaxNotesBytes))
+	}
+	err := t.update(func(data *projectFile) error {
+		var p *project
+		var err error
+		if name, p, err = resolveProject(data, session, name); err != nil {
+			return err
+		}
+		p.Notes = notes
+		p.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	return SilentResult(fmt.Sprintf("Saved notes for project %q", name))
+}
+
+func (t *ProjectTool) show(session, name string) *ToolResult {
+	var p *project
+	var err error
+	if viewErr := t.view(func(data *projectFile) {
+		name, p, err = resolveProject(data, session, name)
+	}); viewErr != nil {
+		return ErrorResult(viewErr.Error())
+	}
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	return SilentResult(formatProject(name, p))
+}
+
+func (t *ProjectTool) list(session string) *ToolResult {
+	var lines []string
+	err := t.view(func(data *projectFile) {
+		current := data.Current[session]
+		for name, p := range data.Projects {
+			line := fmt.Sprintf("%s (%d dirs)", name, len(p.Dirs))
+			if name == current {
+				line += " [current]"
+			}
+			lines = append(lines, line)
+		}
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if len(lines) == 0 {
+		return SilentResult("No projects")
+	}
+	sort.Strings(lines)
+	return SilentResult(strings.Join(lines, "\n"))
+}
+
+func formatProject(name string, p *project) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project: %s\n", name)
+	if len(p.Dirs) == 0 {
+		b.WriteString("Directories: none\n")
+	} else {
+		b.WriteString("Directories:\n")
+		for _, dir := range p.Dirs {
+			marker := ""
+			if dir == p.Active {
+				marker = " (active, exec default)"
+			}
+			fmt.Fprintf(&b, "- %s%s\n", dir, marker)
+		}
+	}
+	if p.Notes != "" {
+		fmt.Fprintf(&b, "Notes:\n%s\n", p.Notes)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// activeProjectDir returns the absolute active directory of the current
+// project of the calling session, or "" when there is none or it was
+// removed from disk.
+func activeProjectDir(ctx context.Context, workspace string) string {
+	if workspace == "" {
+		return ""
+	}
+	session := ToolSessionKey(ctx)
+	if session == "" {
+		session = projectNoSession
+	}
+	data := NewProjectTool(workspace).cached()
+	if data == nil {
+		return ""
+	}
+	p := data.Projects[data.Current[session]]
+	if p == nil || p.Active == "" {
+		return ""
+	}
+	dir := filepath.Join(workspace, filepath.FromSlash(p.Active))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+// cached returns the projects file, parsing it again only when its size or
+// modification time changed. It returns nil when there is no file or it
+// cannot be read. The result is shared and must not be modified.
+func (t *ProjectTool) cached() *projectFile {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		projectFileCache.Delete(t.path)
+		return nil
+	}
+	if v, ok := projectFileCache.Load(t.path); ok {
+		if c := v.(*cachedProjectFile); c.modTime.Equal(info.ModTime()) && c.size == info.Size() {
+			return c.data
+		}
+	}
+	var data *projectFile
+	if err := t.view(func(d *projectFile) { data = d }); err != nil {
+		return nil
+	}
+	projectFileCache.Store(t.path, &cachedProjectFile{modTime: info.ModTime(), size: info.Size(), data: data})
+	return data
+}
+
+func (t *ProjectTool) lock() func() {
+	v, _ := projectFileLocks.LoadOrStore(t.path, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (t *ProjectTool) view(fn func(*projectFile)) error {
+	unlock := t.lock()
+	defer unlock()
+
+	data, err := t.load()
+	if err != nil {
+		return err
+	}
+	fn(data)
+	return nil
+}
+
+func (t *ProjectTool) update(fn func(*projectFile) error) error {
+	unlock := t.lock()
+	defer unlock()
+
+	data, err := t.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(data); err != nil {
+		return err
+	}
+	// Forget selections of projects that no longer exist.
+	for session, name := range data.Current {
+		if data.Projects[name] == nil {
+			delete(data.Current, session)
+		}
+	}
+
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode projects: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create projects directory: %w", err)
+	}
+	projectFileCache.Delete(t.path)
+	if err := fileutil.WriteFileAtomic(t.path, encoded, 0o600); err != nil {
+		return fmt.Errorf("failed to write projects: %w", err)
+	}
+	return nil
+}
+
+func (t *ProjectTool) load() (*projectFile, error) {
+	data := &projectFile{}
+	raw, err := os.ReadFile(t.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read projects: %w", err)
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, data); err != nil {
+			return nil, fmt.Errorf("failed to parse projects file %s: %w", t.path, err)
+		}
+	}
+	if data.Projects == nil {
+		data.Projects = make(map[string]*project)
+	}
+	if data.Current == nil {
+		data.Current = make(map[string]string)
+	}
+	return data, nil
+}
@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestProjectTool_Lifecycle(t *testing.T) {
+	workspace := t.TempDir()
+	tool := NewProjectTool(workspace)
+	ctx := memoryCtx("s1")
+
+	run := func(args map[string]any) *ToolResult {
+		t.Helper()
+		res := tool.Execute(ctx, args)
+		if res.IsError {
+			t.Fatalf("%v failed: %s", args, res.ForLLM)
+		}
+		return res
+	}
+
+	run(map[string]any{"action": "create", "name": "shop", "notes": "make test"})
+	run(map[string]any{"action": "attach", "dir": "shop/api"})
+	run(map[string]any{"action": "attach", "dir": "shop/web"})
+	if info, err := os.Stat(filepath.Join(workspace, "shop", "web")); err != nil || !info.IsDir() {
+		t.Fatalf("attach did not create the directory: %v", err)
+	}
+
+	res := run(map[string]any{"action": "show"})
+	for _, want := range []string{"Project: shop", "- shop/api (active, exec default)", "- shop/web\n", "make test"} {
+		if !strings.Contains(res.ForLLM, want) {
+			t.Errorf("show = %q, want it to contain %q", res.ForLLM, want)
+		}
+	}
+
+	run(map[string]any{"action": "activate", "dir": "shop/web"})
+	if got := activeProjectDir(ctx, workspace); got != filepath.Join(workspace, "shop", "web") {
+		t.Fatalf("activeProjectDir = %q, want shop/web", got)
+	}
+	if got := activeProjectDir(memoryCtx("s2"), workspace); got != "" {
+		t.Fatalf("activeProjectDir for another session = %q, want none", got)
+	}
+
+	run(map[string]any{"action": "detach", "dir": "shop/web"})
+	if got := activeProjectDir(ctx, workspace); got != filepath.Join(workspace, "shop", "api") {
+		t.Fatalf("activeProjectDir after detach = %q, want shop/api", got)
+	}
+
+	res = tool.Execute(memoryCtx("s2"), map[string]any{"action": "list"})
+	if res.ForLLM != "shop (1 dirs)" {
+		t.Fatalf("list from another session = %q", res.ForLLM)
+	}
+}
+
+func TestProjectTool_Errors(t *testing.T) {
+	tool := NewProjectTool(t.TempDir())
+	tool.Execute(memoryCtx("s1"), map[string]any{"action": "create", "name": "p"})
+
+	tests := []struct {
+		session string
+		args    map[string]any
+		want    string
+	}{
+		{"s2", map[string]any{"action": "attach", "dir": "a"}, "no project selected"},
+		{"s1", map[string]any{"action": "use", "name": "missing"}, "not found"},
+		{"s1", map[string]any{"action": "create"}, "name is required"},
+		{"s1", map[string]any{"action": "create", "name": "p"}, "already exists"},
+		{"s1", map[string]any{"action": "attach", "dir": "../outside"}, "outside the workspace"},
+		{"s1", map[string]any{"action": "detach", "dir": "never-attached"}, "is not attached"},
+		{"s1", map[string]any{"action": "bogus"}, "unknown action"},
+	}
+	for _, tt := range tests {
+		res := tool.Execute(memoryCtx(tt.session), tt.args)
+		if !res.IsError || !strings.Contains(res.ForLLM, tt.want) {
+			t.Errorf("%v = %q, want error containing %q", tt.args, res.ForLLM, tt.want)
+		}
+	}
+}
+
+func TestExecTool_RunsInActiveProjectDir(t *testing.T) {
+	workspace := t.TempDir()
+	ctx := memoryCtx("s1")
+	project := NewProjectTool(workspace)
+	project.Execute(ctx, map[string]any{"action": "create", "name": "p"})
+	project.Execute(ctx, map[string]any{"action": "attach", "dir": "svc"})
+
+	exec, err := NewExecTool(workspace, true)
+	if err != nil {
+		t.Fatalf("NewExecTool: %v", err)
+	}
+	res := exec.Execute(ctx, map[string]any{"action": "run", "command": "pwd"})
+	if res.IsError {
+		t.Fatalf("pwd failed: %s", res.ForLLM)
+	}
+	want, _ := filepath.EvalSymlinks(filepath.Join(workspace, "svc"))
+	if !strings.Contains(res.ForLLM, want) {
+		t.Fatalf("pwd = %q, want it to run in %s", res.ForLLM, want)
+	}
+}
+
+func TestExecTool_SkipsProjectDirWhenUnavailable(t *testing.T) {
+	workspace := t.TempDir()
+	ctx := memoryCtx("s1")
+	project := NewProjectTool(workspace)
+	project.Execute(ctx, map[string]any{"action": "create", "name": "p"})
+	project.Execute(ctx, map[string]any{"action": "attach", "dir": "svc"})
+	wantWorkspace, _ := filepath.EvalSymlinks(workspace)
+
+	pwd := func(exec *ExecTool) string {
+		t.Helper()
+		res := exec.Execute(ctx, map[string]any{"action": "run", "command": "pwd"})
+		if res.IsError {
+			t.Fatalf("pwd failed: %s", res.ForLLM)
+		}
+		return strings.TrimSpace(res.ForLLM)
+	}
+
+	// With the project tool off, exec does not look at projects.json.
+	cfg := &config.Config{}
+	cfg.Tools.Exec.AllowRemote = true
+	cfg.Tools.Exec.EnableDenyPatterns = true
+	exec, err := NewExecToolWithConfig(workspace, true, cfg)
+	if err != nil {
+		t.Fatalf("NewExecToolWithConfig: %v", err)
+	}
+	if got := pwd(exec); got != wantWorkspace {
+		t.Fatalf("pwd with the project tool off = %q, want the workspace", got)
+	}
+
+	// A removed active directory falls back to the workspace.
+	if err := os.Remove(filepath.Join(workspace, "svc")); err != nil {
+		t.Fatal(err)
+	}
+	exec, err = NewExecTool(workspace, true)
+	if err != nil {
+		t.Fatalf("NewExecTool: %v", err)
+	}
+	if got := pwd(exec); got != wantWorkspace {
+		t.Fatalf("pwd after the project directory was removed = %q, want the workspace", got)
+	}
+}
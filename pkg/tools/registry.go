@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,7 +23,7 @@ type ToolEntry struct {
 type ToolRegistry struct {
 	tools      map[string]*ToolEntry
 	mu         sync.RWMutex
-	version    atomic.Uint64 // incremented on Register/RegisterHidden for cache invalidation
+	version    atomic.Uint64 // incremented on every registration change for cache invalidation
 	mediaStore media.MediaStore
 }
 
@@ -77,6 +78,47 @@ func (r *ToolRegistry) RegisterHidden(tool Tool) {
 	logger.DebugCF("tools", "Registered hidden tool", map[string]any{"name": name})
 }
 
+// Unregister removes the named tool, core or hidden, and reports whether it
+// was registered.
+func (r *ToolRegistry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[name]; !exists {
+		return false
+	}
+	delete(r.tools, name)
+	r.version.Add(1)
+	logger.DebugCF("tools", "Unregistered tool", map[string]any{"name": name})
+	return true
+}
+
+// UnregisterByPrefix removes every tool whose name starts with prefix, such as
+// all of one MCP server's mcp_<server>_* tools, and returns how many were removed.
+func (r *ToolRegistry) UnregisterByPrefix(prefix string) int {
+	return r.UnregisterFunc(func(tool Tool) bool {
+		return strings.HasPrefix(tool.Name(), prefix)
+	})
+}
+
+// UnregisterFunc removes every tool, core or hidden, for which match returns
+// true, and returns how many were removed.
+func (r *ToolRegistry) UnregisterFunc(match func(Tool) bool) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed := 0
+	for name, entry := range r.tools {
+		if match(entry.Tool) {
+			delete(r.tools, name)
+			removed++
+		}
+	}
+	if removed > 0 {
+		r.version.Add(1)
+		logger.DebugCF("tools", "Unregistered tools", map[string]any{"count": removed})
+	}
+	return removed
+}
+
 // SetMediaStore injects a MediaStore into all registered tools that can
 // consume it, and remembers it for future registrations.
 func (r *ToolRegistry) SetMediaStore(store media.MediaStore) {
@@ -176,6 +218,32 @@ func (r *ToolRegistry) Get(name string) (Tool, bool) {
 	return entry.Tool, true
 }
 
+// IsSequential reports whether the named tool opted out of concurrent
+// execution via SequentialTool. Unknown tools are not sequential.
+func (r *ToolRegistry) IsSequential(name string) bool {
+	tool, ok := r.Get(name)
+	if !ok {
+		return false
+	}
+	st, ok := tool.(SequentialTool)
+	return ok && st.Sequential()
+}
+
+// ConfirmationPreview returns the preview of a call to a ConfirmableTool, or
+// "" when the tool does not exist, is not confirmable, or considers the call
+// harmless.
+func (r *ToolRegistry) ConfirmationPreview(name string, args map[string]any) string {
+	tool, ok := r.Get(name)
+	if !ok {
+		return ""
+	}
+	ct, ok := tool.(ConfirmableTool)
+	if !ok {
+		return ""
+	}
+	return ct.ConfirmationPreview(args)
+}
+
 func (r *ToolRegistry) Execute(ctx context.Context, name string, args map[string]any) *ToolResult {
 	return r.ExecuteWithContext(ctx, name, args, "", "", nil)
 }
@@ -191,7 +259,7 @@ func (r *ToolRegistry) ExecuteWithContext(
 	channel, chatID string,
 	asyncCallback AsyncCallback,
 ) *ToolResult {
-	logger.InfoCF("tool", "Tool execution started",
+	logger.InfoCtx(ctx, "tool", "Tool execution started",
 		map[string]any{
 			"tool": name,
 			"args": args,
@@ -199,7 +267,7 @@ func (r *ToolRegistry) ExecuteWithContext(
 
 	tool, ok := r.Get(name)
 	if !ok {
-		logger.ErrorCF("tool", "Tool not found",
+		logger.ErrorCtx(ctx, "tool", "Tool not found",
 			map[string]any{
 				"tool": name,
 			})
@@ -208,9 +276,11 @@ func (r *ToolRegistry) ExecuteWithContext(
 
 	// Validate arguments against the tool's declared schema.
 	if err := validateToolArgs(tool.Parameters(), args); err != nil {
-		logger.WarnCF("tool", "Tool argument validation failed",
+		logger.WarnCtx(ctx, "tool", "Tool argument validation failed",
 			map[string]any{"tool": name, "error": err.Error()})
-		return ErrorResult(fmt.Sprintf("invalid arguments for tool %q: %s", name, err)).
+		return ErrorResult(fmt.Sprintf(
+			"invalid arguments for tool %q: %s. Check the call against the tool's parameter schema and retry.",
+			name, err)).
 			WithError(fmt.Errorf("argument validation failed: %w", err))
 	}
 
@@ -230,7 +300,7 @@ func (r *ToolRegistry) ExecuteWithContext(
 			if re := recover(); re != nil {
 				logger.RecoverPanicNoExit(re)
 				errMsg := fmt.Sprintf("Tool '%s' crashed with panic: %v", name, re)
-				logger.ErrorCF("tool", "Tool execution panic recovered",
+				logger.ErrorCtx(ctx, "tool", "Tool execution panic recovered",
 					map[string]any{
 						"tool":  name,
 						"panic": fmt.Sprintf("%v", re),
@@ -245,7 +315,7 @@ func (r *ToolRegistry) ExecuteWithContext(
 		}()
 
 		if asyncExec, ok := tool.(AsyncExecutor); ok && asyncCallback != nil {
-			logger.DebugCF("tool", "Executing async tool via ExecuteAsync",
+			logger.DebugCtx(ctx, "tool", "Executing async tool via ExecuteAsync",
 				map[string]any{
 					"tool": name,
 				})
@@ -271,20 +341,20 @@ func (r *ToolRegistry) ExecuteWithContext(
 
 	// Log based on result type
 	if result.IsError {
-		logger.ErrorCF("tool", "Tool execution failed",
+		logger.ErrorCtx(ctx, "tool", "Tool execution failed",
 			map[string]any{
 				"tool":     name,
 				"duration": duration.Milliseconds(),
 				"error":    result.ForLLM,
 			})
 	} else if result.Async {
-		logger.InfoCF("tool", "Tool started (async)",
+		logger.InfoCtx(ctx, "tool", "Tool started (async)",
 			map[string]any{
 				"tool":     name,
 				"duration": duration.Milliseconds(),
 			})
 	} else {
-		logger.InfoCF("tool", "Tool execution completed",
+		logger.InfoCtx(ctx, "tool", "Tool execution completed",
 			map[string]any{
 				"tool":          name,
 				"duration_ms":   duration.Milliseconds(),
@@ -423,6 +493,21 @@ func (r *ToolRegistry) GetSummaries() []string {
 	return summaries
 }
 
+// Snapshot returns every registered tool, including hidden tools whose TTL
+// has expired, sorted by name. Unlike GetAll it is for introspection, not for
+// building the set of tools the model sees.
+func (r *ToolRegistry) Snapshot() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sorted := r.sortedToolNames()
+	tools := make([]Tool, 0, len(sorted))
+	for _, name := range sorted {
+		tools = append(tools, r.tools[name].Tool)
+	}
+	return tools
+}
+
 // GetAll returns all registered tools (both core and non-core with TTL > 0).
 // Used by SubTurn to inherit parent's tool set.
 func (r *ToolRegistry) GetAll() []Tool {
@@ -117,6 +117,72 @@ func TestToolRegistry_RegisterOverwrite(t *testing.T) {
 	}
 }
 
+func TestToolRegistry_Unregister(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("echo", "echoes input"))
+	r.RegisterHidden(newMockTool("hidden", "deferred"))
+	before := r.Version()
+
+	if !r.Unregister("echo") || !r.Unregister("hidden") {
+		t.Fatal("expected registered tools to be removed")
+	}
+	if r.Unregister("echo") {
+		t.Error("expected second Unregister to report false")
+	}
+	if r.Count() != 0 {
+		t.Errorf("expected empty registry, got %d tools", r.Count())
+	}
+	if r.Version() == before {
+		t.Error("expected version to change after Unregister")
+	}
+}
+
+func TestToolRegistry_UnregisterByPrefix(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("mcp_github_search", "a"))
+	r.RegisterHidden(newMockTool("mcp_github_issue", "a"))
+	r.Register(newMockTool("mcp_jira_search", "b"))
+
+	if removed := r.UnregisterByPrefix("mcp_github_"); removed != 2 {
+		t.Errorf("expected 2 tools removed, got %d", removed)
+	}
+	if names := r.List(); len(names) != 1 || names[0] != "mcp_jira_search" {
+		t.Errorf("expected only mcp_jira_search to remain, got %v", names)
+	}
+}
+
+func TestToolRegistry_SnapshotIncludesHiddenTools(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("zeta", "core"))
+	r.RegisterHidden(newMockTool("alpha", "hidden, not promoted"))
+
+	snapshot := r.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Name() != "alpha" || snapshot[1].Name() != "zeta" {
+		t.Fatalf("expected [alpha zeta], got %d tools", len(snapshot))
+	}
+	if len(r.GetAll()) != 1 {
+		t.Errorf("expected GetAll to omit the unpromoted hidden tool")
+	}
+}
+
+func TestToolRegistry_UnregisterFunc(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("mcp_a_one", "a"))
+	r.Register(newMockTool("mcp_a_two", "a"))
+	r.Register(newMockTool("mcp_b_one", "b"))
+
+	removed := r.UnregisterFunc(func(tool Tool) bool { return tool.Description() == "a" })
+	if removed != 2 {
+		t.Errorf("expected 2 tools removed, got %d", removed)
+	}
+	if _, ok := r.Get("mcp_a_one"); ok {
+		t.Error("expected mcp_a_one to be unregistered")
+	}
+	if _, ok := r.Get("mcp_b_one"); !ok {
+		t.Error("expected mcp_b_one to remain registered")
+	}
+}
+
 func TestToolRegistry_Execute_Success(t *testing.T) {
 	r := NewToolRegistry()
 	r.Register(&mockRegistryTool{
@@ -48,6 +48,10 @@ type ProcessSession struct {
 	outputBuffer    *bytes.Buffer
 	outputTruncated bool
 	ptyMaster       *os.File
+	// LogFile is where the session's full output is also written, when
+	// background logs are enabled.
+	LogFile string
+	log     *sessionLog
 
 	// ptyKeyMode tracks arrow key encoding mode (CSI vs SS3)
 	ptyKeyMode PtyKeyMode
@@ -142,6 +146,39 @@ func (s *ProcessSession) Write(data string) error {
 	return err
 }
 
+// enterKey is what pressing Enter sends: a carriage return on a terminal,
+// a newline on a plain stdin pipe.
+func (s *ProcessSession) enterKey() string {
+	if s.PTY {
+		return "\r"
+	}
+	return "\n"
+}
+
+// appendOutput records process output: it goes to the log file in full and
+// into the read buffer until that reaches maxOutputBufferSize.
+func (s *ProcessSession) appendOutput(p []byte) {
+	if s.log != nil {
+		s.log.Write(p)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.outputBuffer.Len() >= maxOutputBufferSize {
+		if !s.outputTruncated {
+			s.outputBuffer.WriteString(outputTruncateMarker)
+			s.outputTruncated = true
+		}
+		return
+	}
+	s.outputBuffer.Write(p)
+}
+
+func (s *ProcessSession) closeLog() {
+	if s.log != nil {
+		s.log.Close()
+	}
+}
+
 func (s *ProcessSession) Read() string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -165,6 +202,7 @@ func (s *ProcessSession) ToSessionInfo() SessionInfo {
 		Status:    s.Status,
 		PID:       s.PID,
 		StartedAt: s.StartTime,
+		LogFile:   s.LogFile,
 	}
 }
 
@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	backgroundLogDir        = ".logs"
+	defaultBackgroundLogMax = 5 * 1024 * 1024
+)
+
+// sessionLog appends a background session's output to a file so the full
+// history survives Read draining the buffer and the buffer's size limit.
+// Runs of the same command share a file, separated by a header line; when
+// the file grows past maxBytes it is renamed to <name>.1 and a new one is
+// started, so at most two files per command are kept.
+//
+// Concurrent runs of one command write through the same logFile, so one
+// run's rotation cannot rename a file another still writes to. Where their
+// output alternates, each switch is marked with the session it came from.
+type sessionLog struct {
+	path      string
+	sessionID string
+	file      *logFile
+	closed    atomic.Bool
+}
+
+// logFile is the file shared by every open sessionLog with the same path.
+type logFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+	refs     int
+	last     string // session of the latest write
+	midLine  bool   // the latest write did not end a line
+}
+
+var (
+	logFilesMu sync.Mutex
+	logFiles   = map[string]*logFile{}
+)
+
+// backgroundLogPath returns where command's log lives under dir.
+func backgroundLogPath(dir, command string) string {
+	sum := sha256.Sum256([]byte(command))
+	return filepath.Join(dir, backgroundLogDir, hex.EncodeToString(sum[:6])+".log")
+}
+
+func openSessionLog(dir, command, sessionID string, maxBytes int64) (*sessionLog, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultBackgroundLogMax
+	}
+	path := backgroundLogPath(dir, command)
+	f, err := acquireLogFile(path, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	l := &sessionLog{path: path, sessionID: sessionID, file: f}
+	header := fmt.Sprintf("=== %s session %s: %s\n", time.Now().Format(time.RFC3339), sessionID, command)
+	if err := f.start(sessionID, header); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// acquireLogFile returns the shared file for path, opening it for the first
+// user.
+func acquireLogFile(path string, maxBytes int64) (*logFile, error) {
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+	if f := logFiles[path]; f != nil {
+		f.refs++
+		return f, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f := &logFile{path: path, maxBytes: maxBytes, refs: 1}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	logFiles[path] = f
+	return f, nil
+}
+
+func (f *logFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// start appends the header of a new run on a line of its own.
+func (f *logFile) start(sessionID, header string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	if f.midLine {
+		header = "\n" + header
+	}
+	f.last, f.midLine = sessionID, false
+	return f.append([]byte(header))
+}
+
+// write appends p for sessionID, marking the switch when the previous write
+// came from another session.
+func (f *logFile) write(sessionID string, p []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	if sessionID != f.last {
+		marker := fmt.Sprintf("--- session %s\n", sessionID)
+		if f.midLine {
+			marker = "\n" + marker
+		}
+		if err := f.append([]byte(marker)); err != nil {
+			return err
+		}
+		f.last = sessionID
+	}
+	if err := f.append(p); err != nil {
+		return err
+	}
+	if len(p) > 0 {
+		f.midLine = p[len(p)-1] != '\n'
+	}
+	return nil
+}
+
+func (f *logFile) append(p []byte) error {
+	if f.size > 0 && f.size+int64(len(p)) > f.maxBytes {
+		f.file.Close()
+		f.file = nil
+		if err := os.Rename(f.path, f.path+".1"); err != nil {
+			return err
+		}
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return err
+}
+
+func (l *sessionLog) Write(p []byte) (int, error) {
+	if l.closed.Load() {
+		return 0, os.ErrClosed
+	}
+	if err := l.file.write(l.sessionID, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close releases the session's hold on the file, closing it once no other
+// run of the command writes to it.
+func (l *sessionLog) Close() error {
+	if l.closed.Swap(true) {
+		return nil
+	}
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+	f := l.file
+	if f.refs--; f.refs > 0 {
+		return nil
+	}
+	delete(logFiles, f.path)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -32,6 +32,7 @@ var (
 	ctxKeyAgentID          = &toolCtxKey{"agentID"}
 	ctxKeySessionKey       = &toolCtxKey{"sessionKey"}
 	ctxKeySessionScope     = &toolCtxKey{"sessionScope"}
+	ctxKeySenderID         = &toolCtxKey{"senderID"}
 )
 
 // WithToolContext returns a child context carrying channel and chatID.
@@ -70,6 +71,11 @@ func WithToolSessionContext(
 	return ctx
 }
 
+// WithToolSenderContext returns a child context carrying the inbound sender ID.
+func WithToolSenderContext(ctx context.Context, senderID string) context.Context {
+	return context.WithValue(ctx, ctxKeySenderID, senderID)
+}
+
 // ToolChannel extracts the channel from ctx, or "" if unset.
 func ToolChannel(ctx context.Context) string {
 	v, _ := ctx.Value(ctxKeyChannel).(string)
@@ -94,6 +100,12 @@ func ToolReplyToMessageID(ctx context.Context) string {
 	return v
 }
 
+// ToolSenderID extracts the inbound sender ID from ctx, or "" if unset.
+func ToolSenderID(ctx context.Context) string {
+	v, _ := ctx.Value(ctxKeySenderID).(string)
+	return v
+}
+
 // ToolAgentID extracts the active turn's agent ID from ctx, or "" if unset.
 func ToolAgentID(ctx context.Context) string {
 	v, _ := ctx.Value(ctxKeyAgentID).(string)
@@ -149,6 +161,24 @@ type AsyncExecutor interface {
 	ExecuteAsync(ctx context.Context, args map[string]any, cb AsyncCallback) *ToolResult
 }
 
+// SequentialTool is implemented by tools that must not run concurrently with
+// other tool calls requested in the same LLM response, e.g. because they
+// mutate files or depend on the effects of earlier calls.
+type SequentialTool interface {
+	Tool
+	Sequential() bool
+}
+
+// ConfirmableTool is implemented by tools whose effects are destructive or
+// hard to undo. ConfirmationPreview describes what the call would do; when
+// tools.confirm is enabled the agent shows it and waits for an explicit
+// approval before executing. An empty preview means the call is harmless and
+// needs no confirmation.
+type ConfirmableTool interface {
+	Tool
+	ConfirmationPreview(args map[string]any) string
+}
+
 func ToolToSchema(tool Tool) map[string]any {
 	return map[string]any{
 		"type": "function",
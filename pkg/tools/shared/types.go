@@ -76,6 +76,7 @@ type ExecResponse struct {
 	Output    string        `json:"output,omitempty"`
 	Error     string        `json:"error,omitempty"`
 	Sessions  []SessionInfo `json:"sessions,omitempty"`
+	LogFile   string        `json:"logFile,omitempty"`
 }
 
 type SessionInfo struct {
@@ -84,4 +85,5 @@ type SessionInfo struct {
 	Status    string `json:"status"`
 	PID       int    `json:"pid"`
 	StartedAt int64  `json:"startedAt"`
+	LogFile   string `json:"logFile,omitempty"`
 }
@@ -22,6 +22,8 @@ type (
 	Tool                   = toolshared.Tool
 	AsyncCallback          = toolshared.AsyncCallback
 	AsyncExecutor          = toolshared.AsyncExecutor
+	SequentialTool         = toolshared.SequentialTool
+	ConfirmableTool        = toolshared.ConfirmableTool
 	ToolResult             = toolshared.ToolResult
 )
 
@@ -53,6 +55,10 @@ func WithToolSessionContext(
 	return toolshared.WithToolSessionContext(ctx, agentID, sessionKey, scope)
 }
 
+func WithToolSenderContext(ctx context.Context, senderID string) context.Context {
+	return toolshared.WithToolSenderContext(ctx, senderID)
+}
+
 func ToolChannel(ctx context.Context) string {
 	return toolshared.ToolChannel(ctx)
 }
@@ -69,6 +75,10 @@ func ToolReplyToMessageID(ctx context.Context) string {
 	return toolshared.ToolReplyToMessageID(ctx)
 }
 
+func ToolSenderID(ctx context.Context) string {
+	return toolshared.ToolSenderID(ctx)
+}
+
 func ToolAgentID(ctx context.Context) string {
 	return toolshared.ToolAgentID(ctx)
 }
@@ -14,6 +14,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/creack/pty"
@@ -21,6 +22,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/isolation"
+	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
 var (
@@ -43,6 +45,17 @@ type ExecTool struct {
 	allowedPathPatterns []*regexp.Regexp
 	restrictToWorkspace bool
 	allowRemote         bool
+	isolateEnv          bool
+	reportExitCode      bool
+	jsonResults         bool
+	backgroundLogs      bool
+	backgroundLogMax    int64
+	commandHistory      bool
+	historySize         int
+	serializeCommands   bool
+	projectDirs         bool // exec defaults to the session's active project directory
+	env                 map[string]string
+	envMu               sync.Mutex // serializes set_env read-modify-write
 	sessionManager      *SessionManager
 }
 
@@ -163,20 +176,71 @@ func NewExecToolWithConfig(
 		denyPatterns = append(denyPatterns, defaultDenyPatterns...)
 	}
 
+	var allowPatterns []*regexp.Regexp
+	if cfg != nil {
+		for _, pattern := range cfg.Tools.Exec.AllowlistPatterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowlist pattern %q: %w", pattern, err)
+			}
+			allowPatterns = append(allowPatterns, re)
+		}
+	}
+
 	var timeout time.Duration
 	if cfg != nil && cfg.Tools.Exec.TimeoutSeconds > 0 {
 		timeout = time.Duration(cfg.Tools.Exec.TimeoutSeconds) * time.Second
 	}
 
+	var isolateEnv, reportExitCode, jsonResults, backgroundLogs, commandHistory, serializeCommands bool
+	var backgroundLogMax int64
+	historySize := defaultExecHistorySize
+	var env map[string]string
+	if cfg != nil {
+		isolateEnv = cfg.Tools.Exec.IsolateEnv
+		reportExitCode = cfg.Tools.Exec.ReportExitCode
+		switch cfg.Tools.Exec.ResultFormat {
+		case "", "text":
+		case "json":
+			jsonResults = true
+		default:
+			return nil, fmt.Errorf("invalid exec result_format %q (want \"text\" or \"json\")", cfg.Tools.Exec.ResultFormat)
+		}
+		for name := range cfg.Tools.Exec.Env {
+			if !envNamePattern.MatchString(name) {
+				return nil, fmt.Errorf("invalid exec env variable name %q", name)
+			}
+		}
+		env = cfg.Tools.Exec.Env
+		backgroundLogs = cfg.Tools.Exec.BackgroundLogs
+		backgroundLogMax = int64(cfg.Tools.Exec.BackgroundLogMaxKB) * 1024
+		commandHistory = cfg.Tools.Exec.CommandHistory
+		if cfg.Tools.Exec.CommandHistorySize > 0 {
+			historySize = cfg.Tools.Exec.CommandHistorySize
+		}
+		serializeCommands = cfg.Tools.Exec.SerializeCommands
+	}
+	projectDirs := cfg == nil || cfg.Tools.IsToolEnabled("project")
+
 	return &ExecTool{
 		workingDir:          workingDir,
 		timeout:             timeout,
 		denyPatterns:        denyPatterns,
-		allowPatterns:       nil,
+		allowPatterns:       allowPatterns,
 		customAllowPatterns: customAllowPatterns,
 		allowedPathPatterns: allowedPathPatterns,
 		restrictToWorkspace: restrict,
 		allowRemote:         allowRemote,
+		isolateEnv:          isolateEnv,
+		reportExitCode:      reportExitCode,
+		jsonResults:         jsonResults,
+		backgroundLogs:      backgroundLogs,
+		backgroundLogMax:    backgroundLogMax,
+		commandHistory:      commandHistory,
+		historySize:         historySize,
+		serializeCommands:   serializeCommands,
+		projectDirs:         projectDirs,
+		env:                 env,
 		sessionManager:      getSessionManager(),
 	}, nil
 }
@@ -185,8 +249,13 @@ func (t *ExecTool) Name() string {
 	return "exec"
 }
 
+// Sequential implements SequentialTool.
+func (t *ExecTool) Sequential() bool {
+	return true
+}
+
 func (t *ExecTool) Description() string {
-	return `Execute shell commands. Use background=true for long-running commands (returns sessionId). Use pty=true for interactive commands (can combine with background=true). Use poll/read/write/send-keys/kill with sessionId to manage background sessions. Sessions auto-cleanup 30 minutes after process exits; use kill to terminate early. Output buffer limit: 1MB.`
+	return `Execute shell commands. Use background=true for long-running commands (returns sessionId). Use pty=true for interactive commands (can combine with background=true). Use poll/read/write/send-keys/kill with sessionId to manage background sessions; write with newline=true types a line into an interactive program such as a REPL. Use set_env to save environment variables (e.g. PORT, NODE_ENV, API keys) for later commands and get_env to list them. Use history to see recent commands with their exit codes, e.g. when resuming earlier work. Sessions auto-cleanup 30 minutes after process exits; use kill to terminate early. Output buffer limit: 1MB.`
 }
 
 func (t *ExecTool) Parameters() map[string]any {
@@ -195,8 +264,13 @@ func (t *ExecTool) Parameters() map[string]any {
 		"properties": map[string]any{
 			"action": map[string]any{
 				"type":        "string",
-				"enum":        []string{"run", "list", "poll", "read", "write", "kill", "send-keys"},
-				"description": "Action: run (execute command), list (show sessions), poll (check status), read (get output), write (send input), kill (terminate), send-keys (send keys to PTY)",
+				"enum":        []string{"run", "list", "poll", "read", "write", "kill", "send-keys", "set_env", "get_env", "history"},
+				"description": "Action: run (execute command), list (show sessions), poll (check status and log file), read (get output), write (send input), kill (terminate), send-keys (send keys to PTY), set_env (save environment variables for later commands), get_env (show them), history (recent commands with exit codes)",
+			},
+			"env": map[string]any{
+				"type":                 "object",
+				"description":          "Variables for set_env, NAME to value. An empty value removes NAME.",
+				"additionalProperties": map[string]any{"type": "string"},
 			},
 			"command": map[string]any{
 				"type":        "string",
@@ -214,6 +288,10 @@ func (t *ExecTool) Parameters() map[string]any {
 				"type":        "string",
 				"description": "Data to write to stdin (required for write)",
 			},
+			"newline": map[string]any{
+				"type":        "boolean",
+				"description": "For write: press Enter after data, e.g. to submit a line to a REPL",
+			},
 			"background": map[string]any{
 				"type":        "string",
 				"description": "Run in background immediately",
@@ -224,12 +302,16 @@ func (t *ExecTool) Parameters() map[string]any {
 			},
 			"cwd": map[string]any{
 				"type":        "string",
-				"description": "Working directory for the command",
+				"description": "Working directory for the command (default: the active directory of the conversation's project, if any)",
 			},
 			"timeout": map[string]any{
 				"type":        "integer",
 				"description": "Timeout in seconds (0 = no timeout)",
 			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "For history: number of recent commands to show (default 20)",
+			},
 		},
 		"required": []string{"action"},
 	}
@@ -256,6 +338,21 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 		return t.executeKill(args)
 	case "send-keys":
 		return t.executeSendKeys(args)
+	case "set_env":
+		if blocked := t.remoteBlocked(ctx, args); blocked != nil {
+			return blocked
+		}
+		return t.executeSetEnv(args)
+	case "get_env":
+		if blocked := t.remoteBlocked(ctx, args); blocked != nil {
+			return blocked
+		}
+		return t.executeGetEnv()
+	case "history":
+		if blocked := t.remoteBlocked(ctx, args); blocked != nil {
+			return blocked
+		}
+		return t.executeHistory(args)
 	default:
 		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
 	}
@@ -267,17 +364,8 @@ func (t *ExecTool) executeRun(ctx context.Context, args map[string]any) *ToolRes
 		return ErrorResult("command is required")
 	}
 
-	// GHSA-pv8c-p6jf-3fpp: block exec from remote channels (e.g. Telegram webhooks)
-	// unless explicitly opted-in via config. Fail-closed: empty channel = blocked.
-	if !t.allowRemote {
-		channel := ToolChannel(ctx)
-		if channel == "" {
-			channel, _ = args["__channel"].(string)
-		}
-		channel = strings.TrimSpace(channel)
-		if channel == "" || !constants.IsInternalChannel(channel) {
-			return ErrorResult("exec is restricted to internal channels")
-		}
+	if blocked := t.remoteBlocked(ctx, args); blocked != nil {
+		return blocked
 	}
 
 	getBoolArg := func(key string) bool {
@@ -309,6 +397,12 @@ func (t *ExecTool) executeRun(ctx context.Context, args map[string]any) *ToolRes
 		} else {
 			cwd = wd
 		}
+	} else if t.projectDirs {
+		// The session's current project picks the directory; it was
+		// checked to be inside the workspace when it was attached.
+		if dir := activeProjectDir(ctx, t.workingDir); dir != "" {
+			cwd = dir
+		}
 	}
 
 	if cwd == "" {
@@ -345,6 +439,18 @@ func (t *ExecTool) executeRun(ctx context.Context, args map[string]any) *ToolRes
 		}
 	}
 
+	// Foreground commands hold the directory until they finish; background
+	// ones only while they start, so they never start mid-way through a
+	// foreground command in the same directory.
+	if t.serializeCommands {
+		unlock, err := lockExecDir(ctx, cwd)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("cancelled while waiting for another command in %s to finish: %v", cwd, err)).
+				WithError(err)
+		}
+		defer unlock()
+	}
+
 	if isBackground {
 		return t.runBackground(ctx, command, cwd, isPty)
 	}
@@ -352,6 +458,24 @@ func (t *ExecTool) executeRun(ctx context.Context, args map[string]any) *ToolRes
 	return t.runSync(ctx, command, cwd)
 }
 
+// remoteBlocked enforces GHSA-pv8c-p6jf-3fpp: exec is blocked from remote
+// channels (e.g. Telegram webhooks) unless explicitly opted-in via config.
+// Fail-closed: empty channel = blocked.
+func (t *ExecTool) remoteBlocked(ctx context.Context, args map[string]any) *ToolResult {
+	if t.allowRemote {
+		return nil
+	}
+	channel := ToolChannel(ctx)
+	if channel == "" {
+		channel, _ = args["__channel"].(string)
+	}
+	channel = strings.TrimSpace(channel)
+	if channel == "" || !constants.IsInternalChannel(channel) {
+		return ErrorResult("exec is restricted to internal channels")
+	}
+	return nil
+}
+
 func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult {
 	// timeout == 0 means no timeout
 	var cmdCtx context.Context
@@ -372,6 +496,11 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 	if cwd != "" {
 		cmd.Dir = cwd
 	}
+	env, envErr := t.commandEnv()
+	if envErr != nil {
+		return ErrorResult(fmt.Sprintf("failed to prepare environment: %v", envErr))
+	}
+	cmd.Env = env
 
 	prepareCommandForTermination(cmd)
 
@@ -385,6 +514,7 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 		return ErrorResult(fmt.Sprintf("failed to start command: %v", err))
 	}
 
+	started := time.Now()
 	done := make(chan error, 1)
 	go func() {
 		done <- cmd.Wait()
@@ -404,6 +534,12 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 			err = <-done
 		}
 	}
+	t.recordHistory(command, cwd, commandExitCode(err), false, started)
+
+	if t.jsonResults {
+		return t.structuredResult(stdout.String(), stderr.String(), err,
+			errors.Is(cmdCtx.Err(), context.DeadlineExceeded), time.Since(started))
+	}
 
 	output := stdout.String()
 	if stderr.Len() > 0 {
@@ -439,7 +575,10 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 		}
 	}
 
-	if output == "" {
+	// With reportExitCode a success gets an explicit marker instead, since
+	// "(no output)" reads to some models as a silent failure.
+	successMarker := err == nil && t.reportExitCode
+	if output == "" && !successMarker {
 		output = "(no output)"
 	}
 
@@ -447,6 +586,12 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 	if len(output) > maxLen {
 		output = output[:maxLen] + fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-maxLen)
 	}
+	if successMarker {
+		if output = strings.TrimRight(output, "\n"); output != "" {
+			output += "\n\n"
+		}
+		output += "[Command exited with code 0]"
+	}
 
 	if err != nil {
 		return &ToolResult{
@@ -463,6 +608,64 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 	}
 }
 
+// execResult is the JSON form of a foreground command's result, used when
+// tools.exec.result_format is "json".
+type execResult struct {
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMS int64  `json:"duration_ms"`
+	TimedOut   bool   `json:"timed_out"`
+	Signaled   bool   `json:"signaled,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// structuredResult encodes a finished command as execResult. ExitCode is -1
+// when the command did not exit on its own: it timed out, was killed by a
+// signal or could not be waited for.
+func (t *ExecTool) structuredResult(
+	stdout, stderr string,
+	err error,
+	timedOut bool,
+	elapsed time.Duration,
+) *ToolResult {
+	res := execResult{
+		Stdout:     truncateStream(stdout),
+		Stderr:     truncateStream(stderr),
+		DurationMS: elapsed.Milliseconds(),
+		TimedOut:   timedOut,
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case timedOut:
+		res.ExitCode = -1
+		res.Error = fmt.Sprintf("command timed out after %v", t.timeout)
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+		res.Signaled = res.ExitCode == -1
+	default:
+		res.ExitCode = -1
+		res.Error = err.Error()
+	}
+
+	data, _ := json.Marshal(res)
+	result := &ToolResult{ForLLM: string(data), ForUser: string(data), IsError: err != nil}
+	if timedOut {
+		result.Err = fmt.Errorf("command timeout: %w", err)
+	}
+	return result
+}
+
+// truncateStream caps one output stream at the same length as text results.
+func truncateStream(s string) string {
+	const maxLen = 10000
+	if len(s) > maxLen {
+		return s[:maxLen] + fmt.Sprintf("\n... (truncated, %d more chars)", len(s)-maxLen)
+	}
+	return s
+}
+
 func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEnabled bool) *ToolResult {
 	sessionID := generateSessionID()
 	session := &ProcessSession{
@@ -484,6 +687,11 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 	if cwd != "" {
 		cmd.Dir = cwd
 	}
+	env, envErr := t.commandEnv()
+	if envErr != nil {
+		return ErrorResult(fmt.Sprintf("failed to prepare environment: %v", envErr))
+	}
+	cmd.Env = env
 
 	prepareCommandForTermination(cmd)
 
@@ -533,23 +741,35 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 		return ErrorResult(fmt.Sprintf("failed to start command: %v", err))
 	}
 
+	started := time.Now()
 	session.PID = cmd.Process.Pid
-	t.sessionManager.Add(session)
-
 	session.outputBuffer = &bytes.Buffer{}
+	if t.backgroundLogs && t.workingDir != "" {
+		if log, err := openSessionLog(t.workingDir, command, sessionID, t.backgroundLogMax); err != nil {
+			logger.WarnCF("tools", "Failed to open background log", map[string]any{"error": err.Error()})
+		} else {
+			session.log = log
+			session.LogFile = log.path
+		}
+	}
+	t.sessionManager.Add(session)
 
 	// PTY mode: read from ptyMaster and wait for process
 	// Note: On Linux, closing ptyMaster doesn't interrupt blocking Read() calls,
 	// so we need cmd.Wait() in a separate goroutine to detect process exit.
 	if session.PTY && session.ptyMaster != nil {
 		go func() {
-			cmd.Wait() // Wait for process to exit
+			waitErr := cmd.Wait() // Wait for process to exit
 			session.mu.Lock()
 			if cmd.ProcessState != nil {
 				session.ExitCode = cmd.ProcessState.ExitCode()
 			}
 			session.Status = "done"
 			session.mu.Unlock()
+			t.recordHistory(command, cwd, commandExitCode(waitErr), true, started)
+			// The reader may still be draining the PTY; output it writes
+			// after this is kept in the buffer only.
+			session.closeLog()
 		}()
 
 		go func() {
@@ -562,16 +782,7 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 						session.SetPtyKeyMode(mode)
 					}
 
-					session.mu.Lock()
-					if session.outputBuffer.Len() >= maxOutputBufferSize {
-						if !session.outputTruncated {
-							session.outputBuffer.WriteString(outputTruncateMarker)
-							session.outputTruncated = true
-						}
-					} else {
-						session.outputBuffer.Write(buf[:n])
-					}
-					session.mu.Unlock()
+					session.appendOutput(buf[:n])
 				}
 				if err != nil {
 					break
@@ -589,16 +800,7 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 			for {
 				n, err := stdoutReader.Read(buf)
 				if n > 0 {
-					session.mu.Lock()
-					if session.outputBuffer.Len() >= maxOutputBufferSize {
-						if !session.outputTruncated {
-							session.outputBuffer.WriteString(outputTruncateMarker)
-							session.outputTruncated = true
-						}
-					} else {
-						session.outputBuffer.Write(buf[:n])
-					}
-					session.mu.Unlock()
+					session.appendOutput(buf[:n])
 				}
 				if err != nil {
 					break
@@ -609,16 +811,7 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 			for {
 				n, err := stderrReader.Read(buf)
 				if n > 0 {
-					session.mu.Lock()
-					if session.outputBuffer.Len() >= maxOutputBufferSize {
-						if !session.outputTruncated {
-							session.outputBuffer.WriteString(outputTruncateMarker)
-							session.outputTruncated = true
-						}
-					} else {
-						session.outputBuffer.Write(buf[:n])
-					}
-					session.mu.Unlock()
+					session.appendOutput(buf[:n])
 				}
 				if err != nil {
 					break
@@ -629,7 +822,8 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 			if stdinWriter != nil {
 				stdinWriter.Close()
 			}
-			cmd.Wait()
+			waitErr := cmd.Wait()
+			session.closeLog()
 
 			session.mu.Lock()
 			if cmd.ProcessState != nil {
@@ -637,12 +831,14 @@ func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEn
 			}
 			session.Status = "done"
 			session.mu.Unlock()
+			t.recordHistory(command, cwd, commandExitCode(waitErr), true, started)
 		}()
 	}
 
 	resp := ExecResponse{
 		SessionID: sessionID,
 		Status:    "running",
+		LogFile:   session.LogFile,
 	}
 	data, _ := json.Marshal(resp)
 	return &ToolResult{
@@ -683,6 +879,7 @@ func (t *ExecTool) executePoll(args map[string]any) *ToolResult {
 		SessionID: sessionID,
 		Status:    session.GetStatus(),
 		ExitCode:  session.GetExitCode(),
+		LogFile:   session.LogFile,
 	}
 	data, _ := json.Marshal(resp)
 	return &ToolResult{
@@ -711,6 +908,7 @@ func (t *ExecTool) executeRead(args map[string]any) *ToolResult {
 		SessionID: sessionID,
 		Output:    output,
 		Status:    session.GetStatus(),
+		LogFile:   session.LogFile,
 	}
 	data, _ := json.Marshal(resp)
 	return &ToolResult{
@@ -738,13 +936,27 @@ func (t *ExecTool) executeWrite(args map[string]any) *ToolResult {
 		return ErrorResult(err.Error())
 	}
 
+	switch v := args["newline"].(type) {
+	case bool:
+		if v {
+			data += session.enterKey()
+		}
+	case string:
+		if v == "true" {
+			data += session.enterKey()
+		}
+	}
+
 	if session.IsDone() {
-		return ErrorResult(fmt.Sprintf("process already exited with code %d", session.GetExitCode()))
+		return exitedSessionResult(session)
 	}
 
 	if err := session.Write(data); err != nil {
-		if errors.Is(err, ErrSessionDone) {
-			return ErrorResult(fmt.Sprintf("process already exited with code %d", session.GetExitCode()))
+		// The process may exit between the check above and the write, which
+		// then fails on the closed pipe.
+		if errors.Is(err, ErrSessionDone) || errors.Is(err, syscall.EPIPE) ||
+			errors.Is(err, os.ErrClosed) || errors.Is(err, io.ErrClosedPipe) {
+			return exitedSessionResult(session)
 		}
 		return ErrorResult(fmt.Sprintf("failed to write to session: %v", err))
 	}
@@ -760,6 +972,16 @@ func (t *ExecTool) executeWrite(args map[string]any) *ToolResult {
 	}
 }
 
+// exitedSessionResult reports that a session's process is gone, along with
+// any output it left unread, so the agent can see why it stopped.
+func exitedSessionResult(session *ProcessSession) *ToolResult {
+	msg := fmt.Sprintf("process already exited with code %d; input was not sent", session.GetExitCode())
+	if output := session.Read(); output != "" {
+		msg += "\n\nUnread output:\n" + output
+	}
+	return ErrorResult(msg)
+}
+
 func (t *ExecTool) executeKill(args map[string]any) *ToolResult {
 	sessionID, ok := args["sessionId"].(string)
 	if !ok {
@@ -1041,6 +1263,11 @@ func (t *ExecTool) guardCommand(command, cwd string) string {
 	}
 
 	if len(t.allowPatterns) > 0 {
+		// A line break starts a new command that a pattern written for one
+		// line would let through, e.g. "git status\nrm -rf ~".
+		if strings.ContainsAny(cmd, "\r\n") {
+			return "Command blocked by safety guard (not in allowlist: multi-line commands are not allowed)"
+		}
 		allowed := false
 		for _, pattern := range t.allowPatterns {
 			if pattern.MatchString(lower) {
@@ -0,0 +1,229 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// execEnvFile holds the variables set with the exec tool's set_env action,
+// relative to the tool's working directory.
+const execEnvFile = ".exec_env.json"
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// baseEnvNames are the host variables kept when isolate_env is on. They are
+// what shells and common toolchains need to find programs and behave sanely,
+// and carry no credentials.
+var baseEnvNames = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LC_ALL", "TERM", "TZ", "TMPDIR",
+	// Windows
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP",
+	"USERPROFILE", "APPDATA", "LOCALAPPDATA", "PROGRAMDATA", "PROGRAMFILES",
+}
+
+// protectedEnvNames are variables set_env refuses because they make the
+// dynamic loader or the shell run code of the agent's choosing in every
+// later command, or change which programs commands resolve to. The operator
+// can still set them in tools.exec.env.
+var protectedEnvNames = map[string]bool{
+	"PATH": true, "BASH_ENV": true, "ENV": true, "IFS": true, "CDPATH": true,
+	"SHELLOPTS": true, "BASHOPTS": true, "PROMPT_COMMAND": true, "PS4": true,
+}
+
+// protectedEnvPrefixes are the loader (LD_PRELOAD, DYLD_INSERT_LIBRARIES, ...)
+// and exported bash function prefixes set_env refuses.
+var protectedEnvPrefixes = []string{"LD_", "DYLD_", "BASH_FUNC_"}
+
+// isProtectedEnvName reports whether set_env must refuse name. Names are
+// compared in upper case, as Windows treats them case-insensitively.
+func isProtectedEnvName(name string) bool {
+	name = strings.ToUpper(name)
+	if protectedEnvNames[name] {
+		return true
+	}
+	for _, prefix := range protectedEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *ExecTool) envFilePath() string {
+	if t.workingDir == "" {
+		return ""
+	}
+	return filepath.Join(t.workingDir, execEnvFile)
+}
+
+// loadEnvFile returns the variables saved with set_env. A missing file is an
+// empty set.
+func (t *ExecTool) loadEnvFile() (map[string]string, error) {
+	path := t.envFilePath()
+	if path == "" {
+		return map[string]string{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var env map[string]string
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", execEnvFile, err)
+	}
+	if env == nil {
+		env = map[string]string{}
+	}
+	return env, nil
+}
+
+// commandEnv builds the environment for a command. It returns nil, meaning
+// "inherit the host environment", when there is nothing to change.
+func (t *ExecTool) commandEnv() ([]string, error) {
+	saved, err := t.loadEnvFile()
+	if err != nil {
+		return nil, err
+	}
+	if !t.isolateEnv && len(t.env) == 0 && len(saved) == 0 {
+		return nil, nil
+	}
+
+	var env []string
+	if t.isolateEnv {
+		env = baseHostEnv()
+	} else {
+		env = os.Environ()
+	}
+	// The env file is in the workspace, so protected names written to it
+	// by other means than set_env are ignored too.
+	for name := range saved {
+		if isProtectedEnvName(name) {
+			delete(saved, name)
+		}
+	}
+	// Later entries win in os/exec, so saved values override configured
+	// ones, which override the host.
+	for _, vars := range []map[string]string{t.env, saved} {
+		for _, name := range sortedEnvNames(vars) {
+			env = append(env, name+"="+vars[name])
+		}
+	}
+	return env, nil
+}
+
+func baseHostEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		for _, keep := range baseEnvNames {
+			if name == keep || (runtime.GOOS == "windows" && strings.EqualFold(name, keep)) {
+				env = append(env, kv)
+				break
+			}
+		}
+	}
+	return env
+}
+
+func sortedEnvNames(vars map[string]string) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t *ExecTool) executeSetEnv(args map[string]any) *ToolResult {
+	path := t.envFilePath()
+	if path == "" {
+		return ErrorResult("set_env needs a working directory to store variables in")
+	}
+	raw, ok := args["env"].(map[string]any)
+	if !ok || len(raw) == 0 {
+		return ErrorResult("env is required for set_env: an object of NAME to value, empty value removes NAME")
+	}
+
+	t.envMu.Lock()
+	defer t.envMu.Unlock()
+	env, err := t.loadEnvFile()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read saved environment: %v", err))
+	}
+	for name, value := range raw {
+		if !envNamePattern.MatchString(name) {
+			return ErrorResult(fmt.Sprintf("invalid environment variable name %q", name))
+		}
+		var s string
+		switch v := value.(type) {
+		case nil:
+		case string:
+			s = v
+		case float64, bool:
+			s = fmt.Sprint(v)
+		default:
+			return ErrorResult(fmt.Sprintf("value for %s must be a string", name))
+		}
+		if s == "" {
+			delete(env, name)
+		} else if isProtectedEnvName(name) {
+			return ErrorResult(fmt.Sprintf(
+				"set_env cannot set %s: it controls the loader, the shell or program lookup; ask the operator to set it in tools.exec.env",
+				name))
+		} else {
+			env[name] = s
+		}
+	}
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to encode environment: %v", err))
+	}
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to save environment: %v", err))
+	}
+	return SilentResult(fmt.Sprintf("Saved %d environment variable(s) for exec commands: %s",
+		len(env), strings.Join(sortedEnvNames(env), ", ")))
+}
+
+func (t *ExecTool) executeGetEnv() *ToolResult {
+	saved, err := t.loadEnvFile()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read saved environment: %v", err))
+	}
+
+	var sb strings.Builder
+	if t.isolateEnv {
+		sb.WriteString("Host environment: not inherited (only PATH, HOME and similar basics).\n")
+	} else {
+		sb.WriteString("Host environment: inherited.\n")
+	}
+	if len(t.env) > 0 {
+		sb.WriteString("\nFrom config (tools.exec.env):\n")
+		for _, name := range sortedEnvNames(t.env) {
+			fmt.Fprintf(&sb, "%s=%s\n", name, t.env[name])
+		}
+	}
+	if len(saved) > 0 {
+		sb.WriteString("\nSet with set_env:\n")
+		for _, name := range sortedEnvNames(saved) {
+			fmt.Fprintf(&sb, "%s=%s\n", name, saved[name])
+		}
+	}
+	if len(t.env) == 0 && len(saved) == 0 {
+		sb.WriteString("No extra variables are set.\n")
+	}
+	return SilentResult(strings.TrimRight(sb.String(), "\n"))
+}
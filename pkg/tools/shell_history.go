@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// execHistoryFile records the commands run with the exec tool, one JSON
+	// object per line, relative to the tool's working directory.
+	execHistoryFile        = ".exec_history.jsonl"
+	defaultExecHistorySize = 200
+	defaultHistoryLimit    = 20
+)
+
+// historyMu serializes history updates; several exec tools (the agent's and
+// its subagents') may share a working directory.
+var historyMu sync.Mutex
+
+// historyEntry is one finished command. ExitCode is -1 when the command did
+// not exit on its own or could not be waited for.
+type historyEntry struct {
+	Time       time.Time `json:"time"`
+	Command    string    `json:"command"`
+	Dir        string    `json:"dir,omitempty"`
+	ExitCode   int       `json:"exit_code"`
+	Background bool      `json:"background,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+func (t *ExecTool) historyFilePath() string {
+	if t.workingDir == "" {
+		return ""
+	}
+	return filepath.Join(t.workingDir, execHistoryFile)
+}
+
+// commandExitCode maps the result of cmd.Wait to the exit code recorded in
+// the history.
+func commandExitCode(err error) int {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode()
+	default:
+		return -1
+	}
+}
+
+// recordHistory appends a finished command to the history file, dropping
+// the oldest entries past historySize. Failures are logged, never returned:
+// the history must not change the outcome of a command.
+func (t *ExecTool) recordHistory(command, cwd string, exitCode int, background bool, started time.Time) {
+	path := t.historyFilePath()
+	if !t.commandHistory || path == "" {
+		return
+	}
+	entry := historyEntry{
+		Time:       started,
+		Command:    command,
+		Dir:        cwd,
+		ExitCode:   exitCode,
+		Background: background,
+		DurationMS: time.Since(started).Milliseconds(),
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	entries, err := loadHistory(path)
+	if err == nil {
+		entries = append(entries, entry)
+		if len(entries) > t.historySize {
+			entries = entries[len(entries)-t.historySize:]
+		}
+		err = writeHistory(path, entries)
+	}
+	if err != nil {
+		logger.WarnCF("tools", "Failed to record exec history", map[string]any{"error": err.Error()})
+	}
+}
+
+// loadHistory reads the history file, oldest entry first. A missing file is
+// an empty history and unparsable lines are skipped.
+func loadHistory(path string) ([]historyEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []historyEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry historyEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func writeHistory(path string, entries []historyEntry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return fileutil.WriteFileAtomic(path, buf.Bytes(), 0o600)
+}
+
+func (t *ExecTool) executeHistory(args map[string]any) *ToolResult {
+	if !t.commandHistory {
+		return ErrorResult("command history is disabled (set tools.exec.command_history to enable it)")
+	}
+	path := t.historyFilePath()
+	if path == "" {
+		return ErrorResult("history needs a working directory to store commands in")
+	}
+	limit := defaultHistoryLimit
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = int(v)
+	}
+
+	historyMu.Lock()
+	entries, err := loadHistory(path)
+	historyMu.Unlock()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read command history: %v", err))
+	}
+	if len(entries) == 0 {
+		return SilentResult("No commands recorded yet.")
+	}
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Last %d command(s), oldest first:\n", len(entries))
+	for _, entry := range entries {
+		fmt.Fprintf(&sb, "%s exit=%d %dms", entry.Time.Format(time.RFC3339), entry.ExitCode, entry.DurationMS)
+		if entry.Background {
+			sb.WriteString(" background")
+		}
+		if entry.Dir != "" && entry.Dir != t.workingDir {
+			fmt.Fprintf(&sb, " cwd=%s", entry.Dir)
+		}
+		fmt.Fprintf(&sb, " $ %s\n", entry.Command)
+	}
+	return SilentResult(strings.TrimRight(sb.String(), "\n"))
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+)
+
+// execDirLocks serializes exec commands per working directory when
+// tools.exec.serialize_commands is set. Parallel chats, or agents sharing a
+// workspace, would otherwise run builds in the same tree at once and corrupt
+// its state. Commands in different directories still run in parallel.
+//
+// Each lock is a one-slot channel rather than a sync.Mutex so a turn that
+// is cancelled while waiting stops waiting. An entry is removed once no
+// command holds or waits for it.
+var (
+	execDirLocksMu sync.Mutex
+	execDirLocks   = map[string]*execDirLock{}
+)
+
+type execDirLock struct {
+	slot chan struct{}
+	refs int // commands holding or waiting for the lock
+}
+
+// lockExecDir waits until no other command is running in dir and returns
+// the function that releases it.
+func lockExecDir(ctx context.Context, dir string) (func(), error) {
+	dir = filepath.Clean(dir)
+	execDirLocksMu.Lock()
+	lock, ok := execDirLocks[dir]
+	if !ok {
+		lock = &execDirLock{slot: make(chan struct{}, 1)}
+		execDirLocks[dir] = lock
+	}
+	lock.refs++
+	execDirLocksMu.Unlock()
+
+	select {
+	case lock.slot <- struct{}{}:
+		return func() {
+			<-lock.slot
+			releaseExecDir(dir, lock)
+		}, nil
+	case <-ctx.Done():
+		releaseExecDir(dir, lock)
+		return nil, ctx.Err()
+	}
+}
+
+func releaseExecDir(dir string, lock *execDirLock) {
+	execDirLocksMu.Lock()
+	defer execDirLocksMu.Unlock()
+	if lock.refs--; lock.refs == 0 {
+		delete(execDirLocks, dir)
+	}
+}
@@ -357,6 +357,80 @@ func TestShellTool_InternalChannelAllowed(t *testing.T) {
 	}
 }
 
+// TestShellTool_ReportExitCode verifies successful results carry the exit
+// code, and silent commands get no "(no output)" placeholder, when enabled
+func TestShellTool_ReportExitCode(t *testing.T) {
+	ctx := WithToolContext(context.Background(), "cli", "direct")
+	run := func(reportExitCode bool, command string) *ToolResult {
+		cfg := &config.Config{}
+		cfg.Tools.Exec.AllowRemote = true
+		cfg.Tools.Exec.ReportExitCode = reportExitCode
+		tool, err := NewExecToolWithConfig("", false, cfg)
+		if err != nil {
+			t.Fatalf("NewExecToolWithConfig() error: %v", err)
+		}
+		return tool.Execute(ctx, map[string]any{"action": "run", "command": command})
+	}
+
+	if got := run(false, "exit 0").ForLLM; got != "(no output)" {
+		t.Errorf("silent command without report_exit_code = %q, want (no output)", got)
+	}
+	if got := run(true, "exit 0").ForLLM; got != "[Command exited with code 0]" {
+		t.Errorf("silent command with report_exit_code = %q, want only the exit marker", got)
+	}
+	if got := run(true, "echo hi").ForLLM; got != "hi\n\n[Command exited with code 0]" {
+		t.Errorf("command with output = %q, want output then the exit marker", got)
+	}
+	if got := run(true, "exit 3"); !got.IsError || !strings.HasSuffix(got.ForLLM, "[Command exited with code 3]") {
+		t.Errorf("failing command = %+v, want an error ending in its exit code", got)
+	}
+}
+
+// TestShellTool_JSONResults verifies result_format "json" reports exit code,
+// separate streams and timeouts as fields
+func TestShellTool_JSONResults(t *testing.T) {
+	ctx := WithToolContext(context.Background(), "cli", "direct")
+	cfg := &config.Config{}
+	cfg.Tools.Exec.AllowRemote = true
+	cfg.Tools.Exec.ResultFormat = "json"
+	cfg.Tools.Exec.TimeoutSeconds = 1
+	tool, err := NewExecToolWithConfig("", false, cfg)
+	if err != nil {
+		t.Fatalf("NewExecToolWithConfig() error: %v", err)
+	}
+	run := func(command string) (execResult, *ToolResult) {
+		t.Helper()
+		result := tool.Execute(ctx, map[string]any{"action": "run", "command": command})
+		var res execResult
+		if err := json.Unmarshal([]byte(result.ForLLM), &res); err != nil {
+			t.Fatalf("result %q is not JSON: %v", result.ForLLM, err)
+		}
+		return res, result
+	}
+
+	res, result := run("echo out; echo err >&2")
+	if result.IsError || res.ExitCode != 0 || res.Stdout != "out\n" || res.Stderr != "err\n" || res.TimedOut {
+		t.Errorf("success = %+v (IsError %v)", res, result.IsError)
+	}
+
+	res, result = run("exit 4")
+	if !result.IsError || res.ExitCode != 4 || res.TimedOut {
+		t.Errorf("non-zero exit = %+v (IsError %v), want exit code 4", res, result.IsError)
+	}
+
+	if runtime.GOOS != "windows" {
+		res, result = run("sleep 5")
+		if !result.IsError || !res.TimedOut || res.ExitCode != -1 {
+			t.Errorf("timeout = %+v (IsError %v), want timed_out", res, result.IsError)
+		}
+	}
+
+	cfg.Tools.Exec.ResultFormat = "yaml"
+	if _, err := NewExecToolWithConfig("", false, cfg); err == nil {
+		t.Error("NewExecToolWithConfig() accepted result_format \"yaml\"")
+	}
+}
+
 // TestShellTool_EmptyChannelBlockedWhenNotAllowRemote verifies fail-closed when no channel context
 func TestShellTool_EmptyChannelBlockedWhenNotAllowRemote(t *testing.T) {
 	cfg := &config.Config{}
@@ -603,6 +677,37 @@ func TestShellTool_CustomAllowPatterns(t *testing.T) {
 	}
 }
 
+// TestShellTool_AllowlistPatterns verifies that a configured allowlist
+// refuses every command it does not match.
+func TestShellTool_AllowlistPatterns(t *testing.T) {
+	cfg := &config.Config{
+		Tools: config.ToolsConfig{
+			Exec: config.ExecConfig{
+				EnableDenyPatterns: true,
+				AllowlistPatterns:  []string{"^echo( [^;&|`$]*)?$"},
+			},
+		},
+	}
+
+	tool, err := NewExecToolWithConfig("", false, cfg)
+	require.NoError(t, err)
+	ctx := WithToolContext(context.Background(), "cli", "test")
+
+	result := tool.Execute(ctx, map[string]any{"action": "run", "command": "echo allowed"})
+	require.False(t, result.IsError, result.ForLLM)
+	require.Contains(t, result.ForLLM, "allowed")
+
+	for _, command := range []string{"ls", "echo allowed; ls", "echo allowed\nls", "echo allowed\r\nls"} {
+		result = tool.Execute(ctx, map[string]any{"action": "run", "command": command})
+		require.True(t, result.IsError, "%q was not refused", command)
+		require.Contains(t, result.ForLLM, "not in allowlist")
+	}
+
+	cfg.Tools.Exec.AllowlistPatterns = []string{"("}
+	_, err = NewExecToolWithConfig("", false, cfg)
+	require.ErrorContains(t, err, "invalid allowlist pattern")
+}
+
 // TestShellTool_URLsNotBlocked verifies that commands containing URLs are not
 // incorrectly blocked by the workspace restriction safety guard (issue #1203).
 func TestShellTool_URLsNotBlocked(t *testing.T) {
@@ -1471,6 +1576,129 @@ func TestShellTool_Background_ReadAfterExit(t *testing.T) {
 	require.Contains(t, readResp.Output, "world", "should contain world after sleep")
 }
 
+func TestShellTool_BackgroundLogFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+	workspace := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Tools.Exec.AllowRemote = true
+	cfg.Tools.Exec.BackgroundLogs = true
+	tool, err := NewExecToolWithConfig(workspace, false, cfg)
+	require.NoError(t, err)
+
+	ctx := WithToolContext(context.Background(), "cli", "direct")
+	command := "echo first && sleep 0.2 && echo second"
+	runResult := tool.Execute(ctx, map[string]any{
+		"action":     "run",
+		"command":    command,
+		"background": "true",
+	})
+	require.False(t, runResult.IsError, "run should succeed: %s", runResult.ForLLM)
+	var resp ExecResponse
+	require.NoError(t, json.Unmarshal([]byte(runResult.ForLLM), &resp))
+	require.Equal(t, backgroundLogPath(workspace, command), resp.LogFile)
+
+	// Drain the buffer before the process finishes; the log keeps everything.
+	time.Sleep(100 * time.Millisecond)
+	tool.Execute(ctx, map[string]any{"action": "read", "sessionId": resp.SessionID})
+
+	var pollResp ExecResponse
+	require.Eventually(t, func() bool {
+		result := tool.Execute(ctx, map[string]any{"action": "poll", "sessionId": resp.SessionID})
+		return json.Unmarshal([]byte(result.ForLLM), &pollResp) == nil && pollResp.Status == "done"
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, resp.LogFile, pollResp.LogFile)
+
+	data, err := os.ReadFile(resp.LogFile)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "session "+resp.SessionID+": "+command)
+	require.Contains(t, string(data), "first\nsecond\n")
+}
+
+func TestSessionLog_Rotates(t *testing.T) {
+	dir := t.TempDir()
+	log, err := openSessionLog(dir, "yes", "s1", 64)
+	require.NoError(t, err)
+	for range 10 {
+		_, err := log.Write([]byte("0123456789abcdef\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, log.Close())
+
+	for _, path := range []string{log.path, log.path + ".1"} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.LessOrEqual(t, info.Size(), int64(64))
+	}
+	_, err = log.Write([]byte("late"))
+	require.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestSessionLog_SharedBetweenRunsOfOneCommand(t *testing.T) {
+	dir := t.TempDir()
+	first, err := openSessionLog(dir, "yes", "s1", 1024)
+	require.NoError(t, err)
+	second, err := openSessionLog(dir, "yes", "s2", 1024)
+	require.NoError(t, err)
+	require.Equal(t, first.path, second.path)
+
+	_, err = first.Write([]byte("one "))
+	require.NoError(t, err)
+	_, err = second.Write([]byte("two\n"))
+	require.NoError(t, err)
+	require.NoError(t, second.Close())
+	_, err = first.Write([]byte("three\n"))
+	require.NoError(t, err, "closing one run must not close the file for the other")
+	require.NoError(t, first.Close())
+
+	data, err := os.ReadFile(first.path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "one \n--- session s2\ntwo\n--- session s1\nthree\n")
+}
+
+func TestShellTool_CommandHistory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+	workspace := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Tools.Exec.AllowRemote = true
+	cfg.Tools.Exec.CommandHistory = true
+	cfg.Tools.Exec.CommandHistorySize = 3
+	tool, err := NewExecToolWithConfig(workspace, false, cfg)
+	require.NoError(t, err)
+
+	ctx := WithToolContext(context.Background(), "cli", "direct")
+	for _, command := range []string{"echo one", "echo two", "exit 3"} {
+		tool.Execute(ctx, map[string]any{"action": "run", "command": command})
+	}
+	bg := tool.Execute(ctx, map[string]any{"action": "run", "command": "echo four", "background": "true"})
+	require.False(t, bg.IsError, "run should succeed: %s", bg.ForLLM)
+
+	path := filepath.Join(workspace, execHistoryFile)
+	var entries []historyEntry
+	require.Eventually(t, func() bool {
+		entries, err = loadHistory(path)
+		return err == nil && len(entries) == 3 && entries[2].Command == "echo four"
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, "echo two", entries[0].Command, "oldest entry should be dropped past the cap")
+	require.Equal(t, 3, entries[1].ExitCode)
+	require.True(t, entries[2].Background)
+	require.False(t, entries[0].Time.IsZero())
+
+	result := tool.Execute(ctx, map[string]any{"action": "history", "limit": float64(2)})
+	require.False(t, result.IsError, result.ForLLM)
+	require.NotContains(t, result.ForLLM, "echo two")
+	require.Contains(t, result.ForLLM, "exit=3")
+	require.Contains(t, result.ForLLM, "background $ echo four")
+
+	cfg.Tools.Exec.CommandHistory = false
+	disabled, err := NewExecToolWithConfig(workspace, false, cfg)
+	require.NoError(t, err)
+	require.True(t, disabled.Execute(ctx, map[string]any{"action": "history"}).IsError)
+}
+
 func TestSendKeys_CtrlC(t *testing.T) {
 	// Note: Ctrl-C as a signal requires sending SIGINT to the process group,
 	// which requires elevated privileges. Writing "\x03" to PTY passes the byte
@@ -1613,3 +1841,199 @@ func TestEncodeKeyTokenWithPtyKeyMode(t *testing.T) {
 		})
 	}
 }
+
+func TestShellTool_SetEnvPersistsForCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh variable expansion")
+	}
+	workspace := t.TempDir()
+	tool, err := NewExecTool(workspace, false)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "set_env",
+		"env":    map[string]any{"PORT": "8080", "NODE_ENV": "production"},
+	})
+	require.False(t, result.IsError, result.ForLLM)
+
+	result = tool.Execute(ctx, map[string]any{"action": "run", "command": "echo $PORT-$NODE_ENV"})
+	require.False(t, result.IsError, result.ForLLM)
+	require.Contains(t, result.ForLLM, "8080-production")
+
+	// A new tool on the same directory sees the saved variables.
+	reopened, err := NewExecTool(workspace, false)
+	require.NoError(t, err)
+	result = reopened.Execute(ctx, map[string]any{"action": "get_env"})
+	require.Contains(t, result.ForLLM, "PORT=8080")
+
+	result = reopened.Execute(ctx, map[string]any{"action": "set_env", "env": map[string]any{"PORT": ""}})
+	require.False(t, result.IsError, result.ForLLM)
+	result = reopened.Execute(ctx, map[string]any{"action": "run", "command": "echo \"[$PORT]\""})
+	require.Contains(t, result.ForLLM, "[]")
+
+	result = tool.Execute(ctx, map[string]any{"action": "set_env", "env": map[string]any{"BAD-NAME": "x"}})
+	require.True(t, result.IsError)
+}
+
+func TestShellTool_SetEnvRefusesLoaderAndShellHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh variable expansion")
+	}
+	workspace := t.TempDir()
+	tool, err := NewExecTool(workspace, false)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, name := range []string{"LD_PRELOAD", "ld_library_path", "DYLD_INSERT_LIBRARIES", "BASH_ENV", "PATH"} {
+		result := tool.Execute(ctx, map[string]any{
+			"action": "set_env",
+			"env":    map[string]any{name: "/tmp/evil", "PORT": "8080"},
+		})
+		require.True(t, result.IsError, "set_env accepted %s", name)
+		require.Contains(t, result.ForLLM, "cannot set")
+	}
+	saved, err := tool.loadEnvFile()
+	require.NoError(t, err)
+	require.Empty(t, saved, "a refused call must not save the other variables")
+
+	// Names written to the env file directly are ignored as well.
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, execEnvFile),
+		[]byte(`{"BASH_ENV": "/tmp/evil", "PORT": "8080"}`), 0o600))
+	result := tool.Execute(ctx, map[string]any{"action": "run", "command": "echo \"[$BASH_ENV][$PORT]\""})
+	require.False(t, result.IsError, result.ForLLM)
+	require.Contains(t, result.ForLLM, "[][8080]")
+
+	// A protected name can still be removed.
+	result = tool.Execute(ctx, map[string]any{"action": "set_env", "env": map[string]any{"BASH_ENV": ""}})
+	require.False(t, result.IsError, result.ForLLM)
+}
+
+func TestShellTool_IsolateEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh variable expansion")
+	}
+	t.Setenv("PICOCLAW_TEST_HOST_SECRET", "leaked")
+	cfg := &config.Config{}
+	cfg.Tools.Exec.AllowRemote = true
+	cfg.Tools.Exec.IsolateEnv = true
+	cfg.Tools.Exec.Env = map[string]string{"APP_MODE": "sandbox"}
+
+	tool, err := NewExecToolWithConfig(t.TempDir(), false, cfg)
+	require.NoError(t, err)
+	result := tool.Execute(context.Background(), map[string]any{
+		"action":  "run",
+		"command": "echo \"[$PICOCLAW_TEST_HOST_SECRET][$APP_MODE]\"; command -v sh",
+	})
+	require.False(t, result.IsError, result.ForLLM)
+	require.Contains(t, result.ForLLM, "[][sandbox]")
+
+	cfg.Tools.Exec.IsolateEnv = false
+	tool, err = NewExecToolWithConfig(t.TempDir(), false, cfg)
+	require.NoError(t, err)
+	result = tool.Execute(context.Background(), map[string]any{
+		"action":  "run",
+		"command": "echo \"[$PICOCLAW_TEST_HOST_SECRET][$APP_MODE]\"",
+	})
+	require.Contains(t, result.ForLLM, "[leaked][sandbox]")
+}
+
+func TestShellTool_SetEnvBlockedFromRemoteChannel(t *testing.T) {
+	cfg := &config.Config{}
+	tool, err := NewExecToolWithConfig(t.TempDir(), false, cfg)
+	require.NoError(t, err)
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+	result := tool.Execute(ctx, map[string]any{"action": "set_env", "env": map[string]any{"A": "1"}})
+	require.True(t, result.IsError)
+	require.Contains(t, result.ForLLM, "restricted to internal channels")
+}
+
+func TestShellTool_WriteNewlineDrivesInteractiveProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh read")
+	}
+	tool, err := NewExecTool("", false)
+	require.NoError(t, err)
+	tool.sessionManager = NewSessionManager()
+	ctx := WithToolContext(context.Background(), "cli", "test")
+
+	result := tool.Execute(ctx, map[string]any{
+		"action":     "run",
+		"command":    "read a; echo got-$a; read b; echo got-$b",
+		"background": true,
+	})
+	require.False(t, result.IsError, result.ForLLM)
+	var resp ExecResponse
+	require.NoError(t, json.Unmarshal([]byte(result.ForLLM), &resp))
+
+	write := func(data string) *ToolResult {
+		return tool.Execute(ctx, map[string]any{
+			"action":    "write",
+			"sessionId": resp.SessionID,
+			"data":      data,
+			"newline":   true,
+		})
+	}
+	result = write("one")
+	require.False(t, result.IsError, result.ForLLM)
+	result = write("two")
+	require.False(t, result.IsError, result.ForLLM)
+
+	session, err := tool.sessionManager.Get(resp.SessionID)
+	require.NoError(t, err)
+	require.Eventually(t, session.IsDone, 3*time.Second, 20*time.Millisecond)
+
+	// Writing to the exited process reports it along with its unread output.
+	result = write("three")
+	require.True(t, result.IsError)
+	require.Contains(t, result.ForLLM, "process already exited with code 0")
+	require.Contains(t, result.ForLLM, "got-one")
+	require.Contains(t, result.ForLLM, "got-two")
+}
+
+func TestShellTool_SerializesCommandsInSameDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Tools.Exec.SerializeCommands = true
+	tool, err := NewExecToolWithConfig(dir, false, cfg)
+	require.NoError(t, err)
+	ctx := WithToolContext(context.Background(), "cli", "test")
+
+	// mkdir fails if the other command is still inside its critical section.
+	results := make(chan *ToolResult, 2)
+	for range 2 {
+		go func() {
+			results <- tool.Execute(ctx, map[string]any{
+				"action":  "run",
+				"command": "mkdir busy && sleep 0.3 && rmdir busy",
+			})
+		}()
+	}
+	for range 2 {
+		result := <-results
+		require.False(t, result.IsError, result.ForLLM)
+	}
+}
+
+func TestLockExecDir_OtherDirectoriesRunInParallel(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	unlockA, err := lockExecDir(context.Background(), dirA)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	unlockB, err := lockExecDir(ctx, dirB)
+	require.NoError(t, err, "a command in another directory had to wait")
+	unlockB()
+
+	_, err = lockExecDir(ctx, dirA+string(filepath.Separator))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlockA()
+	execDirLocksMu.Lock()
+	defer execDirLocksMu.Unlock()
+	require.Empty(t, execDirLocks, "locks no command holds or waits for were kept")
+}
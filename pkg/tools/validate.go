@@ -3,11 +3,28 @@ package tools
 import (
 	"fmt"
 	"math"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
-// validateToolArgs validates args against a JSON Schema-like map.
-// schema is expected to have optional keys: "properties", "required", "additionalProperties".
+// validateToolArgs validates args against a tool's JSON Schema parameters.
+// The common keywords ("properties", "required", "type", "enum",
+// "additionalProperties") are checked here first, with messages written for
+// the model to act on; unknown properties are rejected unless
+// additionalProperties is true. Arguments that pass are then validated
+// against the full schema by jsonschema-go, which covers the rest of the
+// vocabulary (minimum, pattern, anyOf, ...).
 func validateToolArgs(schema map[string]any, args map[string]any) error {
+	if err := checkArgs(schema, args); err != nil {
+		return err
+	}
+	return validateSchemaKeywords(schema, args)
+}
+
+// checkArgs validates args against the common keywords of schema, recursing
+// into nested objects.
+func checkArgs(schema map[string]any, args map[string]any) error {
 	if len(schema) == 0 {
 		return nil
 	}
@@ -94,21 +111,54 @@ func allowsAdditional(schema map[string]any) bool {
 	return ok && b
 }
 
-// checkType validates that val matches the JSON Schema type declared in propSchema.
+// checkType validates that val matches the JSON Schema type declared in
+// propSchema, then checks its enum. "type" may be a single name or a list of
+// names such as ["string", "null"].
 func checkType(key string, val any, propSchema map[string]any) error {
-	typeRaw, ok := propSchema["type"]
-	if !ok {
-		return nil // no type constraint
+	var typeNames []string
+	switch t := propSchema["type"].(type) {
+	case string:
+		typeNames = []string{t}
+	case []string:
+		typeNames = t
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				typeNames = append(typeNames, s)
+			}
+		}
 	}
-	typeName, ok := typeRaw.(string)
-	if !ok {
-		return nil
+
+	if len(typeNames) > 0 {
+		var err error
+		for _, typeName := range typeNames {
+			if err = checkTypeName(key, val, typeName, propSchema); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			if len(typeNames) > 1 {
+				return fmt.Errorf("property %q: expected one of %s, got %s",
+					key, strings.Join(typeNames, ", "), jsonTypeName(val))
+			}
+			return err
+		}
 	}
 
+	return checkEnum(key, val, propSchema)
+}
+
+// checkTypeName validates val against a single JSON Schema type name.
+// Unknown type names are accepted.
+func checkTypeName(key string, val any, typeName string, propSchema map[string]any) error {
 	switch typeName {
+	case "null":
+		if val != nil {
+			return fmt.Errorf("property %q: expected null, got %s", key, jsonTypeName(val))
+		}
 	case "string":
 		if _, ok := val.(string); !ok {
-			return fmt.Errorf("property %q: expected string, got %T", key, val)
+			return fmt.Errorf("property %q: expected string, got %s", key, jsonTypeName(val))
 		}
 	case "integer":
 		switch v := val.(type) {
@@ -121,23 +171,23 @@ func checkType(key string, val any, propSchema map[string]any) error {
 		case int64:
 			// ok
 		default:
-			return fmt.Errorf("property %q: expected integer, got %T", key, val)
+			return fmt.Errorf("property %q: expected integer, got %s", key, jsonTypeName(val))
 		}
 	case "number":
 		switch val.(type) {
 		case float64, int, int64:
 			// ok
 		default:
-			return fmt.Errorf("property %q: expected number, got %T", key, val)
+			return fmt.Errorf("property %q: expected number, got %s", key, jsonTypeName(val))
 		}
 	case "boolean":
 		if _, ok := val.(bool); !ok {
-			return fmt.Errorf("property %q: expected boolean, got %T", key, val)
+			return fmt.Errorf("property %q: expected boolean, got %s", key, jsonTypeName(val))
 		}
 	case "array":
 		arr, ok := val.([]any)
 		if !ok {
-			return fmt.Errorf("property %q: expected array, got %T", key, val)
+			return fmt.Errorf("property %q: expected array, got %s", key, jsonTypeName(val))
 		}
 		if err := checkArrayItems(key, arr, propSchema); err != nil {
 			return err
@@ -145,18 +195,34 @@ func checkType(key string, val any, propSchema map[string]any) error {
 	case "object":
 		obj, ok := val.(map[string]any)
 		if !ok {
-			return fmt.Errorf("property %q: expected object, got %T", key, val)
+			return fmt.Errorf("property %q: expected object, got %s", key, jsonTypeName(val))
 		}
-		if err := validateToolArgs(propSchema, obj); err != nil {
+		if err := checkArgs(propSchema, obj); err != nil {
 			return fmt.Errorf("property %q: %w", key, err)
 		}
 	}
+	return nil
+}
 
-	if err := checkEnum(key, val, propSchema); err != nil {
-		return err
+// jsonTypeName names the JSON type of a decoded argument value, so errors
+// read in the same vocabulary as the schema the model was given.
+func jsonTypeName(val any) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", val)
 	}
-
-	return nil
 }
 
 // checkArrayItems validates each element of arr against the "items" sub-schema.
@@ -180,30 +246,35 @@ func checkArrayItems(key string, arr []any, propSchema map[string]any) error {
 
 // checkEnum validates that val is one of the allowed enum values in propSchema.
 func checkEnum(key string, val any, propSchema map[string]any) error {
-	enumRaw, ok := propSchema["enum"]
-	if !ok {
-		return nil
-	}
-
-	switch ev := enumRaw.(type) {
+	var allowed []any
+	switch ev := propSchema["enum"].(type) {
 	case []any:
-		for _, allowed := range ev {
-			if val == allowed {
-				return nil
-			}
-		}
+		allowed = ev
 	case []string:
-		s, ok := val.(string)
-		if ok {
-			for _, allowed := range ev {
-				if s == allowed {
-					return nil
-				}
-			}
+		for _, v := range ev {
+			allowed = append(allowed, v)
 		}
 	default:
-		return nil // unknown enum format, skip
+		return nil // no enum, or an unknown enum format
+	}
+
+	for _, a := range allowed {
+		if reflect.DeepEqual(val, a) {
+			return nil
+		}
 	}
 
-	return fmt.Errorf("property %q: value %v is not in enum", key, val)
+	quoted := make([]string, len(allowed))
+	for i, a := range allowed {
+		quoted[i] = formatEnumValue(a)
+	}
+	return fmt.Errorf("property %q: value %s is not in enum [%s]",
+		key, formatEnumValue(val), strings.Join(quoted, ", "))
+}
+
+func formatEnumValue(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("%v", v)
 }
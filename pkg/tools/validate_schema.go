@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// resolvedSchemas caches compiled tool schemas by their JSON encoding. A nil
+// entry records a schema jsonschema-go cannot load, such as an MCP server's
+// schema with a remote $ref; those tools get only the checks in checkArgs.
+var resolvedSchemas sync.Map // string -> *jsonschema.Resolved
+
+// validateSchemaKeywords validates args against the full JSON Schema.
+func validateSchemaKeywords(schema map[string]any, args map[string]any) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	resolved := resolveSchema(schema)
+	if resolved == nil {
+		return nil
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	if err := resolved.Validate(args); err != nil {
+		return schemaError(err)
+	}
+	return nil
+}
+
+func resolveSchema(schema map[string]any) *jsonschema.Resolved {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	key := string(data)
+	if cached, ok := resolvedSchemas.Load(key); ok {
+		resolved, _ := cached.(*jsonschema.Resolved)
+		return resolved
+	}
+
+	var resolved *jsonschema.Resolved
+	var s jsonschema.Schema
+	if err := json.Unmarshal(data, &s); err == nil {
+		resolved, _ = s.Resolve(nil)
+	}
+	resolvedSchemas.Store(key, resolved)
+	return resolved
+}
+
+// schemaError strips the "validating <schema path>: " prefixes jsonschema-go
+// adds at every level and keeps the innermost path, so
+// "validating root: validating /properties/count: minimum: ..." becomes
+// "at /properties/count: minimum: ...".
+func schemaError(err error) error {
+	msg := err.Error()
+	path := ""
+	for strings.HasPrefix(msg, "validating ") {
+		at, rest, ok := strings.Cut(strings.TrimPrefix(msg, "validating "), ": ")
+		if !ok {
+			break
+		}
+		path, msg = at, rest
+	}
+	if path == "" || path == "root" {
+		return fmt.Errorf("%s", msg)
+	}
+	return fmt.Errorf("at %s: %s", path, msg)
+}
@@ -273,6 +273,76 @@ func TestValidateToolArgs(t *testing.T) {
 			},
 			args: map[string]any{"anything": "goes"},
 		},
+		{
+			name: "enum checked without a type",
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"mode": map[string]any{"enum": []any{"fast", "slow"}},
+				},
+			},
+			args:    map[string]any{"mode": "medium"},
+			wantErr: `value "medium" is not in enum ["fast", "slow"]`,
+		},
+		{
+			name: "type list accepts null",
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"label": map[string]any{"type": []any{"string", "null"}},
+				},
+			},
+			args: map[string]any{"label": nil},
+		},
+		{
+			name: "type list rejects other types",
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"label": map[string]any{"type": []any{"string", "null"}},
+				},
+			},
+			args:    map[string]any{"label": float64(3)},
+			wantErr: `property "label": expected one of string, null, got number`,
+		},
+		{
+			name:    "type errors use JSON type names",
+			schema:  baseSchema,
+			args:    map[string]any{"name": []any{"a"}},
+			wantErr: "expected string, got array",
+		},
+		{
+			name: "minimum enforced by the schema library",
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"count": map[string]any{"type": "integer", "minimum": 1},
+				},
+			},
+			args:    map[string]any{"count": float64(0)},
+			wantErr: "at /properties/count: minimum",
+		},
+		{
+			name: "pattern enforced by the schema library",
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id": map[string]any{"type": "string", "pattern": "^[a-z]+$"},
+				},
+			},
+			args:    map[string]any{"id": "ABC"},
+			wantErr: "does not match regular expression",
+		},
+		{
+			name: "unloadable schema falls back to the built-in checks",
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"doc": map[string]any{"$ref": "https://example.com/doc.json"},
+				},
+			},
+			args: map[string]any{"doc": "anything"},
+		},
 		{
 			name:   "empty schema accepts anything",
 			schema: map[string]any{},
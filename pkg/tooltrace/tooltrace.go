@@ -0,0 +1,249 @@
+// Package tooltrace records agent tool invocations to per-session JSONL files
+// and loads them back so a session's tool-call timeline can be inspected.
+package tooltrace
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// MaxResultChars caps the tool result text stored per record so a single
+	// large read_file or web_fetch does not bloat the trace.
+	MaxResultChars = 8192
+
+	defaultQueueSize = 256
+	fileSuffix       = ".jsonl"
+)
+
+// Record is one tool invocation as written to the trace file.
+type Record struct {
+	Time       time.Time      `json:"time"`
+	SessionKey string         `json:"session_key"`
+	AgentID    string         `json:"agent_id,omitempty"`
+	TurnID     string         `json:"turn_id,omitempty"`
+	Iteration  int            `json:"iteration,omitempty"`
+	Tool       string         `json:"tool"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	Result     string         `json:"result,omitempty"`
+	Truncated  bool           `json:"truncated,omitempty"`
+	IsError    bool           `json:"is_error,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+}
+
+// Recorder appends Records to <dir>/<session>.jsonl from a background
+// goroutine. Record never blocks the caller: when the queue is full the
+// record is dropped and counted. Files are opened only for the batch of
+// records being written, so idle sessions hold no descriptors.
+type Recorder struct {
+	dir     string
+	queue   chan Record
+	done    chan struct{}
+	dropped atomic.Int64
+
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+}
+
+// NewRecorder creates a Recorder writing under dir and starts its writer.
+func NewRecorder(dir string) *Recorder {
+	r := &Recorder{
+		dir:   dir,
+		queue: make(chan Record, defaultQueueSize),
+		done:  make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Dir returns the directory trace files are written to.
+func (r *Recorder) Dir() string {
+	return r.dir
+}
+
+// Record enqueues rec for writing. Results longer than MaxResultChars are
+// truncated.
+func (r *Recorder) Record(rec Record) {
+	if r == nil || rec.SessionKey == "" {
+		return
+	}
+	if utf8.RuneCountInString(rec.Result) > MaxResultChars {
+		rec.Result = string([]rune(rec.Result)[:MaxResultChars])
+		rec.Truncated = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.queue <- rec:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many records were discarded because the queue was full.
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close flushes queued records and stops the writer.
+func (r *Recorder) Close() error {
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		close(r.queue)
+		r.mu.Unlock()
+		<-r.done
+	})
+	return nil
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	for rec := range r.queue {
+		batch := r.drain([]Record{rec})
+		for _, name := range batch.order {
+			if err := r.appendLines(name, batch.lines[name]); err != nil {
+				logger.WarnCF("tooltrace", "Failed to write tool trace",
+					map[string]any{
+						"file":  name,
+						"error": err.Error(),
+					})
+			}
+		}
+	}
+}
+
+// traceBatch holds encoded records grouped by trace file, in arrival order.
+type traceBatch struct {
+	order []string
+	lines map[string][]byte
+}
+
+// drain collects the records already queued behind first, without waiting
+// for more, and encodes them per trace file.
+func (r *Recorder) drain(batch []Record) traceBatch {
+collect:
+	for len(batch) < defaultQueueSize {
+		select {
+		case rec, ok := <-r.queue:
+			if !ok {
+				break collect
+			}
+			batch = append(batch, rec)
+		default:
+			break collect
+		}
+	}
+
+	tb := traceBatch{lines: make(map[string][]byte)}
+	for _, rec := range batch {
+		name, line, err := encodeRecord(rec)
+		if err != nil {
+			logger.WarnCF("tooltrace", "Failed to encode tool trace",
+				map[string]any{
+					"session_key": rec.SessionKey,
+					"error":       err.Error(),
+				})
+			continue
+		}
+		if _, seen := tb.lines[name]; !seen {
+			tb.order = append(tb.order, name)
+		}
+		tb.lines[name] = append(append(tb.lines[name], line...), '\n')
+	}
+	return tb
+}
+
+func encodeRecord(rec Record) (string, []byte, error) {
+	name, err := fileName(rec.SessionKey)
+	if err != nil {
+		return "", nil, err
+	}
+	line, err := json.Marshal(rec)
+	return name, line, err
+}
+
+// appendLines opens the trace file, appends data and closes it again.
+func (r *Recorder) appendLines(name string, data []byte) error {
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(r.dir, name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadSession reads the trace for sessionKey from dir and returns its records
+// in chronological order. Malformed lines (e.g. a partial write at crash time)
+// are skipped. A missing trace yields an empty timeline.
+func LoadSession(dir, sessionKey string) ([]Record, error) {
+	name, err := fileName(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read trace %s: %w", name, err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
+
+// fileName maps a session key onto a safe file name, mirroring how session
+// files are named.
+func fileName(sessionKey string) (string, error) {
+	s := strings.ReplaceAll(sessionKey, ":", "_")
+	s = strings.ReplaceAll(s, "/", "_")
+	s = strings.ReplaceAll(s, "\\", "_")
+	if s == "" || s == "." || !filepath.IsLocal(s) {
+		return "", fmt.Errorf("invalid session key %q", sessionKey)
+	}
+	return s + fileSuffix, nil
+}
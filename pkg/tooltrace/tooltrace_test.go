@@ -0,0 +1,104 @@
+package tooltrace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir)
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r.Record(Record{Time: base.Add(time.Second), SessionKey: "agent:main:s1", Tool: "exec", IsError: true, Error: "boom"})
+	r.Record(Record{Time: base, SessionKey: "agent:main:s1", Tool: "read_file", Arguments: map[string]any{"path": "a.txt"}})
+	r.Record(Record{Time: base, SessionKey: "agent:main:s2", Tool: "list_dir"})
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	records, err := LoadSession(dir, "agent:main:s1")
+	if err != nil {
+		t.Fatalf("LoadSession failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].Tool != "read_file" || records[1].Tool != "exec" {
+		t.Fatalf("records not in chronological order: %+v", records)
+	}
+	if records[0].Arguments["path"] != "a.txt" {
+		t.Errorf("arguments not preserved: %+v", records[0].Arguments)
+	}
+	if !records[1].IsError || records[1].Error != "boom" {
+		t.Errorf("error fields not preserved: %+v", records[1])
+	}
+}
+
+func TestRecorder_TruncatesLongResults(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir)
+	r.Record(Record{SessionKey: "s", Tool: "web_fetch", Result: strings.Repeat("x", MaxResultChars+10)})
+	r.Close()
+
+	records, err := LoadSession(dir, "s")
+	if err != nil || len(records) != 1 {
+		t.Fatalf("LoadSession = %v, %v", records, err)
+	}
+	if len(records[0].Result) != MaxResultChars || !records[0].Truncated {
+		t.Fatalf("result not truncated: len=%d truncated=%v", len(records[0].Result), records[0].Truncated)
+	}
+}
+
+func TestRecorder_TruncatesOnRuneBoundary(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir)
+	r.Record(Record{SessionKey: "s", Tool: "read_file", Result: strings.Repeat("é", MaxResultChars+1)})
+	r.Close()
+
+	records, err := LoadSession(dir, "s")
+	if err != nil || len(records) != 1 {
+		t.Fatalf("LoadSession = %v, %v", records, err)
+	}
+	if want := strings.Repeat("é", MaxResultChars); records[0].Result != want || !records[0].Truncated {
+		t.Fatalf("result = %d bytes (truncated=%v), want %d whole runes",
+			len(records[0].Result), records[0].Truncated, MaxResultChars)
+	}
+}
+
+func TestRecorder_RecordAfterCloseIsIgnored(t *testing.T) {
+	r := NewRecorder(t.TempDir())
+	r.Close()
+	r.Record(Record{SessionKey: "s", Tool: "exec"})
+	r.Close()
+}
+
+func TestLoadSession_SkipsMalformedLinesAndMissingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	records, err := LoadSession(dir, "missing")
+	if err != nil || len(records) != 0 {
+		t.Fatalf("LoadSession(missing) = %v, %v; want empty", records, err)
+	}
+
+	data := `{"session_key":"s","tool":"a"}` + "\n" + `{"session_key":"s","tool":` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "s.jsonl"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	records, err = LoadSession(dir, "s")
+	if err != nil {
+		t.Fatalf("LoadSession failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Tool != "a" {
+		t.Fatalf("expected only the valid record, got %+v", records)
+	}
+}
+
+func TestLoadSession_RejectsInvalidKey(t *testing.T) {
+	if _, err := LoadSession(t.TempDir(), ".."); err == nil {
+		t.Fatal("expected error for invalid session key")
+	}
+}
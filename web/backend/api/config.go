@@ -342,6 +342,9 @@ func validateConfig(cfg *config.Config) []string {
 		errs = append(
 			errs,
 			validateRegexPatterns("tools.exec.custom_allow_patterns", cfg.Tools.Exec.CustomAllowPatterns)...)
+		errs = append(
+			errs,
+			validateRegexPatterns("tools.exec.allowlist_patterns", cfg.Tools.Exec.AllowlistPatterns)...)
 	}
 
 	return errs
@@ -443,6 +443,15 @@ func computeConfigSignature(cfg *config.Config) string {
 	if cfg.Tools.AppendFile.Enabled {
 		toolSignatures = append(toolSignatures, "append_file")
 	}
+	if cfg.Tools.TailFile.Enabled {
+		toolSignatures = append(toolSignatures, "tail_file")
+	}
+	if cfg.Tools.Grep.Enabled {
+		toolSignatures = append(toolSignatures, "grep")
+	}
+	if cfg.Tools.Diff.Enabled {
+		toolSignatures = append(toolSignatures, "diff")
+	}
 	if cfg.Tools.Exec.Enabled {
 		toolSignatures = append(toolSignatures, "exec")
 	}
@@ -455,6 +464,12 @@ func computeConfigSignature(cfg *config.Config) string {
 	if cfg.Tools.WebFetch.Enabled {
 		toolSignatures = append(toolSignatures, "web_fetch")
 	}
+	if cfg.Tools.HTTPRequest.Enabled {
+		toolSignatures = append(toolSignatures, "http_request")
+	}
+	if cfg.Tools.ImageGen.Enabled {
+		toolSignatures = append(toolSignatures, "image_gen")
+	}
 	if cfg.Tools.Message.Enabled {
 		toolSignatures = append(toolSignatures, "message")
 	}
@@ -482,6 +497,18 @@ func computeConfigSignature(cfg *config.Config) string {
 	if cfg.Tools.MCP.Enabled {
 		toolSignatures = append(toolSignatures, "mcp")
 	}
+	if cfg.Tools.MCPAdmin.Enabled {
+		toolSignatures = append(toolSignatures, "mcp_admin")
+	}
+	if cfg.Tools.Model.Enabled {
+		toolSignatures = append(toolSignatures, "model")
+	}
+	if cfg.Tools.Project.Enabled {
+		toolSignatures = append(toolSignatures, "project")
+	}
+	if cfg.Tools.Encode.Enabled {
+		toolSignatures = append(toolSignatures, "encode")
+	}
 	if cfg.Tools.MCP.Discovery.Enabled {
 		toolSignatures = append(toolSignatures, "mcp_discovery")
 	}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/netbind"
+	"github.com/sipeed/picoclaw/web/backend/middleware"
 )
 
 func (h *Handler) effectiveLauncherPublic() bool {
@@ -218,13 +219,13 @@ func (h *Handler) picoWebUIAddr(r *http.Request) string {
 }
 
 func (h *Handler) buildWsURL(r *http.Request) string {
-	return requestWSScheme(r) + "://" + h.picoWebUIAddr(r) + "/pico/ws"
+	return requestWSScheme(r) + "://" + h.picoWebUIAddr(r) + middleware.RequestBasePath(r) + "/pico/ws"
 }
 
 func (h *Handler) buildPicoEventsURL(r *http.Request) string {
-	return requestHTTPScheme(r) + "://" + h.picoWebUIAddr(r) + "/pico/events"
+	return requestHTTPScheme(r) + "://" + h.picoWebUIAddr(r) + middleware.RequestBasePath(r) + "/pico/events"
 }
 
 func (h *Handler) buildPicoSendURL(r *http.Request) string {
-	return requestHTTPScheme(r) + "://" + h.picoWebUIAddr(r) + "/pico/send"
+	return requestHTTPScheme(r) + "://" + h.picoWebUIAddr(r) + middleware.RequestBasePath(r) + "/pico/send"
 }
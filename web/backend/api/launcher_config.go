@@ -14,6 +14,9 @@ type launcherConfigPayload struct {
 	Public        bool     `json:"public"`
 	AllowedCIDRs  []string `json:"allowed_cidrs"`
 	LauncherToken string   `json:"launcher_token"`
+	// BasePath is reported but not changed through the API; it is set in
+	// launcher-config.json, with -base-path or PICOCLAW_LAUNCHER_BASE_PATH.
+	BasePath string `json:"base_path"`
 }
 
 func (h *Handler) registerLauncherConfigRoutes(mux *http.ServeMux) {
@@ -54,6 +57,7 @@ func (h *Handler) handleGetLauncherConfig(w http.ResponseWriter, r *http.Request
 		Public:        cfg.Public,
 		AllowedCIDRs:  append([]string(nil), cfg.AllowedCIDRs...),
 		LauncherToken: cfg.LauncherToken,
+		BasePath:      cfg.BasePath,
 	})
 }
 
@@ -70,6 +74,10 @@ func (h *Handler) handleUpdateLauncherConfig(w http.ResponseWriter, r *http.Requ
 		AllowedCIDRs:  append([]string(nil), payload.AllowedCIDRs...),
 		LauncherToken: strings.TrimSpace(payload.LauncherToken),
 	}
+	// Keep the saved base path; an unreadable file is simply replaced.
+	if current, err := h.loadLauncherConfig(); err == nil {
+		cfg.BasePath = current.BasePath
+	}
 	if err := launcherconfig.Validate(cfg); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -86,5 +94,6 @@ func (h *Handler) handleUpdateLauncherConfig(w http.ResponseWriter, r *http.Requ
 		Public:        cfg.Public,
 		AllowedCIDRs:  append([]string(nil), cfg.AllowedCIDRs...),
 		LauncherToken: cfg.LauncherToken,
+		BasePath:      cfg.BasePath,
 	})
 }
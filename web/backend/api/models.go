@@ -1,17 +1,23 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
+// providerModelsTimeout bounds a provider's model listing request.
+const providerModelsTimeout = 15 * time.Second
+
 // registerModelRoutes binds model list management endpoints to the ServeMux.
 func (h *Handler) registerModelRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/models", h.handleListModels)
@@ -19,6 +25,7 @@ func (h *Handler) registerModelRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/models/default", h.handleSetDefaultModel)
 	mux.HandleFunc("PUT /api/models/{index}", h.handleUpdateModel)
 	mux.HandleFunc("DELETE /api/models/{index}", h.handleDeleteModel)
+	mux.HandleFunc("GET /api/models/{index}/available", h.handleListProviderModels)
 }
 
 // modelResponse is the JSON structure returned for each model in the list.
@@ -28,6 +35,7 @@ type modelResponse struct {
 	ModelName  string `json:"model_name"`
 	Model      string `json:"model"`
 	APIBase    string `json:"api_base,omitempty"`
+	APIVersion string `json:"api_version,omitempty"`
 	APIKey     string `json:"api_key"`
 	Proxy      string `json:"proxy,omitempty"`
 	AuthMethod string `json:"auth_method,omitempty"`
@@ -40,6 +48,9 @@ type modelResponse struct {
 	ThinkingLevel  string            `json:"thinking_level,omitempty"`
 	ExtraBody      map[string]any    `json:"extra_body,omitempty"`
 	CustomHeaders  map[string]string `json:"custom_headers,omitempty"`
+	MaxTokens      int               `json:"max_tokens,omitempty"`
+	Temperature    *float64          `json:"temperature,omitempty"`
+	MaxTemperature *float64          `json:"max_temperature,omitempty"`
 	// Meta
 	Enabled   bool   `json:"enabled"`
 	Available bool   `json:"available"`
@@ -78,6 +89,7 @@ func (h *Handler) handleListModels(w http.ResponseWriter, r *http.Request) {
 			ModelName:      m.ModelName,
 			Model:          m.Model,
 			APIBase:        m.APIBase,
+			APIVersion:     m.APIVersion,
 			APIKey:         maskAPIKey(m.APIKey()),
 			Proxy:          m.Proxy,
 			AuthMethod:     m.AuthMethod,
@@ -89,6 +101,9 @@ func (h *Handler) handleListModels(w http.ResponseWriter, r *http.Request) {
 			ThinkingLevel:  m.ThinkingLevel,
 			ExtraBody:      m.ExtraBody,
 			CustomHeaders:  m.CustomHeaders,
+			MaxTokens:      m.MaxTokens,
+			Temperature:    m.Temperature,
+			MaxTemperature: m.MaxTemperature,
 			Enabled:        m.Enabled,
 			Available:      modelStatuses[i].Available,
 			Status:         modelStatuses[i].Status,
@@ -226,6 +241,19 @@ func (h *Handler) handleUpdateModel(w http.ResponseWriter, r *http.Request) {
 	} else if len(mc.CustomHeaders) == 0 {
 		mc.CustomHeaders = nil
 	}
+	// The model editor does not manage per-model generation settings, so keep
+	// them unless the request names them explicitly.
+	var fields map[string]json.RawMessage
+	_ = json.Unmarshal(body, &fields)
+	if _, ok := fields["max_tokens"]; !ok {
+		mc.MaxTokens = cfg.ModelList[idx].MaxTokens
+	}
+	if _, ok := fields["temperature"]; !ok {
+		mc.Temperature = cfg.ModelList[idx].Temperature
+	}
+	if _, ok := fields["max_temperature"]; !ok {
+		mc.MaxTemperature = cfg.ModelList[idx].MaxTemperature
+	}
 
 	cfg.ModelList[idx] = &mc.ModelConfig
 
@@ -365,3 +393,55 @@ func maskAPIKey(key string) string {
 	// Show first 3 chars and last 4 chars
 	return key[:3] + "****" + key[len(key)-4:]
 }
+
+// handleListProviderModels returns the model IDs offered by the provider
+// behind a model_list entry, so the UI can offer them when picking a model.
+//
+//	GET /api/models/{index}/available
+func (h *Handler) handleListProviderModels(w http.ResponseWriter, r *http.Request) {
+	idx, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		http.Error(w, "Invalid index", http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if idx < 0 || idx >= len(cfg.ModelList) {
+		http.Error(w, fmt.Sprintf("Index %d out of range (0-%d)", idx, len(cfg.ModelList)-1), http.StatusNotFound)
+		return
+	}
+
+	provider, _, err := providers.CreateProviderFromConfig(cfg.ModelList[idx])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create provider: %v", err), http.StatusBadRequest)
+		return
+	}
+	if sp, ok := provider.(providers.StatefulProvider); ok {
+		defer sp.Close()
+	}
+
+	lister, ok := provider.(providers.ModelLister)
+	if !ok {
+		http.Error(w, "Provider does not support listing models", http.StatusNotImplemented)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), providerModelsTimeout)
+	defer cancel()
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list models: %v", err), http.StatusBadGateway)
+		return
+	}
+	if models == nil {
+		models = []providers.ModelInfo{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"models": models})
+}
@@ -536,6 +536,55 @@ func TestHandleUpdateModel_CustomHeadersPreserveAndClear(t *testing.T) {
 	}
 }
 
+func TestHandleUpdateModel_PreservesGenerationSettings(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	temperature := 0.3
+	cfg.ModelList = []*config.ModelConfig{{
+		ModelName:   "editable",
+		Model:       "openai/gpt-4o-mini",
+		APIKeys:     config.SimpleSecureStrings("sk-existing"),
+		MaxTokens:   2048,
+		Temperature: &temperature,
+	}}
+	if err = config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/models/0", bytes.NewBufferString(`{
+		"model_name":"editable",
+		"model":"openai/gpt-4o-mini",
+		"max_tokens":4096
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	after, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() after update error = %v", err)
+	}
+	got := after.ModelList[0]
+	if got.MaxTokens != 4096 {
+		t.Fatalf("max_tokens = %d, want 4096", got.MaxTokens)
+	}
+	if got.Temperature == nil || *got.Temperature != 0.3 {
+		t.Fatalf("temperature = %v, want preserved 0.3", got.Temperature)
+	}
+}
+
 // TestHandleSetDefaultModel_RejectsNonexistentModel tests that setting a non-existent
 // model as default returns 404. This covers the case where virtual models (which are
 // filtered by SaveConfig) cannot be set as default.
@@ -646,3 +695,71 @@ func TestMaskAPIKey(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleListProviderModels(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"id":"local-b"},{"id":"local-a"}]}`))
+	}))
+	defer upstream.Close()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.ModelList = append(cfg.ModelList,
+		&config.ModelConfig{
+			ModelName: "local",
+			Model:     "openai/local-a",
+			APIBase:   upstream.URL,
+			APIKeys:   config.SimpleSecureStrings("sk-local"),
+		},
+		&config.ModelConfig{
+			ModelName: "claude",
+			Model:     "anthropic-messages/claude-sonnet-4-6",
+			APIKeys:   config.SimpleSecureStrings("sk-ant"),
+		},
+	)
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	list := func(index string) (int, []string) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/models/"+index+"/available", nil))
+		var body struct {
+			Models []struct {
+				ID string `json:"id"`
+			} `json:"models"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		ids := make([]string, 0, len(body.Models))
+		for _, m := range body.Models {
+			ids = append(ids, m.ID)
+		}
+		return rec.Code, ids
+	}
+
+	code, ids := list("1")
+	if code != http.StatusOK || strings.Join(ids, ",") != "local-a,local-b" {
+		t.Fatalf("local models = %d %v, want 200 [local-a local-b]", code, ids)
+	}
+	code, ids = list("2")
+	if code != http.StatusOK || len(ids) == 0 || !strings.HasPrefix(ids[0], "claude-") {
+		t.Fatalf("anthropic models = %d %v, want curated claude list", code, ids)
+	}
+	if code, _ = list("9"); code != http.StatusNotFound {
+		t.Fatalf("out of range status = %d, want 404", code)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httputil"
+	"strings"
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -24,6 +25,7 @@ func (h *Handler) registerPicoRoutes(mux *http.ServeMux) {
 	// This allows the frontend to connect via the same port as the web UI,
 	// avoiding the need to expose extra ports for WebSocket communication.
 	mux.HandleFunc("GET /pico/ws", h.handleWebSocketProxy())
+	mux.HandleFunc("POST /pico/upload", h.handlePicoUploadProxy)
 }
 
 // createWsProxy creates a reverse proxy to the current gateway WebSocket endpoint.
@@ -106,6 +108,39 @@ func (h *Handler) handleWebSocketProxy() http.HandlerFunc {
 	}
 }
 
+// handlePicoUploadProxy forwards a chat file upload to the gateway. The
+// browser authenticates with the Pico token as a bearer token, which is
+// swapped for the gateway's composed token like on the WebSocket proxy.
+//
+//	POST /pico/upload
+func (h *Handler) handlePicoUploadProxy(w http.ResponseWriter, r *http.Request) {
+	gateway.mu.Lock()
+	ensurePicoTokenCachedLocked(h.configPath)
+	gateway.mu.Unlock()
+
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	token := ""
+	if ok && bearer != "" {
+		token = picoComposedToken(tokenPrefix + bearer)
+	}
+	if token == "" {
+		http.Error(w, "Invalid Pico token", http.StatusForbidden)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(h.gatewayProxyURL())
+			r.Out.Header.Set("Authorization", "Bearer "+token)
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Errorf("Failed to proxy upload: %v", err)
+			http.Error(w, "Gateway unavailable: "+err.Error(), http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
 // handleGetPicoToken returns the current WS token and URL for the frontend.
 //
 //	GET /api/pico/token
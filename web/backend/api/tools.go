@@ -75,6 +75,24 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "filesystem",
 		ConfigKey:   "read_file",
 	},
+	{
+		Name:        "tail_file",
+		Description: "Show the last lines of a text file and follow new output.",
+		Category:    "filesystem",
+		ConfigKey:   "tail_file",
+	},
+	{
+		Name:        "grep",
+		Description: "Search file contents across the workspace with a regular expression.",
+		Category:    "filesystem",
+		ConfigKey:   "grep",
+	},
+	{
+		Name:        "diff",
+		Description: "Show a unified diff between files, directories, or a file and an earlier snapshot.",
+		Category:    "filesystem",
+		ConfigKey:   "diff",
+	},
 	{
 		Name:        "write_file",
 		Description: "Create or overwrite files within the writable workspace scope.",
@@ -105,6 +123,18 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "filesystem",
 		ConfigKey:   "exec",
 	},
+	{
+		Name:        "project",
+		Description: "Group workspace directories into named projects and pick the one exec runs in.",
+		Category:    "filesystem",
+		ConfigKey:   "project",
+	},
+	{
+		Name:        "encode",
+		Description: "Convert base64, hex, URL and JSON text and compute hashes, without a shell.",
+		Category:    "utility",
+		ConfigKey:   "encode",
+	},
 	{
 		Name:        "cron",
 		Description: "Schedule one-time or recurring reminders, jobs, and shell commands.",
@@ -123,6 +153,18 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "web",
 		ConfigKey:   "web_fetch",
 	},
+	{
+		Name:        "http_request",
+		Description: "Call REST APIs with any method, headers and body.",
+		Category:    "web",
+		ConfigKey:   "http_request",
+	},
+	{
+		Name:        "image_gen",
+		Description: "Generate an image from a prompt and send it to the active chat.",
+		Category:    "communication",
+		ConfigKey:   "image_gen",
+	},
 	{
 		Name:        "message",
 		Description: "Send a follow-up message back to the active user or chat.",
@@ -171,6 +213,18 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "hardware",
 		ConfigKey:   "spi",
 	},
+	{
+		Name:        "mcp_admin",
+		Description: "List MCP servers and restart or reload them without restarting the gateway.",
+		Category:    "discovery",
+		ConfigKey:   "mcp_admin",
+	},
+	{
+		Name:        "model",
+		Description: "Show the conversation's model, list the available ones and switch models per session.",
+		Category:    "discovery",
+		ConfigKey:   "model",
+	},
 	{
 		Name:        "tool_search_tool_regex",
 		Description: "Discover hidden MCP tools by regex search when tool discovery is enabled.",
@@ -257,6 +311,15 @@ func buildToolSupport(cfg *config.Config) []toolSupportItem {
 					reasonCode = "requires_subagent"
 				}
 			}
+		case "mcp_admin":
+			if cfg.Tools.MCPAdmin.Enabled {
+				if cfg.Tools.IsToolEnabled("mcp") {
+					status = "enabled"
+				} else {
+					status = "blocked"
+					reasonCode = "requires_mcp"
+				}
+			}
 		case "tool_search_tool_regex":
 			status, reasonCode = resolveDiscoveryToolSupport(cfg, cfg.Tools.MCP.Discovery.UseRegex)
 		case "tool_search_tool_bm25":
@@ -316,6 +379,12 @@ func applyToolState(cfg *config.Config, toolName string, enabled bool) error {
 		cfg.Tools.EditFile.Enabled = enabled
 	case "append_file":
 		cfg.Tools.AppendFile.Enabled = enabled
+	case "tail_file":
+		cfg.Tools.TailFile.Enabled = enabled
+	case "grep":
+		cfg.Tools.Grep.Enabled = enabled
+	case "diff":
+		cfg.Tools.Diff.Enabled = enabled
 	case "exec":
 		cfg.Tools.Exec.Enabled = enabled
 	case "cron":
@@ -324,6 +393,10 @@ func applyToolState(cfg *config.Config, toolName string, enabled bool) error {
 		cfg.Tools.Web.Enabled = enabled
 	case "web_fetch":
 		cfg.Tools.WebFetch.Enabled = enabled
+	case "http_request":
+		cfg.Tools.HTTPRequest.Enabled = enabled
+	case "image_gen":
+		cfg.Tools.ImageGen.Enabled = enabled
 	case "message":
 		cfg.Tools.Message.Enabled = enabled
 	case "send_file":
@@ -353,6 +426,17 @@ func applyToolState(cfg *config.Config, toolName string, enabled bool) error {
 		cfg.Tools.I2C.Enabled = enabled
 	case "spi":
 		cfg.Tools.SPI.Enabled = enabled
+	case "model":
+		cfg.Tools.Model.Enabled = enabled
+	case "project":
+		cfg.Tools.Project.Enabled = enabled
+	case "encode":
+		cfg.Tools.Encode.Enabled = enabled
+	case "mcp_admin":
+		cfg.Tools.MCPAdmin.Enabled = enabled
+		if enabled {
+			cfg.Tools.MCP.Enabled = true
+		}
 	case "tool_search_tool_regex":
 		cfg.Tools.MCP.Discovery.UseRegex = enabled
 		if enabled {
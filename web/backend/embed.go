@@ -1,15 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
+	"html"
 	"io/fs"
 	"mime"
 	"net/http"
 	"path"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/web/backend/middleware"
 )
 
 //go:embed all:dist
@@ -71,9 +76,28 @@ func registerEmbedRoutes(mux *http.ServeMux) {
 				}
 			}
 
-			indexReq := r.Clone(r.Context())
-			indexReq.URL.Path = "/"
-			fileServer.ServeHTTP(w, indexReq)
+			serveIndex(w, r, subFS)
 		}),
 	)
 }
+
+// indexBaseTag matches the <base> tag in index.html. The frontend is built
+// with relative asset URLs and resolves its API and page paths against the
+// document base, so pointing it at the base path is all a subpath needs.
+var indexBaseTag = regexp.MustCompile(`<base href="[^"]*"\s*/?>`)
+
+// serveIndex serves the SPA entry with its <base> set to the request's base
+// path.
+func serveIndex(w http.ResponseWriter, r *http.Request, fsys fs.FS) {
+	data, err := fs.ReadFile(fsys, "index.html")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if base := middleware.RequestBasePath(r); base != "" {
+		tag := []byte(`<base href="` + html.EscapeString(base) + `/" />`)
+		data = indexBaseTag.ReplaceAllLiteral(data, tag)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	http.ServeContent(w, r, "index.html", time.Time{}, bytes.NewReader(data))
+}
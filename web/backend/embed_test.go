@@ -3,7 +3,11 @@ package main
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"testing/fstest"
+
+	"github.com/sipeed/picoclaw/web/backend/middleware"
 )
 
 func TestUnknownAPIPathStays404(t *testing.T) {
@@ -31,3 +35,27 @@ func TestMissingAssetStays404(t *testing.T) {
 		t.Fatalf("status = %d, want %d", rr.Code, http.StatusNotFound)
 	}
 }
+
+func TestServeIndexRewritesBaseHref(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html": {Data: []byte(`<head><base href="/" /><script src="./assets/app.js"></script></head>`)},
+	}
+	handler := middleware.BasePath("/picoclaw", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveIndex(w, r, fsys)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/picoclaw/config", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `<base href="/picoclaw/" />`) {
+		t.Fatalf("body = %q, want base href /picoclaw/", body)
+	}
+
+	rr = httptest.NewRecorder()
+	serveIndex(rr, httptest.NewRequest(http.MethodGet, "/config", nil), fsys)
+	if body := rr.Body.String(); !strings.Contains(body, `<base href="/" />`) {
+		t.Fatalf("body = %q, want base href left at /", body)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -20,6 +21,8 @@ const (
 	EnvLauncherToken = "PICOCLAW_LAUNCHER_TOKEN"
 	// EnvLauncherHost overrides launcher listen host.
 	EnvLauncherHost = "PICOCLAW_LAUNCHER_HOST"
+	// EnvLauncherBasePath overrides the path prefix the launcher is served under.
+	EnvLauncherBasePath = "PICOCLAW_LAUNCHER_BASE_PATH"
 
 	// dashboardSigningKeyBytes is the HMAC-SHA256 key size (256 bits).
 	dashboardSigningKeyBytes = 32
@@ -41,6 +44,10 @@ type Config struct {
 	Public        bool     `json:"public"`
 	AllowedCIDRs  []string `json:"allowed_cidrs,omitempty"`
 	LauncherToken string   `json:"launcher_token,omitempty"`
+	// BasePath serves the launcher under a path prefix such as "/picoclaw",
+	// for a reverse proxy that routes a subpath to it. Empty serves it at
+	// the root.
+	BasePath string `json:"base_path,omitempty"`
 }
 
 // Default returns default launcher settings.
@@ -58,6 +65,9 @@ func Validate(cfg Config) error {
 			return fmt.Errorf("invalid CIDR %q", cidr)
 		}
 	}
+	if strings.ContainsAny(cfg.BasePath, "?#\"'<>\\ \t") {
+		return fmt.Errorf("invalid base path %q", cfg.BasePath)
+	}
 	return nil
 }
 
@@ -120,6 +130,20 @@ func NormalizeCIDRs(cidrs []string) []string {
 	return out
 }
 
+// NormalizeBasePath cleans a base path into "/prefix" form without a
+// trailing slash. "" and "/" both mean the root and return "".
+func NormalizeBasePath(raw string) string {
+	p := strings.TrimSpace(raw)
+	if p == "" {
+		return ""
+	}
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return ""
+	}
+	return p
+}
+
 // PathForAppConfig returns launcher-config path near the app config file.
 func PathForAppConfig(appConfigPath string) string {
 	dir := filepath.Dir(appConfigPath)
@@ -145,6 +169,7 @@ func Load(path string, fallback Config) (Config, error) {
 	}
 	cfg.AllowedCIDRs = NormalizeCIDRs(cfg.AllowedCIDRs)
 	cfg.LauncherToken = strings.TrimSpace(cfg.LauncherToken)
+	cfg.BasePath = NormalizeBasePath(cfg.BasePath)
 	if err := Validate(cfg); err != nil {
 		return Config{}, err
 	}
@@ -155,6 +180,7 @@ func Load(path string, fallback Config) (Config, error) {
 func Save(path string, cfg Config) error {
 	cfg.AllowedCIDRs = NormalizeCIDRs(cfg.AllowedCIDRs)
 	cfg.LauncherToken = strings.TrimSpace(cfg.LauncherToken)
+	cfg.BasePath = NormalizeBasePath(cfg.BasePath)
 	if err := Validate(cfg); err != nil {
 		return err
 	}
@@ -153,3 +153,17 @@ func TestNormalizeCIDRs(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeBasePath(t *testing.T) {
+	for raw, want := range map[string]string{
+		"":                 "",
+		"/":                "",
+		" /picoclaw/ ":     "/picoclaw",
+		"picoclaw":         "/picoclaw",
+		"/tools//picoclaw": "/tools/picoclaw",
+	} {
+		if got := NormalizeBasePath(raw); got != want {
+			t.Fatalf("NormalizeBasePath(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -90,6 +90,23 @@ func resolveLauncherHostInput(flagHost string, explicitFlag bool, envHost string
 	return normalized, true, nil
 }
 
+// resolveLauncherBasePath picks the base path: -base-path when given, else
+// PICOCLAW_LAUNCHER_BASE_PATH, else launcher-config.json. The result is
+// normalized to "/prefix", or "" for the root.
+func resolveLauncherBasePath(flagPath string, explicitFlag bool, envPath, configPath string) (string, error) {
+	raw := configPath
+	if explicitFlag {
+		raw = flagPath
+	} else if strings.TrimSpace(envPath) != "" {
+		raw = envPath
+	}
+	basePath := launcherconfig.NormalizeBasePath(raw)
+	if err := launcherconfig.Validate(launcherconfig.Config{Port: launcherconfig.DefaultPort, BasePath: basePath}); err != nil {
+		return "", err
+	}
+	return basePath, nil
+}
+
 func openLauncherListeners(hostInput string, public bool, port string) (netbind.OpenResult, error) {
 	defaultMode := netbind.DefaultLoopback
 	if strings.TrimSpace(hostInput) == "" && public {
@@ -343,6 +360,7 @@ func main() {
 	noBrowser = flag.Bool("no-browser", false, "Do not auto-open browser on startup")
 	lang := flag.String("lang", "", "Language: en (English) or zh (Chinese). Default: auto-detect from system locale")
 	console := flag.Bool("console", false, "Console mode, no GUI")
+	basePathFlag := flag.String("base-path", "", "Serve the launcher under this path prefix, e.g. /picoclaw behind a reverse proxy")
 
 	var debug bool
 	flag.BoolVar(&debug, "d", false, "Enable debug logging")
@@ -370,6 +388,8 @@ func main() {
 		fmt.Fprintf(os.Stderr, "      Bind launcher host explicitly with exact host semantics\n")
 		fmt.Fprintf(os.Stderr, "  %s -console -d ./config.json\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "      Run in the terminal with debug logs enabled\n")
+		fmt.Fprintf(os.Stderr, "  %s -base-path /picoclaw ./config.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "      Serve the console at /picoclaw/ behind a reverse proxy\n")
 	}
 	flag.Parse()
 
@@ -445,6 +465,7 @@ func main() {
 	var explicitPort bool
 	var explicitPublic bool
 	var explicitHost bool
+	var explicitBasePath bool
 	flag.Visit(func(f *flag.Flag) {
 		switch f.Name {
 		case "port":
@@ -453,6 +474,8 @@ func main() {
 			explicitHost = true
 		case "public":
 			explicitPublic = true
+		case "base-path":
+			explicitBasePath = true
 		}
 	})
 
@@ -489,6 +512,18 @@ func main() {
 		logger.InfoC("web", "Ignoring -public because launcher host was explicitly set")
 	}
 
+	basePath, err := resolveLauncherBasePath(
+		*basePathFlag, explicitBasePath, os.Getenv(launcherconfig.EnvLauncherBasePath), launcherCfg.BasePath,
+	)
+	if err != nil {
+		logger.Fatalf("Invalid base path: %v", err)
+	}
+	// consolePath is appended to host:port in the URLs shown and opened.
+	consolePath := ""
+	if basePath != "" {
+		consolePath = basePath + "/"
+	}
+
 	portNum, err := strconv.Atoi(effectivePort)
 	if err != nil || portNum < 1 || portNum > 65535 {
 		if err == nil {
@@ -567,8 +602,10 @@ func main() {
 	// Apply middleware stack
 	handler := middleware.Recoverer(
 		middleware.Logger(
-			middleware.ReferrerPolicyNoReferrer(
-				middleware.JSONContentType(dashAuth),
+			middleware.BasePath(basePath,
+				middleware.ReferrerPolicyNoReferrer(
+					middleware.JSONContentType(dashAuth),
+				),
 			),
 		),
 	)
@@ -582,7 +619,7 @@ func main() {
 		fmt.Println("  Open the following URL in your browser:")
 		fmt.Println()
 		for _, host := range consoleHosts {
-			fmt.Printf("    >> http://%s <<\n", net.JoinHostPort(host, effectivePort))
+			fmt.Printf("    >> http://%s%s <<\n", net.JoinHostPort(host, effectivePort), consolePath)
 		}
 		fmt.Println()
 	}
@@ -599,10 +636,12 @@ func main() {
 
 	// Share the local URL with the launcher runtime.
 	serverAddr = fmt.Sprintf("http://%s", net.JoinHostPort(openResult.ProbeHost, effectivePort))
+	browserLaunchURL = serverAddr + consolePath
+	if basePath != "" {
+		logger.InfoC("web", fmt.Sprintf("Serving the console under %s", consolePath))
+	}
 	if dashboardToken != "" {
-		browserLaunchURL = serverAddr + "?token=" + url.QueryEscape(dashboardToken)
-	} else {
-		browserLaunchURL = serverAddr
+		browserLaunchURL += "?token=" + url.QueryEscape(dashboardToken)
 	}
 
 	// Auto-open browser will be handled by the launcher runtime.
@@ -142,6 +142,41 @@ func TestResolveLauncherHostInput(t *testing.T) {
 	}
 }
 
+func TestResolveLauncherBasePath(t *testing.T) {
+	tests := []struct {
+		name         string
+		flagPath     string
+		explicitFlag bool
+		envPath      string
+		configPath   string
+		want         string
+		wantErr      bool
+	}{
+		{name: "config used by default", configPath: "/picoclaw", want: "/picoclaw"},
+		{name: "env overrides config", envPath: "tools/picoclaw/", configPath: "/picoclaw", want: "/tools/picoclaw"},
+		{
+			name:         "flag overrides env",
+			flagPath:     "/",
+			explicitFlag: true,
+			envPath:      "/picoclaw",
+			want:         "",
+		},
+		{name: "invalid path rejected", envPath: "/pico claw", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveLauncherBasePath(tt.flagPath, tt.explicitFlag, tt.envPath, tt.configPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveLauncherBasePath() err = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("resolveLauncherBasePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestLauncherConsoleHosts(t *testing.T) {
 	t.Run("default loopback shows localhost only", func(t *testing.T) {
 		hosts := launcherConsoleHostsWithLocalAddrs(
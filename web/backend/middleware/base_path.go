@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type basePathKey struct{}
+
+// BasePath serves next under prefix, for a launcher reverse-proxied at a
+// subpath such as https://host/picoclaw/. The prefix is stripped from
+// requests that carry it; requests without it are served unchanged, so the
+// proxy may forward the full path or strip it. A request for the prefix
+// itself is redirected to prefix + "/", where the UI lives. Handlers build
+// browser-facing URLs with RequestBasePath. prefix must be normalized, as by
+// launcherconfig.NormalizeBasePath; "" serves next at the root.
+func BasePath(prefix string, next http.Handler) http.Handler {
+	if prefix == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix {
+			target := prefix + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+		r2 := r.WithContext(context.WithValue(r.Context(), basePathKey{}, prefix))
+		if rest, ok := strings.CutPrefix(r.URL.Path, prefix+"/"); ok {
+			// Copy the URL like http.StripPrefix, leaving r untouched.
+			u := *r.URL
+			u.Path = "/" + rest
+			if rawRest, ok := strings.CutPrefix(u.RawPath, prefix+"/"); ok {
+				u.RawPath = "/" + rawRest
+			} else {
+				u.RawPath = ""
+			}
+			r2.URL = &u
+		}
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// RequestBasePath returns the base path the launcher is served under, or ""
+// at the root. Prepend it to absolute paths sent back to the browser.
+func RequestBasePath(r *http.Request) string {
+	prefix, _ := r.Context().Value(basePathKey{}).(string)
+	return prefix
+}
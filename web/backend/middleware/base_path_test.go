@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasePath_StripsPrefix(t *testing.T) {
+	var gotPath, gotBase string
+	h := BasePath("/picoclaw", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBase = RequestBasePath(r)
+	}))
+
+	for _, tc := range []struct{ path, want string }{
+		{"/picoclaw/", "/"},
+		{"/picoclaw/api/status", "/api/status"},
+		// A proxy that strips the prefix itself forwards the bare path.
+		{"/api/status", "/api/status"},
+		{"/picoclaw-other/x", "/picoclaw-other/x"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if gotPath != tc.want {
+			t.Fatalf("%s: path = %q, want %q", tc.path, gotPath, tc.want)
+		}
+		if gotBase != "/picoclaw" {
+			t.Fatalf("%s: RequestBasePath = %q, want /picoclaw", tc.path, gotBase)
+		}
+		if req.URL.Path != tc.path {
+			t.Fatalf("%s: original request was modified to %q", tc.path, req.URL.Path)
+		}
+	}
+}
+
+func TestBasePath_RedirectsBarePrefix(t *testing.T) {
+	h := BasePath("/picoclaw", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next should not be called")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/picoclaw?token=abc", nil))
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMovedPermanently)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/picoclaw/?token=abc" {
+		t.Fatalf("Location = %q", loc)
+	}
+}
+
+func TestBasePath_EmptyPrefixIsNoop(t *testing.T) {
+	var gotBase string
+	h := BasePath("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBase = RequestBasePath(r)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/status", nil))
+	if gotBase != "" {
+		t.Fatalf("RequestBasePath = %q, want empty", gotBase)
+	}
+}
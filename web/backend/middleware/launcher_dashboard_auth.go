@@ -134,16 +134,17 @@ func tryLauncherQueryTokenLogin(
 }
 
 func redirectAfterQueryTokenLogin(r *http.Request, canonicalPath string) string {
+	base := RequestBasePath(r)
 	if canonicalPath == "/launcher-login" {
-		return "/"
+		return base + "/"
 	}
 	q := r.URL.Query()
 	q.Del("token")
 	enc := q.Encode()
 	if enc != "" {
-		return canonicalPath + "?" + enc
+		return base + canonicalPath + "?" + enc
 	}
-	return canonicalPath
+	return base + canonicalPath
 }
 
 func canonicalAuthPath(raw string) string {
@@ -224,5 +225,5 @@ func rejectLauncherDashboardAuth(w http.ResponseWriter, r *http.Request, canonic
 		_, _ = w.Write([]byte(`{"error":"unauthorized"}`))
 		return
 	}
-	http.Redirect(w, r, "/launcher-login", http.StatusFound)
+	http.Redirect(w, r, RequestBasePath(r)+"/launcher-login", http.StatusFound)
 }
@@ -1,3 +1,4 @@
+import { withBasePath } from "@/lib/base-path"
 import { isLauncherAuthPathname } from "@/lib/launcher-login-path"
 
 function isLauncherAuthPath(): boolean {
@@ -19,15 +20,19 @@ function isLauncherAuthPath(): boolean {
 /**
  * Same-origin fetch that sends cookies; redirects to launcher login on 401 JSON responses.
  * Skips redirect while already on an auth page (login or setup) to avoid reload loops.
+ * Absolute path strings are resolved against the launcher's base path.
  */
 export async function launcherFetch(
   input: RequestInfo | URL,
   init?: RequestInit,
 ): Promise<Response> {
-  const res = await fetch(input, {
-    credentials: "same-origin",
-    ...init,
-  })
+  const res = await fetch(
+    typeof input === "string" ? withBasePath(input) : input,
+    {
+      credentials: "same-origin",
+      ...init,
+    },
+  )
   if (res.status === 401) {
     const ct = res.headers.get("content-type") || ""
     if (
@@ -35,7 +40,7 @@ export async function launcherFetch(
       typeof globalThis.location !== "undefined" &&
       !isLauncherAuthPath()
     ) {
-      globalThis.location.assign("/launcher-login")
+      globalThis.location.assign(withBasePath("/launcher-login"))
     }
   }
   return res
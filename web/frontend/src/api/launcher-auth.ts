@@ -1,3 +1,5 @@
+import { withBasePath } from "@/lib/base-path"
+
 /**
  * Dashboard launcher auth API.
  * Uses plain fetch (not launcherFetch) to avoid redirect loops on auth pages.
@@ -5,7 +7,7 @@
 export async function postLauncherDashboardLogin(
   password: string,
 ): Promise<boolean> {
-  const res = await fetch("/api/auth/login", {
+  const res = await fetch(withBasePath("/api/auth/login"), {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     credentials: "same-origin",
@@ -21,7 +23,7 @@ export type LauncherAuthStatus = {
 }
 
 export async function getLauncherAuthStatus(): Promise<LauncherAuthStatus> {
-  const res = await fetch("/api/auth/status", {
+  const res = await fetch(withBasePath("/api/auth/status"), {
     method: "GET",
     credentials: "same-origin",
   })
@@ -32,7 +34,7 @@ export async function getLauncherAuthStatus(): Promise<LauncherAuthStatus> {
 }
 
 export async function postLauncherDashboardLogout(): Promise<boolean> {
-  const res = await fetch("/api/auth/logout", {
+  const res = await fetch(withBasePath("/api/auth/logout"), {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     credentials: "same-origin",
@@ -47,7 +49,7 @@ export async function postLauncherDashboardSetup(
   password: string,
   confirm: string,
 ): Promise<SetupResult> {
-  const res = await fetch("/api/auth/setup", {
+  const res = await fetch(withBasePath("/api/auth/setup"), {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     credentials: "same-origin",
@@ -20,6 +20,9 @@ export interface ModelInfo {
   thinking_level?: string
   extra_body?: Record<string, unknown>
   custom_headers?: Record<string, string>
+  max_tokens?: number
+  temperature?: number
+  max_temperature?: number
   // Meta
   available: boolean
   status: "available" | "unconfigured" | "unreachable"
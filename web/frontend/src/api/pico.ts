@@ -8,6 +8,13 @@ interface PicoTokenResponse {
   enabled: boolean
 }
 
+interface PicoUploadResponse {
+  ref: string
+  filename: string
+  mime_type: string
+  size: number
+}
+
 interface PicoSetupResponse {
   token: string
   ws_url: string
@@ -37,4 +44,28 @@ export async function setupPico(): Promise<PicoSetupResponse> {
   return request<PicoSetupResponse>("/api/pico/setup", { method: "POST" })
 }
 
-export type { PicoTokenResponse, PicoSetupResponse }
+// uploadPicoFile stores a chat attachment on the gateway. The returned ref is
+// sent in the media list of the next message.
+export async function uploadPicoFile(
+  file: File,
+  sessionId: string,
+): Promise<PicoUploadResponse> {
+  const { token } = await getPicoToken()
+  const body = new FormData()
+  body.append("file", file)
+  const res = await launcherFetch(
+    `${BASE_URL}/pico/upload?session_id=${encodeURIComponent(sessionId)}`,
+    {
+      method: "POST",
+      headers: { Authorization: `Bearer ${token}` },
+      body,
+    },
+  )
+  if (!res.ok) {
+    const message = (await res.text()).trim()
+    throw new Error(message || `${res.status} ${res.statusText}`)
+  }
+  return res.json() as Promise<PicoUploadResponse>
+}
+
+export type { PicoTokenResponse, PicoSetupResponse, PicoUploadResponse }
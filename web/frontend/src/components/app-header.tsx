@@ -41,6 +41,7 @@ import {
 } from "@/components/ui/tooltip"
 import { useGateway } from "@/hooks/use-gateway.ts"
 import { useTheme } from "@/hooks/use-theme.ts"
+import { withBasePath } from "@/lib/base-path"
 
 export function AppHeader() {
   const { i18n, t } = useTranslation()
@@ -73,7 +74,7 @@ export function AppHeader() {
 
   const handleLogout = async () => {
     await postLauncherDashboardLogout()
-    globalThis.location.assign("/launcher-login")
+    globalThis.location.assign(withBasePath("/launcher-login"))
   }
 
   const handleGatewayToggle = () => {
@@ -105,7 +106,7 @@ export function AppHeader() {
         </SidebarTrigger>
         <div className="hidden w-36 shrink-0 items-center sm:flex">
           <Link to="/">
-            <img className="w-full" src="/logo_with_text.png" alt="Logo" />
+            <img className="w-full" src={withBasePath("/logo_with_text.png")} alt="Logo" />
           </Link>
         </div>
       </div>
@@ -1,4 +1,9 @@
-import { IconArrowUp, IconPhotoPlus, IconX } from "@tabler/icons-react"
+import {
+  IconArrowUp,
+  IconPaperclip,
+  IconPhotoPlus,
+  IconX,
+} from "@tabler/icons-react"
 import type { KeyboardEvent } from "react"
 import { useTranslation } from "react-i18next"
 import TextareaAutosize from "react-textarea-autosize"
@@ -24,6 +29,7 @@ interface ChatComposerProps {
   attachments: ChatAttachment[]
   onInputChange: (value: string) => void
   onAddImages: () => void
+  onAddFiles: () => void
   onRemoveAttachment: (index: number) => void
   onSend: () => void
   inputDisabledReason: ChatInputDisabledReason | null
@@ -35,6 +41,7 @@ export function ChatComposer({
   attachments,
   onInputChange,
   onAddImages,
+  onAddFiles,
   onRemoveAttachment,
   onSend,
   inputDisabledReason,
@@ -61,27 +68,48 @@ export function ChatComposer({
       <div className="bg-card border-border/80 mx-auto flex max-w-[1000px] flex-col rounded-2xl border p-3 shadow-md">
         {attachments.length > 0 && (
           <div className="mb-3 flex flex-wrap gap-2 px-2">
-            {attachments.map((attachment, index) => (
-              <div
-                key={`${attachment.url}-${index}`}
-                className="bg-background relative h-20 w-20 overflow-hidden rounded-xl border"
-              >
-                <img
-                  src={attachment.url}
-                  alt={attachment.filename || t("chat.uploadedImage")}
-                  className="h-full w-full object-cover"
-                />
-                <button
-                  type="button"
-                  onClick={() => onRemoveAttachment(index)}
-                  className="bg-background/85 text-foreground absolute top-1 right-1 inline-flex h-6 w-6 items-center justify-center rounded-full border shadow-sm transition hover:bg-white"
-                  aria-label={t("chat.removeImage")}
-                  title={t("chat.removeImage")}
+            {attachments.map((attachment, index) =>
+              attachment.type === "file" ? (
+                <div
+                  key={`${attachment.url}-${index}`}
+                  className="bg-background flex h-9 max-w-60 items-center gap-2 rounded-xl border pr-1 pl-3 text-sm"
                 >
-                  <IconX className="h-3.5 w-3.5" />
-                </button>
-              </div>
-            ))}
+                  <IconPaperclip className="text-muted-foreground size-4 shrink-0" />
+                  <span className="truncate" title={attachment.filename}>
+                    {attachment.filename}
+                  </span>
+                  <button
+                    type="button"
+                    onClick={() => onRemoveAttachment(index)}
+                    className="text-muted-foreground hover:text-foreground inline-flex h-6 w-6 shrink-0 items-center justify-center rounded-full transition"
+                    aria-label={t("chat.removeFile")}
+                    title={t("chat.removeFile")}
+                  >
+                    <IconX className="h-3.5 w-3.5" />
+                  </button>
+                </div>
+              ) : (
+                <div
+                  key={`${attachment.url}-${index}`}
+                  className="bg-background relative h-20 w-20 overflow-hidden rounded-xl border"
+                >
+                  <img
+                    src={attachment.url}
+                    alt={attachment.filename || t("chat.uploadedImage")}
+                    className="h-full w-full object-cover"
+                  />
+                  <button
+                    type="button"
+                    onClick={() => onRemoveAttachment(index)}
+                    className="bg-background/85 text-foreground absolute top-1 right-1 inline-flex h-6 w-6 items-center justify-center rounded-full border shadow-sm transition hover:bg-white"
+                    aria-label={t("chat.removeImage")}
+                    title={t("chat.removeImage")}
+                  >
+                    <IconX className="h-3.5 w-3.5" />
+                  </button>
+                </div>
+              ),
+            )}
           </div>
         )}
 
@@ -119,6 +147,18 @@ export function ChatComposer({
             >
               <IconPhotoPlus className="size-4" />
             </Button>
+            <Button
+              type="button"
+              variant="ghost"
+              size="icon"
+              className="text-muted-foreground hover:text-foreground h-8 w-8 rounded-full"
+              onClick={onAddFiles}
+              disabled={!canInput}
+              aria-label={t("chat.attachFile")}
+              title={t("chat.attachFile")}
+            >
+              <IconPaperclip className="size-4" />
+            </Button>
           </div>
 
           {canInput ? (
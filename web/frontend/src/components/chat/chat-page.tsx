@@ -3,6 +3,7 @@ import { type ChangeEvent, useEffect, useRef, useState } from "react"
 import { useTranslation } from "react-i18next"
 import { toast } from "sonner"
 
+import { uploadPicoFile } from "@/api/pico"
 import { AssistantMessage } from "@/components/chat/assistant-message"
 import {
   ChatComposer,
@@ -105,10 +106,12 @@ export function ChatPage() {
   const { t } = useTranslation()
   const scrollRef = useRef<HTMLDivElement>(null)
   const fileInputRef = useRef<HTMLInputElement>(null)
+  const documentInputRef = useRef<HTMLInputElement>(null)
   const [isAtBottom, setIsAtBottom] = useState(true)
   const [hasScrolled, setHasScrolled] = useState(false)
   const [input, setInput] = useState("")
   const [attachments, setAttachments] = useState<ChatAttachment[]>([])
+  const [isUploading, setIsUploading] = useState(false)
 
   const {
     messages,
@@ -172,7 +175,12 @@ export function ChatPage() {
   }, [messages, isTyping, isAtBottom])
 
   const handleSend = () => {
-    if ((!input.trim() && attachments.length === 0) || !canInput) return
+    if (
+      (!input.trim() && attachments.length === 0) ||
+      !canInput ||
+      isUploading
+    )
+      return
     if (
       sendMessage({
         content: input,
@@ -189,6 +197,48 @@ export function ChatPage() {
     fileInputRef.current?.click()
   }
 
+  const handleAddFiles = () => {
+    if (!canInput) return
+    documentInputRef.current?.click()
+  }
+
+  const handleFileSelection = async (event: ChangeEvent<HTMLInputElement>) => {
+    const files = Array.from(event.target.files ?? [])
+    event.target.value = ""
+
+    if (files.length === 0) {
+      return
+    }
+
+    setIsUploading(true)
+    try {
+      for (const file of files) {
+        try {
+          const uploaded = await uploadPicoFile(file, activeSessionId)
+          setAttachments((prev) => [
+            ...prev,
+            {
+              type: "file",
+              url: uploaded.ref,
+              filename: uploaded.filename,
+              mimeType: uploaded.mime_type,
+              size: uploaded.size,
+            },
+          ])
+        } catch (error) {
+          toast.error(
+            t("chat.fileUploadFailed", {
+              name: file.name,
+              error: error instanceof Error ? error.message : String(error),
+            }),
+          )
+        }
+      }
+    } finally {
+      setIsUploading(false)
+    }
+  }
+
   const handleRemoveAttachment = (index: number) => {
     setAttachments((prev) => prev.filter((_, itemIndex) => itemIndex !== index))
   }
@@ -238,12 +288,17 @@ export function ChatPage() {
     }
 
     if (nextAttachments.length > 0) {
-      setAttachments(nextAttachments.slice(0, 1))
+      setAttachments((prev) => [
+        ...prev.filter((attachment) => attachment.type === "file"),
+        ...nextAttachments.slice(0, 1),
+      ])
     }
   }
 
   const canSubmit =
-    canInput && (Boolean(input.trim()) || attachments.length > 0)
+    canInput &&
+    !isUploading &&
+    (Boolean(input.trim()) || attachments.length > 0)
 
   return (
     <div className="bg-background/95 flex h-full flex-col">
@@ -333,12 +388,20 @@ export function ChatPage() {
         className="hidden"
         onChange={handleImageSelection}
       />
+      <input
+        ref={documentInputRef}
+        type="file"
+        multiple
+        className="hidden"
+        onChange={handleFileSelection}
+      />
 
       <ChatComposer
         input={input}
         attachments={attachments}
         onInputChange={setInput}
         onAddImages={handleAddImages}
+        onAddFiles={handleAddFiles}
         onRemoveAttachment={handleRemoveAttachment}
         onSend={handleSend}
         inputDisabledReason={inputDisabledReason}
@@ -1,3 +1,5 @@
+import { IconPaperclip } from "@tabler/icons-react"
+
 import type { ChatAttachment } from "@/store/chat"
 
 interface UserMessageProps {
@@ -10,6 +12,9 @@ export function UserMessage({ content, attachments = [] }: UserMessageProps) {
   const imageAttachments = attachments.filter(
     (attachment) => attachment.type === "image",
   )
+  const fileAttachments = attachments.filter(
+    (attachment) => attachment.type === "file",
+  )
 
   return (
     <div className="flex w-full flex-col items-end gap-1.5">
@@ -26,6 +31,22 @@ export function UserMessage({ content, attachments = [] }: UserMessageProps) {
         </div>
       )}
 
+      {fileAttachments.length > 0 && (
+        <div className="flex max-w-[70%] flex-wrap justify-end gap-2">
+          {fileAttachments.map((attachment, index) => (
+            <div
+              key={`${attachment.url}-${index}`}
+              className="bg-card flex max-w-60 items-center gap-2 rounded-xl border px-3 py-2 text-sm"
+            >
+              <IconPaperclip className="text-muted-foreground size-4 shrink-0" />
+              <span className="truncate" title={attachment.filename}>
+                {attachment.filename}
+              </span>
+            </div>
+          ))}
+        </div>
+      )}
+
       {hasText && (
         <div className="max-w-[70%] rounded-2xl rounded-tr-sm bg-violet-500 px-5 py-3 text-[15px] leading-relaxed wrap-break-word whitespace-pre-wrap text-white shadow-sm">
           {content}
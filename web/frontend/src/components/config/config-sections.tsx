@@ -25,6 +25,7 @@ import {
   SelectValue,
 } from "@/components/ui/select"
 import { Textarea } from "@/components/ui/textarea"
+import { withBasePath } from "@/lib/base-path"
 
 type UpdateCoreField = <K extends keyof CoreConfigForm>(
   key: K,
@@ -239,7 +240,7 @@ export function ExecSection({ form, onFieldChange }: ExecSectionProps) {
 
     setIsLoading(true)
     try {
-      const res = await fetch("/api/config/test-command-patterns", {
+      const res = await fetch(withBasePath("/api/config/test-command-patterns"), {
         method: "POST",
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify({
@@ -14,6 +14,7 @@ import {
 } from "@/features/chat/state"
 import { invalidateSocket, isCurrentSocket } from "@/features/chat/websocket"
 import i18n from "@/i18n"
+import { withBasePath } from "@/lib/base-path"
 import {
   type ChatAttachment,
   getChatState,
@@ -148,7 +149,7 @@ export async function connectChat() {
     }
 
     const wsScheme = window.location.protocol === "https:" ? "wss:" : "ws:"
-    const wsUrl = `${wsScheme}//${window.location.host}/pico/ws`
+    const wsUrl = `${wsScheme}//${window.location.host}${withBasePath("/pico/ws")}`
     const url = `${wsUrl}?session_id=${encodeURIComponent(sessionId)}`
     const socket = new WebSocket(url, [`token.${token}`])
 
@@ -341,7 +342,7 @@ export function sendChatMessage({
 
   const normalizedContent = content.trim()
   const normalizedAttachments = attachments
-    .filter((attachment) => attachment.type === "image" && attachment.url)
+    .filter((attachment) => attachment.url)
     .map((attachment) => ({ ...attachment }))
 
   if (!normalizedContent && normalizedAttachments.length === 0) {
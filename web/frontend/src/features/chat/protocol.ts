@@ -81,6 +81,22 @@ export function handlePicoMessage(
       break
     }
 
+    case "message.delete": {
+      const messageId = payload.message_id as string
+      if (!messageId) {
+        break
+      }
+
+      updateChatStore((prev) => ({
+        messages: prev.messages.filter((msg) => msg.id !== messageId),
+      }))
+      break
+    }
+
+    case "message.done":
+      updateChatStore({ isTyping: false })
+      break
+
     case "typing.start":
       updateChatStore({ isTyping: true })
       break
@@ -0,0 +1,25 @@
+/**
+ * Path prefix the launcher is served under, such as "/picoclaw" behind a
+ * reverse proxy, or "" at the root. The launcher writes it into the page's
+ * <base href>.
+ */
+export const basePath =
+  typeof document === "undefined"
+    ? ""
+    : new URL(document.baseURI).pathname.replace(/\/+$/, "")
+
+/** Prefix an absolute app path such as "/api/status" with the base path. */
+export function withBasePath(path: string): string {
+  return path.startsWith("/") ? `${basePath}${path}` : path
+}
+
+/** Remove the base path from a location pathname. */
+export function stripBasePath(pathname: string): string {
+  if (basePath === "") {
+    return pathname
+  }
+  if (pathname === basePath || pathname.startsWith(`${basePath}/`)) {
+    return pathname.slice(basePath.length) || "/"
+  }
+  return pathname
+}
@@ -1,6 +1,8 @@
-/** Normalize URL pathname for comparisons (trailing slashes, empty). */
+import { stripBasePath } from "@/lib/base-path"
+
+/** Normalize URL pathname for comparisons (base path, trailing slashes, empty). */
 export function normalizePathname(p: string): string {
-  const t = p.replace(/\/+$/, "")
+  const t = stripBasePath(p).replace(/\/+$/, "")
   return t === "" ? "/" : t
 }
 
@@ -3,6 +3,8 @@ import { RouterProvider, createRouter } from "@tanstack/react-router"
 import { StrictMode } from "react"
 import ReactDOM from "react-dom/client"
 
+import { basePath } from "@/lib/base-path"
+
 import { AppProviders } from "./app-providers"
 import "./i18n"
 import "./index.css"
@@ -12,6 +14,7 @@ const queryClient = new QueryClient()
 
 const router = createRouter({
   routeTree,
+  basepath: basePath || "/",
   context: {
     queryClient,
   },
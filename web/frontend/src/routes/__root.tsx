@@ -5,6 +5,7 @@ import { useEffect, useState } from "react"
 import { getLauncherAuthStatus } from "@/api/launcher-auth"
 import { AppLayout } from "@/components/app-layout"
 import { initializeChatStore } from "@/features/chat/controller"
+import { withBasePath } from "@/lib/base-path"
 import { isLauncherAuthPathname } from "@/lib/launcher-login-path"
 
 const RootLayout = () => {
@@ -39,9 +40,9 @@ const RootLayout = () => {
     void getLauncherAuthStatus()
       .then((s) => {
         if (!s.initialized) {
-          globalThis.location.assign("/launcher-setup")
+          globalThis.location.assign(withBasePath("/launcher-setup"))
         } else if (!s.authenticated) {
-          globalThis.location.assign("/launcher-login")
+          globalThis.location.assign(withBasePath("/launcher-login"))
         }
       })
       .catch((err: unknown) => {
@@ -50,7 +51,7 @@ const RootLayout = () => {
         // do NOT redirect: a subsequent successful login would loop straight back here.
         // launcherFetch handles 401 on real API calls regardless.
         if (err instanceof Error && /^status 40[13]$/.test(err.message)) {
-          globalThis.location.assign("/launcher-login")
+          globalThis.location.assign(withBasePath("/launcher-login"))
         } else {
           setAuthError(
             err instanceof Error
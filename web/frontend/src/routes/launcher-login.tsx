@@ -24,6 +24,7 @@ import {
 import { Input } from "@/components/ui/input"
 import { Label } from "@/components/ui/label"
 import { useTheme } from "@/hooks/use-theme"
+import { withBasePath } from "@/lib/base-path"
 
 function LauncherLoginPage() {
   const { t, i18n } = useTranslation()
@@ -37,7 +38,7 @@ function LauncherLoginPage() {
     void getLauncherAuthStatus()
       .then((s) => {
         if (!s.initialized) {
-          globalThis.location.assign("/launcher-setup")
+          globalThis.location.assign(withBasePath("/launcher-setup"))
         }
       })
       .catch(() => {
@@ -52,7 +53,7 @@ function LauncherLoginPage() {
       try {
         const ok = await postLauncherDashboardLogin(tokenValue)
         if (ok) {
-          globalThis.location.assign("/")
+          globalThis.location.assign(withBasePath("/"))
           return
         }
         setError(t("launcherLogin.errorInvalid"))
@@ -21,6 +21,7 @@ import {
 import { Input } from "@/components/ui/input"
 import { Label } from "@/components/ui/label"
 import { useTheme } from "@/hooks/use-theme"
+import { withBasePath } from "@/lib/base-path"
 
 function LauncherSetupPage() {
   const { t, i18n } = useTranslation()
@@ -41,7 +42,7 @@ function LauncherSetupPage() {
     try {
       const result = await postLauncherDashboardSetup(password, confirm)
       if (result.ok) {
-        globalThis.location.assign("/launcher-login")
+        globalThis.location.assign(withBasePath("/launcher-login"))
         return
       }
       setError(result.error)
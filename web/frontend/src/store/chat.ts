@@ -6,9 +6,12 @@ import {
 } from "@/features/chat/state"
 
 export interface ChatAttachment {
-  type: "image"
+  type: "image" | "file"
+  // A data URL for images, or the media:// ref of an uploaded file.
   url: string
   filename?: string
+  mimeType?: string
+  size?: number
 }
 
 export type AssistantMessageKind = "normal" | "thought"
@@ -7,6 +7,9 @@ import { defineConfig } from "vite"
 
 // https://vite.dev/config/
 export default defineConfig({
+  // Relative asset URLs resolve against the <base href> the launcher writes
+  // into index.html, so the same build works under any base path.
+  base: "./",
   plugins: [
     tanstackRouter({
       target: "react",